@@ -0,0 +1,434 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// exportFormatVersion is bumped when the archive layout changes.
+	exportFormatVersion = 1
+	// exportManifestName and exportDatabaseName are the archive entries.
+	exportManifestName = "manifest.json"
+	exportDatabaseName = "dispatchoor.db"
+	// exportManifestMaxSize bounds reading the manifest of an archive.
+	exportManifestMaxSize = 1 << 20
+)
+
+// exportManifest describes an export archive.
+type exportManifest struct {
+	FormatVersion int       `json:"format_version"`
+	Version       string    `json:"version"`
+	SourceDriver  string    `json:"source_driver"`
+	ExportedAt    time.Time `json:"exported_at"`
+}
+
+func newExportCmd(log *logrus.Logger) *cobra.Command {
+	var (
+		configPath string
+		output     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export all state to a portable archive",
+		Long: `Export groups, templates and their lint results, jobs, users, saved filters,
+subscriptions, campaigns, queue changes, queue stats, runtime settings, and
+audit entries from the configured database to a gzipped tar archive, e.g. to back up a SQLite install or seed a staging
+environment.
+
+Sessions are not exported. The archive holds a SQLite snapshot, so it can be
+imported into either database backend with the import command.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExport(cmd.Context(), log, configPath, output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "config.yaml",
+		"Path to configuration file")
+	cmd.Flags().StringVarP(&output, "output", "o", "state.tar.gz", "Path of the archive to write")
+
+	return cmd
+}
+
+func newImportCmd(log *logrus.Logger) *cobra.Command {
+	var (
+		configPath string
+		input      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import state from an export archive",
+		Long: `Import an archive written by the export command into the configured database.
+
+The database must be empty. Users have to log in again, since sessions are not
+part of the archive.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runImport(cmd.Context(), log, configPath, input)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "config.yaml",
+		"Path to configuration file")
+	cmd.Flags().StringVarP(&input, "input", "i", "state.tar.gz", "Path of the archive to read")
+
+	return cmd
+}
+
+func runExport(ctx context.Context, log *logrus.Logger, configPath, output string) error {
+	log.WithField("path", configPath).Info("Loading configuration")
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+
+	src, err := newStoreForDriver(log, cfg, cfg.Database.Driver)
+	if err != nil {
+		return err
+	}
+
+	if err := src.Start(ctx); err != nil {
+		return fmt.Errorf("starting store: %w", err)
+	}
+
+	defer src.Stop()
+
+	// Bring the schema up to date so every copied column exists.
+	if err := src.Migrate(ctx); err != nil {
+		return fmt.Errorf("migrating schema: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "dispatchoor-export-")
+	if err != nil {
+		return fmt.Errorf("creating temporary directory: %w", err)
+	}
+
+	defer os.RemoveAll(tmpDir)
+
+	dbPath := filepath.Join(tmpDir, exportDatabaseName)
+
+	counts, err := writeSnapshot(ctx, log, src, dbPath)
+	if err != nil {
+		return err
+	}
+
+	manifest := &exportManifest{
+		FormatVersion: exportFormatVersion,
+		Version:       Version,
+		SourceDriver:  cfg.Database.Driver,
+		ExportedAt:    time.Now().UTC(),
+	}
+
+	if err := writeExportArchive(output, manifest, dbPath); err != nil {
+		return err
+	}
+
+	log.WithFields(logrus.Fields{
+		"output":        output,
+		"groups":        counts.groups,
+		"templates":     counts.templates,
+		"jobs":          counts.jobs,
+		"users":         counts.users,
+		"campaigns":     counts.campaigns,
+		"audit_entries": counts.auditEntries,
+	}).Info("Export completed successfully")
+
+	return nil
+}
+
+// writeSnapshot copies src, without sessions, into a new SQLite database at
+// path and closes it so the file is complete.
+func writeSnapshot(ctx context.Context, log *logrus.Logger, src store.Store, path string) (*migrateDataCounts, error) {
+	snapshot := store.NewSQLiteStore(log, path)
+
+	if err := snapshot.Start(ctx); err != nil {
+		return nil, fmt.Errorf("starting snapshot store: %w", err)
+	}
+
+	defer snapshot.Stop()
+
+	if err := snapshot.Migrate(ctx); err != nil {
+		return nil, fmt.Errorf("migrating snapshot schema: %w", err)
+	}
+
+	counts, err := copyStoreData(ctx, log, src, snapshot, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyStoreCounts(ctx, snapshot, counts); err != nil {
+		return nil, fmt.Errorf("integrity check failed: %w", err)
+	}
+
+	if err := snapshot.Stop(); err != nil {
+		return nil, fmt.Errorf("closing snapshot store: %w", err)
+	}
+
+	return counts, nil
+}
+
+// writeExportArchive writes the manifest and the snapshot at dbPath to a
+// gzipped tar archive at output. The archive is written next to output and
+// renamed into place, so a failed export never leaves a partial archive.
+func writeExportArchive(output string, manifest *exportManifest, dbPath string) (err error) {
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(output), filepath.Base(output)+".tmp-")
+	if err != nil {
+		return fmt.Errorf("creating archive: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+		}
+	}()
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    exportManifestName,
+		Mode:    0o600,
+		Size:    int64(len(manifestData)),
+		ModTime: manifest.ExportedAt,
+	}); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+
+	if _, err := tw.Write(manifestData); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+
+	db, err := os.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("opening snapshot: %w", err)
+	}
+
+	defer db.Close()
+
+	info, err := db.Stat()
+	if err != nil {
+		return fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    exportDatabaseName,
+		Mode:    0o600,
+		Size:    info.Size(),
+		ModTime: manifest.ExportedAt,
+	}); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+
+	if _, err := io.Copy(tw, db); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("finishing archive: %w", err)
+	}
+
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("finishing archive: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing archive: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), output); err != nil {
+		return fmt.Errorf("moving archive into place: %w", err)
+	}
+
+	return nil
+}
+
+func runImport(ctx context.Context, log *logrus.Logger, configPath, input string) error {
+	log.WithField("path", configPath).Info("Loading configuration")
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "dispatchoor-import-")
+	if err != nil {
+		return fmt.Errorf("creating temporary directory: %w", err)
+	}
+
+	defer os.RemoveAll(tmpDir)
+
+	dbPath := filepath.Join(tmpDir, exportDatabaseName)
+
+	manifest, err := readExportArchive(input, dbPath)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(logrus.Fields{
+		"input":         input,
+		"version":       manifest.Version,
+		"source_driver": manifest.SourceDriver,
+		"exported_at":   manifest.ExportedAt,
+	}).Info("Read export archive")
+
+	src := store.NewSQLiteStore(log, dbPath)
+
+	if err := src.Start(ctx); err != nil {
+		return fmt.Errorf("starting snapshot store: %w", err)
+	}
+
+	defer src.Stop()
+
+	// Archives from older releases are brought up to the current schema.
+	if err := src.Migrate(ctx); err != nil {
+		return fmt.Errorf("migrating snapshot schema: %w", err)
+	}
+
+	dst, err := newStoreForDriver(log, cfg, cfg.Database.Driver)
+	if err != nil {
+		return err
+	}
+
+	if err := dst.Start(ctx); err != nil {
+		return fmt.Errorf("starting store: %w", err)
+	}
+
+	defer dst.Stop()
+
+	if err := dst.Migrate(ctx); err != nil {
+		return fmt.Errorf("migrating schema: %w", err)
+	}
+
+	if err := ensureStoreEmpty(ctx, dst); err != nil {
+		return err
+	}
+
+	counts, err := copyStoreData(ctx, log, src, dst, false)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyStoreCounts(ctx, dst, counts); err != nil {
+		return fmt.Errorf("integrity check failed: %w", err)
+	}
+
+	log.WithFields(logrus.Fields{
+		"groups":        counts.groups,
+		"templates":     counts.templates,
+		"jobs":          counts.jobs,
+		"users":         counts.users,
+		"campaigns":     counts.campaigns,
+		"audit_entries": counts.auditEntries,
+	}).Info("Import completed successfully")
+
+	return nil
+}
+
+// readExportArchive reads the manifest of the archive at input and extracts
+// its snapshot to dbPath.
+func readExportArchive(input, dbPath string) (*exportManifest, error) {
+	file, err := os.Open(input)
+	if err != nil {
+		return nil, fmt.Errorf("opening archive: %w", err)
+	}
+
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+
+	defer gz.Close()
+
+	var (
+		manifest    *exportManifest
+		hasDatabase bool
+	)
+
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+
+		switch header.Name {
+		case exportManifestName:
+			data, err := io.ReadAll(io.LimitReader(tr, exportManifestMaxSize))
+			if err != nil {
+				return nil, fmt.Errorf("reading manifest: %w", err)
+			}
+
+			manifest = &exportManifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, fmt.Errorf("decoding manifest: %w", err)
+			}
+
+			if manifest.FormatVersion < 1 || manifest.FormatVersion > exportFormatVersion {
+				return nil, fmt.Errorf("unsupported archive format version %d (this release reads up to %d)",
+					manifest.FormatVersion, exportFormatVersion)
+			}
+		case exportDatabaseName:
+			if err := extractArchiveFile(tr, dbPath); err != nil {
+				return nil, err
+			}
+
+			hasDatabase = true
+		default:
+			return nil, fmt.Errorf("unexpected archive entry %q", header.Name)
+		}
+	}
+
+	if manifest == nil || !hasDatabase {
+		return nil, fmt.Errorf("archive is missing %s or %s", exportManifestName, exportDatabaseName)
+	}
+
+	return manifest, nil
+}
+
+// extractArchiveFile writes the current archive entry to path.
+func extractArchiveFile(r io.Reader, path string) error {
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("creating snapshot: %w", err)
+	}
+
+	if _, err := io.Copy(out, r); err != nil {
+		_ = out.Close()
+
+		return fmt.Errorf("extracting snapshot: %w", err)
+	}
+
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("extracting snapshot: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// writeStoreConfig writes a config using the SQLite database at dbPath.
+func writeStoreConfig(t *testing.T, dir, dbPath string) string {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{
+		"server":   map[string]any{"listen": ":0"},
+		"database": map[string]any{"driver": "sqlite", "sqlite": map[string]any{"path": dbPath}},
+		"auth": map[string]any{"basic": map[string]any{"enabled": true, "users": []map[string]any{
+			{"username": "admin", "password": "pass", "role": "admin"},
+		}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// JSON is valid YAML.
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// openStore starts and migrates the SQLite store at path.
+func openStore(t *testing.T, log *logrus.Logger, path string) store.Store {
+	t.Helper()
+
+	st := store.NewSQLiteStore(log, path)
+	if err := st.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+
+	t.Cleanup(func() { _ = st.Stop() })
+
+	if err := st.Migrate(context.Background()); err != nil {
+		t.Fatalf("Failed to migrate store: %v", err)
+	}
+
+	return st
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	src := openStore(t, log, filepath.Join(srcDir, "src.db"))
+
+	if err := src.CreateGroup(ctx, &store.Group{
+		ID: "test-group", Name: "Test Group", RunnerLabels: []string{"self-hosted"}, Enabled: true,
+		CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create group: %v", err)
+	}
+
+	if err := src.CreateJobTemplate(ctx, &store.JobTemplate{
+		ID: "testnet-spam", GroupID: "test-group", Name: "Testnet Spam", Owner: "ethpandaops", Repo: "tests",
+		WorkflowID: "spam.yml", Ref: "main", SourceType: "inline", CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+
+	if err := src.UpsertTemplateLint(ctx, &store.TemplateLint{
+		TemplateID: "testnet-spam", Ref: "main", WorkflowPath: ".github/workflows/spam.yml",
+		CheckedAt: now, Issues: []*store.LintIssue{
+			{Severity: store.LintSeverityWarning, Code: "unused-input", Message: "input network is unused"},
+		},
+	}); err != nil {
+		t.Fatalf("Failed to store lint: %v", err)
+	}
+
+	if err := src.CreateQueueStatSamples(ctx, []*store.QueueStatSample{
+		{GroupID: "test-group", SampledAt: now.Add(-time.Hour), Pending: 3, Running: 1, IdleRunners: 2},
+		{GroupID: "test-group", SampledAt: now, Pending: 1, Running: 2},
+	}); err != nil {
+		t.Fatalf("Failed to store queue stats: %v", err)
+	}
+
+	if err := src.Stop(); err != nil {
+		t.Fatalf("Failed to stop store: %v", err)
+	}
+
+	archive := filepath.Join(srcDir, "state.tar.gz")
+
+	if err := runExport(ctx, log, writeStoreConfig(t, srcDir, filepath.Join(srcDir, "src.db")), archive); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	dstPath := filepath.Join(dstDir, "dst.db")
+
+	if err := runImport(ctx, log, writeStoreConfig(t, dstDir, dstPath), archive); err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	dst := openStore(t, log, dstPath)
+
+	lint, err := dst.GetTemplateLint(ctx, "testnet-spam")
+	if err != nil {
+		t.Fatalf("Failed to get lint: %v", err)
+	}
+
+	if lint == nil || lint.WorkflowPath != ".github/workflows/spam.yml" || len(lint.Issues) != 1 ||
+		!lint.CheckedAt.Equal(now) {
+		t.Errorf("Expected the template lint to be imported, got %+v", lint)
+	}
+
+	samples, err := dst.ListQueueStatSamples(ctx, "test-group", time.Time{})
+	if err != nil {
+		t.Fatalf("Failed to list queue stats: %v", err)
+	}
+
+	if len(samples) != 2 || samples[0].Pending != 3 || samples[0].IdleRunners != 2 || samples[1].Running != 2 {
+		t.Errorf("Expected both queue stat samples to be imported, got %+v", samples)
+	}
+}
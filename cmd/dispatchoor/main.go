@@ -58,6 +58,9 @@ runner availability, not blind schedules.`,
 	rootCmd.AddCommand(
 		newServerCmd(log),
 		newMigrateCmd(log),
+		newMigrateDataCmd(log),
+		newExportCmd(log),
+		newImportCmd(log),
 		newVersionCmd(),
 	)
 
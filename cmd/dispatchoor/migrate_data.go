@@ -0,0 +1,583 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// migrateDataProgressInterval controls how often job copy progress is logged.
+const migrateDataProgressInterval = 500
+
+func newMigrateDataCmd(log *logrus.Logger) *cobra.Command {
+	var (
+		configPath string
+		from       string
+		to         string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "migrate-data",
+		Short: "Copy data between database backends",
+		Long: `Copy groups, templates and their lint results, jobs, queue stats, users,
+saved filters, subscriptions, sessions, and audit entries from one database
+backend to another (e.g. SQLite to PostgreSQL).
+
+Both databases are read from the database section of the configuration file,
+regardless of the configured driver. The destination must be empty.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigrateData(cmd.Context(), log, configPath, from, to)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "config.yaml",
+		"Path to configuration file")
+	cmd.Flags().StringVar(&from, "from", "sqlite", "Source database driver (sqlite, postgres)")
+	cmd.Flags().StringVar(&to, "to", "postgres", "Destination database driver (sqlite, postgres)")
+
+	return cmd
+}
+
+func runMigrateData(ctx context.Context, log *logrus.Logger, configPath, from, to string) error {
+	if from == to {
+		return fmt.Errorf("source and destination drivers must differ")
+	}
+
+	log.WithField("path", configPath).Info("Loading configuration")
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+
+	src, err := newStoreForDriver(log, cfg, from)
+	if err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+
+	dst, err := newStoreForDriver(log, cfg, to)
+	if err != nil {
+		return fmt.Errorf("destination: %w", err)
+	}
+
+	if err := src.Start(ctx); err != nil {
+		return fmt.Errorf("starting source store: %w", err)
+	}
+
+	defer src.Stop()
+
+	if err := dst.Start(ctx); err != nil {
+		return fmt.Errorf("starting destination store: %w", err)
+	}
+
+	defer dst.Stop()
+
+	// Bring both schemas up to date so every column exists on both sides.
+	if err := src.Migrate(ctx); err != nil {
+		return fmt.Errorf("migrating source schema: %w", err)
+	}
+
+	if err := dst.Migrate(ctx); err != nil {
+		return fmt.Errorf("migrating destination schema: %w", err)
+	}
+
+	if err := ensureStoreEmpty(ctx, dst); err != nil {
+		return err
+	}
+
+	log.WithFields(logrus.Fields{
+		"from": from,
+		"to":   to,
+	}).Info("Starting data migration")
+
+	counts, err := copyStoreData(ctx, log, src, dst, true)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyStoreCounts(ctx, dst, counts); err != nil {
+		return fmt.Errorf("integrity check failed: %w", err)
+	}
+
+	log.WithFields(logrus.Fields{
+		"groups":         counts.groups,
+		"templates":      counts.templates,
+		"template_lints": counts.templateLints,
+		"queue_stats":    counts.queueStats,
+		"jobs":           counts.jobs,
+		"users":          counts.users,
+		"saved_filters":  counts.savedFilters,
+		"subscriptions":  counts.subscriptions,
+		"sessions":       counts.sessions,
+		"campaigns":      counts.campaigns,
+		"queue_changes":  counts.queueChanges,
+		"settings":       counts.settings,
+		"audit_entries":  counts.auditEntries,
+	}).Info("Data migration completed successfully")
+
+	return nil
+}
+
+// newStoreForDriver creates a store for the given driver using the database section of cfg.
+func newStoreForDriver(log *logrus.Logger, cfg *config.Config, driver string) (store.Store, error) {
+	switch driver {
+	case "sqlite":
+		if cfg.Database.SQLite.Path == "" {
+			return nil, fmt.Errorf("database.sqlite.path is required")
+		}
+
+		return store.NewSQLiteStore(log, cfg.Database.SQLite.Path), nil
+	case "postgres":
+		if cfg.Database.Postgres.Host == "" || cfg.Database.Postgres.Database == "" {
+			return nil, fmt.Errorf("database.postgres.host and database.postgres.database are required")
+		}
+
+		pgCfg := *cfg
+		pgCfg.Database.Driver = "postgres"
+
+		return store.NewPostgresStore(log, pgCfg.GetDSN()), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", driver)
+	}
+}
+
+// migrateDataCounts tracks the number of rows copied per entity.
+type migrateDataCounts struct {
+	groups    int
+	templates int
+	// templateLints are per template, and queueStats per group.
+	templateLints int
+	queueStats    int
+	jobs          int
+	users         int
+	// savedFilters and subscriptions are per user.
+	savedFilters  int
+	subscriptions int
+	sessions      int
+	campaigns     int
+	queueChanges  int
+	settings      int
+	auditEntries  int
+}
+
+// ensureStoreEmpty refuses to copy into a database that already holds any of
+// the rows copyStoreData writes.
+func ensureStoreEmpty(ctx context.Context, st store.Store) error {
+	counts, err := countStoreRows(ctx, st)
+	if err != nil {
+		return fmt.Errorf("checking destination: %w", err)
+	}
+
+	if *counts != (migrateDataCounts{}) {
+		return fmt.Errorf("destination database is not empty (%+v)", *counts)
+	}
+
+	return nil
+}
+
+// copyStoreData copies all persistent entities from src to dst in dependency
+// order. Sessions are only copied if withSessions is set.
+func copyStoreData(ctx context.Context, log logrus.FieldLogger, src, dst store.Store, withSessions bool) (*migrateDataCounts, error) {
+	counts := &migrateDataCounts{}
+
+	// Groups, templates, and jobs.
+	groups, err := src.ListGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing groups: %w", err)
+	}
+
+	for _, group := range groups {
+		if err := dst.CreateGroup(ctx, group); err != nil {
+			return nil, fmt.Errorf("copying group %s: %w", group.ID, err)
+		}
+
+		counts.groups++
+
+		templates, err := src.ListJobTemplatesByGroup(ctx, group.ID)
+		if err != nil {
+			return nil, fmt.Errorf("listing templates for group %s: %w", group.ID, err)
+		}
+
+		for _, template := range templates {
+			if err := dst.CreateJobTemplate(ctx, template); err != nil {
+				return nil, fmt.Errorf("copying template %s: %w", template.ID, err)
+			}
+
+			counts.templates++
+
+			lint, err := src.GetTemplateLint(ctx, template.ID)
+			if err != nil {
+				return nil, fmt.Errorf("getting lint for template %s: %w", template.ID, err)
+			}
+
+			if lint != nil {
+				if err := dst.UpsertTemplateLint(ctx, lint); err != nil {
+					return nil, fmt.Errorf("copying lint for template %s: %w", template.ID, err)
+				}
+
+				counts.templateLints++
+			}
+		}
+
+		jobs, err := src.ListJobsByGroup(ctx, group.ID)
+		if err != nil {
+			return nil, fmt.Errorf("listing jobs for group %s: %w", group.ID, err)
+		}
+
+		for _, job := range jobs {
+			if err := dst.CreateJob(ctx, job); err != nil {
+				return nil, fmt.Errorf("copying job %s: %w", job.ID, err)
+			}
+
+			// CreateJob only persists queue fields; run tracking fields are written by UpdateJob.
+			if job.Status != store.JobStatusPending {
+				if err := dst.UpdateJob(ctx, job); err != nil {
+					return nil, fmt.Errorf("copying job %s run state: %w", job.ID, err)
+				}
+			}
+
+			if job.Progress != nil {
+				if err := dst.UpdateJobProgress(ctx, job.ID, job.Progress); err != nil {
+					return nil, fmt.Errorf("copying job %s progress: %w", job.ID, err)
+				}
+			}
+
+			if job.HeartbeatAt != nil {
+				if err := dst.RecordJobHeartbeat(ctx, job.ID, *job.HeartbeatAt); err != nil {
+					return nil, fmt.Errorf("copying job %s heartbeat: %w", job.ID, err)
+				}
+			}
+
+			if job.StalledAt != nil {
+				if err := dst.SetJobStalled(ctx, job.ID, job.StalledAt); err != nil {
+					return nil, fmt.Errorf("copying job %s stalled flag: %w", job.ID, err)
+				}
+			}
+
+			if job.Retained {
+				if err := dst.SetJobRetained(ctx, job.ID, true); err != nil {
+					return nil, fmt.Errorf("copying job %s retained: %w", job.ID, err)
+				}
+			}
+
+			if job.Variant != "" {
+				if err := dst.SetJobVariant(ctx, job.ID, job.Variant); err != nil {
+					return nil, fmt.Errorf("copying job %s variant: %w", job.ID, err)
+				}
+			}
+
+			if job.Assignee != "" {
+				if err := dst.SetJobAssignee(ctx, job.ID, job.Assignee, job.AssignedAt); err != nil {
+					return nil, fmt.Errorf("copying job %s assignee: %w", job.ID, err)
+				}
+			}
+
+			if job.HandoffSecrets != nil {
+				if err := dst.SetJobHandoffSecrets(ctx, job.ID, job.HandoffSecrets); err != nil {
+					return nil, fmt.Errorf("copying job %s handoff secrets: %w", job.ID, err)
+				}
+			}
+
+			if job.RunAttempt != 0 {
+				if err := dst.UpdateJobRunAttempt(ctx, job.ID, job.RunAttempt, job.LogsURL, job.ArtifactsURL); err != nil {
+					return nil, fmt.Errorf("copying job %s run attempt: %w", job.ID, err)
+				}
+			}
+
+			events, err := src.ListJobEvents(ctx, job.ID)
+			if err != nil {
+				return nil, fmt.Errorf("listing events for job %s: %w", job.ID, err)
+			}
+
+			for _, event := range events {
+				if err := dst.CreateJobEvent(ctx, event); err != nil {
+					return nil, fmt.Errorf("copying event %s of job %s: %w", event.ID, job.ID, err)
+				}
+			}
+
+			attempts, err := src.ListJobRunAttempts(ctx, job.ID)
+			if err != nil {
+				return nil, fmt.Errorf("listing run attempts for job %s: %w", job.ID, err)
+			}
+
+			for _, attempt := range attempts {
+				if err := dst.CreateJobRunAttempt(ctx, attempt); err != nil {
+					return nil, fmt.Errorf("copying run attempt %d of job %s: %w", attempt.Attempt, job.ID, err)
+				}
+			}
+
+			counts.jobs++
+
+			if counts.jobs%migrateDataProgressInterval == 0 {
+				log.WithField("jobs", counts.jobs).Info("Copying jobs")
+			}
+		}
+
+		changes, _, err := src.ListQueueChanges(ctx, store.QueueChangeQueryOpts{GroupID: group.ID})
+		if err != nil {
+			return nil, fmt.Errorf("listing queue changes for group %s: %w", group.ID, err)
+		}
+
+		for _, change := range changes {
+			if err := dst.CreateQueueChange(ctx, change); err != nil {
+				return nil, fmt.Errorf("copying queue change %s: %w", change.ID, err)
+			}
+
+			counts.queueChanges++
+		}
+
+		samples, err := src.ListQueueStatSamples(ctx, group.ID, time.Time{})
+		if err != nil {
+			return nil, fmt.Errorf("listing queue stats for group %s: %w", group.ID, err)
+		}
+
+		if err := dst.CreateQueueStatSamples(ctx, samples); err != nil {
+			return nil, fmt.Errorf("copying queue stats for group %s: %w", group.ID, err)
+		}
+
+		counts.queueStats += len(samples)
+
+		log.WithFields(logrus.Fields{
+			"group":         group.ID,
+			"templates":     len(templates),
+			"jobs":          len(jobs),
+			"queue_changes": len(changes),
+			"queue_stats":   len(samples),
+		}).Info("Copied group")
+	}
+
+	// Users and sessions.
+	users, err := src.ListUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing users: %w", err)
+	}
+
+	for _, user := range users {
+		if err := dst.CreateUser(ctx, user); err != nil {
+			return nil, fmt.Errorf("copying user %s: %w", user.Username, err)
+		}
+
+		counts.users++
+
+		filters, err := src.ListSavedFiltersByUser(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("listing saved filters for user %s: %w", user.Username, err)
+		}
+
+		for _, filter := range filters {
+			if err := dst.CreateSavedFilter(ctx, filter); err != nil {
+				return nil, fmt.Errorf("copying saved filter %s: %w", filter.ID, err)
+			}
+
+			counts.savedFilters++
+		}
+
+		subs, err := src.ListSubscriptionsByUser(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("listing subscriptions for user %s: %w", user.Username, err)
+		}
+
+		for _, sub := range subs {
+			if err := dst.CreateSubscription(ctx, sub); err != nil {
+				return nil, fmt.Errorf("copying subscription %s: %w", sub.ID, err)
+			}
+
+			counts.subscriptions++
+		}
+	}
+
+	log.WithFields(logrus.Fields{
+		"users":         counts.users,
+		"saved_filters": counts.savedFilters,
+		"subscriptions": counts.subscriptions,
+	}).Info("Copied users")
+
+	if withSessions {
+		sessions, err := src.ListSessions(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing sessions: %w", err)
+		}
+
+		for _, session := range sessions {
+			if err := dst.CreateSession(ctx, session); err != nil {
+				return nil, fmt.Errorf("copying session %s: %w", session.ID, err)
+			}
+
+			counts.sessions++
+		}
+
+		log.WithField("sessions", counts.sessions).Info("Copied sessions")
+	}
+
+	// Campaigns.
+	campaigns, err := src.ListCampaigns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing campaigns: %w", err)
+	}
+
+	for _, campaign := range campaigns {
+		if err := dst.CreateCampaign(ctx, campaign); err != nil {
+			return nil, fmt.Errorf("copying campaign %s: %w", campaign.ID, err)
+		}
+
+		counts.campaigns++
+	}
+
+	log.WithField("campaigns", counts.campaigns).Info("Copied campaigns")
+
+	// Runtime settings.
+	settings, err := src.ListSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing settings: %w", err)
+	}
+
+	for _, setting := range settings {
+		if err := dst.UpsertSetting(ctx, setting); err != nil {
+			return nil, fmt.Errorf("copying setting %s: %w", setting.Key, err)
+		}
+
+		counts.settings++
+	}
+
+	// Audit log.
+	entries, _, err := src.ListAuditEntries(ctx, store.AuditQueryOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("listing audit entries: %w", err)
+	}
+
+	for _, entry := range entries {
+		if err := dst.CreateAuditEntry(ctx, entry); err != nil {
+			return nil, fmt.Errorf("copying audit entry %s: %w", entry.ID, err)
+		}
+
+		counts.auditEntries++
+	}
+
+	log.WithField("audit_entries", counts.auditEntries).Info("Copied audit entries")
+
+	return counts, nil
+}
+
+// verifyStoreCounts re-reads the destination and checks that every copied row is present.
+func verifyStoreCounts(ctx context.Context, dst store.Store, expected *migrateDataCounts) error {
+	actual, err := countStoreRows(ctx, dst)
+	if err != nil {
+		return err
+	}
+
+	if *actual != *expected {
+		return fmt.Errorf("row counts differ: expected %+v, got %+v", *expected, *actual)
+	}
+
+	return nil
+}
+
+// countStoreRows counts the rows of st that copyStoreData copies, reaching
+// them the same way.
+func countStoreRows(ctx context.Context, st store.Store) (*migrateDataCounts, error) {
+	actual := &migrateDataCounts{}
+
+	groups, err := st.ListGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing groups: %w", err)
+	}
+
+	actual.groups = len(groups)
+
+	for _, group := range groups {
+		templates, err := st.ListJobTemplatesByGroup(ctx, group.ID)
+		if err != nil {
+			return nil, fmt.Errorf("listing templates for group %s: %w", group.ID, err)
+		}
+
+		actual.templates += len(templates)
+
+		for _, template := range templates {
+			lint, err := st.GetTemplateLint(ctx, template.ID)
+			if err != nil {
+				return nil, fmt.Errorf("getting lint for template %s: %w", template.ID, err)
+			}
+
+			if lint != nil {
+				actual.templateLints++
+			}
+		}
+
+		samples, err := st.ListQueueStatSamples(ctx, group.ID, time.Time{})
+		if err != nil {
+			return nil, fmt.Errorf("listing queue stats for group %s: %w", group.ID, err)
+		}
+
+		actual.queueStats += len(samples)
+
+		jobs, err := st.ListJobsByGroup(ctx, group.ID)
+		if err != nil {
+			return nil, fmt.Errorf("listing jobs for group %s: %w", group.ID, err)
+		}
+
+		actual.jobs += len(jobs)
+
+		_, changes, err := st.ListQueueChanges(ctx, store.QueueChangeQueryOpts{GroupID: group.ID, Limit: 1})
+		if err != nil {
+			return nil, fmt.Errorf("counting queue changes for group %s: %w", group.ID, err)
+		}
+
+		actual.queueChanges += changes
+	}
+
+	users, err := st.ListUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing users: %w", err)
+	}
+
+	actual.users = len(users)
+
+	for _, user := range users {
+		filters, err := st.ListSavedFiltersByUser(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("listing saved filters for user %s: %w", user.Username, err)
+		}
+
+		actual.savedFilters += len(filters)
+
+		subs, err := st.ListSubscriptionsByUser(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("listing subscriptions for user %s: %w", user.Username, err)
+		}
+
+		actual.subscriptions += len(subs)
+	}
+
+	sessions, err := st.ListSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing sessions: %w", err)
+	}
+
+	actual.sessions = len(sessions)
+
+	campaigns, err := st.ListCampaigns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing campaigns: %w", err)
+	}
+
+	actual.campaigns = len(campaigns)
+
+	settings, err := st.ListSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing settings: %w", err)
+	}
+
+	actual.settings = len(settings)
+
+	_, actual.auditEntries, err = st.ListAuditEntries(ctx, store.AuditQueryOpts{Limit: 1})
+	if err != nil {
+		return nil, fmt.Errorf("counting audit entries: %w", err)
+	}
+
+	return actual, nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// seedStore writes one row of every kind copyStoreData copies.
+func seedStore(t *testing.T, st store.Store) {
+	t.Helper()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	if err := st.CreateGroup(ctx, &store.Group{
+		ID: "test-group", Name: "Test Group", RunnerLabels: []string{"self-hosted"}, Enabled: true,
+		CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create group: %v", err)
+	}
+
+	if err := st.CreateJobTemplate(ctx, &store.JobTemplate{
+		ID: "testnet-spam", GroupID: "test-group", Name: "Testnet Spam", Owner: "ethpandaops", Repo: "tests",
+		WorkflowID: "spam.yml", Ref: "main", SourceType: "inline", CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+
+	if err := st.UpsertTemplateLint(ctx, &store.TemplateLint{
+		TemplateID: "testnet-spam", Ref: "main", WorkflowPath: ".github/workflows/spam.yml", CheckedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to store lint: %v", err)
+	}
+
+	completedAt := now
+	job := &store.Job{
+		ID: "job-1", GroupID: "test-group", TemplateID: "testnet-spam", Status: store.JobStatusPending,
+		CreatedBy: "admin", CreatedAt: now, UpdatedAt: now,
+	}
+
+	if err := st.CreateJob(ctx, job); err != nil {
+		t.Fatalf("Failed to create job: %v", err)
+	}
+
+	job.Status, job.CompletedAt = store.JobStatusCompleted, &completedAt
+
+	if err := st.UpdateJob(ctx, job); err != nil {
+		t.Fatalf("Failed to update job: %v", err)
+	}
+
+	if err := st.CreateQueueChange(ctx, &store.QueueChange{
+		ID: "change-1", GroupID: "test-group", JobID: "job-1", Operation: store.QueueChangePause,
+		Actor: "admin", CreatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create queue change: %v", err)
+	}
+
+	if err := st.CreateQueueStatSamples(ctx, []*store.QueueStatSample{
+		{GroupID: "test-group", SampledAt: now, Pending: 1},
+	}); err != nil {
+		t.Fatalf("Failed to store queue stats: %v", err)
+	}
+
+	if err := st.CreateUser(ctx, &store.User{
+		ID: "user-1", Username: "admin", Role: store.RoleAdmin, AuthProvider: store.AuthProviderBasic,
+		CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	if err := st.CreateSavedFilter(ctx, &store.SavedFilter{
+		ID: "filter-1", UserID: "user-1", Name: "Mine", View: store.SavedFilterViewQueue, CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create saved filter: %v", err)
+	}
+
+	if err := st.CreateSubscription(ctx, &store.Subscription{
+		ID: "sub-1", UserID: "user-1", TargetType: store.SubscriptionTargetTemplate, TargetID: "testnet-spam",
+		Channel: store.SubscriptionChannelWebhook, Destination: "https://hooks.example.com", CreatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+
+	if err := st.CreateSession(ctx, &store.Session{
+		ID: "session-1", UserID: "user-1", TokenHash: "hash", ExpiresAt: now.Add(time.Hour), CreatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	if err := st.CreateCampaign(ctx, &store.Campaign{
+		ID: "campaign-1", Name: "Fork", CreatedBy: "admin", CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create campaign: %v", err)
+	}
+
+	if err := st.UpsertSetting(ctx, &store.Setting{
+		Key: "dispatcher.interval", Value: "30s", UpdatedBy: "admin", UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to store setting: %v", err)
+	}
+
+	if err := st.CreateAuditEntry(ctx, &store.AuditEntry{
+		ID: "audit-1", Action: store.AuditActionSettingsUpdated, EntityType: store.AuditEntitySystem,
+		Actor: "admin", CreatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create audit entry: %v", err)
+	}
+}
+
+func TestCopyStoreDataRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	dir := t.TempDir()
+	src := openStore(t, log, filepath.Join(dir, "src.db"))
+	dst := openStore(t, log, filepath.Join(dir, "dst.db"))
+
+	seedStore(t, src)
+
+	if err := ensureStoreEmpty(ctx, dst); err != nil {
+		t.Fatalf("Expected a migrated store to be empty: %v", err)
+	}
+
+	counts, err := copyStoreData(ctx, log, src, dst, true)
+	if err != nil {
+		t.Fatalf("Failed to copy store data: %v", err)
+	}
+
+	want := migrateDataCounts{
+		groups: 1, templates: 1, templateLints: 1, queueStats: 1, jobs: 1, users: 1, savedFilters: 1,
+		subscriptions: 1, sessions: 1, campaigns: 1, queueChanges: 1, settings: 1, auditEntries: 1,
+	}
+
+	if *counts != want {
+		t.Errorf("Expected every row to be copied, got %+v", *counts)
+	}
+
+	if err := verifyStoreCounts(ctx, dst, counts); err != nil {
+		t.Errorf("Failed to verify copied rows: %v", err)
+	}
+
+	job, err := dst.GetJob(ctx, "job-1")
+	if err != nil || job == nil || job.Status != store.JobStatusCompleted || job.CompletedAt == nil {
+		t.Errorf("Expected the job's run state to be copied, got %+v (%v)", job, err)
+	}
+
+	if err := ensureStoreEmpty(ctx, dst); err == nil {
+		t.Error("Expected a copied store not to be empty")
+	}
+}
+
+func TestEnsureStoreEmptyChecksEveryTable(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	// Rows that neither hang off a group or user nor are audit entries.
+	for name, seed := range map[string]func(store.Store) error{
+		"campaign": func(st store.Store) error {
+			return st.CreateCampaign(ctx, &store.Campaign{ID: "campaign-1", Name: "Fork", CreatedAt: now, UpdatedAt: now})
+		},
+		"setting": func(st store.Store) error {
+			return st.UpsertSetting(ctx, &store.Setting{Key: "dispatcher.interval", Value: "30s", UpdatedAt: now})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			st := openStore(t, log, filepath.Join(t.TempDir(), "dst.db"))
+
+			if err := seed(st); err != nil {
+				t.Fatalf("Failed to seed %s: %v", name, err)
+			}
+
+			err := ensureStoreEmpty(ctx, st)
+			if err == nil || !strings.Contains(err.Error(), "not empty") {
+				t.Errorf("Expected a store holding a %s to be refused, got %v", name, err)
+			}
+		})
+	}
+}
@@ -4,16 +4,26 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/ethpandaops/dispatchoor/pkg/api"
 	"github.com/ethpandaops/dispatchoor/pkg/auth"
 	"github.com/ethpandaops/dispatchoor/pkg/config"
 	"github.com/ethpandaops/dispatchoor/pkg/dispatcher"
 	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/lint"
+	"github.com/ethpandaops/dispatchoor/pkg/maintenance"
 	"github.com/ethpandaops/dispatchoor/pkg/metrics"
+	"github.com/ethpandaops/dispatchoor/pkg/notify"
+	"github.com/ethpandaops/dispatchoor/pkg/pgnotify"
 	"github.com/ethpandaops/dispatchoor/pkg/queue"
+	"github.com/ethpandaops/dispatchoor/pkg/queuestats"
+	"github.com/ethpandaops/dispatchoor/pkg/settings"
+	"github.com/ethpandaops/dispatchoor/pkg/starvation"
 	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/ethpandaops/dispatchoor/pkg/telemetry"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -71,28 +81,46 @@ func runServer(ctx context.Context, log *logrus.Logger, configPath string) error
 		return err
 	}
 
+	// Create metrics.
+	m := metrics.New()
+	m.SetBuildInfo(Version, GitCommit, BuildDate)
+	m.SetJobLabelLimits(cfg.Metrics.Groups, cfg.Metrics.Templates, cfg.Metrics.MaxLabelValues)
+
+	// Record latency of every store call and log slow queries.
+	st = store.NewInstrumentedStore(log, st, m, cfg.Database.SlowQueryThreshold)
+
+	// Cache hot group/template reads in front of the database.
+	var cache *store.CachedStore
+
+	if cfg.Database.Cache.Enabled {
+		cache = store.NewCachedStore(log, st, cfg.Database.Cache.TTL)
+		st = cache
+	}
+
 	// Sync groups from config.
 	if err := api.SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
 		return err
 	}
 
-	// Create metrics.
-	m := metrics.New()
-	m.SetBuildInfo(Version, GitCommit, BuildDate)
-
 	// Create GitHub clients.
-	// - runnersClient: used for polling runner status (uses runners_token if set, else token)
-	// - dispatchClient: used for dispatching workflows (uses token)
+	// - runnersClient: used for polling runner status (uses runners_token if set, else token or app)
+	// - dispatchClient: used for dispatching workflows (uses token or app)
 	var runnersClient github.Client
 
 	var dispatchClient github.Client
 
 	var poller github.Poller
 
-	// Create runners client for polling (uses runners_token if configured, else falls back to token).
-	if cfg.HasRunnersToken() {
+	// Create runners client for polling (uses runners_token if configured, else falls back to token or app).
+	if cfg.HasRunnersToken() || cfg.HasGitHubApp() {
 		runnersToken := cfg.GetRunnersToken()
-		runnersClient = github.NewClient(log.WithField("client", "runners"), runnersToken)
+		runnersOpts := []github.ClientOption{github.WithHTTPConfig(cfg.GitHub.HTTP), github.WithMetrics("runners", m)}
+
+		if runnersToken == "" {
+			runnersOpts = append(runnersOpts, github.WithAppAuth(cfg.GitHub.App))
+		}
+
+		runnersClient = github.NewClient(log.WithField("client", "runners"), runnersToken, runnersOpts...)
 
 		if err := runnersClient.Start(ctx); err != nil {
 			return err
@@ -124,9 +152,15 @@ func runServer(ctx context.Context, log *logrus.Logger, configPath string) error
 		log.Warn("No GitHub token configured for runners - runner polling disabled")
 	}
 
-	// Create dispatch client for workflow dispatching (uses main token).
-	if cfg.HasGitHubToken() {
-		dispatchClient = github.NewClient(log.WithField("client", "dispatch"), cfg.GitHub.Token)
+	// Create dispatch client for workflow dispatching (uses main token or app).
+	if cfg.HasGitHubToken() || cfg.HasGitHubApp() {
+		dispatchOpts := []github.ClientOption{github.WithHTTPConfig(cfg.GitHub.HTTP), github.WithMetrics("dispatch", m)}
+
+		if cfg.HasGitHubApp() {
+			dispatchOpts = append(dispatchOpts, github.WithAppAuth(cfg.GitHub.App))
+		}
+
+		dispatchClient = github.NewClient(log.WithField("client", "dispatch"), cfg.GitHub.Token, dispatchOpts...)
 
 		if err := dispatchClient.Start(ctx); err != nil {
 			return err
@@ -146,7 +180,7 @@ func runServer(ctx context.Context, log *logrus.Logger, configPath string) error
 	}
 
 	// Create queue service.
-	queueSvc := queue.NewService(log, cfg, st)
+	queueSvc := queue.NewService(log, cfg, st, m)
 
 	if err := queueSvc.Start(ctx); err != nil {
 		return err
@@ -158,7 +192,7 @@ func runServer(ctx context.Context, log *logrus.Logger, configPath string) error
 	var disp dispatcher.Dispatcher
 
 	if dispatchClient != nil && dispatchClient.IsConnected() {
-		disp = dispatcher.NewDispatcher(log, cfg, st, queueSvc, dispatchClient)
+		disp = dispatcher.NewDispatcher(log, cfg, st, queueSvc, dispatchClient, m)
 
 		if err := disp.Start(ctx); err != nil {
 			return err
@@ -171,6 +205,97 @@ func runServer(ctx context.Context, log *logrus.Logger, configPath string) error
 		}()
 	}
 
+	// Apply runtime settings overrides on top of the configured defaults.
+	settingsSvc := settings.NewService(log, cfg, st)
+	settingsSvc.SetChangeCallback(func(values settings.Values) {
+		if disp != nil {
+			disp.SetIntervals(values.DispatchInterval, values.TrackingInterval)
+		}
+
+		if poller != nil {
+			poller.SetRateLimitBuffer(values.RateLimitBuffer)
+		}
+	})
+
+	if err := settingsSvc.Start(ctx); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := settingsSvc.Stop(); err != nil {
+			log.WithError(err).Warn("Failed to stop runtime settings")
+		}
+	}()
+
+	// Lint templates against their workflow definitions on a schedule.
+	if cfg.Lint.Enabled && dispatchClient != nil && dispatchClient.IsConnected() {
+		lintSvc := lint.NewService(log, cfg, st, dispatchClient)
+
+		if err := lintSvc.Start(ctx); err != nil {
+			return err
+		}
+
+		defer func() {
+			if err := lintSvc.Stop(); err != nil {
+				log.WithError(err).Warn("Failed to stop template lint service")
+			}
+		}()
+	}
+
+	// Track how long jobs wait and report starved queues.
+	starvationSvc := starvation.NewService(log, cfg, st, m)
+
+	if err := starvationSvc.Start(ctx); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := starvationSvc.Stop(); err != nil {
+			log.WithError(err).Warn("Failed to stop queue starvation monitor")
+		}
+	}()
+
+	// Sample queue depth and idle runners for backlog trends.
+	queueStatsSvc := queuestats.NewService(log, cfg, st)
+
+	if err := queueStatsSvc.Start(ctx); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := queueStatsSvc.Stop(); err != nil {
+			log.WithError(err).Warn("Failed to stop queue stats sampler")
+		}
+	}()
+
+	// Push anonymized aggregate stats to a central endpoint.
+	if cfg.Telemetry.Enabled {
+		telemetrySvc := telemetry.NewService(log, cfg, st, Version)
+
+		if err := telemetrySvc.Start(ctx); err != nil {
+			return err
+		}
+
+		defer func() {
+			if err := telemetrySvc.Stop(); err != nil {
+				log.WithError(err).Warn("Failed to stop telemetry exporter")
+			}
+		}()
+	}
+
+	// Deliver job transitions to user subscriptions.
+	notifySvc := notify.NewService(log, cfg, st)
+
+	if err := notifySvc.Start(ctx); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := notifySvc.Stop(); err != nil {
+			log.WithError(err).Warn("Failed to stop notification service")
+		}
+	}()
+
 	// Create and start auth service.
 	authSvc := auth.NewService(log, cfg, st)
 
@@ -183,17 +308,136 @@ func runServer(ctx context.Context, log *logrus.Logger, configPath string) error
 	// Create and start API server.
 	srv := api.NewServer(log, cfg, configPath, st, queueSvc, authSvc, runnersClient, dispatchClient, m)
 
+	queueSvc.SetJobTransitionCallback(func(job *store.Job, from store.JobStatus) {
+		notifySvc.JobTransition(job, from)
+		srv.JobTransition(job, from)
+	})
+
+	onJobChange := func(job *store.Job) {
+		srv.BroadcastJobChange(job)
+
+		if disp != nil && cfg.Dispatcher.EventDriven && job.Status != store.JobStatusTriggered {
+			disp.Trigger()
+		}
+	}
+
+	onRunnerChange := func(runner *store.Runner) {
+		srv.BroadcastRunnerChange(runner)
+
+		if disp != nil && cfg.Dispatcher.EventDriven && runner.Status == store.RunnerStatusOnline && !runner.Busy {
+			disp.Trigger()
+		}
+	}
+
+	// Share changes with other replicas on the same PostgreSQL database.
+	publish := func(pgnotify.Kind, string) {}
+
+	if cfg.Database.Notify.Enabled {
+		notifier := pgnotify.NewService(log, cfg)
+		notifier.SetEventCallback(func(event pgnotify.Event) {
+			handleReplicaEvent(ctx, log, st, cache, srv, settingsSvc, onJobChange, onRunnerChange, event)
+		})
+
+		if err := notifier.Start(ctx); err != nil {
+			return err
+		}
+
+		defer func() {
+			if err := notifier.Stop(); err != nil {
+				log.WithError(err).Warn("Failed to stop replica notifications")
+			}
+		}()
+
+		publish = notifier.Publish
+
+		if cache != nil {
+			cache.SetChangeCallback(func() { publish(pgnotify.KindCache, "") })
+		}
+
+		settingsSvc.SetUpdateCallback(func() { publish(pgnotify.KindSettings, "") })
+	}
+
+	// Broadcast job changes via WebSocket. With event driven dispatch, any
+	// change except the dispatcher's own trigger may let another job start.
+	queueSvc.SetJobChangeCallback(func(job *store.Job) {
+		onJobChange(job)
+		publish(pgnotify.KindJob, job.ID)
+	})
+
 	// Set up runner change callbacks to broadcast via WebSocket.
 	if poller != nil {
 		poller.SetRunnerChangeCallback(func(runner *store.Runner) {
-			srv.BroadcastRunnerChange(runner)
+			onRunnerChange(runner)
+			publish(pgnotify.KindRunner, strconv.FormatInt(runner.ID, 10))
 		})
 	}
 
+	if disp != nil {
+		srv.SetDispatchTrigger(disp.Trigger)
+		srv.SetRunEventHandler(disp)
+	}
+
+	srv.SetSettings(settingsSvc)
+
+	starvationSvc.SetStarvedCallback(func(group *store.Group, job *store.Job, age time.Duration) {
+		srv.BroadcastGroupStarved(group, job, age)
+	})
+
 	if disp != nil {
 		disp.SetRunnerChangeCallback(func(runner *store.Runner) {
 			srv.BroadcastRunnerChange(runner)
+			publish(pgnotify.KindRunner, strconv.FormatInt(runner.ID, 10))
 		})
+
+		disp.SetGroupChangeCallback(func(group *store.Group) {
+			srv.BroadcastGroupChange(group)
+			publish(pgnotify.KindGroup, group.ID)
+		})
+
+		disp.SetJobStalledCallback(func(job *store.Job, idle time.Duration) {
+			srv.BroadcastJobStalled(job, idle)
+		})
+	}
+
+	// Vacuum and analyze the database on a schedule.
+	if cfg.Database.Maintenance.Enabled {
+		maintenanceSvc := maintenance.NewService(log, cfg, st)
+
+		if err := maintenanceSvc.Start(ctx); err != nil {
+			return err
+		}
+
+		defer func() {
+			if err := maintenanceSvc.Stop(); err != nil {
+				log.WithError(err).Warn("Failed to stop database maintenance scheduler")
+			}
+		}()
+
+		srv.SetMaintenanceStatus(maintenanceSvc.Status)
+	}
+
+	// Export table row counts and sizes as metrics.
+	if cfg.Database.StatsInterval > 0 {
+		statsSampler := maintenance.NewStatsSampler(log, cfg, st, m)
+
+		if err := statsSampler.Start(ctx); err != nil {
+			return err
+		}
+
+		defer func() {
+			if err := statsSampler.Stop(); err != nil {
+				log.WithError(err).Warn("Failed to stop database stats sampler")
+			}
+		}()
+	}
+
+	// Verify token permissions up front so problems show up in logs and /status
+	// rather than at the first dispatch.
+	checks, err := github.CheckPermissions(ctx, log, st, runnersClient, dispatchClient)
+	if err != nil {
+		log.WithError(err).Warn("Failed to run permission checks")
+	} else {
+		srv.SetPermissionChecks(checks)
 	}
 
 	if err := srv.Start(ctx); err != nil {
@@ -219,3 +463,83 @@ func runServer(ctx context.Context, log *logrus.Logger, configPath string) error
 
 	return nil
 }
+
+// handleReplicaEvent applies a change announced by another replica: caches are
+// dropped and the changed job, runner or group is loaded and broadcast to this
+// replica's WebSocket clients like a local change.
+func handleReplicaEvent(
+	ctx context.Context,
+	log logrus.FieldLogger,
+	st store.Store,
+	cache *store.CachedStore,
+	srv api.Server,
+	settingsSvc settings.Service,
+	onJobChange func(*store.Job),
+	onRunnerChange func(*store.Runner),
+	event pgnotify.Event,
+) {
+	log = log.WithFields(logrus.Fields{"kind": event.Kind, "id": event.ID})
+
+	switch event.Kind {
+	case pgnotify.KindCache, pgnotify.KindResync:
+		if cache != nil {
+			cache.Invalidate()
+		}
+
+		// A resync may have missed a settings change too.
+		if event.Kind == pgnotify.KindResync {
+			if err := settingsSvc.Reload(ctx); err != nil {
+				log.WithError(err).Warn("Failed to reload runtime settings")
+			}
+		}
+	case pgnotify.KindSettings:
+		if err := settingsSvc.Reload(ctx); err != nil {
+			log.WithError(err).Warn("Failed to reload runtime settings from replica notification")
+		}
+	case pgnotify.KindJob:
+		job, err := st.GetJob(ctx, event.ID)
+		if err != nil {
+			log.WithError(err).Warn("Failed to load job from replica notification")
+
+			return
+		}
+
+		if job != nil {
+			onJobChange(job)
+		}
+	case pgnotify.KindRunner:
+		id, err := strconv.ParseInt(event.ID, 10, 64)
+		if err != nil {
+			log.Warn("Ignoring replica notification with invalid runner ID")
+
+			return
+		}
+
+		runner, err := st.GetRunner(ctx, id)
+		if err != nil {
+			log.WithError(err).Warn("Failed to load runner from replica notification")
+
+			return
+		}
+
+		if runner != nil {
+			onRunnerChange(runner)
+		}
+	case pgnotify.KindGroup:
+		// Group state changes are writes another replica's cache saw, not ours.
+		if cache != nil {
+			cache.Invalidate()
+		}
+
+		group, err := st.GetGroup(ctx, event.ID)
+		if err != nil {
+			log.WithError(err).Warn("Failed to load group from replica notification")
+
+			return
+		}
+
+		if group != nil {
+			srv.BroadcastGroupChange(group)
+		}
+	}
+}
@@ -3,8 +3,11 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -13,9 +16,14 @@ import (
 	"github.com/ethpandaops/dispatchoor/pkg/api/docs"
 	"github.com/ethpandaops/dispatchoor/pkg/auth"
 	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/dispatcher"
 	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/lint"
+	"github.com/ethpandaops/dispatchoor/pkg/maintenance"
 	"github.com/ethpandaops/dispatchoor/pkg/metrics"
 	"github.com/ethpandaops/dispatchoor/pkg/queue"
+	"github.com/ethpandaops/dispatchoor/pkg/settings"
 	"github.com/ethpandaops/dispatchoor/pkg/store"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -29,6 +37,16 @@ type Server interface {
 	Start(ctx context.Context) error
 	Stop() error
 	BroadcastRunnerChange(runner *store.Runner)
+	BroadcastJobChange(job *store.Job)
+	BroadcastGroupChange(group *store.Group)
+	BroadcastGroupStarved(group *store.Group, job *store.Job, age time.Duration)
+	BroadcastJobStalled(job *store.Job, idle time.Duration)
+	SetPermissionChecks(checks []*github.PermissionCheck)
+	SetDispatchTrigger(fn func())
+	SetRunEventHandler(h RunEventHandler)
+	JobTransition(job *store.Job, from store.JobStatus)
+	SetMaintenanceStatus(fn func() maintenance.Status)
+	SetSettings(svc settings.Service)
 }
 
 // server implements Server.
@@ -46,11 +64,40 @@ type server struct {
 	hub            *Hub
 	srv            *http.Server
 	router         chi.Router
+	metricsSrv     *http.Server
+
+	// permissionChecks holds the results of the startup permission checks.
+	permissionChecksMu sync.RWMutex
+	permissionChecks   []*github.PermissionCheck
+
+	// dispatchTrigger runs a dispatch cycle when a webhook frees a runner.
+	dispatchTrigger func()
+	// runEvents applies workflow run webhooks to tracked jobs.
+	runEvents RunEventHandler
+
+	// maintenanceStatus reports periodic database maintenance in /status (nil
+	// when maintenance is disabled).
+	maintenanceStatus func() maintenance.Status
+
+	// settings serves the runtime-tunable settings (nil until set).
+	settings settings.Service
 
 	// Rate limiters for different endpoint tiers.
 	authRateLimiter          *IPRateLimiter
 	publicRateLimiter        *IPRateLimiter
 	authenticatedRateLimiter *IPRateLimiter
+
+	// campaignUpdates holds the scheduled campaign_update broadcasts by campaign ID.
+	campaignUpdatesMu sync.Mutex
+	campaignUpdates   map[string]*time.Timer
+
+	// stagedSync is the config reload waiting for approval when
+	// sync.require_approval is enabled.
+	stagedSyncMu sync.Mutex
+	stagedSync   *StagedConfigSync
+
+	// gitRefresh requests a reload of templates from git sources.
+	gitRefresh chan struct{}
 }
 
 // Ensure server implements Server.
@@ -71,25 +118,33 @@ func NewServer(log logrus.FieldLogger, cfg *config.Config, configPath string, st
 		dispatchClient: dispatchClient,
 		metrics:        m,
 		hub:            hub,
+
+		campaignUpdates: make(map[string]*time.Timer),
+		gitRefresh:      make(chan struct{}, 1),
 	}
 
 	// Initialize rate limiters if enabled.
 	if cfg.Server.RateLimit.Enabled {
-		s.authRateLimiter = NewIPRateLimiter(cfg.Server.RateLimit.Auth.RequestsPerMinute)
-		s.publicRateLimiter = NewIPRateLimiter(cfg.Server.RateLimit.Public.RequestsPerMinute)
-		s.authenticatedRateLimiter = NewIPRateLimiter(cfg.Server.RateLimit.Authenticated.RequestsPerMinute)
+		// The whitelist is validated when the config is loaded.
+		whitelist, _ := cfg.Server.RateLimit.WhitelistPrefixes()
+
+		s.authRateLimiter = NewIPRateLimiter(cfg.Server.RateLimit.Auth, whitelist)
+		s.publicRateLimiter = NewIPRateLimiter(cfg.Server.RateLimit.Public, whitelist)
+		s.authenticatedRateLimiter = NewIPRateLimiter(cfg.Server.RateLimit.Authenticated, whitelist)
 
 		log.WithFields(logrus.Fields{
 			"auth_rpm":          cfg.Server.RateLimit.Auth.RequestsPerMinute,
 			"public_rpm":        cfg.Server.RateLimit.Public.RequestsPerMinute,
 			"authenticated_rpm": cfg.Server.RateLimit.Authenticated.RequestsPerMinute,
+			"whitelist":         len(whitelist),
 		}).Info("Rate limiting enabled")
 	}
 
-	// Set up callback to broadcast job state changes via WebSocket.
-	q.SetJobChangeCallback(func(job *store.Job) {
-		hub.BroadcastJobState(job)
-	})
+	hub.SetAccessControl(s.canViewGroup, s.redactMessage)
+
+	if c := cfg.Server.Compression; c.WebSocket {
+		hub.SetCompression(c.Level, c.MinSize)
+	}
 
 	s.setupRouter()
 
@@ -98,26 +153,87 @@ func NewServer(log logrus.FieldLogger, cfg *config.Config, configPath string, st
 
 // Start starts the HTTP server.
 func (s *server) Start(ctx context.Context) error {
+	timeouts := s.cfg.Server.Timeouts
+
 	s.srv = &http.Server{
 		Addr:              s.cfg.Server.Listen,
 		Handler:           s.router,
-		ReadHeaderTimeout: 10 * time.Second,
+		ReadHeaderTimeout: timeouts.ReadHeader,
+		ReadTimeout:       timeouts.Read,
+		WriteTimeout:      timeouts.Write,
+		IdleTimeout:       timeouts.Idle,
+	}
+
+	var reloader *tlsReloader
+
+	if s.cfg.Server.TLS.Enabled() {
+		var err error
+
+		reloader, err = newTLSReloader(s.log, s.cfg.Server.TLS)
+		if err != nil {
+			return fmt.Errorf("setting up TLS: %w", err)
+		}
+
+		s.srv.TLSConfig = reloader.tlsConfig()
+
+		go reloader.watch(ctx)
 	}
 
-	s.log.WithField("addr", s.cfg.Server.Listen).Info("Starting API server")
+	s.log.WithFields(logrus.Fields{
+		"addr": s.cfg.Server.Listen,
+		"tls":  reloader != nil,
+		"mtls": s.cfg.Server.TLS.ClientCAFile != "",
+	}).Info("Starting API server")
 
 	// Start WebSocket hub.
 	go s.hub.Run(ctx)
 
+	go s.gitRefreshLoop(ctx, s.cfg.Sync.GitRefreshInterval)
+
 	go func() {
-		if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if reloader != nil {
+			// Certificates come from TLSConfig.
+			err = s.srv.ListenAndServeTLS("", "")
+		} else {
+			err = s.srv.ListenAndServe()
+		}
+
+		if err != nil && err != http.ErrServerClosed {
 			s.log.WithError(err).Error("Server error")
 		}
 	}()
 
+	if s.cfg.Server.MetricsListen != "" {
+		s.metricsSrv = &http.Server{
+			Addr:              s.cfg.Server.MetricsListen,
+			Handler:           s.metricsRouter(),
+			ReadHeaderTimeout: timeouts.ReadHeader,
+		}
+
+		s.log.WithField("addr", s.cfg.Server.MetricsListen).Info("Starting metrics server")
+
+		go func() {
+			if err := s.metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				s.log.WithError(err).Error("Metrics server error")
+			}
+		}()
+	}
+
 	return nil
 }
 
+// metricsRouter returns the handler for the metrics listener. It is not rate
+// limited so scrapes and probes are never rejected.
+func (s *server) metricsRouter() http.Handler {
+	r := chi.NewRouter()
+	r.Use(middleware.Recoverer)
+	r.Get("/health", s.handleHealth)
+	r.Handle("/metrics", promhttp.Handler())
+
+	return r
+}
+
 // Stop gracefully shuts down the HTTP server.
 func (s *server) Stop() error {
 	if s.srv == nil {
@@ -126,12 +242,68 @@ func (s *server) Stop() error {
 
 	s.log.Info("Stopping API server")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Server.Timeouts.Shutdown)
 	defer cancel()
 
+	// Hijacked WebSocket connections are not covered by http.Server.Shutdown,
+	// so close them first with a reconnect hint.
+	s.hub.Shutdown(ctx)
+
+	if s.metricsSrv != nil {
+		if err := s.metricsSrv.Shutdown(ctx); err != nil {
+			s.log.WithError(err).Warn("Failed to stop metrics server")
+		}
+	}
+
 	return s.srv.Shutdown(ctx)
 }
 
+// BroadcastJobChange broadcasts a job state change to the job's group
+// subscribers, and the progress of the job's campaign to all clients.
+func (s *server) BroadcastJobChange(job *store.Job) {
+	s.hub.BroadcastJobState(job)
+
+	if job.CampaignID != "" {
+		s.scheduleCampaignUpdate(job.CampaignID)
+	}
+}
+
+// BroadcastGroupChange broadcasts a group state change to the group's subscribers.
+func (s *server) BroadcastGroupChange(group *store.Group) {
+	s.hub.BroadcastGroupState(group)
+}
+
+// BroadcastGroupStarved notifies the group's subscribers that its oldest
+// pending job exceeded the starvation threshold.
+func (s *server) BroadcastGroupStarved(group *store.Group, job *store.Job, age time.Duration) {
+	s.hub.BroadcastGroupStarved(group, job, age)
+}
+
+// BroadcastJobStalled notifies the job's group subscribers that it was
+// flagged as stalled, followed by its new state.
+func (s *server) BroadcastJobStalled(job *store.Job, idle time.Duration) {
+	s.hub.BroadcastJobStalled(job, idle)
+	s.hub.BroadcastJobState(job)
+}
+
+// SetPermissionChecks records startup permission check results for /status.
+func (s *server) SetPermissionChecks(checks []*github.PermissionCheck) {
+	s.permissionChecksMu.Lock()
+	defer s.permissionChecksMu.Unlock()
+
+	s.permissionChecks = checks
+}
+
+// SetMaintenanceStatus sets the function reporting database maintenance for /status.
+func (s *server) SetMaintenanceStatus(fn func() maintenance.Status) {
+	s.maintenanceStatus = fn
+}
+
+// SetSettings sets the runtime settings served by /admin/settings.
+func (s *server) SetSettings(svc settings.Service) {
+	s.settings = svc
+}
+
 // BroadcastRunnerChange broadcasts a runner status change to all matching groups.
 func (s *server) BroadcastRunnerChange(runner *store.Runner) {
 	s.cfgMu.RLock()
@@ -167,8 +339,16 @@ func (s *server) setupRouter() {
 
 	// Middleware.
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
+	// Proxies are validated when the config is loaded.
+	trustedProxies, _ := s.cfg.Server.TrustedProxyPrefixes()
+	r.Use(realIPMiddleware(trustedProxies))
+	r.Use(auth.ClientInfoMiddleware)
+	r.Use(s.requestLogger())
+
+	if c := s.cfg.Server.Compression; c.Enabled {
+		r.Use(compressMiddleware(c.Level, c.MinSize))
+	}
+
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(60 * time.Second))
 
@@ -186,8 +366,10 @@ func (s *server) setupRouter() {
 		// Health check (public).
 		r.Get("/health", s.handleHealth)
 
-		// Metrics endpoint (public).
-		r.Handle("/metrics", promhttp.Handler())
+		// Metrics endpoint (public), unless it has its own listener.
+		if s.cfg.Server.MetricsListen == "" {
+			r.Handle("/metrics", promhttp.Handler())
+		}
 	})
 
 	// API v1.
@@ -198,6 +380,17 @@ func (s *server) setupRouter() {
 				r.Use(s.publicRateLimiter.Middleware)
 			}
 			r.Get("/openapi.json", s.handleOpenAPISpec)
+
+			// GitHub webhooks (authenticated by signature).
+			if s.cfg.GitHub.WebhookSecret != "" {
+				r.Post("/webhooks/github", s.handleGitHubWebhook)
+			}
+
+			// Workflow progress reports and heartbeats (authenticated by API token).
+			if len(s.cfg.Auth.APITokens) > 0 {
+				r.With(s.apiTokenMiddleware).Post("/jobs/{id}/progress", s.handleReportJobProgress)
+				r.With(s.apiTokenMiddleware).Post("/jobs/{id}/heartbeat", s.handleJobHeartbeat)
+			}
 		})
 
 		// Auth routes with strict rate limit.
@@ -209,6 +402,7 @@ func (s *server) setupRouter() {
 			r.Get("/auth/github", s.handleGitHubAuth)
 			r.Get("/auth/github/callback", s.handleGitHubCallback)
 			r.Post("/auth/exchange", s.handleExchangeCode)
+			r.Post("/auth/refresh", s.handleRefresh)
 		})
 
 		// WebSocket (authentication handled in handler, uses authenticated rate limit).
@@ -222,6 +416,9 @@ func (s *server) setupRouter() {
 		// Protected routes with authenticated rate limit.
 		r.Group(func(r chi.Router) {
 			r.Use(auth.AuthMiddleware(s.auth))
+			r.Use(s.csrfMiddleware)
+			r.Use(s.impersonationMiddleware)
+			r.Use(annotateRequestLog)
 			if s.authenticatedRateLimiter != nil {
 				r.Use(s.authenticatedRateLimiter.Middleware)
 			}
@@ -229,6 +426,19 @@ func (s *server) setupRouter() {
 			// Auth (authenticated).
 			r.Post("/auth/logout", s.handleLogout)
 			r.Get("/auth/me", s.handleMe)
+			r.Get("/auth/sessions", s.handleListSessions)
+			r.Delete("/auth/sessions/{id}", s.handleRevokeSession)
+
+			// Saved filters (per user).
+			r.Get("/filters", s.handleListSavedFilters)
+			r.Post("/filters", s.handleCreateSavedFilter)
+			r.Put("/filters/{id}", s.handleUpdateSavedFilter)
+			r.Delete("/filters/{id}", s.handleDeleteSavedFilter)
+
+			// Notification subscriptions (per user).
+			r.Get("/subscriptions", s.handleListSubscriptions)
+			r.Post("/subscriptions", s.handleCreateSubscription)
+			r.Delete("/subscriptions/{id}", s.handleDeleteSubscription)
 
 			// Groups (read-only).
 			r.Get("/groups", s.handleListGroups)
@@ -237,14 +447,29 @@ func (s *server) setupRouter() {
 			// Job templates (read-only).
 			r.Get("/groups/{id}/templates", s.handleListJobTemplates)
 			r.Get("/templates/{id}", s.handleGetJobTemplate)
+			r.Get("/templates/{id}/lint", s.handleLintJobTemplate)
 
 			// Queue (read-only).
 			r.Get("/groups/{id}/queue", s.handleGetQueue)
+			r.Get("/groups/{id}/queue/changes", s.handleGetQueueChanges)
+			r.Get("/groups/{id}/queue/stats", s.handleGetQueueStats)
 			r.Get("/groups/{id}/history", s.handleGetHistory)
 			r.Get("/groups/{id}/history/stats", s.handleGetHistoryStats)
 
 			// Jobs (read-only).
+			r.Get("/jobs/compare", s.handleCompareJobs)
 			r.Get("/jobs/{id}", s.handleGetJob)
+			r.Get("/jobs/{id}/annotations", s.handleGetJobAnnotations)
+			r.Get("/jobs/{id}/timeline", s.handleGetJobTimeline)
+			r.Get("/jobs/{id}/attempts", s.handleGetJobAttempts)
+
+			// Job assignment (per user; admins may assign anyone).
+			r.Post("/jobs/{id}/assign", s.handleAssignJob)
+			r.Post("/jobs/{id}/unassign", s.handleUnassignJob)
+
+			// Campaigns (read-only).
+			r.Get("/campaigns", s.handleListCampaigns)
+			r.Get("/campaigns/{id}", s.handleGetCampaign)
 
 			// Runners (read-only).
 			r.Get("/groups/{id}/runners", s.handleGetRunners)
@@ -252,6 +477,8 @@ func (s *server) setupRouter() {
 
 			// System (read-only).
 			r.Get("/status", s.handleStatus)
+			r.Get("/overview", s.handleGetOverview)
+			r.Get("/search", s.handleSearch)
 
 			// Admin-only routes.
 			r.Group(func(r chi.Router) {
@@ -260,29 +487,74 @@ func (s *server) setupRouter() {
 				// Group management (admin).
 				r.Post("/groups/{id}/pause", s.handlePauseGroup)
 				r.Post("/groups/{id}/unpause", s.handleUnpauseGroup)
+				r.Post("/groups/{id}/archive", s.handleArchiveGroup)
+				r.Post("/groups/{id}/unarchive", s.handleUnarchiveGroup)
 
 				// Queue management (admin).
 				r.Post("/groups/{id}/queue", s.handleAddJob)
 				r.Put("/groups/{id}/queue/reorder", s.handleReorderQueue)
+				r.Post("/groups/{id}/queue/compact", s.handleCompactQueue)
+
+				// History management (admin).
+				r.Post("/groups/{id}/history/restore", s.handleRestoreJob)
 
 				// Job management (admin).
 				r.Put("/jobs/{id}", s.handleUpdateJob)
+				r.Put("/jobs/{id}/notify-targets", s.handleSetJobNotifyTargets)
 				r.Delete("/jobs/{id}", s.handleDeleteJob)
 				r.Post("/jobs/{id}/pause", s.handlePauseJob)
 				r.Post("/jobs/{id}/unpause", s.handleUnpauseJob)
 				r.Post("/jobs/{id}/cancel", s.handleCancelJob)
 				r.Post("/jobs/{id}/disable-requeue", s.handleDisableAutoRequeue)
 				r.Put("/jobs/{id}/auto-requeue", s.handleUpdateAutoRequeue)
+				r.Post("/jobs/{id}/requeue", s.handleRequeueJob)
+				r.Patch("/jobs/{id}/owner", s.handleUpdateJobOwner)
+				r.Post("/jobs/{id}/retain", s.handleRetainJob)
+				r.Post("/jobs/{id}/unretain", s.handleUnretainJob)
+
+				// Campaign management (admin).
+				r.Post("/campaigns", s.handleCreateCampaign)
+				r.Post("/campaigns/{id}/pause", s.handlePauseCampaign)
+				r.Post("/campaigns/{id}/unpause", s.handleUnpauseCampaign)
+				r.Post("/campaigns/{id}/cancel", s.handleCancelCampaign)
 
 				// Runner refresh (admin).
 				r.Post("/runners/refresh", s.handleRefreshRunners)
 
+				// Dispatch debugging (admin).
+				r.Get("/admin/matching-report", s.handleMatchingReport)
+
+				// Database size (admin).
+				r.Get("/admin/database", s.handleGetDatabaseStats)
+
+				// Runtime settings (admin).
+				r.Get("/admin/settings", s.handleGetSettings)
+				r.Put("/admin/settings", s.handleUpdateSettings)
+
+				// Template cloning and bulk edits (admin).
+				r.Post("/templates/{id}/clone", s.handleCloneJobTemplate)
+				r.Delete("/templates/{id}", s.handleDeleteJobTemplate)
+				r.Post("/templates/bulk-edit", s.handleBulkEditJobTemplates)
+
 				// Template reload (admin).
 				r.Post("/templates/reload", s.handleReloadTemplates)
+				r.Get("/config/sync", s.handleGetStagedConfigSync)
+				r.Post("/config/sync/{id}/approve", s.handleApproveConfigSync)
+				r.Post("/config/sync/{id}/reject", s.handleRejectConfigSync)
 			})
 		})
 	})
 
+	// Web UI (public; the UI authenticates against the API itself).
+	if h := s.uiHandler(); h != nil {
+		r.Group(func(r chi.Router) {
+			if s.publicRateLimiter != nil {
+				r.Use(s.publicRateLimiter.Middleware)
+			}
+			r.Handle("/*", h)
+		})
+	}
+
 	s.router = r
 }
 
@@ -300,8 +572,8 @@ func corsMiddleware(origins []string) func(http.Handler) http.Handler {
 
 			if allowAll || originSet[origin] {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+csrfHeaderName+", "+actAsHeaderName)
 				w.Header().Set("Access-Control-Allow-Credentials", "true")
 			}
 
@@ -325,15 +597,40 @@ type ErrorResponse struct {
 	Error string `json:"error" example:"Something went wrong"`
 }
 
+// GroupResponse is a group with its description rendered for display.
+type GroupResponse struct {
+	*store.Group
+	// DescriptionHTML is the markdown description rendered as HTML, without
+	// raw HTML, images or unsafe links.
+	DescriptionHTML string `json:"description_html,omitempty" example:"<p>Ethereum sync testing jobs</p>"`
+}
+
+// newGroupResponse renders a group's description for display.
+func newGroupResponse(group *store.Group) GroupResponse {
+	return GroupResponse{Group: group, DescriptionHTML: renderMarkdown(group.Description)}
+}
+
 // GroupWithStats is a group with additional statistics.
 type GroupWithStats struct {
-	*store.Group
+	GroupResponse
 	QueuedJobs    int `json:"queued_jobs" example:"5"`
 	RunningJobs   int `json:"running_jobs" example:"2"`
 	IdleRunners   int `json:"idle_runners" example:"3"`
 	BusyRunners   int `json:"busy_runners" example:"2"`
 	TotalRunners  int `json:"total_runners" example:"5"`
 	TemplateCount int `json:"template_count" example:"10"`
+	// Age of the oldest unpaused pending job; 0 when nothing is waiting.
+	OldestPendingAgeSeconds int64  `json:"oldest_pending_age_seconds" example:"120"`
+	OldestPendingJobID      string `json:"oldest_pending_job_id,omitempty"`
+	// Starved is set when the oldest pending age exceeds queue.starvation.threshold.
+	Starved bool `json:"starved"`
+	// OnlineRunners counts matching runners that are online, busy or not.
+	OnlineRunners int `json:"online_runners" example:"5"`
+	// Degraded is set while fewer runners are online than min_online_runners,
+	// which holds dispatch for the group.
+	Degraded bool `json:"degraded"`
+	// Last24h counts the jobs that finished in the last 24 hours.
+	Last24h HistoryStatsTotals `json:"last_24h"`
 }
 
 func (s *server) writeJSON(w http.ResponseWriter, status int, data any) {
@@ -424,7 +721,25 @@ func (s *server) handleHealth(w http.ResponseWriter, _ *http.Request) {
 //	@Router			/status [get]
 func (s *server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	resp := s.systemStatus(ctx)
+
+	// Queue statistics.
+	pendingJobs, _ := s.store.ListJobsByStatus(ctx, store.JobStatusPending)
+	triggeredJobs, _ := s.store.ListJobsByStatus(ctx, store.JobStatusTriggered)
+	runningJobs, _ := s.store.ListJobsByStatus(ctx, store.JobStatusRunning)
+
+	resp.Queue = QueueStats{
+		PendingJobs:   len(pendingJobs),
+		TriggeredJobs: len(triggeredJobs),
+		RunningJobs:   len(runningJobs),
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
 
+// systemStatus checks the database, GitHub clients and startup permissions.
+// Queue statistics are left for the caller.
+func (s *server) systemStatus(ctx context.Context) SystemStatusResponse {
 	// Initialize response with current timestamp.
 	resp := SystemStatusResponse{
 		Status:    ComponentStatusHealthy,
@@ -451,6 +766,16 @@ func (s *server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if s.maintenanceStatus != nil {
+		resp.Database.Maintenance = newMaintenanceStatus(s.maintenanceStatus())
+
+		// A failing maintenance run degrades the database over time rather than
+		// immediately, so it is reported without marking the database unhealthy.
+		if resp.Database.Maintenance.Status != ComponentStatusHealthy && resp.Status == ComponentStatusHealthy {
+			resp.Status = ComponentStatusDegraded
+		}
+	}
+
 	// GitHub connection and rate limit info for both clients.
 	resp.GitHub = GitHubClientsStatus{}
 
@@ -509,15 +834,35 @@ func (s *server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Queue statistics.
-	pendingJobs, _ := s.store.ListJobsByStatus(ctx, store.JobStatusPending)
-	triggeredJobs, _ := s.store.ListJobsByStatus(ctx, store.JobStatusTriggered)
-	runningJobs, _ := s.store.ListJobsByStatus(ctx, store.JobStatusRunning)
+	// Startup permission checks.
+	s.permissionChecksMu.RLock()
+	for _, check := range s.permissionChecks {
+		resp.Permissions = append(resp.Permissions, PermissionStatus{
+			Target:     check.Target,
+			Permission: check.Permission,
+			OK:         check.OK,
+			Error:      check.Error,
+			Templates:  check.Templates,
+		})
 
-	resp.Queue = QueueStats{
-		PendingJobs:   len(pendingJobs),
-		TriggeredJobs: len(triggeredJobs),
-		RunningJobs:   len(runningJobs),
+		if !check.OK && resp.Status == ComponentStatusHealthy {
+			resp.Status = ComponentStatusDegraded
+		}
+	}
+	s.permissionChecksMu.RUnlock()
+
+	// Groups whose runner pools fell below min_online_runners.
+	pools, err := s.runnerPoolStatuses(ctx)
+	if err != nil {
+		s.log.WithError(err).Warn("Failed to check runner pools")
+	}
+
+	for _, pool := range pools {
+		resp.RunnerPools = append(resp.RunnerPools, pool)
+
+		if pool.Status != ComponentStatusHealthy && resp.Status == ComponentStatusHealthy {
+			resp.Status = ComponentStatusDegraded
+		}
 	}
 
 	// Version info.
@@ -527,22 +872,92 @@ func (s *server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		BuildDate: "unknown",
 	}
 
-	s.writeJSON(w, http.StatusOK, resp)
+	return resp
+}
+
+// runnerPoolStatuses checks the runner pool of every active group that sets
+// min_online_runners.
+func (s *server) runnerPoolStatuses(ctx context.Context) ([]RunnerPoolStatus, error) {
+	groups, err := s.store.ListGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing groups: %w", err)
+	}
+
+	var watched []*store.Group
+
+	for _, group := range groups {
+		if !group.Archived && group.Enabled && group.MinOnlineRunners > 0 {
+			watched = append(watched, group)
+		}
+	}
+
+	if len(watched) == 0 {
+		return nil, nil
+	}
+
+	runners, err := s.store.ListRunners(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing runners: %w", err)
+	}
+
+	pools := make([]RunnerPoolStatus, 0, len(watched))
+
+	for _, group := range watched {
+		pool := RunnerPoolStatus{
+			GroupID:          group.ID,
+			Status:           ComponentStatusHealthy,
+			MinOnlineRunners: group.MinOnlineRunners,
+		}
+
+		for _, runner := range runners {
+			if runner.Status == store.RunnerStatusOnline && runnerMatchesLabels(runner.Labels, group.RunnerLabels) {
+				pool.OnlineRunners++
+			}
+		}
+
+		if dispatcher.RunnerPoolShort(group, pool.OnlineRunners) {
+			pool.Status = ComponentStatusDegraded
+		}
+
+		pools = append(pools, pool)
+	}
+
+	return pools, nil
 }
 
 // handleListGroups godoc
 //
 //	@Summary		List groups
-//	@Description	Returns all configured groups with statistics
+//	@Description	Returns all configured groups with statistics. Archived groups are omitted unless include_archived=true.
 //	@Tags			groups
 //	@Security		BearerAuth
 //	@Produce		json
+//	@Param			include_archived	query		bool	false	"Include archived groups"
 //	@Success		200	{array}		GroupWithStats
 //	@Failure		401	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
 //	@Router			/groups [get]
 func (s *server) handleListGroups(w http.ResponseWriter, r *http.Request) {
-	groups, err := s.store.ListGroups(r.Context())
+	includeArchived := r.URL.Query().Get("include_archived") == "true"
+	now := time.Now()
+
+	counts, err := s.store.GetGroupJobCounts(r.Context(), now.Add(-overviewHistoryWindow))
+	if err != nil {
+		s.log.WithError(err).Error("Failed to count group jobs")
+		s.writeError(w, http.StatusInternalServerError, "Failed to list groups")
+
+		return
+	}
+
+	runners, err := s.store.ListRunners(r.Context())
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list runners")
+		s.writeError(w, http.StatusInternalServerError, "Failed to list groups")
+
+		return
+	}
+
+	result, err := s.groupsWithStats(r.Context(), includeArchived, counts, runners, now)
 	if err != nil {
 		s.log.WithError(err).Error("Failed to list groups")
 		s.writeError(w, http.StatusInternalServerError, "Failed to list groups")
@@ -550,38 +965,80 @@ func (s *server) handleListGroups(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// groupsWithStats adds job, runner and template counts to the groups, from
+// aggregate job counts and the full runner list rather than per-group queries.
+func (s *server) groupsWithStats(
+	ctx context.Context,
+	includeArchived bool,
+	counts []*store.GroupJobCounts,
+	runners []*store.Runner,
+	now time.Time,
+) ([]GroupWithStats, error) {
+	groups, err := s.store.ListGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing groups: %w", err)
+	}
+
+	s.cfgMu.RLock()
+	threshold := s.cfg.Queue.Starvation.Threshold
+	s.cfgMu.RUnlock()
+
+	byGroup := make(map[string]*store.GroupJobCounts, len(counts))
+	for _, count := range counts {
+		byGroup[count.GroupID] = count
+	}
+
 	result := make([]GroupWithStats, 0, len(groups))
 
 	for _, group := range groups {
-		stats := GroupWithStats{Group: group}
-
-		// Get job counts.
-		pendingJobs, err := s.store.ListJobsByGroup(r.Context(), group.ID, store.JobStatusPending)
-		if err == nil {
-			stats.QueuedJobs = len(pendingJobs)
+		if group.Archived && !includeArchived {
+			continue
 		}
 
-		runningJobs, err := s.store.ListJobsByGroup(r.Context(), group.ID, store.JobStatusTriggered, store.JobStatusRunning)
-		if err == nil {
-			stats.RunningJobs = len(runningJobs)
+		stats := GroupWithStats{GroupResponse: newGroupResponse(group)}
+
+		if count := byGroup[group.ID]; count != nil {
+			stats.QueuedJobs = count.Pending
+			stats.RunningJobs = count.Triggered + count.Running
+			stats.Last24h = HistoryStatsTotals{
+				Completed: count.Completed,
+				Failed:    count.Failed,
+				Cancelled: count.Cancelled,
+			}
+
+			if oldest := count.OldestPending; oldest != nil {
+				age := now.Sub(oldest.CreatedAt)
+				stats.OldestPendingAgeSeconds = int64(age.Seconds())
+				stats.OldestPendingJobID = oldest.ID
+				stats.Starved = threshold > 0 && age >= threshold
+			}
 		}
 
-		// Get runner counts.
-		runners, err := s.store.ListRunnersByLabels(r.Context(), group.RunnerLabels)
-		if err == nil {
-			for _, runner := range runners {
-				stats.TotalRunners++
+		for _, runner := range runners {
+			if !runnerMatchesLabels(runner.Labels, group.RunnerLabels) {
+				continue
+			}
 
-				if runner.Busy {
-					stats.BusyRunners++
-				} else if runner.Status == store.RunnerStatusOnline {
-					stats.IdleRunners++
-				}
+			stats.TotalRunners++
+
+			if runner.Status == store.RunnerStatusOnline {
+				stats.OnlineRunners++
+			}
+
+			if runner.Busy {
+				stats.BusyRunners++
+			} else if runner.Status == store.RunnerStatusOnline {
+				stats.IdleRunners++
 			}
 		}
 
-		// Get template count.
-		templates, err := s.store.ListJobTemplatesByGroup(r.Context(), group.ID)
+		stats.Degraded = dispatcher.RunnerPoolShort(group, stats.OnlineRunners)
+
+		// Templates are served from the store cache.
+		templates, err := s.store.ListJobTemplatesByGroup(ctx, group.ID)
 		if err == nil {
 			stats.TemplateCount = len(templates)
 		}
@@ -589,18 +1046,18 @@ func (s *server) handleListGroups(w http.ResponseWriter, r *http.Request) {
 		result = append(result, stats)
 	}
 
-	s.writeJSON(w, http.StatusOK, result)
+	return result, nil
 }
 
 // handleGetGroup godoc
 //
 //	@Summary		Get group
-//	@Description	Returns a single group by ID
+//	@Description	Returns a single group by ID, with its markdown description rendered as HTML
 //	@Tags			groups
 //	@Security		BearerAuth
 //	@Produce		json
 //	@Param			id	path		string	true	"Group ID"
-//	@Success		200	{object}	store.Group
+//	@Success		200	{object}	GroupResponse
 //	@Failure		401	{object}	ErrorResponse
 //	@Failure		404	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
@@ -622,7 +1079,7 @@ func (s *server) handleGetGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.writeJSON(w, http.StatusOK, group)
+	s.writeJSON(w, http.StatusOK, newGroupResponse(group))
 }
 
 // handlePauseGroup godoc
@@ -657,6 +1114,7 @@ func (s *server) handlePauseGroup(w http.ResponseWriter, r *http.Request) {
 	}
 
 	group.Paused = true
+	group.PausedReason = ""
 
 	if err := s.store.UpdateGroup(r.Context(), group); err != nil {
 		s.log.WithError(err).Error("Failed to pause group")
@@ -700,7 +1158,10 @@ func (s *server) handleUnpauseGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	now := time.Now()
 	group.Paused = false
+	group.PausedReason = ""
+	group.ResumedAt = &now
 
 	if err := s.store.UpdateGroup(r.Context(), group); err != nil {
 		s.log.WithError(err).Error("Failed to unpause group")
@@ -713,83 +1174,377 @@ func (s *server) handleUnpauseGroup(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, http.StatusOK, group)
 }
 
-// handleListJobTemplates godoc
+// handleArchiveGroup godoc
 //
-//	@Summary		List job templates
-//	@Description	Returns all job templates for a group
-//	@Tags			templates
+//	@Summary		Archive group
+//	@Description	Archives a group: it is no longer dispatched, accepts no new jobs and is hidden from the group list, while its templates and history are kept. The group must have no pending, triggered or running jobs (requires admin)
+//	@Tags			groups
 //	@Security		BearerAuth
 //	@Produce		json
 //	@Param			id	path		string	true	"Group ID"
-//	@Success		200	{array}		store.JobTemplate
+//	@Success		200	{object}	store.Group
 //	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse	"Group has active jobs"
 //	@Failure		500	{object}	ErrorResponse
-//	@Router			/groups/{id}/templates [get]
-func (s *server) handleListJobTemplates(w http.ResponseWriter, r *http.Request) {
-	groupID := chi.URLParam(r, "id")
+//	@Router			/groups/{id}/archive [post]
+func (s *server) handleArchiveGroup(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
 
-	templates, err := s.store.ListJobTemplatesByGroup(r.Context(), groupID)
+	group, err := s.store.GetGroup(r.Context(), id)
 	if err != nil {
-		s.log.WithError(err).Error("Failed to list job templates")
-		s.writeError(w, http.StatusInternalServerError, "Failed to list job templates")
+		s.log.WithError(err).Error("Failed to get group")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get group")
 
 		return
 	}
 
-	if templates == nil {
-		templates = []*store.JobTemplate{}
+	if group == nil {
+		s.writeError(w, http.StatusNotFound, "Group not found")
+
+		return
 	}
 
-	s.writeJSON(w, http.StatusOK, templates)
-}
+	if group.Archived {
+		s.writeJSON(w, http.StatusOK, group)
 
-// handleGetJobTemplate godoc
-//
-//	@Summary		Get job template
-//	@Description	Returns a single job template by ID
-//	@Tags			templates
-//	@Security		BearerAuth
-//	@Produce		json
-//	@Param			id	path		string	true	"Template ID"
-//	@Success		200	{object}	store.JobTemplate
-//	@Failure		401	{object}	ErrorResponse
-//	@Failure		404	{object}	ErrorResponse
-//	@Failure		500	{object}	ErrorResponse
-//	@Router			/templates/{id} [get]
-func (s *server) handleGetJobTemplate(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
+		return
+	}
 
-	template, err := s.store.GetJobTemplate(r.Context(), id)
+	active, err := s.store.ListJobsByGroup(r.Context(), id,
+		store.JobStatusPending, store.JobStatusTriggered, store.JobStatusRunning)
 	if err != nil {
-		s.log.WithError(err).Error("Failed to get job template")
-		s.writeError(w, http.StatusInternalServerError, "Failed to get job template")
+		s.log.WithError(err).Error("Failed to list group jobs")
+		s.writeError(w, http.StatusInternalServerError, "Failed to archive group")
 
 		return
 	}
 
-	if template == nil {
-		s.writeError(w, http.StatusNotFound, "Job template not found")
+	if len(active) > 0 {
+		s.writeError(w, http.StatusConflict,
+			fmt.Sprintf("Group has %d pending or running jobs; remove or finish them before archiving", len(active)))
 
 		return
 	}
 
-	s.writeJSON(w, http.StatusOK, template)
+	now := time.Now()
+	group.Archived = true
+	group.ArchivedAt = &now
+
+	if err := s.store.UpdateGroup(r.Context(), group); err != nil {
+		s.log.WithError(err).Error("Failed to archive group")
+		s.writeError(w, http.StatusInternalServerError, "Failed to archive group")
+
+		return
+	}
+
+	s.auditGroupArchive(r, group, store.AuditActionGroupArchived)
+
+	s.log.WithField("group", id).Info("Group archived")
+	s.BroadcastGroupChange(group)
+	s.writeJSON(w, http.StatusOK, group)
 }
 
-// handleGetQueue godoc
+// handleUnarchiveGroup godoc
 //
-//	@Summary		Get queue
-//	@Description	Returns all pending, triggered, and running jobs in the group's queue
-//	@Tags			queue
+//	@Summary		Unarchive group
+//	@Description	Restores an archived group so it is listed, accepts jobs and is dispatched again (requires admin)
+//	@Tags			groups
 //	@Security		BearerAuth
 //	@Produce		json
 //	@Param			id	path		string	true	"Group ID"
-//	@Success		200	{array}		store.Job
+//	@Success		200	{object}	store.Group
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/groups/{id}/unarchive [post]
+func (s *server) handleUnarchiveGroup(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	group, err := s.store.GetGroup(r.Context(), id)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get group")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get group")
+
+		return
+	}
+
+	if group == nil {
+		s.writeError(w, http.StatusNotFound, "Group not found")
+
+		return
+	}
+
+	if !group.Archived {
+		s.writeJSON(w, http.StatusOK, group)
+
+		return
+	}
+
+	group.Archived = false
+	group.ArchivedAt = nil
+
+	if err := s.store.UpdateGroup(r.Context(), group); err != nil {
+		s.log.WithError(err).Error("Failed to unarchive group")
+		s.writeError(w, http.StatusInternalServerError, "Failed to unarchive group")
+
+		return
+	}
+
+	s.auditGroupArchive(r, group, store.AuditActionGroupUnarchived)
+
+	s.log.WithField("group", id).Info("Group unarchived")
+	s.BroadcastGroupChange(group)
+	s.writeJSON(w, http.StatusOK, group)
+}
+
+// auditGroupArchive records who archived or unarchived a group.
+func (s *server) auditGroupArchive(r *http.Request, group *store.Group, action store.AuditAction) {
+	actor := "anonymous"
+	if user := auth.UserFromContext(r.Context()); user != nil {
+		actor = user.Username
+	}
+
+	if err := s.store.CreateAuditEntry(r.Context(), &store.AuditEntry{
+		ID:         uuid.New().String(),
+		Action:     action,
+		EntityType: store.AuditEntityGroup,
+		EntityID:   group.ID,
+		Actor:      actor,
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		s.log.WithError(err).WithField("group", group.ID).Warn("Failed to create audit entry for group archive")
+	}
+}
+
+// TemplateCategory is a named set of templates in the grouped template listing.
+type TemplateCategory struct {
+	Category  string               `json:"category" example:"Devnets"`
+	Templates []*store.JobTemplate `json:"templates"`
+}
+
+// GroupedTemplatesResponse is the response for listing templates by category.
+type GroupedTemplatesResponse struct {
+	Categories []*TemplateCategory `json:"categories"`
+}
+
+// groupTemplatesByCategory groups templates by category, keeping their order
+// within each category. Categories are sorted by name with uncategorized
+// templates last.
+func groupTemplatesByCategory(templates []*store.JobTemplate) []*TemplateCategory {
+	byName := make(map[string]*TemplateCategory)
+	categories := make([]*TemplateCategory, 0)
+
+	for _, tmpl := range templates {
+		category, ok := byName[tmpl.Category]
+		if !ok {
+			category = &TemplateCategory{Category: tmpl.Category, Templates: []*store.JobTemplate{}}
+			byName[tmpl.Category] = category
+			categories = append(categories, category)
+		}
+
+		category.Templates = append(category.Templates, tmpl)
+	}
+
+	sort.SliceStable(categories, func(i, j int) bool {
+		a, b := categories[i].Category, categories[j].Category
+		if a == "" || b == "" {
+			return b == "" && a != ""
+		}
+
+		return a < b
+	})
+
+	return categories
+}
+
+// handleListJobTemplates godoc
+//
+//	@Summary		List job templates
+//	@Description	Returns all job templates for a group, ordered by display_order then name. With group_by=category, returns a GroupedTemplatesResponse instead of an array.
+//	@Tags			templates
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id			path		string	true	"Group ID"
+//	@Param			group_by	query		string	false	"Group templates by field"	Enums(category)
+//	@Success		200			{array}		store.JobTemplate
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/groups/{id}/templates [get]
+func (s *server) handleListJobTemplates(w http.ResponseWriter, r *http.Request) {
+	groupID := chi.URLParam(r, "id")
+
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy != "" && groupBy != "category" {
+		s.writeError(w, http.StatusBadRequest, "group_by must be 'category'")
+
+		return
+	}
+
+	templates, err := s.store.ListJobTemplatesByGroup(r.Context(), groupID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list job templates")
+		s.writeError(w, http.StatusInternalServerError, "Failed to list job templates")
+
+		return
+	}
+
+	if templates == nil {
+		templates = []*store.JobTemplate{}
+	}
+
+	if groupBy == "category" {
+		s.writeJSON(w, http.StatusOK, &GroupedTemplatesResponse{Categories: groupTemplatesByCategory(templates)})
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, templates)
+}
+
+// handleGetJobTemplate godoc
+//
+//	@Summary		Get job template
+//	@Description	Returns a single job template by ID
+//	@Tags			templates
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Template ID"
+//	@Success		200	{object}	store.JobTemplate
 //	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
+//	@Router			/templates/{id} [get]
+func (s *server) handleGetJobTemplate(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	template, err := s.store.GetJobTemplate(r.Context(), id)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get job template")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get job template")
+
+		return
+	}
+
+	if template == nil {
+		s.writeError(w, http.StatusNotFound, "Job template not found")
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, template)
+}
+
+// handleLintJobTemplate godoc
+//
+//	@Summary		Lint job template
+//	@Description	Checks the template against its workflow definition on the template's ref and stores the result. With cached=true, returns the last stored result instead.
+//	@Tags			templates
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id		path		string	true	"Template ID"
+//	@Param			cached	query		bool	false	"Return the last stored result without contacting GitHub"
+//	@Success		200		{object}	store.TemplateLint
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Failure		503		{object}	ErrorResponse
+//	@Router			/templates/{id}/lint [get]
+func (s *server) handleLintJobTemplate(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	template, err := s.store.GetJobTemplate(r.Context(), id)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get job template")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get job template")
+
+		return
+	}
+
+	if template == nil {
+		s.writeError(w, http.StatusNotFound, "Job template not found")
+
+		return
+	}
+
+	if r.URL.Query().Get("cached") == "true" {
+		result, err := s.store.GetTemplateLint(r.Context(), id)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to get template lint")
+			s.writeError(w, http.StatusInternalServerError, "Failed to get template lint")
+
+			return
+		}
+
+		if result == nil {
+			s.writeError(w, http.StatusNotFound, "Template has not been linted yet")
+
+			return
+		}
+
+		s.writeJSON(w, http.StatusOK, result)
+
+		return
+	}
+
+	result, err := lint.Template(r.Context(), s.dispatchClient, template)
+	if err != nil {
+		if errors.Is(err, lint.ErrNotConnected) {
+			s.writeError(w, http.StatusServiceUnavailable, "GitHub client not connected")
+
+			return
+		}
+
+		s.log.WithError(err).WithField("template_id", id).Error("Failed to lint job template")
+		s.writeError(w, http.StatusInternalServerError, "Failed to lint job template")
+
+		return
+	}
+
+	if err := s.store.UpsertTemplateLint(r.Context(), result); err != nil {
+		s.log.WithError(err).Error("Failed to store template lint")
+		s.writeError(w, http.StatusInternalServerError, "Failed to store template lint")
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// handleGetQueue godoc
+//
+//	@Summary		Get queue
+//	@Description	Returns all pending, triggered, and running jobs in the group's queue. Unpaused pending jobs include their queue_position and ahead_count in dispatch order. With limit or after, returns a QueuePageResponse paging through pending jobs in dispatch order instead; with summary=true, returns a QueueSummaryResponse of job counts per template.
+//	@Tags			queue
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id		path		string	true	"Group ID"
+//	@Param			limit	query		int		false	"Page through pending jobs, this many per page (max 1000)"
+//	@Param			after	query		string	false	"Cursor for pagination (next_cursor of the previous page)"
+//	@Param			summary	query		bool	false	"Return job counts per template instead of jobs"
+//	@Success		200		{array}		store.Job
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
 //	@Router			/groups/{id}/queue [get]
 func (s *server) handleGetQueue(w http.ResponseWriter, r *http.Request) {
 	groupID := chi.URLParam(r, "id")
+	query := r.URL.Query()
+
+	if query.Get("summary") == "true" {
+		s.handleGetQueueSummary(w, r, groupID)
+
+		return
+	}
+
+	if query.Has("limit") || query.Has("after") {
+		s.handleGetQueuePage(w, r, groupID)
+
+		return
+	}
 
 	jobs, err := s.store.ListJobsByGroup(r.Context(), groupID, store.JobStatusPending, store.JobStatusTriggered, store.JobStatusRunning)
 	if err != nil {
@@ -803,6 +1558,9 @@ func (s *server) handleGetQueue(w http.ResponseWriter, r *http.Request) {
 		jobs = []*store.Job{}
 	}
 
+	queue.SetQueuePositions(jobs)
+	s.setInputOverrides(r.Context(), jobs...)
+
 	s.writeJSON(w, http.StatusOK, jobs)
 }
 
@@ -884,10 +1642,12 @@ func (s *server) handleListRunners(w http.ResponseWriter, r *http.Request) {
 
 // AddJobRequest is the request body for adding a job to the queue.
 type AddJobRequest struct {
-	TemplateID   string            `json:"template_id,omitempty" example:"my-template"`
-	Inputs       map[string]string `json:"inputs"`
-	AutoRequeue  bool              `json:"auto_requeue" example:"false"`
-	RequeueLimit *int              `json:"requeue_limit" example:"3"`
+	TemplateID   string    `json:"template_id,omitempty" example:"my-template"`
+	Inputs       input.Map `json:"inputs"`
+	AutoRequeue  bool      `json:"auto_requeue" example:"false"`
+	RequeueLimit *int      `json:"requeue_limit" example:"3"`
+	// Priority overrides the template's default priority, up to the group's max_priority.
+	Priority *int `json:"priority,omitempty" example:"10"`
 	// Manual job fields (used when template_id is empty).
 	Name       string            `json:"name,omitempty" example:"Manual Job"`
 	Owner      string            `json:"owner,omitempty" example:"ethpandaops"`
@@ -895,6 +1655,17 @@ type AddJobRequest struct {
 	WorkflowID string            `json:"workflow_id,omitempty" example:"deploy.yml"`
 	Ref        string            `json:"ref,omitempty" example:"main"`
 	Labels     map[string]string `json:"labels,omitempty"`
+	// CampaignID adds the job to an existing campaign.
+	CampaignID string `json:"campaign_id,omitempty" example:"5f0c6a3e-8d1b-4c3e-9f4a-2b7d1e6c9a10"`
+	// MaxDurationSeconds overrides the template's max_duration; the job's run
+	// is cancelled and the job failed once it runs longer (0 = no limit).
+	MaxDurationSeconds *int `json:"max_duration_seconds,omitempty" example:"3600"`
+	// NotifyTargets are notified of the job's transitions in addition to its
+	// subscribers.
+	NotifyTargets []store.JobNotifyTarget `json:"notify_targets,omitempty"`
+	// NotBefore holds the job in the queue until the given time, for a
+	// one-off scheduled run.
+	NotBefore *time.Time `json:"not_before,omitempty" example:"2026-10-14T22:00:00Z"`
 }
 
 // handleAddJob godoc
@@ -922,6 +1693,12 @@ func (s *server) handleAddJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if msg := s.checkJobLimits(req.Inputs, req.Labels); msg != "" {
+		s.writeError(w, http.StatusBadRequest, msg)
+
+		return
+	}
+
 	// Validate: either template_id is provided, or all manual fields are required.
 	if req.TemplateID == "" {
 		// Manual job - validate required fields.
@@ -932,6 +1709,48 @@ func (s *server) handleAddJob(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if req.MaxDurationSeconds != nil && *req.MaxDurationSeconds < 0 {
+		s.writeError(w, http.StatusBadRequest, "max_duration_seconds must not be negative")
+
+		return
+	}
+
+	if msg := s.validateNotifyTargets(req.NotifyTargets); msg != "" {
+		s.writeError(w, http.StatusBadRequest, msg)
+
+		return
+	}
+
+	msg, err := s.checkJobRef(r.Context(), req.TemplateID, req.Owner, req.Repo, req.Ref)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to check job ref")
+		s.writeError(w, http.StatusInternalServerError, "Failed to check job ref")
+
+		return
+	}
+
+	if msg != "" {
+		s.writeError(w, http.StatusBadRequest, msg)
+
+		return
+	}
+
+	if req.CampaignID != "" {
+		campaign, err := s.store.GetCampaign(r.Context(), req.CampaignID)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to get campaign")
+			s.writeError(w, http.StatusInternalServerError, "Failed to get campaign")
+
+			return
+		}
+
+		if campaign == nil {
+			s.writeError(w, http.StatusBadRequest, "Campaign not found")
+
+			return
+		}
+	}
+
 	createdBy := "anonymous"
 	if user := auth.UserFromContext(r.Context()); user != nil {
 		createdBy = user.Username
@@ -940,6 +1759,7 @@ func (s *server) handleAddJob(w http.ResponseWriter, r *http.Request) {
 	opts := &queue.EnqueueOptions{
 		AutoRequeue:  req.AutoRequeue,
 		RequeueLimit: req.RequeueLimit,
+		Priority:     req.Priority,
 		// Manual job fields.
 		Name:       req.Name,
 		Owner:      req.Owner,
@@ -947,6 +1767,11 @@ func (s *server) handleAddJob(w http.ResponseWriter, r *http.Request) {
 		WorkflowID: req.WorkflowID,
 		Ref:        req.Ref,
 		Labels:     req.Labels,
+		CampaignID: req.CampaignID,
+
+		MaxDurationSeconds: req.MaxDurationSeconds,
+		NotifyTargets:      req.NotifyTargets,
+		NotBefore:          req.NotBefore,
 	}
 
 	job, err := s.queue.Enqueue(r.Context(), groupID, req.TemplateID, createdBy, req.Inputs, opts)
@@ -957,13 +1782,32 @@ func (s *server) handleAddJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.setInputOverrides(r.Context(), job)
+
+	// Surface template deprecation to the client without failing the request.
+	if req.TemplateID != "" {
+		if template, err := s.store.GetJobTemplate(r.Context(), req.TemplateID); err == nil && template != nil && template.Deprecated {
+			w.Header().Set("Warning", fmt.Sprintf(`299 dispatchoor %q`, deprecationWarning(template)))
+		}
+	}
+
 	s.writeJSON(w, http.StatusCreated, job)
 }
 
+// deprecationWarning builds the human-readable warning for a deprecated template.
+func deprecationWarning(template *store.JobTemplate) string {
+	if template.SunsetAt != nil {
+		return fmt.Sprintf("template %s is deprecated and will stop accepting jobs on %s",
+			template.ID, template.SunsetAt.UTC().Format(time.RFC3339))
+	}
+
+	return fmt.Sprintf("template %s is deprecated", template.ID)
+}
+
 // handleGetJob godoc
 //
 //	@Summary		Get job
-//	@Description	Returns a single job by ID
+//	@Description	Returns a single job by ID. Unpaused pending jobs include their queue_position and ahead_count.
 //	@Tags			jobs
 //	@Security		BearerAuth
 //	@Produce		json
@@ -990,25 +1834,203 @@ func (s *server) handleGetJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if job.Status == store.JobStatusPending && !job.Paused {
+		s.setQueuePosition(r.Context(), job)
+	}
+
+	s.setInputOverrides(r.Context(), job)
+
 	s.writeJSON(w, http.StatusOK, job)
 }
 
-// UpdateJobRequest is the request body for updating a job.
-type UpdateJobRequest struct {
-	Inputs     map[string]string `json:"inputs"`
-	Name       *string           `json:"name,omitempty" example:"Updated Job"`
-	Owner      *string           `json:"owner,omitempty" example:"ethpandaops"`
-	Repo       *string           `json:"repo,omitempty" example:"dispatchoor"`
-	WorkflowID *string           `json:"workflow_id,omitempty" example:"deploy.yml"`
-	Ref        *string           `json:"ref,omitempty" example:"main"`
-	Labels     map[string]string `json:"labels,omitempty"`
-}
+// setQueuePosition sets the queue position of a pending job from its group's
+// queue. The job is served without a position if the queue cannot be listed.
+func (s *server) setQueuePosition(ctx context.Context, job *store.Job) {
+	pending, err := s.queue.ListPending(ctx, job.GroupID)
+	if err != nil {
+		s.log.WithError(err).WithField("job_id", job.ID).Warn("Failed to list queue for job position")
 
-// handleUpdateJob godoc
-//
-//	@Summary		Update job
-//	@Description	Updates job configuration (inputs, name, owner, repo, workflow_id, ref, labels)
-//	@Tags			jobs
+		return
+	}
+
+	queue.SetQueuePositions(pending)
+
+	for _, queued := range pending {
+		if queued.ID == job.ID {
+			job.QueuePosition = queued.QueuePosition
+			job.AheadCount = queued.AheadCount
+
+			return
+		}
+	}
+}
+
+// handleGetJobAnnotations godoc
+//
+//	@Summary		Get job annotations
+//	@Description	Returns the failure annotations (file, line and message) reported by the workflow run of a finished job, at most 50 per job
+//	@Tags			jobs
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Job ID"
+//	@Success		200	{array}		store.JobAnnotation
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/jobs/{id}/annotations [get]
+func (s *server) handleGetJobAnnotations(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+
+	job, err := s.queue.GetJob(r.Context(), jobID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get job")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get job")
+
+		return
+	}
+
+	if job == nil {
+		s.writeError(w, http.StatusNotFound, "Job not found")
+
+		return
+	}
+
+	annotations := job.Annotations
+	if annotations == nil {
+		annotations = []store.JobAnnotation{}
+	}
+
+	s.writeJSON(w, http.StatusOK, annotations)
+}
+
+// handleGetJobTimeline godoc
+//
+//	@Summary		Get job timeline
+//	@Description	Returns every status transition of a job, oldest first, with who made it, the workflow run and the runner at the time
+//	@Tags			jobs
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Job ID"
+//	@Success		200	{array}		store.JobEvent
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/jobs/{id}/timeline [get]
+func (s *server) handleGetJobTimeline(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	jobID := chi.URLParam(r, "id")
+
+	job, err := s.queue.GetJob(ctx, jobID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get job")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get job")
+
+		return
+	}
+
+	if job == nil {
+		s.writeError(w, http.StatusNotFound, "Job not found")
+
+		return
+	}
+
+	events, err := s.store.ListJobEvents(ctx, jobID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list job events")
+		s.writeError(w, http.StatusInternalServerError, "Failed to list job events")
+
+		return
+	}
+
+	if events == nil {
+		events = []*store.JobEvent{}
+	}
+
+	s.writeJSON(w, http.StatusOK, events)
+}
+
+// JobAttemptsResponse lists the attempts of a job's workflow run.
+type JobAttemptsResponse struct {
+	JobID string `json:"job_id"`
+	// RunAttempt is the current attempt, 0 before the run was tracked.
+	RunAttempt int `json:"run_attempt" example:"2"`
+	// Attempts holds the earlier attempts that finished before the run was
+	// re-run from GitHub, oldest first.
+	Attempts []*store.JobRunAttempt `json:"attempts"`
+}
+
+// handleGetJobAttempts godoc
+//
+//	@Summary		Get job run attempts
+//	@Description	Returns the current attempt of a job's workflow run and how each earlier attempt ended before the run was re-run from GitHub, oldest first
+//	@Tags			jobs
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Job ID"
+//	@Success		200	{object}	JobAttemptsResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/jobs/{id}/attempts [get]
+func (s *server) handleGetJobAttempts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	jobID := chi.URLParam(r, "id")
+
+	job, err := s.queue.GetJob(ctx, jobID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get job")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get job")
+
+		return
+	}
+
+	if job == nil {
+		s.writeError(w, http.StatusNotFound, "Job not found")
+
+		return
+	}
+
+	attempts, err := s.store.ListJobRunAttempts(ctx, jobID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list job run attempts")
+		s.writeError(w, http.StatusInternalServerError, "Failed to list job run attempts")
+
+		return
+	}
+
+	if attempts == nil {
+		attempts = []*store.JobRunAttempt{}
+	}
+
+	s.writeJSON(w, http.StatusOK, JobAttemptsResponse{
+		JobID:      jobID,
+		RunAttempt: job.RunAttempt,
+		Attempts:   attempts,
+	})
+}
+
+// UpdateJobRequest is the request body for updating a job.
+type UpdateJobRequest struct {
+	Inputs     input.Map         `json:"inputs"`
+	Name       *string           `json:"name,omitempty" example:"Updated Job"`
+	Owner      *string           `json:"owner,omitempty" example:"ethpandaops"`
+	Repo       *string           `json:"repo,omitempty" example:"dispatchoor"`
+	WorkflowID *string           `json:"workflow_id,omitempty" example:"deploy.yml"`
+	Ref        *string           `json:"ref,omitempty" example:"main"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	// Priority moves the job ahead of (higher) or behind (lower) jobs of other
+	// priorities, whatever their queue position.
+	Priority *int `json:"priority,omitempty" example:"10"`
+	// NotBefore reschedules the job, and ClearNotBefore makes it due now.
+	NotBefore      *time.Time `json:"not_before,omitempty" example:"2026-10-14T22:00:00Z"`
+	ClearNotBefore bool       `json:"clear_not_before,omitempty" example:"false"`
+}
+
+// handleUpdateJob godoc
+//
+//	@Summary		Update job
+//	@Description	Updates job configuration (inputs, name, owner, repo, workflow_id, ref, labels, priority, not_before)
+//	@Tags			jobs
 //	@Security		BearerAuth
 //	@Accept			json
 //	@Produce		json
@@ -1030,6 +2052,28 @@ func (s *server) handleUpdateJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if msg := s.checkJobLimits(req.Inputs, req.Labels); msg != "" {
+		s.writeError(w, http.StatusBadRequest, msg)
+
+		return
+	}
+
+	if req.Owner != nil || req.Repo != nil || req.Ref != nil {
+		msg, err := s.checkUpdatedJobRef(r.Context(), jobID, &req)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to check job ref")
+			s.writeError(w, http.StatusInternalServerError, "Failed to check job ref")
+
+			return
+		}
+
+		if msg != "" {
+			s.writeError(w, http.StatusBadRequest, msg)
+
+			return
+		}
+	}
+
 	opts := &queue.UpdateJobOptions{
 		Inputs:     req.Inputs,
 		Name:       req.Name,
@@ -1038,6 +2082,10 @@ func (s *server) handleUpdateJob(w http.ResponseWriter, r *http.Request) {
 		WorkflowID: req.WorkflowID,
 		Ref:        req.Ref,
 		Labels:     req.Labels,
+		Priority:   req.Priority,
+
+		NotBefore:      req.NotBefore,
+		ClearNotBefore: req.ClearNotBefore,
 	}
 
 	if err := s.queue.UpdateJob(r.Context(), jobID, opts); err != nil {
@@ -1048,6 +2096,7 @@ func (s *server) handleUpdateJob(w http.ResponseWriter, r *http.Request) {
 	}
 
 	job, _ := s.queue.GetJob(r.Context(), jobID)
+	s.setInputOverrides(r.Context(), job)
 	s.writeJSON(w, http.StatusOK, job)
 }
 
@@ -1173,104 +2222,110 @@ func (s *server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// If we have a run ID, cancel the workflow run on GitHub.
-	if job.RunID != nil && *job.RunID != 0 {
-		// Get owner/repo - prefer job overrides, fall back to template.
-		var owner, repo string
+	if status, msg := s.cancelWorkflowRun(r.Context(), job); status != 0 {
+		s.writeError(w, status, msg)
 
-		if job.Owner != nil && *job.Owner != "" {
-			owner = *job.Owner
-		}
-
-		if job.Repo != nil && *job.Repo != "" {
-			repo = *job.Repo
-		}
+		return
+	}
 
-		// If not set on job, get from template.
-		if (owner == "" || repo == "") && job.TemplateID != "" {
-			template, err := s.store.GetJobTemplate(r.Context(), job.TemplateID)
-			if err != nil {
-				s.log.WithError(err).Error("Failed to get job template")
-				s.writeError(w, http.StatusInternalServerError, "Failed to get job template")
+	// Mark the job as cancelled.
+	if err := s.queue.MarkCancelled(r.Context(), job.ID); err != nil {
+		s.log.WithError(err).Error("Failed to mark job as cancelled")
+		s.writeError(w, http.StatusInternalServerError, "Failed to mark job as cancelled")
 
-				return
-			}
+		return
+	}
 
-			if template == nil {
-				s.writeError(w, http.StatusInternalServerError, "Job template not found")
+	// Get the updated job.
+	job, _ = s.queue.GetJob(r.Context(), jobID)
 
-				return
-			}
+	s.writeJSON(w, http.StatusOK, job)
+}
 
-			if owner == "" {
-				owner = template.Owner
-			}
+// cancelWorkflowRun cancels the GitHub workflow run of a triggered or running
+// job, if it has one. On failure it returns a non-zero HTTP status and the
+// message for the client.
+func (s *server) cancelWorkflowRun(ctx context.Context, job *store.Job) (int, string) {
+	if job.RunID == nil || *job.RunID == 0 {
+		return 0, ""
+	}
 
-			if repo == "" {
-				repo = template.Repo
-			}
-		}
+	// Get owner/repo - prefer job overrides, fall back to template.
+	var owner, repo string
 
-		if owner == "" || repo == "" {
-			s.writeError(w, http.StatusInternalServerError, "Cannot determine owner/repo for job")
+	if job.Owner != nil && *job.Owner != "" {
+		owner = *job.Owner
+	}
 
-			return
-		}
+	if job.Repo != nil && *job.Repo != "" {
+		repo = *job.Repo
+	}
 
-		// Check if dispatch client is available.
-		if s.dispatchClient == nil || !s.dispatchClient.IsConnected() {
-			s.writeError(w, http.StatusServiceUnavailable, "GitHub integration is not available")
+	// If not set on job, get from template.
+	if (owner == "" || repo == "") && job.TemplateID != "" {
+		template, err := s.store.GetJobTemplate(ctx, job.TemplateID)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to get job template")
 
-			return
+			return http.StatusInternalServerError, "Failed to get job template"
 		}
 
-		// Cancel the workflow run on GitHub.
-		if err := s.dispatchClient.CancelWorkflowRun(r.Context(), owner, repo, *job.RunID); err != nil {
-			s.log.WithError(err).Warn("Cancel request returned error, checking actual run status")
-
-			// Check if the run was actually cancelled despite the error.
-			// GitHub can return transient errors like "job scheduled on GitHub side"
-			// even when the cancellation succeeds.
-			run, getErr := s.dispatchClient.GetWorkflowRun(r.Context(), owner, repo, *job.RunID)
-			if getErr != nil {
-				s.log.WithError(getErr).Error("Failed to verify workflow run status after cancel error")
-				s.writeError(w, http.StatusInternalServerError, "Failed to cancel workflow run on GitHub")
+		if template == nil {
+			return http.StatusInternalServerError, "Job template not found"
+		}
 
-				return
-			}
+		if owner == "" {
+			owner = template.Owner
+		}
 
-			// If the run is already completed with a non-cancel conclusion, we can't cancel it.
-			if run.Status == "completed" && run.Conclusion != "cancelled" {
-				s.log.WithFields(logrus.Fields{
-					"status":     run.Status,
-					"conclusion": run.Conclusion,
-				}).Warn("Workflow run already completed, cannot cancel")
-				// Still proceed to mark job as cancelled locally since the run is done.
-			} else if run.Conclusion == "cancelled" {
-				s.log.Info("Workflow run confirmed cancelled")
-			} else {
-				// Run is still in_progress - GitHub is processing the cancellation.
-				// This is expected; proceed with marking job cancelled locally.
-				s.log.WithFields(logrus.Fields{
-					"status":     run.Status,
-					"conclusion": run.Conclusion,
-				}).Info("Workflow run cancellation in progress")
-			}
+		if repo == "" {
+			repo = template.Repo
 		}
 	}
 
-	// Mark the job as cancelled.
-	if err := s.queue.MarkCancelled(r.Context(), job.ID); err != nil {
-		s.log.WithError(err).Error("Failed to mark job as cancelled")
-		s.writeError(w, http.StatusInternalServerError, "Failed to mark job as cancelled")
+	if owner == "" || repo == "" {
+		return http.StatusInternalServerError, "Cannot determine owner/repo for job"
+	}
 
-		return
+	// Check if dispatch client is available.
+	if s.dispatchClient == nil || !s.dispatchClient.IsConnected() {
+		return http.StatusServiceUnavailable, "GitHub integration is not available"
 	}
 
-	// Get the updated job.
-	job, _ = s.queue.GetJob(r.Context(), jobID)
+	// Cancel the workflow run on GitHub.
+	if err := s.dispatchClient.CancelWorkflowRun(ctx, owner, repo, *job.RunID); err != nil {
+		s.log.WithError(err).Warn("Cancel request returned error, checking actual run status")
 
-	s.writeJSON(w, http.StatusOK, job)
+		// Check if the run was actually cancelled despite the error.
+		// GitHub can return transient errors like "job scheduled on GitHub side"
+		// even when the cancellation succeeds.
+		run, getErr := s.dispatchClient.GetWorkflowRun(ctx, owner, repo, *job.RunID)
+		if getErr != nil {
+			s.log.WithError(getErr).Error("Failed to verify workflow run status after cancel error")
+
+			return http.StatusInternalServerError, "Failed to cancel workflow run on GitHub"
+		}
+
+		// If the run is already completed with a non-cancel conclusion, we can't cancel it.
+		if run.Status == "completed" && run.Conclusion != "cancelled" {
+			s.log.WithFields(logrus.Fields{
+				"status":     run.Status,
+				"conclusion": run.Conclusion,
+			}).Warn("Workflow run already completed, cannot cancel")
+			// Still proceed to mark job as cancelled locally since the run is done.
+		} else if run.Conclusion == "cancelled" {
+			s.log.Info("Workflow run confirmed cancelled")
+		} else {
+			// Run is still in_progress - GitHub is processing the cancellation.
+			// This is expected; proceed with marking job cancelled locally.
+			s.log.WithFields(logrus.Fields{
+				"status":     run.Status,
+				"conclusion": run.Conclusion,
+			}).Info("Workflow run cancellation in progress")
+		}
+	}
+
+	return 0, ""
 }
 
 // handleDisableAutoRequeue godoc
@@ -1344,533 +2399,1830 @@ func (s *server) handleUpdateAutoRequeue(w http.ResponseWriter, r *http.Request)
 	s.writeJSON(w, http.StatusOK, job)
 }
 
-// ReorderQueueRequest is the request body for reordering the job queue.
-type ReorderQueueRequest struct {
-	JobIDs []string `json:"job_ids" example:"job-1,job-2,job-3"`
+// handleRetainJob godoc
+//
+//	@Summary		Retain job
+//	@Description	Marks a completed, failed or cancelled job as retained, so history retention and max_jobs_per_group never delete it, e.g. to keep a golden run (requires admin)
+//	@Tags			jobs
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Job ID"
+//	@Success		200	{object}	store.Job
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Router			/jobs/{id}/retain [post]
+func (s *server) handleRetainJob(w http.ResponseWriter, r *http.Request) {
+	s.setJobRetained(w, r, true)
 }
 
-// handleReorderQueue godoc
+// handleUnretainJob godoc
 //
-//	@Summary		Reorder queue
-//	@Description	Reorders jobs in the queue by specifying the desired order of job IDs
-//	@Tags			queue
+//	@Summary		Unretain job
+//	@Description	Returns a retained job to normal history retention (requires admin)
+//	@Tags			jobs
 //	@Security		BearerAuth
-//	@Accept			json
 //	@Produce		json
-//	@Param			id		path	string					true	"Group ID"
-//	@Param			body	body	ReorderQueueRequest		true	"New job order"
-//	@Success		204		"Queue reordered successfully"
-//	@Failure		400		{object}	ErrorResponse
-//	@Failure		401		{object}	ErrorResponse
-//	@Failure		403		{object}	ErrorResponse
-//	@Router			/groups/{id}/queue/reorder [put]
-func (s *server) handleReorderQueue(w http.ResponseWriter, r *http.Request) {
-	groupID := chi.URLParam(r, "id")
+//	@Param			id	path		string	true	"Job ID"
+//	@Success		200	{object}	store.Job
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Router			/jobs/{id}/unretain [post]
+func (s *server) handleUnretainJob(w http.ResponseWriter, r *http.Request) {
+	s.setJobRetained(w, r, false)
+}
 
-	var req ReorderQueueRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.writeError(w, http.StatusBadRequest, "Invalid request body")
+// setJobRetained changes whether a job is retained and audits the change.
+func (s *server) setJobRetained(w http.ResponseWriter, r *http.Request, retained bool) {
+	jobID := chi.URLParam(r, "id")
+
+	existing, err := s.queue.GetJob(r.Context(), jobID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get job")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get job")
 
 		return
 	}
 
-	if len(req.JobIDs) == 0 {
-		s.writeError(w, http.StatusBadRequest, "job_ids is required")
+	if existing == nil {
+		s.writeError(w, http.StatusNotFound, "Job not found")
 
 		return
 	}
 
-	if err := s.queue.Reorder(r.Context(), groupID, req.JobIDs); err != nil {
-		s.log.WithError(err).Error("Failed to reorder queue")
+	job, err := s.queue.SetRetained(r.Context(), jobID, retained)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to change job retention")
 		s.writeError(w, http.StatusBadRequest, err.Error())
 
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
-}
+	if existing.Retained != retained {
+		actor := "anonymous"
+		if user := auth.UserFromContext(r.Context()); user != nil {
+			actor = user.Username
+		}
 
-// ============================================================================
-// Status Types
-// ============================================================================
+		action, details := store.AuditActionJobRetained, "Retained indefinitely"
+		if !retained {
+			action, details = store.AuditActionJobUnretained, "Returned to history retention"
+		}
 
-// ComponentStatus represents health status of a component.
-type ComponentStatus string
+		if err := s.store.CreateAuditEntry(r.Context(), &store.AuditEntry{
+			ID:         uuid.New().String(),
+			Action:     action,
+			EntityType: store.AuditEntityJob,
+			EntityID:   jobID,
+			Actor:      actor,
+			Details:    details,
+			CreatedAt:  time.Now(),
+		}); err != nil {
+			s.log.WithError(err).Warn("Failed to create audit entry for job retention")
+		}
+	}
 
-const (
-	ComponentStatusHealthy   ComponentStatus = "healthy"
-	ComponentStatusDegraded  ComponentStatus = "degraded"
-	ComponentStatusUnhealthy ComponentStatus = "unhealthy"
-)
+	s.writeJSON(w, http.StatusOK, job)
+}
 
-// DatabaseStatus contains database health information.
-type DatabaseStatus struct {
+// AssignJobRequest is the request body for assigning a job.
+type AssignJobRequest struct {
+	// Assignee is the user investigating the job. It defaults to the caller;
+	// only admins may assign someone else.
+	Assignee string `json:"assignee,omitempty" example:"alice"`
+}
+
+// handleAssignJob godoc
+//
+//	@Summary		Assign job
+//	@Description	Assigns a failed job to the user investigating it, the caller by default. Only admins may assign other users
+//	@Tags			jobs
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Job ID"
+//	@Param			body	body		AssignJobRequest	false	"Assignee"
+//	@Success		200		{object}	store.Job
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Router			/jobs/{id}/assign [post]
+func (s *server) handleAssignJob(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		s.writeError(w, http.StatusUnauthorized, "Not authenticated")
+
+		return
+	}
+
+	var req AssignJobRequest
+
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.writeError(w, http.StatusBadRequest, "Invalid request body")
+
+			return
+		}
+	}
+
+	req.Assignee = strings.TrimSpace(req.Assignee)
+	if req.Assignee == "" {
+		req.Assignee = user.Username
+	}
+
+	if req.Assignee != user.Username && !s.auth.IsAdmin(user) {
+		s.writeError(w, http.StatusForbidden, "Only admins can assign jobs to other users")
+
+		return
+	}
+
+	s.setJobAssignee(w, r, user, req.Assignee)
+}
+
+// handleUnassignJob godoc
+//
+//	@Summary		Unassign job
+//	@Description	Clears the assignee of a job. Only admins may unassign jobs assigned to other users
+//	@Tags			jobs
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Job ID"
+//	@Success		200	{object}	store.Job
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Router			/jobs/{id}/unassign [post]
+func (s *server) handleUnassignJob(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		s.writeError(w, http.StatusUnauthorized, "Not authenticated")
+
+		return
+	}
+
+	s.setJobAssignee(w, r, user, "")
+}
+
+// setJobAssignee changes the assignee of a job and audits the change. Users
+// other than admins may only change jobs that are unassigned or theirs.
+func (s *server) setJobAssignee(w http.ResponseWriter, r *http.Request, user *store.User, assignee string) {
+	jobID := chi.URLParam(r, "id")
+
+	existing, err := s.queue.GetJob(r.Context(), jobID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get job")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get job")
+
+		return
+	}
+
+	if existing == nil {
+		s.writeError(w, http.StatusNotFound, "Job not found")
+
+		return
+	}
+
+	if existing.Assignee != "" && existing.Assignee != user.Username && !s.auth.IsAdmin(user) {
+		s.writeError(w, http.StatusForbidden, fmt.Sprintf("Job is assigned to %s", existing.Assignee))
+
+		return
+	}
+
+	job, err := s.queue.SetAssignee(r.Context(), jobID, assignee)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to change job assignee")
+		s.writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	if existing.Assignee != assignee {
+		action, details := store.AuditActionJobAssigned, fmt.Sprintf("Assigned to %s", assignee)
+		if assignee == "" {
+			action, details = store.AuditActionJobUnassigned, fmt.Sprintf("Unassigned from %s", existing.Assignee)
+		}
+
+		if err := s.store.CreateAuditEntry(r.Context(), &store.AuditEntry{
+			ID:         uuid.New().String(),
+			Action:     action,
+			EntityType: store.AuditEntityJob,
+			EntityID:   jobID,
+			Actor:      user.Username,
+			Details:    details,
+			CreatedAt:  time.Now(),
+		}); err != nil {
+			s.log.WithError(err).Warn("Failed to create audit entry for job assignment")
+		}
+	}
+
+	s.writeJSON(w, http.StatusOK, job)
+}
+
+// UpdateJobOwnerRequest is the request body for reassigning a job.
+type UpdateJobOwnerRequest struct {
+	// CreatedBy is the username the job is attributed to from now on.
+	CreatedBy string `json:"created_by" example:"alice"`
+}
+
+// handleUpdateJobOwner godoc
+//
+//	@Summary		Reassign job owner
+//	@Description	Changes the created_by of a job, e.g. when its creator left or a bot enqueued it on behalf of someone. The original creator is kept in original_created_by (requires admin)
+//	@Tags			jobs
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string					true	"Job ID"
+//	@Param			body	body		UpdateJobOwnerRequest	true	"New owner"
+//	@Success		200		{object}	store.Job
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/jobs/{id}/owner [patch]
+func (s *server) handleUpdateJobOwner(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+
+	var req UpdateJobOwnerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	req.CreatedBy = strings.TrimSpace(req.CreatedBy)
+	if req.CreatedBy == "" {
+		s.writeError(w, http.StatusBadRequest, "created_by is required")
+
+		return
+	}
+
+	existing, err := s.queue.GetJob(r.Context(), jobID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get job")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get job")
+
+		return
+	}
+
+	if existing == nil {
+		s.writeError(w, http.StatusNotFound, "Job not found")
+
+		return
+	}
+
+	job, err := s.queue.ReassignOwner(r.Context(), jobID, req.CreatedBy)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to reassign job owner")
+		s.writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	if existing.CreatedBy != job.CreatedBy {
+		actor := "anonymous"
+		if user := auth.UserFromContext(r.Context()); user != nil {
+			actor = user.Username
+		}
+
+		if err := s.store.CreateAuditEntry(r.Context(), &store.AuditEntry{
+			ID:         uuid.New().String(),
+			Action:     store.AuditActionJobReassigned,
+			EntityType: store.AuditEntityJob,
+			EntityID:   jobID,
+			Actor:      actor,
+			Details:    fmt.Sprintf("Reassigned from %q to %q", existing.CreatedBy, job.CreatedBy),
+			CreatedAt:  time.Now(),
+		}); err != nil {
+			s.log.WithError(err).Warn("Failed to create audit entry for job reassignment")
+		}
+	}
+
+	s.writeJSON(w, http.StatusOK, job)
+}
+
+// RequeueJobRequest is the request body for requeueing a finished job.
+type RequeueJobRequest struct {
+	// GroupID is the group to requeue into (defaults to the original job's group).
+	GroupID string `json:"group_id,omitempty" example:"my-group"`
+	// Inputs override the inputs the original job ran with.
+	Inputs input.Map `json:"inputs,omitempty"`
+}
+
+// handleRequeueJob godoc
+//
+//	@Summary		Requeue job
+//	@Description	Creates a new pending job from a completed, failed, or cancelled job, optionally with modified inputs or in another group (requires admin)
+//	@Tags			jobs
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Job ID"
+//	@Param			body	body		RequeueJobRequest	false	"Requeue options"
+//	@Success		201		{object}	store.Job
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/jobs/{id}/requeue [post]
+func (s *server) handleRequeueJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+
+	var req RequeueJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if msg := s.checkJobLimits(req.Inputs, nil); msg != "" {
+		s.writeError(w, http.StatusBadRequest, msg)
+
+		return
+	}
+
+	original, err := s.queue.GetJob(r.Context(), jobID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get job")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get job")
+
+		return
+	}
+
+	if original == nil {
+		s.writeError(w, http.StatusNotFound, "Job not found")
+
+		return
+	}
+
+	if req.GroupID != "" && req.GroupID != original.GroupID {
+		group, err := s.store.GetGroup(r.Context(), req.GroupID)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to get group")
+			s.writeError(w, http.StatusInternalServerError, "Failed to get group")
+
+			return
+		}
+
+		if group == nil {
+			s.writeError(w, http.StatusBadRequest, "Target group not found")
+
+			return
+		}
+	}
+
+	createdBy := "anonymous"
+	if user := auth.UserFromContext(r.Context()); user != nil {
+		createdBy = user.Username
+	}
+
+	job, err := s.queue.Requeue(r.Context(), jobID, req.GroupID, createdBy, req.Inputs)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to requeue job")
+		s.writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	s.setInputOverrides(r.Context(), job)
+
+	s.writeJSON(w, http.StatusCreated, job)
+}
+
+// ReorderQueueRequest is the request body for reordering the job queue.
+type ReorderQueueRequest struct {
+	JobIDs []string `json:"job_ids" example:"job-1,job-2,job-3"`
+}
+
+// handleReorderQueue godoc
+//
+//	@Summary		Reorder queue
+//	@Description	Reorders jobs in the queue by specifying the desired order of job IDs
+//	@Tags			queue
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string					true	"Group ID"
+//	@Param			body	body	ReorderQueueRequest		true	"New job order"
+//	@Success		204		"Queue reordered successfully"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Router			/groups/{id}/queue/reorder [put]
+func (s *server) handleReorderQueue(w http.ResponseWriter, r *http.Request) {
+	groupID := chi.URLParam(r, "id")
+
+	var req ReorderQueueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if len(req.JobIDs) == 0 {
+		s.writeError(w, http.StatusBadRequest, "job_ids is required")
+
+		return
+	}
+
+	if err := s.queue.Reorder(r.Context(), groupID, req.JobIDs); err != nil {
+		s.log.WithError(err).Error("Failed to reorder queue")
+		s.writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// QueueChangesResponse is a page of a group's queue changes, newest first.
+type QueueChangesResponse struct {
+	Changes []*store.QueueChange `json:"changes"`
+	Total   int                  `json:"total" example:"42"`
+}
+
+// handleGetQueueChanges godoc
+//
+//	@Summary		Get queue changes
+//	@Description	Returns who reordered, paused, unpaused, reprioritized or deleted jobs in a group's queue, newest first, with the queue state of the affected jobs before and after each change
+//	@Tags			queue
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id		path		string	true	"Group ID"
+//	@Param			job_id	query		string	false	"Only changes affecting this job, including reorders that moved it"
+//	@Param			limit	query		int		false	"Number of changes to return (max 500)"	default(50)
+//	@Param			offset	query		int		false	"Number of changes to skip"
+//	@Success		200		{object}	QueueChangesResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/groups/{id}/queue/changes [get]
+func (s *server) handleGetQueueChanges(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	groupID := chi.URLParam(r, "id")
+
+	group, err := s.store.GetGroup(ctx, groupID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get group")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get group")
+
+		return
+	}
+
+	if group == nil {
+		s.writeError(w, http.StatusNotFound, "Group not found")
+
+		return
+	}
+
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 500 {
+			limit = l
+		}
+	}
+
+	offset := 0
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o > 0 {
+			offset = o
+		}
+	}
+
+	changes, total, err := s.store.ListQueueChanges(ctx, store.QueueChangeQueryOpts{
+		GroupID: groupID,
+		JobID:   r.URL.Query().Get("job_id"),
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list queue changes")
+		s.writeError(w, http.StatusInternalServerError, "Failed to list queue changes")
+
+		return
+	}
+
+	if changes == nil {
+		changes = []*store.QueueChange{}
+	}
+
+	s.writeJSON(w, http.StatusOK, QueueChangesResponse{Changes: changes, Total: total})
+}
+
+// CompactQueueResponse is the response for compacting a group's queue.
+type CompactQueueResponse struct {
+	// Updated is the number of pending jobs whose position changed.
+	Updated int `json:"updated" example:"12"`
+}
+
+// handleCompactQueue godoc
+//
+//	@Summary		Compact queue
+//	@Description	Renumbers pending job positions of a group to consecutive values, keeping their order (requires admin)
+//	@Tags			queue
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Group ID"
+//	@Success		200	{object}	CompactQueueResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/groups/{id}/queue/compact [post]
+func (s *server) handleCompactQueue(w http.ResponseWriter, r *http.Request) {
+	groupID := chi.URLParam(r, "id")
+
+	group, err := s.store.GetGroup(r.Context(), groupID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get group")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get group")
+
+		return
+	}
+
+	if group == nil {
+		s.writeError(w, http.StatusNotFound, "Group not found")
+
+		return
+	}
+
+	updated, err := s.queue.Compact(r.Context(), groupID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to compact queue")
+		s.writeError(w, http.StatusInternalServerError, "Failed to compact queue")
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, CompactQueueResponse{Updated: updated})
+}
+
+// ============================================================================
+// Status Types
+// ============================================================================
+
+// ComponentStatus represents health status of a component.
+type ComponentStatus string
+
+const (
+	ComponentStatusHealthy   ComponentStatus = "healthy"
+	ComponentStatusDegraded  ComponentStatus = "degraded"
+	ComponentStatusUnhealthy ComponentStatus = "unhealthy"
+)
+
+// DatabaseStatus contains database health information.
+type DatabaseStatus struct {
 	Status  ComponentStatus `json:"status"`
 	Latency string          `json:"latency,omitempty"`
 	Error   string          `json:"error,omitempty"`
+	// Maintenance is set when database.maintenance is enabled.
+	Maintenance *MaintenanceStatus `json:"maintenance,omitempty"`
+}
+
+// MaintenanceStatus describes periodic database maintenance and its last run.
+type MaintenanceStatus struct {
+	Status    ComponentStatus         `json:"status"`
+	Interval  string                  `json:"interval" example:"24h0m0s"`
+	NextRunAt string                  `json:"next_run_at,omitempty"`
+	LastRunAt string                  `json:"last_run_at,omitempty"`
+	Duration  string                  `json:"duration,omitempty"`
+	Tasks     []MaintenanceTaskStatus `json:"tasks,omitempty"`
+	Error     string                  `json:"error,omitempty"`
+}
+
+// MaintenanceTaskStatus is the outcome of one statement in the last maintenance run.
+type MaintenanceTaskStatus struct {
+	Task     string `json:"task" example:"VACUUM ANALYZE jobs"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+// newMaintenanceStatus converts the maintenance scheduler's status for /status.
+func newMaintenanceStatus(status maintenance.Status) *MaintenanceStatus {
+	resp := &MaintenanceStatus{
+		Status:   ComponentStatusHealthy,
+		Interval: status.Interval.String(),
+		Error:    status.Error,
+	}
+
+	if !status.NextRunAt.IsZero() {
+		resp.NextRunAt = status.NextRunAt.UTC().Format(time.RFC3339)
+	}
+
+	if status.LastRunAt != nil {
+		resp.LastRunAt = status.LastRunAt.UTC().Format(time.RFC3339)
+		resp.Duration = status.Duration.Round(time.Millisecond).String()
+	}
+
+	for _, result := range status.Results {
+		resp.Tasks = append(resp.Tasks, MaintenanceTaskStatus{
+			Task:     result.Task,
+			Duration: result.Duration.Round(time.Millisecond).String(),
+			Error:    result.Error,
+		})
+	}
+
+	if status.Error != "" {
+		resp.Status = ComponentStatusDegraded
+	}
+
+	return resp
+}
+
+// GitHubClientStatus contains status and rate limit information for a single GitHub client.
+type GitHubClientStatus struct {
+	Status             ComponentStatus `json:"status"`
+	Connected          bool            `json:"connected"`
+	Error              string          `json:"error,omitempty"`
+	RateLimitRemaining int             `json:"rate_limit_remaining"`
+	RateLimitReset     string          `json:"rate_limit_reset,omitempty"`
+	ResetIn            string          `json:"reset_in,omitempty"`
+}
+
+// GitHubClientsStatus contains status for both GitHub clients.
+type GitHubClientsStatus struct {
+	Runners  *GitHubClientStatus `json:"runners,omitempty"`
+	Dispatch *GitHubClientStatus `json:"dispatch,omitempty"`
+}
+
+// PermissionStatus is the result of a startup check that a GitHub token has a
+// permission dispatchoor needs.
+type PermissionStatus struct {
+	Target     string   `json:"target" example:"ethpandaops/ethereum-package"`
+	Permission string   `json:"permission" example:"actions:write"`
+	OK         bool     `json:"ok"`
+	Error      string   `json:"error,omitempty"`
+	Templates  []string `json:"templates,omitempty"`
+}
+
+// QueueStats contains queue statistics.
+type QueueStats struct {
+	PendingJobs   int `json:"pending_jobs"`
+	TriggeredJobs int `json:"triggered_jobs"`
+	RunningJobs   int `json:"running_jobs"`
+}
+
+// VersionInfo contains build version information.
+type VersionInfo struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildDate string `json:"build_date"`
+}
+
+// SystemStatusResponse is the comprehensive status response.
+type SystemStatusResponse struct {
+	Status    ComponentStatus     `json:"status"`
+	Timestamp string              `json:"timestamp"`
+	Database  DatabaseStatus      `json:"database"`
+	GitHub    GitHubClientsStatus `json:"github"`
+	Queue     QueueStats          `json:"queue"`
+	Version   VersionInfo         `json:"version"`
+	// Permissions lists startup permission checks (omitted until they have run).
+	Permissions []PermissionStatus `json:"permissions,omitempty"`
+	// RunnerPools lists the groups that set min_online_runners.
+	RunnerPools []RunnerPoolStatus `json:"runner_pools,omitempty"`
+}
+
+// RunnerPoolStatus compares the online runners of a group with its
+// min_online_runners. The status is degraded while dispatch is held.
+type RunnerPoolStatus struct {
+	GroupID          string          `json:"group_id" example:"sysadmin"`
+	Status           ComponentStatus `json:"status"`
+	OnlineRunners    int             `json:"online_runners" example:"1"`
+	MinOnlineRunners int             `json:"min_online_runners" example:"3"`
+}
+
+// HistoryResponse wraps the paginated history response.
+type HistoryResponse struct {
+	Jobs         []*store.Job `json:"jobs"`
+	HasMore      bool         `json:"has_more" example:"true"`
+	NextCursor   string       `json:"next_cursor,omitempty" example:"2024-01-15T10:30:00Z"`
+	NextCursorID string       `json:"next_cursor_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// TotalCount is omitted when the request sets count=false. TotalCountCached
+	// is set when it was served from a short-lived cache.
+	TotalCount       *int `json:"total_count,omitempty" example:"150"`
+	TotalCountCached bool `json:"total_count_cached,omitempty" example:"false"`
+}
+
+// handleGetHistory godoc
+//
+//	@Summary		Get job history
+//	@Description	Returns paginated history of completed, failed, and cancelled jobs
+//	@Tags			history
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id			path		string	true	"Group ID"
+//	@Param			limit		query		int		false	"Number of jobs to return (max 100)"	default(50)
+//	@Param			before		query		string	false	"Cursor for pagination (RFC3339 timestamp)"
+//	@Param			before_id	query		string	false	"Cursor tie-breaker (next_cursor_id of the previous page)"
+//	@Param			offset		query		int		false	"Number of jobs to skip (alternative to cursor pagination)"
+//	@Param			count		query		bool	false	"Set to false to skip computing total_count"	default(true)
+//	@Param			status		query		string	false	"Filter by status (comma-separated: completed,failed,cancelled)"
+//	@Param			template_id	query		string	false	"Filter by template ID"
+//	@Param			retained	query		bool	false	"Set to true to list retained jobs only"
+//	@Param			assignee	query		string	false	"List jobs assigned to this user; me is the caller"
+//	@Success		200			{object}	HistoryResponse
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/groups/{id}/history [get]
+func (s *server) handleGetHistory(w http.ResponseWriter, r *http.Request) {
+	groupID := chi.URLParam(r, "id")
+
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	var before *time.Time
+
+	if beforeStr := r.URL.Query().Get("before"); beforeStr != "" {
+		t, err := time.Parse(time.RFC3339Nano, beforeStr)
+		if err == nil {
+			before = &t
+		}
+	}
+
+	offset := 0
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o > 0 {
+			offset = o
+		}
+	}
+
+	skipCount := r.URL.Query().Get("count") == "false"
+
+	// Parse status filter (comma-separated).
+	var statuses []store.JobStatus
+
+	if statusStr := r.URL.Query().Get("status"); statusStr != "" {
+		statusParts := strings.Split(statusStr, ",")
+		for _, st := range statusParts {
+			st = strings.TrimSpace(st)
+			switch st {
+			case "completed":
+				statuses = append(statuses, store.JobStatusCompleted)
+			case "failed":
+				statuses = append(statuses, store.JobStatusFailed)
+			case "cancelled":
+				statuses = append(statuses, store.JobStatusCancelled)
+			}
+		}
+	}
+
+	// Parse label filters (label.KEY=VALUE).
+	labels := make(map[string]string)
+
+	for key, values := range r.URL.Query() {
+		if strings.HasPrefix(key, "label.") && len(values) > 0 {
+			labelKey := strings.TrimPrefix(key, "label.")
+			labels[labelKey] = values[0]
+		}
+	}
+
+	opts := store.HistoryQueryOpts{
+		GroupID:      groupID,
+		Limit:        limit,
+		Before:       before,
+		BeforeID:     r.URL.Query().Get("before_id"),
+		Offset:       offset,
+		Statuses:     statuses,
+		Labels:       labels,
+		TemplateID:   r.URL.Query().Get("template_id"),
+		SkipCount:    skipCount,
+		RetainedOnly: r.URL.Query().Get("retained") == "true",
+		Assignee:     r.URL.Query().Get("assignee"),
+	}
+
+	if user := auth.UserFromContext(r.Context()); user != nil && opts.Assignee == "me" {
+		opts.Assignee = user.Username
+	}
+
+	result, err := s.queue.ListHistoryPaginated(r.Context(), opts)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get history")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get history")
+
+		return
+	}
+
+	resp := HistoryResponse{
+		Jobs:    result.Jobs,
+		HasMore: result.HasMore,
+	}
+
+	if !skipCount {
+		resp.TotalCount = &result.TotalCount
+		resp.TotalCountCached = result.CountCached
+	}
+
+	if result.NextCursor != nil {
+		resp.NextCursor = result.NextCursor.Format(time.RFC3339Nano)
+		resp.NextCursorID = result.NextCursorID
+	}
+
+	if resp.Jobs == nil {
+		resp.Jobs = []*store.Job{}
+	}
+
+	s.setInputOverrides(r.Context(), resp.Jobs...)
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// HistoryStatsResponse wraps the aggregated history statistics.
+type HistoryStatsResponse struct {
+	Buckets []HistoryStatsBucket `json:"buckets"`
+	Range   HistoryStatsRange    `json:"range"`
+	Totals  HistoryStatsTotals   `json:"totals"`
+	Series  []HistoryStatsSeries `json:"series,omitempty"`
+}
+
+// HistoryStatsSeries contains the job counts for a single group_by dimension value.
+type HistoryStatsSeries struct {
+	Key     string               `json:"key" example:"sync-test-hoodi"`
+	Buckets []HistoryStatsBucket `json:"buckets"`
+	Totals  HistoryStatsTotals   `json:"totals"`
+}
+
+// HistoryStatsBucket represents job counts in a time bucket.
+type HistoryStatsBucket struct {
+	Timestamp string `json:"timestamp" example:"2024-01-15T10:00:00Z"`
+	Completed int    `json:"completed" example:"5"`
+	Failed    int    `json:"failed" example:"1"`
+	Cancelled int    `json:"cancelled" example:"0"`
+}
+
+// HistoryStatsRange describes the time range of the statistics.
+type HistoryStatsRange struct {
+	Start          string `json:"start" example:"2024-01-15T00:00:00Z"`
+	End            string `json:"end" example:"2024-01-16T00:00:00Z"`
+	BucketDuration string `json:"bucket_duration" example:"1h0m0s"`
+}
+
+// HistoryStatsTotals contains total counts across all buckets.
+type HistoryStatsTotals struct {
+	Completed int `json:"completed" example:"120"`
+	Failed    int `json:"failed" example:"15"`
+	Cancelled int `json:"cancelled" example:"5"`
+}
+
+// handleGetHistoryStats godoc
+//
+//	@Summary		Get history statistics
+//	@Description	Returns aggregated job statistics over a time range, optionally split by template, label, or creator
+//	@Tags			history
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id			path		string	true	"Group ID"
+//	@Param			range		query		string	false	"Time range (1h, 6h, 24h, 7d, 30d, auto)"	default(auto)
+//	@Param			group_by	query		string	false	"Split counts into series by dimension (template_id, label, created_by, variant)"
+//	@Param			label_key	query		string	false	"Label key to group by (required when group_by=label)"
+//	@Success		200			{object}	HistoryStatsResponse
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/groups/{id}/history/stats [get]
+func (s *server) handleGetHistoryStats(w http.ResponseWriter, r *http.Request) {
+	groupID := chi.URLParam(r, "id")
+
+	// Parse time range parameter.
+	rangeStr := r.URL.Query().Get("range")
+	if rangeStr == "" {
+		rangeStr = "auto"
+	}
+
+	now := time.Now()
+	var start, end time.Time
+	var buckets int
+
+	end = now
+
+	switch rangeStr {
+	case "1h":
+		start = now.Add(-1 * time.Hour)
+		buckets = 12 // 5 minute intervals
+	case "6h":
+		start = now.Add(-6 * time.Hour)
+		buckets = 24 // 15 minute intervals
+	case "24h":
+		start = now.Add(-24 * time.Hour)
+		buckets = 24 // 1 hour intervals
+	case "7d":
+		start = now.Add(-7 * 24 * time.Hour)
+		buckets = 28 // 6 hour intervals
+	case "30d":
+		start = now.Add(-30 * 24 * time.Hour)
+		buckets = 30 // 1 day intervals
+	case "auto":
+		// For auto mode, show all jobs from oldest to now.
+		oldestTime, _, err := s.store.GetHistoryTimeBounds(r.Context(), groupID)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to get history time bounds")
+			s.writeError(w, http.StatusInternalServerError, "Failed to get history stats")
+
+			return
+		}
+
+		if oldestTime == nil {
+			// No history data, return empty buckets for 24h.
+			start = now.Add(-24 * time.Hour)
+			buckets = 24
+		} else {
+			// Set start to oldest job time (with small buffer).
+			start = oldestTime.Add(-1 * time.Minute)
+
+			// Calculate appropriate number of buckets based on span.
+			span := now.Sub(start)
+
+			if span > 30*24*time.Hour {
+				buckets = 30 // ~1 day per bucket
+			} else if span > 7*24*time.Hour {
+				buckets = 28 // ~6 hours per bucket
+			} else if span > 24*time.Hour {
+				buckets = 24 // ~1 hour per bucket
+			} else if span > 6*time.Hour {
+				buckets = 24 // ~15 min per bucket
+			} else if span > 1*time.Hour {
+				buckets = 12 // ~5 min per bucket
+			} else {
+				buckets = 12 // Small intervals
+			}
+		}
+	default:
+		s.writeError(w, http.StatusBadRequest, "Invalid range parameter")
+
+		return
+	}
+
+	groupBy := store.HistoryStatsDimension(r.URL.Query().Get("group_by"))
+	labelKey := r.URL.Query().Get("label_key")
+
+	switch groupBy {
+	case "", store.HistoryStatsByTemplate, store.HistoryStatsByCreatedBy, store.HistoryStatsByVariant:
+	case store.HistoryStatsByLabel:
+		if labelKey == "" {
+			s.writeError(w, http.StatusBadRequest, "label_key is required when group_by=label")
+
+			return
+		}
+	default:
+		s.writeError(w, http.StatusBadRequest, "Invalid group_by parameter")
+
+		return
+	}
+
+	opts := store.HistoryStatsOpts{
+		GroupID:  groupID,
+		Start:    start,
+		End:      end,
+		Buckets:  buckets,
+		GroupBy:  groupBy,
+		LabelKey: labelKey,
+	}
+
+	result, err := s.store.GetHistoryStats(r.Context(), opts)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get history stats")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get history stats")
+
+		return
+	}
+
+	// Convert to response format with string timestamps.
+	resp := HistoryStatsResponse{
+		Buckets: toHistoryStatsBuckets(result.Buckets),
+		Range: HistoryStatsRange{
+			Start:          result.Range.Start.Format(time.RFC3339),
+			End:            result.Range.End.Format(time.RFC3339),
+			BucketDuration: result.Range.BucketDuration.String(),
+		},
+		Totals: toHistoryStatsTotals(result.Totals),
+	}
+
+	for _, series := range result.Series {
+		resp.Series = append(resp.Series, HistoryStatsSeries{
+			Key:     series.Key,
+			Buckets: toHistoryStatsBuckets(series.Buckets),
+			Totals:  toHistoryStatsTotals(series.Totals),
+		})
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// toHistoryStatsBuckets converts store buckets to the response format with string timestamps.
+func toHistoryStatsBuckets(buckets []*store.HistoryStatsBucket) []HistoryStatsBucket {
+	out := make([]HistoryStatsBucket, len(buckets))
+	for i, bucket := range buckets {
+		out[i] = HistoryStatsBucket{
+			Timestamp: bucket.Timestamp.Format(time.RFC3339),
+			Completed: bucket.Completed,
+			Failed:    bucket.Failed,
+			Cancelled: bucket.Cancelled,
+		}
+	}
+
+	return out
+}
+
+// toHistoryStatsTotals converts store totals to the response format.
+func toHistoryStatsTotals(totals store.HistoryStatsTotals) HistoryStatsTotals {
+	return HistoryStatsTotals{
+		Completed: totals.Completed,
+		Failed:    totals.Failed,
+		Cancelled: totals.Cancelled,
+	}
+}
+
+// MatchingReportResponse is the response for the matching report.
+type MatchingReportResponse struct {
+	GeneratedAt       time.Time                      `json:"generated_at"`
+	DispatcherEnabled bool                           `json:"dispatcher_enabled"`
+	Groups            []*dispatcher.GroupMatchReport `json:"groups"`
+}
+
+// handleGetDatabaseStats godoc
+//
+//	@Summary		Get database stats
+//	@Description	Returns the size of the database and the row count and on-disk size of each table, to watch the growth of the jobs and audit tables. Row counts are estimates for PostgreSQL, and SQLite table sizes need the dbstat table (requires admin).
+//	@Tags			admin
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{object}	store.DatabaseStats
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/admin/database [get]
+func (s *server) handleGetDatabaseStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.store.DatabaseStats(r.Context())
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get database stats")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get database stats")
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, stats)
+}
+
+// handleMatchingReport godoc
+//
+//	@Summary		Get matching report
+//	@Description	Lists, for each group, the runners matching its labels and, for each pending job, why it can or cannot be dispatched right now: dispatchable, dispatcher_disabled, group_disabled, group_paused, triggered_jobs_pending, job_paused, outside_dispatch_window, queued_behind, no_idle_runner or not_enough_idle_runners. Nothing is dispatched (requires admin).
+//	@Tags			admin
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{object}	MatchingReportResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/admin/matching-report [get]
+func (s *server) handleMatchingReport(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+
+	groups, err := dispatcher.MatchingReport(r.Context(), s.log, s.cfg, s.store, s.queue, now)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to build matching report")
+		s.writeError(w, http.StatusInternalServerError, "Failed to build matching report")
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, MatchingReportResponse{
+		GeneratedAt:       now,
+		DispatcherEnabled: s.cfg.Dispatcher.Enabled,
+		Groups:            groups,
+	})
 }
 
-// GitHubClientStatus contains status and rate limit information for a single GitHub client.
-type GitHubClientStatus struct {
-	Status             ComponentStatus `json:"status"`
-	Connected          bool            `json:"connected"`
-	Error              string          `json:"error,omitempty"`
-	RateLimitRemaining int             `json:"rate_limit_remaining"`
-	RateLimitReset     string          `json:"rate_limit_reset,omitempty"`
-	ResetIn            string          `json:"reset_in,omitempty"`
+// handleRefreshRunners godoc
+//
+//	@Summary		Refresh runners
+//	@Description	Triggers a refresh of runner information from GitHub (requires admin)
+//	@Tags			runners
+//	@Security		BearerAuth
+//	@Success		204	"Runners refresh initiated"
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Router			/runners/refresh [post]
+func (s *server) handleRefreshRunners(w http.ResponseWriter, _ *http.Request) {
+	// TODO: Implement runner refresh by calling poller.ForceRefresh()
+	// For now, just return success.
+	w.WriteHeader(http.StatusNoContent)
 }
 
-// GitHubClientsStatus contains status for both GitHub clients.
-type GitHubClientsStatus struct {
-	Runners  *GitHubClientStatus `json:"runners,omitempty"`
-	Dispatch *GitHubClientStatus `json:"dispatch,omitempty"`
+// handleWebSocket godoc
+//
+//	@Summary		WebSocket connection
+//	@Description	Establishes a WebSocket connection for real-time job and runner updates
+//	@Tags			websocket
+//	@Param			token	query	string	false	"Authentication token"
+//	@Success		101		"WebSocket connection established"
+//	@Failure		401		{object}	ErrorResponse
+//	@Router			/ws [get]
+func (s *server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	ServeWs(s.hub, s.auth, s.cfg.Server.CORSOrigins, w, r)
+}
+
+// ============================================================================
+// Auth Handlers
+// ============================================================================
+
+// LoginRequest is the request body for username/password login.
+type LoginRequest struct {
+	Username string `json:"username" example:"admin"`
+	Password string `json:"password" example:"password123"`
+}
+
+// LoginResponse is the response for successful authentication.
+type LoginResponse struct {
+	Token string      `json:"token" example:"eyJhbGciOiJIUzI1NiIs..."`
+	User  *store.User `json:"user"`
+	// RefreshToken and ExpiresAt are set with JWT sessions, where Token is a
+	// short-lived access token to renew at POST /auth/refresh.
+	RefreshToken string     `json:"refresh_token,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+}
+
+// The refresh token cookie of JWT sessions, only sent to the refresh endpoint.
+const (
+	refreshCookieName = "refresh_token"
+	refreshCookiePath = "/api/v1/auth/refresh"
+)
+
+// startSession sets the cookies for a new session token and returns the login
+// response for it. With JWT sessions the session token becomes the refresh
+// token and the session cookie holds a short-lived access token.
+func (s *server) startSession(w http.ResponseWriter, r *http.Request, user *store.User, token string) (*LoginResponse, error) {
+	resp := &LoginResponse{Token: token, User: user}
+	maxAge := s.cfg.Auth.SessionTTL
+
+	if s.cfg.Auth.JWT.Enabled {
+		tokenUser, accessToken, expiresAt, err := s.auth.IssueAccessToken(r.Context(), token)
+		if err != nil {
+			return nil, fmt.Errorf("issuing access token: %w", err)
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     refreshCookieName,
+			Value:    token,
+			Path:     refreshCookiePath,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+			Secure:   s.isSecureRequest(r),
+			MaxAge:   int(s.cfg.Auth.SessionTTL.Seconds()),
+		})
+
+		resp = &LoginResponse{Token: accessToken, User: tokenUser, RefreshToken: token, ExpiresAt: &expiresAt}
+		maxAge = time.Until(expiresAt)
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "session",
+		Value:    resp.Token,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Secure:   s.isSecureRequest(r),
+		MaxAge:   int(maxAge.Seconds()),
+	})
+
+	s.setCSRFCookie(w, r, resp.Token)
+
+	return resp, nil
+}
+
+// RefreshRequest is the request body for renewing a JWT access token.
+type RefreshRequest struct {
+	// RefreshToken defaults to the refresh_token cookie.
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// handleLogin godoc
+//
+//	@Summary		Login with username and password
+//	@Description	Authenticates a user with username and password, returns JWT token
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		LoginRequest	true	"Login credentials"
+//	@Success		200		{object}	LoginResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		429		{object}	RateLimitErrorResponse	"Rate limit exceeded"
+//	@Router			/auth/login [post]
+func (s *server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if req.Username == "" || req.Password == "" {
+		s.writeError(w, http.StatusBadRequest, "Username and password are required")
+
+		return
+	}
+
+	user, token, err := s.auth.AuthenticateBasic(r.Context(), req.Username, req.Password)
+	if err != nil {
+		s.log.WithError(err).WithField("username", req.Username).Warn("Login failed")
+		s.writeError(w, http.StatusUnauthorized, "Invalid credentials")
+
+		return
+	}
+
+	resp, err := s.startSession(w, r, user, token)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to start session")
+		s.writeError(w, http.StatusInternalServerError, "Failed to start session")
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleLogout godoc
+//
+//	@Summary		Logout
+//	@Description	Logs out the current user and invalidates the session
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Success		204	"Logged out successfully"
+//	@Failure		401	{object}	ErrorResponse
+//	@Router			/auth/logout [post]
+func (s *server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	// Get token from cookie or header.
+	token := ""
+
+	if cookie, err := r.Cookie("session"); err == nil {
+		token = cookie.Value
+	}
+
+	if token == "" {
+		authHeader := r.Header.Get("Authorization")
+		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
+			token = authHeader[7:]
+		}
+	}
+
+	if token != "" {
+		if err := s.auth.Logout(r.Context(), token); err != nil {
+			s.log.WithError(err).Warn("Logout error")
+		}
+	}
+
+	// Clear session cookie.
+	http.SetCookie(w, &http.Cookie{
+		Name:     "session",
+		Value:    "",
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Secure:   s.isSecureRequest(r),
+		MaxAge:   -1,
+	})
+
+	if s.cfg.Auth.JWT.Enabled {
+		http.SetCookie(w, &http.Cookie{
+			Name:     refreshCookieName,
+			Value:    "",
+			Path:     refreshCookiePath,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+			Secure:   s.isSecureRequest(r),
+			MaxAge:   -1,
+		})
+	}
+
+	s.setCSRFCookie(w, r, "")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MeResponse is the current user, with the admin acting as them when the
+// request is impersonated.
+type MeResponse struct {
+	*store.User
+	ImpersonatedBy string `json:"impersonated_by,omitempty"`
+}
+
+// handleMe godoc
+//
+//	@Summary		Get current user
+//	@Description	Returns the currently authenticated user's information, or that of the user an admin acts as with the X-Act-As header
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{object}	MeResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Router			/auth/me [get]
+func (s *server) handleMe(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		s.writeError(w, http.StatusUnauthorized, "Not authenticated")
+
+		return
+	}
+
+	resp := MeResponse{User: user}
+	if admin := auth.ImpersonatorFromContext(r.Context()); admin != nil {
+		resp.ImpersonatedBy = admin.Username
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// SessionResponse is an active session with the username it belongs to.
+type SessionResponse struct {
+	*store.Session
+	Username string `json:"username"`
+	// Current is set on the session the request was made with.
+	Current bool `json:"current"`
+}
+
+// SessionsResponse is the response for listing sessions.
+type SessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+// handleListSessions godoc
+//
+//	@Summary		List sessions
+//	@Description	Returns the current user's active sessions, newest first, with the IP address, user agent, and time of their last use. Admins can pass all=true to list every user's sessions.
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			all	query		bool	false	"List sessions of all users (admin only)"
+//	@Success		200	{object}	SessionsResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/auth/sessions [get]
+func (s *server) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		s.writeError(w, http.StatusUnauthorized, "Not authenticated")
+
+		return
+	}
+
+	all := r.URL.Query().Get("all") == "true"
+	if all && !s.auth.IsAdmin(user) {
+		s.writeError(w, http.StatusForbidden, "Only admins can list all sessions")
+
+		return
+	}
+
+	userID := user.ID
+	if all {
+		userID = ""
+	}
+
+	sessions, err := s.auth.ListSessions(r.Context(), userID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list sessions")
+		s.writeError(w, http.StatusInternalServerError, "Failed to list sessions")
+
+		return
+	}
+
+	usernames := map[string]string{user.ID: user.Username}
+
+	if all {
+		users, err := s.store.ListUsers(r.Context())
+		if err != nil {
+			s.log.WithError(err).Error("Failed to list users")
+			s.writeError(w, http.StatusInternalServerError, "Failed to list sessions")
+
+			return
+		}
+
+		for _, u := range users {
+			usernames[u.ID] = u.Username
+		}
+	}
+
+	var currentID string
+
+	if current, err := s.auth.CurrentSession(r.Context(), auth.TokenFromRequest(r)); err != nil {
+		s.log.WithError(err).Warn("Failed to resolve current session")
+	} else if current != nil {
+		currentID = current.ID
+	}
+
+	resp := SessionsResponse{Sessions: make([]SessionResponse, 0, len(sessions))}
+
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, SessionResponse{
+			Session:  session,
+			Username: usernames[session.UserID],
+			Current:  session.ID == currentID,
+		})
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
 }
 
-// QueueStats contains queue statistics.
-type QueueStats struct {
-	PendingJobs   int `json:"pending_jobs"`
-	TriggeredJobs int `json:"triggered_jobs"`
-	RunningJobs   int `json:"running_jobs"`
-}
+// handleRevokeSession godoc
+//
+//	@Summary		Revoke session
+//	@Description	Revokes one of the current user's sessions. Admins can revoke any user's session.
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Param			id	path	string	true	"Session ID"
+//	@Success		204	"Session revoked"
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/auth/sessions/{id} [delete]
+func (s *server) handleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		s.writeError(w, http.StatusUnauthorized, "Not authenticated")
+
+		return
+	}
+
+	if err := s.auth.RevokeSession(r.Context(), user, chi.URLParam(r, "id")); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			s.writeError(w, http.StatusNotFound, "Session not found")
+
+			return
+		}
+
+		s.log.WithError(err).Error("Failed to revoke session")
+		s.writeError(w, http.StatusInternalServerError, "Failed to revoke session")
 
-// VersionInfo contains build version information.
-type VersionInfo struct {
-	Version   string `json:"version"`
-	GitCommit string `json:"git_commit"`
-	BuildDate string `json:"build_date"`
-}
+		return
+	}
 
-// SystemStatusResponse is the comprehensive status response.
-type SystemStatusResponse struct {
-	Status    ComponentStatus     `json:"status"`
-	Timestamp string              `json:"timestamp"`
-	Database  DatabaseStatus      `json:"database"`
-	GitHub    GitHubClientsStatus `json:"github"`
-	Queue     QueueStats          `json:"queue"`
-	Version   VersionInfo         `json:"version"`
+	w.WriteHeader(http.StatusNoContent)
 }
 
-// HistoryResponse wraps the paginated history response.
-type HistoryResponse struct {
-	Jobs       []*store.Job `json:"jobs"`
-	HasMore    bool         `json:"has_more" example:"true"`
-	NextCursor string       `json:"next_cursor,omitempty" example:"2024-01-15T10:30:00Z"`
-	TotalCount int          `json:"total_count" example:"150"`
+// SavedFilterRequest is the request body for creating or updating a saved filter.
+type SavedFilterRequest struct {
+	Name string                `json:"name" example:"Failed hoodi syncs"`
+	View store.SavedFilterView `json:"view" example:"history"`
+	// GroupID restricts the filter to one group (empty = any group).
+	GroupID  string            `json:"group_id,omitempty" example:"sync-tests"`
+	Statuses []store.JobStatus `json:"statuses,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	// TemplateID restricts the filter to one template (empty = all templates).
+	TemplateID string `json:"template_id,omitempty"`
 }
 
-// handleGetHistory godoc
-//
-//	@Summary		Get job history
-//	@Description	Returns paginated history of completed, failed, and cancelled jobs
-//	@Tags			history
-//	@Security		BearerAuth
-//	@Produce		json
-//	@Param			id		path		string	true	"Group ID"
-//	@Param			limit	query		int		false	"Number of jobs to return (max 100)"	default(50)
-//	@Param			before	query		string	false	"Cursor for pagination (RFC3339 timestamp)"
-//	@Param			status	query		string	false	"Filter by status (comma-separated: completed,failed,cancelled)"
-//	@Success		200		{object}	HistoryResponse
-//	@Failure		401		{object}	ErrorResponse
-//	@Failure		500		{object}	ErrorResponse
-//	@Router			/groups/{id}/history [get]
-func (s *server) handleGetHistory(w http.ResponseWriter, r *http.Request) {
-	groupID := chi.URLParam(r, "id")
+// maxSavedFilterNameLength is the maximum length of a saved filter name.
+const maxSavedFilterNameLength = 100
 
-	limit := 50
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
+// validateSavedFilter checks a saved filter request and returns a user-facing error message.
+func (s *server) validateSavedFilter(ctx context.Context, req *SavedFilterRequest) (string, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return "Name is required", nil
 	}
 
-	var before *time.Time
+	if len(req.Name) > maxSavedFilterNameLength {
+		return fmt.Sprintf("Name must be at most %d characters", maxSavedFilterNameLength), nil
+	}
 
-	if beforeStr := r.URL.Query().Get("before"); beforeStr != "" {
-		t, err := time.Parse(time.RFC3339Nano, beforeStr)
-		if err == nil {
-			before = &t
-		}
+	if msg := s.checkJobLimits(nil, req.Labels); msg != "" {
+		return msg, nil
 	}
 
-	// Parse status filter (comma-separated).
-	var statuses []store.JobStatus
+	var allowed []store.JobStatus
 
-	if statusStr := r.URL.Query().Get("status"); statusStr != "" {
-		statusParts := strings.Split(statusStr, ",")
-		for _, st := range statusParts {
-			st = strings.TrimSpace(st)
-			switch st {
-			case "completed":
-				statuses = append(statuses, store.JobStatusCompleted)
-			case "failed":
-				statuses = append(statuses, store.JobStatusFailed)
-			case "cancelled":
-				statuses = append(statuses, store.JobStatusCancelled)
+	switch req.View {
+	case store.SavedFilterViewQueue:
+		allowed = []store.JobStatus{store.JobStatusPending, store.JobStatusTriggered, store.JobStatusRunning}
+	case store.SavedFilterViewHistory:
+		allowed = []store.JobStatus{store.JobStatusCompleted, store.JobStatusFailed, store.JobStatusCancelled}
+	default:
+		return "View must be 'queue' or 'history'", nil
+	}
+
+	for _, status := range req.Statuses {
+		valid := false
+
+		for _, a := range allowed {
+			if status == a {
+				valid = true
+
+				break
 			}
 		}
+
+		if !valid {
+			return fmt.Sprintf("Status %q is not valid for the %s view", status, req.View), nil
+		}
 	}
 
-	// Parse label filters (label.KEY=VALUE).
-	labels := make(map[string]string)
+	if req.GroupID != "" {
+		group, err := s.store.GetGroup(ctx, req.GroupID)
+		if err != nil {
+			return "", err
+		}
 
-	for key, values := range r.URL.Query() {
-		if strings.HasPrefix(key, "label.") && len(values) > 0 {
-			labelKey := strings.TrimPrefix(key, "label.")
-			labels[labelKey] = values[0]
+		if group == nil {
+			return "Group not found", nil
 		}
 	}
 
-	opts := store.HistoryQueryOpts{
-		GroupID:  groupID,
-		Limit:    limit,
-		Before:   before,
-		Statuses: statuses,
-		Labels:   labels,
-	}
+	if req.TemplateID != "" {
+		template, err := s.store.GetJobTemplate(ctx, req.TemplateID)
+		if err != nil {
+			return "", err
+		}
 
-	result, err := s.queue.ListHistoryPaginated(r.Context(), opts)
-	if err != nil {
-		s.log.WithError(err).Error("Failed to get history")
-		s.writeError(w, http.StatusInternalServerError, "Failed to get history")
+		if template == nil {
+			return "Template not found", nil
+		}
 
-		return
+		if req.GroupID != "" && template.GroupID != req.GroupID {
+			return "Template does not belong to the group", nil
+		}
 	}
 
-	resp := HistoryResponse{
-		Jobs:       result.Jobs,
-		HasMore:    result.HasMore,
-		TotalCount: result.TotalCount,
-	}
+	return "", nil
+}
 
-	if result.NextCursor != nil {
-		resp.NextCursor = result.NextCursor.Format(time.RFC3339Nano)
+// savedFilterNameTaken returns true if the user already has another filter with the same view and name.
+func (s *server) savedFilterNameTaken(ctx context.Context, userID string, req *SavedFilterRequest, excludeID string) (bool, error) {
+	filters, err := s.store.ListSavedFiltersByUser(ctx, userID)
+	if err != nil {
+		return false, err
 	}
 
-	if resp.Jobs == nil {
-		resp.Jobs = []*store.Job{}
+	for _, f := range filters {
+		if f.ID != excludeID && f.View == req.View && f.Name == req.Name {
+			return true, nil
+		}
 	}
 
-	s.writeJSON(w, http.StatusOK, resp)
+	return false, nil
 }
 
-// HistoryStatsResponse wraps the aggregated history statistics.
-type HistoryStatsResponse struct {
-	Buckets []HistoryStatsBucket `json:"buckets"`
-	Range   HistoryStatsRange    `json:"range"`
-	Totals  HistoryStatsTotals   `json:"totals"`
-}
+// handleListSavedFilters godoc
+//
+//	@Summary		List saved filters
+//	@Description	Returns the current user's saved queue and history filters
+//	@Tags			filters
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			view	query		string	false	"Only return filters for this view (queue or history)"
+//	@Success		200		{array}		store.SavedFilter
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/filters [get]
+func (s *server) handleListSavedFilters(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		s.writeError(w, http.StatusUnauthorized, "Not authenticated")
 
-// HistoryStatsBucket represents job counts in a time bucket.
-type HistoryStatsBucket struct {
-	Timestamp string `json:"timestamp" example:"2024-01-15T10:00:00Z"`
-	Completed int    `json:"completed" example:"5"`
-	Failed    int    `json:"failed" example:"1"`
-	Cancelled int    `json:"cancelled" example:"0"`
-}
+		return
+	}
 
-// HistoryStatsRange describes the time range of the statistics.
-type HistoryStatsRange struct {
-	Start          string `json:"start" example:"2024-01-15T00:00:00Z"`
-	End            string `json:"end" example:"2024-01-16T00:00:00Z"`
-	BucketDuration string `json:"bucket_duration" example:"1h0m0s"`
-}
+	filters, err := s.store.ListSavedFiltersByUser(r.Context(), user.ID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list saved filters")
+		s.writeError(w, http.StatusInternalServerError, "Failed to list saved filters")
 
-// HistoryStatsTotals contains total counts across all buckets.
-type HistoryStatsTotals struct {
-	Completed int `json:"completed" example:"120"`
-	Failed    int `json:"failed" example:"15"`
-	Cancelled int `json:"cancelled" example:"5"`
+		return
+	}
+
+	view := store.SavedFilterView(r.URL.Query().Get("view"))
+
+	result := make([]*store.SavedFilter, 0, len(filters))
+
+	for _, f := range filters {
+		if view == "" || f.View == view {
+			result = append(result, f)
+		}
+	}
+
+	s.writeJSON(w, http.StatusOK, result)
 }
 
-// handleGetHistoryStats godoc
+// handleCreateSavedFilter godoc
 //
-//	@Summary		Get history statistics
-//	@Description	Returns aggregated job statistics over a time range
-//	@Tags			history
+//	@Summary		Create saved filter
+//	@Description	Saves a named queue or history filter for the current user
+//	@Tags			filters
 //	@Security		BearerAuth
+//	@Accept			json
 //	@Produce		json
-//	@Param			id		path		string	true	"Group ID"
-//	@Param			range	query		string	false	"Time range (1h, 6h, 24h, 7d, 30d, auto)"	default(auto)
-//	@Success		200		{object}	HistoryStatsResponse
+//	@Param			body	body		SavedFilterRequest	true	"Filter"
+//	@Success		201		{object}	store.SavedFilter
 //	@Failure		400		{object}	ErrorResponse
 //	@Failure		401		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
 //	@Failure		500		{object}	ErrorResponse
-//	@Router			/groups/{id}/history/stats [get]
-func (s *server) handleGetHistoryStats(w http.ResponseWriter, r *http.Request) {
-	groupID := chi.URLParam(r, "id")
+//	@Router			/filters [post]
+func (s *server) handleCreateSavedFilter(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		s.writeError(w, http.StatusUnauthorized, "Not authenticated")
 
-	// Parse time range parameter.
-	rangeStr := r.URL.Query().Get("range")
-	if rangeStr == "" {
-		rangeStr = "auto"
+		return
 	}
 
-	now := time.Now()
-	var start, end time.Time
-	var buckets int
-
-	end = now
-
-	switch rangeStr {
-	case "1h":
-		start = now.Add(-1 * time.Hour)
-		buckets = 12 // 5 minute intervals
-	case "6h":
-		start = now.Add(-6 * time.Hour)
-		buckets = 24 // 15 minute intervals
-	case "24h":
-		start = now.Add(-24 * time.Hour)
-		buckets = 24 // 1 hour intervals
-	case "7d":
-		start = now.Add(-7 * 24 * time.Hour)
-		buckets = 28 // 6 hour intervals
-	case "30d":
-		start = now.Add(-30 * 24 * time.Hour)
-		buckets = 30 // 1 day intervals
-	case "auto":
-		// For auto mode, show all jobs from oldest to now.
-		oldestTime, _, err := s.store.GetHistoryTimeBounds(r.Context(), groupID)
-		if err != nil {
-			s.log.WithError(err).Error("Failed to get history time bounds")
-			s.writeError(w, http.StatusInternalServerError, "Failed to get history stats")
+	var req SavedFilterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body")
 
-			return
-		}
+		return
+	}
 
-		if oldestTime == nil {
-			// No history data, return empty buckets for 24h.
-			start = now.Add(-24 * time.Hour)
-			buckets = 24
-		} else {
-			// Set start to oldest job time (with small buffer).
-			start = oldestTime.Add(-1 * time.Minute)
+	msg, err := s.validateSavedFilter(r.Context(), &req)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to validate saved filter")
+		s.writeError(w, http.StatusInternalServerError, "Failed to create saved filter")
 
-			// Calculate appropriate number of buckets based on span.
-			span := now.Sub(start)
+		return
+	}
 
-			if span > 30*24*time.Hour {
-				buckets = 30 // ~1 day per bucket
-			} else if span > 7*24*time.Hour {
-				buckets = 28 // ~6 hours per bucket
-			} else if span > 24*time.Hour {
-				buckets = 24 // ~1 hour per bucket
-			} else if span > 6*time.Hour {
-				buckets = 24 // ~15 min per bucket
-			} else if span > 1*time.Hour {
-				buckets = 12 // ~5 min per bucket
-			} else {
-				buckets = 12 // Small intervals
-			}
-		}
-	default:
-		s.writeError(w, http.StatusBadRequest, "Invalid range parameter")
+	if msg != "" {
+		s.writeError(w, http.StatusBadRequest, msg)
 
 		return
 	}
 
-	opts := store.HistoryStatsOpts{
-		GroupID: groupID,
-		Start:   start,
-		End:     end,
-		Buckets: buckets,
+	taken, err := s.savedFilterNameTaken(r.Context(), user.ID, &req, "")
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list saved filters")
+		s.writeError(w, http.StatusInternalServerError, "Failed to create saved filter")
+
+		return
 	}
 
-	result, err := s.store.GetHistoryStats(r.Context(), opts)
-	if err != nil {
-		s.log.WithError(err).Error("Failed to get history stats")
-		s.writeError(w, http.StatusInternalServerError, "Failed to get history stats")
+	if taken {
+		s.writeError(w, http.StatusConflict, "A filter with this name already exists")
 
 		return
 	}
 
-	// Convert to response format with string timestamps.
-	respBuckets := make([]HistoryStatsBucket, len(result.Buckets))
-	for i, bucket := range result.Buckets {
-		respBuckets[i] = HistoryStatsBucket{
-			Timestamp: bucket.Timestamp.Format(time.RFC3339),
-			Completed: bucket.Completed,
-			Failed:    bucket.Failed,
-			Cancelled: bucket.Cancelled,
-		}
+	now := time.Now()
+	filter := &store.SavedFilter{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		Name:       req.Name,
+		View:       req.View,
+		GroupID:    req.GroupID,
+		Statuses:   req.Statuses,
+		Labels:     req.Labels,
+		TemplateID: req.TemplateID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
 	}
 
-	resp := HistoryStatsResponse{
-		Buckets: respBuckets,
-		Range: HistoryStatsRange{
-			Start:          result.Range.Start.Format(time.RFC3339),
-			End:            result.Range.End.Format(time.RFC3339),
-			BucketDuration: result.Range.BucketDuration.String(),
-		},
-		Totals: HistoryStatsTotals{
-			Completed: result.Totals.Completed,
-			Failed:    result.Totals.Failed,
-			Cancelled: result.Totals.Cancelled,
-		},
+	if err := s.store.CreateSavedFilter(r.Context(), filter); err != nil {
+		s.log.WithError(err).Error("Failed to create saved filter")
+		s.writeError(w, http.StatusInternalServerError, "Failed to create saved filter")
+
+		return
 	}
 
-	s.writeJSON(w, http.StatusOK, resp)
-}
+	s.writeJSON(w, http.StatusCreated, filter)
+}
+
+// getOwnSavedFilter loads a saved filter of the current user, writing an error
+// response and returning nil if it does not exist or belongs to someone else.
+func (s *server) getOwnSavedFilter(w http.ResponseWriter, r *http.Request) *store.SavedFilter {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		s.writeError(w, http.StatusUnauthorized, "Not authenticated")
+
+		return nil
+	}
 
-// handleRefreshRunners godoc
-//
-//	@Summary		Refresh runners
-//	@Description	Triggers a refresh of runner information from GitHub (requires admin)
-//	@Tags			runners
-//	@Security		BearerAuth
-//	@Success		204	"Runners refresh initiated"
-//	@Failure		401	{object}	ErrorResponse
-//	@Failure		403	{object}	ErrorResponse
-//	@Router			/runners/refresh [post]
-func (s *server) handleRefreshRunners(w http.ResponseWriter, _ *http.Request) {
-	// TODO: Implement runner refresh by calling poller.ForceRefresh()
-	// For now, just return success.
-	w.WriteHeader(http.StatusNoContent)
-}
+	filter, err := s.store.GetSavedFilter(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get saved filter")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get saved filter")
 
-// handleWebSocket godoc
-//
-//	@Summary		WebSocket connection
-//	@Description	Establishes a WebSocket connection for real-time job and runner updates
-//	@Tags			websocket
-//	@Param			token	query	string	false	"Authentication token"
-//	@Success		101		"WebSocket connection established"
-//	@Failure		401		{object}	ErrorResponse
-//	@Router			/ws [get]
-func (s *server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
-	ServeWs(s.hub, s.auth, s.cfg.Server.CORSOrigins, w, r)
-}
+		return nil
+	}
 
-// ============================================================================
-// Auth Handlers
-// ============================================================================
+	// Other users' filters are reported as missing rather than forbidden.
+	if filter == nil || filter.UserID != user.ID {
+		s.writeError(w, http.StatusNotFound, "Saved filter not found")
 
-// LoginRequest is the request body for username/password login.
-type LoginRequest struct {
-	Username string `json:"username" example:"admin"`
-	Password string `json:"password" example:"password123"`
-}
+		return nil
+	}
 
-// LoginResponse is the response for successful authentication.
-type LoginResponse struct {
-	Token string      `json:"token" example:"eyJhbGciOiJIUzI1NiIs..."`
-	User  *store.User `json:"user"`
+	return filter
 }
 
-// handleLogin godoc
+// handleUpdateSavedFilter godoc
 //
-//	@Summary		Login with username and password
-//	@Description	Authenticates a user with username and password, returns JWT token
-//	@Tags			auth
+//	@Summary		Update saved filter
+//	@Description	Replaces a saved filter of the current user
+//	@Tags			filters
+//	@Security		BearerAuth
 //	@Accept			json
 //	@Produce		json
-//	@Param			body	body		LoginRequest	true	"Login credentials"
-//	@Success		200		{object}	LoginResponse
+//	@Param			id		path		string				true	"Filter ID"
+//	@Param			body	body		SavedFilterRequest	true	"Filter"
+//	@Success		200		{object}	store.SavedFilter
 //	@Failure		400		{object}	ErrorResponse
 //	@Failure		401		{object}	ErrorResponse
-//	@Failure		429		{object}	RateLimitErrorResponse	"Rate limit exceeded"
-//	@Router			/auth/login [post]
-func (s *server) handleLogin(w http.ResponseWriter, r *http.Request) {
-	var req LoginRequest
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/filters/{id} [put]
+func (s *server) handleUpdateSavedFilter(w http.ResponseWriter, r *http.Request) {
+	filter := s.getOwnSavedFilter(w, r)
+	if filter == nil {
+		return
+	}
+
+	var req SavedFilterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		s.writeError(w, http.StatusBadRequest, "Invalid request body")
 
 		return
 	}
 
-	if req.Username == "" || req.Password == "" {
-		s.writeError(w, http.StatusBadRequest, "Username and password are required")
+	msg, err := s.validateSavedFilter(r.Context(), &req)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to validate saved filter")
+		s.writeError(w, http.StatusInternalServerError, "Failed to update saved filter")
 
 		return
 	}
 
-	user, token, err := s.auth.AuthenticateBasic(r.Context(), req.Username, req.Password)
-	if err != nil {
-		s.log.WithError(err).WithField("username", req.Username).Warn("Login failed")
-		s.writeError(w, http.StatusUnauthorized, "Invalid credentials")
+	if msg != "" {
+		s.writeError(w, http.StatusBadRequest, msg)
 
 		return
 	}
 
-	// Set session cookie.
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session",
-		Value:    token,
-		Path:     "/",
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		Secure:   s.isSecureRequest(r),
-		MaxAge:   int(s.cfg.Auth.SessionTTL.Seconds()),
-	})
+	taken, err := s.savedFilterNameTaken(r.Context(), filter.UserID, &req, filter.ID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list saved filters")
+		s.writeError(w, http.StatusInternalServerError, "Failed to update saved filter")
 
-	s.writeJSON(w, http.StatusOK, LoginResponse{
-		Token: token,
-		User:  user,
-	})
-}
+		return
+	}
 
-// handleLogout godoc
-//
-//	@Summary		Logout
-//	@Description	Logs out the current user and invalidates the session
-//	@Tags			auth
-//	@Security		BearerAuth
-//	@Success		204	"Logged out successfully"
-//	@Failure		401	{object}	ErrorResponse
-//	@Router			/auth/logout [post]
-func (s *server) handleLogout(w http.ResponseWriter, r *http.Request) {
-	// Get token from cookie or header.
-	token := ""
+	if taken {
+		s.writeError(w, http.StatusConflict, "A filter with this name already exists")
 
-	if cookie, err := r.Cookie("session"); err == nil {
-		token = cookie.Value
+		return
 	}
 
-	if token == "" {
-		authHeader := r.Header.Get("Authorization")
-		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-			token = authHeader[7:]
-		}
-	}
+	filter.Name = req.Name
+	filter.View = req.View
+	filter.GroupID = req.GroupID
+	filter.Statuses = req.Statuses
+	filter.Labels = req.Labels
+	filter.TemplateID = req.TemplateID
 
-	if token != "" {
-		if err := s.auth.Logout(r.Context(), token); err != nil {
-			s.log.WithError(err).Warn("Logout error")
-		}
-	}
+	if err := s.store.UpdateSavedFilter(r.Context(), filter); err != nil {
+		s.log.WithError(err).Error("Failed to update saved filter")
+		s.writeError(w, http.StatusInternalServerError, "Failed to update saved filter")
 
-	// Clear session cookie.
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session",
-		Value:    "",
-		Path:     "/",
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		Secure:   s.isSecureRequest(r),
-		MaxAge:   -1,
-	})
+		return
+	}
 
-	w.WriteHeader(http.StatusNoContent)
+	s.writeJSON(w, http.StatusOK, filter)
 }
 
-// handleMe godoc
+// handleDeleteSavedFilter godoc
 //
-//	@Summary		Get current user
-//	@Description	Returns the currently authenticated user's information
-//	@Tags			auth
+//	@Summary		Delete saved filter
+//	@Description	Deletes a saved filter of the current user
+//	@Tags			filters
 //	@Security		BearerAuth
-//	@Produce		json
-//	@Success		200	{object}	store.User
+//	@Param			id	path	string	true	"Filter ID"
+//	@Success		204	"Saved filter deleted"
 //	@Failure		401	{object}	ErrorResponse
-//	@Router			/auth/me [get]
-func (s *server) handleMe(w http.ResponseWriter, r *http.Request) {
-	user := auth.UserFromContext(r.Context())
-	if user == nil {
-		s.writeError(w, http.StatusUnauthorized, "Not authenticated")
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/filters/{id} [delete]
+func (s *server) handleDeleteSavedFilter(w http.ResponseWriter, r *http.Request) {
+	filter := s.getOwnSavedFilter(w, r)
+	if filter == nil {
+		return
+	}
+
+	if err := s.store.DeleteSavedFilter(r.Context(), filter.ID); err != nil {
+		s.log.WithError(err).Error("Failed to delete saved filter")
+		s.writeError(w, http.StatusInternalServerError, "Failed to delete saved filter")
 
 		return
 	}
 
-	s.writeJSON(w, http.StatusOK, user)
+	w.WriteHeader(http.StatusNoContent)
 }
 
 // handleGitHubAuth godoc
@@ -1956,23 +4308,17 @@ func (s *server) handleGitHubCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Set session cookie (works for same-origin requests).
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session",
-		Value:    token,
-		Path:     "/",
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		Secure:   s.isSecureRequest(r),
-		MaxAge:   int(s.cfg.Auth.SessionTTL.Seconds()),
-	})
+	resp, err := s.startSession(w, r, user, token)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to start session")
+		s.writeError(w, http.StatusInternalServerError, "Failed to start session")
+
+		return
+	}
 
 	// Check if client wants JSON response (API clients) or redirect (browsers).
 	if r.Header.Get("Accept") == "application/json" {
-		s.writeJSON(w, http.StatusOK, LoginResponse{
-			Token: token,
-			User:  user,
-		})
+		s.writeJSON(w, http.StatusOK, resp)
 
 		return
 	}
@@ -2046,21 +4392,69 @@ func (s *server) handleExchangeCode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Set session cookie.
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session",
-		Value:    token,
-		Path:     "/",
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		Secure:   s.isSecureRequest(r),
-		MaxAge:   int(s.cfg.Auth.SessionTTL.Seconds()),
-	})
+	resp, err := s.startSession(w, r, user, token)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to start session")
+		s.writeError(w, http.StatusInternalServerError, "Failed to start session")
 
-	s.writeJSON(w, http.StatusOK, LoginResponse{
-		Token: token,
-		User:  user,
-	})
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleRefresh godoc
+//
+//	@Summary		Refresh access token
+//	@Description	Exchanges a refresh token for a new short-lived access token when JWT sessions are enabled. The refresh token is read from the body or the refresh_token cookie and stays valid until its session expires or is revoked.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		RefreshRequest	false	"Refresh token"
+//	@Success		200		{object}	LoginResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse	"JWT sessions not enabled"
+//	@Failure		429		{object}	RateLimitErrorResponse	"Rate limit exceeded"
+//	@Router			/auth/refresh [post]
+func (s *server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	if !s.cfg.Auth.JWT.Enabled {
+		s.writeError(w, http.StatusNotFound, "JWT sessions are not enabled")
+
+		return
+	}
+
+	var req RefreshRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.writeError(w, http.StatusBadRequest, "Invalid request body")
+
+			return
+		}
+	}
+
+	if req.RefreshToken == "" {
+		if cookie, err := r.Cookie(refreshCookieName); err == nil {
+			req.RefreshToken = cookie.Value
+		}
+	}
+
+	if req.RefreshToken == "" {
+		s.writeError(w, http.StatusBadRequest, "Refresh token is required")
+
+		return
+	}
+
+	// The user is taken from the refresh token's session.
+	resp, err := s.startSession(w, r, nil, req.RefreshToken)
+	if err != nil {
+		s.log.WithError(err).Debug("Token refresh failed")
+		s.writeError(w, http.StatusUnauthorized, "Invalid or expired refresh token")
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
 }
 
 // isSecureRequest checks if the request was made over HTTPS.
@@ -2091,15 +4485,7 @@ func SyncGroupsFromConfig(ctx context.Context, log logrus.FieldLogger, st store.
 			return fmt.Errorf("checking group %s: %w", groupCfg.ID, err)
 		}
 
-		group := &store.Group{
-			ID:           groupCfg.ID,
-			Name:         groupCfg.Name,
-			Description:  groupCfg.Description,
-			RunnerLabels: groupCfg.RunnerLabels,
-			Enabled:      true,
-			CreatedAt:    now,
-			UpdatedAt:    now,
-		}
+		group := groupFromConfig(&groupCfg, now)
 
 		if existing == nil {
 			log.WithField("group", groupCfg.ID).Info("Creating group")
@@ -2112,6 +4498,10 @@ func SyncGroupsFromConfig(ctx context.Context, log logrus.FieldLogger, st store.
 
 			group.CreatedAt = existing.CreatedAt
 			group.Paused = existing.Paused
+			group.PausedReason = existing.PausedReason
+			group.ResumedAt = existing.ResumedAt
+			group.Archived = existing.Archived
+			group.ArchivedAt = existing.ArchivedAt
 
 			if err := st.UpdateGroup(ctx, group); err != nil {
 				return fmt.Errorf("updating group %s: %w", groupCfg.ID, err)
@@ -2125,23 +4515,9 @@ func SyncGroupsFromConfig(ctx context.Context, log logrus.FieldLogger, st store.
 		}
 
 		// Sync job templates (upsert instead of delete/recreate to preserve jobs).
-		for _, tmplCfg := range groupCfg.WorkflowDispatchTemplates {
-			template := &store.JobTemplate{
-				ID:            tmplCfg.ID,
-				GroupID:       groupCfg.ID,
-				Name:          tmplCfg.Name,
-				Owner:         tmplCfg.Owner,
-				Repo:          tmplCfg.Repo,
-				WorkflowID:    tmplCfg.WorkflowID,
-				Ref:           tmplCfg.Ref,
-				DefaultInputs: tmplCfg.Inputs,
-				Labels:        tmplCfg.Labels,
-				InConfig:      true,
-				SourceType:    tmplCfg.SourceType,
-				SourcePath:    tmplCfg.SourcePath,
-				CreatedAt:     now,
-				UpdatedAt:     now,
-			}
+		for i := range groupCfg.WorkflowDispatchTemplates {
+			tmplCfg := &groupCfg.WorkflowDispatchTemplates[i]
+			template := templateFromConfig(groupCfg.ID, tmplCfg, now)
 
 			// Check if template exists.
 			existingTemplate, err := st.GetJobTemplate(ctx, tmplCfg.ID)
@@ -2165,6 +4541,7 @@ func SyncGroupsFromConfig(ctx context.Context, log logrus.FieldLogger, st store.
 				}).Debug("Updating job template")
 
 				template.CreatedAt = existingTemplate.CreatedAt
+				applyTemplateOverrides(template, existingTemplate.Overrides)
 
 				if err := st.UpdateJobTemplate(ctx, template); err != nil {
 					return fmt.Errorf("updating job template %s: %w", tmplCfg.ID, err)
@@ -2179,8 +4556,8 @@ func SyncGroupsFromConfig(ctx context.Context, log logrus.FieldLogger, st store.
 		}
 
 		for _, dbTmpl := range dbTemplates {
-			if configTemplateIDs[dbTmpl.ID] {
-				continue // Template is in config, skip.
+			if configTemplateIDs[dbTmpl.ID] || dbTmpl.SourceType == store.TemplateSourceAPI {
+				continue // Template is in config or managed through the API, skip.
 			}
 
 			// Template not in config - check if it has any jobs.
@@ -2228,6 +4605,9 @@ func SyncGroupsFromConfig(ctx context.Context, log logrus.FieldLogger, st store.
 type ReloadTemplatesResponse struct {
 	Message string                      `json:"message" example:"Templates reloaded successfully"`
 	Groups  []ReloadTemplatesGroupStats `json:"groups"`
+	// Staged is set when sync.require_approval is enabled and the reload
+	// changes the database; nothing is applied until it is approved.
+	Staged *StagedConfigSync `json:"staged,omitempty"`
 }
 
 // ReloadTemplatesGroupStats contains template change counts for a group.
@@ -2236,60 +4616,129 @@ type ReloadTemplatesGroupStats struct {
 	Templates int    `json:"templates" example:"5"`
 }
 
+// applyConfigSync syncs a reloaded configuration to the database and swaps in
+// its groups, so the poller picks up the changes.
+func (s *server) applyConfigSync(ctx context.Context, cfg *config.Config) error {
+	if err := SyncGroupsFromConfig(ctx, s.log, s.store, cfg); err != nil {
+		return err
+	}
+
+	s.cfgMu.Lock()
+	s.cfg.Groups = cfg.Groups
+	s.cfgMu.Unlock()
+
+	return nil
+}
+
+// reloadGroupStats returns the per-group template counts of a configuration.
+func reloadGroupStats(cfg *config.Config) []ReloadTemplatesGroupStats {
+	groups := make([]ReloadTemplatesGroupStats, 0, len(cfg.Groups.GitHub))
+	for _, g := range cfg.Groups.GitHub {
+		groups = append(groups, ReloadTemplatesGroupStats{
+			GroupID:   g.ID,
+			Templates: len(g.WorkflowDispatchTemplates),
+		})
+	}
+
+	return groups
+}
+
 // handleReloadTemplates godoc
 //
 //	@Summary		Reload templates
-//	@Description	Re-reads the config file to reload templates from files and URLs, then syncs to the database (requires admin)
+//	@Description	Re-reads the config file to reload templates from files, URLs and git sources, then syncs to the database (requires admin). With sync.require_approval enabled, changes are staged for approval instead and 202 is returned with the diff
 //	@Tags			templates
 //	@Security		BearerAuth
 //	@Produce		json
 //	@Success		200	{object}	ReloadTemplatesResponse
+//	@Success		202	{object}	ReloadTemplatesResponse
 //	@Failure		401	{object}	ErrorResponse
 //	@Failure		403	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
 //	@Router			/templates/reload [post]
 func (s *server) handleReloadTemplates(w http.ResponseWriter, r *http.Request) {
-	s.log.Info("Reloading templates from config")
+	actor := "anonymous"
+	if user := auth.UserFromContext(r.Context()); user != nil {
+		actor = user.Username
+	}
 
-	// Re-read config file to pick up updated template files and URLs.
-	newCfg, err := config.Load(s.configPath)
+	resp, err := s.reloadTemplates(r.Context(), actor, false)
 	if err != nil {
-		s.log.WithError(err).Error("Failed to reload config")
+		s.log.WithError(err).Error("Failed to reload templates")
 		s.writeError(w, http.StatusInternalServerError,
-			fmt.Sprintf("Failed to reload config: %v", err))
+			fmt.Sprintf("Failed to reload templates: %v", err))
 
 		return
 	}
 
-	// Sync the refreshed templates to the database.
-	if err := SyncGroupsFromConfig(r.Context(), s.log, s.store, newCfg); err != nil {
-		s.log.WithError(err).Error("Failed to sync templates")
-		s.writeError(w, http.StatusInternalServerError,
-			fmt.Sprintf("Failed to sync templates: %v", err))
+	if resp.Staged != nil {
+		s.writeJSON(w, http.StatusAccepted, resp)
 
 		return
 	}
 
-	// Update the in-memory config's groups so the poller picks up changes.
-	s.cfgMu.Lock()
-	s.cfg.Groups = newCfg.Groups
-	s.cfgMu.Unlock()
+	s.writeJSON(w, http.StatusOK, resp)
+}
 
-	// Build response with per-group template counts.
-	groups := make([]ReloadTemplatesGroupStats, 0, len(newCfg.Groups.GitHub))
-	for _, g := range newCfg.Groups.GitHub {
-		groups = append(groups, ReloadTemplatesGroupStats{
-			GroupID:   g.ID,
-			Templates: len(g.WorkflowDispatchTemplates),
-		})
+// reloadTemplates re-reads the config file and syncs it to the database, or
+// stages the changes when sync.require_approval is enabled. Refreshes from git
+// sources keep a staged sync with the same changes rather than replacing it,
+// so they do not invalidate the ID an approver is looking at, and are only
+// audited when they change something.
+func (s *server) reloadTemplates(ctx context.Context, actor string, refresh bool) (*ReloadTemplatesResponse, error) {
+	if !refresh {
+		s.log.Info("Reloading templates from config")
 	}
 
-	// Create audit log entry.
-	actor := "anonymous"
-	if user := auth.UserFromContext(r.Context()); user != nil {
-		actor = user.Username
+	// Re-read config file to pick up updated template files, URLs and git sources.
+	newCfg, err := config.Load(s.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("reloading config: %w", err)
+	}
+
+	s.cfgMu.RLock()
+	requireApproval := s.cfg.Sync.RequireApproval
+	current := &config.Config{Groups: s.cfg.Groups}
+	s.cfgMu.RUnlock()
+
+	unchanged := false
+
+	// With approval required, stage the changes instead of applying them.
+	if requireApproval || refresh {
+		diff, err := DiffGroupsFromConfig(ctx, s.store, current, newCfg)
+		if err != nil {
+			return nil, fmt.Errorf("diffing templates: %w", err)
+		}
+
+		unchanged = diff.Empty()
+
+		if requireApproval && !unchanged {
+			staged := s.stageConfigSync(ctx, actor, newCfg, diff, refresh)
+
+			s.log.WithField("sync", staged.ID).Info("Config sync staged for approval")
+
+			return &ReloadTemplatesResponse{
+				Message: "Config sync staged for approval",
+				Groups:  reloadGroupStats(newCfg),
+				Staged:  staged,
+			}, nil
+		}
 	}
 
+	if err := s.applyConfigSync(ctx, newCfg); err != nil {
+		return nil, fmt.Errorf("syncing templates: %w", err)
+	}
+
+	groups := reloadGroupStats(newCfg)
+
+	if refresh && unchanged {
+		return &ReloadTemplatesResponse{
+			Message: "Templates unchanged",
+			Groups:  groups,
+		}, nil
+	}
+
+	// Create audit log entry.
 	auditEntry := &store.AuditEntry{
 		ID:         uuid.New().String(),
 		Action:     store.AuditActionConfigReload,
@@ -2300,13 +4749,14 @@ func (s *server) handleReloadTemplates(w http.ResponseWriter, r *http.Request) {
 		CreatedAt:  time.Now(),
 	}
 
-	if err := s.store.CreateAuditEntry(r.Context(), auditEntry); err != nil {
+	if err := s.store.CreateAuditEntry(ctx, auditEntry); err != nil {
 		s.log.WithError(err).Warn("Failed to create audit entry for template reload")
 	}
 
 	s.log.WithField("groups", len(groups)).Info("Templates reloaded successfully")
-	s.writeJSON(w, http.StatusOK, ReloadTemplatesResponse{
+
+	return &ReloadTemplatesResponse{
 		Message: "Templates reloaded successfully",
 		Groups:  groups,
-	})
+	}, nil
 }
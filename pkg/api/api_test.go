@@ -1,22 +1,50 @@
 package api
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"maps"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/ethpandaops/dispatchoor/pkg/auth"
 	"github.com/ethpandaops/dispatchoor/pkg/config"
 	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/maintenance"
 	"github.com/ethpandaops/dispatchoor/pkg/metrics"
+	"github.com/ethpandaops/dispatchoor/pkg/notify"
 	"github.com/ethpandaops/dispatchoor/pkg/queue"
+	"github.com/ethpandaops/dispatchoor/pkg/settings"
 	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
 )
 
 // stubQueue implements queue.Service with no-op methods for testing.
@@ -25,7 +53,9 @@ type stubQueue struct{}
 func (q *stubQueue) Start(context.Context) error                  { return nil }
 func (q *stubQueue) Stop() error                                  { return nil }
 func (q *stubQueue) SetJobChangeCallback(queue.JobChangeCallback) {}
-func (q *stubQueue) Enqueue(context.Context, string, string, string, map[string]string, *queue.EnqueueOptions) (*store.Job, error) {
+
+func (q *stubQueue) SetJobTransitionCallback(queue.JobTransitionCallback) {}
+func (q *stubQueue) Enqueue(context.Context, string, string, string, input.Map, *queue.EnqueueOptions) (*store.Job, error) {
 	return nil, nil
 }
 func (q *stubQueue) Dequeue(context.Context, string) (*store.Job, error) { return nil, nil }
@@ -47,23 +77,42 @@ func (q *stubQueue) ListHistoryPaginated(context.Context, store.HistoryQueryOpts
 }
 func (q *stubQueue) MarkTriggered(context.Context, string, int64, string) error { return nil }
 func (q *stubQueue) MarkRunning(context.Context, string, int64, string) error   { return nil }
+func (q *stubQueue) MarkRerun(context.Context, string, int) error               { return nil }
 func (q *stubQueue) MarkCompleted(context.Context, string) error                { return nil }
 func (q *stubQueue) MarkFailed(context.Context, string, string) error           { return nil }
 func (q *stubQueue) MarkCancelled(context.Context, string) error                { return nil }
 func (q *stubQueue) Pause(context.Context, string) (*store.Job, error)          { return nil, nil }
 func (q *stubQueue) Unpause(context.Context, string) (*store.Job, error)        { return nil, nil }
-func (q *stubQueue) UpdateInputs(context.Context, string, map[string]string) error {
+func (q *stubQueue) UpdateInputs(context.Context, string, input.Map) error {
 	return nil
 }
 func (q *stubQueue) UpdateJob(context.Context, string, *queue.UpdateJobOptions) error {
 	return nil
 }
+func (q *stubQueue) ReassignOwner(context.Context, string, string) (*store.Job, error) {
+	return nil, nil
+}
+func (q *stubQueue) SetRetained(context.Context, string, bool) (*store.Job, error) {
+	return nil, nil
+}
+func (q *stubQueue) SetAssignee(context.Context, string, string) (*store.Job, error) {
+	return nil, nil
+}
+func (q *stubQueue) SetNotifyTargets(context.Context, string, []store.JobNotifyTarget) (*store.Job, error) {
+	return nil, nil
+}
 func (q *stubQueue) DisableAutoRequeue(context.Context, string) (*store.Job, error) {
 	return nil, nil
 }
 func (q *stubQueue) UpdateAutoRequeue(context.Context, string, bool, *int) (*store.Job, error) {
 	return nil, nil
 }
+func (q *stubQueue) Requeue(context.Context, string, string, string, input.Map) (*store.Job, error) {
+	return nil, nil
+}
+func (q *stubQueue) Compact(context.Context, string) (int, error) {
+	return 0, nil
+}
 
 // stubAuth implements auth.Service for testing.
 type stubAuth struct{}
@@ -83,12 +132,22 @@ func (a *stubAuth) ValidateSession(_ context.Context, _ string) (*store.User, er
 		Role:     store.RoleAdmin,
 	}, nil
 }
-func (a *stubAuth) Logout(context.Context, string) error             { return nil }
-func (a *stubAuth) HasRole(_ *store.User, _ store.Role) bool         { return true }
-func (a *stubAuth) IsAdmin(_ *store.User) bool                       { return true }
-func (a *stubAuth) GetGitHubAuthURL(string) string                   { return "" }
-func (a *stubAuth) CreateOAuthState(context.Context) (string, error) { return "", nil }
-func (a *stubAuth) ValidateOAuthState(context.Context, string) error { return nil }
+func (a *stubAuth) Logout(context.Context, string) error { return nil }
+func (a *stubAuth) IssueAccessToken(context.Context, string) (*store.User, string, time.Time, error) {
+	return nil, "", time.Time{}, nil
+}
+func (a *stubAuth) ListSessions(context.Context, string) ([]*store.Session, error) {
+	return nil, nil
+}
+func (a *stubAuth) CurrentSession(context.Context, string) (*store.Session, error) {
+	return nil, nil
+}
+func (a *stubAuth) RevokeSession(context.Context, *store.User, string) error { return nil }
+func (a *stubAuth) HasRole(_ *store.User, _ store.Role) bool                 { return true }
+func (a *stubAuth) IsAdmin(_ *store.User) bool                               { return true }
+func (a *stubAuth) GetGitHubAuthURL(string) string                           { return "" }
+func (a *stubAuth) CreateOAuthState(context.Context) (string, error)         { return "", nil }
+func (a *stubAuth) ValidateOAuthState(context.Context, string) error         { return nil }
 func (a *stubAuth) CreateAuthCode(context.Context, string) (string, error) {
 	return "", nil
 }
@@ -97,11 +156,19 @@ func (a *stubAuth) ExchangeAuthCode(context.Context, string) (*store.User, strin
 }
 
 // stubGitHubClient implements github.Client for testing.
-type stubGitHubClient struct{}
+type stubGitHubClient struct {
+	connected bool
+	// missingRefs are refs ResolveRef reports as not found.
+	missingRefs map[string]bool
+	// comments are the bodies of issue comments created.
+	comments []string
+	// closedIssues are the numbers of issues closed.
+	closedIssues []int
+}
 
 func (c *stubGitHubClient) Start(context.Context) error { return nil }
 func (c *stubGitHubClient) Stop() error                 { return nil }
-func (c *stubGitHubClient) IsConnected() bool           { return false }
+func (c *stubGitHubClient) IsConnected() bool           { return c.connected }
 func (c *stubGitHubClient) ConnectionError() string     { return "" }
 func (c *stubGitHubClient) ListOrgRunners(context.Context, string) ([]*github.Runner, error) {
 	return nil, nil
@@ -109,7 +176,10 @@ func (c *stubGitHubClient) ListOrgRunners(context.Context, string) ([]*github.Ru
 func (c *stubGitHubClient) ListRepoRunners(context.Context, string, string) ([]*github.Runner, error) {
 	return nil, nil
 }
-func (c *stubGitHubClient) TriggerWorkflowDispatch(context.Context, string, string, string, string, map[string]string) error {
+func (c *stubGitHubClient) ListOrgHostedRunners(context.Context, string) ([]*github.HostedRunner, error) {
+	return nil, nil
+}
+func (c *stubGitHubClient) TriggerWorkflowDispatch(context.Context, string, string, string, string, input.Map) error {
 	return nil
 }
 func (c *stubGitHubClient) GetWorkflowRun(context.Context, string, string, int64) (*github.WorkflowRun, error) {
@@ -118,12 +188,50 @@ func (c *stubGitHubClient) GetWorkflowRun(context.Context, string, string, int64
 func (c *stubGitHubClient) ListWorkflowRuns(context.Context, string, string, string, github.ListWorkflowRunsOpts) ([]*github.WorkflowRun, error) {
 	return nil, nil
 }
+func (c *stubGitHubClient) ListRepoWorkflowRuns(context.Context, string, string, github.ListWorkflowRunsOpts) ([]*github.WorkflowRun, error) {
+	return nil, nil
+}
 func (c *stubGitHubClient) ListWorkflowRunJobs(context.Context, string, string, int64) ([]*github.WorkflowJob, error) {
 	return nil, nil
 }
 func (c *stubGitHubClient) CancelWorkflowRun(context.Context, string, string, int64) error {
 	return nil
 }
+func (c *stubGitHubClient) GetEnvironment(context.Context, string, string, string) (*github.Environment, error) {
+	return nil, nil
+}
+func (c *stubGitHubClient) ResolveRef(_ context.Context, _, _, ref string) (string, error) {
+	if c.missingRefs[ref] {
+		return "", github.ErrRefNotFound
+	}
+
+	return "", nil
+}
+func (c *stubGitHubClient) GetRepoPermissions(context.Context, string, string) (*github.RepoPermissions, error) {
+	return nil, nil
+}
+func (c *stubGitHubClient) GetWorkflowFile(context.Context, string, string, string, string) (*github.WorkflowFile, error) {
+	return nil, nil
+}
+func (c *stubGitHubClient) ListCheckRunAnnotations(context.Context, string, string, int64) ([]*github.Annotation, error) {
+	return nil, nil
+}
+func (c *stubGitHubClient) CreateIssueComment(_ context.Context, _, _ string, _ int, body string) error {
+	c.comments = append(c.comments, body)
+
+	return nil
+}
+func (c *stubGitHubClient) CloseIssue(_ context.Context, _, _ string, number int) error {
+	c.closedIssues = append(c.closedIssues, number)
+
+	return nil
+}
+func (c *stubGitHubClient) PutActionsSecret(context.Context, string, string, string, string, string) error {
+	return nil
+}
+func (c *stubGitHubClient) DeleteActionsSecret(context.Context, string, string, string, string) error {
+	return nil
+}
 func (c *stubGitHubClient) RateLimitRemaining() int   { return 0 }
 func (c *stubGitHubClient) RateLimitReset() time.Time { return time.Time{} }
 
@@ -478,6 +586,4520 @@ func TestHandleReloadTemplates_InvalidConfig(t *testing.T) {
 	}
 }
 
-func ptr[T any](v T) *T {
-	return &v
+func TestHandleGetHistoryStats_GroupBy(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	templates := []map[string]any{
+		{
+			"id":          "tmpl-1",
+			"name":        "Template 1",
+			"owner":       "org",
+			"repo":        "repo",
+			"workflow_id": "build.yml",
+			"labels":      map[string]string{"network": "hoodi"},
+		},
+		{
+			"id":          "tmpl-2",
+			"name":        "Template 2",
+			"owner":       "org",
+			"repo":        "repo",
+			"workflow_id": "deploy.yml",
+			"labels":      map[string]string{"network": "mainnet"},
+		},
+	}
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, templates)
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	// Two finished jobs for tmpl-1 (one failed) and one for tmpl-2.
+	completedAt := time.Now().Add(-30 * time.Minute)
+	jobs := []struct {
+		id, templateID string
+		status         store.JobStatus
+	}{
+		{"job-1", "tmpl-1", store.JobStatusCompleted},
+		{"job-2", "tmpl-1", store.JobStatusFailed},
+		{"job-3", "tmpl-2", store.JobStatusCompleted},
+	}
+
+	for i, j := range jobs {
+		job := &store.Job{
+			ID:         j.id,
+			GroupID:    "test-group",
+			TemplateID: j.templateID,
+			Position:   i,
+			Status:     store.JobStatusPending,
+			CreatedBy:  "testadmin",
+			CreatedAt:  completedAt,
+			UpdatedAt:  completedAt,
+		}
+		if err := st.CreateJob(ctx, job); err != nil {
+			t.Fatalf("Failed to create job: %v", err)
+		}
+
+		job.Status = j.status
+		job.CompletedAt = &completedAt
+
+		if err := st.UpdateJob(ctx, job); err != nil {
+			t.Fatalf("Failed to update job: %v", err)
+		}
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	s := srv.(*server)
+
+	get := func(query string) (*httptest.ResponseRecorder, HistoryStatsResponse) {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/groups/test-group/history/stats?"+query, nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		var resp HistoryStatsResponse
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+
+		return w, resp
+	}
+
+	w, resp := get("range=24h&group_by=template_id")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if len(resp.Series) != 2 {
+		t.Fatalf("Expected 2 series, got %d", len(resp.Series))
+	}
+
+	if resp.Series[0].Key != "tmpl-1" || resp.Series[0].Totals.Completed != 1 || resp.Series[0].Totals.Failed != 1 {
+		t.Errorf("Unexpected tmpl-1 series: %+v", resp.Series[0])
+	}
+
+	if resp.Series[1].Key != "tmpl-2" || resp.Series[1].Totals.Completed != 1 {
+		t.Errorf("Unexpected tmpl-2 series: %+v", resp.Series[1])
+	}
+
+	if resp.Totals.Completed != 2 || resp.Totals.Failed != 1 {
+		t.Errorf("Unexpected overall totals: %+v", resp.Totals)
+	}
+
+	w, resp = get("range=24h&group_by=label&label_key=network")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if len(resp.Series) != 2 || resp.Series[0].Key != "hoodi" || resp.Series[1].Key != "mainnet" {
+		t.Errorf("Unexpected label series: %+v", resp.Series)
+	}
+
+	if w, _ := get("range=24h&group_by=label"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without label_key, got %d", w.Code)
+	}
+
+	if w, _ := get("range=24h&group_by=bogus"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid group_by, got %d", w.Code)
+	}
+}
+
+func TestHandleSavedFilters(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	templates := []map[string]any{
+		{
+			"id":          "tmpl-1",
+			"name":        "Template 1",
+			"owner":       "org",
+			"repo":        "repo",
+			"workflow_id": "build.yml",
+		},
+	}
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, templates)
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	now := time.Now()
+	for _, id := range []string{"test-user-id", "other-user-id"} {
+		if err := st.CreateUser(ctx, &store.User{
+			ID: id, Username: id, Role: store.RoleAdmin, AuthProvider: store.AuthProviderBasic,
+			CreatedAt: now, UpdatedAt: now,
+		}); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	other := &store.SavedFilter{
+		ID: "other-filter", UserID: "other-user-id", Name: "Theirs", View: store.SavedFilterViewQueue,
+		CreatedAt: now, UpdatedAt: now,
+	}
+	if err := st.CreateSavedFilter(ctx, other); err != nil {
+		t.Fatalf("Failed to create saved filter: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	s := srv.(*server)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	body := `{"name":"Failed builds","view":"history","group_id":"test-group",` +
+		`"statuses":["failed"],"labels":{"network":"hoodi"},"template_id":"tmpl-1"}`
+
+	w := do(http.MethodPost, "/api/v1/filters", body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var created store.SavedFilter
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if w := do(http.MethodPost, "/api/v1/filters", body); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for duplicate name, got %d", w.Code)
+	}
+
+	if w := do(http.MethodPost, "/api/v1/filters", `{"name":"Bad","view":"history","statuses":["pending"]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for queue status in history view, got %d", w.Code)
+	}
+
+	w = do(http.MethodGet, "/api/v1/filters", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var filters []*store.SavedFilter
+	if err := json.NewDecoder(w.Body).Decode(&filters); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(filters) != 1 || filters[0].ID != created.ID || filters[0].Labels["network"] != "hoodi" ||
+		len(filters[0].Statuses) != 1 || filters[0].TemplateID != "tmpl-1" {
+		t.Errorf("Unexpected filters: %+v", filters)
+	}
+
+	if w := do(http.MethodDelete, "/api/v1/filters/other-filter", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for another user's filter, got %d", w.Code)
+	}
+
+	if w := do(http.MethodDelete, "/api/v1/filters/"+created.ID, ""); w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+}
+
+func TestHandleListJobTemplates_GroupByCategory(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	tmpl := func(id, name, category string, order int) map[string]any {
+		return map[string]any{
+			"id": id, "name": name, "owner": "org", "repo": "repo", "workflow_id": id + ".yml",
+			"category": category, "display_order": order,
+		}
+	}
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{
+		tmpl("misc", "Misc", "", 0),
+		tmpl("sync-b", "Sync B", "Sync", 1),
+		tmpl("sync-a", "Sync A", "Sync", 2),
+		tmpl("build", "Build", "Build", 5),
+	})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	s := srv.(*server)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/groups/test-group/templates?group_by=category", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp GroupedTemplatesResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	var got []string
+
+	for _, category := range resp.Categories {
+		for _, tmpl := range category.Templates {
+			got = append(got, category.Category+"/"+tmpl.ID)
+		}
+	}
+
+	// Categories by name with uncategorized last; display_order within a category.
+	want := []string{"Build/build", "Sync/sync-b", "Sync/sync-a", "/misc"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestHandleArchiveGroup(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	s := srv.(*server)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	listed := func(query string) int {
+		w := do(http.MethodGet, "/api/v1/groups"+query)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var groups []GroupWithStats
+		if err := json.NewDecoder(w.Body).Decode(&groups); err != nil {
+			t.Fatalf("Failed to decode groups: %v", err)
+		}
+
+		return len(groups)
+	}
+
+	now := time.Now()
+	if err := st.CreateJob(ctx, &store.Job{
+		ID: "pending-job", GroupID: "test-group", Status: store.JobStatusPending,
+		CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create job: %v", err)
+	}
+
+	if w := do(http.MethodPost, "/api/v1/groups/test-group/archive"); w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409 with a pending job, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if err := st.DeleteJob(ctx, "pending-job"); err != nil {
+		t.Fatalf("Failed to delete job: %v", err)
+	}
+
+	if w := do(http.MethodPost, "/api/v1/groups/test-group/archive"); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if n := listed(""); n != 0 {
+		t.Errorf("Expected archived group to be hidden, got %d groups", n)
+	}
+
+	if n := listed("?include_archived=true"); n != 1 {
+		t.Errorf("Expected archived group with include_archived, got %d groups", n)
+	}
+
+	// Archiving survives a config sync.
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	if group, err := st.GetGroup(ctx, "test-group"); err != nil || !group.Archived || group.ArchivedAt == nil {
+		t.Fatalf("Expected group to stay archived after sync, got %+v (%v)", group, err)
+	}
+
+	if w := do(http.MethodPost, "/api/v1/groups/test-group/unarchive"); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if n := listed(""); n != 1 {
+		t.Errorf("Expected unarchived group to be listed, got %d groups", n)
+	}
+}
+
+func TestHandleGetQueue_Positions(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	now := time.Now()
+	for i, job := range []*store.Job{
+		{ID: "running", Status: store.JobStatusRunning},
+		{ID: "first", Status: store.JobStatusPending},
+		{ID: "paused", Status: store.JobStatusPending, Paused: true},
+		{ID: "second", Status: store.JobStatusPending},
+	} {
+		job.GroupID = "test-group"
+		job.Position = i + 1
+		job.CreatedAt = now
+		job.UpdatedAt = now
+
+		if err := st.CreateJob(ctx, job); err != nil {
+			t.Fatalf("Failed to create job: %v", err)
+		}
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/groups/test-group/queue", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	w := httptest.NewRecorder()
+	srv.(*server).router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var jobs []*store.Job
+	if err := json.NewDecoder(w.Body).Decode(&jobs); err != nil {
+		t.Fatalf("Failed to decode queue: %v", err)
+	}
+
+	want := map[string][2]int{"first": {1, 0}, "second": {2, 1}}
+
+	for _, job := range jobs {
+		expected, ok := want[job.ID]
+		if !ok {
+			if job.QueuePosition != nil || job.AheadCount != nil {
+				t.Errorf("Expected no position for job %s", job.ID)
+			}
+
+			continue
+		}
+
+		if job.QueuePosition == nil || job.AheadCount == nil ||
+			*job.QueuePosition != expected[0] || *job.AheadCount != expected[1] {
+			t.Errorf("Expected job %s at position %d with %d ahead, got %v/%v",
+				job.ID, expected[0], expected[1], job.QueuePosition, job.AheadCount)
+		}
+	}
+}
+
+func TestHandleGetOverview(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	now := time.Now()
+	hourAgo, dayAgo := now.Add(-time.Hour), now.Add(-48*time.Hour)
+
+	for i, job := range []*store.Job{
+		{ID: "paused", Status: store.JobStatusPending, Paused: true, CreatedAt: now.Add(-3 * time.Hour)},
+		{ID: "oldest", Status: store.JobStatusPending, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "newer", Status: store.JobStatusPending, CreatedAt: now},
+		{ID: "running", Status: store.JobStatusRunning, CreatedAt: now},
+		{ID: "failed", Status: store.JobStatusFailed, CreatedAt: hourAgo, CompletedAt: &hourAgo},
+		{ID: "done", Status: store.JobStatusCompleted, CreatedAt: hourAgo, CompletedAt: &hourAgo},
+		{ID: "old-failure", Status: store.JobStatusFailed, CreatedAt: dayAgo, CompletedAt: &dayAgo},
+	} {
+		job.GroupID = "test-group"
+		job.Position = i + 1
+		job.UpdatedAt = now
+
+		if err := st.CreateJob(ctx, job); err != nil {
+			t.Fatalf("Failed to create job: %v", err)
+		}
+
+		// Completion times are only stored on update.
+		if job.CompletedAt != nil {
+			if err := st.UpdateJob(ctx, job); err != nil {
+				t.Fatalf("Failed to complete job: %v", err)
+			}
+		}
+	}
+
+	for _, runner := range []*store.Runner{
+		{ID: 1, Name: "busy", Status: store.RunnerStatusOnline, Busy: true},
+		{ID: 2, Name: "idle", Status: store.RunnerStatusOnline},
+		{ID: 3, Name: "offline", Status: store.RunnerStatusOffline},
+		{ID: 4, Name: "other", Status: store.RunnerStatusOnline, Labels: []string{"other"}},
+	} {
+		if runner.Labels == nil {
+			runner.Labels = []string{"self-hosted"}
+		}
+
+		runner.LastSeenAt, runner.CreatedAt, runner.UpdatedAt = now, now, now
+
+		if err := st.UpsertRunner(ctx, runner); err != nil {
+			t.Fatalf("Failed to create runner: %v", err)
+		}
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/overview", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	w := httptest.NewRecorder()
+	srv.(*server).router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp OverviewResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode overview: %v", err)
+	}
+
+	if len(resp.Groups) != 1 {
+		t.Fatalf("Expected 1 group, got %d", len(resp.Groups))
+	}
+
+	group := resp.Groups[0]
+	if group.QueuedJobs != 3 || group.RunningJobs != 1 || group.OldestPendingJobID != "oldest" ||
+		group.TotalRunners != 3 || group.BusyRunners != 1 || group.IdleRunners != 1 ||
+		group.Last24h != (HistoryStatsTotals{Completed: 1, Failed: 1}) {
+		t.Errorf("Unexpected group stats %+v", group)
+	}
+
+	if resp.Runners != (RunnerSummary{Total: 4, Online: 3, Offline: 1, Busy: 1, Idle: 2}) {
+		t.Errorf("Unexpected runner summary %+v", resp.Runners)
+	}
+
+	if len(resp.RecentFailures) != 1 || resp.RecentFailures[0].ID != "failed" {
+		t.Errorf("Expected only the recent failure, got %d failures", len(resp.RecentFailures))
+	}
+
+	if resp.System == nil || resp.System.Queue != (QueueStats{PendingJobs: 3, RunningJobs: 1}) {
+		t.Errorf("Unexpected system status %+v", resp.System)
+	}
+}
+
+func TestHandleGetQueue_Pages(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	now := time.Now()
+	for i, job := range []*store.Job{
+		{ID: "running", Status: store.JobStatusRunning},
+		{ID: "second", Status: store.JobStatusPending},
+		{ID: "paused", Status: store.JobStatusPending, Paused: true},
+		{ID: "first", Status: store.JobStatusPending, Priority: 5},
+		{ID: "third", Status: store.JobStatusPending},
+	} {
+		job.GroupID = "test-group"
+		job.Position = i + 1
+		job.CreatedAt = now
+		job.UpdatedAt = now
+
+		if err := st.CreateJob(ctx, job); err != nil {
+			t.Fatalf("Failed to create job: %v", err)
+		}
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	get := func(query string, out any) int {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/groups/test-group/queue?"+query, nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		srv.(*server).router.ServeHTTP(w, req)
+
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(out); err != nil {
+				t.Fatalf("Failed to decode queue: %v", err)
+			}
+		}
+
+		return w.Code
+	}
+
+	positions := func(jobs []*store.Job) []string {
+		out := make([]string, 0, len(jobs))
+
+		for _, job := range jobs {
+			position := "-"
+			if job.QueuePosition != nil {
+				position = strconv.Itoa(*job.QueuePosition)
+			}
+
+			out = append(out, job.ID+"@"+position)
+		}
+
+		return out
+	}
+
+	var first QueuePageResponse
+	if code := get("limit=2", &first); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+
+	if got := positions(first.Jobs); !slices.Equal(got, []string{"first@1", "second@2"}) ||
+		!first.HasMore || len(first.Active) != 1 {
+		t.Fatalf("Unexpected first page %v (has_more %v, %d active)", got, first.HasMore, len(first.Active))
+	}
+
+	var second QueuePageResponse
+	if code := get("limit=2&after="+first.NextCursor, &second); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+
+	if got := positions(second.Jobs); !slices.Equal(got, []string{"paused@-", "third@3"}) ||
+		second.HasMore || second.NextCursor != "" || second.Active != nil {
+		t.Errorf("Unexpected second page %v (has_more %v)", got, second.HasMore)
+	}
+
+	if code := get("after=bogus", &second); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a bad cursor, got %d", code)
+	}
+
+	var summary QueueSummaryResponse
+	if code := get("summary=true", &summary); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+
+	want := QueueTemplateSummary{Pending: 3, Paused: 1, Running: 1}
+	if len(summary.Templates) != 1 || summary.Templates[0] != want || summary.Total != want {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+}
+func TestSPAHandler(t *testing.T) {
+	assets := fstest.MapFS{
+		"index.html":      {Data: []byte("<html>index</html>")},
+		"assets/app-1.js": {Data: []byte("console.log(1)")},
+		"images/logo.png": {Data: []byte("png")},
+	}
+
+	h := notForAPI(spaHandler(assets))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return w
+	}
+
+	if w := get("/groups/sync-tests"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "index") {
+		t.Errorf("Expected index.html for client-side route, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := get("/assets/app-1.js")
+	if w.Code != http.StatusOK || w.Body.String() != "console.log(1)" {
+		t.Errorf("Expected asset, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if !strings.Contains(w.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("Expected immutable cache header on fingerprinted asset, got %q", w.Header().Get("Cache-Control"))
+	}
+
+	if w := get("/config.json"); !strings.Contains(w.Body.String(), `"/api/v1"`) {
+		t.Errorf("Expected same-origin config.json, got %s", w.Body.String())
+	}
+
+	if w := get("/api/v2/unknown"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown API path, got %d", w.Code)
+	}
+}
+
+func TestRequestLogger(t *testing.T) {
+	log, hook := logrustest.NewNullLogger()
+
+	cfg := &config.Config{}
+	cfg.Server.RequestLog.SampleSuccessfulGets = 2
+
+	s := &server{log: log, cfg: cfg}
+
+	r := chi.NewRouter()
+	r.Use(s.requestLogger())
+	r.Get("/api/v1/groups/{id}/queue", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	r.Delete("/api/v1/jobs/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	for range 4 {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/groups/g1/queue", nil))
+	}
+
+	if got := len(hook.AllEntries()); got != 2 {
+		t.Fatalf("Expected 2 of 4 successful GETs to be logged, got %d", got)
+	}
+
+	if id := hook.LastEntry().Data["group_id"]; id != "g1" {
+		t.Errorf("Expected group_id g1, got %v", id)
+	}
+
+	hook.Reset()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/api/v1/jobs/j1", nil))
+
+	entry := hook.LastEntry()
+	if entry == nil || entry.Level != logrus.WarnLevel || entry.Data["job_id"] != "j1" || entry.Data["status"] != http.StatusNotFound {
+		t.Errorf("Unexpected log entry for failed request: %+v", entry)
+	}
+}
+
+func TestTLSReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+
+	writeCert := func(cn string, modTime time.Time) {
+		t.Helper()
+
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("Failed to generate key: %v", err)
+		}
+
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: cn},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+		if err != nil {
+			t.Fatalf("Failed to create certificate: %v", err)
+		}
+
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatalf("Failed to marshal key: %v", err)
+		}
+
+		for file, block := range map[string]*pem.Block{
+			certFile: {Type: "CERTIFICATE", Bytes: der},
+			keyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+		} {
+			if err := os.WriteFile(file, pem.EncodeToMemory(block), 0o600); err != nil {
+				t.Fatalf("Failed to write %s: %v", file, err)
+			}
+
+			if err := os.Chtimes(file, modTime, modTime); err != nil {
+				t.Fatalf("Failed to set mtime: %v", err)
+			}
+		}
+	}
+
+	servedCN := func(r *tlsReloader) string {
+		t.Helper()
+
+		cfg, err := r.tlsConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+		if err != nil {
+			t.Fatalf("GetConfigForClient failed: %v", err)
+		}
+
+		leaf, err := x509.ParseCertificate(cfg.Certificates[0].Certificate[0])
+		if err != nil {
+			t.Fatalf("Failed to parse certificate: %v", err)
+		}
+
+		return leaf.Subject.CommonName
+	}
+
+	writeCert("first", time.Now().Add(-time.Minute))
+
+	r, err := newTLSReloader(logrus.New(), config.TLSConfig{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("Failed to load certificates: %v", err)
+	}
+
+	if cn := servedCN(r); cn != "first" {
+		t.Fatalf("Expected first certificate, got %s", cn)
+	}
+
+	if r.changed() {
+		t.Error("Expected no change before rotation")
+	}
+
+	writeCert("second", time.Now())
+
+	if !r.changed() {
+		t.Fatal("Expected rotation to be detected")
+	}
+
+	if err := r.load(); err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+
+	if cn := servedCN(r); cn != "second" {
+		t.Errorf("Expected rotated certificate, got %s", cn)
+	}
+}
+
+func TestHubShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hub := NewHub(logrus.New())
+	go hub.Run(ctx)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeWs(hub, &stubAuth{}, []string{"*"}, w, r)
+	}))
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/?token=test-token"
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	for hub.ClientCount() != 1 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 5*time.Second)
+		defer shutdownCancel()
+
+		hub.Shutdown(shutdownCtx)
+	}()
+
+	var msg Message
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("Failed to read reconnect message: %v", err)
+	}
+
+	if msg.Type != MessageTypeReconnect {
+		t.Errorf("Expected reconnect message, got %s", msg.Type)
+	}
+
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseServiceRestart) {
+		t.Errorf("Expected service restart close frame, got %v", err)
+	}
+
+	<-done
+
+	if hub.ClientCount() != 0 {
+		t.Errorf("Expected no clients after shutdown, got %d", hub.ClientCount())
+	}
+
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL, nil); err == nil || resp == nil ||
+		resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected new connections to be rejected with 503 while draining, got %v", err)
+	}
+}
+
+func TestCompressMiddleware(t *testing.T) {
+	large := `{"jobs":"` + strings.Repeat("a", 2048) + `"}`
+
+	handler := compressMiddleware(5, 1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/large":
+			_, _ = io.WriteString(w, large)
+		case "/archive":
+			w.Header().Set("Content-Type", "application/zip")
+			_, _ = io.WriteString(w, large)
+		default:
+			_, _ = io.WriteString(w, `{"ok":true}`)
+		}
+	}))
+
+	for _, tc := range []struct {
+		path, acceptEncoding string
+		gzipped              bool
+	}{
+		{"/large", "gzip, br", true},
+		{"/large", "gzip;q=0", false},
+		{"/large", "", false},
+		{"/small", "gzip", false},
+		{"/archive", "gzip", false},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if gzipped := rec.Header().Get("Content-Encoding") == "gzip"; gzipped != tc.gzipped {
+			t.Errorf("%s with %q: expected gzipped %t, got %t", tc.path, tc.acceptEncoding, tc.gzipped, gzipped)
+
+			continue
+		}
+
+		if rec.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("%s: expected Vary: Accept-Encoding, got %q", tc.path, rec.Header().Get("Vary"))
+		}
+
+		if !tc.gzipped {
+			continue
+		}
+
+		gz, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("Failed to read gzip body: %v", err)
+		}
+
+		body, err := io.ReadAll(gz)
+		if err != nil || string(body) != large {
+			t.Errorf("Expected the original body after decompressing, got %d bytes, %v", len(body), err)
+		}
+	}
+}
+
+func TestHubJobDeltas(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hub := NewHub(logrus.New())
+	go hub.Run(ctx)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeWs(hub, &stubAuth{}, []string{"*"}, w, r)
+	}))
+	defer ts.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/?token=test-token", nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	read := func() map[string]json.RawMessage {
+		t.Helper()
+
+		var msg map[string]json.RawMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("Failed to read message: %v", err)
+		}
+
+		return msg
+	}
+
+	if err := conn.WriteJSON(&Message{Type: MessageTypeSubscribe, GroupID: "sync", Format: MessageFormatDelta}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	if msg := read(); string(msg["type"]) != `"subscribed"` || string(msg["format"]) != `"delta"` {
+		t.Fatalf("Expected delta subscription, got %v", msg)
+	}
+
+	job := &store.Job{ID: "job-1", GroupID: "sync", Status: store.JobStatusPending, Inputs: input.Map{"network": input.String("hoodi")}}
+	hub.BroadcastJobState(job)
+
+	if msg := read(); string(msg["type"]) != `"job_state"` {
+		t.Fatalf("Expected the first state in full, got %v", msg)
+	}
+
+	// Unchanged states are not sent at all.
+	hub.BroadcastJobState(job)
+
+	runID := int64(42)
+	hub.BroadcastJobState(&store.Job{ID: "job-1", GroupID: "sync", Status: store.JobStatusTriggered, RunID: &runID, Inputs: job.Inputs})
+
+	msg := read()
+	if string(msg["type"]) != `"job_delta"` {
+		t.Fatalf("Expected a delta, got %v", msg)
+	}
+
+	var delta JobDeltaPayload
+	if err := json.Unmarshal(msg["payload"], &delta); err != nil {
+		t.Fatalf("Failed to decode delta: %v", err)
+	}
+
+	if delta.ID != "job-1" || len(delta.Changed) != 2 ||
+		string(delta.Changed["status"]) != `"triggered"` || string(delta.Changed["run_id"]) != "42" {
+		t.Errorf("Expected status and run_id to change, got %+v", delta)
+	}
+}
+
+func TestGroupVisibility(t *testing.T) {
+	s := &server{cfg: &config.Config{Groups: config.GroupsConfig{GitHub: []config.Group{
+		{ID: "open"},
+		{
+			ID:      "restricted",
+			Viewers: []string{"alice"},
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{
+				{ID: "deploy", SecretInputs: []string{"token"}},
+			},
+		},
+	}}}}
+
+	alice := &store.User{Username: "alice", Role: store.RoleReadOnly}
+	bob := &store.User{Username: "bob", Role: store.RoleReadOnly}
+	admin := &store.User{Username: "root", Role: store.RoleAdmin}
+
+	for _, tc := range []struct {
+		user    *store.User
+		groupID string
+		want    bool
+	}{
+		{alice, "restricted", true},
+		{bob, "restricted", false},
+		{admin, "restricted", true},
+		{bob, "open", true},
+		{nil, "open", false},
+	} {
+		if got := s.canViewGroup(tc.user, tc.groupID); got != tc.want {
+			t.Errorf("canViewGroup(%v, %s) = %t, want %t", tc.user, tc.groupID, got, tc.want)
+		}
+	}
+
+	job := &store.Job{ID: "job", TemplateID: "deploy", Inputs: input.Map{"token": input.String("s3cret"), "env": input.String("prod")}}
+
+	msg := s.redactMessage(&Message{Type: MessageTypeJobState, Payload: job})
+
+	redacted, ok := msg.Payload.(*store.Job)
+	if !ok {
+		t.Fatalf("Expected job payload, got %T", msg.Payload)
+	}
+
+	if redacted.Inputs["token"].String() != redactedValue || redacted.Inputs["env"].String() != "prod" {
+		t.Errorf("Expected only the secret input to be redacted, got %v", redacted.Inputs)
+	}
+
+	if job.Inputs["token"].String() != "s3cret" {
+		t.Error("Expected the original job to be left unchanged")
+	}
+}
+
+func TestHandleGitHubWebhook(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	cfg.GitHub.WebhookSecret = "webhook-secret"
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	now := time.Now()
+	if err := st.UpsertRunner(ctx, &store.Runner{
+		ID: 42, Name: "runner-1", Labels: []string{"self-hosted", "linux"},
+		Status: store.RunnerStatusOnline, Busy: true,
+		LastSeenAt: now, CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create runner: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	triggered := 0
+	srv.SetDispatchTrigger(func() { triggered++ })
+
+	s := srv.(*server)
+
+	deliver := func(event, body, secret string) int {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(body))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", event)
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	completed := `{"action":"completed","workflow_job":{"id":1,"run_id":2,"runner_id":42,` +
+		`"runner_name":"runner-1","labels":["self-hosted"]}}`
+
+	if code := deliver("workflow_job", completed, "wrong-secret"); code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for a bad signature, got %d", code)
+	}
+
+	if code := deliver("ping", `{"zen":"hi"}`, "webhook-secret"); code != http.StatusNoContent {
+		t.Errorf("Expected status 204 for an ignored event, got %d", code)
+	}
+
+	if triggered != 0 {
+		t.Fatalf("Expected no dispatch before a valid completed delivery, got %d", triggered)
+	}
+
+	if code := deliver("workflow_job", completed, "webhook-secret"); code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", code)
+	}
+
+	if triggered != 1 {
+		t.Errorf("Expected one dispatch trigger, got %d", triggered)
+	}
+
+	runner, err := st.GetRunner(ctx, 42)
+	if err != nil {
+		t.Fatalf("Failed to get runner: %v", err)
+	}
+
+	if runner.Busy {
+		t.Error("Expected runner to be marked idle")
+	}
+}
+
+func TestHandleGetJobTimeline(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	q := queue.NewService(log, cfg, st, testMetrics)
+
+	userCtx := auth.ContextWithUser(ctx, &store.User{Username: "alice", Role: store.RoleAdmin})
+
+	job, err := q.Enqueue(userCtx, "test-group", "", "alice", nil, &queue.EnqueueOptions{
+		Name: "Manual", Owner: "ethpandaops", Repo: "dispatchoor", WorkflowID: "test.yml", Ref: "main",
+	})
+	if err != nil {
+		t.Fatalf("Failed to enqueue job: %v", err)
+	}
+
+	if err := q.MarkTriggered(ctx, job.ID, 42, "https://github.com/ethpandaops/dispatchoor/actions/runs/42"); err != nil {
+		t.Fatalf("Failed to mark triggered: %v", err)
+	}
+
+	if err := q.MarkFailed(ctx, job.ID, "Workflow failure"); err != nil {
+		t.Fatalf("Failed to mark failed: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, q, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+job.ID+"/timeline", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	w := httptest.NewRecorder()
+	srv.(*server).router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var events []*store.JobEvent
+	if err := json.NewDecoder(w.Body).Decode(&events); err != nil {
+		t.Fatalf("Failed to decode timeline: %v", err)
+	}
+
+	want := []struct {
+		from, to store.JobStatus
+		actor    string
+	}{
+		{"", store.JobStatusPending, "alice"},
+		{store.JobStatusPending, store.JobStatusTriggered, "system"},
+		{store.JobStatusTriggered, store.JobStatusFailed, "system"},
+	}
+
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d events, got %d", len(want), len(events))
+	}
+
+	for i, w := range want {
+		if events[i].FromStatus != w.from || events[i].ToStatus != w.to || events[i].Actor != w.actor {
+			t.Errorf("Event %d: expected %q -> %q by %s, got %q -> %q by %s",
+				i, w.from, w.to, w.actor, events[i].FromStatus, events[i].ToStatus, events[i].Actor)
+		}
+	}
+
+	if events[2].RunID == nil || *events[2].RunID != 42 || events[2].Message != "Workflow failure" {
+		t.Errorf("Expected failure event with run 42 and message, got %+v", events[2])
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/jobs/missing/timeline", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	w = httptest.NewRecorder()
+	srv.(*server).router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown job, got %d", w.Code)
+	}
+}
+
+func TestWebhookReopensRerunJob(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	cfg.GitHub.WebhookSecret = "webhook-secret"
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	q := queue.NewService(log, cfg, st, testMetrics)
+
+	job, err := q.Enqueue(ctx, "test-group", "", "alice", nil, &queue.EnqueueOptions{
+		Name: "Manual", Owner: "ethpandaops", Repo: "dispatchoor", WorkflowID: "test.yml", Ref: "main",
+	})
+	if err != nil {
+		t.Fatalf("Failed to enqueue job: %v", err)
+	}
+
+	if err := q.MarkTriggered(ctx, job.ID, 42, "https://github.com/ethpandaops/dispatchoor/actions/runs/42"); err != nil {
+		t.Fatalf("Failed to mark triggered: %v", err)
+	}
+
+	logsURL := "https://api.github.com/repos/ethpandaops/dispatchoor/actions/runs/42/logs"
+	if err := st.UpdateJobRunAttempt(ctx, job.ID, 1, logsURL, ""); err != nil {
+		t.Fatalf("Failed to record run attempt: %v", err)
+	}
+
+	if err := q.MarkFailed(ctx, job.ID, "Workflow failure"); err != nil {
+		t.Fatalf("Failed to mark failed: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, q, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+	s := srv.(*server)
+
+	deliver := func(body string) {
+		mac := hmac.New(sha256.New, []byte("webhook-secret"))
+		mac.Write([]byte(body))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", "workflow_job")
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d", w.Code)
+		}
+	}
+
+	// A late event of the first attempt leaves the job alone.
+	deliver(`{"action":"in_progress","workflow_job":{"id":1,"run_id":42,"run_attempt":1}}`)
+
+	got, err := st.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+
+	if got.Status != store.JobStatusFailed {
+		t.Fatalf("Expected job to stay failed, got %s", got.Status)
+	}
+
+	deliver(`{"action":"queued","workflow_job":{"id":2,"run_id":42,"run_attempt":2}}`)
+
+	got, err = st.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+
+	if got.Status != store.JobStatusTriggered || got.RunAttempt != 2 || got.ErrorMessage != "" {
+		t.Fatalf("Expected job triggered on attempt 2, got %s on attempt %d (%q)", got.Status, got.RunAttempt, got.ErrorMessage)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+job.ID+"/attempts", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp JobAttemptsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode attempts: %v", err)
+	}
+
+	if resp.RunAttempt != 2 || len(resp.Attempts) != 1 {
+		t.Fatalf("Expected attempt 2 with one earlier attempt, got %+v", resp)
+	}
+
+	first := resp.Attempts[0]
+	if first.Attempt != 1 || first.Status != store.JobStatusFailed || first.ErrorMessage != "Workflow failure" ||
+		first.CompletedAt == nil ||
+		first.LogsURL != "https://api.github.com/repos/ethpandaops/dispatchoor/actions/runs/42/attempts/1/logs" {
+		t.Errorf("Unexpected first attempt: %+v", first)
+	}
+}
+
+func TestRetainedJobsSurviveCleanup(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	q := queue.NewService(log, cfg, st, testMetrics)
+
+	var jobs []*store.Job
+
+	for i := range 3 {
+		job, err := q.Enqueue(ctx, "test-group", "", "alice", nil, &queue.EnqueueOptions{
+			Name: "Manual", Owner: "ethpandaops", Repo: "dispatchoor", WorkflowID: "test.yml", Ref: "main",
+		})
+		if err != nil {
+			t.Fatalf("Failed to enqueue job: %v", err)
+		}
+
+		if err := q.MarkTriggered(ctx, job.ID, int64(i+1), ""); err != nil {
+			t.Fatalf("Failed to mark triggered: %v", err)
+		}
+
+		if err := q.MarkFailed(ctx, job.ID, "Workflow failure"); err != nil {
+			t.Fatalf("Failed to mark failed: %v", err)
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, q, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+	s := srv.(*server)
+
+	do := func(method, path string, out any) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		if out != nil && w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(out); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+
+		return w.Code
+	}
+
+	var retained store.Job
+	if code := do(http.MethodPost, "/api/v1/jobs/"+jobs[0].ID+"/retain", &retained); code != http.StatusOK || !retained.Retained {
+		t.Fatalf("Expected job to be retained, got %d %+v", code, retained)
+	}
+
+	if code := do(http.MethodDelete, "/api/v1/jobs/"+jobs[0].ID, nil); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 removing a retained job, got %d", code)
+	}
+
+	var history HistoryResponse
+	if code := do(http.MethodGet, "/api/v1/groups/test-group/history?retained=true", &history); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+
+	if len(history.Jobs) != 1 || history.Jobs[0].ID != jobs[0].ID || history.TotalCount == nil || *history.TotalCount != 1 {
+		t.Errorf("Expected only the retained job in filtered history, got %+v", history)
+	}
+
+	// Retained jobs are neither deleted nor counted towards the cap.
+	if _, err := st.DeleteExcessJobs(ctx, "test-group", 1); err != nil {
+		t.Fatalf("Failed to delete excess jobs: %v", err)
+	}
+
+	for i, want := range []bool{true, false, true} {
+		got, err := st.GetJob(ctx, jobs[i].ID)
+		if err != nil {
+			t.Fatalf("Failed to get job: %v", err)
+		}
+
+		if (got != nil) != want {
+			t.Errorf("Job %d: expected kept=%v after cap, got %v", i, want, got != nil)
+		}
+	}
+
+	if _, err := st.DeleteOldJobs(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to delete old jobs: %v", err)
+	}
+
+	if got, err := st.GetJob(ctx, jobs[0].ID); err != nil || got == nil || !got.Retained {
+		t.Fatalf("Expected retained job to survive retention, got %+v (%v)", got, err)
+	}
+
+	if got, err := st.GetJob(ctx, jobs[2].ID); err != nil || got != nil {
+		t.Errorf("Expected unretained job to be deleted by retention, got %+v (%v)", got, err)
+	}
+
+	var unretained store.Job
+	if code := do(http.MethodPost, "/api/v1/jobs/"+jobs[0].ID+"/unretain", &unretained); code != http.StatusOK || unretained.Retained {
+		t.Fatalf("Expected job to be unretained, got %d %+v", code, unretained)
+	}
+
+	if _, err := st.DeleteOldJobs(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to delete old jobs: %v", err)
+	}
+
+	if got, err := st.GetJob(ctx, jobs[0].ID); err != nil || got != nil {
+		t.Errorf("Expected unretained job to be deleted by retention, got %+v (%v)", got, err)
+	}
+}
+
+func TestAssignFailedJobs(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	q := queue.NewService(log, cfg, st, testMetrics)
+
+	var jobs []*store.Job
+
+	for i := range 2 {
+		job, err := q.Enqueue(ctx, "test-group", "", "alice", nil, &queue.EnqueueOptions{
+			Name: "Manual", Owner: "ethpandaops", Repo: "dispatchoor", WorkflowID: "test.yml", Ref: "main",
+		})
+		if err != nil {
+			t.Fatalf("Failed to enqueue job: %v", err)
+		}
+
+		if err := q.MarkTriggered(ctx, job.ID, int64(i+1), ""); err != nil {
+			t.Fatalf("Failed to mark triggered: %v", err)
+		}
+
+		if i == 0 {
+			err = q.MarkFailed(ctx, job.ID, "Workflow failure")
+		} else {
+			err = q.MarkCompleted(ctx, job.ID)
+		}
+
+		if err != nil {
+			t.Fatalf("Failed to finish job: %v", err)
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, q, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+	s := srv.(*server)
+
+	do := func(method, path, body string, out any) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		if out != nil && w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(out); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+
+		return w.Code
+	}
+
+	// Without a body the caller takes the job.
+	var assigned store.Job
+	if code := do(http.MethodPost, "/api/v1/jobs/"+jobs[0].ID+"/assign", "", &assigned); code != http.StatusOK ||
+		assigned.Assignee != "testadmin" || assigned.AssignedAt == nil {
+		t.Fatalf("Expected job to be assigned to the caller, got %d %+v", code, assigned)
+	}
+
+	if code := do(http.MethodPost, "/api/v1/jobs/"+jobs[1].ID+"/assign", "", nil); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 assigning a completed job, got %d", code)
+	}
+
+	var history HistoryResponse
+	if code := do(http.MethodGet, "/api/v1/groups/test-group/history?assignee=me", "", &history); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+
+	if len(history.Jobs) != 1 || history.Jobs[0].ID != jobs[0].ID || history.TotalCount == nil || *history.TotalCount != 1 {
+		t.Errorf("Expected only the assigned job in filtered history, got %+v", history)
+	}
+
+	// Admins may hand the job to someone else.
+	if code := do(http.MethodPost, "/api/v1/jobs/"+jobs[0].ID+"/assign", `{"assignee":"bob"}`, &assigned); code != http.StatusOK ||
+		assigned.Assignee != "bob" {
+		t.Fatalf("Expected job to be assigned to bob, got %d %+v", code, assigned)
+	}
+
+	if code := do(http.MethodGet, "/api/v1/groups/test-group/history?assignee=me", "", &history); code != http.StatusOK ||
+		len(history.Jobs) != 0 {
+		t.Errorf("Expected no jobs assigned to the caller, got %d %+v", code, history)
+	}
+
+	var unassigned store.Job
+	if code := do(http.MethodPost, "/api/v1/jobs/"+jobs[0].ID+"/unassign", "", &unassigned); code != http.StatusOK ||
+		unassigned.Assignee != "" || unassigned.AssignedAt != nil {
+		t.Fatalf("Expected job to be unassigned, got %d %+v", code, unassigned)
+	}
+
+	got, err := st.GetJob(ctx, jobs[0].ID)
+	if err != nil || got == nil || got.Assignee != "" {
+		t.Errorf("Expected stored job to be unassigned, got %+v (%v)", got, err)
+	}
+}
+
+func TestImpersonation(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	now := time.Now()
+	if err := st.CreateUser(ctx, &store.User{
+		ID: "bob-id", Username: "bob", Role: store.RoleReadOnly, AuthProvider: store.AuthProviderBasic,
+		CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	q := queue.NewService(log, cfg, st, testMetrics)
+
+	job, err := q.Enqueue(ctx, "test-group", "", "alice", nil, &queue.EnqueueOptions{
+		Name: "Manual", Owner: "ethpandaops", Repo: "dispatchoor", WorkflowID: "test.yml", Ref: "main",
+	})
+	if err != nil {
+		t.Fatalf("Failed to enqueue job: %v", err)
+	}
+
+	if err := q.MarkTriggered(ctx, job.ID, 1, ""); err != nil {
+		t.Fatalf("Failed to mark triggered: %v", err)
+	}
+
+	if err := q.MarkFailed(ctx, job.ID, "Workflow failure"); err != nil {
+		t.Fatalf("Failed to mark failed: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, q, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	do := func(method, path, actAs string, out any) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+		req.Header.Set(actAsHeaderName, actAs)
+
+		w := httptest.NewRecorder()
+		srv.(*server).router.ServeHTTP(w, req)
+
+		if out != nil && w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(out); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+
+		return w.Code
+	}
+
+	var me MeResponse
+	if code := do(http.MethodGet, "/api/v1/auth/me", "bob", &me); code != http.StatusOK ||
+		me.User == nil || me.Username != "bob" || me.ImpersonatedBy != "testadmin" {
+		t.Fatalf("Expected to act as bob, got %d %+v", code, me)
+	}
+
+	if code := do(http.MethodGet, "/api/v1/auth/me", "nobody", nil); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 acting as an unknown user, got %d", code)
+	}
+
+	// The admin gets bob's permissions, not their own.
+	if code := do(http.MethodPost, "/api/v1/groups/test-group/pause", "bob", nil); code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for an admin route as bob, got %d", code)
+	}
+
+	var assigned store.Job
+	if code := do(http.MethodPost, "/api/v1/jobs/"+job.ID+"/assign", "bob", &assigned); code != http.StatusOK ||
+		assigned.Assignee != "bob" {
+		t.Fatalf("Expected job to be assigned to bob, got %d %+v", code, assigned)
+	}
+
+	action := store.AuditActionUserImpersonated
+
+	entries, _, err := st.ListAuditEntries(ctx, store.AuditQueryOpts{Action: &action})
+	if err != nil {
+		t.Fatalf("Failed to list audit entries: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("Expected the two state-changing requests to be audited, got %d", len(entries))
+	}
+
+	for _, entry := range entries {
+		if entry.Actor != "testadmin" || entry.EntityID != "bob-id" ||
+			!strings.Contains(entry.Details, "testadmin acting as bob") {
+			t.Errorf("Unexpected audit entry: %+v", entry)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestCampaignLifecycle(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{
+		{
+			"id":          "tmpl-1",
+			"name":        "Template 1",
+			"owner":       "org",
+			"repo":        "repo",
+			"workflow_id": "build.yml",
+			"ref":         "main",
+		},
+	})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	q := queue.NewService(log, cfg, st, testMetrics)
+	gh := github.NewFakeClient()
+
+	srv := NewServer(log, cfg, cfgPath, st, q, &stubAuth{}, gh, gh, testMetrics)
+	router := srv.(*server).router
+
+	do := func(method, path, body string, out any) int {
+		t.Helper()
+
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if out != nil && w.Code < 300 {
+			if err := json.NewDecoder(w.Body).Decode(out); err != nil {
+				t.Fatalf("Failed to decode %s %s: %v", method, path, err)
+			}
+		}
+
+		return w.Code
+	}
+
+	// A campaign with an unknown template is rejected and leaves nothing behind.
+	if code := do(http.MethodPost, "/api/v1/campaigns", `{"name": "Broken", "jobs": [
+		{"group_id": "test-group", "template_id": "tmpl-1"},
+		{"group_id": "test-group", "template_id": "missing"}
+	]}`, nil); code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for unknown template, got %d", code)
+	}
+
+	if pending, _ := q.ListPending(ctx, "test-group"); len(pending) != 0 {
+		t.Fatalf("Expected failed campaign jobs to be removed, got %d pending", len(pending))
+	}
+
+	var created CampaignResponse
+	if code := do(http.MethodPost, "/api/v1/campaigns", `{"name": "Sync", "metadata": {"fork": "fusaka"}, "jobs": [
+		{"group_id": "test-group", "template_id": "tmpl-1", "inputs": {"el": "geth"}},
+		{"group_id": "test-group", "template_id": "tmpl-1", "inputs": {"el": "reth"}},
+		{"group_id": "test-group", "template_id": "tmpl-1", "inputs": {"el": "besu"}}
+	]}`, &created); code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", code)
+	}
+
+	if len(created.Jobs) != 3 || created.Progress.Total != 3 || created.Metadata["fork"] != "fusaka" {
+		t.Fatalf("Unexpected campaign %+v", created)
+	}
+
+	// Add a fourth job through the queue endpoint.
+	if code := do(http.MethodPost, "/api/v1/groups/test-group/queue",
+		`{"template_id": "tmpl-1", "campaign_id": "`+created.ID+`"}`, nil); code != http.StatusCreated {
+		t.Fatalf("Expected status 201 adding a campaign job, got %d", code)
+	}
+
+	if code := do(http.MethodPost, "/api/v1/groups/test-group/queue",
+		`{"template_id": "tmpl-1", "campaign_id": "missing"}`, nil); code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for unknown campaign, got %d", code)
+	}
+
+	// One job finishes and one is running on GitHub.
+	if err := gh.TriggerWorkflowDispatch(ctx, "org", "repo", "build.yml", "main", nil); err != nil {
+		t.Fatalf("Failed to dispatch: %v", err)
+	}
+
+	runID := gh.Dispatches()[0].RunID
+
+	if err := q.MarkTriggered(ctx, created.Jobs[0].ID, 1, ""); err != nil {
+		t.Fatalf("Failed to mark triggered: %v", err)
+	}
+
+	if err := q.MarkCompleted(ctx, created.Jobs[0].ID); err != nil {
+		t.Fatalf("Failed to mark completed: %v", err)
+	}
+
+	if err := q.MarkTriggered(ctx, created.Jobs[1].ID, runID, ""); err != nil {
+		t.Fatalf("Failed to mark triggered: %v", err)
+	}
+
+	var paused CampaignActionResponse
+	if code := do(http.MethodPost, "/api/v1/campaigns/"+created.ID+"/pause", "", &paused); code != http.StatusOK {
+		t.Fatalf("Expected status 200 pausing, got %d", code)
+	}
+
+	if paused.Affected != 2 || paused.Progress.Paused != 2 {
+		t.Errorf("Expected 2 paused jobs, got %+v", paused)
+	}
+
+	var cancelled CampaignActionResponse
+	if code := do(http.MethodPost, "/api/v1/campaigns/"+created.ID+"/cancel", "", &cancelled); code != http.StatusOK {
+		t.Fatalf("Expected status 200 cancelling, got %d", code)
+	}
+
+	if cancelled.Affected != 3 || len(cancelled.Errors) != 0 {
+		t.Errorf("Expected 3 cancelled jobs without errors, got %+v", cancelled)
+	}
+
+	if run := gh.Run(runID); run == nil || run.Conclusion != "cancelled" {
+		t.Errorf("Expected workflow run %d to be cancelled, got %+v", runID, run)
+	}
+
+	var campaigns []*CampaignResponse
+	if code := do(http.MethodGet, "/api/v1/campaigns", "", &campaigns); code != http.StatusOK {
+		t.Fatalf("Expected status 200 listing, got %d", code)
+	}
+
+	want := CampaignProgress{Total: 4, Completed: 1, Cancelled: 3, Finished: 4}
+	if len(campaigns) != 1 || campaigns[0].Progress != want {
+		t.Errorf("Expected one campaign with progress %+v, got %+v", want, campaigns)
+	}
+
+	if code := do(http.MethodGet, "/api/v1/campaigns/missing", "", nil); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown campaign, got %d", code)
+	}
+}
+
+func TestReloadTemplatesWithSyncApproval(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	tmpl := func(id, ref string) map[string]any {
+		return map[string]any{
+			"id": id, "name": id, "owner": "org", "repo": "repo", "workflow_id": "build.yml", "ref": ref,
+		}
+	}
+
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{tmpl("tmpl-1", "main"), tmpl("tmpl-2", "main")})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	cfg.Sync.RequireApproval = true
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+	router := srv.(*server).router
+
+	do := func(method, path string, out any) int {
+		t.Helper()
+
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if out != nil && w.Code < 300 {
+			if err := json.NewDecoder(w.Body).Decode(out); err != nil {
+				t.Fatalf("Failed to decode %s %s: %v", method, path, err)
+			}
+		}
+
+		return w.Code
+	}
+
+	// Reloading an unchanged config applies immediately.
+	if code := do(http.MethodPost, "/api/v1/templates/reload", nil); code != http.StatusOK {
+		t.Fatalf("Expected status 200 for unchanged config, got %d", code)
+	}
+
+	// Change the ref of tmpl-1, drop tmpl-2 and add tmpl-3.
+	writeTestConfig(t, tmpDir, dbPath, []map[string]any{tmpl("tmpl-1", "release"), tmpl("tmpl-3", "main")})
+
+	var resp ReloadTemplatesResponse
+	if code := do(http.MethodPost, "/api/v1/templates/reload", &resp); code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", code)
+	}
+
+	if resp.Staged == nil {
+		t.Fatal("Expected a staged sync")
+	}
+
+	diff := resp.Staged.Diff
+	if len(diff.TemplatesCreated) != 1 || diff.TemplatesCreated[0].TemplateID != "tmpl-3" {
+		t.Errorf("Expected tmpl-3 to be created, got %+v", diff.TemplatesCreated)
+	}
+
+	if len(diff.TemplatesUpdated) != 1 || !slices.Equal(diff.TemplatesUpdated[0].Fields, []string{"ref"}) {
+		t.Errorf("Expected the ref of tmpl-1 to be updated, got %+v", diff.TemplatesUpdated)
+	}
+
+	if len(diff.TemplatesDeleted) != 1 || diff.TemplatesDeleted[0].TemplateID != "tmpl-2" {
+		t.Errorf("Expected tmpl-2 to be deleted, got %+v", diff.TemplatesDeleted)
+	}
+
+	// Nothing is applied until approval.
+	template, err := st.GetJobTemplate(ctx, "tmpl-1")
+	if err != nil || template == nil || template.Ref != "main" {
+		t.Fatalf("Expected tmpl-1 to keep ref main before approval, got %+v (%v)", template, err)
+	}
+
+	var staged StagedConfigSync
+	if code := do(http.MethodGet, "/api/v1/config/sync", &staged); code != http.StatusOK || staged.ID != resp.Staged.ID {
+		t.Fatalf("Expected staged sync %s, got %d %+v", resp.Staged.ID, code, staged)
+	}
+
+	if code := do(http.MethodPost, "/api/v1/config/sync/other/approve", nil); code != http.StatusConflict {
+		t.Errorf("Expected status 409 for another sync ID, got %d", code)
+	}
+
+	if code := do(http.MethodPost, "/api/v1/config/sync/"+staged.ID+"/approve", nil); code != http.StatusOK {
+		t.Fatalf("Expected status 200 approving, got %d", code)
+	}
+
+	templates, err := st.ListJobTemplatesByGroup(ctx, "test-group")
+	if err != nil {
+		t.Fatalf("Failed to list templates: %v", err)
+	}
+
+	refs := make(map[string]string, len(templates))
+	for _, tmpl := range templates {
+		refs[tmpl.ID] = tmpl.Ref
+	}
+
+	if !maps.Equal(refs, map[string]string{"tmpl-1": "release", "tmpl-3": "main"}) {
+		t.Errorf("Unexpected templates after approval: %v", refs)
+	}
+
+	if code := do(http.MethodGet, "/api/v1/config/sync", nil); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after approval, got %d", code)
+	}
+}
+
+func TestGitTemplateSources(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	repoDir := filepath.Join(tmpDir, "org", "templates")
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	git := func(args ...string) {
+		t.Helper()
+
+		cmd := exec.Command("git", append([]string{
+			"-C", repoDir, "-c", "user.name=test", "-c", "user.email=test@example.com",
+		}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	commit := func(ref string) {
+		t.Helper()
+
+		data := fmt.Sprintf(`- id: git-tmpl
+  name: Git Template
+  owner: org
+  repo: repo
+  workflow_id: test.yml
+  ref: %s
+`, ref)
+		if err := os.WriteFile(filepath.Join(repoDir, "ci", "templates.yaml"), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		git("add", "-A")
+		git("commit", "--quiet", "-m", "Update templates")
+	}
+
+	if err := os.MkdirAll(filepath.Join(repoDir, "ci"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	git("init", "--quiet", "--initial-branch=main")
+	commit("main")
+
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	cfgData := fmt.Sprintf(`server:
+  listen: ":0"
+database:
+  driver: sqlite
+  sqlite:
+    path: %s
+auth:
+  basic:
+    enabled: true
+    users:
+      - {username: admin, password: pass, role: admin}
+github:
+  webhook_secret: webhook-secret
+groups:
+  github:
+    - id: test-group
+      name: Test Group
+      runner_labels: [self-hosted]
+      workflow_dispatch_templates_git:
+        - url: file://%s
+          ref: main
+          path: ci/templates.yaml
+`, dbPath, repoDir)
+	if err := os.WriteFile(cfgPath, []byte(cfgData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	tmpl := cfg.Groups.GitHub[0].WorkflowDispatchTemplates
+	if len(tmpl) != 1 || tmpl[0].ID != "git-tmpl" || tmpl[0].SourceType != "git" ||
+		tmpl[0].SourcePath != "file://"+repoDir+"@main:ci/templates.yaml" {
+		t.Fatalf("Unexpected templates from git: %+v", tmpl)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+	s := srv.(*server)
+
+	deliver := func(body string) {
+		mac := hmac.New(sha256.New, []byte("webhook-secret"))
+		mac.Write([]byte(body))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", "push")
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d", w.Code)
+		}
+	}
+
+	commit("release")
+
+	// Pushes to other repositories or refs are ignored.
+	deliver(`{"ref":"refs/heads/main","repository":{"full_name":"org/other","default_branch":"main"}}`)
+	deliver(`{"ref":"refs/heads/dev","repository":{"full_name":"org/templates","default_branch":"main"}}`)
+
+	if len(s.gitRefresh) != 0 {
+		t.Fatal("Expected no refresh for unrelated pushes")
+	}
+
+	deliver(`{"ref":"refs/heads/main","repository":{"full_name":"Org/Templates","default_branch":"main"}}`)
+
+	if len(s.gitRefresh) != 1 {
+		t.Fatal("Expected a refresh request for a push to the source ref")
+	}
+
+	refreshCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		s.gitRefreshLoop(refreshCtx, 0)
+	}()
+
+	deadline := time.Now().Add(10 * time.Second)
+
+	for {
+		template, err := st.GetJobTemplate(ctx, "git-tmpl")
+		if err != nil {
+			t.Fatalf("Failed to get template: %v", err)
+		}
+
+		if template != nil && template.Ref == "release" {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the template to be refreshed from git, got %+v", template)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+}
+
+func TestHandleStatus_Maintenance(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	results, err := st.RunMaintenance(ctx, store.MaintenanceOpts{Vacuum: true, Analyze: true})
+	if err != nil {
+		t.Fatalf("Failed to run maintenance: %v", err)
+	}
+
+	if len(results) != 2 || results[0].Task != "VACUUM" || results[1].Task != "ANALYZE" {
+		t.Fatalf("Unexpected maintenance results %+v", results)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	s := srv.(*server)
+
+	getStatus := func() SystemStatusResponse {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/status", nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var resp SystemStatusResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode status: %v", err)
+		}
+
+		return resp
+	}
+
+	if resp := getStatus(); resp.Database.Maintenance != nil {
+		t.Errorf("Expected no maintenance status when disabled, got %+v", resp.Database.Maintenance)
+	}
+
+	lastRun := time.Now()
+	status := maintenance.Status{
+		Interval:  24 * time.Hour,
+		NextRunAt: lastRun.Add(24 * time.Hour),
+		LastRunAt: &lastRun,
+		Results:   results,
+	}
+
+	srv.SetMaintenanceStatus(func() maintenance.Status { return status })
+
+	resp := getStatus()
+	if m := resp.Database.Maintenance; m == nil || m.Status != ComponentStatusHealthy ||
+		m.LastRunAt == "" || len(m.Tasks) != 2 {
+		t.Fatalf("Expected healthy maintenance status with 2 tasks, got %+v", m)
+	}
+
+	status.Error = "running VACUUM: database is locked"
+
+	if m := getStatus().Database.Maintenance; m.Status != ComponentStatusDegraded || m.Error != status.Error {
+		t.Errorf("Expected degraded maintenance status, got %+v", m)
+	}
+}
+
+func TestHandleGetQueueChanges(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	q := queue.NewService(log, cfg, st, testMetrics)
+
+	enqueue := func(name string) *store.Job {
+		job, err := q.Enqueue(ctx, "test-group", "", "alice", nil, &queue.EnqueueOptions{
+			Name: name, Owner: "ethpandaops", Repo: "dispatchoor", WorkflowID: "test.yml", Ref: "main",
+		})
+		if err != nil {
+			t.Fatalf("Failed to enqueue job: %v", err)
+		}
+
+		return job
+	}
+
+	first, second := enqueue("First"), enqueue("Second")
+
+	srv := NewServer(log, cfg, cfgPath, st, q, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		srv.(*server).router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	for _, step := range []struct {
+		method, path, body string
+	}{
+		{http.MethodPut, "/api/v1/groups/test-group/queue/reorder", `{"job_ids":["` + second.ID + `","` + first.ID + `"]}`},
+		{http.MethodPost, "/api/v1/jobs/" + first.ID + "/pause", ""},
+		{http.MethodPut, "/api/v1/jobs/" + first.ID, `{"priority":5}`},
+		{http.MethodDelete, "/api/v1/jobs/" + second.ID, ""},
+	} {
+		if w := do(step.method, step.path, step.body); w.Code >= 300 {
+			t.Fatalf("%s %s: expected success, got %d: %s", step.method, step.path, w.Code, w.Body.String())
+		}
+	}
+
+	list := func(query string) QueueChangesResponse {
+		w := do(http.MethodGet, "/api/v1/groups/test-group/queue/changes"+query, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var resp QueueChangesResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode queue changes: %v", err)
+		}
+
+		return resp
+	}
+
+	resp := list("")
+	ops := make([]store.QueueChangeOperation, 0, len(resp.Changes))
+
+	for _, change := range resp.Changes {
+		ops = append(ops, change.Operation)
+
+		if change.Actor != "testadmin" {
+			t.Errorf("Expected %s by testadmin, got %s", change.Operation, change.Actor)
+		}
+	}
+
+	want := []store.QueueChangeOperation{
+		store.QueueChangeDelete, store.QueueChangePriority, store.QueueChangePause, store.QueueChangeReorder,
+	}
+	if resp.Total != len(want) || !slices.Equal(ops, want) {
+		t.Fatalf("Expected changes %v (newest first), got %v (total %d)", want, ops, resp.Total)
+	}
+
+	reorder := resp.Changes[3]
+	if len(reorder.Before) != 2 || reorder.Before[0].JobID != first.ID ||
+		len(reorder.After) != 2 || reorder.After[0].JobID != second.ID {
+		t.Errorf("Expected reorder snapshots to swap the jobs, got %+v -> %+v", reorder.Before, reorder.After)
+	}
+
+	priority := resp.Changes[1]
+	if priority.Before[0].Priority != 0 || priority.After[0].Priority != 5 || !priority.After[0].Paused {
+		t.Errorf("Expected priority 0 -> 5 on a paused job, got %+v -> %+v", priority.Before, priority.After)
+	}
+
+	if deleted := resp.Changes[0]; deleted.JobID != second.ID || len(deleted.After) != 0 {
+		t.Errorf("Expected delete of %s with empty after, got %+v", second.ID, deleted)
+	}
+
+	// Filtering by job includes reorders that moved it.
+	if resp := list("?job_id=" + second.ID + "&limit=1"); resp.Total != 2 || len(resp.Changes) != 1 ||
+		resp.Changes[0].Operation != store.QueueChangeDelete {
+		t.Errorf("Expected 2 changes for %s with the delete first, got %+v", second.ID, resp)
+	}
+
+	if w := do(http.MethodGet, "/api/v1/groups/missing/queue/changes", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown group, got %d", w.Code)
+	}
+}
+
+func TestHandleSessions(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	authSvc := auth.NewService(log, cfg, st)
+	if err := authSvc.Start(ctx); err != nil {
+		t.Fatalf("Failed to start auth: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, authSvc,
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	s := srv.(*server)
+
+	do := func(method, path, token, userAgent, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+		req.RemoteAddr = "203.0.113.7:54321"
+
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	login := func(userAgent string) string {
+		w := do(http.MethodPost, "/api/v1/auth/login", "", userAgent, `{"username":"admin","password":"pass"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected login status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var resp LoginResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode login: %v", err)
+		}
+
+		return resp.Token
+	}
+
+	laptop := login("laptop")
+	phone := login("phone")
+
+	w := do(http.MethodGet, "/api/v1/auth/sessions", laptop, "laptop", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp SessionsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode sessions: %v", err)
+	}
+
+	if len(resp.Sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(resp.Sessions))
+	}
+
+	var phoneSession SessionResponse
+
+	for _, session := range resp.Sessions {
+		if session.Username != "admin" || session.IPAddress != "203.0.113.7" || session.LastUsedAt == nil {
+			t.Errorf("Unexpected session %+v", session)
+		}
+
+		if session.Current != (session.UserAgent == "laptop") {
+			t.Errorf("Expected only the laptop session to be current, got %+v", session)
+		}
+
+		if session.UserAgent == "phone" {
+			phoneSession = session
+		}
+	}
+
+	if phoneSession.Session == nil {
+		t.Fatal("Expected a phone session")
+	}
+
+	if w := do(http.MethodDelete, "/api/v1/auth/sessions/"+phoneSession.ID, laptop, "laptop", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := do(http.MethodGet, "/api/v1/auth/me", phone, "phone", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected revoked session to be rejected, got %d", w.Code)
+	}
+
+	if w := do(http.MethodDelete, "/api/v1/auth/sessions/missing", laptop, "laptop", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown session, got %d", w.Code)
+	}
+}
+
+func TestCSRFMiddleware(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	authSvc := auth.NewService(log, cfg, st)
+	if err := authSvc.Start(ctx); err != nil {
+		t.Fatalf("Failed to start auth: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, authSvc,
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	s := srv.(*server)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"username":"admin","password":"pass"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected login status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	cookies := map[string]string{}
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie.Value
+	}
+
+	if cookies["session"] == "" || cookies[csrfCookieName] != auth.CSRFToken(cookies["session"]) {
+		t.Fatalf("Expected session and CSRF cookies, got %v", cookies)
+	}
+
+	created := 0
+
+	createFilter := func(setAuth func(req *http.Request)) int {
+		created++
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/filters",
+			strings.NewReader(fmt.Sprintf(`{"name":"Filter %d","view":"history"}`, created)))
+		req.Header.Set("Content-Type", "application/json")
+		setAuth(req)
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	sessionCookie := &http.Cookie{Name: "session", Value: cookies["session"]}
+
+	if code := createFilter(func(req *http.Request) { req.AddCookie(sessionCookie) }); code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for cookie auth without CSRF token, got %d", code)
+	}
+
+	if code := createFilter(func(req *http.Request) {
+		req.AddCookie(sessionCookie)
+		req.Header.Set(csrfHeaderName, "forged")
+	}); code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for cookie auth with a wrong CSRF token, got %d", code)
+	}
+
+	if code := createFilter(func(req *http.Request) {
+		req.AddCookie(sessionCookie)
+		req.Header.Set(csrfHeaderName, cookies[csrfCookieName])
+	}); code != http.StatusCreated {
+		t.Errorf("Expected status 201 for cookie auth with CSRF token, got %d", code)
+	}
+
+	if code := createFilter(func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+cookies["session"])
+	}); code != http.StatusCreated {
+		t.Errorf("Expected status 201 for bearer auth without CSRF token, got %d", code)
+	}
+}
+
+func TestJWTSessions(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	cfg.Auth.JWT = config.JWTConfig{
+		Enabled:   true,
+		Secret:    strings.Repeat("s", 32),
+		AccessTTL: 15 * time.Minute,
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	authSvc := auth.NewService(log, cfg, st)
+	if err := authSvc.Start(ctx); err != nil {
+		t.Fatalf("Failed to start auth: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, authSvc,
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	s := srv.(*server)
+
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	decode := func(w *httptest.ResponseRecorder) LoginResponse {
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var resp LoginResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode login: %v", err)
+		}
+
+		return resp
+	}
+
+	login := decode(do(http.MethodPost, "/api/v1/auth/login", "", `{"username":"admin","password":"pass"}`))
+	if strings.Count(login.Token, ".") != 2 || login.RefreshToken == "" || login.ExpiresAt == nil {
+		t.Fatalf("Expected an access token, refresh token and expiry, got %+v", login)
+	}
+
+	if w := do(http.MethodGet, "/api/v1/auth/me", login.Token, ""); w.Code != http.StatusOK {
+		t.Errorf("Expected access token to authenticate, got %d", w.Code)
+	}
+
+	if w := do(http.MethodGet, "/api/v1/auth/me", login.RefreshToken, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected refresh token to be rejected as an access token, got %d", w.Code)
+	}
+
+	parts := strings.Split(login.Token, ".")
+	forged := parts[0] + "." + strings.TrimRight(parts[1], "=") + "x." + parts[2]
+
+	if w := do(http.MethodGet, "/api/v1/auth/me", forged, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected tampered access token to be rejected, got %d", w.Code)
+	}
+
+	refreshBody := fmt.Sprintf(`{"refresh_token":%q}`, login.RefreshToken)
+
+	refreshed := decode(do(http.MethodPost, "/api/v1/auth/refresh", "", refreshBody))
+	if refreshed.User == nil || refreshed.User.Username != "admin" || refreshed.RefreshToken != login.RefreshToken {
+		t.Fatalf("Unexpected refresh response %+v", refreshed)
+	}
+
+	if w := do(http.MethodPost, "/api/v1/auth/logout", refreshed.Token, ""); w.Code != http.StatusNoContent {
+		t.Fatalf("Expected logout status 204, got %d", w.Code)
+	}
+
+	if w := do(http.MethodPost, "/api/v1/auth/refresh", "", refreshBody); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected refresh after logout to fail, got %d", w.Code)
+	}
+}
+
+func TestHandleAddJob_Limits(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	cfg.Server.Limits.MaxInputs = 2
+	cfg.Server.Limits.MaxInputValueLength = 8
+	cfg.Server.Limits.MaxLabelKeyLength = 4
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, queue.NewService(log, cfg, st, testMetrics), &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	for _, tc := range []struct {
+		name   string
+		fields string
+		status int
+	}{
+		{name: "within limits", fields: `"inputs":{"a":"1","b":"2"},"labels":{"env":"ci"}`, status: http.StatusCreated},
+		{name: "too many inputs", fields: `"inputs":{"a":"1","b":"2","c":"3"}`, status: http.StatusBadRequest},
+		{name: "input value too long", fields: `"inputs":{"a":"123456789"}`, status: http.StatusBadRequest},
+		{name: "label key too long", fields: `"labels":{"owner":"me"}`, status: http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			body := `{"name":"Manual","owner":"ethpandaops","repo":"dispatchoor","workflow_id":"test.yml","ref":"main",` +
+				tc.fields + `}`
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/groups/test-group/queue", strings.NewReader(body))
+			req.Header.Set("Authorization", "Bearer test-token")
+
+			w := httptest.NewRecorder()
+			srv.(*server).router.ServeHTTP(w, req)
+
+			if w.Code != tc.status {
+				t.Errorf("Expected %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleAddJob_GroupCaps(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{{
+		"id": "tmpl", "name": "Template", "owner": "ethpandaops", "repo": "dispatchoor",
+		"workflow_id": "test.yml", "ref": "main", "default_priority": 5,
+	}})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	maxPriority, maxRequeueLimit := 10, 3
+	cfg.Groups.GitHub[0].MaxPriority = &maxPriority
+	cfg.Groups.GitHub[0].MaxRequeueLimit = &maxRequeueLimit
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, queue.NewService(log, cfg, st, testMetrics), &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	for _, tc := range []struct {
+		name         string
+		fields       string
+		status       int
+		priority     int
+		requeueLimit int
+	}{
+		{name: "template default priority", fields: `"auto_requeue":false`, status: http.StatusCreated, priority: 5},
+		{name: "priority within cap", fields: `"priority":10`, status: http.StatusCreated, priority: 10},
+		{name: "priority over cap", fields: `"priority":11`, status: http.StatusBadRequest},
+		{name: "unlimited requeue capped", fields: `"auto_requeue":true`, status: http.StatusCreated, priority: 5, requeueLimit: 3},
+		{name: "requeue limit over cap", fields: `"auto_requeue":true,"requeue_limit":4`, status: http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/groups/test-group/queue",
+				strings.NewReader(`{"template_id":"tmpl",`+tc.fields+`}`))
+			req.Header.Set("Authorization", "Bearer test-token")
+
+			w := httptest.NewRecorder()
+			srv.(*server).router.ServeHTTP(w, req)
+
+			if w.Code != tc.status {
+				t.Fatalf("Expected %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+
+			if w.Code != http.StatusCreated {
+				return
+			}
+
+			var job store.Job
+			if err := json.NewDecoder(w.Body).Decode(&job); err != nil {
+				t.Fatalf("Failed to decode job: %v", err)
+			}
+
+			if job.Priority != tc.priority {
+				t.Errorf("Expected priority %d, got %d", tc.priority, job.Priority)
+			}
+
+			if tc.requeueLimit != 0 && (job.RequeueLimit == nil || *job.RequeueLimit != tc.requeueLimit) {
+				t.Errorf("Expected requeue limit %d, got %v", tc.requeueLimit, job.RequeueLimit)
+			}
+		})
+	}
+}
+
+func TestHandleCompareJobs(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{{
+		"id": "tmpl", "name": "Template", "owner": "ethpandaops", "repo": "dispatchoor",
+		"workflow_id": "test.yml", "ref": "main", "inputs": map[string]string{"client": "geth", "network": "hoodi"},
+	}})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	q := queue.NewService(log, cfg, st, testMetrics)
+
+	finish := func(inputs input.Map, opts *queue.EnqueueOptions, status store.JobStatus, took time.Duration) *store.Job {
+		job, err := q.Enqueue(ctx, "test-group", "tmpl", "alice", inputs, opts)
+		if err != nil {
+			t.Fatalf("Failed to enqueue job: %v", err)
+		}
+
+		completedAt := time.Now()
+		triggeredAt := completedAt.Add(-took)
+		job.Status = status
+		job.TriggeredAt = &triggeredAt
+		job.CompletedAt = &completedAt
+		job.RunnerName = "runner-" + string(status)
+
+		if err := st.UpdateJob(ctx, job); err != nil {
+			t.Fatalf("Failed to update job: %v", err)
+		}
+
+		return job
+	}
+
+	green := finish(nil, nil, store.JobStatusCompleted, time.Hour)
+	red := finish(input.FromStrings(map[string]string{"client": "besu", "extra": "1"}), &queue.EnqueueOptions{Ref: "feature"},
+		store.JobStatusFailed, 90*time.Minute)
+
+	manual, err := q.Enqueue(ctx, "test-group", "", "alice", nil, &queue.EnqueueOptions{
+		Name: "Manual", Owner: "ethpandaops", Repo: "dispatchoor", WorkflowID: "test.yml", Ref: "main",
+	})
+	if err != nil {
+		t.Fatalf("Failed to enqueue manual job: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, q, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	do := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/compare?"+query, nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		srv.(*server).router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	w := do("a=" + green.ID + "&b=" + red.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var cmp JobComparison
+	if err := json.NewDecoder(w.Body).Decode(&cmp); err != nil {
+		t.Fatalf("Failed to decode comparison: %v", err)
+	}
+
+	if len(cmp.Inputs) != 2 || cmp.Inputs[0].Key != "client" || cmp.Inputs[0].A.String() != "geth" ||
+		cmp.Inputs[0].B.String() != "besu" || cmp.Inputs[1].Key != "extra" || cmp.Inputs[1].A != nil {
+		t.Errorf("Unexpected input changes: %+v", cmp.Inputs)
+	}
+
+	if cmp.Ref != (FieldChange{A: "main", B: "feature", Changed: true}) {
+		t.Errorf("Unexpected ref change: %+v", cmp.Ref)
+	}
+
+	if !cmp.Conclusion.Changed || cmp.Conclusion.B != string(store.JobStatusFailed) || !cmp.Runner.Changed {
+		t.Errorf("Unexpected conclusion %+v or runner %+v", cmp.Conclusion, cmp.Runner)
+	}
+
+	if cmp.Duration.DeltaSeconds == nil || *cmp.Duration.DeltaSeconds != 1800 {
+		t.Errorf("Expected a 1800s duration delta, got %+v", cmp.Duration)
+	}
+
+	for query, status := range map[string]int{
+		"a=" + green.ID:                     http.StatusBadRequest,
+		"a=" + green.ID + "&b=" + manual.ID: http.StatusBadRequest,
+		"a=" + green.ID + "&b=missing":      http.StatusNotFound,
+	} {
+		if w := do(query); w.Code != status {
+			t.Errorf("%s: expected %d, got %d", query, status, w.Code)
+		}
+	}
+}
+
+func TestHandleAddJob_StrictRefs(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{{
+		"id": "stale", "name": "Stale", "owner": "ethpandaops", "repo": "dispatchoor",
+		"workflow_id": "test.yml", "ref": "deleted-branch",
+	}})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	cfg.Queue.StrictRefs = true
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	dispatchClient := &stubGitHubClient{connected: true, missingRefs: map[string]bool{"deleted-branch": true}}
+	srv := NewServer(log, cfg, cfgPath, st, queue.NewService(log, cfg, st, testMetrics), &stubAuth{},
+		&stubGitHubClient{}, dispatchClient, testMetrics)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		srv.(*server).router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	manual := `{"name":"Manual","owner":"ethpandaops","repo":"dispatchoor","workflow_id":"test.yml","ref":"%s"}`
+
+	if w := do(http.MethodPost, "/api/v1/groups/test-group/queue", `{"template_id":"stale"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a template with a missing ref, got %d", w.Code)
+	}
+
+	if w := do(http.MethodPost, "/api/v1/groups/test-group/queue", fmt.Sprintf(manual, "deleted-branch")); w.Code != http.StatusBadRequest ||
+		!strings.Contains(w.Body.String(), "deleted-branch") {
+		t.Errorf("Expected 400 naming the missing ref, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := do(http.MethodPost, "/api/v1/groups/test-group/queue", fmt.Sprintf(manual, "main"))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201 for an existing ref, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var job store.Job
+	if err := json.NewDecoder(w.Body).Decode(&job); err != nil {
+		t.Fatalf("Failed to decode job: %v", err)
+	}
+
+	if w := do(http.MethodPut, "/api/v1/jobs/"+job.ID, `{"ref":"deleted-branch"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 when updating to a missing ref, got %d", w.Code)
+	}
+
+	// Without a connected client the job is accepted and dispatch reports the ref.
+	dispatchClient.connected = false
+
+	if w := do(http.MethodPost, "/api/v1/groups/test-group/queue", `{"template_id":"stale"}`); w.Code != http.StatusCreated {
+		t.Errorf("Expected 201 when GitHub cannot be asked, got %d", w.Code)
+	}
+}
+
+func TestHandleReportJobProgress(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	cfg.Auth.APITokens = []config.APIToken{{Name: "ci", Token: strings.Repeat("x", 32)}}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	q := queue.NewService(log, cfg, st, testMetrics)
+
+	job, err := q.Enqueue(ctx, "test-group", "", "alice", nil, &queue.EnqueueOptions{
+		Name: "Manual", Owner: "ethpandaops", Repo: "dispatchoor", WorkflowID: "test.yml", Ref: "main",
+	})
+	if err != nil {
+		t.Fatalf("Failed to enqueue job: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, q, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	report := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs/"+job.ID+"/progress", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		srv.(*server).router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	// Session tokens are not API tokens.
+	if w := report("test-token", `{"percent":10}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a session token, got %d", w.Code)
+	}
+
+	if w := report(strings.Repeat("x", 32), `{"percent":10}`); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a pending job, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if err := q.MarkTriggered(ctx, job.ID, 42, "https://github.com/ethpandaops/dispatchoor/actions/runs/42"); err != nil {
+		t.Fatalf("Failed to mark triggered: %v", err)
+	}
+
+	if w := report(strings.Repeat("x", 32), `{"percent":101}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for percent over 100, got %d", w.Code)
+	}
+
+	if w := report(strings.Repeat("x", 32), `{"percent":40,"stage":"syncing","message":"Block 1200000"}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs/"+job.ID+"/heartbeat", nil)
+	req.Header.Set("Authorization", "Bearer "+strings.Repeat("x", 32))
+
+	w := httptest.NewRecorder()
+	srv.(*server).router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected heartbeat status 204, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Progress survives later job updates.
+	if err := q.MarkRunning(ctx, job.ID, 7, "runner-1"); err != nil {
+		t.Fatalf("Failed to mark running: %v", err)
+	}
+
+	stored, err := st.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+
+	if stored.Progress == nil || stored.Progress.Percent == nil || *stored.Progress.Percent != 40 ||
+		stored.Progress.Stage != "syncing" || stored.Progress.Message != "Block 1200000" {
+		t.Errorf("Unexpected progress %+v", stored.Progress)
+	}
+
+	if stored.HeartbeatAt == nil {
+		t.Error("Expected heartbeat_at to be set")
+	}
+}
+
+func TestHandleSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	now := time.Now()
+	for _, id := range []string{"test-user-id", "other-user-id"} {
+		if err := st.CreateUser(ctx, &store.User{
+			ID: id, Username: id, Role: store.RoleAdmin, AuthProvider: store.AuthProviderBasic,
+			CreatedAt: now, UpdatedAt: now,
+		}); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	if err := st.CreateSubscription(ctx, &store.Subscription{
+		ID: "other-sub", UserID: "other-user-id", TargetType: store.SubscriptionTargetTemplate,
+		TargetID: "tmpl", Channel: store.SubscriptionChannelSlack, Destination: "https://example.com",
+		CreatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+
+	received := make(chan notify.WebhookPayload, 4)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload notify.WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode webhook payload: %v", err)
+		}
+
+		received <- payload
+	}))
+	defer hook.Close()
+
+	q := queue.NewService(log, cfg, st, testMetrics)
+
+	notifier := notify.NewService(log, cfg, st)
+	if err := notifier.Start(ctx); err != nil {
+		t.Fatalf("Failed to start notifier: %v", err)
+	}
+	defer func() { _ = notifier.Stop() }()
+
+	q.SetJobTransitionCallback(notifier.JobTransition)
+
+	job, err := q.Enqueue(ctx, "test-group", "", "alice", nil, &queue.EnqueueOptions{
+		Name: "Manual", Owner: "ethpandaops", Repo: "dispatchoor", WorkflowID: "test.yml", Ref: "main",
+	})
+	if err != nil {
+		t.Fatalf("Failed to enqueue job: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, q, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		srv.(*server).router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	for _, tc := range []struct {
+		name string
+		body string
+		code int
+	}{
+		{"unknown channel", `{"target_type":"job","target_id":"` + job.ID + `","channel":"sms","destination":"x"}`, http.StatusBadRequest},
+		{"email not configured", `{"target_type":"job","target_id":"` + job.ID + `","channel":"email","destination":"a@example.com"}`, http.StatusBadRequest},
+		{"not a URL", `{"target_type":"job","target_id":"` + job.ID + `","channel":"webhook","destination":"ftp://x"}`, http.StatusBadRequest},
+		{"invalid status", `{"target_type":"job","target_id":"` + job.ID + `","channel":"webhook","destination":"https://x","statuses":["done"]}`, http.StatusBadRequest},
+		{"unknown template", `{"target_type":"template","target_id":"missing","channel":"webhook","destination":"https://x"}`, http.StatusNotFound},
+	} {
+		if w := do(http.MethodPost, "/api/v1/subscriptions", tc.body); w.Code != tc.code {
+			t.Errorf("%s: expected status %d, got %d: %s", tc.name, tc.code, w.Code, w.Body.String())
+		}
+	}
+
+	w := do(http.MethodPost, "/api/v1/subscriptions",
+		`{"target_type":"job","target_id":"`+job.ID+`","channel":"webhook","destination":"`+hook.URL+`","statuses":["failed"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var created store.Subscription
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	w = do(http.MethodGet, "/api/v1/subscriptions", "")
+
+	var subs []*store.Subscription
+	if err := json.NewDecoder(w.Body).Decode(&subs); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(subs) != 1 || subs[0].ID != created.ID {
+		t.Errorf("Expected only the user's own subscription, got %+v", subs)
+	}
+
+	if w := do(http.MethodDelete, "/api/v1/subscriptions/other-sub", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for another user's subscription, got %d", w.Code)
+	}
+
+	// The job's own notify targets are delivered alongside subscriptions.
+	if w := do(http.MethodPut, "/api/v1/jobs/"+job.ID+"/notify-targets",
+		`{"targets":[{"channel":"webhook","destination":"ftp://x"}]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid notify target, got %d", w.Code)
+	}
+
+	w = do(http.MethodPut, "/api/v1/jobs/"+job.ID+"/notify-targets",
+		`{"targets":[{"channel":"webhook","destination":"`+hook.URL+`/target","statuses":["failed"]}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Only the transition into failed is delivered.
+	if err := q.MarkTriggered(ctx, job.ID, 42, "https://github.com/ethpandaops/dispatchoor/actions/runs/42"); err != nil {
+		t.Fatalf("Failed to mark triggered: %v", err)
+	}
+
+	if err := q.MarkFailed(ctx, job.ID, "boom"); err != nil {
+		t.Fatalf("Failed to mark failed: %v", err)
+	}
+
+	subscriptionIDs := make(map[string]bool)
+
+	for range 2 {
+		select {
+		case payload := <-received:
+			if payload.JobID != job.ID || payload.FromStatus != store.JobStatusTriggered ||
+				payload.Status != store.JobStatusFailed || payload.ErrorMessage != "boom" {
+				t.Errorf("Unexpected webhook payload: %+v", payload)
+			}
+
+			subscriptionIDs[payload.SubscriptionID] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for webhook")
+		}
+	}
+
+	if !subscriptionIDs[created.ID] || !subscriptionIDs[""] {
+		t.Errorf("Expected one webhook for the subscription and one for the notify target, got %v", subscriptionIDs)
+	}
+
+	if w := do(http.MethodPut, "/api/v1/jobs/"+job.ID+"/notify-targets", `{"targets":[]}`); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a finished job, got %d", w.Code)
+	}
+
+	// Subscriptions to a finished job are removed after delivery.
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		remaining, err := st.ListSubscriptionsByTarget(ctx, store.SubscriptionTargetJob, job.ID)
+		if err != nil {
+			t.Fatalf("Failed to list subscriptions: %v", err)
+		}
+
+		if len(remaining) == 0 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the finished job's subscriptions to be removed")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case payload := <-received:
+		t.Errorf("Unexpected extra webhook: %+v", payload)
+	default:
+	}
+}
+
+func TestHandleSettings(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	settingsSvc := settings.NewService(log, cfg, st)
+
+	var applied []settings.Values
+
+	settingsSvc.SetChangeCallback(func(values settings.Values) {
+		applied = append(applied, values)
+	})
+
+	if err := settingsSvc.Start(ctx); err != nil {
+		t.Fatalf("Failed to start settings: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+	srv.SetSettings(settingsSvc)
+
+	do := func(method, body string) (int, SettingsResponse) {
+		t.Helper()
+
+		req := httptest.NewRequest(method, "/api/v1/admin/settings", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		srv.(*server).router.ServeHTTP(w, req)
+
+		var resp SettingsResponse
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+
+		return w.Code, resp
+	}
+
+	defaults := SettingsValues{
+		DispatchInterval: cfg.Dispatcher.Interval.String(),
+		TrackingInterval: cfg.Dispatcher.TrackingInterval.String(),
+		RateLimitBuffer:  cfg.GitHub.RateLimitBuffer,
+	}
+
+	code, resp := do(http.MethodGet, "")
+	if code != http.StatusOK || resp.Effective != defaults || resp.Defaults != defaults || len(resp.Overrides) != 0 {
+		t.Fatalf("Expected the configured defaults, got %d %+v", code, resp)
+	}
+
+	for _, body := range []string{
+		`{}`,
+		`{"dispatch_interval":"soon"}`,
+		`{"dispatch_interval":"100ms"}`,
+		`{"rate_limit_buffer":-1}`,
+		`{"reset":["poll_interval"]}`,
+		`{"tracking_interval":"10s","reset":["tracking_interval"]}`,
+	} {
+		if code, _ := do(http.MethodPut, body); code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", body, code)
+		}
+	}
+
+	code, resp = do(http.MethodPut, `{"dispatch_interval":"5s","rate_limit_buffer":250}`)
+	if code != http.StatusOK || resp.Effective.DispatchInterval != "5s" || resp.Effective.RateLimitBuffer != 250 ||
+		resp.Effective.TrackingInterval != defaults.TrackingInterval || len(resp.Overrides) != 2 ||
+		resp.Overrides[0].UpdatedBy != "testadmin" {
+		t.Fatalf("Expected the overrides to apply, got %d %+v", code, resp)
+	}
+
+	if len(applied) != 1 || applied[0].DispatchInterval != 5*time.Second || applied[0].RateLimitBuffer != 250 {
+		t.Errorf("Expected the new values to be applied once, got %+v", applied)
+	}
+
+	// Overrides survive a restart.
+	reloaded := settings.NewService(log, cfg, st)
+	if err := reloaded.Start(ctx); err != nil {
+		t.Fatalf("Failed to start settings: %v", err)
+	}
+
+	if values := reloaded.Values(); values.DispatchInterval != 5*time.Second || values.RateLimitBuffer != 250 {
+		t.Errorf("Expected persisted overrides after restart, got %+v", values)
+	}
+
+	code, resp = do(http.MethodPut, `{"reset":["dispatch_interval","rate_limit_buffer"]}`)
+	if code != http.StatusOK || resp.Effective != defaults || len(resp.Overrides) != 0 {
+		t.Fatalf("Expected the defaults back after a reset, got %d %+v", code, resp)
+	}
+
+	action := store.AuditActionSettingsUpdated
+
+	entries, _, err := st.ListAuditEntries(ctx, store.AuditQueryOpts{Action: &action})
+	if err != nil || len(entries) != 2 {
+		t.Errorf("Expected both updates to be audited, got %d (%v)", len(entries), err)
+	}
+}
+
+func TestHandleSearch(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{
+		{"id": "sync-hoodi", "name": "Sync Hoodi", "owner": "org", "repo": "repo", "workflow_id": "sync.yml"},
+	})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	now := time.Now()
+	if err := st.UpsertRunner(ctx, &store.Runner{
+		ID: 7, Name: "hoodi-runner-1", Status: store.RunnerStatusOnline, CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create runner: %v", err)
+	}
+
+	q := queue.NewService(log, cfg, st, testMetrics)
+
+	job, err := q.Enqueue(ctx, "test-group", "sync-hoodi", "alice", nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to enqueue job: %v", err)
+	}
+
+	if err := q.MarkTriggered(ctx, job.ID, 4242, ""); err != nil {
+		t.Fatalf("Failed to mark triggered: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, q, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+	s := srv.(*server)
+
+	search := func(user *store.User, query string) SearchResponse {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q="+query, nil)
+		req = req.WithContext(auth.ContextWithUser(req.Context(), user))
+
+		w := httptest.NewRecorder()
+		s.handleSearch(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var resp SearchResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		return resp
+	}
+
+	admin := &store.User{ID: "admin", Username: "admin", Role: store.RoleAdmin}
+
+	types := func(resp SearchResponse) []SearchResultType {
+		var got []SearchResultType
+		for _, result := range resp.Results {
+			got = append(got, result.Type)
+		}
+
+		return got
+	}
+
+	if got := types(search(admin, "HOODI")); !slices.Equal(got, []SearchResultType{SearchResultTemplate, SearchResultRunner}) {
+		t.Errorf("Expected a template and a runner match, got %v", got)
+	}
+
+	if resp := search(admin, "4242"); len(resp.Results) != 1 || resp.Results[0].ID != job.ID ||
+		resp.Results[0].Status != string(store.JobStatusTriggered) {
+		t.Errorf("Expected the job by run ID, got %+v", resp.Results)
+	}
+
+	if resp := search(admin, job.ID[:8]); len(resp.Results) != 1 || resp.Results[0].Type != SearchResultJob {
+		t.Errorf("Expected the job by ID prefix, got %+v", resp.Results)
+	}
+
+	// Groups a user cannot view hide their templates and jobs.
+	cfg.Groups.GitHub[0].Viewers = []string{"alice"}
+	bob := &store.User{ID: "bob", Username: "bob", Role: store.RoleReadOnly}
+
+	if got := types(search(bob, "hoodi")); !slices.Equal(got, []SearchResultType{SearchResultRunner}) {
+		t.Errorf("Expected only the runner for a user without access, got %v", got)
+	}
+
+	if resp := search(bob, "4242"); len(resp.Results) != 0 {
+		t.Errorf("Expected no jobs for a user without access, got %+v", resp.Results)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/search", nil)
+	w := httptest.NewRecorder()
+	s.handleSearch(w, req.WithContext(auth.ContextWithUser(req.Context(), admin)))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a query, got %d", w.Code)
+	}
+}
+
+func TestHandleGetQueueStats(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	// Two samples in one hour bucket three hours ago, one in the current hour
+	// and one outside the 7 day range.
+	bucketStart := time.Now().Add(-3 * time.Hour).Truncate(time.Hour)
+	samples := []*store.QueueStatSample{
+		{GroupID: "test-group", SampledAt: bucketStart.Add(time.Minute), Pending: 10, Running: 2, IdleRunners: 1},
+		{GroupID: "test-group", SampledAt: bucketStart.Add(2 * time.Minute), Pending: 20, Running: 2, IdleRunners: 0},
+		{GroupID: "test-group", SampledAt: time.Now().Add(-time.Minute), Pending: 5, Running: 1, IdleRunners: 3},
+		{GroupID: "test-group", SampledAt: time.Now().Add(-8 * 24 * time.Hour), Pending: 99},
+	}
+
+	if err := st.CreateQueueStatSamples(ctx, samples); err != nil {
+		t.Fatalf("Failed to create samples: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	do := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		srv.(*server).router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	w := do("/api/v1/groups/test-group/queue/stats?range=7d")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp QueueStatsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.BucketSeconds != 3600 || len(resp.Points) != 2 {
+		t.Fatalf("Expected 2 hourly points, got %d with %ds buckets: %+v", len(resp.Points), resp.BucketSeconds, resp.Points)
+	}
+
+	first := resp.Points[0]
+	if !first.Time.Equal(bucketStart) || first.Samples != 2 || first.Pending != 15 || first.MaxPending != 20 ||
+		first.Running != 2 || first.IdleRunners != 0.5 || first.MinIdleRunners != 0 {
+		t.Errorf("Unexpected first point: %+v", first)
+	}
+
+	if last := resp.Points[1]; last.Samples != 1 || last.Pending != 5 || last.MinIdleRunners != 3 {
+		t.Errorf("Unexpected last point: %+v", last)
+	}
+
+	if w := do("/api/v1/groups/test-group/queue/stats?range=1y"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid range, got %d", w.Code)
+	}
+
+	if w := do("/api/v1/groups/missing/queue/stats"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a missing group, got %d", w.Code)
+	}
+
+	deleted, err := st.DeleteQueueStatSamplesBefore(ctx, time.Now().Add(-7*24*time.Hour))
+	if err != nil || deleted != 1 {
+		t.Errorf("Expected 1 pruned sample, got %d (%v)", deleted, err)
+	}
+}
+
+func TestIPRateLimiterWhitelist(t *testing.T) {
+	cfg := config.RateLimitConfig{Whitelist: []string{"10.0.0.0/8", "192.0.2.7"}}
+
+	whitelist, err := cfg.WhitelistPrefixes()
+	if err != nil {
+		t.Fatalf("Failed to parse whitelist: %v", err)
+	}
+
+	limiter := NewIPRateLimiter(config.RateLimitTierConfig{RequestsPerMinute: 60, Burst: 2}, whitelist)
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	do := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	for _, addr := range []string{"10.1.2.3:4567", "192.0.2.7"} {
+		for i := 0; i < 5; i++ {
+			if code := do(addr); code != http.StatusNoContent {
+				t.Fatalf("Expected whitelisted %s to pass request %d, got %d", addr, i, code)
+			}
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if code := do("198.51.100.1"); code != http.StatusNoContent {
+			t.Fatalf("Expected request %d within burst to pass, got %d", i, code)
+		}
+	}
+
+	if code := do("198.51.100.1"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected request beyond burst to be limited, got %d", code)
+	}
+
+	if _, err := (config.RateLimitConfig{Whitelist: []string{"10.0.0.0/33"}}).WhitelistPrefixes(); err == nil {
+		t.Error("Expected an invalid CIDR to be rejected")
+	}
+}
+
+func TestRealIPMiddleware(t *testing.T) {
+	cfg := config.ServerConfig{TrustedProxies: []string{"10.0.0.0/8"}}
+
+	trusted, err := cfg.TrustedProxyPrefixes()
+	if err != nil {
+		t.Fatalf("Failed to parse trusted proxies: %v", err)
+	}
+
+	var got string
+
+	handler := realIPMiddleware(trusted)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
+	}))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"untrusted peer", "203.0.113.9:1234", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "203.0.113.9"},
+		{"trusted peer", "10.0.0.2:1234", map[string]string{"X-Real-IP": "198.51.100.1"}, "198.51.100.1"},
+		{"spoofed hop", "10.0.0.2:1234", map[string]string{"X-Forwarded-For": "192.0.2.66, 198.51.100.1, 10.0.0.3"}, "198.51.100.1"},
+		{"forwarded over real IP", "10.0.0.2:1234", map[string]string{"X-Real-IP": "192.0.2.66", "X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+		{"no header", "10.0.0.2:1234", nil, "10.0.0.2"},
+		{"ipv6 peer", "[2001:db8::1]:443", nil, "2001:db8::1"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tc.want {
+				t.Errorf("Expected RemoteAddr %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	// Without trusted proxies, forwarded headers are ignored and peers are
+	// keyed on their IP.
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.9:1234"
+	req.Header.Set("X-Real-IP", "198.51.100.1")
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+	realIPMiddleware(nil)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
+	})).ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != "203.0.113.9" {
+		t.Errorf("Expected the peer's IP without port, got %q", got)
+	}
+}
+
+func TestHandleChatOpsComment(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{{
+		"id": "testnet-spam", "name": "Testnet Spam", "owner": "ethpandaops", "repo": "dispatchoor",
+		"workflow_id": "spam.yml", "ref": "main", "inputs": map[string]any{"network": "hoodi", "debug": false},
+	}})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	cfg.GitHub.WebhookSecret = "webhook-secret"
+	cfg.ChatOps = config.ChatOpsConfig{
+		Enabled: true, Repos: []string{"ethpandaops/tests"}, Command: "/dispatch", UIURL: "https://dispatchoor.example/",
+	}
+	cfg.Auth.GitHub.UserRoleMapping = map[string]string{"Alice": "admin", "bob": "readonly", "dave": "admin"}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	dispatchClient := &stubGitHubClient{connected: true}
+	srv := NewServer(log, cfg, cfgPath, st, queue.NewService(log, cfg, st, testMetrics), &stubAuth{},
+		&stubGitHubClient{}, dispatchClient, testMetrics)
+
+	commentAs := func(repo, author string, authorID int64, authorType, body string) {
+		payload, err := json.Marshal(map[string]any{
+			"action":     "created",
+			"repository": map[string]any{"name": repo, "owner": map[string]any{"login": "ethpandaops"}},
+			"issue":      map[string]any{"number": 7},
+			"comment": map[string]any{
+				"body": body,
+				"user": map[string]any{"login": author, "id": authorID, "type": authorType},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to encode payload: %v", err)
+		}
+
+		mac := hmac.New(sha256.New, []byte("webhook-secret"))
+		mac.Write(payload)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", "issue_comment")
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+		w := httptest.NewRecorder()
+		srv.(*server).router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	comment := func(repo, author, authorType, body string) {
+		commentAs(repo, author, 100, authorType, body)
+	}
+
+	comment("tests", "alice", "User", "Thanks!\n/dispatch testnet-spam network=holesky debug=true note=\"two words\"")
+
+	if len(dispatchClient.comments) != 1 {
+		t.Fatalf("Expected 1 reply, got %d", len(dispatchClient.comments))
+	}
+
+	jobs, err := st.ListJobsByGroup(ctx, "test-group", store.JobStatusPending)
+	if err != nil {
+		t.Fatalf("Failed to list jobs: %v", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].CreatedBy != "alice" {
+		t.Fatalf("Expected one job created by alice, got %+v", jobs)
+	}
+
+	job := jobs[0]
+	if job.Inputs["network"].String() != "holesky" || job.Inputs["debug"] != input.Bool(true) ||
+		job.Inputs["note"].String() != "two words" {
+		t.Errorf("Unexpected job inputs %v", job.Inputs)
+	}
+
+	if reply := dispatchClient.comments[0]; !strings.Contains(reply, "https://dispatchoor.example/groups/test-group") ||
+		!strings.Contains(reply, job.ID) {
+		t.Errorf("Expected reply to link the job, got %q", reply)
+	}
+
+	comment("tests", "bob", "User", "/dispatch testnet-spam")
+
+	if reply := dispatchClient.comments[len(dispatchClient.comments)-1]; !strings.Contains(reply, "not allowed") {
+		t.Errorf("Expected a read-only author to be rejected, got %q", reply)
+	}
+
+	comment("tests", "alice", "User", "/dispatch missing-template")
+
+	if reply := dispatchClient.comments[len(dispatchClient.comments)-1]; !strings.Contains(reply, "not found") {
+		t.Errorf("Expected an unknown template to be reported, got %q", reply)
+	}
+
+	// GitHub logins' roles from team or org mappings may be stale, and mapped
+	// logins belong to the GitHub ID that logged in with them.
+	for _, user := range []*store.User{
+		{ID: "carol", Username: "carol", Role: store.RoleAdmin, GitHubID: "200"},
+		{ID: "dave", Username: "dave", Role: store.RoleReadOnly, GitHubID: "400"},
+	} {
+		user.AuthProvider = store.AuthProviderGitHub
+		user.CreatedAt = time.Now()
+		user.UpdatedAt = user.CreatedAt
+
+		if err := st.CreateUser(ctx, user); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		author   string
+		authorID int64
+		allowed  bool
+	}{
+		{"carol", 200, false},
+		{"dave", 500, false},
+		{"dave-renamed", 400, true},
+	} {
+		commentAs("tests", tc.author, tc.authorID, "User", "/dispatch testnet-spam")
+
+		reply := dispatchClient.comments[len(dispatchClient.comments)-1]
+		if allowed := !strings.Contains(reply, "not allowed"); allowed != tc.allowed {
+			t.Errorf("Expected %s (%d) allowed=%t, got reply %q", tc.author, tc.authorID, tc.allowed, reply)
+		}
+	}
+
+	replies := len(dispatchClient.comments)
+
+	comment("other", "alice", "User", "/dispatch testnet-spam")
+	comment("tests", "dispatchoor[bot]", "Bot", "/dispatch testnet-spam")
+	comment("tests", "alice", "User", "no command here, /dispatch mid-line is ignored")
+
+	if len(dispatchClient.comments) != replies {
+		t.Errorf("Expected other repos, bots and plain comments to be ignored, got %v", dispatchClient.comments[replies:])
+	}
+}
+
+func TestHandleIssueFormRequest(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{{
+		"id": "testnet-spam", "name": "Testnet Spam", "owner": "ethpandaops", "repo": "dispatchoor",
+		"workflow_id": "spam.yml", "ref": "main", "inputs": map[string]any{"network": "hoodi"},
+	}})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	cfg.GitHub.WebhookSecret = "webhook-secret"
+	cfg.ChatOps = config.ChatOpsConfig{
+		Enabled: true, Repos: []string{"ethpandaops/tests"}, Command: "/dispatch", IssueLabel: "dispatch-request",
+	}
+	cfg.Auth.GitHub.UserRoleMapping = map[string]string{"alice": "admin"}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	q := queue.NewService(log, cfg, st, testMetrics)
+	dispatchClient := &stubGitHubClient{connected: true}
+	srv := NewServer(log, cfg, cfgPath, st, q, &stubAuth{}, &stubGitHubClient{}, dispatchClient, testMetrics)
+
+	openIssue := func(number int, label, body string) {
+		payload, err := json.Marshal(map[string]any{
+			"action":     "opened",
+			"repository": map[string]any{"name": "tests", "owner": map[string]any{"login": "ethpandaops"}},
+			"issue": map[string]any{
+				"number": number,
+				"body":   body,
+				"labels": []map[string]any{{"name": label}},
+				"user":   map[string]any{"login": "alice", "id": 100, "type": "User"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to encode payload: %v", err)
+		}
+
+		mac := hmac.New(sha256.New, []byte("webhook-secret"))
+		mac.Write(payload)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", "issues")
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+		w := httptest.NewRecorder()
+		srv.(*server).router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	openIssue(3, "bug", "### Template\n\ntestnet-spam")
+
+	if len(dispatchClient.comments) != 0 {
+		t.Fatalf("Expected an issue without the label to be ignored, got %v", dispatchClient.comments)
+	}
+
+	openIssue(7, "dispatch-request", "### Template\n\ntestnet-spam\n\n### network\n\nholesky\n\n### note\n\n_No response_\n")
+
+	jobs, err := st.ListJobsByGroup(ctx, "test-group", store.JobStatusPending)
+	if err != nil {
+		t.Fatalf("Failed to list jobs: %v", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].CreatedBy != "alice" || jobs[0].SourceIssue != "ethpandaops/tests#7" {
+		t.Fatalf("Expected one job created by alice from issue 7, got %+v", jobs)
+	}
+
+	job := jobs[0]
+	if job.Inputs["network"].String() != "holesky" {
+		t.Errorf("Expected the network field to set the input, got %v", job.Inputs)
+	}
+
+	if _, ok := job.Inputs["note"]; ok {
+		t.Errorf("Expected an empty field to be skipped, got %v", job.Inputs)
+	}
+
+	if len(dispatchClient.comments) != 1 || !strings.Contains(dispatchClient.comments[0], job.ID) {
+		t.Fatalf("Expected a reply naming the job, got %v", dispatchClient.comments)
+	}
+
+	job.Status = store.JobStatusCompleted
+	srv.(*server).reportIssueFormJob(ctx, job)
+
+	if len(dispatchClient.comments) != 2 || !strings.Contains(dispatchClient.comments[1], "completed") {
+		t.Errorf("Expected the outcome to be reported, got %v", dispatchClient.comments)
+	}
+
+	if len(dispatchClient.closedIssues) != 1 || dispatchClient.closedIssues[0] != 7 {
+		t.Errorf("Expected issue 7 to be closed, got %v", dispatchClient.closedIssues)
+	}
+
+	openIssue(8, "dispatch-request", "### network\n\nholesky")
+
+	if reply := dispatchClient.comments[len(dispatchClient.comments)-1]; !strings.Contains(reply, "Template") {
+		t.Errorf("Expected a missing template field to be reported, got %q", reply)
+	}
+}
+
+func TestHandleDatabaseStats(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	for i := range 3 {
+		if err := st.CreateAuditEntry(ctx, &store.AuditEntry{
+			ID:         fmt.Sprintf("audit-%d", i),
+			Action:     store.AuditActionSettingsUpdated,
+			EntityType: store.AuditEntitySystem,
+			EntityID:   "settings",
+			Actor:      "testadmin",
+			CreatedAt:  time.Now(),
+		}); err != nil {
+			t.Fatalf("Failed to create audit entry: %v", err)
+		}
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/database", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	w := httptest.NewRecorder()
+	srv.(*server).router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var stats store.DatabaseStats
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if stats.Driver != "sqlite" || stats.SizeBytes <= 0 {
+		t.Fatalf("Expected a non-empty sqlite database, got %+v", stats)
+	}
+
+	rows := make(map[string]int64, len(stats.Tables))
+	for _, table := range stats.Tables {
+		rows[table.Table] = table.Rows
+	}
+
+	if got, ok := rows["audit_log"]; !ok || got != 3 {
+		t.Errorf("Expected 3 audit_log rows, got %d (listed: %v)", got, ok)
+	}
+
+	if got, ok := rows["jobs"]; !ok || got != 0 {
+		t.Errorf("Expected an empty jobs table, got %d (listed: %v)", got, ok)
+	}
+}
+
+func TestGroupDescriptionAndMetadata(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	cfg.Groups.GitHub[0].Description = "**Do not pause.** See the [runbook](https://wiki.example.com/x), " +
+		"[not this](javascript:alert(1)) <script>alert(1)</script>"
+	cfg.Groups.GitHub[0].Metadata = &config.GroupMetadata{
+		Team:        "platform",
+		ChatChannel: "#sync-tests",
+		Links:       []config.GroupLink{{Title: "Runbook", URL: "https://wiki.example.com/x"}},
+		Fields:      map[string]string{"oncall": "alice"},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected valid metadata, got %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/groups/test-group", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	w := httptest.NewRecorder()
+	srv.(*server).router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var group GroupResponse
+	if err := json.NewDecoder(w.Body).Decode(&group); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if !strings.Contains(group.DescriptionHTML, "<strong>Do not pause.</strong>") ||
+		!strings.Contains(group.DescriptionHTML, `href="https://wiki.example.com/x"`) {
+		t.Errorf("Expected rendered markdown, got %q", group.DescriptionHTML)
+	}
+
+	if strings.Contains(group.DescriptionHTML, "<script") || strings.Contains(group.DescriptionHTML, "javascript:") {
+		t.Errorf("Expected unsafe markup to be stripped, got %q", group.DescriptionHTML)
+	}
+
+	if group.Metadata == nil || group.Metadata.Team != "platform" || group.Metadata.ChatChannel != "#sync-tests" ||
+		len(group.Metadata.Links) != 1 || group.Metadata.Fields["oncall"] != "alice" {
+		t.Errorf("Expected the configured metadata, got %+v", group.Metadata)
+	}
+
+	cfg.Groups.GitHub[0].Metadata.Links[0].URL = "javascript:alert(1)"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a non-http link to be rejected")
+	}
+}
+
+func TestTemplateCloneAndBulkEdit(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{
+		{
+			"id":          "tmpl-1",
+			"name":        "Template 1",
+			"owner":       "org",
+			"repo":        "repo",
+			"workflow_id": "build.yml",
+			"ref":         "master",
+		},
+	})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, &stubQueue{}, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		srv.(*server).router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	w := do(http.MethodPost, "/api/v1/templates/tmpl-1/clone", `{"id":"tmpl-2","repo":"other"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var clone store.JobTemplate
+	if err := json.NewDecoder(w.Body).Decode(&clone); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if clone.SourceType != store.TemplateSourceAPI || clone.Repo != "other" || clone.Owner != "org" ||
+		clone.Name != "Template 1 (copy)" || clone.GroupID != "test-group" {
+		t.Errorf("Unexpected clone: %+v", clone)
+	}
+
+	if w := do(http.MethodPost, "/api/v1/templates/tmpl-1/clone", `{"id":"tmpl-2"}`); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a taken ID, got %d", w.Code)
+	}
+
+	w = do(http.MethodPost, "/api/v1/templates/bulk-edit", `{"template_ids":["tmpl-1","tmpl-2"],"ref":"main"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := do(http.MethodPost, "/api/v1/templates/bulk-edit", `{"template_ids":["tmpl-1","missing"],"ref":"x"}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown template, got %d", w.Code)
+	}
+
+	// A config sync keeps the override and the clone.
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	tmpl, err := st.GetJobTemplate(ctx, "tmpl-1")
+	if err != nil || tmpl == nil || tmpl.Ref != "main" || tmpl.Overrides == nil || tmpl.Overrides.Ref != "main" {
+		t.Fatalf("Expected the ref override to survive a sync, got %+v (%v)", tmpl, err)
+	}
+
+	cloned, err := st.GetJobTemplate(ctx, "tmpl-2")
+	if err != nil || cloned == nil || cloned.Ref != "main" || cloned.Overrides != nil {
+		t.Fatalf("Expected the clone to be edited directly and kept, got %+v (%v)", cloned, err)
+	}
+
+	if w := do(http.MethodPost, "/api/v1/templates/bulk-edit", `{"template_ids":["tmpl-1"],"reset":true}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if tmpl, _ := st.GetJobTemplate(ctx, "tmpl-1"); tmpl.Ref != "master" || tmpl.Overrides != nil {
+		t.Errorf("Expected the config ref after reset, got %q %+v", tmpl.Ref, tmpl.Overrides)
+	}
+
+	if w := do(http.MethodDelete, "/api/v1/templates/tmpl-1", ""); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 deleting a config template, got %d", w.Code)
+	}
+
+	if w := do(http.MethodDelete, "/api/v1/templates/tmpl-2", ""); w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204 deleting a clone, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestJobInputsOverridden(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	cfgPath := writeTestConfig(t, tmpDir, dbPath, []map[string]any{
+		{
+			"id":          "tmpl-1",
+			"name":        "Template 1",
+			"owner":       "org",
+			"repo":        "repo",
+			"workflow_id": "build.yml",
+			"ref":         "main",
+			"inputs":      map[string]any{"el-client": "geth", "network": "hoodi", "debug": false},
+		},
+	})
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	st := store.NewSQLiteStore(log, dbPath)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if err := SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	q := queue.NewService(log, cfg, st, testMetrics)
+
+	job, err := q.Enqueue(ctx, "test-group", "tmpl-1", "alice",
+		input.FromStrings(map[string]string{"el-client": "nethermind", "network": "hoodi", "debug": "false", "extra": "1"}), nil)
+	if err != nil {
+		t.Fatalf("Failed to enqueue job: %v", err)
+	}
+
+	srv := NewServer(log, cfg, cfgPath, st, q, &stubAuth{},
+		&stubGitHubClient{}, &stubGitHubClient{}, testMetrics)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+job.ID, nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	w := httptest.NewRecorder()
+	srv.(*server).router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var got store.Job
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode job: %v", err)
+	}
+
+	if len(got.InputsOverridden) != 2 {
+		t.Fatalf("Expected 2 overridden inputs, got %+v", got.InputsOverridden)
+	}
+
+	if o := got.InputsOverridden["el-client"]; o.Value.String() != "nethermind" || o.Default == nil || o.Default.String() != "geth" {
+		t.Errorf("Expected el-client nethermind over geth, got %+v", o)
+	}
+
+	if o, ok := got.InputsOverridden["extra"]; !ok || o.Default != nil {
+		t.Errorf("Expected extra without a default, got %+v", o)
+	}
 }
@@ -0,0 +1,526 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/auth"
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/queue"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// campaignUpdateDelay coalesces the campaign_update broadcasts of a burst of
+// job changes, such as a campaign being cancelled, into one.
+const campaignUpdateDelay = 250 * time.Millisecond
+
+// CampaignProgress counts a campaign's jobs by status.
+type CampaignProgress struct {
+	Total     int `json:"total" example:"12"`
+	Pending   int `json:"pending" example:"4"`
+	Paused    int `json:"paused" example:"1"`
+	Triggered int `json:"triggered" example:"1"`
+	Running   int `json:"running" example:"2"`
+	Completed int `json:"completed" example:"3"`
+	Failed    int `json:"failed" example:"1"`
+	Cancelled int `json:"cancelled" example:"1"`
+	// Finished is the number of completed, failed and cancelled jobs.
+	Finished int `json:"finished" example:"5"`
+}
+
+// CampaignResponse is a campaign with its progress and, for a single
+// campaign, its jobs.
+type CampaignResponse struct {
+	*store.Campaign
+	Progress CampaignProgress `json:"progress"`
+	Jobs     []*store.Job     `json:"jobs,omitempty"`
+}
+
+// CampaignJobRequest is a job to enqueue when a campaign is created.
+type CampaignJobRequest struct {
+	GroupID    string    `json:"group_id" example:"sync-tests"`
+	TemplateID string    `json:"template_id" example:"sync-hoodi"`
+	Inputs     input.Map `json:"inputs,omitempty"`
+}
+
+// CreateCampaignRequest is the request body for creating a campaign.
+type CreateCampaignRequest struct {
+	Name        string            `json:"name" example:"Fusaka sync tests"`
+	Description string            `json:"description,omitempty" example:"Sync every client pair on hoodi"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	// Jobs are enqueued in the campaign. More jobs can be added later with
+	// campaign_id on the add job endpoint.
+	Jobs []CampaignJobRequest `json:"jobs,omitempty"`
+}
+
+// CampaignActionResponse is the response for a batch action on a campaign.
+type CampaignActionResponse struct {
+	// Affected is the number of jobs the action was applied to.
+	Affected int `json:"affected" example:"4"`
+	// Errors lists the jobs the action failed for.
+	Errors   []string         `json:"errors,omitempty"`
+	Progress CampaignProgress `json:"progress"`
+}
+
+// campaignProgress counts the jobs of a campaign by status.
+func campaignProgress(jobs []*store.Job) CampaignProgress {
+	progress := CampaignProgress{Total: len(jobs)}
+
+	for _, job := range jobs {
+		switch job.Status {
+		case store.JobStatusPending:
+			progress.Pending++
+
+			if job.Paused {
+				progress.Paused++
+			}
+		case store.JobStatusTriggered:
+			progress.Triggered++
+		case store.JobStatusRunning:
+			progress.Running++
+		case store.JobStatusCompleted:
+			progress.Completed++
+		case store.JobStatusFailed:
+			progress.Failed++
+		case store.JobStatusCancelled:
+			progress.Cancelled++
+		}
+	}
+
+	progress.Finished = progress.Completed + progress.Failed + progress.Cancelled
+
+	return progress
+}
+
+// handleListCampaigns godoc
+//
+//	@Summary		List campaigns
+//	@Description	Returns all campaigns, newest first, with their progress
+//	@Tags			campaigns
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{array}		CampaignResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/campaigns [get]
+func (s *server) handleListCampaigns(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	campaigns, err := s.store.ListCampaigns(ctx)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list campaigns")
+		s.writeError(w, http.StatusInternalServerError, "Failed to list campaigns")
+
+		return
+	}
+
+	resp := make([]*CampaignResponse, 0, len(campaigns))
+
+	for _, campaign := range campaigns {
+		jobs, err := s.store.ListJobsByCampaign(ctx, campaign.ID)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to list campaign jobs")
+			s.writeError(w, http.StatusInternalServerError, "Failed to list campaign jobs")
+
+			return
+		}
+
+		resp = append(resp, &CampaignResponse{Campaign: campaign, Progress: campaignProgress(jobs)})
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleGetCampaign godoc
+//
+//	@Summary		Get campaign
+//	@Description	Returns a campaign with its progress and jobs
+//	@Tags			campaigns
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Campaign ID"
+//	@Success		200	{object}	CampaignResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/campaigns/{id} [get]
+func (s *server) handleGetCampaign(w http.ResponseWriter, r *http.Request) {
+	campaign, jobs, ok := s.loadCampaign(w, r)
+	if !ok {
+		return
+	}
+
+	if jobs == nil {
+		jobs = []*store.Job{}
+	}
+
+	s.writeJSON(w, http.StatusOK, &CampaignResponse{
+		Campaign: campaign,
+		Progress: campaignProgress(jobs),
+		Jobs:     jobs,
+	})
+}
+
+// handleCreateCampaign godoc
+//
+//	@Summary		Create campaign
+//	@Description	Creates a campaign and enqueues its jobs. If any job cannot be enqueued, the jobs enqueued so far are removed and no campaign is created (requires admin)
+//	@Tags			campaigns
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		CreateCampaignRequest	true	"Campaign"
+//	@Success		201		{object}	CampaignResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/campaigns [post]
+func (s *server) handleCreateCampaign(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req CreateCampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		s.writeError(w, http.StatusBadRequest, "Campaign name is required")
+
+		return
+	}
+
+	for i, job := range req.Jobs {
+		if job.GroupID == "" || job.TemplateID == "" {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Job %d requires group_id and template_id", i))
+
+			return
+		}
+
+		if msg := s.checkJobLimits(job.Inputs, nil); msg != "" {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Job %d: %s", i, msg))
+
+			return
+		}
+	}
+
+	// Campaigns often run one template many times; check each ref once.
+	checkedTemplates := make(map[string]bool, len(req.Jobs))
+
+	for i, job := range req.Jobs {
+		if checkedTemplates[job.TemplateID] {
+			continue
+		}
+
+		checkedTemplates[job.TemplateID] = true
+
+		msg, err := s.checkJobRef(ctx, job.TemplateID, "", "", "")
+		if err != nil {
+			s.log.WithError(err).Error("Failed to check job ref")
+			s.writeError(w, http.StatusInternalServerError, "Failed to check job ref")
+
+			return
+		}
+
+		if msg != "" {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Job %d: %s", i, msg))
+
+			return
+		}
+	}
+
+	actor := "anonymous"
+	if user := auth.UserFromContext(ctx); user != nil {
+		actor = user.Username
+	}
+
+	now := time.Now()
+	campaign := &store.Campaign{
+		ID:          uuid.New().String(),
+		Name:        req.Name,
+		Description: req.Description,
+		Metadata:    req.Metadata,
+		CreatedBy:   actor,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if campaign.Metadata == nil {
+		campaign.Metadata = map[string]string{}
+	}
+
+	// Jobs are enqueued before the campaign is stored so a failed request
+	// leaves nothing behind.
+	jobs := make([]*store.Job, 0, len(req.Jobs))
+
+	for i, jobReq := range req.Jobs {
+		job, err := s.queue.Enqueue(ctx, jobReq.GroupID, jobReq.TemplateID, actor, jobReq.Inputs,
+			&queue.EnqueueOptions{CampaignID: campaign.ID})
+		if err != nil {
+			s.removeJobs(ctx, jobs)
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Job %d: %v", i, err))
+
+			return
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	if err := s.store.CreateCampaign(ctx, campaign); err != nil {
+		s.log.WithError(err).Error("Failed to create campaign")
+		s.removeJobs(ctx, jobs)
+		s.writeError(w, http.StatusInternalServerError, "Failed to create campaign")
+
+		return
+	}
+
+	s.auditCampaign(r, campaign, store.AuditActionCampaignCreated,
+		fmt.Sprintf("Created campaign %q with %d jobs", campaign.Name, len(jobs)))
+
+	resp := &CampaignResponse{Campaign: campaign, Progress: campaignProgress(jobs), Jobs: jobs}
+	s.hub.BroadcastCampaignUpdate(&CampaignResponse{Campaign: campaign, Progress: resp.Progress})
+
+	s.writeJSON(w, http.StatusCreated, resp)
+}
+
+// removeJobs removes the jobs enqueued by a failed campaign creation.
+func (s *server) removeJobs(ctx context.Context, jobs []*store.Job) {
+	for _, job := range jobs {
+		if err := s.queue.Remove(ctx, job.ID); err != nil {
+			s.log.WithError(err).WithField("job_id", job.ID).Warn("Failed to remove job of failed campaign")
+		}
+	}
+}
+
+// handlePauseCampaign godoc
+//
+//	@Summary		Pause campaign
+//	@Description	Pauses every pending job of a campaign (requires admin)
+//	@Tags			campaigns
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Campaign ID"
+//	@Success		200	{object}	CampaignActionResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/campaigns/{id}/pause [post]
+func (s *server) handlePauseCampaign(w http.ResponseWriter, r *http.Request) {
+	s.applyCampaignAction(w, r, store.AuditActionCampaignPaused, func(ctx context.Context, job *store.Job) (bool, error) {
+		if job.Status != store.JobStatusPending || job.Paused {
+			return false, nil
+		}
+
+		_, err := s.queue.Pause(ctx, job.ID)
+
+		return err == nil, err
+	})
+}
+
+// handleUnpauseCampaign godoc
+//
+//	@Summary		Unpause campaign
+//	@Description	Resumes every paused job of a campaign (requires admin)
+//	@Tags			campaigns
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Campaign ID"
+//	@Success		200	{object}	CampaignActionResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/campaigns/{id}/unpause [post]
+func (s *server) handleUnpauseCampaign(w http.ResponseWriter, r *http.Request) {
+	s.applyCampaignAction(w, r, store.AuditActionCampaignUnpaused, func(ctx context.Context, job *store.Job) (bool, error) {
+		if job.Status != store.JobStatusPending || !job.Paused {
+			return false, nil
+		}
+
+		_, err := s.queue.Unpause(ctx, job.ID)
+
+		return err == nil, err
+	})
+}
+
+// handleCancelCampaign godoc
+//
+//	@Summary		Cancel campaign
+//	@Description	Cancels every unfinished job of a campaign. Workflow runs of triggered and running jobs are cancelled on GitHub (requires admin)
+//	@Tags			campaigns
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Campaign ID"
+//	@Success		200	{object}	CampaignActionResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/campaigns/{id}/cancel [post]
+func (s *server) handleCancelCampaign(w http.ResponseWriter, r *http.Request) {
+	s.applyCampaignAction(w, r, store.AuditActionCampaignCancelled, func(ctx context.Context, job *store.Job) (bool, error) {
+		switch job.Status {
+		case store.JobStatusPending:
+		case store.JobStatusTriggered, store.JobStatusRunning:
+			if status, msg := s.cancelWorkflowRun(ctx, job); status != 0 {
+				return false, errors.New(msg)
+			}
+		default:
+			return false, nil
+		}
+
+		if err := s.queue.MarkCancelled(ctx, job.ID); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	})
+}
+
+// applyCampaignAction applies an action to each job of a campaign and responds
+// with the number of jobs it was applied to. The action reports false for jobs
+// it does not apply to.
+func (s *server) applyCampaignAction(
+	w http.ResponseWriter, r *http.Request, action store.AuditAction,
+	apply func(ctx context.Context, job *store.Job) (bool, error),
+) {
+	ctx := r.Context()
+
+	campaign, jobs, ok := s.loadCampaign(w, r)
+	if !ok {
+		return
+	}
+
+	resp := &CampaignActionResponse{}
+
+	for _, job := range jobs {
+		applied, err := apply(ctx, job)
+		if err != nil {
+			s.log.WithError(err).WithField("job_id", job.ID).Warn("Failed to apply campaign action to job")
+			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %v", job.ID, err))
+
+			continue
+		}
+
+		if applied {
+			resp.Affected++
+		}
+	}
+
+	jobs, err := s.store.ListJobsByCampaign(ctx, campaign.ID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list campaign jobs")
+		s.writeError(w, http.StatusInternalServerError, "Failed to list campaign jobs")
+
+		return
+	}
+
+	resp.Progress = campaignProgress(jobs)
+
+	s.auditCampaign(r, campaign, action,
+		fmt.Sprintf("Applied to %d jobs (%d errors)", resp.Affected, len(resp.Errors)))
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// loadCampaign loads the campaign named by the id URL parameter and its jobs,
+// writing the error response when it cannot.
+func (s *server) loadCampaign(w http.ResponseWriter, r *http.Request) (*store.Campaign, []*store.Job, bool) {
+	ctx := r.Context()
+	id := chi.URLParam(r, "id")
+
+	campaign, err := s.store.GetCampaign(ctx, id)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get campaign")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get campaign")
+
+		return nil, nil, false
+	}
+
+	if campaign == nil {
+		s.writeError(w, http.StatusNotFound, "Campaign not found")
+
+		return nil, nil, false
+	}
+
+	jobs, err := s.store.ListJobsByCampaign(ctx, id)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list campaign jobs")
+		s.writeError(w, http.StatusInternalServerError, "Failed to list campaign jobs")
+
+		return nil, nil, false
+	}
+
+	return campaign, jobs, true
+}
+
+// auditCampaign records who created or acted on a campaign.
+func (s *server) auditCampaign(r *http.Request, campaign *store.Campaign, action store.AuditAction, details string) {
+	actor := "anonymous"
+	if user := auth.UserFromContext(r.Context()); user != nil {
+		actor = user.Username
+	}
+
+	if err := s.store.CreateAuditEntry(r.Context(), &store.AuditEntry{
+		ID:         uuid.New().String(),
+		Action:     action,
+		EntityType: store.AuditEntityCampaign,
+		EntityID:   campaign.ID,
+		Actor:      actor,
+		Details:    details,
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		s.log.WithError(err).WithField("campaign", campaign.ID).Warn("Failed to create audit entry for campaign")
+	}
+}
+
+// scheduleCampaignUpdate broadcasts a campaign's progress after
+// campaignUpdateDelay, unless a broadcast is already scheduled.
+func (s *server) scheduleCampaignUpdate(campaignID string) {
+	s.campaignUpdatesMu.Lock()
+	defer s.campaignUpdatesMu.Unlock()
+
+	if _, ok := s.campaignUpdates[campaignID]; ok {
+		return
+	}
+
+	s.campaignUpdates[campaignID] = time.AfterFunc(campaignUpdateDelay, func() {
+		s.campaignUpdatesMu.Lock()
+		delete(s.campaignUpdates, campaignID)
+		s.campaignUpdatesMu.Unlock()
+
+		s.broadcastCampaignUpdate(context.Background(), campaignID)
+	})
+}
+
+// broadcastCampaignUpdate sends a campaign's current progress to all clients.
+func (s *server) broadcastCampaignUpdate(ctx context.Context, campaignID string) {
+	campaign, err := s.store.GetCampaign(ctx, campaignID)
+	if err != nil || campaign == nil {
+		if err != nil {
+			s.log.WithError(err).WithField("campaign", campaignID).Warn("Failed to get campaign for update")
+		}
+
+		return
+	}
+
+	jobs, err := s.store.ListJobsByCampaign(ctx, campaignID)
+	if err != nil {
+		s.log.WithError(err).WithField("campaign", campaignID).Warn("Failed to list campaign jobs for update")
+
+		return
+	}
+
+	s.hub.BroadcastCampaignUpdate(&CampaignResponse{Campaign: campaign, Progress: campaignProgress(jobs)})
+}
@@ -0,0 +1,278 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/queue"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/ethpandaops/dispatchoor/pkg/webhook"
+	"github.com/sirupsen/logrus"
+)
+
+// chatOpsReplyTimeout bounds posting the reply to a chatops command.
+const chatOpsReplyTimeout = 10 * time.Second
+
+// errChatOpsUsage is returned for commands without a template ID.
+var errChatOpsUsage = errors.New("expected a template ID followed by key=value inputs")
+
+// chatOpsCommand is a parsed chatops command.
+type chatOpsCommand struct {
+	TemplateID string
+	Inputs     input.Map
+}
+
+// handleChatOpsComment runs the chatops command in a new issue or pull request
+// comment, if any, and replies on the same thread with the outcome.
+func (s *server) handleChatOpsComment(ctx context.Context, event *webhook.IssueCommentEvent) {
+	s.cfgMu.RLock()
+	cfg := s.cfg.ChatOps
+	s.cfgMu.RUnlock()
+
+	// Bots are ignored so replies, ours included, never trigger commands.
+	if event.AuthorType == "Bot" {
+		return
+	}
+
+	repo := event.Owner + "/" + event.Repo
+	if !slices.ContainsFunc(cfg.Repos, func(r string) bool { return strings.EqualFold(r, repo) }) {
+		return
+	}
+
+	cmd, found, err := parseChatOpsCommand(event.Body, cfg.Command)
+	if !found {
+		return
+	}
+
+	log := s.log.WithFields(logrus.Fields{
+		"repo":   repo,
+		"number": event.Number,
+		"author": event.Author,
+	})
+
+	var reply string
+
+	if err != nil {
+		reply = fmt.Sprintf("@%s Usage: `%s <template-id> [key=value ...]`: %s.", event.Author, cfg.Command, err)
+	} else {
+		reply = s.runChatOpsCommand(ctx, log, event, cmd, cfg.UIURL, nil)
+	}
+
+	s.postChatOpsReply(ctx, log, event.Owner, event.Repo, event.Number, reply)
+}
+
+// postChatOpsReply comments reply on an issue or pull request. The reply is
+// posted even if ctx, the delivery's request, is cancelled.
+func (s *server) postChatOpsReply(ctx context.Context, log logrus.FieldLogger, owner, repo string, number int, reply string) {
+	if s.dispatchClient == nil {
+		log.Warn("No GitHub client to reply to chatops command")
+
+		return
+	}
+
+	replyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), chatOpsReplyTimeout)
+	defer cancel()
+
+	if err := s.dispatchClient.CreateIssueComment(replyCtx, owner, repo, number, reply); err != nil {
+		log.WithError(err).Warn("Failed to reply to chatops command")
+	}
+}
+
+// runChatOpsCommand authorizes the author and enqueues the job with opts,
+// which may be nil, returning the reply to post.
+func (s *server) runChatOpsCommand(
+	ctx context.Context,
+	log logrus.FieldLogger,
+	event *webhook.IssueCommentEvent,
+	cmd *chatOpsCommand,
+	uiURL string,
+	opts *queue.EnqueueOptions,
+) string {
+	user, err := s.chatOpsUser(ctx, event)
+	if err != nil {
+		log.WithError(err).Error("Failed to resolve chatops author")
+
+		return fmt.Sprintf("@%s Failed to check your permissions, please try again.", event.Author)
+	}
+
+	if user == nil || user.Role != store.RoleAdmin {
+		log.Warn("Rejected chatops command from unauthorized author")
+
+		return fmt.Sprintf("@%s You are not allowed to dispatch jobs.", event.Author)
+	}
+
+	template, err := s.store.GetJobTemplate(ctx, cmd.TemplateID)
+	if err != nil {
+		log.WithError(err).Error("Failed to get job template")
+
+		return fmt.Sprintf("@%s Failed to look up template `%s`, please try again.", event.Author, cmd.TemplateID)
+	}
+
+	if template == nil {
+		return fmt.Sprintf("@%s Template `%s` not found.", event.Author, cmd.TemplateID)
+	}
+
+	if msg := s.checkJobLimits(cmd.Inputs, nil); msg != "" {
+		return fmt.Sprintf("@%s %s.", event.Author, msg)
+	}
+
+	job, err := s.queue.Enqueue(ctx, template.GroupID, template.ID, user.Username, cmd.Inputs, opts)
+	if err != nil {
+		log.WithError(err).Warn("Failed to enqueue chatops job")
+
+		return fmt.Sprintf("@%s Could not enqueue `%s`: %s.", event.Author, template.ID, err)
+	}
+
+	log.WithFields(logrus.Fields{
+		"job_id":      job.ID,
+		"template_id": template.ID,
+	}).Info("Enqueued job from chatops command")
+
+	return fmt.Sprintf("@%s Enqueued **%s** as job %s in group `%s`.",
+		event.Author, template.Name, chatOpsJobRef(uiURL, job), template.GroupID)
+}
+
+// chatOpsJobRef formats a job for a reply, linking its group in the UI when
+// uiURL is set.
+func chatOpsJobRef(uiURL string, job *store.Job) string {
+	if uiURL == "" {
+		return fmt.Sprintf("`%s`", job.ID)
+	}
+
+	return fmt.Sprintf("[`%s`](%s/groups/%s)", job.ID, strings.TrimSuffix(uiURL, "/"), url.PathEscape(job.GroupID))
+}
+
+// chatOpsUser resolves the author of a comment: a basic auth user linked to
+// their GitHub ID, then auth.github.user_role_mapping. Roles that GitHub
+// logins took from team or org mappings are not used, as membership may have
+// changed since. Logins can be renamed and reclaimed, so an author with an
+// account is matched by the login recorded for their GitHub ID, and a login
+// recorded for another GitHub ID is refused. It returns nil if the author is
+// not authorized.
+func (s *server) chatOpsUser(ctx context.Context, event *webhook.IssueCommentEvent) (*store.User, error) {
+	githubID := strconv.FormatInt(event.AuthorID, 10)
+
+	linked, err := s.store.GetUserByGitHubID(ctx, githubID)
+	if err != nil {
+		return nil, fmt.Errorf("getting user by github id: %w", err)
+	}
+
+	if linked != nil && linked.AuthProvider == store.AuthProviderBasic {
+		return linked, nil
+	}
+
+	login := event.Author
+
+	if linked != nil {
+		login = linked.Username
+	} else {
+		existing, err := s.store.GetUserByUsername(ctx, login)
+		if err != nil {
+			return nil, fmt.Errorf("getting user by username: %w", err)
+		}
+
+		if existing != nil && existing.GitHubID != "" && existing.GitHubID != githubID {
+			return nil, nil
+		}
+	}
+
+	s.cfgMu.RLock()
+	mapping := s.cfg.Auth.GitHub.UserRoleMapping
+	s.cfgMu.RUnlock()
+
+	for mapped, role := range mapping {
+		if strings.EqualFold(mapped, login) {
+			return &store.User{Username: login, Role: store.Role(role)}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// parseChatOpsCommand finds the first line of body that starts with command
+// and parses the rest of it as a template ID followed by key=value inputs.
+// Values may be double-quoted to include spaces. found is false when no line
+// holds the command.
+func parseChatOpsCommand(body, command string) (cmd *chatOpsCommand, found bool, err error) {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+
+		rest, ok := strings.CutPrefix(line, command)
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+
+		fields, err := splitChatOpsFields(rest)
+		if err != nil {
+			return nil, true, err
+		}
+
+		if len(fields) == 0 || strings.Contains(fields[0], "=") {
+			return nil, true, errChatOpsUsage
+		}
+
+		cmd := &chatOpsCommand{TemplateID: fields[0]}
+
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok || key == "" {
+				return nil, true, fmt.Errorf("input %q is not key=value", field)
+			}
+
+			if cmd.Inputs == nil {
+				cmd.Inputs = make(input.Map, len(fields)-1)
+			}
+
+			cmd.Inputs[key] = input.String(value)
+		}
+
+		return cmd, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// splitChatOpsFields splits s on whitespace, keeping double-quoted runs
+// together without their quotes.
+func splitChatOpsFields(s string) ([]string, error) {
+	var (
+		fields  []string
+		current strings.Builder
+		quoted  bool
+		started bool
+	)
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case (r == ' ' || r == '\t') && !quoted:
+			if started {
+				fields = append(fields, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+
+	if started {
+		fields = append(fields, current.String())
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"cmp"
+	"net/http"
+	"sort"
+
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+)
+
+// JobComparison is a structured diff of two jobs of the same template, from
+// job a to job b.
+type JobComparison struct {
+	A          *store.Job `json:"a"`
+	B          *store.Job `json:"b"`
+	TemplateID string     `json:"template_id" example:"sync-test-hoodi-geth-prysm"`
+	// Inputs lists the inputs whose values differ, sorted by key.
+	Inputs []InputChange `json:"inputs"`
+	// Ref is the ref each job was dispatched with, the template's unless overridden.
+	Ref          FieldChange `json:"ref"`
+	ResolvedSHA  FieldChange `json:"resolved_sha"`
+	Runner       FieldChange `json:"runner"`
+	Conclusion   FieldChange `json:"conclusion"`
+	ErrorMessage FieldChange `json:"error_message"`
+	// Duration is the time from trigger to completion of each job.
+	Duration DurationChange `json:"duration"`
+}
+
+// FieldChange is a value of both compared jobs.
+type FieldChange struct {
+	A       string `json:"a" example:"main"`
+	B       string `json:"b" example:"feature"`
+	Changed bool   `json:"changed" example:"true"`
+}
+
+// InputChange is an input whose value differs between the compared jobs.
+// A side is null when the input is not set on that job.
+type InputChange struct {
+	Key string       `json:"key" example:"el-client"`
+	A   *input.Value `json:"a" swaggertype:"string" example:"geth"`
+	B   *input.Value `json:"b" swaggertype:"string" example:"nethermind"`
+}
+
+// DurationChange compares the run durations of two jobs. Durations are null
+// for jobs that were never triggered or have not finished.
+type DurationChange struct {
+	ASeconds *int64 `json:"a_seconds" example:"3600"`
+	BSeconds *int64 `json:"b_seconds" example:"5400"`
+	// DeltaSeconds is b minus a, set when both durations are known.
+	DeltaSeconds *int64 `json:"delta_seconds,omitempty" example:"1800"`
+}
+
+// handleCompareJobs godoc
+//
+//	@Summary		Compare jobs
+//	@Description	Returns a structured diff of the inputs, ref, resolved commit, runner, conclusion and duration of two jobs of the same template, e.g. a failed job against the last successful one
+//	@Tags			jobs
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			a	query		string	true	"ID of the job to compare from"
+//	@Param			b	query		string	true	"ID of the job to compare to"
+//	@Success		200	{object}	JobComparison
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/jobs/compare [get]
+func (s *server) handleCompareJobs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	aID, bID := r.URL.Query().Get("a"), r.URL.Query().Get("b")
+
+	if aID == "" || bID == "" {
+		s.writeError(w, http.StatusBadRequest, "Query parameters a and b are required")
+
+		return
+	}
+
+	jobs := make([]*store.Job, 0, 2)
+
+	for _, id := range []string{aID, bID} {
+		job, err := s.queue.GetJob(ctx, id)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to get job")
+			s.writeError(w, http.StatusInternalServerError, "Failed to get job")
+
+			return
+		}
+
+		if job == nil {
+			s.writeError(w, http.StatusNotFound, "Job not found: "+id)
+
+			return
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	a, b := jobs[0], jobs[1]
+
+	if a.TemplateID == "" || a.TemplateID != b.TemplateID {
+		s.writeError(w, http.StatusBadRequest, "Jobs must belong to the same template")
+
+		return
+	}
+
+	template, err := s.store.GetJobTemplate(ctx, a.TemplateID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get job template")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get job template")
+
+		return
+	}
+
+	var templateRef, canaryRef string
+	if template != nil {
+		templateRef = template.Ref
+		canaryRef = cmp.Or(template.CanaryRef, template.Ref)
+	}
+
+	jobRef := func(job *store.Job) string {
+		if job.Ref != nil {
+			return *job.Ref
+		}
+
+		if job.Variant == store.JobVariantCanary {
+			return canaryRef
+		}
+
+		return templateRef
+	}
+
+	s.writeJSON(w, http.StatusOK, &JobComparison{
+		A:            a,
+		B:            b,
+		TemplateID:   a.TemplateID,
+		Inputs:       diffInputs(a.Inputs, b.Inputs),
+		Ref:          fieldChange(jobRef(a), jobRef(b)),
+		ResolvedSHA:  fieldChange(a.ResolvedSHA, b.ResolvedSHA),
+		Runner:       fieldChange(a.RunnerName, b.RunnerName),
+		Conclusion:   fieldChange(string(a.Status), string(b.Status)),
+		ErrorMessage: fieldChange(a.ErrorMessage, b.ErrorMessage),
+		Duration:     durationChange(a, b),
+	})
+}
+
+// fieldChange pairs the values of a field of two jobs.
+func fieldChange(a, b string) FieldChange {
+	return FieldChange{A: a, B: b, Changed: a != b}
+}
+
+// diffInputs lists the inputs set to different values, or set on one side
+// only, sorted by key.
+func diffInputs(a, b input.Map) []InputChange {
+	keys := make(map[string]bool, len(a)+len(b))
+	for key := range a {
+		keys[key] = true
+	}
+
+	for key := range b {
+		keys[key] = true
+	}
+
+	changes := make([]InputChange, 0)
+
+	for key := range keys {
+		aValue, aOK := a[key]
+		bValue, bOK := b[key]
+
+		if aOK == bOK && aValue == bValue {
+			continue
+		}
+
+		change := InputChange{Key: key}
+		if aOK {
+			change.A = &aValue
+		}
+
+		if bOK {
+			change.B = &bValue
+		}
+
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+
+	return changes
+}
+
+// durationChange compares the trigger-to-completion durations of two jobs.
+func durationChange(a, b *store.Job) DurationChange {
+	change := DurationChange{ASeconds: jobDurationSeconds(a), BSeconds: jobDurationSeconds(b)}
+
+	if change.ASeconds != nil && change.BSeconds != nil {
+		delta := *change.BSeconds - *change.ASeconds
+		change.DeltaSeconds = &delta
+	}
+
+	return change
+}
+
+// jobDurationSeconds returns how long a finished job ran after being
+// triggered, or nil if it was not triggered or has not finished.
+func jobDurationSeconds(job *store.Job) *int64 {
+	if job.TriggeredAt == nil || job.CompletedAt == nil {
+		return nil
+	}
+
+	seconds := int64(job.CompletedAt.Sub(*job.TriggeredAt).Seconds())
+
+	return &seconds
+}
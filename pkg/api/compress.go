@@ -0,0 +1,248 @@
+package api
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// compressibleTypes are the content types worth compressing. Images and
+// archives, such as run logs, are already compressed.
+var compressibleTypes = map[string]bool{
+	"application/json":       true,
+	"application/javascript": true,
+	"application/x-ndjson":   true,
+	"application/xml":        true,
+	"image/svg+xml":          true,
+	"text/css":               true,
+	"text/csv":               true,
+	"text/html":              true,
+	"text/javascript":        true,
+	"text/plain":             true,
+	"text/yaml":              true,
+}
+
+// compressMiddleware gzips responses for clients that accept it once they
+// reach minSize bytes. Shorter responses, responses that already carry a
+// Content-Encoding and content types not in compressibleTypes are sent
+// as-is. WebSocket upgrades are left alone; they negotiate
+// permessage-deflate instead.
+func compressMiddleware(level, minSize int) func(http.Handler) http.Handler {
+	pool := sync.Pool{New: func() any {
+		// The level is validated when the config is loaded.
+		gz, _ := gzip.NewWriterLevel(io.Discard, level)
+
+		return gz
+	}}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, pool: &pool, minSize: minSize}
+			defer cw.close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if name != "gzip" && name != "*" {
+			continue
+		}
+
+		q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !ok {
+			return true
+		}
+
+		weight, err := strconv.ParseFloat(q, 64)
+
+		return err == nil && weight > 0
+	}
+
+	return false
+}
+
+// compressWriter buffers a response until it is known to reach minSize, then
+// sends it gzipped if its headers allow, or as-is otherwise.
+type compressWriter struct {
+	http.ResponseWriter
+
+	pool    *sync.Pool
+	minSize int
+
+	status int
+	buf    []byte
+	// started is set once the headers have been sent.
+	started bool
+	gz      *gzip.Writer
+}
+
+// WriteHeader records the status; it is sent with the first flushed bytes.
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.started || cw.status != 0 {
+		return
+	}
+
+	// Informational responses go out straight away.
+	if status < http.StatusOK {
+		cw.ResponseWriter.WriteHeader(status)
+
+		return
+	}
+
+	cw.status = status
+
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		_ = cw.start(false)
+	}
+}
+
+// Write buffers p until minSize is reached.
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.started {
+		if cw.gz != nil {
+			return cw.gz.Write(p)
+		}
+
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+
+	if len(cw.buf) >= cw.minSize {
+		if err := cw.start(cw.compressible()); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush sends what was buffered, compressed if the response qualifies, so
+// streamed responses are never held back.
+func (cw *compressWriter) Flush() {
+	if !cw.started {
+		if err := cw.start(cw.compressible()); err != nil {
+			return
+		}
+	}
+
+	if cw.gz != nil {
+		_ = cw.gz.Flush()
+	}
+
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets handlers take over the connection, bypassing compression.
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := cw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("http.Hijacker not supported")
+	}
+
+	return h.Hijack()
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// compressible reports whether the response headers allow compressing it. A
+// missing content type is sniffed from the buffered bytes, as net/http would.
+func (cw *compressWriter) compressible() bool {
+	header := cw.Header()
+
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(cw.buf)
+		header.Set("Content-Type", contentType)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+
+	return err == nil && compressibleTypes[mediaType]
+}
+
+// start sends the headers and the buffered bytes, through a gzip writer when
+// compress is set.
+func (cw *compressWriter) start(compress bool) error {
+	cw.started = true
+
+	if compress {
+		header := cw.Header()
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+
+		cw.gz, _ = cw.pool.Get().(*gzip.Writer)
+		cw.gz.Reset(cw.ResponseWriter)
+	}
+
+	if cw.status != 0 {
+		cw.ResponseWriter.WriteHeader(cw.status)
+	}
+
+	if len(cw.buf) == 0 {
+		return nil
+	}
+
+	buf := cw.buf
+	cw.buf = nil
+
+	var err error
+	if cw.gz != nil {
+		_, err = cw.gz.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+
+	return err
+}
+
+// close sends a response that stayed below minSize uncompressed and ends the
+// gzip stream of a compressed one.
+func (cw *compressWriter) close() {
+	if !cw.started {
+		_ = cw.start(false)
+
+		return
+	}
+
+	if cw.gz != nil {
+		_ = cw.gz.Close()
+		cw.pool.Put(cw.gz)
+		cw.gz = nil
+	}
+}
@@ -0,0 +1,526 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/auth"
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/schedule"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ConfigSyncItem is a group or template affected by a config sync.
+type ConfigSyncItem struct {
+	GroupID    string `json:"group_id" example:"sync-tests"`
+	TemplateID string `json:"template_id,omitempty" example:"sync-hoodi"`
+	// Fields lists the changed fields of an updated group or template.
+	Fields []string `json:"fields,omitempty" example:"ref,default_inputs"`
+}
+
+// ConfigSyncDiff is what syncing a configuration would change in the database.
+type ConfigSyncDiff struct {
+	GroupsCreated []ConfigSyncItem `json:"groups_created"`
+	GroupsUpdated []ConfigSyncItem `json:"groups_updated"`
+	// GroupsRemoved are in the running config but not in the new one. Sync
+	// keeps them in the database; archive them to stop dispatching them.
+	GroupsRemoved    []ConfigSyncItem `json:"groups_removed"`
+	TemplatesCreated []ConfigSyncItem `json:"templates_created"`
+	TemplatesUpdated []ConfigSyncItem `json:"templates_updated"`
+	// TemplatesDeleted are removed from the config and have no jobs, so they
+	// are deleted.
+	TemplatesDeleted []ConfigSyncItem `json:"templates_deleted"`
+	// TemplatesRetired are removed from the config but keep their job history,
+	// so they are marked as not in config.
+	TemplatesRetired []ConfigSyncItem `json:"templates_retired"`
+}
+
+// Empty reports whether the sync would change nothing.
+func (d *ConfigSyncDiff) Empty() bool {
+	return len(d.GroupsCreated) == 0 && len(d.GroupsUpdated) == 0 && len(d.GroupsRemoved) == 0 &&
+		len(d.TemplatesCreated) == 0 && len(d.TemplatesUpdated) == 0 &&
+		len(d.TemplatesDeleted) == 0 && len(d.TemplatesRetired) == 0
+}
+
+// StagedConfigSync is a config reload waiting for admin approval.
+type StagedConfigSync struct {
+	ID       string          `json:"id" example:"3f1c2b8e-7a4d-4e6b-9c0a-5d8e2f1b7c3a"`
+	Diff     *ConfigSyncDiff `json:"diff"`
+	StagedBy string          `json:"staged_by" example:"alice"`
+	StagedAt time.Time       `json:"staged_at"`
+
+	cfg *config.Config
+}
+
+// groupFromConfig builds the stored form of a configured group.
+func groupFromConfig(groupCfg *config.Group, now time.Time) *store.Group {
+	return &store.Group{
+		ID:               groupCfg.ID,
+		Name:             groupCfg.Name,
+		Description:      groupCfg.Description,
+		RunnerLabels:     groupCfg.RunnerLabels,
+		Enabled:          true,
+		SchedulingPolicy: groupCfg.SchedulingPolicy,
+		MaxPriority:      groupCfg.MaxPriority,
+		MaxRequeueLimit:  groupCfg.MaxRequeueLimit,
+		MinOnlineRunners: groupCfg.MinOnlineRunners,
+		Metadata:         groupMetadataFromConfig(groupCfg.Metadata),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+}
+
+// groupMetadataFromConfig builds the stored form of a group's metadata.
+func groupMetadataFromConfig(metadataCfg *config.GroupMetadata) *store.GroupMetadata {
+	if metadataCfg == nil {
+		return nil
+	}
+
+	metadata := &store.GroupMetadata{
+		Team:        metadataCfg.Team,
+		ChatChannel: metadataCfg.ChatChannel,
+		Fields:      metadataCfg.Fields,
+	}
+
+	for _, link := range metadataCfg.Links {
+		metadata.Links = append(metadata.Links, store.GroupLink{Title: link.Title, URL: link.URL})
+	}
+
+	return metadata
+}
+
+// templateFromConfig builds the stored form of a configured template.
+func templateFromConfig(groupID string, tmplCfg *config.WorkflowDispatchTemplate, now time.Time) *store.JobTemplate {
+	template := &store.JobTemplate{
+		ID:                  tmplCfg.ID,
+		GroupID:             groupID,
+		Name:                tmplCfg.Name,
+		Owner:               tmplCfg.Owner,
+		Repo:                tmplCfg.Repo,
+		WorkflowID:          tmplCfg.WorkflowID,
+		Ref:                 tmplCfg.Ref,
+		DefaultInputs:       tmplCfg.Inputs,
+		Labels:              tmplCfg.Labels,
+		InConfig:            true,
+		SourceType:          tmplCfg.SourceType,
+		SourcePath:          tmplCfg.SourcePath,
+		Deprecated:          tmplCfg.Deprecated,
+		SunsetAt:            tmplCfg.SunsetAt,
+		Environment:         tmplCfg.Environment,
+		Category:            tmplCfg.Category,
+		DisplayOrder:        tmplCfg.DisplayOrder,
+		DispatchWindows:     tmplCfg.DispatchWindows,
+		PinnedInputs:        tmplCfg.PinnedInputs,
+		MinIdleRunners:      tmplCfg.MinIdleRunners,
+		DefaultPriority:     tmplCfg.DefaultPriority,
+		KeepRunning:         tmplCfg.KeepRunning,
+		StallTimeoutSeconds: int(tmplCfg.StallTimeout.Seconds()),
+		MaxDurationSeconds:  int(tmplCfg.MaxDuration.Seconds()),
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+
+	if canary := tmplCfg.Canary; canary != nil {
+		template.CanaryPercent = canary.Percent
+		template.CanaryWorkflowID = canary.WorkflowID
+		template.CanaryRef = canary.Ref
+	}
+
+	if handoff := tmplCfg.SecretHandoff; handoff != nil {
+		template.SecretHandoffInputs = handoff.Inputs
+		template.SecretHandoffEnvironment = handoff.Environment
+	}
+
+	return template
+}
+
+// applyTemplateOverrides applies the API overrides of a stored template to its
+// form built from config, so config syncs keep them.
+func applyTemplateOverrides(template *store.JobTemplate, overrides *store.TemplateOverrides) {
+	if overrides == nil {
+		return
+	}
+
+	template.Overrides = overrides
+
+	if overrides.Owner != "" {
+		template.Owner = overrides.Owner
+	}
+
+	if overrides.Repo != "" {
+		template.Repo = overrides.Repo
+	}
+
+	if overrides.Ref != "" {
+		template.Ref = overrides.Ref
+	}
+}
+
+// changedGroupFields lists the synced fields that differ between two groups.
+func changedGroupFields(old, updated *store.Group) []string {
+	var fields []string
+
+	if old.Name != updated.Name {
+		fields = append(fields, "name")
+	}
+
+	if old.Description != updated.Description {
+		fields = append(fields, "description")
+	}
+
+	if !slices.Equal(old.RunnerLabels, updated.RunnerLabels) {
+		fields = append(fields, "runner_labels")
+	}
+
+	if old.SchedulingPolicy != updated.SchedulingPolicy {
+		fields = append(fields, "scheduling_policy")
+	}
+
+	if !intsEqual(old.MaxPriority, updated.MaxPriority) {
+		fields = append(fields, "max_priority")
+	}
+
+	if !intsEqual(old.MaxRequeueLimit, updated.MaxRequeueLimit) {
+		fields = append(fields, "max_requeue_limit")
+	}
+
+	if old.MinOnlineRunners != updated.MinOnlineRunners {
+		fields = append(fields, "min_online_runners")
+	}
+
+	if !reflect.DeepEqual(old.Metadata, updated.Metadata) {
+		fields = append(fields, "metadata")
+	}
+
+	return fields
+}
+
+// changedTemplateFields lists the synced fields that differ between two templates.
+func changedTemplateFields(old, updated *store.JobTemplate) []string {
+	var fields []string
+
+	check := func(name string, changed bool) {
+		if changed {
+			fields = append(fields, name)
+		}
+	}
+
+	check("group_id", old.GroupID != updated.GroupID)
+	check("name", old.Name != updated.Name)
+	check("owner", old.Owner != updated.Owner)
+	check("repo", old.Repo != updated.Repo)
+	check("workflow_id", old.WorkflowID != updated.WorkflowID)
+	check("ref", old.Ref != updated.Ref)
+	check("default_inputs", !maps.Equal(old.DefaultInputs, updated.DefaultInputs))
+	check("labels", !maps.Equal(old.Labels, updated.Labels))
+	check("in_config", old.InConfig != updated.InConfig)
+	check("source", old.SourceType != updated.SourceType || old.SourcePath != updated.SourcePath)
+	check("deprecated", old.Deprecated != updated.Deprecated)
+	check("sunset_at", !timesEqual(old.SunsetAt, updated.SunsetAt))
+	check("environment", old.Environment != updated.Environment)
+	check("category", old.Category != updated.Category)
+	check("display_order", old.DisplayOrder != updated.DisplayOrder)
+	check("dispatch_windows", (len(old.DispatchWindows) > 0 || len(updated.DispatchWindows) > 0) &&
+		!schedule.Equal(old.DispatchWindows, updated.DispatchWindows))
+	check("pinned_inputs", !slices.Equal(old.PinnedInputs, updated.PinnedInputs))
+	check("min_idle_runners", old.MinIdleRunners != updated.MinIdleRunners)
+	check("default_priority", old.DefaultPriority != updated.DefaultPriority)
+	check("keep_running", old.KeepRunning != updated.KeepRunning)
+	check("stall_timeout", old.StallTimeoutSeconds != updated.StallTimeoutSeconds)
+	check("max_duration", old.MaxDurationSeconds != updated.MaxDurationSeconds)
+	check("canary", old.CanaryPercent != updated.CanaryPercent || old.CanaryWorkflowID != updated.CanaryWorkflowID ||
+		old.CanaryRef != updated.CanaryRef)
+	check("secret_handoff", !slices.Equal(old.SecretHandoffInputs, updated.SecretHandoffInputs) ||
+		old.SecretHandoffEnvironment != updated.SecretHandoffEnvironment)
+
+	return fields
+}
+
+// timesEqual reports whether two optional times are both unset or the same instant.
+func timesEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
+
+// intsEqual reports whether two optional ints are both unset or equal.
+func intsEqual(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// DiffGroupsFromConfig computes what SyncGroupsFromConfig would change for a
+// configuration replacing the current one, without changing anything. Groups
+// removed are those of current missing from cfg; current may be nil.
+func DiffGroupsFromConfig(ctx context.Context, st store.Store, current, cfg *config.Config) (*ConfigSyncDiff, error) {
+	diff := &ConfigSyncDiff{}
+	now := time.Now()
+
+	configGroups := make(map[string]bool, len(cfg.Groups.GitHub))
+
+	for i := range cfg.Groups.GitHub {
+		groupCfg := &cfg.Groups.GitHub[i]
+		configGroups[groupCfg.ID] = true
+
+		existing, err := st.GetGroup(ctx, groupCfg.ID)
+		if err != nil {
+			return nil, fmt.Errorf("checking group %s: %w", groupCfg.ID, err)
+		}
+
+		if existing == nil {
+			diff.GroupsCreated = append(diff.GroupsCreated, ConfigSyncItem{GroupID: groupCfg.ID})
+		} else if fields := changedGroupFields(existing, groupFromConfig(groupCfg, now)); len(fields) > 0 {
+			diff.GroupsUpdated = append(diff.GroupsUpdated, ConfigSyncItem{GroupID: groupCfg.ID, Fields: fields})
+		}
+
+		configTemplateIDs := make(map[string]bool, len(groupCfg.WorkflowDispatchTemplates))
+
+		for j := range groupCfg.WorkflowDispatchTemplates {
+			tmplCfg := &groupCfg.WorkflowDispatchTemplates[j]
+			configTemplateIDs[tmplCfg.ID] = true
+			item := ConfigSyncItem{GroupID: groupCfg.ID, TemplateID: tmplCfg.ID}
+
+			existingTemplate, err := st.GetJobTemplate(ctx, tmplCfg.ID)
+			if err != nil {
+				return nil, fmt.Errorf("checking job template %s: %w", tmplCfg.ID, err)
+			}
+
+			if existingTemplate == nil {
+				diff.TemplatesCreated = append(diff.TemplatesCreated, item)
+
+				continue
+			}
+
+			template := templateFromConfig(groupCfg.ID, tmplCfg, now)
+			applyTemplateOverrides(template, existingTemplate.Overrides)
+
+			if item.Fields = changedTemplateFields(existingTemplate, template); len(item.Fields) > 0 {
+				diff.TemplatesUpdated = append(diff.TemplatesUpdated, item)
+			}
+		}
+
+		if existing == nil {
+			continue
+		}
+
+		dbTemplates, err := st.ListJobTemplatesByGroup(ctx, groupCfg.ID)
+		if err != nil {
+			return nil, fmt.Errorf("listing templates for group %s: %w", groupCfg.ID, err)
+		}
+
+		for _, dbTmpl := range dbTemplates {
+			if configTemplateIDs[dbTmpl.ID] || dbTmpl.SourceType == store.TemplateSourceAPI {
+				continue
+			}
+
+			hasJobs, err := st.HasAnyJobs(ctx, dbTmpl.ID)
+			if err != nil {
+				return nil, fmt.Errorf("checking jobs for template %s: %w", dbTmpl.ID, err)
+			}
+
+			item := ConfigSyncItem{GroupID: groupCfg.ID, TemplateID: dbTmpl.ID}
+
+			switch {
+			case !hasJobs:
+				diff.TemplatesDeleted = append(diff.TemplatesDeleted, item)
+			case dbTmpl.InConfig:
+				diff.TemplatesRetired = append(diff.TemplatesRetired, item)
+			}
+		}
+	}
+
+	if current != nil {
+		for _, group := range current.Groups.GitHub {
+			if !configGroups[group.ID] {
+				diff.GroupsRemoved = append(diff.GroupsRemoved, ConfigSyncItem{GroupID: group.ID})
+			}
+		}
+	}
+
+	return diff, nil
+}
+
+// stageConfigSync replaces any staged config sync with one for cfg. With
+// keepStaged, a staged sync with the same diff is returned instead.
+func (s *server) stageConfigSync(
+	ctx context.Context,
+	actor string,
+	cfg *config.Config,
+	diff *ConfigSyncDiff,
+	keepStaged bool,
+) *StagedConfigSync {
+	s.stagedSyncMu.Lock()
+	current := s.stagedSync
+	s.stagedSyncMu.Unlock()
+
+	if keepStaged && current != nil && reflect.DeepEqual(current.Diff, diff) {
+		return current
+	}
+
+	staged := &StagedConfigSync{
+		ID:       uuid.New().String(),
+		Diff:     diff,
+		StagedBy: actor,
+		StagedAt: time.Now(),
+		cfg:      cfg,
+	}
+
+	s.stagedSyncMu.Lock()
+	s.stagedSync = staged
+	s.stagedSyncMu.Unlock()
+
+	s.auditConfigSyncAs(ctx, actor, staged, store.AuditActionConfigSyncStaged, fmt.Sprintf("Staged config sync by %s", actor))
+
+	return staged
+}
+
+// takeStagedConfigSync removes and returns the staged config sync with the
+// given ID, writing the error response when there is none.
+func (s *server) takeStagedConfigSync(w http.ResponseWriter, id string) *StagedConfigSync {
+	s.stagedSyncMu.Lock()
+	defer s.stagedSyncMu.Unlock()
+
+	if s.stagedSync == nil {
+		s.writeError(w, http.StatusNotFound, "No config sync is staged")
+
+		return nil
+	}
+
+	if s.stagedSync.ID != id {
+		s.writeError(w, http.StatusConflict, "A newer config sync has been staged")
+
+		return nil
+	}
+
+	staged := s.stagedSync
+	s.stagedSync = nil
+
+	return staged
+}
+
+// handleGetStagedConfigSync godoc
+//
+//	@Summary		Get staged config sync
+//	@Description	Returns the config reload waiting for approval and what it would change (requires admin)
+//	@Tags			templates
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{object}	StagedConfigSync
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Router			/config/sync [get]
+func (s *server) handleGetStagedConfigSync(w http.ResponseWriter, r *http.Request) {
+	s.stagedSyncMu.Lock()
+	staged := s.stagedSync
+	s.stagedSyncMu.Unlock()
+
+	if staged == nil {
+		s.writeError(w, http.StatusNotFound, "No config sync is staged")
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, staged)
+}
+
+// handleApproveConfigSync godoc
+//
+//	@Summary		Approve staged config sync
+//	@Description	Applies the staged config reload to the database and the running configuration (requires admin)
+//	@Tags			templates
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		string	true	"Staged sync ID"
+//	@Success		200	{object}	ReloadTemplatesResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/config/sync/{id}/approve [post]
+func (s *server) handleApproveConfigSync(w http.ResponseWriter, r *http.Request) {
+	staged := s.takeStagedConfigSync(w, chi.URLParam(r, "id"))
+	if staged == nil {
+		return
+	}
+
+	if err := s.applyConfigSync(r.Context(), staged.cfg); err != nil {
+		s.log.WithError(err).Error("Failed to apply staged config sync")
+		s.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to sync templates: %v", err))
+
+		return
+	}
+
+	s.auditConfigSync(r, staged, store.AuditActionConfigSyncApproved,
+		fmt.Sprintf("Approved config sync staged by %s", staged.StagedBy))
+
+	s.log.WithField("sync", staged.ID).Info("Staged config sync approved")
+	s.writeJSON(w, http.StatusOK, ReloadTemplatesResponse{
+		Message: "Templates reloaded successfully",
+		Groups:  reloadGroupStats(staged.cfg),
+	})
+}
+
+// handleRejectConfigSync godoc
+//
+//	@Summary		Reject staged config sync
+//	@Description	Discards the staged config reload without changing anything (requires admin)
+//	@Tags			templates
+//	@Security		BearerAuth
+//	@Param			id	path	string	true	"Staged sync ID"
+//	@Success		204	"Staged sync discarded"
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Router			/config/sync/{id}/reject [post]
+func (s *server) handleRejectConfigSync(w http.ResponseWriter, r *http.Request) {
+	staged := s.takeStagedConfigSync(w, chi.URLParam(r, "id"))
+	if staged == nil {
+		return
+	}
+
+	s.auditConfigSync(r, staged, store.AuditActionConfigSyncRejected,
+		fmt.Sprintf("Rejected config sync staged by %s", staged.StagedBy))
+
+	s.log.WithField("sync", staged.ID).Info("Staged config sync rejected")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// auditConfigSync records an action on a staged config sync.
+func (s *server) auditConfigSync(r *http.Request, staged *StagedConfigSync, action store.AuditAction, details string) {
+	actor := "anonymous"
+	if user := auth.UserFromContext(r.Context()); user != nil {
+		actor = user.Username
+	}
+
+	s.auditConfigSyncAs(r.Context(), actor, staged, action, details)
+}
+
+// auditConfigSyncAs records a config sync audit entry for the given actor.
+func (s *server) auditConfigSyncAs(ctx context.Context, actor string, staged *StagedConfigSync, action store.AuditAction, details string) {
+	if err := s.store.CreateAuditEntry(ctx, &store.AuditEntry{
+		ID:         uuid.New().String(),
+		Action:     action,
+		EntityType: store.AuditEntitySystem,
+		EntityID:   staged.ID,
+		Actor:      actor,
+		Details:    details,
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		s.log.WithError(err).WithField("sync", staged.ID).Warn("Failed to create audit entry for config sync")
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/ethpandaops/dispatchoor/pkg/auth"
+)
+
+const (
+	// csrfCookieName is the cookie holding the CSRF token, readable by scripts
+	// on the UI origin so they can echo it in csrfHeaderName.
+	csrfCookieName = "csrf_token"
+	// csrfHeaderName is the header state-changing cookie-authenticated
+	// requests must carry the CSRF token in.
+	csrfHeaderName = "X-CSRF-Token"
+)
+
+// csrfMiddleware rejects state-changing requests authenticated by the session
+// cookie unless they carry the session's CSRF token in csrfHeaderName. A
+// cross-site page can make the browser send the cookie but cannot read the
+// token. Requests with an Authorization header are not checked, since browsers
+// never attach one on their own. The token cookie is (re)issued whenever it is
+// missing or stale, e.g. for sessions created before CSRF checks existed.
+func (s *server) csrfMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := r.Cookie("session")
+		if r.Header.Get("Authorization") != "" || err != nil || session.Value == "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		expected := auth.CSRFToken(session.Value)
+
+		if cookie, err := r.Cookie(csrfCookieName); err != nil || cookie.Value != expected {
+			s.setCSRFCookie(w, r, session.Value)
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get(csrfHeaderName)), []byte(expected)) != 1 {
+				s.writeError(w, http.StatusForbidden, "Missing or invalid CSRF token")
+
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setCSRFCookie issues the CSRF token cookie for a session token, or clears it
+// when sessionToken is empty.
+func (s *server) setCSRFCookie(w http.ResponseWriter, r *http.Request, sessionToken string) {
+	cookie := &http.Cookie{
+		Name:     csrfCookieName,
+		Path:     "/",
+		SameSite: http.SameSiteLaxMode,
+		Secure:   s.isSecureRequest(r),
+		MaxAge:   -1,
+	}
+
+	if sessionToken != "" {
+		cookie.Value = auth.CSRFToken(sessionToken)
+		cookie.MaxAge = int(s.cfg.Auth.SessionTTL.Seconds())
+	}
+
+	http.SetCookie(w, cookie)
+}
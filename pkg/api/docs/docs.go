@@ -22,6 +22,208 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/database": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the size of the database and the row count and on-disk size of each table, to watch the growth of the jobs and audit tables. Row counts are estimates for PostgreSQL, and SQLite table sizes need the dbstat table (requires admin).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get database stats",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.DatabaseStats"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/matching-report": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists, for each group, the runners matching its labels and, for each pending job, why it can or cannot be dispatched right now: dispatchable, dispatcher_disabled, group_disabled, group_paused, triggered_jobs_pending, job_paused, outside_dispatch_window, queued_behind, no_idle_runner or not_enough_idle_runners. Nothing is dispatched (requires admin).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get matching report",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.MatchingReportResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/settings": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the runtime-tunable settings in effect, their configured defaults and the persisted overrides (requires admin)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get runtime settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.SettingsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Overrides or resets runtime-tunable settings. Changes are persisted and applied without a restart, on every replica (requires admin)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Update runtime settings",
+                "parameters": [
+                    {
+                        "description": "Settings to change",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.UpdateSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.SettingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/auth/exchange": {
             "post": {
                 "description": "Exchanges a one-time authorization code for a session token",
@@ -260,7 +462,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns the currently authenticated user's information",
+                "description": "Returns the currently authenticated user's information, or that of the user an admin acts as with the X-Act-As header",
                 "produces": [
                     "application/json"
                 ],
@@ -272,7 +474,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.User"
+                            "$ref": "#/definitions/pkg_api.MeResponse"
                         }
                     },
                     "401": {
@@ -284,29 +486,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/groups": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchanges a refresh token for a new short-lived access token when JWT sessions are enabled. The refresh token is read from the body or the refresh_token cookie and stays valid until its session expires or is revoked.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Returns all configured groups with statistics",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "groups"
+                    "auth"
+                ],
+                "summary": "Refresh access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.RefreshRequest"
+                        }
+                    }
                 ],
-                "summary": "List groups",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/pkg_api.GroupWithStats"
-                            }
+                            "$ref": "#/definitions/pkg_api.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
                     "401": {
@@ -315,44 +528,49 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "JWT sessions not enabled",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.RateLimitErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/groups/{id}": {
+        "/auth/sessions": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns a single group by ID",
+                "description": "Returns the current user's active sessions, newest first, with the IP address, user agent, and time of their last use. Admins can pass all=true to list every user's sessions.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "groups"
+                    "auth"
                 ],
-                "summary": "Get group",
+                "summary": "List sessions",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Group ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "boolean",
+                        "description": "List sessions of all users (admin only)",
+                        "name": "all",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Group"
+                            "$ref": "#/definitions/pkg_api.SessionsResponse"
                         }
                     },
                     "401": {
@@ -361,8 +579,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
@@ -376,58 +594,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/groups/{id}/history": {
-            "get": {
+        "/auth/sessions/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns paginated history of completed, failed, and cancelled jobs",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Revokes one of the current user's sessions. Admins can revoke any user's session.",
                 "tags": [
-                    "history"
+                    "auth"
                 ],
-                "summary": "Get job history",
+                "summary": "Revoke session",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Group ID",
+                        "description": "Session ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Session revoked"
                     },
-                    {
-                        "type": "integer",
-                        "default": 50,
-                        "description": "Number of jobs to return (max 100)",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Cursor for pagination (RFC3339 timestamp)",
-                        "name": "before",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by status (comma-separated: completed,failed,cancelled)",
-                        "name": "status",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/pkg_api.HistoryResponse"
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
@@ -441,48 +640,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/groups/{id}/history/stats": {
+        "/campaigns": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns aggregated job statistics over a time range",
+                "description": "Returns all campaigns, newest first, with their progress",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "history"
-                ],
-                "summary": "Get history statistics",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Group ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "default": "auto",
-                        "description": "Time range (1h, 6h, 24h, 7d, 30d, auto)",
-                        "name": "range",
-                        "in": "query"
-                    }
+                    "campaigns"
                 ],
+                "summary": "List campaigns",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/pkg_api.HistoryStatsResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/pkg_api.CampaignResponse"
+                            }
                         }
                     },
                     "401": {
@@ -498,53 +678,56 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/groups/{id}/pause": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Pauses job dispatching for a group (requires admin)",
+                "description": "Creates a campaign and enqueues its jobs. If any job cannot be enqueued, the jobs enqueued so far are removed and no campaign is created (requires admin)",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "groups"
+                    "campaigns"
                 ],
-                "summary": "Pause group",
+                "summary": "Create campaign",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Group ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Campaign",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.CreateCampaignRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Group"
+                            "$ref": "#/definitions/pkg_api.CampaignResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
@@ -558,25 +741,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/groups/{id}/queue": {
+        "/campaigns/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns all pending, triggered, and running jobs in the group's queue",
+                "description": "Returns a campaign with its progress and jobs",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "queue"
+                    "campaigns"
                 ],
-                "summary": "Get queue",
+                "summary": "Get campaign",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Group ID",
+                        "description": "Campaign ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -586,10 +769,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
-                            }
+                            "$ref": "#/definitions/pkg_api.CampaignResponse"
                         }
                     },
                     "401": {
@@ -598,6 +778,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -605,53 +791,37 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/campaigns/{id}/cancel": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Adds a new job to the group's queue, either from a template or with manual configuration",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Cancels every unfinished job of a campaign. Workflow runs of triggered and running jobs are cancelled on GitHub (requires admin)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "jobs"
+                    "campaigns"
                 ],
-                "summary": "Add job to queue",
+                "summary": "Cancel campaign",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Group ID",
+                        "description": "Campaign ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Job configuration",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/pkg_api.AddJobRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                            "$ref": "#/definitions/pkg_api.CampaignActionResponse"
                         }
                     },
                     "401": {
@@ -665,54 +835,51 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/groups/{id}/queue/reorder": {
-            "put": {
+        "/campaigns/{id}/pause": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Reorders jobs in the queue by specifying the desired order of job IDs",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Pauses every pending job of a campaign (requires admin)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "queue"
+                    "campaigns"
                 ],
-                "summary": "Reorder queue",
+                "summary": "Pause campaign",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Group ID",
+                        "description": "Campaign ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "New job order",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/pkg_api.ReorderQueueRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Queue reordered successfully"
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                            "$ref": "#/definitions/pkg_api.CampaignActionResponse"
                         }
                     },
                     "401": {
@@ -726,29 +893,41 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/groups/{id}/runners": {
-            "get": {
+        "/campaigns/{id}/unpause": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns all runners matching the group's runner labels",
+                "description": "Resumes every paused job of a campaign (requires admin)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "runners"
+                    "campaigns"
                 ],
-                "summary": "Get group runners",
+                "summary": "Unpause campaign",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Group ID",
+                        "description": "Campaign ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -758,10 +937,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Runner"
-                            }
+                            "$ref": "#/definitions/pkg_api.CampaignActionResponse"
                         }
                     },
                     "401": {
@@ -770,6 +946,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -785,38 +967,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/groups/{id}/templates": {
+        "/config/sync": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns all job templates for a group",
+                "description": "Returns the config reload waiting for approval and what it would change (requires admin)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "templates"
                 ],
-                "summary": "List job templates",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Group ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "Get staged config sync",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobTemplate"
-                            }
+                            "$ref": "#/definitions/pkg_api.StagedConfigSync"
                         }
                     },
                     "401": {
@@ -825,8 +995,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
@@ -834,25 +1010,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/groups/{id}/unpause": {
+        "/config/sync/{id}/approve": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Resumes job dispatching for a group (requires admin)",
+                "description": "Applies the staged config reload to the database and the running configuration (requires admin)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "groups"
+                    "templates"
                 ],
-                "summary": "Unpause group",
+                "summary": "Approve staged config sync",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Group ID",
+                        "description": "Staged sync ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -862,7 +1038,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Group"
+                            "$ref": "#/definitions/pkg_api.ReloadTemplatesResponse"
                         }
                     },
                     "401": {
@@ -883,6 +1059,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -892,62 +1074,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/health": {
-            "get": {
-                "description": "Returns the health status of the API server",
-                "produces": [
-                    "application/json"
+        "/config/sync/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Discards the staged config reload without changing anything (requires admin)",
                 "tags": [
-                    "system"
+                    "templates"
                 ],
-                "summary": "Health check",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/pkg_api.HealthResponse"
-                        }
-                    },
-                    "429": {
-                        "description": "Rate limit exceeded",
-                        "schema": {
-                            "$ref": "#/definitions/pkg_api.RateLimitErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/jobs/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Returns a single job by ID",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "jobs"
-                ],
-                "summary": "Get job",
+                "summary": "Reject staged config sync",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Job ID",
+                        "description": "Staged sync ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
-                        }
+                    "204": {
+                        "description": "Staged sync discarded"
                     },
                     "401": {
                         "description": "Unauthorized",
@@ -955,66 +1105,58 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/filters": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Updates job configuration (inputs, name, owner, repo, workflow_id, ref, labels)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Returns the current user's saved queue and history filters",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "jobs"
+                    "filters"
                 ],
-                "summary": "Update job",
+                "summary": "List saved filters",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Job ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Job updates",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/pkg_api.UpdateJobRequest"
-                        }
+                        "description": "Only return filters for this view (queue or history)",
+                        "name": "view",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.SavedFilter"
+                            }
                         }
                     },
                     "401": {
@@ -1023,43 +1165,48 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/pkg_api.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     }
                 }
             },
-            "delete": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Removes a job from the queue (requires admin)",
+                "description": "Saves a named queue or history filter for the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "jobs"
+                    "filters"
                 ],
-                "summary": "Delete job",
+                "summary": "Create saved filter",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Job ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.SavedFilterRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Job deleted successfully"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.SavedFilter"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -1073,14 +1220,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
@@ -1088,14 +1235,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/jobs/{id}/auto-requeue": {
+        "/filters/{id}": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Enables or disables auto-requeue for a job and optionally sets a requeue limit",
+                "description": "Replaces a saved filter of the current user",
                 "consumes": [
                     "application/json"
                 ],
@@ -1103,24 +1250,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "jobs"
+                    "filters"
                 ],
-                "summary": "Update auto-requeue settings",
+                "summary": "Update saved filter",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Job ID",
+                        "description": "Filter ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Auto-requeue settings",
+                        "description": "Filter",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/pkg_api.UpdateAutoRequeueRequest"
+                            "$ref": "#/definitions/pkg_api.SavedFilterRequest"
                         }
                     }
                 ],
@@ -1128,7 +1275,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.SavedFilter"
                         }
                     },
                     "400": {
@@ -1143,57 +1290,49 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/jobs/{id}/cancel": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Cancels a triggered or running job (requires admin). If running on GitHub, also cancels the workflow run.",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Deletes a saved filter of the current user",
                 "tags": [
-                    "jobs"
+                    "filters"
                 ],
-                "summary": "Cancel job",
+                "summary": "Delete saved filter",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Job ID",
+                        "description": "Filter ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/pkg_api.ErrorResponse"
-                        }
+                    "204": {
+                        "description": "Saved filter deleted"
                     },
                     "401": {
                         "description": "Unauthorized",
@@ -1201,12 +1340,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/pkg_api.ErrorResponse"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1222,41 +1355,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/jobs/{id}/disable-requeue": {
-            "post": {
+        "/groups": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Disables auto-requeue for a job (requires admin)",
+                "description": "Returns all configured groups with statistics. Archived groups are omitted unless include_archived=true.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "jobs"
+                    "groups"
                 ],
-                "summary": "Disable auto-requeue",
+                "summary": "List groups",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Job ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "boolean",
+                        "description": "Include archived groups",
+                        "name": "include_archived",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/pkg_api.GroupWithStats"
+                            }
                         }
                     },
                     "401": {
@@ -1265,14 +1394,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/pkg_api.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
@@ -1280,25 +1403,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/jobs/{id}/pause": {
-            "post": {
+        "/groups/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Pauses a job in the queue (requires admin)",
+                "description": "Returns a single group by ID, with its markdown description rendered as HTML",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "jobs"
+                    "groups"
                 ],
-                "summary": "Pause job",
+                "summary": "Get group",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Job ID",
+                        "description": "Group ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1308,13 +1431,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                            "$ref": "#/definitions/pkg_api.GroupResponse"
                         }
                     },
                     "401": {
@@ -1323,14 +1440,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
@@ -1338,25 +1455,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/jobs/{id}/unpause": {
+        "/groups/{id}/archive": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Resumes a paused job (requires admin)",
+                "description": "Archives a group: it is no longer dispatched, accepts no new jobs and is hidden from the group list, while its templates and history are kept. The group must have no pending, triggered or running jobs (requires admin)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "jobs"
+                    "groups"
                 ],
-                "summary": "Unpause job",
+                "summary": "Archive group",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Job ID",
+                        "description": "Group ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1366,13 +1483,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Group"
                         }
                     },
                     "401": {
@@ -1392,53 +1503,107 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
+                    },
+                    "409": {
+                        "description": "Group has active jobs",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/openapi.json": {
-            "get": {
-                "description": "Returns the OpenAPI 3.0 specification for the API",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "system"
-                ],
-                "summary": "OpenAPI specification",
-                "responses": {
-                    "200": {
-                        "description": "OpenAPI specification",
-                        "schema": {
-                            "type": "object"
-                        }
-                    }
-                }
-            }
-        },
-        "/runners": {
+        "/groups/{id}/history": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns all GitHub Actions runners across all groups",
+                "description": "Returns paginated history of completed, failed, and cancelled jobs",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "runners"
+                    "history"
+                ],
+                "summary": "Get job history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of jobs to return (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor for pagination (RFC3339 timestamp)",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor tie-breaker (next_cursor_id of the previous page)",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of jobs to skip (alternative to cursor pagination)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Set to false to skip computing total_count",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status (comma-separated: completed,failed,cancelled)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by template ID",
+                        "name": "template_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Set to true to list retained jobs only",
+                        "name": "retained",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "List jobs assigned to this user; me is the caller",
+                        "name": "assignee",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List all runners",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Runner"
-                            }
+                            "$ref": "#/definitions/pkg_api.HistoryResponse"
                         }
                     },
                     "401": {
@@ -1456,21 +1621,54 @@ const docTemplate = `{
                 }
             }
         },
-        "/runners/refresh": {
+        "/groups/{id}/history/restore": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Triggers a refresh of runner information from GitHub (requires admin)",
+                "description": "Rebuilds a finished job from a completed GitHub workflow run, e.g. after its record was deleted by accident. Status, conclusion, timings, runner and commit are read from GitHub; inputs are not returned by GitHub and are taken from the request (requires admin)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "runners"
+                    "history"
+                ],
+                "summary": "Restore history job from run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Run to restore",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.RestoreJobRequest"
+                        }
+                    }
                 ],
-                "summary": "Refresh runners",
                 "responses": {
-                    "204": {
-                        "description": "Runners refresh initiated"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
                     },
                     "401": {
                         "description": "Unauthorized",
@@ -1483,30 +1681,88 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/status": {
+        "/groups/{id}/history/stats": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns comprehensive system status including database, GitHub API, and queue statistics",
+                "description": "Returns aggregated job statistics over a time range, optionally split by template, label, or creator",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "system"
+                    "history"
+                ],
+                "summary": "Get history statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "auto",
+                        "description": "Time range (1h, 6h, 24h, 7d, 30d, auto)",
+                        "name": "range",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Split counts into series by dimension (template_id, label, created_by, variant)",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label key to group by (required when group_by=label)",
+                        "name": "label_key",
+                        "in": "query"
+                    }
                 ],
-                "summary": "System status",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/pkg_api.SystemStatusResponse"
+                            "$ref": "#/definitions/pkg_api.HistoryStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
                     "401": {
@@ -1515,35 +1771,44 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "429": {
-                        "description": "Rate limit exceeded",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/pkg_api.RateLimitErrorResponse"
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/templates/reload": {
+        "/groups/{id}/pause": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Re-reads the config file to reload templates from files and URLs, then syncs to the database (requires admin)",
+                "description": "Pauses job dispatching for a group (requires admin)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "templates"
+                    "groups"
+                ],
+                "summary": "Pause group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Reload templates",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/pkg_api.ReloadTemplatesResponse"
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Group"
                         }
                     },
                     "401": {
@@ -1558,6 +1823,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1567,45 +1838,66 @@ const docTemplate = `{
                 }
             }
         },
-        "/templates/{id}": {
+        "/groups/{id}/queue": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns a single job template by ID",
+                "description": "Returns all pending, triggered, and running jobs in the group's queue. Unpaused pending jobs include their queue_position and ahead_count in dispatch order. With limit or after, returns a QueuePageResponse paging through pending jobs in dispatch order instead; with summary=true, returns a QueueSummaryResponse of job counts per template.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "templates"
+                    "queue"
                 ],
-                "summary": "Get job template",
+                "summary": "Get queue",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Template ID",
+                        "description": "Group ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page through pending jobs, this many per page (max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor for pagination (next_cursor of the previous page)",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return job counts per template instead of jobs",
+                        "name": "summary",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobTemplate"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                            }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
@@ -1617,306 +1909,4983 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/ws": {
-            "get": {
-                "description": "Establishes a WebSocket connection for real-time job and runner updates",
-                "tags": [
-                    "websocket"
-                ],
-                "summary": "WebSocket connection",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Authentication token",
-                        "name": "token",
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds a new job to the group's queue, either from a template or with manual configuration",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Add job to queue",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Job configuration",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.AddJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/queue/changes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns who reordered, paused, unpaused, reprioritized or deleted jobs in a group's queue, newest first, with the queue state of the affected jobs before and after each change",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "summary": "Get queue changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only changes affecting this job, including reorders that moved it",
+                        "name": "job_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of changes to return (max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of changes to skip",
+                        "name": "offset",
                         "in": "query"
                     }
                 ],
                 "responses": {
-                    "101": {
-                        "description": "WebSocket connection established"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.QueueChangesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/queue/compact": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Renumbers pending job positions of a group to consecutive values, keeping their order (requires admin)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "summary": "Compact queue",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.CompactQueueResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/queue/reorder": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reorders jobs in the queue by specifying the desired order of job IDs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "summary": "Reorder queue",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New job order",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ReorderQueueRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Queue reordered successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/pkg_api.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/queue/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a group's queue depth (pending jobs, paused or not), triggered and running jobs, and idle runners over a time range, from samples taken every queue.stats.interval. Samples are averaged into buckets of 1m (1h), 5m (6h), 15m (24h), 1h (7d) or 6h (30d); buckets without samples are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "summary": "Get queue stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "24h",
+                        "description": "Time range (1h, 6h, 24h, 7d, 30d)",
+                        "name": "range",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.QueueStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/runners": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns all runners matching the group's runner labels",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "runners"
+                ],
+                "summary": "Get group runners",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Runner"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/templates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns all job templates for a group, ordered by display_order then name. With group_by=category, returns a GroupedTemplatesResponse instead of an array.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "List job templates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "category"
+                        ],
+                        "type": "string",
+                        "description": "Group templates by field",
+                        "name": "group_by",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobTemplate"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/unarchive": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restores an archived group so it is listed, accepts jobs and is dispatched again (requires admin)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Unarchive group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Group"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/unpause": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Resumes job dispatching for a group (requires admin)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Unpause group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Group"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Returns the health status of the API server",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.HealthResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.RateLimitErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/compare": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a structured diff of the inputs, ref, resolved commit, runner, conclusion and duration of two jobs of the same template, e.g. a failed job against the last successful one",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Compare jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the job to compare from",
+                        "name": "a",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the job to compare to",
+                        "name": "b",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.JobComparison"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a single job by ID. Unpaused pending jobs include their queue_position and ahead_count.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates job configuration (inputs, name, owner, repo, workflow_id, ref, labels, priority, not_before)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Update job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Job updates",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.UpdateJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a job from the queue (requires admin)",
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Delete job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Job deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/annotations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the failure annotations (file, line and message) reported by the workflow run of a finished job, at most 50 per job",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get job annotations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobAnnotation"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/assign": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Assigns a failed job to the user investigating it, the caller by default. Only admins may assign other users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Assign job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Assignee",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.AssignJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/attempts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the current attempt of a job's workflow run and how each earlier attempt ended before the run was re-run from GitHub, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get job run attempts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.JobAttemptsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/auto-requeue": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Enables or disables auto-requeue for a job and optionally sets a requeue limit",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Update auto-requeue settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Auto-requeue settings",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.UpdateAutoRequeueRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancels a triggered or running job (requires admin). If running on GitHub, also cancels the workflow run.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Cancel job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/disable-requeue": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Disables auto-requeue for a job (requires admin)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Disable auto-requeue",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/heartbeat": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Records that a triggered or running job's workflow is alive, clearing its stalled flag. Authenticated with an API token from auth.api_tokens. Progress reports count as heartbeats too.",
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Send job heartbeat",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/notify-targets": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces where the transitions of a job that has not finished are delivered, in addition to its subscribers, e.g. a Slack channel to ping on completion (requires admin)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Set job notify targets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Notify targets",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.SetJobNotifyTargetsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/owner": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes the created_by of a job, e.g. when its creator left or a bot enqueued it on behalf of someone. The original creator is kept in original_created_by (requires admin)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Reassign job owner",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New owner",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.UpdateJobOwnerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pauses a job in the queue (requires admin)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Pause job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/progress": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stores the progress of a triggered or running job and broadcasts it to the job's group subscribers. Authenticated with an API token from auth.api_tokens, so workflows can call it mid-run. Each report replaces the previous one and counts as a heartbeat.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Report job progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Progress report",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ReportJobProgressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/requeue": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a new pending job from a completed, failed, or cancelled job, optionally with modified inputs or in another group (requires admin)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Requeue job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Requeue options",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.RequeueJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/retain": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marks a completed, failed or cancelled job as retained, so history retention and max_jobs_per_group never delete it, e.g. to keep a golden run (requires admin)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Retain job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/timeline": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns every status transition of a job, oldest first, with who made it, the workflow run and the runner at the time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get job timeline",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobEvent"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/unassign": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Clears the assignee of a job. Only admins may unassign jobs assigned to other users",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Unassign job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/unpause": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Resumes a paused job (requires admin)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Unpause job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/unretain": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a retained job to normal history retention (requires admin)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Unretain job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/openapi.json": {
+            "get": {
+                "description": "Returns the OpenAPI 3.0 specification for the API",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "OpenAPI specification",
+                "responses": {
+                    "200": {
+                        "description": "OpenAPI specification",
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                }
+            }
+        },
+        "/overview": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns all groups with statistics, a runner summary, failures of the last 24 hours and system status in one response. Group statistics come from aggregate queries rather than per-group job lists.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Get dashboard overview",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Include archived groups",
+                        "name": "include_archived",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.OverviewResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/runners": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns all GitHub Actions runners across all groups",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "runners"
+                ],
+                "summary": "List all runners",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Runner"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/runners/refresh": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Triggers a refresh of runner information from GitHub (requires admin)",
+                "tags": [
+                    "runners"
+                ],
+                "summary": "Refresh runners",
+                "responses": {
+                    "204": {
+                        "description": "Runners refresh initiated"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Finds groups and templates whose ID or name contains the query, jobs whose ID starts with it (at least 4 characters) or whose workflow run ID equals it, and runners whose name contains it. Matching is case-insensitive. Groups the user cannot view, and their templates and jobs, are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Search",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum matches per type (default 10, max 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.SearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/status": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns comprehensive system status including database, GitHub API, and queue statistics",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "System status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.SystemStatusResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.RateLimitErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the current user's job and template subscriptions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "List subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Subscription"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Subscribes the current user to the state transitions of a job, or of every job of a template. Notifications are sent by email, to a Slack incoming webhook or as JSON to a webhook. Job subscriptions are removed once the job finishes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Create subscription",
+                "parameters": [
+                    {
+                        "description": "Subscription",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.SubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a subscription of the current user",
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Delete subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Subscription deleted"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/bulk-edit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes the owner, repo or ref of the selected templates, e.g. after a repository was renamed or its default branch changed. Templates managed through the API are changed directly; templates from config keep the change as an override that config syncs apply until it is reset. Nothing is changed unless every template can be (requires admin).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Bulk edit job templates",
+                "parameters": [
+                    {
+                        "description": "Templates and changes",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.BulkEditTemplatesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.BulkEditTemplatesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/reload": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Re-reads the config file to reload templates from files, URLs and git sources, then syncs to the database (requires admin). With sync.require_approval enabled, changes are staged for approval instead and 202 is returned with the diff",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Reload templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ReloadTemplatesResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ReloadTemplatesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a single job template by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Get job template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobTemplate"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a template managed through the API, such as a clone. Templates from config, and templates with jobs, cannot be deleted (requires admin).",
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Delete job template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{id}/clone": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Copies a template under a new ID, optionally into another group and with a different owner, repo or ref. The clone is managed through the API: config syncs neither update nor remove it (requires admin).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Clone job template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Clone",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.CloneTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{id}/lint": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Checks the template against its workflow definition on the template's ref and stores the result. With cached=true, returns the last stored result instead.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Lint job template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return the last stored result without contacting GitHub",
+                        "name": "cached",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.TemplateLint"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/github": {
+            "post": {
+                "description": "Accepts workflow_run, workflow_job, issue_comment, issues and push deliveries signed with github.webhook_secret. An in-progress or completed workflow run moves the job tracking it to running, completed, failed or cancelled without waiting for the tracking loop. A workflow job picked up by a runner marks the runner busy and the job running on it; a completed job marks its runner idle and immediately triggers a dispatch cycle if the runner matches any group. A queued or in-progress job of a re-run attempt reopens the failed or cancelled job that ran the workflow run. New comments are checked for chatops commands when chatops is enabled, and newly opened issues carrying chatops.issue_label are read as issue form job requests. A push to the ref of a git template source refreshes templates in the background. Other events are ignored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Receive GitHub webhooks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook event type",
+                        "name": "X-GitHub-Event",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "HMAC-SHA256 signature of the payload",
+                        "name": "X-Hub-Signature-256",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Delivery processed or ignored"
+                    },
+                    "400": {
+                        "description": "Malformed payload",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid signature",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.RateLimitErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "description": "Establishes a WebSocket connection for real-time job and runner updates",
+                "tags": [
+                    "websocket"
+                ],
+                "summary": "WebSocket connection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authentication token",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "WebSocket connection established"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "github_com_ethpandaops_dispatchoor_pkg_dispatcher.GroupMatchReport": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error is set when the group could not be evaluated.",
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "string"
+                },
+                "idle_runners": {
+                    "type": "integer"
+                },
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_dispatcher.JobMatch"
+                    }
+                },
+                "min_online_runners": {
+                    "type": "integer"
+                },
+                "next_job_id": {
+                    "description": "NextJobID is the job the dispatcher picks next, blocked or not.",
+                    "type": "string"
+                },
+                "online_runners": {
+                    "description": "OnlineRunners counts matching runners that are online, busy or not.",
+                    "type": "integer"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "paused_reason": {
+                    "type": "string"
+                },
+                "runner_labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "runners": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_dispatcher.RunnerMatch"
+                    }
+                },
+                "triggered_jobs": {
+                    "description": "TriggeredJobs counts jobs dispatched but not running yet.",
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_dispatcher.JobMatch": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_dispatcher.MatchReason"
+                },
+                "template_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_dispatcher.MatchReason": {
+            "type": "string",
+            "enum": [
+                "dispatchable",
+                "dispatcher_disabled",
+                "group_disabled",
+                "group_paused",
+                "triggered_jobs_pending",
+                "job_paused",
+                "not_before",
+                "outside_dispatch_window",
+                "queued_behind",
+                "not_enough_online_runners",
+                "no_idle_runner",
+                "not_enough_idle_runners"
+            ],
+            "x-enum-varnames": [
+                "MatchReasonDispatchable",
+                "MatchReasonDispatcherDisabled",
+                "MatchReasonGroupDisabled",
+                "MatchReasonGroupPaused",
+                "MatchReasonTriggeredPending",
+                "MatchReasonJobPaused",
+                "MatchReasonNotBefore",
+                "MatchReasonOutsideWindow",
+                "MatchReasonQueuedBehind",
+                "MatchReasonNotEnoughOnlineRunners",
+                "MatchReasonNoIdleRunner",
+                "MatchReasonNotEnoughIdleRunners"
+            ]
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_dispatcher.RunnerMatch": {
+            "type": "object",
+            "properties": {
+                "busy": {
+                    "type": "boolean"
+                },
+                "free_slots": {
+                    "type": "integer"
+                },
+                "hosted": {
+                    "description": "Hosted marks a GitHub-hosted larger runner pool, and FreeSlots is how\nmany more jobs it can take.",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "idle": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.RunnerStatus"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_input.Map": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_input.Value"
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_input.Value": {
+            "type": "object"
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_schedule.Window": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.AuthProvider": {
+            "type": "string",
+            "enum": [
+                "basic",
+                "github"
+            ],
+            "x-enum-varnames": [
+                "AuthProviderBasic",
+                "AuthProviderGitHub"
+            ]
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.DatabaseStats": {
+            "type": "object",
+            "properties": {
+                "driver": {
+                    "type": "string"
+                },
+                "free_bytes": {
+                    "type": "integer"
+                },
+                "size_bytes": {
+                    "description": "SizeBytes is the size of the whole database, and FreeBytes the part of\nit that is allocated but unused (SQLite only; reclaimed by VACUUM).",
+                    "type": "integer"
+                },
+                "tables": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.TableStats"
+                    }
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.Group": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "description": "not dispatched or listed, history kept",
+                    "type": "boolean"
+                },
+                "archived_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "max_priority": {
+                    "description": "MaxPriority caps the priority of the group's jobs (nil = no cap).",
+                    "type": "integer"
+                },
+                "max_requeue_limit": {
+                    "description": "MaxRequeueLimit caps the requeue limit of auto-requeue jobs; with a cap\nset, no job requeues forever (nil = no cap).",
+                    "type": "integer"
+                },
+                "metadata": {
+                    "description": "Metadata is context shown on the group's queue page (nil = none).",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.GroupMetadata"
+                        }
+                    ]
+                },
+                "min_online_runners": {
+                    "description": "MinOnlineRunners is the number of online matching runners below which\ndispatch is held (0 = no minimum).",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "paused_reason": {
+                    "description": "set when paused automatically",
+                    "type": "string"
+                },
+                "resumed_at": {
+                    "description": "last manual unpause",
+                    "type": "string"
+                },
+                "runner_labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scheduling_policy": {
+                    "description": "SchedulingPolicy orders the group's pending jobs for dispatch (empty = fifo).",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.GroupLink": {
+            "type": "object",
+            "properties": {
+                "title": {
+                    "type": "string",
+                    "example": "Runbook"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://wiki.example.com/sync-tests"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.GroupMetadata": {
+            "type": "object",
+            "properties": {
+                "chat_channel": {
+                    "type": "string",
+                    "example": "#sync-tests"
+                },
+                "fields": {
+                    "description": "Fields are any other key/value pairs worth showing.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.GroupLink"
+                    }
+                },
+                "team": {
+                    "type": "string",
+                    "example": "platform"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.HandoffSecrets": {
+            "type": "object",
+            "properties": {
+                "environment": {
+                    "type": "string"
+                },
+                "names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "repo": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.InputOverride": {
+            "type": "object",
+            "properties": {
+                "default": {
+                    "type": "string",
+                    "example": "geth"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "nethermind"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.Job": {
+            "type": "object",
+            "properties": {
+                "ahead_count": {
+                    "type": "integer"
+                },
+                "artifacts_url": {
+                    "type": "string"
+                },
+                "assigned_at": {
+                    "type": "string"
+                },
+                "assignee": {
+                    "description": "Assignee is the user investigating a failed job, and AssignedAt when\nthey took it. They are written by SetJobAssignee only, never by\nUpdateJob.",
+                    "type": "string"
+                },
+                "auto_requeue": {
+                    "type": "boolean"
+                },
+                "campaign_id": {
+                    "description": "CampaignID is the campaign the job belongs to, if any.",
+                    "type": "string"
+                },
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "error_message": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "string"
+                },
+                "handoff_secrets": {
+                    "description": "HandoffSecrets are the secrets created to pass the job's secret inputs,\nkept until they are deleted after the job finishes. It is written by\nSetJobHandoffSecrets only, never by UpdateJob.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.HandoffSecrets"
+                        }
+                    ]
+                },
+                "heartbeat_at": {
+                    "description": "HeartbeatAt is when the job's workflow last sent a heartbeat or progress\nreport. StalledAt is set while a running job is flagged as stalled. Both\nare written by their own store methods, never by UpdateJob.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "inputs": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_input.Map"
+                },
+                "inputs_overridden": {
+                    "description": "InputsOverridden are the inputs that differ from the current defaults of\nthe job's template, computed when jobs are served by the API; they are\nnot stored.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.InputOverride"
+                    }
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "logs_url": {
+                    "type": "string"
+                },
+                "max_duration_seconds": {
+                    "description": "MaxDurationSeconds overrides the template's MaxDurationSeconds for this\njob, and is the only limit for manual jobs. It is set when the job is\ncreated.",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Override fields (nil/empty means use template value).",
+                    "type": "string"
+                },
+                "not_before": {
+                    "description": "NotBefore is the earliest time the job may be dispatched. Until then it\nkeeps its place in the queue while later jobs are dispatched past it.",
+                    "type": "string"
+                },
+                "notify_targets": {
+                    "description": "NotifyTargets are notified of the job's transitions in addition to its\nsubscribers. They are set when the job is created and by\nSetJobNotifyTargets, never by UpdateJob.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobNotifyTarget"
+                    }
+                },
+                "original_created_by": {
+                    "description": "OriginalCreatedBy is who enqueued the job, set the first time CreatedBy\nis reassigned. Empty means the job was never reassigned.",
+                    "type": "string"
+                },
+                "outputs": {
+                    "description": "Outputs holds structured results reported by the workflow run, captured\nwhen the job completes.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "progress": {
+                    "description": "Progress is the latest progress reported by the job's workflow run. It is\nwritten by UpdateJobProgress only, never by UpdateJob.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobProgress"
+                        }
+                    ]
+                },
+                "queue_position": {
+                    "description": "QueuePosition (1-based) and AheadCount are computed for unpaused pending\njobs when they are served by the API; they are not stored.",
+                    "type": "integer"
+                },
+                "ref": {
+                    "type": "string"
+                },
+                "repo": {
+                    "type": "string"
+                },
+                "requeue_count": {
+                    "type": "integer"
+                },
+                "requeue_limit": {
+                    "type": "integer"
+                },
+                "requeued_from": {
+                    "description": "RequeuedFrom is the ID of the job this job was manually requeued from.",
+                    "type": "string"
+                },
+                "resolved_sha": {
+                    "description": "ResolvedSHA is the commit the job's ref pointed to when it was dispatched.",
+                    "type": "string"
+                },
+                "retained": {
+                    "description": "Retained jobs are never deleted by history retention or the per-group\nhistory cap. It is written by SetJobRetained only, never by UpdateJob.",
+                    "type": "boolean"
+                },
+                "run_attempt": {
+                    "description": "RunAttempt is the attempt of the workflow run last seen, increasing when\nthe run is re-run from GitHub. LogsURL and ArtifactsURL are the API URLs\nof that attempt's logs archive and artifacts. All three are written by\nUpdateJobRunAttempt only, never by UpdateJob.",
+                    "type": "integer"
+                },
+                "run_id": {
+                    "type": "integer"
+                },
+                "run_url": {
+                    "type": "string"
+                },
+                "runner_id": {
+                    "type": "integer"
+                },
+                "runner_name": {
+                    "type": "string"
+                },
+                "source_issue": {
+                    "description": "SourceIssue is the GitHub issue the job was requested in, as\nowner/repo#number. The issue is closed once the job finishes.",
+                    "type": "string"
+                },
+                "stalled_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobStatus"
+                },
+                "template_id": {
+                    "type": "string"
+                },
+                "triggered_at": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "variant": {
+                    "description": "Variant is the JobVariant a job of a canary template was dispatched as,\npicked on its first dispatch. It is written by SetJobVariant only, never\nby UpdateJob.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobVariant"
+                        }
+                    ]
+                },
+                "workflow_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.JobAnnotation": {
+            "type": "object",
+            "properties": {
+                "end_line": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "start_line": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "workflow_job": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.JobEvent": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "Actor is the user behind the transition, or \"system\" for the dispatcher and tracker.",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from_status": {
+                    "description": "FromStatus is empty for the event that created the job.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobStatus"
+                        }
+                    ]
+                },
+                "id": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "run_id": {
+                    "type": "integer"
+                },
+                "runner_name": {
+                    "type": "string"
+                },
+                "to_status": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobStatus"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.JobNotifyTarget": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.SubscriptionChannel"
+                },
+                "destination": {
+                    "description": "Destination is an email address for email, or a URL for slack and webhook.",
+                    "type": "string"
+                },
+                "statuses": {
+                    "description": "empty = every transition",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobStatus"
+                    }
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.JobProgress": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "percent": {
+                    "description": "Percent is the completion percentage (0-100), if the workflow knows it.",
+                    "type": "integer"
+                },
+                "stage": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.JobRunAttempt": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "completed_at": {
+                    "type": "string"
+                },
+                "error_message": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "logs_url": {
+                    "type": "string"
+                },
+                "runner_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is how the attempt ended: failed or cancelled.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.JobStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "triggered",
+                "running",
+                "completed",
+                "failed",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "JobStatusPending",
+                "JobStatusTriggered",
+                "JobStatusRunning",
+                "JobStatusCompleted",
+                "JobStatusFailed",
+                "JobStatusCancelled"
+            ]
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.JobTemplate": {
+            "type": "object",
+            "properties": {
+                "canary_percent": {
+                    "description": "CanaryPercent of the template's jobs are dispatched with\nCanaryWorkflowID and CanaryRef in place of WorkflowID and Ref, where\nset (0 = no canary).",
+                    "type": "integer"
+                },
+                "canary_ref": {
+                    "type": "string"
+                },
+                "canary_workflow_id": {
+                    "type": "string"
+                },
+                "category": {
+                    "description": "optional UI grouping",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "default_inputs": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_input.Map"
+                },
+                "default_priority": {
+                    "description": "DefaultPriority is the priority of jobs enqueued from the template.",
+                    "type": "integer"
+                },
+                "deprecated": {
+                    "type": "boolean"
+                },
+                "dispatch_windows": {
+                    "description": "DispatchWindows restricts when the template's jobs may be dispatched.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_schedule.Window"
+                    }
+                },
+                "display_order": {
+                    "description": "sort key within the group, then by name",
+                    "type": "integer"
+                },
+                "environment": {
+                    "description": "GitHub deployment environment used by the workflow",
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "in_config": {
+                    "type": "boolean"
+                },
+                "keep_running": {
+                    "description": "KeepRunning is the number of the template's jobs the dispatcher keeps\npending, triggered or running, enqueuing a new job as each finishes\n(0 = disabled).",
+                    "type": "integer"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "max_duration_seconds": {
+                    "description": "MaxDurationSeconds cancels the template's running jobs and fails them\nwith a timeout once they have run for this long since being dispatched\n(0 = no limit).",
+                    "type": "integer"
+                },
+                "min_idle_runners": {
+                    "description": "MinIdleRunners is the number of idle matching runners required before the\ntemplate's jobs are dispatched (0 or 1 = any idle runner).",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "overrides": {
+                    "description": "Overrides are fields of a template from config changed through the API;\nconfig syncs apply them over the config (nil = none).",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.TemplateOverrides"
+                        }
+                    ]
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "pinned_inputs": {
+                    "description": "PinnedInputs are default input keys that jobs may not override.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "ref": {
+                    "type": "string"
+                },
+                "repo": {
+                    "type": "string"
+                },
+                "secret_handoff_environment": {
+                    "type": "boolean"
+                },
+                "secret_handoff_inputs": {
+                    "description": "SecretHandoffInputs are written to short-lived GitHub Actions secrets at\ndispatch, and the workflow receives the secrets' names in their place.\nWith SecretHandoffEnvironment the secrets belong to Environment rather\nthan the repository.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "source_path": {
+                    "description": "filename or URL (empty for inline)",
+                    "type": "string"
+                },
+                "source_type": {
+                    "description": "\"inline\", \"file\", \"url\", \"git\" or \"api\"",
+                    "type": "string"
+                },
+                "stall_timeout_seconds": {
+                    "description": "StallTimeoutSeconds flags the template's running jobs as stalled when\nneither a heartbeat nor their GitHub run changed for this long\n(0 = disabled).",
+                    "type": "integer"
+                },
+                "sunset_at": {
+                    "description": "enqueues are rejected after this time",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "workflow_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.JobVariant": {
+            "type": "string",
+            "enum": [
+                "stable",
+                "canary"
+            ],
+            "x-enum-varnames": [
+                "JobVariantStable",
+                "JobVariantCanary"
+            ]
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.LintIssue": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "input": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "severity": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.LintSeverity"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.LintSeverity": {
+            "type": "string",
+            "enum": [
+                "error",
+                "warning"
+            ],
+            "x-enum-varnames": [
+                "LintSeverityError",
+                "LintSeverityWarning"
+            ]
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.QueueChange": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.QueueSnapshotEntry"
+                    }
+                },
+                "before": {
+                    "description": "Before and After hold the affected jobs in dispatch order. After is empty\nfor deletes.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.QueueSnapshotEntry"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "description": "JobID is the job changed, empty for reorders which affect the whole queue.",
+                    "type": "string"
+                },
+                "operation": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.QueueChangeOperation"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.QueueChangeOperation": {
+            "type": "string",
+            "enum": [
+                "reorder",
+                "pause",
+                "unpause",
+                "priority",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "QueueChangeReorder",
+                "QueueChangePause",
+                "QueueChangeUnpause",
+                "QueueChangePriority",
+                "QueueChangeDelete"
+            ]
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.QueueSnapshotEntry": {
+            "type": "object",
+            "properties": {
+                "job_id": {
+                    "type": "string"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "priority": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.Role": {
+            "type": "string",
+            "enum": [
+                "readonly",
+                "admin"
+            ],
+            "x-enum-varnames": [
+                "RoleReadOnly",
+                "RoleAdmin"
+            ]
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.Runner": {
+            "type": "object",
+            "properties": {
+                "busy": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "hosted": {
+                    "description": "Hosted marks a GitHub-hosted larger runner. It is a pool that runs up\nto MaxRunners jobs at once, and is busy once that many are in flight.",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "max_runners": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "os": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.RunnerStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.RunnerStatus": {
+            "type": "string",
+            "enum": [
+                "online",
+                "offline"
+            ],
+            "x-enum-varnames": [
+                "RunnerStatusOnline",
+                "RunnerStatusOffline"
+            ]
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.SavedFilter": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "description": "empty = any group",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "template labels (AND logic)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "statuses": {
+                    "description": "empty = all statuses of the view",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobStatus"
+                    }
+                },
+                "template_id": {
+                    "description": "empty = all templates",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "view": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.SavedFilterView"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.SavedFilterView": {
+            "type": "string",
+            "enum": [
+                "queue",
+                "history"
+            ],
+            "x-enum-varnames": [
+                "SavedFilterViewQueue",
+                "SavedFilterViewHistory"
+            ]
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.Setting": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.Subscription": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.SubscriptionChannel"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "destination": {
+                    "description": "Destination is an email address for email, or a URL for slack and webhook.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "statuses": {
+                    "description": "empty = every transition",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobStatus"
+                    }
+                },
+                "target_id": {
+                    "type": "string"
+                },
+                "target_type": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.SubscriptionTarget"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.SubscriptionChannel": {
+            "type": "string",
+            "enum": [
+                "email",
+                "slack",
+                "webhook"
+            ],
+            "x-enum-varnames": [
+                "SubscriptionChannelEmail",
+                "SubscriptionChannelSlack",
+                "SubscriptionChannelWebhook"
+            ]
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.SubscriptionTarget": {
+            "type": "string",
+            "enum": [
+                "job",
+                "template"
+            ],
+            "x-enum-varnames": [
+                "SubscriptionTargetJob",
+                "SubscriptionTargetTemplate"
+            ]
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.TableStats": {
+            "type": "object",
+            "properties": {
+                "rows": {
+                    "description": "Rows is exact for SQLite and the planner's live row estimate for\nPostgreSQL.",
+                    "type": "integer"
+                },
+                "size_bytes": {
+                    "description": "SizeBytes includes indexes and, for PostgreSQL, TOAST data. It is left\nout when SQLite is built without the dbstat table.",
+                    "type": "integer"
+                },
+                "table": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.TemplateLint": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.LintIssue"
+                    }
+                },
+                "ok": {
+                    "type": "boolean"
+                },
+                "ref": {
+                    "type": "string"
+                },
+                "template_id": {
+                    "type": "string"
+                },
+                "workflow_path": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.TemplateOverrides": {
+            "type": "object",
+            "properties": {
+                "owner": {
+                    "type": "string",
+                    "example": "ethpandaops"
+                },
+                "ref": {
+                    "type": "string",
+                    "example": "main"
+                },
+                "repo": {
+                    "type": "string",
+                    "example": "syncoor-tests"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string",
+                    "example": "alice"
+                }
+            }
+        },
+        "github_com_ethpandaops_dispatchoor_pkg_store.User": {
+            "type": "object",
+            "properties": {
+                "auth_provider": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.AuthProvider"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "github_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Role"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "pkg_api.AddJobRequest": {
+            "type": "object",
+            "properties": {
+                "auto_requeue": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "campaign_id": {
+                    "description": "CampaignID adds the job to an existing campaign.",
+                    "type": "string",
+                    "example": "5f0c6a3e-8d1b-4c3e-9f4a-2b7d1e6c9a10"
+                },
+                "inputs": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_input.Map"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "max_duration_seconds": {
+                    "description": "MaxDurationSeconds overrides the template's max_duration; the job's run\nis cancelled and the job failed once it runs longer (0 = no limit).",
+                    "type": "integer",
+                    "example": 3600
+                },
+                "name": {
+                    "description": "Manual job fields (used when template_id is empty).",
+                    "type": "string",
+                    "example": "Manual Job"
+                },
+                "not_before": {
+                    "description": "NotBefore holds the job in the queue until the given time, for a\none-off scheduled run.",
+                    "type": "string",
+                    "example": "2026-10-14T22:00:00Z"
+                },
+                "notify_targets": {
+                    "description": "NotifyTargets are notified of the job's transitions in addition to its\nsubscribers.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobNotifyTarget"
+                    }
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "ethpandaops"
+                },
+                "priority": {
+                    "description": "Priority overrides the template's default priority, up to the group's max_priority.",
+                    "type": "integer",
+                    "example": 10
+                },
+                "ref": {
+                    "type": "string",
+                    "example": "main"
+                },
+                "repo": {
+                    "type": "string",
+                    "example": "dispatchoor"
+                },
+                "requeue_limit": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "template_id": {
+                    "type": "string",
+                    "example": "my-template"
+                },
+                "workflow_id": {
+                    "type": "string",
+                    "example": "deploy.yml"
+                }
+            }
+        },
+        "pkg_api.AssignJobRequest": {
+            "type": "object",
+            "properties": {
+                "assignee": {
+                    "description": "Assignee is the user investigating the job. It defaults to the caller;\nonly admins may assign someone else.",
+                    "type": "string",
+                    "example": "alice"
+                }
+            }
+        },
+        "pkg_api.BulkEditTemplatesRequest": {
+            "type": "object",
+            "properties": {
+                "owner": {
+                    "type": "string",
+                    "example": "ethpandaops"
+                },
+                "ref": {
+                    "type": "string",
+                    "example": "main"
+                },
+                "repo": {
+                    "type": "string",
+                    "example": "syncoor-tests"
+                },
+                "reset": {
+                    "description": "Reset drops the overrides of templates from config, returning them to\nthe owner, repo and ref in the config.",
+                    "type": "boolean"
+                },
+                "template_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "sync-geth-prysm",
+                        "sync-geth-lighthouse"
+                    ]
+                }
+            }
+        },
+        "pkg_api.BulkEditTemplatesResponse": {
+            "type": "object",
+            "properties": {
+                "templates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobTemplate"
+                    }
+                }
+            }
+        },
+        "pkg_api.CampaignActionResponse": {
+            "type": "object",
+            "properties": {
+                "affected": {
+                    "description": "Affected is the number of jobs the action was applied to.",
+                    "type": "integer",
+                    "example": 4
+                },
+                "errors": {
+                    "description": "Errors lists the jobs the action failed for.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "progress": {
+                    "$ref": "#/definitions/pkg_api.CampaignProgress"
+                }
+            }
+        },
+        "pkg_api.CampaignJobRequest": {
+            "type": "object",
+            "properties": {
+                "group_id": {
+                    "type": "string",
+                    "example": "sync-tests"
+                },
+                "inputs": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_input.Map"
+                },
+                "template_id": {
+                    "type": "string",
+                    "example": "sync-hoodi"
+                }
+            }
+        },
+        "pkg_api.CampaignProgress": {
+            "type": "object",
+            "properties": {
+                "cancelled": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "completed": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "finished": {
+                    "description": "Finished is the number of completed, failed and cancelled jobs.",
+                    "type": "integer",
+                    "example": 5
+                },
+                "paused": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "pending": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "running": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "triggered": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "pkg_api.CampaignResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                    }
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "progress": {
+                    "$ref": "#/definitions/pkg_api.CampaignProgress"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "pkg_api.CloneTemplateRequest": {
+            "type": "object",
+            "properties": {
+                "group_id": {
+                    "description": "GroupID to create the template in (default: the source's group).",
+                    "type": "string",
+                    "example": "sync-tests"
+                },
+                "id": {
+                    "description": "ID of the new template.",
+                    "type": "string",
+                    "example": "sync-geth-prysm-holesky"
+                },
+                "name": {
+                    "description": "Name of the new template (default: the source name with \" (copy)\").",
+                    "type": "string",
+                    "example": "Sync Test geth/prysm (holesky)"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "ethpandaops"
+                },
+                "ref": {
+                    "type": "string",
+                    "example": "main"
+                },
+                "repo": {
+                    "type": "string",
+                    "example": "syncoor-tests"
+                }
+            }
+        },
+        "pkg_api.CompactQueueResponse": {
+            "type": "object",
+            "properties": {
+                "updated": {
+                    "description": "Updated is the number of pending jobs whose position changed.",
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "pkg_api.ComponentStatus": {
+            "type": "string",
+            "enum": [
+                "healthy",
+                "degraded",
+                "unhealthy"
+            ],
+            "x-enum-varnames": [
+                "ComponentStatusHealthy",
+                "ComponentStatusDegraded",
+                "ComponentStatusUnhealthy"
+            ]
+        },
+        "pkg_api.ConfigSyncDiff": {
+            "type": "object",
+            "properties": {
+                "groups_created": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.ConfigSyncItem"
+                    }
+                },
+                "groups_removed": {
+                    "description": "GroupsRemoved are in the running config but not in the new one. Sync\nkeeps them in the database; archive them to stop dispatching them.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.ConfigSyncItem"
+                    }
+                },
+                "groups_updated": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.ConfigSyncItem"
+                    }
+                },
+                "templates_created": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.ConfigSyncItem"
+                    }
+                },
+                "templates_deleted": {
+                    "description": "TemplatesDeleted are removed from the config and have no jobs, so they\nare deleted.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.ConfigSyncItem"
+                    }
+                },
+                "templates_retired": {
+                    "description": "TemplatesRetired are removed from the config but keep their job history,\nso they are marked as not in config.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.ConfigSyncItem"
+                    }
+                },
+                "templates_updated": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.ConfigSyncItem"
+                    }
+                }
+            }
+        },
+        "pkg_api.ConfigSyncItem": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "description": "Fields lists the changed fields of an updated group or template.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ref",
+                        "default_inputs"
+                    ]
+                },
+                "group_id": {
+                    "type": "string",
+                    "example": "sync-tests"
+                },
+                "template_id": {
+                    "type": "string",
+                    "example": "sync-hoodi"
+                }
+            }
+        },
+        "pkg_api.CreateCampaignRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Sync every client pair on hoodi"
+                },
+                "jobs": {
+                    "description": "Jobs are enqueued in the campaign. More jobs can be added later with\ncampaign_id on the add job endpoint.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.CampaignJobRequest"
+                    }
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Fusaka sync tests"
+                }
+            }
+        },
+        "pkg_api.DatabaseStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latency": {
+                    "type": "string"
+                },
+                "maintenance": {
+                    "description": "Maintenance is set when database.maintenance is enabled.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkg_api.MaintenanceStatus"
+                        }
+                    ]
+                },
+                "status": {
+                    "$ref": "#/definitions/pkg_api.ComponentStatus"
+                }
+            }
+        },
+        "pkg_api.DurationChange": {
+            "type": "object",
+            "properties": {
+                "a_seconds": {
+                    "type": "integer",
+                    "example": 3600
+                },
+                "b_seconds": {
+                    "type": "integer",
+                    "example": 5400
+                },
+                "delta_seconds": {
+                    "description": "DeltaSeconds is b minus a, set when both durations are known.",
+                    "type": "integer",
+                    "example": 1800
+                }
+            }
+        },
+        "pkg_api.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Something went wrong"
+                }
+            }
+        },
+        "pkg_api.FieldChange": {
+            "type": "object",
+            "properties": {
+                "a": {
+                    "type": "string",
+                    "example": "main"
+                },
+                "b": {
+                    "type": "string",
+                    "example": "feature"
+                },
+                "changed": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "pkg_api.GitHubClientStatus": {
+            "type": "object",
+            "properties": {
+                "connected": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "rate_limit_remaining": {
+                    "type": "integer"
+                },
+                "rate_limit_reset": {
+                    "type": "string"
+                },
+                "reset_in": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/pkg_api.ComponentStatus"
+                }
+            }
+        },
+        "pkg_api.GitHubClientsStatus": {
+            "type": "object",
+            "properties": {
+                "dispatch": {
+                    "$ref": "#/definitions/pkg_api.GitHubClientStatus"
+                },
+                "runners": {
+                    "$ref": "#/definitions/pkg_api.GitHubClientStatus"
+                }
+            }
+        },
+        "pkg_api.GroupResponse": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "description": "not dispatched or listed, history kept",
+                    "type": "boolean"
+                },
+                "archived_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "description_html": {
+                    "description": "DescriptionHTML is the markdown description rendered as HTML, without\nraw HTML, images or unsafe links.",
+                    "type": "string",
+                    "example": "\u003cp\u003eEthereum sync testing jobs\u003c/p\u003e"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "max_priority": {
+                    "description": "MaxPriority caps the priority of the group's jobs (nil = no cap).",
+                    "type": "integer"
+                },
+                "max_requeue_limit": {
+                    "description": "MaxRequeueLimit caps the requeue limit of auto-requeue jobs; with a cap\nset, no job requeues forever (nil = no cap).",
+                    "type": "integer"
+                },
+                "metadata": {
+                    "description": "Metadata is context shown on the group's queue page (nil = none).",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.GroupMetadata"
+                        }
+                    ]
+                },
+                "min_online_runners": {
+                    "description": "MinOnlineRunners is the number of online matching runners below which\ndispatch is held (0 = no minimum).",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "paused_reason": {
+                    "description": "set when paused automatically",
+                    "type": "string"
+                },
+                "resumed_at": {
+                    "description": "last manual unpause",
+                    "type": "string"
+                },
+                "runner_labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
+                },
+                "scheduling_policy": {
+                    "description": "SchedulingPolicy orders the group's pending jobs for dispatch (empty = fifo).",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
-        }
-    },
-    "definitions": {
-        "github_com_ethpandaops_dispatchoor_pkg_store.AuthProvider": {
-            "type": "string",
-            "enum": [
-                "basic",
-                "github"
-            ],
-            "x-enum-varnames": [
-                "AuthProviderBasic",
-                "AuthProviderGitHub"
-            ]
         },
-        "github_com_ethpandaops_dispatchoor_pkg_store.Group": {
+        "pkg_api.GroupWithStats": {
             "type": "object",
             "properties": {
+                "archived": {
+                    "description": "not dispatched or listed, history kept",
+                    "type": "boolean"
+                },
+                "archived_at": {
+                    "type": "string"
+                },
+                "busy_runners": {
+                    "type": "integer",
+                    "example": 2
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "degraded": {
+                    "description": "Degraded is set while fewer runners are online than min_online_runners,\nwhich holds dispatch for the group.",
+                    "type": "boolean"
+                },
                 "description": {
                     "type": "string"
                 },
+                "description_html": {
+                    "description": "DescriptionHTML is the markdown description rendered as HTML, without\nraw HTML, images or unsafe links.",
+                    "type": "string",
+                    "example": "\u003cp\u003eEthereum sync testing jobs\u003c/p\u003e"
+                },
                 "enabled": {
                     "type": "boolean"
                 },
                 "id": {
                     "type": "string"
                 },
+                "idle_runners": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "last_24h": {
+                    "description": "Last24h counts the jobs that finished in the last 24 hours.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkg_api.HistoryStatsTotals"
+                        }
+                    ]
+                },
+                "max_priority": {
+                    "description": "MaxPriority caps the priority of the group's jobs (nil = no cap).",
+                    "type": "integer"
+                },
+                "max_requeue_limit": {
+                    "description": "MaxRequeueLimit caps the requeue limit of auto-requeue jobs; with a cap\nset, no job requeues forever (nil = no cap).",
+                    "type": "integer"
+                },
+                "metadata": {
+                    "description": "Metadata is context shown on the group's queue page (nil = none).",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.GroupMetadata"
+                        }
+                    ]
+                },
+                "min_online_runners": {
+                    "description": "MinOnlineRunners is the number of online matching runners below which\ndispatch is held (0 = no minimum).",
+                    "type": "integer"
+                },
                 "name": {
                     "type": "string"
                 },
-                "paused": {
-                    "type": "boolean"
+                "oldest_pending_age_seconds": {
+                    "description": "Age of the oldest unpaused pending job; 0 when nothing is waiting.",
+                    "type": "integer",
+                    "example": 120
+                },
+                "oldest_pending_job_id": {
+                    "type": "string"
+                },
+                "online_runners": {
+                    "description": "OnlineRunners counts matching runners that are online, busy or not.",
+                    "type": "integer",
+                    "example": 5
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "paused_reason": {
+                    "description": "set when paused automatically",
+                    "type": "string"
+                },
+                "queued_jobs": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "resumed_at": {
+                    "description": "last manual unpause",
+                    "type": "string"
+                },
+                "runner_labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "running_jobs": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "scheduling_policy": {
+                    "description": "SchedulingPolicy orders the group's pending jobs for dispatch (empty = fifo).",
+                    "type": "string"
+                },
+                "starved": {
+                    "description": "Starved is set when the oldest pending age exceeds queue.starvation.threshold.",
+                    "type": "boolean"
+                },
+                "template_count": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "total_runners": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "pkg_api.HealthAuthConfig": {
+            "type": "object",
+            "properties": {
+                "basic": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "github": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "pkg_api.HealthConfig": {
+            "type": "object",
+            "properties": {
+                "auth": {
+                    "$ref": "#/definitions/pkg_api.HealthAuthConfig"
+                }
+            }
+        },
+        "pkg_api.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "config": {
+                    "$ref": "#/definitions/pkg_api.HealthConfig"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "pkg_api.HistoryResponse": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "next_cursor_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "total_count": {
+                    "description": "TotalCount is omitted when the request sets count=false. TotalCountCached\nis set when it was served from a short-lived cache.",
+                    "type": "integer",
+                    "example": 150
+                },
+                "total_count_cached": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "pkg_api.HistoryStatsBucket": {
+            "type": "object",
+            "properties": {
+                "cancelled": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "completed": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2024-01-15T10:00:00Z"
+                }
+            }
+        },
+        "pkg_api.HistoryStatsRange": {
+            "type": "object",
+            "properties": {
+                "bucket_duration": {
+                    "type": "string",
+                    "example": "1h0m0s"
+                },
+                "end": {
+                    "type": "string",
+                    "example": "2024-01-16T00:00:00Z"
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2024-01-15T00:00:00Z"
+                }
+            }
+        },
+        "pkg_api.HistoryStatsResponse": {
+            "type": "object",
+            "properties": {
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.HistoryStatsBucket"
+                    }
+                },
+                "range": {
+                    "$ref": "#/definitions/pkg_api.HistoryStatsRange"
                 },
-                "runner_labels": {
+                "series": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/pkg_api.HistoryStatsSeries"
                     }
                 },
-                "updated_at": {
-                    "type": "string"
+                "totals": {
+                    "$ref": "#/definitions/pkg_api.HistoryStatsTotals"
                 }
             }
         },
-        "github_com_ethpandaops_dispatchoor_pkg_store.Job": {
+        "pkg_api.HistoryStatsSeries": {
             "type": "object",
             "properties": {
-                "auto_requeue": {
-                    "type": "boolean"
-                },
-                "completed_at": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.HistoryStatsBucket"
+                    }
                 },
-                "created_by": {
-                    "type": "string"
+                "key": {
+                    "type": "string",
+                    "example": "sync-test-hoodi"
                 },
-                "error_message": {
-                    "type": "string"
+                "totals": {
+                    "$ref": "#/definitions/pkg_api.HistoryStatsTotals"
+                }
+            }
+        },
+        "pkg_api.HistoryStatsTotals": {
+            "type": "object",
+            "properties": {
+                "cancelled": {
+                    "type": "integer",
+                    "example": 5
                 },
-                "group_id": {
-                    "type": "string"
+                "completed": {
+                    "type": "integer",
+                    "example": 120
                 },
-                "id": {
-                    "type": "string"
+                "failed": {
+                    "type": "integer",
+                    "example": 15
+                }
+            }
+        },
+        "pkg_api.InputChange": {
+            "type": "object",
+            "properties": {
+                "a": {
+                    "type": "string",
+                    "example": "geth"
                 },
-                "inputs": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "b": {
+                    "type": "string",
+                    "example": "nethermind"
                 },
-                "labels": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
+                "key": {
+                    "type": "string",
+                    "example": "el-client"
+                }
+            }
+        },
+        "pkg_api.JobAttemptsResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Attempts holds the earlier attempts that finished before the run was\nre-run from GitHub, oldest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobRunAttempt"
                     }
                 },
-                "name": {
-                    "description": "Override fields (nil/empty means use template value).",
-                    "type": "string"
-                },
-                "owner": {
+                "job_id": {
                     "type": "string"
                 },
-                "paused": {
-                    "type": "boolean"
-                },
-                "position": {
-                    "type": "integer"
-                },
-                "priority": {
-                    "type": "integer"
-                },
-                "ref": {
-                    "type": "string"
+                "run_attempt": {
+                    "description": "RunAttempt is the current attempt, 0 before the run was tracked.",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "pkg_api.JobComparison": {
+            "type": "object",
+            "properties": {
+                "a": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
                 },
-                "repo": {
-                    "type": "string"
+                "b": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
                 },
-                "requeue_count": {
-                    "type": "integer"
+                "conclusion": {
+                    "$ref": "#/definitions/pkg_api.FieldChange"
                 },
-                "requeue_limit": {
-                    "type": "integer"
+                "duration": {
+                    "description": "Duration is the time from trigger to completion of each job.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkg_api.DurationChange"
+                        }
+                    ]
                 },
-                "run_id": {
-                    "type": "integer"
+                "error_message": {
+                    "$ref": "#/definitions/pkg_api.FieldChange"
                 },
-                "run_url": {
-                    "type": "string"
+                "inputs": {
+                    "description": "Inputs lists the inputs whose values differ, sorted by key.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.InputChange"
+                    }
                 },
-                "runner_id": {
-                    "type": "integer"
+                "ref": {
+                    "description": "Ref is the ref each job was dispatched with, the template's unless overridden.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkg_api.FieldChange"
+                        }
+                    ]
                 },
-                "runner_name": {
-                    "type": "string"
+                "resolved_sha": {
+                    "$ref": "#/definitions/pkg_api.FieldChange"
                 },
-                "status": {
-                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobStatus"
+                "runner": {
+                    "$ref": "#/definitions/pkg_api.FieldChange"
                 },
                 "template_id": {
-                    "type": "string"
-                },
-                "triggered_at": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
-                },
-                "workflow_id": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "sync-test-hoodi-geth-prysm"
                 }
             }
         },
-        "github_com_ethpandaops_dispatchoor_pkg_store.JobStatus": {
-            "type": "string",
-            "enum": [
-                "pending",
-                "triggered",
-                "running",
-                "completed",
-                "failed",
-                "cancelled"
-            ],
-            "x-enum-varnames": [
-                "JobStatusPending",
-                "JobStatusTriggered",
-                "JobStatusRunning",
-                "JobStatusCompleted",
-                "JobStatusFailed",
-                "JobStatusCancelled"
-            ]
-        },
-        "github_com_ethpandaops_dispatchoor_pkg_store.JobTemplate": {
+        "pkg_api.LoginRequest": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "default_inputs": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "password": {
+                    "type": "string",
+                    "example": "password123"
                 },
-                "group_id": {
+                "username": {
+                    "type": "string",
+                    "example": "admin"
+                }
+            }
+        },
+        "pkg_api.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
                     "type": "string"
                 },
-                "id": {
+                "refresh_token": {
+                    "description": "RefreshToken and ExpiresAt are set with JWT sessions, where Token is a\nshort-lived access token to renew at POST /auth/refresh.",
                     "type": "string"
                 },
-                "in_config": {
-                    "type": "boolean"
-                },
-                "labels": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIs..."
                 },
-                "name": {
+                "user": {
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.User"
+                }
+            }
+        },
+        "pkg_api.MaintenanceStatus": {
+            "type": "object",
+            "properties": {
+                "duration": {
                     "type": "string"
                 },
-                "owner": {
+                "error": {
                     "type": "string"
                 },
-                "ref": {
-                    "type": "string"
+                "interval": {
+                    "type": "string",
+                    "example": "24h0m0s"
                 },
-                "repo": {
+                "last_run_at": {
                     "type": "string"
                 },
-                "source_path": {
-                    "description": "filename or URL (empty for inline)",
+                "next_run_at": {
                     "type": "string"
                 },
-                "source_type": {
-                    "description": "\"inline\", \"file\", or \"url\"",
-                    "type": "string"
+                "status": {
+                    "$ref": "#/definitions/pkg_api.ComponentStatus"
                 },
-                "updated_at": {
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.MaintenanceTaskStatus"
+                    }
+                }
+            }
+        },
+        "pkg_api.MaintenanceTaskStatus": {
+            "type": "object",
+            "properties": {
+                "duration": {
                     "type": "string"
                 },
-                "workflow_id": {
+                "error": {
                     "type": "string"
+                },
+                "task": {
+                    "type": "string",
+                    "example": "VACUUM ANALYZE jobs"
                 }
             }
         },
-        "github_com_ethpandaops_dispatchoor_pkg_store.Role": {
-            "type": "string",
-            "enum": [
-                "readonly",
-                "admin"
-            ],
-            "x-enum-varnames": [
-                "RoleReadOnly",
-                "RoleAdmin"
-            ]
-        },
-        "github_com_ethpandaops_dispatchoor_pkg_store.Runner": {
+        "pkg_api.MatchingReportResponse": {
             "type": "object",
             "properties": {
-                "busy": {
+                "dispatcher_enabled": {
                     "type": "boolean"
                 },
-                "created_at": {
+                "generated_at": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "labels": {
+                "groups": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_dispatcher.GroupMatchReport"
                     }
-                },
-                "last_seen_at": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "os": {
-                    "type": "string"
-                },
-                "status": {
-                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.RunnerStatus"
-                },
-                "updated_at": {
-                    "type": "string"
                 }
             }
         },
-        "github_com_ethpandaops_dispatchoor_pkg_store.RunnerStatus": {
-            "type": "string",
-            "enum": [
-                "online",
-                "offline"
-            ],
-            "x-enum-varnames": [
-                "RunnerStatusOnline",
-                "RunnerStatusOffline"
-            ]
-        },
-        "github_com_ethpandaops_dispatchoor_pkg_store.User": {
+        "pkg_api.MeResponse": {
             "type": "object",
             "properties": {
                 "auth_provider": {
@@ -1931,6 +6900,9 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
+                "impersonated_by": {
+                    "type": "string"
+                },
                 "role": {
                     "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Role"
                 },
@@ -1942,399 +6914,579 @@ const docTemplate = `{
                 }
             }
         },
-        "pkg_api.AddJobRequest": {
+        "pkg_api.OverviewResponse": {
             "type": "object",
             "properties": {
-                "auto_requeue": {
-                    "type": "boolean",
-                    "example": false
-                },
-                "inputs": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.GroupWithStats"
                     }
                 },
-                "labels": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
+                "recent_failures": {
+                    "description": "RecentFailures are the latest jobs of any group that failed in the last\n24 hours, most recent first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
                     }
                 },
-                "name": {
-                    "description": "Manual job fields (used when template_id is empty).",
-                    "type": "string",
-                    "example": "Manual Job"
-                },
-                "owner": {
-                    "type": "string",
-                    "example": "ethpandaops"
-                },
-                "ref": {
-                    "type": "string",
-                    "example": "main"
-                },
-                "repo": {
-                    "type": "string",
-                    "example": "dispatchoor"
-                },
-                "requeue_limit": {
-                    "type": "integer",
-                    "example": 3
-                },
-                "template_id": {
-                    "type": "string",
-                    "example": "my-template"
+                "runners": {
+                    "$ref": "#/definitions/pkg_api.RunnerSummary"
                 },
-                "workflow_id": {
-                    "type": "string",
-                    "example": "deploy.yml"
+                "system": {
+                    "$ref": "#/definitions/pkg_api.SystemStatusResponse"
                 }
             }
         },
-        "pkg_api.ComponentStatus": {
-            "type": "string",
-            "enum": [
-                "healthy",
-                "degraded",
-                "unhealthy"
-            ],
-            "x-enum-varnames": [
-                "ComponentStatusHealthy",
-                "ComponentStatusDegraded",
-                "ComponentStatusUnhealthy"
-            ]
-        },
-        "pkg_api.DatabaseStatus": {
+        "pkg_api.PermissionStatus": {
             "type": "object",
             "properties": {
                 "error": {
                     "type": "string"
                 },
-                "latency": {
-                    "type": "string"
+                "ok": {
+                    "type": "boolean"
                 },
-                "status": {
-                    "$ref": "#/definitions/pkg_api.ComponentStatus"
+                "permission": {
+                    "type": "string",
+                    "example": "actions:write"
+                },
+                "target": {
+                    "type": "string",
+                    "example": "ethpandaops/ethereum-package"
+                },
+                "templates": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "pkg_api.ErrorResponse": {
+        "pkg_api.QueueChangesResponse": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "Something went wrong"
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.QueueChange"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
                 }
             }
         },
-        "pkg_api.GitHubClientStatus": {
+        "pkg_api.QueueStats": {
             "type": "object",
             "properties": {
-                "connected": {
-                    "type": "boolean"
-                },
-                "error": {
-                    "type": "string"
-                },
-                "rate_limit_remaining": {
+                "pending_jobs": {
                     "type": "integer"
                 },
-                "rate_limit_reset": {
-                    "type": "string"
-                },
-                "reset_in": {
-                    "type": "string"
+                "running_jobs": {
+                    "type": "integer"
                 },
-                "status": {
-                    "$ref": "#/definitions/pkg_api.ComponentStatus"
+                "triggered_jobs": {
+                    "type": "integer"
                 }
             }
         },
-        "pkg_api.GitHubClientsStatus": {
+        "pkg_api.QueueStatsPoint": {
             "type": "object",
             "properties": {
-                "dispatch": {
-                    "$ref": "#/definitions/pkg_api.GitHubClientStatus"
+                "idle_runners": {
+                    "type": "number",
+                    "example": 0.4
                 },
-                "runners": {
-                    "$ref": "#/definitions/pkg_api.GitHubClientStatus"
+                "max_pending": {
+                    "type": "integer",
+                    "example": 1224
+                },
+                "min_idle_runners": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "pending": {
+                    "type": "number",
+                    "example": 1180.5
+                },
+                "running": {
+                    "type": "number",
+                    "example": 4
+                },
+                "samples": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "time": {
+                    "type": "string"
                 }
             }
         },
-        "pkg_api.GroupWithStats": {
+        "pkg_api.QueueStatsResponse": {
             "type": "object",
             "properties": {
-                "busy_runners": {
+                "bucket_seconds": {
                     "type": "integer",
-                    "example": 2
-                },
-                "created_at": {
-                    "type": "string"
+                    "example": 900
                 },
-                "description": {
+                "end": {
                     "type": "string"
                 },
-                "enabled": {
-                    "type": "boolean"
+                "group_id": {
+                    "type": "string",
+                    "example": "sync-tests"
                 },
-                "id": {
-                    "type": "string"
+                "points": {
+                    "description": "Points holds the buckets with at least one sample, oldest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.QueueStatsPoint"
+                    }
                 },
-                "idle_runners": {
-                    "type": "integer",
-                    "example": 3
+                "range": {
+                    "type": "string",
+                    "example": "24h"
                 },
-                "name": {
+                "start": {
                     "type": "string"
+                }
+            }
+        },
+        "pkg_api.RateLimitErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "rate limit exceeded"
+                }
+            }
+        },
+        "pkg_api.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "description": "RefreshToken defaults to the refresh_token cookie.",
+                    "type": "string"
+                }
+            }
+        },
+        "pkg_api.ReloadTemplatesGroupStats": {
+            "type": "object",
+            "properties": {
+                "group_id": {
+                    "type": "string",
+                    "example": "my-group"
                 },
-                "paused": {
-                    "type": "boolean"
-                },
-                "queued_jobs": {
+                "templates": {
                     "type": "integer",
                     "example": 5
-                },
-                "runner_labels": {
+                }
+            }
+        },
+        "pkg_api.ReloadTemplatesResponse": {
+            "type": "object",
+            "properties": {
+                "groups": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/pkg_api.ReloadTemplatesGroupStats"
                     }
                 },
-                "running_jobs": {
-                    "type": "integer",
-                    "example": 2
-                },
-                "template_count": {
-                    "type": "integer",
-                    "example": 10
-                },
-                "total_runners": {
-                    "type": "integer",
-                    "example": 5
+                "message": {
+                    "type": "string",
+                    "example": "Templates reloaded successfully"
                 },
-                "updated_at": {
-                    "type": "string"
+                "staged": {
+                    "description": "Staged is set when sync.require_approval is enabled and the reload\nchanges the database; nothing is applied until it is approved.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkg_api.StagedConfigSync"
+                        }
+                    ]
                 }
             }
         },
-        "pkg_api.HealthAuthConfig": {
+        "pkg_api.ReorderQueueRequest": {
             "type": "object",
             "properties": {
-                "basic": {
-                    "type": "boolean",
-                    "example": true
+                "job_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "job-1",
+                        "job-2",
+                        "job-3"
+                    ]
+                }
+            }
+        },
+        "pkg_api.ReportJobProgressRequest": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Block 1200000 of 3000000"
                 },
-                "github": {
-                    "type": "boolean",
-                    "example": false
+                "percent": {
+                    "description": "Percent is the completion percentage, from 0 to 100.",
+                    "type": "integer",
+                    "example": 40
+                },
+                "stage": {
+                    "type": "string",
+                    "example": "syncing"
                 }
             }
         },
-        "pkg_api.HealthConfig": {
+        "pkg_api.RequeueJobRequest": {
             "type": "object",
             "properties": {
-                "auth": {
-                    "$ref": "#/definitions/pkg_api.HealthAuthConfig"
+                "group_id": {
+                    "description": "GroupID is the group to requeue into (defaults to the original job's group).",
+                    "type": "string",
+                    "example": "my-group"
+                },
+                "inputs": {
+                    "description": "Inputs override the inputs the original job ran with.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_input.Map"
+                        }
+                    ]
                 }
             }
         },
-        "pkg_api.HealthResponse": {
+        "pkg_api.RestoreJobRequest": {
             "type": "object",
             "properties": {
-                "config": {
-                    "$ref": "#/definitions/pkg_api.HealthConfig"
+                "created_by": {
+                    "description": "CreatedBy is who the job is attributed to (defaults to the restoring admin).",
+                    "type": "string",
+                    "example": "alice"
                 },
-                "status": {
+                "inputs": {
+                    "description": "Inputs are the inputs the run was dispatched with, which GitHub does not return.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_input.Map"
+                        }
+                    ]
+                },
+                "run_url": {
+                    "description": "RunURL is the workflow run the job is rebuilt from.",
                     "type": "string",
-                    "example": "ok"
+                    "example": "https://github.com/ethpandaops/syncoor-tests/actions/runs/123456789"
+                },
+                "template_id": {
+                    "description": "TemplateID links the job to one of the group's templates. Without it the\njob is restored as a manual job using the run's repository, workflow and branch.",
+                    "type": "string",
+                    "example": "sync-test-hoodi-geth-prysm"
                 }
             }
         },
-        "pkg_api.HistoryResponse": {
+        "pkg_api.RunnerPoolStatus": {
             "type": "object",
             "properties": {
-                "has_more": {
-                    "type": "boolean",
-                    "example": true
-                },
-                "jobs": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Job"
-                    }
-                },
-                "next_cursor": {
+                "group_id": {
                     "type": "string",
-                    "example": "2024-01-15T10:30:00Z"
+                    "example": "sysadmin"
                 },
-                "total_count": {
+                "min_online_runners": {
                     "type": "integer",
-                    "example": 150
+                    "example": 3
+                },
+                "online_runners": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "status": {
+                    "$ref": "#/definitions/pkg_api.ComponentStatus"
                 }
             }
         },
-        "pkg_api.HistoryStatsBucket": {
+        "pkg_api.RunnerSummary": {
             "type": "object",
             "properties": {
-                "cancelled": {
+                "busy": {
                     "type": "integer",
-                    "example": 0
+                    "example": 8
                 },
-                "completed": {
+                "idle": {
                     "type": "integer",
-                    "example": 5
+                    "example": 3
                 },
-                "failed": {
+                "offline": {
                     "type": "integer",
                     "example": 1
                 },
-                "timestamp": {
-                    "type": "string",
-                    "example": "2024-01-15T10:00:00Z"
+                "online": {
+                    "type": "integer",
+                    "example": 11
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 12
                 }
             }
         },
-        "pkg_api.HistoryStatsRange": {
+        "pkg_api.SavedFilterRequest": {
             "type": "object",
             "properties": {
-                "bucket_duration": {
+                "group_id": {
+                    "description": "GroupID restricts the filter to one group (empty = any group).",
                     "type": "string",
-                    "example": "1h0m0s"
+                    "example": "sync-tests"
                 },
-                "end": {
-                    "type": "string",
-                    "example": "2024-01-16T00:00:00Z"
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 },
-                "start": {
+                "name": {
                     "type": "string",
-                    "example": "2024-01-15T00:00:00Z"
+                    "example": "Failed hoodi syncs"
+                },
+                "statuses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobStatus"
+                    }
+                },
+                "template_id": {
+                    "description": "TemplateID restricts the filter to one template (empty = all templates).",
+                    "type": "string"
+                },
+                "view": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.SavedFilterView"
+                        }
+                    ],
+                    "example": "history"
                 }
             }
         },
-        "pkg_api.HistoryStatsResponse": {
+        "pkg_api.SearchResponse": {
             "type": "object",
             "properties": {
-                "buckets": {
+                "query": {
+                    "type": "string",
+                    "example": "hoodi"
+                },
+                "results": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/pkg_api.HistoryStatsBucket"
+                        "$ref": "#/definitions/pkg_api.SearchResult"
                     }
-                },
-                "range": {
-                    "$ref": "#/definitions/pkg_api.HistoryStatsRange"
-                },
-                "totals": {
-                    "$ref": "#/definitions/pkg_api.HistoryStatsTotals"
                 }
             }
         },
-        "pkg_api.HistoryStatsTotals": {
+        "pkg_api.SearchResult": {
             "type": "object",
             "properties": {
-                "cancelled": {
-                    "type": "integer",
-                    "example": 5
+                "group_id": {
+                    "description": "GroupID is the group of templates and jobs.",
+                    "type": "string",
+                    "example": "sync-tests"
                 },
-                "completed": {
-                    "type": "integer",
-                    "example": 120
+                "id": {
+                    "type": "string",
+                    "example": "sync-test-hoodi-geth-prysm"
                 },
-                "failed": {
-                    "type": "integer",
-                    "example": 15
+                "name": {
+                    "type": "string",
+                    "example": "Sync Test Hoodi (geth/prysm)"
+                },
+                "status": {
+                    "description": "Status is the status of jobs and runners.",
+                    "type": "string",
+                    "example": "running"
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkg_api.SearchResultType"
+                        }
+                    ],
+                    "example": "template"
                 }
             }
         },
-        "pkg_api.LoginRequest": {
+        "pkg_api.SearchResultType": {
+            "type": "string",
+            "enum": [
+                "group",
+                "template",
+                "job",
+                "runner"
+            ],
+            "x-enum-varnames": [
+                "SearchResultGroup",
+                "SearchResultTemplate",
+                "SearchResultJob",
+                "SearchResultRunner"
+            ]
+        },
+        "pkg_api.SessionResponse": {
             "type": "object",
             "properties": {
-                "password": {
-                    "type": "string",
-                    "example": "password123"
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Current is set on the session the request was made with.",
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "description": "IPAddress and UserAgent are those of the most recent request made with\nthe session.",
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
                 },
                 "username": {
-                    "type": "string",
-                    "example": "admin"
+                    "type": "string"
                 }
             }
         },
-        "pkg_api.LoginResponse": {
+        "pkg_api.SessionsResponse": {
             "type": "object",
             "properties": {
-                "token": {
-                    "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIs..."
-                },
-                "user": {
-                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.User"
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.SessionResponse"
+                    }
                 }
             }
         },
-        "pkg_api.QueueStats": {
+        "pkg_api.SetJobNotifyTargetsRequest": {
             "type": "object",
             "properties": {
-                "pending_jobs": {
-                    "type": "integer"
-                },
-                "running_jobs": {
-                    "type": "integer"
-                },
-                "triggered_jobs": {
-                    "type": "integer"
+                "targets": {
+                    "description": "Targets replace the job's notify targets; an empty list clears them.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobNotifyTarget"
+                    }
                 }
             }
         },
-        "pkg_api.RateLimitErrorResponse": {
+        "pkg_api.SettingsResponse": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "rate limit exceeded"
+                "defaults": {
+                    "description": "Defaults are the values from the configuration.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkg_api.SettingsValues"
+                        }
+                    ]
+                },
+                "effective": {
+                    "description": "Effective are the values in use.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkg_api.SettingsValues"
+                        }
+                    ]
+                },
+                "overrides": {
+                    "description": "Overrides are the persisted values replacing their defaults.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.Setting"
+                    }
                 }
             }
         },
-        "pkg_api.ReloadTemplatesGroupStats": {
+        "pkg_api.SettingsValues": {
             "type": "object",
             "properties": {
-                "group_id": {
+                "dispatch_interval": {
                     "type": "string",
-                    "example": "my-group"
+                    "example": "10s"
                 },
-                "templates": {
+                "rate_limit_buffer": {
                     "type": "integer",
-                    "example": 5
+                    "example": 100
+                },
+                "tracking_interval": {
+                    "type": "string",
+                    "example": "30s"
                 }
             }
         },
-        "pkg_api.ReloadTemplatesResponse": {
+        "pkg_api.StagedConfigSync": {
             "type": "object",
             "properties": {
-                "groups": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/pkg_api.ReloadTemplatesGroupStats"
-                    }
+                "diff": {
+                    "$ref": "#/definitions/pkg_api.ConfigSyncDiff"
                 },
-                "message": {
+                "id": {
                     "type": "string",
-                    "example": "Templates reloaded successfully"
+                    "example": "3f1c2b8e-7a4d-4e6b-9c0a-5d8e2f1b7c3a"
+                },
+                "staged_at": {
+                    "type": "string"
+                },
+                "staged_by": {
+                    "type": "string",
+                    "example": "alice"
                 }
             }
         },
-        "pkg_api.ReorderQueueRequest": {
+        "pkg_api.SubscriptionRequest": {
             "type": "object",
             "properties": {
-                "job_ids": {
+                "channel": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.SubscriptionChannel"
+                        }
+                    ],
+                    "example": "slack"
+                },
+                "destination": {
+                    "description": "Destination is an email address for email, or an http(s) URL for slack\nand webhook.",
+                    "type": "string",
+                    "example": "https://hooks.slack.com/services/T000/B000/XXXX"
+                },
+                "statuses": {
+                    "description": "Statuses limits notifications to transitions into these statuses\n(empty = every transition).",
                     "type": "array",
                     "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "job-1",
-                        "job-2",
-                        "job-3"
-                    ]
+                        "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.JobStatus"
+                    }
+                },
+                "target_id": {
+                    "type": "string",
+                    "example": "sync-test-hoodi-geth-prysm"
+                },
+                "target_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_store.SubscriptionTarget"
+                        }
+                    ],
+                    "example": "template"
                 }
             }
         },
@@ -2347,9 +7499,23 @@ const docTemplate = `{
                 "github": {
                     "$ref": "#/definitions/pkg_api.GitHubClientsStatus"
                 },
+                "permissions": {
+                    "description": "Permissions lists startup permission checks (omitted until they have run).",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.PermissionStatus"
+                    }
+                },
                 "queue": {
                     "$ref": "#/definitions/pkg_api.QueueStats"
                 },
+                "runner_pools": {
+                    "description": "RunnerPools lists the groups that set min_online_runners.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_api.RunnerPoolStatus"
+                    }
+                },
                 "status": {
                     "$ref": "#/definitions/pkg_api.ComponentStatus"
                 },
@@ -2374,14 +7540,25 @@ const docTemplate = `{
                 }
             }
         },
+        "pkg_api.UpdateJobOwnerRequest": {
+            "type": "object",
+            "properties": {
+                "created_by": {
+                    "description": "CreatedBy is the username the job is attributed to from now on.",
+                    "type": "string",
+                    "example": "alice"
+                }
+            }
+        },
         "pkg_api.UpdateJobRequest": {
             "type": "object",
             "properties": {
+                "clear_not_before": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "inputs": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                    "$ref": "#/definitions/github_com_ethpandaops_dispatchoor_pkg_input.Map"
                 },
                 "labels": {
                     "type": "object",
@@ -2393,10 +7570,20 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Updated Job"
                 },
+                "not_before": {
+                    "description": "NotBefore reschedules the job, and ClearNotBefore makes it due now.",
+                    "type": "string",
+                    "example": "2026-10-14T22:00:00Z"
+                },
                 "owner": {
                     "type": "string",
                     "example": "ethpandaops"
                 },
+                "priority": {
+                    "description": "Priority moves the job ahead of (higher) or behind (lower) jobs of other\npriorities, whatever their queue position.",
+                    "type": "integer",
+                    "example": 10
+                },
                 "ref": {
                     "type": "string",
                     "example": "main"
@@ -2411,6 +7598,33 @@ const docTemplate = `{
                 }
             }
         },
+        "pkg_api.UpdateSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "dispatch_interval": {
+                    "type": "string",
+                    "example": "10s"
+                },
+                "rate_limit_buffer": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "reset": {
+                    "description": "Reset returns the named settings to their configured defaults.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tracking_interval"
+                    ]
+                },
+                "tracking_interval": {
+                    "type": "string",
+                    "example": "30s"
+                }
+            }
+        },
         "pkg_api.VersionInfo": {
             "type": "object",
             "properties": {
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/webhook"
+)
+
+// gitRefreshTimeout bounds one refresh of templates from git sources.
+const gitRefreshTimeout = 5 * time.Minute
+
+// requestGitRefresh asks the refresh loop to reload templates, without
+// blocking. Requests made while a refresh is pending are merged.
+func (s *server) requestGitRefresh() {
+	select {
+	case s.gitRefresh <- struct{}{}:
+	default:
+	}
+}
+
+// gitRefreshLoop reloads templates every interval (never if 0) and on
+// request, while any group reads templates from git.
+func (s *server) gitRefreshLoop(ctx context.Context, interval time.Duration) {
+	var tick <-chan time.Time
+
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		case <-s.gitRefresh:
+		}
+
+		if !s.hasGitTemplateSources() {
+			continue
+		}
+
+		refreshCtx, cancel := context.WithTimeout(ctx, gitRefreshTimeout)
+
+		if _, err := s.reloadTemplates(refreshCtx, "system", true); err != nil {
+			s.log.WithError(err).Warn("Failed to refresh templates from git")
+		}
+
+		cancel()
+	}
+}
+
+// hasGitTemplateSources reports whether any configured group reads templates
+// from git.
+func (s *server) hasGitTemplateSources() bool {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+
+	for _, group := range s.cfg.Groups.GitHub {
+		if len(group.WorkflowDispatchTemplatesGit) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pushUpdatesGitSource reports whether a push changed the ref of any git
+// template source.
+func (s *server) pushUpdatesGitSource(event *webhook.PushEvent) bool {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+
+	for _, group := range s.cfg.Groups.GitHub {
+		for _, src := range group.WorkflowDispatchTemplatesGit {
+			if !strings.EqualFold(src.Repo(), event.Repo) {
+				continue
+			}
+
+			ref := src.Ref
+			if ref == "" {
+				ref = event.DefaultBranch
+			}
+
+			if event.Ref == "refs/heads/"+ref || event.Ref == "refs/tags/"+ref {
+				return true
+			}
+		}
+	}
+
+	return false
+}
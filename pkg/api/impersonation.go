@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/auth"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+// actAsHeaderName is the header an admin names the user to act as in.
+const actAsHeaderName = "X-Act-As"
+
+// impersonationMiddleware lets admins act as another user by naming them in
+// actAsHeaderName. The request is then handled with that user's identity and
+// permissions, and the admin is kept in the context as its impersonator. Every
+// state-changing impersonated request is recorded in the audit log under both
+// identities. It must run after auth.AuthMiddleware.
+func (s *server) impersonationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username := r.Header.Get(actAsHeaderName)
+		if username == "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		admin := auth.UserFromContext(r.Context())
+		if admin == nil || !s.auth.IsAdmin(admin) {
+			s.writeError(w, http.StatusForbidden, "Only admins can act as another user")
+
+			return
+		}
+
+		if username == admin.Username {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		target, err := s.store.GetUserByUsername(r.Context(), username)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to get user to act as")
+			s.writeError(w, http.StatusInternalServerError, "Failed to get user to act as")
+
+			return
+		}
+
+		if target == nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown user to act as: %s", username))
+
+			return
+		}
+
+		ctx := auth.ContextWithImpersonator(auth.ContextWithUser(r.Context(), target), admin)
+		r = r.WithContext(ctx)
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		if err := s.store.CreateAuditEntry(ctx, &store.AuditEntry{
+			ID:         uuid.New().String(),
+			Action:     store.AuditActionUserImpersonated,
+			EntityType: store.AuditEntityUser,
+			EntityID:   target.ID,
+			Actor:      admin.Username,
+			Details:    fmt.Sprintf("%s acting as %s: %s %s (status %d)", admin.Username, target.Username, r.Method, r.URL.Path, status),
+			CreatedAt:  time.Now(),
+		}); err != nil {
+			s.log.WithError(err).WithField("user", target.Username).Warn("Failed to create audit entry for impersonated request")
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+)
+
+// setInputOverrides sets InputsOverridden on jobs from the current default
+// inputs of their templates. Manual jobs, and jobs whose template cannot be
+// read, are served without it.
+func (s *server) setInputOverrides(ctx context.Context, jobs ...*store.Job) {
+	templates := make(map[string]*store.JobTemplate)
+
+	for _, job := range jobs {
+		if job == nil || job.TemplateID == "" {
+			continue
+		}
+
+		template, ok := templates[job.TemplateID]
+		if !ok {
+			// Templates are served from the store cache.
+			var err error
+
+			template, err = s.store.GetJobTemplate(ctx, job.TemplateID)
+			if err != nil {
+				s.log.WithError(err).WithField("template", job.TemplateID).Debug("Failed to get template for input overrides")
+			}
+
+			templates[job.TemplateID] = template
+		}
+
+		if template != nil {
+			job.InputsOverridden = inputOverrides(job.Inputs, template.DefaultInputs)
+		}
+	}
+}
+
+// inputOverrides returns the inputs whose value differs from their default,
+// or that have no default. Values are compared as dispatched, so "true" and
+// true are the same.
+func inputOverrides(inputs, defaults input.Map) map[string]store.InputOverride {
+	var overrides map[string]store.InputOverride
+
+	for key, value := range inputs {
+		override := store.InputOverride{Value: value}
+
+		if defaultValue, ok := defaults[key]; ok {
+			if value.String() == defaultValue.String() {
+				continue
+			}
+
+			override.Default = &defaultValue
+		}
+
+		if overrides == nil {
+			overrides = make(map[string]store.InputOverride)
+		}
+
+		overrides[key] = override
+	}
+
+	return overrides
+}
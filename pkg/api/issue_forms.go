@@ -0,0 +1,217 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/queue"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/ethpandaops/dispatchoor/pkg/webhook"
+	"github.com/sirupsen/logrus"
+)
+
+// issueFormTemplateField is the issue form field naming the template to run.
+const issueFormTemplateField = "template"
+
+// issueFormNoResponse is what GitHub renders for optional fields left empty.
+const issueFormNoResponse = "_No response_"
+
+// handleIssueFormRequest enqueues the job requested by a newly opened issue
+// carrying the chatops issue label, and replies on the issue with the
+// outcome. The issue is closed once the job completes; see
+// reportIssueFormJob.
+func (s *server) handleIssueFormRequest(ctx context.Context, event *webhook.IssuesEvent) {
+	s.cfgMu.RLock()
+	cfg := s.cfg.ChatOps
+	s.cfgMu.RUnlock()
+
+	if event.AuthorType == "Bot" || !slices.Contains(event.Labels, cfg.IssueLabel) {
+		return
+	}
+
+	repo := event.Owner + "/" + event.Repo
+	if !slices.ContainsFunc(cfg.Repos, func(r string) bool { return strings.EqualFold(r, repo) }) {
+		return
+	}
+
+	log := s.log.WithFields(logrus.Fields{
+		"repo":   repo,
+		"number": event.Number,
+		"author": event.Author,
+	})
+
+	var reply string
+
+	cmd, err := parseIssueForm(event.Body)
+	if err != nil {
+		reply = fmt.Sprintf("@%s Could not read the request: %s.", event.Author, err)
+	} else {
+		// The issue's author is authorized as a comment's would be.
+		author := &webhook.IssueCommentEvent{
+			Owner:      event.Owner,
+			Repo:       event.Repo,
+			Number:     event.Number,
+			Author:     event.Author,
+			AuthorID:   event.AuthorID,
+			AuthorType: event.AuthorType,
+		}
+
+		reply = s.runChatOpsCommand(ctx, log, author, cmd, cfg.UIURL, &queue.EnqueueOptions{
+			SourceIssue: fmt.Sprintf("%s#%d", repo, event.Number),
+		})
+	}
+
+	s.postChatOpsReply(ctx, log, event.Owner, event.Repo, event.Number, reply)
+}
+
+// parseIssueForm reads the fields of an issue created from an issue form,
+// which GitHub renders as a "### <label>" heading followed by the value. The
+// "Template" field names the template; every other field is an input named
+// by its label. Fields left empty are skipped, so the template's defaults
+// apply.
+func parseIssueForm(body string) (*chatOpsCommand, error) {
+	cmd := &chatOpsCommand{}
+
+	var (
+		field  string
+		value  []string
+		inForm bool
+	)
+
+	flush := func() {
+		if !inForm {
+			return
+		}
+
+		v := strings.TrimSpace(strings.Join(value, "\n"))
+		value = value[:0]
+
+		if v == "" || v == issueFormNoResponse {
+			return
+		}
+
+		if strings.EqualFold(field, issueFormTemplateField) {
+			cmd.TemplateID = v
+
+			return
+		}
+
+		if cmd.Inputs == nil {
+			cmd.Inputs = make(input.Map)
+		}
+
+		cmd.Inputs[field] = input.String(v)
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		if heading, ok := strings.CutPrefix(line, "### "); ok {
+			flush()
+
+			field = strings.TrimSpace(heading)
+			inForm = true
+
+			continue
+		}
+
+		value = append(value, line)
+	}
+
+	flush()
+
+	if cmd.TemplateID == "" {
+		return nil, fmt.Errorf("expected a %q field naming the template", "Template")
+	}
+
+	return cmd, nil
+}
+
+// JobTransition reports a finished job requested by an issue form on its
+// issue, closing the issue if the job completed. It does not block the
+// caller.
+func (s *server) JobTransition(job *store.Job, _ store.JobStatus) {
+	if job.SourceIssue == "" {
+		return
+	}
+
+	switch job.Status {
+	case store.JobStatusCompleted, store.JobStatusFailed, store.JobStatusCancelled:
+		finished := *job
+
+		go s.reportIssueFormJob(context.Background(), &finished)
+	}
+}
+
+// reportIssueFormJob comments the outcome of a finished job on the issue it
+// was requested by, and closes the issue if the job completed. Failed and
+// cancelled jobs leave the issue open for the requester to follow up.
+func (s *server) reportIssueFormJob(ctx context.Context, job *store.Job) {
+	log := s.log.WithFields(logrus.Fields{
+		"job_id":       job.ID,
+		"source_issue": job.SourceIssue,
+	})
+
+	owner, repo, number, ok := parseSourceIssue(job.SourceIssue)
+	if !ok {
+		log.Warn("Invalid source issue on job")
+
+		return
+	}
+
+	s.cfgMu.RLock()
+	uiURL := s.cfg.ChatOps.UIURL
+	s.cfgMu.RUnlock()
+
+	ref := chatOpsJobRef(uiURL, job)
+
+	var reply string
+
+	switch job.Status {
+	case store.JobStatusCompleted:
+		reply = fmt.Sprintf("Job %s completed.", ref)
+	case store.JobStatusFailed:
+		reply = fmt.Sprintf("Job %s failed.", ref)
+	default:
+		reply = fmt.Sprintf("Job %s was cancelled.", ref)
+	}
+
+	if job.RunURL != "" {
+		reply += fmt.Sprintf(" [View the workflow run](%s).", job.RunURL)
+	}
+
+	s.postChatOpsReply(ctx, log, owner, repo, number, reply)
+
+	if job.Status != store.JobStatusCompleted || s.dispatchClient == nil {
+		return
+	}
+
+	closeCtx, cancel := context.WithTimeout(ctx, chatOpsReplyTimeout)
+	defer cancel()
+
+	if err := s.dispatchClient.CloseIssue(closeCtx, owner, repo, number); err != nil {
+		log.WithError(err).Warn("Failed to close source issue")
+	}
+}
+
+// parseSourceIssue splits an "owner/repo#number" issue reference.
+func parseSourceIssue(ref string) (owner, repo string, number int, ok bool) {
+	fullName, num, ok := strings.Cut(ref, "#")
+	if !ok {
+		return "", "", 0, false
+	}
+
+	owner, repo, ok = strings.Cut(fullName, "/")
+	if !ok || owner == "" || repo == "" {
+		return "", "", 0, false
+	}
+
+	number, err := strconv.Atoi(num)
+	if err != nil || number <= 0 {
+		return "", "", 0, false
+	}
+
+	return owner, repo, number, true
+}
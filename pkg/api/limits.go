@@ -0,0 +1,51 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+)
+
+// checkMapLimits returns a user-facing error message when a map of job inputs
+// or labels exceeds the given limits, or "" when it fits. kind names an entry
+// in the message, e.g. "input".
+func checkMapLimits(kind string, values map[string]string, maxCount, maxKey, maxValue int) string {
+	if len(values) > maxCount {
+		return fmt.Sprintf("Too many %ss: at most %d allowed, got %d", kind, maxCount, len(values))
+	}
+
+	// Sorted so the same payload always reports the same key.
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if len(key) > maxKey {
+			return fmt.Sprintf("Key of %s too long: at most %d bytes, got %d", kind, maxKey, len(key))
+		}
+
+		if len(values[key]) > maxValue {
+			return fmt.Sprintf("Value of %s %q too long: at most %d bytes, got %d", kind, key, maxValue, len(values[key]))
+		}
+	}
+
+	return ""
+}
+
+// checkJobLimits checks job inputs and labels from a request against
+// server.limits and returns a user-facing error message, or "". Input values
+// are measured in their string form.
+func (s *server) checkJobLimits(inputs input.Map, labels map[string]string) string {
+	limits := s.cfg.Server.Limits
+
+	if msg := checkMapLimits("input", inputs.Strings(),
+		limits.MaxInputs, limits.MaxInputKeyLength, limits.MaxInputValueLength); msg != "" {
+		return msg
+	}
+
+	return checkMapLimits("label", labels, limits.MaxLabels, limits.MaxLabelKeyLength, limits.MaxLabelValueLength)
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+// requestLogKey is the context key for the request's log entry.
+type requestLogKey struct{}
+
+// requestLogEntry collects fields that are only known further down the
+// middleware chain, such as the authenticated user.
+type requestLogEntry struct {
+	user         string
+	impersonator string
+}
+
+// requestParamFields maps the resource preceding an {id} route param to the
+// log field it is reported as.
+var requestParamFields = map[string]string{
+	"groups":    "group_id",
+	"jobs":      "job_id",
+	"templates": "template_id",
+	"filters":   "filter_id",
+}
+
+// requestLogger logs every request as a structured logrus entry once it has
+// been handled. Successful GETs can be sampled with sample_successful_gets.
+func (s *server) requestLogger() func(http.Handler) http.Handler {
+	sampleEvery := uint64(s.cfg.Server.RequestLog.SampleSuccessfulGets)
+
+	var gets atomic.Uint64
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			entry := &requestLogEntry{}
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), requestLogKey{}, entry)))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			if r.Method == http.MethodGet && status < http.StatusBadRequest && sampleEvery > 1 &&
+				gets.Add(1)%sampleEvery != 1 {
+				return
+			}
+
+			fields := logrus.Fields{
+				"request_id": middleware.GetReqID(r.Context()),
+				"method":     r.Method,
+				"path":       r.URL.Path,
+				"status":     status,
+				"bytes":      ww.BytesWritten(),
+				"latency_ms": time.Since(start).Milliseconds(),
+				"remote":     r.RemoteAddr,
+			}
+
+			if entry.user != "" {
+				fields["user"] = entry.user
+			}
+
+			if entry.impersonator != "" {
+				fields["impersonator"] = entry.impersonator
+			}
+
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				pattern := rctx.RoutePattern()
+				if pattern != "" {
+					fields["route"] = pattern
+				}
+
+				for i, key := range rctx.URLParams.Keys {
+					if key == "*" || i >= len(rctx.URLParams.Values) {
+						continue
+					}
+
+					fields[paramField(pattern, key)] = rctx.URLParams.Values[i]
+				}
+			}
+
+			log := s.log.WithFields(fields)
+
+			switch {
+			case status >= http.StatusInternalServerError:
+				log.Error("Request failed")
+			case status >= http.StatusBadRequest:
+				log.Warn("Request rejected")
+			default:
+				log.Info("Request handled")
+			}
+		})
+	}
+}
+
+// annotateRequestLog records the authenticated user, and the admin acting as
+// them, on the request's log entry. It must run after auth.AuthMiddleware.
+func annotateRequestLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if entry, ok := r.Context().Value(requestLogKey{}).(*requestLogEntry); ok {
+			if user := auth.UserFromContext(r.Context()); user != nil {
+				entry.user = user.Username
+			}
+
+			if admin := auth.ImpersonatorFromContext(r.Context()); admin != nil {
+				entry.impersonator = admin.Username
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// paramField returns the log field name for a route param, e.g. "group_id"
+// for {id} in /api/v1/groups/{id}/queue.
+func paramField(pattern, key string) string {
+	if key != "id" {
+		return key
+	}
+
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if segment == "{id}" && i > 0 {
+			if field, ok := requestParamFields[segments[i-1]]; ok {
+				return field
+			}
+		}
+	}
+
+	return "id"
+}
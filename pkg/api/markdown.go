@@ -0,0 +1,27 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// markdownFlags render markdown for display in the UI without letting it
+// inject markup: raw HTML and images are dropped, links are limited to safe
+// protocols and open in a new tab.
+const markdownFlags = blackfriday.SkipHTML | blackfriday.SkipImages | blackfriday.Safelink |
+	blackfriday.NofollowLinks | blackfriday.NoreferrerLinks | blackfriday.NoopenerLinks |
+	blackfriday.HrefTargetBlank
+
+// renderMarkdown renders a markdown description as HTML that is safe to embed.
+func renderMarkdown(src string) string {
+	if strings.TrimSpace(src) == "" {
+		return ""
+	}
+
+	renderer := blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{Flags: markdownFlags})
+
+	return string(blackfriday.Run([]byte(src),
+		blackfriday.WithRenderer(renderer),
+		blackfriday.WithExtensions(blackfriday.CommonExtensions)))
+}
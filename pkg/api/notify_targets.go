@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// maxNotifyTargetsPerJob bounds the notify targets of one job.
+const maxNotifyTargetsPerJob = 10
+
+// SetJobNotifyTargetsRequest is the request body for replacing the notify
+// targets of a job.
+type SetJobNotifyTargetsRequest struct {
+	// Targets replace the job's notify targets; an empty list clears them.
+	Targets []store.JobNotifyTarget `json:"targets"`
+}
+
+// handleSetJobNotifyTargets godoc
+//
+//	@Summary		Set job notify targets
+//	@Description	Replaces where the transitions of a job that has not finished are delivered, in addition to its subscribers, e.g. a Slack channel to ping on completion (requires admin)
+//	@Tags			jobs
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"Job ID"
+//	@Param			body	body		SetJobNotifyTargetsRequest	true	"Notify targets"
+//	@Success		200		{object}	store.Job
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/jobs/{id}/notify-targets [put]
+func (s *server) handleSetJobNotifyTargets(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+
+	var req SetJobNotifyTargetsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if msg := s.validateNotifyTargets(req.Targets); msg != "" {
+		s.writeError(w, http.StatusBadRequest, msg)
+
+		return
+	}
+
+	existing, err := s.queue.GetJob(r.Context(), jobID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get job")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get job")
+
+		return
+	}
+
+	if existing == nil {
+		s.writeError(w, http.StatusNotFound, "Job not found")
+
+		return
+	}
+
+	switch existing.Status {
+	case store.JobStatusCompleted, store.JobStatusFailed, store.JobStatusCancelled:
+		s.writeError(w, http.StatusConflict, "Job has already finished")
+
+		return
+	}
+
+	job, err := s.queue.SetNotifyTargets(r.Context(), jobID, req.Targets)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to set job notify targets")
+		s.writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, job)
+}
+
+// validateNotifyTargets checks the notify targets of a job, normalizing their
+// destinations, and returns a user-facing error message.
+func (s *server) validateNotifyTargets(targets []store.JobNotifyTarget) string {
+	if len(targets) > maxNotifyTargetsPerJob {
+		return fmt.Sprintf("At most %d notify targets are allowed per job", maxNotifyTargetsPerJob)
+	}
+
+	for i := range targets {
+		target := &targets[i]
+
+		if msg := s.validateDelivery(target.Channel, &target.Destination, target.Statuses); msg != "" {
+			return fmt.Sprintf("Notify target %d: %s", i+1, msg)
+		}
+	}
+
+	return ""
+}
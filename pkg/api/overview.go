@@ -0,0 +1,120 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+)
+
+const (
+	// overviewHistoryWindow is how far back finished jobs are counted in group
+	// stats and recent failures are listed.
+	overviewHistoryWindow = 24 * time.Hour
+	// overviewRecentFailures bounds the recent failures in the overview.
+	overviewRecentFailures = 10
+)
+
+// OverviewResponse is everything the dashboard shows on load.
+type OverviewResponse struct {
+	Groups  []GroupWithStats `json:"groups"`
+	Runners RunnerSummary    `json:"runners"`
+	// RecentFailures are the latest jobs of any group that failed in the last
+	// 24 hours, most recent first.
+	RecentFailures []*store.Job          `json:"recent_failures"`
+	System         *SystemStatusResponse `json:"system"`
+}
+
+// RunnerSummary counts the runners across all groups.
+type RunnerSummary struct {
+	Total   int `json:"total" example:"12"`
+	Online  int `json:"online" example:"11"`
+	Offline int `json:"offline" example:"1"`
+	Busy    int `json:"busy" example:"8"`
+	Idle    int `json:"idle" example:"3"`
+}
+
+// handleGetOverview godoc
+//
+//	@Summary		Get dashboard overview
+//	@Description	Returns all groups with statistics, a runner summary, failures of the last 24 hours and system status in one response. Group statistics come from aggregate queries rather than per-group job lists.
+//	@Tags			system
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			include_archived	query		bool	false	"Include archived groups"
+//	@Success		200					{object}	OverviewResponse
+//	@Failure		401					{object}	ErrorResponse
+//	@Failure		500					{object}	ErrorResponse
+//	@Router			/overview [get]
+func (s *server) handleGetOverview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	now := time.Now()
+	since := now.Add(-overviewHistoryWindow)
+
+	counts, err := s.store.GetGroupJobCounts(ctx, since)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to count group jobs")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get overview")
+
+		return
+	}
+
+	runners, err := s.store.ListRunners(ctx)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list runners")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get overview")
+
+		return
+	}
+
+	groups, err := s.groupsWithStats(ctx, r.URL.Query().Get("include_archived") == "true", counts, runners, now)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list groups")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get overview")
+
+		return
+	}
+
+	failures, err := s.store.ListRecentFailures(ctx, since, overviewRecentFailures)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list recent failures")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get overview")
+
+		return
+	}
+
+	if failures == nil {
+		failures = []*store.Job{}
+	}
+
+	system := s.systemStatus(ctx)
+
+	for _, count := range counts {
+		system.Queue.PendingJobs += count.Pending
+		system.Queue.TriggeredJobs += count.Triggered
+		system.Queue.RunningJobs += count.Running
+	}
+
+	resp := OverviewResponse{
+		Groups:         groups,
+		RecentFailures: failures,
+		System:         &system,
+	}
+
+	for _, runner := range runners {
+		resp.Runners.Total++
+
+		switch {
+		case runner.Status != store.RunnerStatusOnline:
+			resp.Runners.Offline++
+		case runner.Busy:
+			resp.Runners.Online++
+			resp.Runners.Busy++
+		default:
+			resp.Runners.Online++
+			resp.Runners.Idle++
+		}
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// maxProgressStageLength bounds the stage of a progress report.
+	maxProgressStageLength = 100
+	// maxProgressMessageLength bounds the message of a progress report.
+	maxProgressMessageLength = 1024
+)
+
+// ReportJobProgressRequest is a progress report from a job's workflow run.
+type ReportJobProgressRequest struct {
+	// Percent is the completion percentage, from 0 to 100.
+	Percent *int   `json:"percent,omitempty" example:"40"`
+	Stage   string `json:"stage,omitempty" example:"syncing"`
+	Message string `json:"message,omitempty" example:"Block 1200000 of 3000000"`
+}
+
+// handleReportJobProgress godoc
+//
+//	@Summary		Report job progress
+//	@Description	Stores the progress of a triggered or running job and broadcasts it to the job's group subscribers. Authenticated with an API token from auth.api_tokens, so workflows can call it mid-run. Each report replaces the previous one and counts as a heartbeat.
+//	@Tags			jobs
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"Job ID"
+//	@Param			request	body		ReportJobProgressRequest	true	"Progress report"
+//	@Success		200		{object}	store.Job
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/jobs/{id}/progress [post]
+func (s *server) handleReportJobProgress(w http.ResponseWriter, r *http.Request) {
+	var req ReportJobProgressRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if req.Percent != nil && (*req.Percent < 0 || *req.Percent > 100) {
+		s.writeError(w, http.StatusBadRequest, "percent must be between 0 and 100")
+
+		return
+	}
+
+	if len(req.Stage) > maxProgressStageLength {
+		s.writeError(w, http.StatusBadRequest, "stage is too long")
+
+		return
+	}
+
+	if len(req.Message) > maxProgressMessageLength {
+		s.writeError(w, http.StatusBadRequest, "message is too long")
+
+		return
+	}
+
+	job, ok := s.activeJob(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	job.Progress = &store.JobProgress{
+		Percent:   req.Percent,
+		Stage:     req.Stage,
+		Message:   req.Message,
+		UpdatedAt: now,
+	}
+	job.HeartbeatAt, job.StalledAt = &now, nil
+
+	if err := s.store.UpdateJobProgress(r.Context(), job.ID, job.Progress); err != nil {
+		s.log.WithError(err).Error("Failed to update job progress")
+		s.writeError(w, http.StatusInternalServerError, "Failed to report progress")
+
+		return
+	}
+
+	s.hub.BroadcastJobState(job)
+
+	s.writeJSON(w, http.StatusOK, job)
+}
+
+// handleJobHeartbeat godoc
+//
+//	@Summary		Send job heartbeat
+//	@Description	Records that a triggered or running job's workflow is alive, clearing its stalled flag. Authenticated with an API token from auth.api_tokens. Progress reports count as heartbeats too.
+//	@Tags			jobs
+//	@Security		BearerAuth
+//	@Param			id	path	string	true	"Job ID"
+//	@Success		204
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/jobs/{id}/heartbeat [post]
+func (s *server) handleJobHeartbeat(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.activeJob(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+
+	if err := s.store.RecordJobHeartbeat(r.Context(), job.ID, now); err != nil {
+		s.log.WithError(err).Error("Failed to record job heartbeat")
+		s.writeError(w, http.StatusInternalServerError, "Failed to record heartbeat")
+
+		return
+	}
+
+	// Heartbeats are frequent, so subscribers only hear of the stall clearing.
+	if job.StalledAt != nil {
+		job.HeartbeatAt, job.StalledAt = &now, nil
+		s.hub.BroadcastJobState(job)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// activeJob returns the triggered or running job named by the request's {id}
+// param. Otherwise it writes an error and returns false.
+func (s *server) activeJob(w http.ResponseWriter, r *http.Request) (*store.Job, bool) {
+	job, err := s.queue.GetJob(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get job")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get job")
+
+		return nil, false
+	}
+
+	if job == nil {
+		s.writeError(w, http.StatusNotFound, "Job not found")
+
+		return nil, false
+	}
+
+	if job.Status != store.JobStatusTriggered && job.Status != store.JobStatusRunning {
+		s.writeError(w, http.StatusConflict, "Job is not triggered or running")
+
+		return nil, false
+	}
+
+	return job, true
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+)
+
+const (
+	// defaultQueuePageLimit is the page size when only a cursor is given.
+	defaultQueuePageLimit = 100
+	// maxQueuePageLimit bounds the page size of queue pagination.
+	maxQueuePageLimit = 1000
+)
+
+// QueuePageResponse is a page of a group's pending jobs in dispatch order.
+type QueuePageResponse struct {
+	Jobs []*store.Job `json:"jobs"`
+	// Active holds the group's triggered and running jobs, on the first page only.
+	Active     []*store.Job `json:"active,omitempty"`
+	HasMore    bool         `json:"has_more" example:"true"`
+	NextCursor string       `json:"next_cursor,omitempty" example:"0:42:550e8400-e29b-41d4-a716-446655440000"`
+}
+
+// QueueSummaryResponse counts a group's pending, triggered and running jobs
+// per template, without returning the jobs.
+type QueueSummaryResponse struct {
+	Templates []QueueTemplateSummary `json:"templates"`
+	Total     QueueTemplateSummary   `json:"total"`
+}
+
+// QueueTemplateSummary counts the active jobs of one template. TemplateID is
+// empty for manual jobs and in totals.
+type QueueTemplateSummary struct {
+	TemplateID string `json:"template_id" example:"sync-test-hoodi-geth-prysm"`
+	// Pending counts unpaused pending jobs; paused ones are counted in Paused.
+	Pending   int `json:"pending" example:"1200"`
+	Paused    int `json:"paused" example:"3"`
+	Triggered int `json:"triggered" example:"1"`
+	Running   int `json:"running" example:"4"`
+}
+
+// handleGetQueuePage writes a page of the group's pending jobs, continuing
+// after the job named by the after cursor. Pages are cursored by priority and
+// position, so they stay consistent while jobs ahead are dispatched.
+func (s *server) handleGetQueuePage(w http.ResponseWriter, r *http.Request, groupID string) {
+	ctx := r.Context()
+	opts := store.PendingQueryOpts{GroupID: groupID, Limit: defaultQueuePageLimit}
+
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		opts.Limit = min(l, maxQueuePageLimit)
+	}
+
+	if after := r.URL.Query().Get("after"); after != "" {
+		cursor, err := parsePendingCursor(after)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "Invalid cursor: "+err.Error())
+
+			return
+		}
+
+		opts.After = cursor
+	}
+
+	result, err := s.store.ListPendingJobs(ctx, opts)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get queue")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get queue")
+
+		return
+	}
+
+	resp := QueuePageResponse{Jobs: result.Jobs, HasMore: result.HasMore}
+
+	if resp.Jobs == nil {
+		resp.Jobs = []*store.Job{}
+	}
+
+	ahead := result.Ahead
+
+	for _, job := range resp.Jobs {
+		if job.Paused {
+			continue
+		}
+
+		position, jobAhead := ahead+1, ahead
+		job.QueuePosition = &position
+		job.AheadCount = &jobAhead
+		ahead++
+	}
+
+	if result.HasMore {
+		last := resp.Jobs[len(resp.Jobs)-1]
+		resp.NextCursor = formatPendingCursor(last)
+	}
+
+	if opts.After == nil {
+		resp.Active, err = s.store.ListJobsByGroup(ctx, groupID, store.JobStatusTriggered, store.JobStatusRunning)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to get queue")
+			s.writeError(w, http.StatusInternalServerError, "Failed to get queue")
+
+			return
+		}
+	}
+
+	s.setInputOverrides(ctx, resp.Jobs...)
+	s.setInputOverrides(ctx, resp.Active...)
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleGetQueueSummary writes the number of the group's active jobs per
+// template.
+func (s *server) handleGetQueueSummary(w http.ResponseWriter, r *http.Request, groupID string) {
+	counts, err := s.store.CountQueueJobs(r.Context(), groupID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to count queue")
+		s.writeError(w, http.StatusInternalServerError, "Failed to count queue")
+
+		return
+	}
+
+	resp := QueueSummaryResponse{Templates: []QueueTemplateSummary{}}
+	byTemplate := make(map[string]int)
+
+	// Counts are sorted by template, so each template is appended once.
+	for _, count := range counts {
+		i, ok := byTemplate[count.TemplateID]
+		if !ok {
+			i = len(resp.Templates)
+			byTemplate[count.TemplateID] = i
+			resp.Templates = append(resp.Templates, QueueTemplateSummary{TemplateID: count.TemplateID})
+		}
+
+		for _, summary := range []*QueueTemplateSummary{&resp.Templates[i], &resp.Total} {
+			switch {
+			case count.Status == store.JobStatusPending && count.Paused:
+				summary.Paused += count.Count
+			case count.Status == store.JobStatusPending:
+				summary.Pending += count.Count
+			case count.Status == store.JobStatusTriggered:
+				summary.Triggered += count.Count
+			case count.Status == store.JobStatusRunning:
+				summary.Running += count.Count
+			}
+		}
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// formatPendingCursor returns the cursor of the page continuing after job.
+func formatPendingCursor(job *store.Job) string {
+	return fmt.Sprintf("%d:%d:%s", job.Priority, job.Position, job.ID)
+}
+
+// parsePendingCursor parses a cursor returned by formatPendingCursor.
+func parsePendingCursor(value string) (*store.PendingCursor, error) {
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) != 3 || parts[2] == "" {
+		return nil, errors.New("expected priority:position:id")
+	}
+
+	priority, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("parsing priority: %w", err)
+	}
+
+	position, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("parsing position: %w", err)
+	}
+
+	return &store.PendingCursor{Priority: priority, Position: position, ID: parts[2]}, nil
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// queueStatsRanges maps each accepted range to its span and bucket width.
+var queueStatsRanges = map[string]struct{ span, bucket time.Duration }{
+	"1h":  {time.Hour, time.Minute},
+	"6h":  {6 * time.Hour, 5 * time.Minute},
+	"24h": {24 * time.Hour, 15 * time.Minute},
+	"7d":  {7 * 24 * time.Hour, time.Hour},
+	"30d": {30 * 24 * time.Hour, 6 * time.Hour},
+}
+
+// QueueStatsResponse is a group's sampled queue depth and idle runners over a
+// time range, in buckets.
+type QueueStatsResponse struct {
+	GroupID       string    `json:"group_id" example:"sync-tests"`
+	Range         string    `json:"range" example:"24h"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	BucketSeconds int64     `json:"bucket_seconds" example:"900"`
+	// Points holds the buckets with at least one sample, oldest first.
+	Points []QueueStatsPoint `json:"points"`
+}
+
+// QueueStatsPoint summarizes the samples of one bucket. Averages are rounded
+// to two decimals.
+type QueueStatsPoint struct {
+	Time           time.Time `json:"time"`
+	Samples        int       `json:"samples" example:"15"`
+	Pending        float64   `json:"pending" example:"1180.5"`
+	MaxPending     int       `json:"max_pending" example:"1224"`
+	Running        float64   `json:"running" example:"4"`
+	IdleRunners    float64   `json:"idle_runners" example:"0.4"`
+	MinIdleRunners int       `json:"min_idle_runners" example:"0"`
+}
+
+// handleGetQueueStats godoc
+//
+//	@Summary		Get queue stats
+//	@Description	Returns a group's queue depth (pending jobs, paused or not), triggered and running jobs, and idle runners over a time range, from samples taken every queue.stats.interval. Samples are averaged into buckets of 1m (1h), 5m (6h), 15m (24h), 1h (7d) or 6h (30d); buckets without samples are left out.
+//	@Tags			queue
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id		path		string	true	"Group ID"
+//	@Param			range	query		string	false	"Time range (1h, 6h, 24h, 7d, 30d)"	default(24h)
+//	@Success		200		{object}	QueueStatsResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/groups/{id}/queue/stats [get]
+func (s *server) handleGetQueueStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	groupID := chi.URLParam(r, "id")
+
+	rangeStr := r.URL.Query().Get("range")
+	if rangeStr == "" {
+		rangeStr = "24h"
+	}
+
+	window, ok := queueStatsRanges[rangeStr]
+	if !ok {
+		s.writeError(w, http.StatusBadRequest, "Invalid range parameter")
+
+		return
+	}
+
+	group, err := s.store.GetGroup(ctx, groupID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get group")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get group")
+
+		return
+	}
+
+	if group == nil {
+		s.writeError(w, http.StatusNotFound, "Group not found")
+
+		return
+	}
+
+	s.cfgMu.RLock()
+	bucket := max(window.bucket, s.cfg.Queue.Stats.Interval)
+	s.cfgMu.RUnlock()
+
+	end := time.Now()
+	start := end.Add(-window.span).Truncate(bucket)
+
+	samples, err := s.store.ListQueueStatSamples(ctx, groupID, start)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list queue stats")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get queue stats")
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, QueueStatsResponse{
+		GroupID:       groupID,
+		Range:         rangeStr,
+		Start:         start,
+		End:           end,
+		BucketSeconds: int64(bucket.Seconds()),
+		Points:        bucketQueueStats(samples, start, bucket),
+	})
+}
+
+// bucketQueueStats summarizes samples, ordered oldest first, into buckets of
+// the given width starting at start.
+func bucketQueueStats(samples []*store.QueueStatSample, start time.Time, bucket time.Duration) []QueueStatsPoint {
+	points := []QueueStatsPoint{}
+
+	var pending, running, idle int
+
+	flush := func() {
+		if len(points) == 0 {
+			return
+		}
+
+		point := &points[len(points)-1]
+		n := float64(point.Samples)
+		point.Pending = math.Round(float64(pending)/n*100) / 100
+		point.Running = math.Round(float64(running)/n*100) / 100
+		point.IdleRunners = math.Round(float64(idle)/n*100) / 100
+		pending, running, idle = 0, 0, 0
+	}
+
+	for _, sample := range samples {
+		at := start.Add(sample.SampledAt.Sub(start) / bucket * bucket)
+
+		if len(points) == 0 || !points[len(points)-1].Time.Equal(at) {
+			flush()
+
+			points = append(points, QueueStatsPoint{Time: at, MinIdleRunners: sample.IdleRunners})
+		}
+
+		point := &points[len(points)-1]
+		point.Samples++
+		point.MaxPending = max(point.MaxPending, sample.Pending)
+		point.MinIdleRunners = min(point.MinIdleRunners, sample.IdleRunners)
+		pending += sample.Pending
+		running += sample.Running
+		idle += sample.IdleRunners
+	}
+
+	flush()
+
+	return points
+}
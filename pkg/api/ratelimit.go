@@ -2,19 +2,22 @@ package api
 
 import (
 	"net/http"
+	"net/netip"
 	"strconv"
 	"sync"
 	"time"
 
+	"github.com/ethpandaops/dispatchoor/pkg/config"
 	"golang.org/x/time/rate"
 )
 
 // IPRateLimiter provides per-IP rate limiting middleware.
 type IPRateLimiter struct {
-	visitors map[string]*visitorEntry
-	mu       sync.RWMutex
-	rate     rate.Limit
-	burst    int
+	visitors  map[string]*visitorEntry
+	mu        sync.RWMutex
+	rate      rate.Limit
+	burst     int
+	whitelist []netip.Prefix
 }
 
 // visitorEntry holds the rate limiter and last seen time for a visitor.
@@ -23,12 +26,14 @@ type visitorEntry struct {
 	lastSeen time.Time
 }
 
-// NewIPRateLimiter creates a new IP-based rate limiter.
-func NewIPRateLimiter(requestsPerMinute int) *IPRateLimiter {
+// NewIPRateLimiter creates a new IP-based rate limiter. Clients within
+// whitelist are never limited.
+func NewIPRateLimiter(tier config.RateLimitTierConfig, whitelist []netip.Prefix) *IPRateLimiter {
 	rl := &IPRateLimiter{
-		visitors: make(map[string]*visitorEntry, 256),
-		rate:     rate.Limit(float64(requestsPerMinute) / 60.0),
-		burst:    requestsPerMinute,
+		visitors:  make(map[string]*visitorEntry, 256),
+		rate:      rate.Limit(float64(tier.RequestsPerMinute) / 60.0),
+		burst:     tier.Burst,
+		whitelist: whitelist,
 	}
 
 	// Start cleanup goroutine to remove stale entries.
@@ -61,7 +66,13 @@ func (l *IPRateLimiter) getLimiter(ip string) *rate.Limiter {
 // Middleware returns an HTTP middleware that enforces rate limiting per IP.
 func (l *IPRateLimiter) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := r.RemoteAddr // chi's RealIP middleware sets this
+		ip := r.RemoteAddr // realIPMiddleware sets this
+		if l.whitelisted(ip) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
 		limiter := l.getLimiter(ip)
 
 		if !limiter.Allow() {
@@ -78,6 +89,27 @@ func (l *IPRateLimiter) Middleware(next http.Handler) http.Handler {
 	})
 }
 
+// whitelisted reports whether the client address, with or without a port, is
+// within the whitelist.
+func (l *IPRateLimiter) whitelisted(remoteAddr string) bool {
+	if len(l.whitelist) == 0 {
+		return false
+	}
+
+	addr, ok := parseIP(remoteAddr)
+	if !ok {
+		return false
+	}
+
+	for _, prefix := range l.whitelist {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // cleanupLoop periodically removes stale IP entries.
 func (l *IPRateLimiter) cleanupLoop() {
 	ticker := time.NewTicker(10 * time.Minute)
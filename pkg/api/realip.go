@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// realIPMiddleware sets RemoteAddr to the client IP named by the
+// X-Forwarded-For header or, without one, the True-Client-IP or X-Real-IP
+// headers, but only for requests from a trusted proxy, so clients connecting
+// directly cannot choose the IP that rate limits and sessions see. Without
+// trusted proxies the headers are ignored. Either way RemoteAddr is left as a
+// bare IP, so a client's connections share one rate limit whatever their port.
+func realIPMiddleware(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	isTrusted := func(addr netip.Addr) bool {
+		for _, prefix := range trusted {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			peer, ok := parseIP(r.RemoteAddr)
+			if !ok {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			r.RemoteAddr = peer.String()
+
+			if !isTrusted(peer) {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			if ip := forwardedClientIP(r, isTrusted); ip.IsValid() {
+				r.RemoteAddr = ip.String()
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedClientIP returns the client IP a trusted proxy forwarded, or the
+// zero Addr. X-Forwarded-For is read from the right, skipping trusted proxies,
+// because entries to the left of the last untrusted hop are client-supplied.
+// True-Client-IP and X-Real-IP, which a client may have set before reaching
+// the proxy, are only read without X-Forwarded-For.
+func forwardedClientIP(r *http.Request, isTrusted func(netip.Addr) bool) netip.Addr {
+	forwarded := r.Header.Values("X-Forwarded-For")
+	if len(forwarded) == 0 {
+		for _, header := range []string{"True-Client-IP", "X-Real-IP"} {
+			if value := r.Header.Get(header); value != "" {
+				ip, _ := parseIP(strings.TrimSpace(value))
+
+				return ip
+			}
+		}
+
+		return netip.Addr{}
+	}
+
+	hops := strings.Split(strings.Join(forwarded, ","), ",")
+
+	var client netip.Addr
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip, ok := parseIP(strings.TrimSpace(hops[i]))
+		if !ok {
+			break
+		}
+
+		client = ip
+
+		if !isTrusted(ip) {
+			break
+		}
+	}
+
+	return client
+}
+
+// parseIP parses an IP, with or without a port.
+func parseIP(addr string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return ip.Unmap(), true
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/sirupsen/logrus"
+)
+
+// checkJobRef returns a user-facing error message when queue.strict_refs is on
+// and the ref a job would be dispatched with does not exist in its repository,
+// or "" otherwise. The job's template supplies the owner, repo and ref not
+// given. When GitHub cannot be asked, the job is accepted and a bad ref still
+// fails at dispatch.
+func (s *server) checkJobRef(ctx context.Context, templateID, owner, repo, ref string) (string, error) {
+	if !s.cfg.Queue.StrictRefs || s.dispatchClient == nil || !s.dispatchClient.IsConnected() {
+		return "", nil
+	}
+
+	if templateID != "" {
+		template, err := s.store.GetJobTemplate(ctx, templateID)
+		if err != nil {
+			return "", fmt.Errorf("getting job template: %w", err)
+		}
+
+		// Unknown templates are reported by the queue.
+		if template == nil {
+			return "", nil
+		}
+
+		owner = cmp.Or(owner, template.Owner)
+		repo = cmp.Or(repo, template.Repo)
+		ref = cmp.Or(ref, template.Ref)
+	}
+
+	if owner == "" || repo == "" || ref == "" {
+		return "", nil
+	}
+
+	if _, err := s.dispatchClient.ResolveRef(ctx, owner, repo, ref); err != nil {
+		if errors.Is(err, github.ErrRefNotFound) {
+			return fmt.Sprintf("Ref %q does not exist in %s/%s", ref, owner, repo), nil
+		}
+
+		s.log.WithError(err).WithFields(logrus.Fields{
+			"owner": owner,
+			"repo":  repo,
+			"ref":   ref,
+		}).Warn("Failed to check job ref, accepting job")
+	}
+
+	return "", nil
+}
+
+// checkUpdatedJobRef checks the ref a job would be dispatched with after an
+// update changing its owner, repo or ref.
+func (s *server) checkUpdatedJobRef(ctx context.Context, jobID string, req *UpdateJobRequest) (string, error) {
+	job, err := s.queue.GetJob(ctx, jobID)
+	if err != nil {
+		return "", fmt.Errorf("getting job: %w", err)
+	}
+
+	// Missing jobs are reported by the update.
+	if job == nil {
+		return "", nil
+	}
+
+	value := func(update, current *string) string {
+		if update != nil {
+			return *update
+		}
+
+		if current != nil {
+			return *current
+		}
+
+		return ""
+	}
+
+	return s.checkJobRef(ctx, job.TemplateID,
+		value(req.Owner, job.Owner), value(req.Repo, job.Repo), value(req.Ref, job.Ref))
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/auth"
+	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// RestoreJobRequest is the request body for rebuilding a history job from a
+// GitHub workflow run.
+type RestoreJobRequest struct {
+	// RunURL is the workflow run the job is rebuilt from.
+	RunURL string `json:"run_url" example:"https://github.com/ethpandaops/syncoor-tests/actions/runs/123456789"`
+	// TemplateID links the job to one of the group's templates. Without it the
+	// job is restored as a manual job using the run's repository, workflow and branch.
+	TemplateID string `json:"template_id,omitempty" example:"sync-test-hoodi-geth-prysm"`
+	// Inputs are the inputs the run was dispatched with, which GitHub does not return.
+	Inputs input.Map `json:"inputs,omitempty"`
+	// CreatedBy is who the job is attributed to (defaults to the restoring admin).
+	CreatedBy string `json:"created_by,omitempty" example:"alice"`
+}
+
+// handleRestoreJob godoc
+//
+//	@Summary		Restore history job from run
+//	@Description	Rebuilds a finished job from a completed GitHub workflow run, e.g. after its record was deleted by accident. Status, conclusion, timings, runner and commit are read from GitHub; inputs are not returned by GitHub and are taken from the request (requires admin)
+//	@Tags			history
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Group ID"
+//	@Param			body	body		RestoreJobRequest	true	"Run to restore"
+//	@Success		201		{object}	store.Job
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Failure		503		{object}	ErrorResponse
+//	@Router			/groups/{id}/history/restore [post]
+func (s *server) handleRestoreJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	groupID := chi.URLParam(r, "id")
+
+	var req RestoreJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if msg := s.checkJobLimits(req.Inputs, nil); msg != "" {
+		s.writeError(w, http.StatusBadRequest, msg)
+
+		return
+	}
+
+	owner, repo, runID, err := github.ParseRunURL(req.RunURL)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	group, err := s.store.GetGroup(ctx, groupID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get group")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get group")
+
+		return
+	}
+
+	if group == nil {
+		s.writeError(w, http.StatusNotFound, "Group not found")
+
+		return
+	}
+
+	existing, err := s.store.GetJobByRunID(ctx, runID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to look up job by run ID")
+		s.writeError(w, http.StatusInternalServerError, "Failed to look up job by run ID")
+
+		return
+	}
+
+	if existing != nil {
+		s.writeError(w, http.StatusConflict, fmt.Sprintf("Run %d is already recorded as job %s", runID, existing.ID))
+
+		return
+	}
+
+	var template *store.JobTemplate
+
+	if req.TemplateID != "" {
+		template, err = s.store.GetJobTemplate(ctx, req.TemplateID)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to get template")
+			s.writeError(w, http.StatusInternalServerError, "Failed to get template")
+
+			return
+		}
+
+		if template == nil || template.GroupID != groupID {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Template %s not found in group %s", req.TemplateID, groupID))
+
+			return
+		}
+
+		if !strings.EqualFold(template.Owner, owner) || !strings.EqualFold(template.Repo, repo) {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Run belongs to %s/%s, but template %s dispatches to %s/%s",
+				owner, repo, template.ID, template.Owner, template.Repo))
+
+			return
+		}
+	}
+
+	if s.dispatchClient == nil || !s.dispatchClient.IsConnected() {
+		s.writeError(w, http.StatusServiceUnavailable, "GitHub integration is not available")
+
+		return
+	}
+
+	run, err := s.dispatchClient.GetWorkflowRun(ctx, owner, repo, runID)
+	if err != nil {
+		s.log.WithError(err).WithField("run_id", runID).Error("Failed to get workflow run")
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Failed to get workflow run: %v", err))
+
+		return
+	}
+
+	if run.Status != "completed" {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Run %d is %s; only completed runs can be restored", runID, run.Status))
+
+		return
+	}
+
+	createdBy := strings.TrimSpace(req.CreatedBy)
+
+	actor := "anonymous"
+	if user := auth.UserFromContext(ctx); user != nil {
+		actor = user.Username
+	}
+
+	if createdBy == "" {
+		createdBy = actor
+	}
+
+	job := restoredJob(run, groupID, createdBy, req.Inputs)
+
+	if template != nil {
+		job.TemplateID = template.ID
+	} else {
+		workflowID := strconv.FormatInt(run.WorkflowID, 10)
+		job.Name = &run.Name
+		job.Owner = &owner
+		job.Repo = &repo
+		job.WorkflowID = &workflowID
+		job.Ref = &run.HeadBranch
+	}
+
+	// The runner is informational; a run whose jobs can no longer be listed is
+	// still restored.
+	if jobs, err := s.dispatchClient.ListWorkflowRunJobs(ctx, owner, repo, runID); err != nil {
+		s.log.WithError(err).WithField("run_id", runID).Warn("Failed to get workflow jobs for runner info")
+	} else {
+		for _, j := range jobs {
+			if j.RunnerID != 0 {
+				job.RunnerID = &j.RunnerID
+				job.RunnerName = j.RunnerName
+
+				break
+			}
+		}
+	}
+
+	// CreateJob only stores the fields of a new job; the run details are
+	// written by the update.
+	if err := s.store.CreateJob(ctx, job); err != nil {
+		s.log.WithError(err).Error("Failed to create restored job")
+		s.writeError(w, http.StatusInternalServerError, "Failed to create job")
+
+		return
+	}
+
+	if err := s.store.UpdateJob(ctx, job); err != nil {
+		s.log.WithError(err).Error("Failed to store restored job details")
+		s.writeError(w, http.StatusInternalServerError, "Failed to create job")
+
+		return
+	}
+
+	if err := s.store.CreateJobEvent(ctx, &store.JobEvent{
+		ID:         uuid.New().String(),
+		JobID:      job.ID,
+		ToStatus:   job.Status,
+		Actor:      actor,
+		RunID:      job.RunID,
+		RunnerName: job.RunnerName,
+		Message:    fmt.Sprintf("Restored from run %s", run.HTMLURL),
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		s.log.WithError(err).Warn("Failed to record job event for restored job")
+	}
+
+	if err := s.store.CreateAuditEntry(ctx, &store.AuditEntry{
+		ID:         uuid.New().String(),
+		Action:     store.AuditActionJobRestored,
+		EntityType: store.AuditEntityJob,
+		EntityID:   job.ID,
+		Actor:      actor,
+		Details:    fmt.Sprintf("Restored from run %s (%s)", run.HTMLURL, job.Status),
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		s.log.WithError(err).Warn("Failed to create audit entry for restored job")
+	}
+
+	s.writeJSON(w, http.StatusCreated, job)
+}
+
+// restoredJob builds a finished job from a completed workflow run. Runs have no
+// separate completion time, so the run's last update is used.
+func restoredJob(run *github.WorkflowRun, groupID, createdBy string, inputs input.Map) *store.Job {
+	triggeredAt := run.CreatedAt
+	completedAt := run.UpdatedAt
+	runID := run.ID
+
+	job := &store.Job{
+		ID:          uuid.New().String(),
+		GroupID:     groupID,
+		Status:      store.JobStatusCompleted,
+		Inputs:      inputs,
+		CreatedBy:   createdBy,
+		TriggeredAt: &triggeredAt,
+		RunID:       &runID,
+		RunURL:      run.HTMLURL,
+		CompletedAt: &completedAt,
+		ResolvedSHA: run.HeadSHA,
+		CreatedAt:   run.CreatedAt,
+		UpdatedAt:   run.UpdatedAt,
+	}
+
+	switch run.Conclusion {
+	case "success":
+	case "cancelled":
+		job.Status = store.JobStatusCancelled
+	default:
+		job.Status = store.JobStatusFailed
+		job.ErrorMessage = fmt.Sprintf("Workflow %s", run.Conclusion)
+	}
+
+	return job
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ethpandaops/dispatchoor/pkg/auth"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+)
+
+const (
+	// defaultSearchLimit is the number of matches returned per type by default.
+	defaultSearchLimit = 10
+	// maxSearchLimit bounds the matches returned per type.
+	maxSearchLimit = 50
+	// minJobIDPrefixLength is the shortest job ID prefix searched for, so short
+	// queries do not match a large part of the history.
+	minJobIDPrefixLength = 4
+)
+
+// SearchResultType is the kind of entity a search result names.
+type SearchResultType string
+
+const (
+	SearchResultGroup    SearchResultType = "group"
+	SearchResultTemplate SearchResultType = "template"
+	SearchResultJob      SearchResultType = "job"
+	SearchResultRunner   SearchResultType = "runner"
+)
+
+// SearchResponse holds the matches of a search, grouped by type in the order
+// groups, templates, jobs, runners, best match first within each type.
+type SearchResponse struct {
+	Query   string         `json:"query" example:"hoodi"`
+	Results []SearchResult `json:"results"`
+}
+
+// SearchResult is one entity matching a search.
+type SearchResult struct {
+	Type SearchResultType `json:"type" example:"template"`
+	ID   string           `json:"id" example:"sync-test-hoodi-geth-prysm"`
+	Name string           `json:"name" example:"Sync Test Hoodi (geth/prysm)"`
+	// GroupID is the group of templates and jobs.
+	GroupID string `json:"group_id,omitempty" example:"sync-tests"`
+	// Status is the status of jobs and runners.
+	Status string `json:"status,omitempty" example:"running"`
+}
+
+// handleSearch godoc
+//
+//	@Summary		Search
+//	@Description	Finds groups and templates whose ID or name contains the query, jobs whose ID starts with it (at least 4 characters) or whose workflow run ID equals it, and runners whose name contains it. Matching is case-insensitive. Groups the user cannot view, and their templates and jobs, are left out.
+//	@Tags			system
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			q		query		string	true	"Search query"
+//	@Param			limit	query		int		false	"Maximum matches per type (default 10, max 50)"
+//	@Success		200		{object}	SearchResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/search [get]
+func (s *server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := auth.UserFromContext(ctx)
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		s.writeError(w, http.StatusBadRequest, "Query is required")
+
+		return
+	}
+
+	limit := defaultSearchLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxSearchLimit)
+	}
+
+	needle := strings.ToLower(query)
+	resp := SearchResponse{Query: query, Results: []SearchResult{}}
+
+	groups, err := s.store.ListGroups(ctx)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list groups")
+		s.writeError(w, http.StatusInternalServerError, "Failed to search")
+
+		return
+	}
+
+	var groupMatches, templateMatches []scoredResult
+
+	for _, group := range groups {
+		if !s.canViewGroup(user, group.ID) {
+			continue
+		}
+
+		if score := matchScore(needle, group.ID, group.Name); score > 0 {
+			groupMatches = append(groupMatches, scoredResult{score: score, result: SearchResult{
+				Type: SearchResultGroup, ID: group.ID, Name: group.Name,
+			}})
+		}
+
+		templates, err := s.store.ListJobTemplatesByGroup(ctx, group.ID)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to list job templates")
+			s.writeError(w, http.StatusInternalServerError, "Failed to search")
+
+			return
+		}
+
+		for _, template := range templates {
+			if score := matchScore(needle, template.ID, template.Name); score > 0 {
+				templateMatches = append(templateMatches, scoredResult{score: score, result: SearchResult{
+					Type: SearchResultTemplate, ID: template.ID, Name: template.Name, GroupID: group.ID,
+				}})
+			}
+		}
+	}
+
+	jobs, err := s.searchJobs(ctx, user, needle, limit)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to search jobs")
+		s.writeError(w, http.StatusInternalServerError, "Failed to search")
+
+		return
+	}
+
+	runners, err := s.store.ListRunners(ctx)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list runners")
+		s.writeError(w, http.StatusInternalServerError, "Failed to search")
+
+		return
+	}
+
+	var runnerMatches []scoredResult
+
+	for _, runner := range runners {
+		if score := matchScore(needle, runner.Name); score > 0 {
+			runnerMatches = append(runnerMatches, scoredResult{score: score, result: SearchResult{
+				Type: SearchResultRunner, ID: strconv.FormatInt(runner.ID, 10), Name: runner.Name,
+				Status: string(runner.Status),
+			}})
+		}
+	}
+
+	resp.Results = appendBest(resp.Results, groupMatches, limit)
+	resp.Results = appendBest(resp.Results, templateMatches, limit)
+	resp.Results = append(resp.Results, jobs...)
+	resp.Results = appendBest(resp.Results, runnerMatches, limit)
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// searchJobs returns the jobs of visible groups whose workflow run ID equals
+// needle or whose ID starts with it, the run match first.
+func (s *server) searchJobs(ctx context.Context, user *store.User, needle string, limit int) ([]SearchResult, error) {
+	var jobs []*store.Job
+
+	if runID, err := strconv.ParseInt(needle, 10, 64); err == nil && runID > 0 {
+		job, err := s.store.GetJobByRunID(ctx, runID)
+		if err != nil {
+			return nil, err
+		}
+
+		if job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+
+	// Job IDs are UUIDs, so other characters cannot match and LIKE wildcards
+	// never reach the query.
+	if len(needle) >= minJobIDPrefixLength && strings.Trim(needle, "0123456789abcdef-") == "" {
+		byID, err := s.store.ListJobsByIDPrefix(ctx, needle, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, byID...)
+	}
+
+	results := make([]SearchResult, 0, len(jobs))
+	seen := make(map[string]struct{}, len(jobs))
+
+	for _, job := range jobs {
+		if _, ok := seen[job.ID]; ok || !s.canViewGroup(user, job.GroupID) {
+			continue
+		}
+
+		seen[job.ID] = struct{}{}
+
+		name := job.TemplateID
+		if job.Name != nil && *job.Name != "" {
+			name = *job.Name
+		}
+
+		results = append(results, SearchResult{
+			Type: SearchResultJob, ID: job.ID, Name: name, GroupID: job.GroupID, Status: string(job.Status),
+		})
+
+		if len(results) == limit {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// scoredResult is a search result with how well it matched.
+type scoredResult struct {
+	score  int
+	result SearchResult
+}
+
+// matchScore rates how well the lowercase needle matches the best of values:
+// 3 for an exact match, 2 for a prefix, 1 for a substring and 0 otherwise.
+func matchScore(needle string, values ...string) int {
+	best := 0
+
+	for _, value := range values {
+		value = strings.ToLower(value)
+
+		switch {
+		case value == needle:
+			return 3
+		case strings.HasPrefix(value, needle):
+			best = max(best, 2)
+		case strings.Contains(value, needle):
+			best = max(best, 1)
+		}
+	}
+
+	return best
+}
+
+// appendBest appends up to limit of the matches to results, best match first
+// and by ID among equal matches.
+func appendBest(results []SearchResult, matches []scoredResult, limit int) []SearchResult {
+	slices.SortFunc(matches, func(a, b scoredResult) int {
+		return cmp.Or(cmp.Compare(b.score, a.score), cmp.Compare(a.result.ID, b.result.ID))
+	})
+
+	for i := 0; i < len(matches) && i < limit; i++ {
+		results = append(results, matches[i].result)
+	}
+
+	return results
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/auth"
+	"github.com/ethpandaops/dispatchoor/pkg/settings"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/google/uuid"
+)
+
+// SettingsValues are runtime settings, with intervals as Go durations.
+type SettingsValues struct {
+	DispatchInterval string `json:"dispatch_interval" example:"10s"`
+	TrackingInterval string `json:"tracking_interval" example:"30s"`
+	RateLimitBuffer  int    `json:"rate_limit_buffer" example:"100"`
+}
+
+// SettingsResponse is the response for the runtime settings.
+type SettingsResponse struct {
+	// Effective are the values in use.
+	Effective SettingsValues `json:"effective"`
+	// Defaults are the values from the configuration.
+	Defaults SettingsValues `json:"defaults"`
+	// Overrides are the persisted values replacing their defaults.
+	Overrides []*store.Setting `json:"overrides"`
+}
+
+// UpdateSettingsRequest is the request body for changing runtime settings.
+// Settings left out keep their current value.
+type UpdateSettingsRequest struct {
+	DispatchInterval *string `json:"dispatch_interval,omitempty" example:"10s"`
+	TrackingInterval *string `json:"tracking_interval,omitempty" example:"30s"`
+	RateLimitBuffer  *int    `json:"rate_limit_buffer,omitempty" example:"200"`
+	// Reset returns the named settings to their configured defaults.
+	Reset []string `json:"reset,omitempty" example:"tracking_interval"`
+}
+
+// newSettingsValues converts runtime settings for the API.
+func newSettingsValues(values settings.Values) SettingsValues {
+	return SettingsValues{
+		DispatchInterval: values.DispatchInterval.String(),
+		TrackingInterval: values.TrackingInterval.String(),
+		RateLimitBuffer:  values.RateLimitBuffer,
+	}
+}
+
+// handleGetSettings godoc
+//
+//	@Summary		Get runtime settings
+//	@Description	Returns the runtime-tunable settings in effect, their configured defaults and the persisted overrides (requires admin)
+//	@Tags			admin
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{object}	SettingsResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Failure		503	{object}	ErrorResponse
+//	@Router			/admin/settings [get]
+func (s *server) handleGetSettings(w http.ResponseWriter, r *http.Request) {
+	s.writeSettings(w, r)
+}
+
+// handleUpdateSettings godoc
+//
+//	@Summary		Update runtime settings
+//	@Description	Overrides or resets runtime-tunable settings. Changes are persisted and applied without a restart, on every replica (requires admin)
+//	@Tags			admin
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		UpdateSettingsRequest	true	"Settings to change"
+//	@Success		200		{object}	SettingsResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Failure		503		{object}	ErrorResponse
+//	@Router			/admin/settings [put]
+func (s *server) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
+	if s.settings == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Runtime settings are not available")
+
+		return
+	}
+
+	var req UpdateSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	update := settings.Update{
+		RateLimitBuffer: req.RateLimitBuffer,
+		Reset:           req.Reset,
+	}
+
+	var changes []string
+
+	for _, field := range []struct {
+		key   string
+		value *string
+		dst   **time.Duration
+	}{
+		{settings.KeyDispatchInterval, req.DispatchInterval, &update.DispatchInterval},
+		{settings.KeyTrackingInterval, req.TrackingInterval, &update.TrackingInterval},
+	} {
+		if field.value == nil {
+			continue
+		}
+
+		interval, err := time.ParseDuration(*field.value)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s: %s", field.key, *field.value))
+
+			return
+		}
+
+		*field.dst = &interval
+
+		changes = append(changes, fmt.Sprintf("%s=%s", field.key, interval))
+	}
+
+	if req.RateLimitBuffer != nil {
+		changes = append(changes, fmt.Sprintf("%s=%d", settings.KeyRateLimitBuffer, *req.RateLimitBuffer))
+	}
+
+	for _, key := range req.Reset {
+		changes = append(changes, key+" reset")
+	}
+
+	if len(changes) == 0 {
+		s.writeError(w, http.StatusBadRequest, "No settings to change")
+
+		return
+	}
+
+	actor := "anonymous"
+	if user := auth.UserFromContext(r.Context()); user != nil {
+		actor = user.Username
+	}
+
+	if _, err := s.settings.Update(r.Context(), update, actor); err != nil {
+		if errors.Is(err, settings.ErrInvalid) {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+
+			return
+		}
+
+		s.log.WithError(err).Error("Failed to update runtime settings")
+		s.writeError(w, http.StatusInternalServerError, "Failed to update runtime settings")
+
+		return
+	}
+
+	if err := s.store.CreateAuditEntry(r.Context(), &store.AuditEntry{
+		ID:         uuid.New().String(),
+		Action:     store.AuditActionSettingsUpdated,
+		EntityType: store.AuditEntitySystem,
+		EntityID:   "settings",
+		Actor:      actor,
+		Details:    strings.Join(changes, ", "),
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		s.log.WithError(err).Warn("Failed to create audit entry for settings update")
+	}
+
+	s.writeSettings(w, r)
+}
+
+// writeSettings writes the current runtime settings.
+func (s *server) writeSettings(w http.ResponseWriter, r *http.Request) {
+	if s.settings == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Runtime settings are not available")
+
+		return
+	}
+
+	overrides, err := s.store.ListSettings(r.Context())
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list runtime settings")
+		s.writeError(w, http.StatusInternalServerError, "Failed to list runtime settings")
+
+		return
+	}
+
+	if overrides == nil {
+		overrides = []*store.Setting{}
+	}
+
+	s.writeJSON(w, http.StatusOK, SettingsResponse{
+		Effective: newSettingsValues(s.settings.Values()),
+		Defaults:  newSettingsValues(s.settings.Defaults()),
+		Overrides: overrides,
+	})
+}
@@ -0,0 +1,288 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/auth"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxSubscriptionsPerUser bounds the subscriptions a user can hold.
+const maxSubscriptionsPerUser = 100
+
+// SubscriptionRequest is the request body for creating a subscription.
+type SubscriptionRequest struct {
+	TargetType store.SubscriptionTarget  `json:"target_type" example:"template"`
+	TargetID   string                    `json:"target_id" example:"sync-test-hoodi-geth-prysm"`
+	Channel    store.SubscriptionChannel `json:"channel" example:"slack"`
+	// Destination is an email address for email, or an http(s) URL for slack
+	// and webhook.
+	Destination string `json:"destination" example:"https://hooks.slack.com/services/T000/B000/XXXX"`
+	// Statuses limits notifications to transitions into these statuses
+	// (empty = every transition).
+	Statuses []store.JobStatus `json:"statuses,omitempty"`
+}
+
+// handleListSubscriptions godoc
+//
+//	@Summary		List subscriptions
+//	@Description	Returns the current user's job and template subscriptions
+//	@Tags			subscriptions
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{array}		store.Subscription
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/subscriptions [get]
+func (s *server) handleListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		s.writeError(w, http.StatusUnauthorized, "Not authenticated")
+
+		return
+	}
+
+	subs, err := s.store.ListSubscriptionsByUser(r.Context(), user.ID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list subscriptions")
+		s.writeError(w, http.StatusInternalServerError, "Failed to list subscriptions")
+
+		return
+	}
+
+	if subs == nil {
+		subs = []*store.Subscription{}
+	}
+
+	s.writeJSON(w, http.StatusOK, subs)
+}
+
+// handleCreateSubscription godoc
+//
+//	@Summary		Create subscription
+//	@Description	Subscribes the current user to the state transitions of a job, or of every job of a template. Notifications are sent by email, to a Slack incoming webhook or as JSON to a webhook. Job subscriptions are removed once the job finishes.
+//	@Tags			subscriptions
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		SubscriptionRequest	true	"Subscription"
+//	@Success		201		{object}	store.Subscription
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/subscriptions [post]
+func (s *server) handleCreateSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	user := auth.UserFromContext(ctx)
+	if user == nil {
+		s.writeError(w, http.StatusUnauthorized, "Not authenticated")
+
+		return
+	}
+
+	var req SubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if msg := s.validateSubscription(&req); msg != "" {
+		s.writeError(w, http.StatusBadRequest, msg)
+
+		return
+	}
+
+	groupID, err := s.subscriptionTargetGroup(ctx, &req)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get subscription target")
+		s.writeError(w, http.StatusInternalServerError, "Failed to create subscription")
+
+		return
+	}
+
+	// Targets in groups the user cannot see are reported as missing.
+	if groupID == "" || !s.canViewGroup(user, groupID) {
+		if req.TargetType == store.SubscriptionTargetJob {
+			s.writeError(w, http.StatusNotFound, "Job not found")
+		} else {
+			s.writeError(w, http.StatusNotFound, "Template not found")
+		}
+
+		return
+	}
+
+	existing, err := s.store.ListSubscriptionsByUser(ctx, user.ID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list subscriptions")
+		s.writeError(w, http.StatusInternalServerError, "Failed to create subscription")
+
+		return
+	}
+
+	if len(existing) >= maxSubscriptionsPerUser {
+		s.writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("At most %d subscriptions are allowed per user", maxSubscriptionsPerUser))
+
+		return
+	}
+
+	sub := &store.Subscription{
+		ID:          uuid.New().String(),
+		UserID:      user.ID,
+		TargetType:  req.TargetType,
+		TargetID:    req.TargetID,
+		Channel:     req.Channel,
+		Destination: req.Destination,
+		Statuses:    req.Statuses,
+		CreatedAt:   time.Now(),
+	}
+
+	if sub.Statuses == nil {
+		sub.Statuses = []store.JobStatus{}
+	}
+
+	if err := s.store.CreateSubscription(ctx, sub); err != nil {
+		s.log.WithError(err).Error("Failed to create subscription")
+		s.writeError(w, http.StatusInternalServerError, "Failed to create subscription")
+
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, sub)
+}
+
+// handleDeleteSubscription godoc
+//
+//	@Summary		Delete subscription
+//	@Description	Deletes a subscription of the current user
+//	@Tags			subscriptions
+//	@Security		BearerAuth
+//	@Param			id	path	string	true	"Subscription ID"
+//	@Success		204	"Subscription deleted"
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/subscriptions/{id} [delete]
+func (s *server) handleDeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		s.writeError(w, http.StatusUnauthorized, "Not authenticated")
+
+		return
+	}
+
+	sub, err := s.store.GetSubscription(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get subscription")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get subscription")
+
+		return
+	}
+
+	// Other users' subscriptions are reported as missing rather than forbidden.
+	if sub == nil || sub.UserID != user.ID {
+		s.writeError(w, http.StatusNotFound, "Subscription not found")
+
+		return
+	}
+
+	if err := s.store.DeleteSubscription(r.Context(), sub.ID); err != nil {
+		s.log.WithError(err).Error("Failed to delete subscription")
+		s.writeError(w, http.StatusInternalServerError, "Failed to delete subscription")
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateSubscription checks a subscription request and returns a
+// user-facing error message.
+func (s *server) validateSubscription(req *SubscriptionRequest) string {
+	if req.TargetType != store.SubscriptionTargetJob && req.TargetType != store.SubscriptionTargetTemplate {
+		return "Target type must be 'job' or 'template'"
+	}
+
+	if req.TargetID == "" {
+		return "Target ID is required"
+	}
+
+	return s.validateDelivery(req.Channel, &req.Destination, req.Statuses)
+}
+
+// validateDelivery checks where and for which statuses notifications are
+// sent, normalizing destination, and returns a user-facing error message.
+// It is shared by subscriptions and job notify targets.
+func (s *server) validateDelivery(channel store.SubscriptionChannel, destination *string, statuses []store.JobStatus) string {
+	*destination = strings.TrimSpace(*destination)
+	if *destination == "" {
+		return "Destination is required"
+	}
+
+	switch channel {
+	case store.SubscriptionChannelEmail:
+		if s.cfg.Notifications.Email.Host == "" {
+			return "Email notifications are not configured"
+		}
+
+		addr, err := mail.ParseAddress(*destination)
+		if err != nil || addr.Name != "" {
+			return "Destination must be an email address"
+		}
+
+		*destination = addr.Address
+	case store.SubscriptionChannelSlack, store.SubscriptionChannelWebhook:
+		u, err := url.Parse(*destination)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "Destination must be an http or https URL"
+		}
+	default:
+		return "Channel must be 'email', 'slack' or 'webhook'"
+	}
+
+	valid := []store.JobStatus{
+		store.JobStatusPending, store.JobStatusTriggered, store.JobStatusRunning,
+		store.JobStatusCompleted, store.JobStatusFailed, store.JobStatusCancelled,
+	}
+
+	for _, status := range statuses {
+		if !slices.Contains(valid, status) {
+			return fmt.Sprintf("Invalid status %q", status)
+		}
+	}
+
+	return ""
+}
+
+// subscriptionTargetGroup returns the group of the job or template a
+// subscription follows, or "" if it does not exist.
+func (s *server) subscriptionTargetGroup(ctx context.Context, req *SubscriptionRequest) (string, error) {
+	if req.TargetType == store.SubscriptionTargetJob {
+		job, err := s.queue.GetJob(ctx, req.TargetID)
+		if err != nil || job == nil {
+			return "", err
+		}
+
+		return job.GroupID, nil
+	}
+
+	template, err := s.store.GetJobTemplate(ctx, req.TargetID)
+	if err != nil || template == nil {
+		return "", err
+	}
+
+	return template.GroupID, nil
+}
@@ -0,0 +1,448 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/auth"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxBulkEditTemplates bounds the templates changed by one bulk edit.
+const maxBulkEditTemplates = 500
+
+// CloneTemplateRequest is the request body for cloning a template.
+type CloneTemplateRequest struct {
+	// ID of the new template.
+	ID string `json:"id" example:"sync-geth-prysm-holesky"`
+	// Name of the new template (default: the source name with " (copy)").
+	Name string `json:"name,omitempty" example:"Sync Test geth/prysm (holesky)"`
+	// GroupID to create the template in (default: the source's group).
+	GroupID string `json:"group_id,omitempty" example:"sync-tests"`
+	Owner   string `json:"owner,omitempty" example:"ethpandaops"`
+	Repo    string `json:"repo,omitempty" example:"syncoor-tests"`
+	Ref     string `json:"ref,omitempty" example:"main"`
+}
+
+// BulkEditTemplatesRequest is the request body for changing the owner, repo
+// or ref of several templates at once.
+type BulkEditTemplatesRequest struct {
+	TemplateIDs []string `json:"template_ids" example:"sync-geth-prysm,sync-geth-lighthouse"`
+	Owner       *string  `json:"owner,omitempty" example:"ethpandaops"`
+	Repo        *string  `json:"repo,omitempty" example:"syncoor-tests"`
+	Ref         *string  `json:"ref,omitempty" example:"main"`
+	// Reset drops the overrides of templates from config, returning them to
+	// the owner, repo and ref in the config.
+	Reset bool `json:"reset,omitempty"`
+}
+
+// BulkEditTemplatesResponse is the response for a bulk template edit.
+type BulkEditTemplatesResponse struct {
+	Templates []*store.JobTemplate `json:"templates"`
+}
+
+// handleCloneJobTemplate godoc
+//
+//	@Summary		Clone job template
+//	@Description	Copies a template under a new ID, optionally into another group and with a different owner, repo or ref. The clone is managed through the API: config syncs neither update nor remove it (requires admin).
+//	@Tags			templates
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string					true	"Template ID"
+//	@Param			body	body		CloneTemplateRequest	true	"Clone"
+//	@Success		201		{object}	store.JobTemplate
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/templates/{id}/clone [post]
+func (s *server) handleCloneJobTemplate(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req CloneTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	req.ID = strings.TrimSpace(req.ID)
+	if req.ID == "" || strings.ContainsAny(req.ID, "/ ") {
+		s.writeError(w, http.StatusBadRequest, "id is required and must not contain slashes or spaces")
+
+		return
+	}
+
+	source, err := s.store.GetJobTemplate(r.Context(), id)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get job template")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get job template")
+
+		return
+	}
+
+	if source == nil {
+		s.writeError(w, http.StatusNotFound, "Job template not found")
+
+		return
+	}
+
+	existing, err := s.store.GetJobTemplate(r.Context(), req.ID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get job template")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get job template")
+
+		return
+	}
+
+	if existing != nil {
+		s.writeError(w, http.StatusConflict, fmt.Sprintf("Template %s already exists", req.ID))
+
+		return
+	}
+
+	clone := *source
+	clone.ID = req.ID
+	clone.Name = source.Name + " (copy)"
+	clone.InConfig = true
+	clone.SourceType = store.TemplateSourceAPI
+	clone.SourcePath = ""
+	clone.Overrides = nil
+	clone.CreatedAt = time.Now()
+	clone.UpdatedAt = clone.CreatedAt
+
+	if req.Name != "" {
+		clone.Name = req.Name
+	}
+
+	if req.GroupID != "" && req.GroupID != source.GroupID {
+		group, err := s.store.GetGroup(r.Context(), req.GroupID)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to get group")
+			s.writeError(w, http.StatusInternalServerError, "Failed to get group")
+
+			return
+		}
+
+		if group == nil || group.Archived {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown group: %s", req.GroupID))
+
+			return
+		}
+
+		clone.GroupID = req.GroupID
+	}
+
+	for _, field := range []struct {
+		value string
+		dst   *string
+	}{
+		{req.Owner, &clone.Owner},
+		{req.Repo, &clone.Repo},
+		{req.Ref, &clone.Ref},
+	} {
+		if value := strings.TrimSpace(field.value); value != "" {
+			*field.dst = value
+		}
+	}
+
+	if err := s.store.CreateJobTemplate(r.Context(), &clone); err != nil {
+		s.log.WithError(err).Error("Failed to create job template")
+		s.writeError(w, http.StatusInternalServerError, "Failed to create job template")
+
+		return
+	}
+
+	s.auditTemplate(r, store.AuditActionTemplateCloned, clone.ID, fmt.Sprintf("Cloned from %s", source.ID))
+
+	s.writeJSON(w, http.StatusCreated, &clone)
+}
+
+// handleDeleteJobTemplate godoc
+//
+//	@Summary		Delete job template
+//	@Description	Deletes a template managed through the API, such as a clone. Templates from config, and templates with jobs, cannot be deleted (requires admin).
+//	@Tags			templates
+//	@Security		BearerAuth
+//	@Param			id	path	string	true	"Template ID"
+//	@Success		204
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/templates/{id} [delete]
+func (s *server) handleDeleteJobTemplate(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	template, err := s.store.GetJobTemplate(r.Context(), id)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get job template")
+		s.writeError(w, http.StatusInternalServerError, "Failed to get job template")
+
+		return
+	}
+
+	if template == nil {
+		s.writeError(w, http.StatusNotFound, "Job template not found")
+
+		return
+	}
+
+	if template.SourceType != store.TemplateSourceAPI {
+		s.writeError(w, http.StatusConflict, "Template is defined in config; remove it there")
+
+		return
+	}
+
+	hasJobs, err := s.store.HasAnyJobs(r.Context(), id)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to check jobs for template")
+		s.writeError(w, http.StatusInternalServerError, "Failed to check jobs for template")
+
+		return
+	}
+
+	if hasJobs {
+		s.writeError(w, http.StatusConflict, "Template has job history and cannot be deleted")
+
+		return
+	}
+
+	if err := s.store.DeleteJobTemplate(r.Context(), id); err != nil {
+		s.log.WithError(err).Error("Failed to delete job template")
+		s.writeError(w, http.StatusInternalServerError, "Failed to delete job template")
+
+		return
+	}
+
+	s.auditTemplate(r, store.AuditActionTemplateDeleted, id, "Deleted "+template.Name)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleBulkEditJobTemplates godoc
+//
+//	@Summary		Bulk edit job templates
+//	@Description	Changes the owner, repo or ref of the selected templates, e.g. after a repository was renamed or its default branch changed. Templates managed through the API are changed directly; templates from config keep the change as an override that config syncs apply until it is reset. Nothing is changed unless every template can be (requires admin).
+//	@Tags			templates
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		BulkEditTemplatesRequest	true	"Templates and changes"
+//	@Success		200		{object}	BulkEditTemplatesResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/templates/bulk-edit [post]
+func (s *server) handleBulkEditJobTemplates(w http.ResponseWriter, r *http.Request) {
+	var req BulkEditTemplatesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if len(req.TemplateIDs) == 0 || len(req.TemplateIDs) > maxBulkEditTemplates {
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("Between 1 and %d template_ids are required", maxBulkEditTemplates))
+
+		return
+	}
+
+	var changes []string
+
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"owner", req.Owner},
+		{"repo", req.Repo},
+		{"ref", req.Ref},
+	} {
+		if field.value == nil {
+			continue
+		}
+
+		*field.value = strings.TrimSpace(*field.value)
+		if *field.value == "" {
+			s.writeError(w, http.StatusBadRequest, field.name+" must not be empty")
+
+			return
+		}
+
+		changes = append(changes, fmt.Sprintf("%s=%s", field.name, *field.value))
+	}
+
+	if req.Reset {
+		if len(changes) > 0 {
+			s.writeError(w, http.StatusBadRequest, "reset cannot be combined with changes")
+
+			return
+		}
+
+		changes = append(changes, "overrides reset")
+	}
+
+	if len(changes) == 0 {
+		s.writeError(w, http.StatusBadRequest, "No changes given")
+
+		return
+	}
+
+	templates := make([]*store.JobTemplate, 0, len(req.TemplateIDs))
+	seen := make(map[string]bool, len(req.TemplateIDs))
+
+	for _, id := range req.TemplateIDs {
+		if seen[id] {
+			continue
+		}
+
+		seen[id] = true
+
+		template, err := s.store.GetJobTemplate(r.Context(), id)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to get job template")
+			s.writeError(w, http.StatusInternalServerError, "Failed to get job template")
+
+			return
+		}
+
+		if template == nil {
+			s.writeError(w, http.StatusNotFound, fmt.Sprintf("Job template not found: %s", id))
+
+			return
+		}
+
+		if !template.InConfig {
+			s.writeError(w, http.StatusConflict, fmt.Sprintf("Template %s is no longer in config", id))
+
+			return
+		}
+
+		templates = append(templates, template)
+	}
+
+	actor := "anonymous"
+	if user := auth.UserFromContext(r.Context()); user != nil {
+		actor = user.Username
+	}
+
+	now := time.Now()
+
+	for _, template := range templates {
+		switch {
+		case req.Reset:
+			if template.SourceType == store.TemplateSourceAPI || template.Overrides == nil {
+				continue
+			}
+
+			s.resetTemplateOverrides(template)
+		case template.SourceType == store.TemplateSourceAPI:
+			applyTemplateEdit(template, req)
+		default:
+			overrides := &store.TemplateOverrides{}
+			if template.Overrides != nil {
+				*overrides = *template.Overrides
+			}
+
+			applyOverridesEdit(overrides, req)
+			overrides.UpdatedBy = actor
+			overrides.UpdatedAt = now
+
+			applyTemplateOverrides(template, overrides)
+		}
+
+		if err := s.store.UpdateJobTemplate(r.Context(), template); err != nil {
+			s.log.WithError(err).WithField("template", template.ID).Error("Failed to update job template")
+			s.writeError(w, http.StatusInternalServerError, "Failed to update job template")
+
+			return
+		}
+
+		s.auditTemplate(r, store.AuditActionTemplateEdited, template.ID, strings.Join(changes, ", "))
+	}
+
+	s.writeJSON(w, http.StatusOK, BulkEditTemplatesResponse{Templates: templates})
+}
+
+// applyTemplateEdit changes the fields of a template managed through the API.
+func applyTemplateEdit(template *store.JobTemplate, req BulkEditTemplatesRequest) {
+	if req.Owner != nil {
+		template.Owner = *req.Owner
+	}
+
+	if req.Repo != nil {
+		template.Repo = *req.Repo
+	}
+
+	if req.Ref != nil {
+		template.Ref = *req.Ref
+	}
+}
+
+// applyOverridesEdit records the changes of a bulk edit as overrides.
+func applyOverridesEdit(overrides *store.TemplateOverrides, req BulkEditTemplatesRequest) {
+	if req.Owner != nil {
+		overrides.Owner = *req.Owner
+	}
+
+	if req.Repo != nil {
+		overrides.Repo = *req.Repo
+	}
+
+	if req.Ref != nil {
+		overrides.Ref = *req.Ref
+	}
+}
+
+// resetTemplateOverrides drops a template's overrides, restoring the owner,
+// repo and ref from the running config.
+func (s *server) resetTemplateOverrides(template *store.JobTemplate) {
+	template.Overrides = nil
+
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+
+	for _, group := range s.cfg.Groups.GitHub {
+		for _, tmplCfg := range group.WorkflowDispatchTemplates {
+			if tmplCfg.ID == template.ID {
+				template.Owner = tmplCfg.Owner
+				template.Repo = tmplCfg.Repo
+				template.Ref = tmplCfg.Ref
+
+				return
+			}
+		}
+	}
+}
+
+// auditTemplate records a change to a template in the audit log.
+func (s *server) auditTemplate(r *http.Request, action store.AuditAction, templateID, details string) {
+	actor := "anonymous"
+	if user := auth.UserFromContext(r.Context()); user != nil {
+		actor = user.Username
+	}
+
+	if err := s.store.CreateAuditEntry(r.Context(), &store.AuditEntry{
+		ID:         uuid.New().String(),
+		Action:     action,
+		EntityType: store.AuditEntityTemplate,
+		EntityID:   templateID,
+		Actor:      actor,
+		Details:    details,
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		s.log.WithError(err).WithField("template", templateID).Warn("Failed to create audit entry for template change")
+	}
+}
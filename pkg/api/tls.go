@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/sirupsen/logrus"
+)
+
+// tlsReloader holds the current server certificate and client CA pool and
+// reloads them when the files on disk change, so rotated certificates are
+// picked up without a restart.
+type tlsReloader struct {
+	log logrus.FieldLogger
+	cfg config.TLSConfig
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+// newTLSReloader loads the configured files. It fails if they cannot be loaded.
+func newTLSReloader(log logrus.FieldLogger, cfg config.TLSConfig) (*tlsReloader, error) {
+	r := &tlsReloader{
+		log: log.WithField("component", "tls"),
+		cfg: cfg,
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// files returns the paths watched for rotation.
+func (r *tlsReloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+
+	return files
+}
+
+// load reads the certificate, key and client CA bundle from disk.
+func (r *tlsReloader) load() error {
+	modTimes := make(map[string]time.Time, 3)
+
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", file, err)
+		}
+
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("loading certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("reading client CA: %w", err)
+		}
+
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA file %s", r.cfg.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCA = pool
+	r.modTimes = modTimes
+	r.mu.Unlock()
+
+	return nil
+}
+
+// changed returns true if any watched file was modified since the last load.
+func (r *tlsReloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			// Files are often replaced non-atomically; try again next time.
+			continue
+		}
+
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// watch reloads the files whenever they change until ctx is cancelled. A failed
+// reload keeps serving the previous certificate.
+func (r *tlsReloader) watch(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+
+			if err := r.load(); err != nil {
+				r.log.WithError(err).Error("Failed to reload TLS certificates, keeping previous ones")
+
+				continue
+			}
+
+			r.log.Info("Reloaded TLS certificates")
+		}
+	}
+}
+
+// tlsConfig returns a tls.Config that always uses the latest loaded files.
+func (r *tlsReloader) tlsConfig() *tls.Config {
+	minVersion := uint16(tls.VersionTLS12)
+	if r.cfg.MinVersion == "1.3" {
+		minVersion = tls.VersionTLS13
+	}
+
+	clientAuth := tls.NoClientCert
+
+	if r.cfg.ClientCAFile != "" {
+		clientAuth = tls.RequireAndVerifyClientCert
+		if r.cfg.ClientAuth == config.TLSClientAuthVerifyIfGiven {
+			clientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	// Each handshake gets a fresh config so a rotated client CA applies to new
+	// connections. NextProtos is set here since the per-connection config
+	// replaces the one net/http adds the protocols to.
+	nextProtos := []string{"h2", "http/1.1"}
+
+	return &tls.Config{
+		MinVersion: minVersion,
+		NextProtos: nextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			return &tls.Config{
+				MinVersion:   minVersion,
+				NextProtos:   nextProtos,
+				Certificates: []tls.Certificate{*r.cert},
+				ClientAuth:   clientAuth,
+				ClientCAs:    r.clientCA,
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/ethpandaops/dispatchoor/ui"
+)
+
+// uiHandler returns the handler serving the web UI under /, or nil if the UI
+// is disabled or was not embedded in this build.
+func (s *server) uiHandler() http.Handler {
+	if s.cfg.Server.UI.Disabled {
+		return nil
+	}
+
+	if external := s.cfg.Server.UI.ExternalURL; external != "" {
+		base := strings.TrimSuffix(external, "/")
+
+		return notForAPI(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, base+r.URL.RequestURI(), http.StatusFound)
+		}))
+	}
+
+	assets, ok := ui.Assets()
+	if !ok {
+		return nil
+	}
+
+	return notForAPI(spaHandler(assets))
+}
+
+// notForAPI answers unknown /api/ paths with a plain 404 instead of handing
+// them to the UI.
+func notForAPI(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// spaHandler serves static files from assets, falling back to index.html for
+// unknown paths so client-side routes survive a page reload.
+func spaHandler(assets fs.FS) http.Handler {
+	fileServer := http.FileServer(http.FS(assets))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The UI is served from the API origin, so it talks to the API directly.
+		if r.URL.Path == "/config.json" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"apiUrl":"/api/v1"}`))
+
+			return
+		}
+
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if name == "" {
+			name = "index.html"
+		}
+
+		if _, err := fs.Stat(assets, name); err != nil {
+			r.URL.Path = "/"
+		} else if strings.HasPrefix(name, "assets/") {
+			// Vite fingerprints everything under assets/.
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		}
+
+		fileServer.ServeHTTP(w, r)
+	})
+}
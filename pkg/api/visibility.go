@@ -0,0 +1,112 @@
+package api
+
+import (
+	"slices"
+
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+)
+
+// redactedValue replaces secret input values in WebSocket broadcasts.
+const redactedValue = "[redacted]"
+
+// canViewGroup reports whether a user may receive a group's live events.
+// Admins see every group; other users see groups without a viewers list and
+// groups that list them.
+func (s *server) canViewGroup(user *store.User, groupID string) bool {
+	if user == nil {
+		return false
+	}
+
+	if user.Role == store.RoleAdmin {
+		return true
+	}
+
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+
+	for _, group := range s.cfg.Groups.GitHub {
+		if group.ID == groupID {
+			return len(group.Viewers) == 0 || slices.Contains(group.Viewers, user.Username)
+		}
+	}
+
+	// Groups no longer in the config keep no viewers list to check against.
+	return true
+}
+
+// secretInputs returns the inputs of a template that must not be broadcast.
+func (s *server) secretInputs(templateID string) []string {
+	if templateID == "" {
+		return nil
+	}
+
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+
+	for _, group := range s.cfg.Groups.GitHub {
+		for _, tmpl := range group.WorkflowDispatchTemplates {
+			if tmpl.ID == templateID {
+				return tmpl.SecretInputs
+			}
+		}
+	}
+
+	return nil
+}
+
+// redactJob returns the job with its secret input values replaced, or the job
+// itself when it has none. The original job is never modified.
+func (s *server) redactJob(job *store.Job) *store.Job {
+	secrets := s.secretInputs(job.TemplateID)
+
+	var inputs input.Map
+
+	for _, key := range secrets {
+		if _, ok := job.Inputs[key]; !ok {
+			continue
+		}
+
+		if inputs == nil {
+			inputs = make(input.Map, len(job.Inputs))
+			for k, v := range job.Inputs {
+				inputs[k] = v
+			}
+		}
+
+		inputs[key] = input.String(redactedValue)
+	}
+
+	if inputs == nil {
+		return job
+	}
+
+	redacted := *job
+	redacted.Inputs = inputs
+
+	return &redacted
+}
+
+// redactMessage strips secret inputs from the jobs carried by a group message.
+func (s *server) redactMessage(msg *Message) *Message {
+	switch payload := msg.Payload.(type) {
+	case *store.Job:
+		out := *msg
+		out.Payload = s.redactJob(payload)
+
+		return &out
+
+	case []*store.Job:
+		jobs := make([]*store.Job, len(payload))
+		for i, job := range payload {
+			jobs[i] = s.redactJob(job)
+		}
+
+		out := *msg
+		out.Payload = jobs
+
+		return &out
+	}
+
+	return msg
+}
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/ethpandaops/dispatchoor/pkg/webhook"
+	"github.com/sirupsen/logrus"
+)
+
+// RunEventHandler applies workflow run and workflow job webhooks to the jobs
+// tracking the runs, so they move on without waiting for a tracking cycle.
+type RunEventHandler interface {
+	HandleWorkflowRun(ctx context.Context, run *github.WorkflowRun) error
+	HandleWorkflowJobStarted(ctx context.Context, runID, runnerID int64, runnerName string) error
+}
+
+// SetRunEventHandler sets the handler of workflow run webhooks.
+func (s *server) SetRunEventHandler(h RunEventHandler) {
+	s.runEvents = h
+}
+
+// SetDispatchTrigger sets the function called to run a dispatch cycle when a
+// webhook reports that a runner has been freed.
+func (s *server) SetDispatchTrigger(fn func()) {
+	s.dispatchTrigger = fn
+}
+
+// handleGitHubWebhook godoc
+//
+//	@Summary		Receive GitHub webhooks
+//	@Description	Accepts workflow_run, workflow_job, issue_comment, issues and push deliveries signed with github.webhook_secret. An in-progress or completed workflow run moves the job tracking it to running, completed, failed or cancelled without waiting for the tracking loop. A workflow job picked up by a runner marks the runner busy and the job running on it; a completed job marks its runner idle and immediately triggers a dispatch cycle if the runner matches any group. A queued or in-progress job of a re-run attempt reopens the failed or cancelled job that ran the workflow run. New comments are checked for chatops commands when chatops is enabled, and newly opened issues carrying chatops.issue_label are read as issue form job requests. A push to the ref of a git template source refreshes templates in the background. Other events are ignored.
+//	@Tags			webhooks
+//	@Accept			json
+//	@Param			X-GitHub-Event		header	string	true	"Webhook event type"
+//	@Param			X-Hub-Signature-256	header	string	true	"HMAC-SHA256 signature of the payload"
+//	@Success		204	"Delivery processed or ignored"
+//	@Failure		400	{object}	map[string]string	"Malformed payload"
+//	@Failure		401	{object}	map[string]string	"Invalid signature"
+//	@Failure		429	{object}	RateLimitErrorResponse	"Rate limit exceeded"
+//	@Router			/webhooks/github [post]
+func (s *server) handleGitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	event, err := webhook.Parse(r, s.cfg.GitHub.WebhookSecret)
+	if err != nil {
+		if errors.Is(err, webhook.ErrInvalidSignature) {
+			s.writeError(w, http.StatusUnauthorized, "Invalid signature")
+
+			return
+		}
+
+		s.writeError(w, http.StatusBadRequest, "Invalid payload")
+
+		return
+	}
+
+	switch event := event.(type) {
+	case *webhook.WorkflowJobEvent:
+		switch event.Action {
+		case "completed":
+			s.handleWorkflowJobCompleted(r, event)
+		case "queued":
+			s.handleWorkflowJobRerun(r, event)
+		case "in_progress":
+			s.handleWorkflowJobRerun(r, event)
+			s.handleWorkflowJobStarted(r, event)
+		}
+	case *webhook.WorkflowRunEvent:
+		if (event.Action == "in_progress" || event.Action == "completed") && s.runEvents != nil {
+			if err := s.runEvents.HandleWorkflowRun(r.Context(), event.Run); err != nil {
+				s.log.WithError(err).WithField("run_id", event.Run.ID).Warn("Failed to apply workflow run webhook")
+			}
+		}
+	case *webhook.IssueCommentEvent:
+		if event.Action == "created" && s.cfg.ChatOps.Enabled {
+			s.handleChatOpsComment(r.Context(), event)
+		}
+	case *webhook.IssuesEvent:
+		if event.Action == "opened" && s.cfg.ChatOps.Enabled && s.cfg.ChatOps.IssueLabel != "" {
+			s.handleIssueFormRequest(r.Context(), event)
+		}
+	case *webhook.PushEvent:
+		if s.pushUpdatesGitSource(event) {
+			s.log.WithFields(logrus.Fields{
+				"repo": event.Repo,
+				"ref":  event.Ref,
+			}).Info("Git template source pushed, refreshing templates")
+
+			s.requestGitRefresh()
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleWorkflowJobCompleted marks the runner that ran the job idle and triggers
+// a dispatch cycle if the runner can pick up work for any group.
+func (s *server) handleWorkflowJobCompleted(r *http.Request, event *webhook.WorkflowJobEvent) {
+	ctx := r.Context()
+	log := s.log.WithFields(logrus.Fields{
+		"workflow_job_id": event.Job.ID,
+		"run_id":          event.RunID,
+		"runner_name":     event.Job.RunnerName,
+	})
+
+	// Jobs cancelled before they were picked up never had a runner.
+	if event.Job.RunnerID == 0 {
+		return
+	}
+
+	labels := event.Job.Labels
+
+	runner, err := s.store.GetRunner(ctx, event.Job.RunnerID)
+	if err != nil {
+		log.WithError(err).Warn("Failed to get runner for webhook")
+	}
+
+	if runner != nil {
+		labels = runner.Labels
+
+		if runner.Busy {
+			runner.Busy = false
+			runner.UpdatedAt = time.Now()
+
+			if err := s.store.UpsertRunner(ctx, runner); err != nil {
+				log.WithError(err).Warn("Failed to mark runner idle")
+			} else {
+				s.BroadcastRunnerChange(runner)
+			}
+		}
+	}
+
+	groups, err := s.store.ListGroups(ctx)
+	if err != nil {
+		log.WithError(err).Warn("Failed to list groups for webhook")
+
+		return
+	}
+
+	var matched []string
+
+	for _, group := range groups {
+		if group.Enabled && !group.Paused && !group.Archived && runnerMatchesLabels(labels, group.RunnerLabels) {
+			matched = append(matched, group.ID)
+		}
+	}
+
+	if len(matched) == 0 || s.dispatchTrigger == nil {
+		return
+	}
+
+	log.WithField("groups", matched).Debug("Runner freed, triggering dispatch")
+
+	s.dispatchTrigger()
+}
+
+// handleWorkflowJobStarted marks the runner that picked up the job busy, and
+// the job tracking its run as running on it.
+func (s *server) handleWorkflowJobStarted(r *http.Request, event *webhook.WorkflowJobEvent) {
+	if event.Job.RunnerID == 0 {
+		return
+	}
+
+	ctx := r.Context()
+	log := s.log.WithFields(logrus.Fields{
+		"workflow_job_id": event.Job.ID,
+		"run_id":          event.RunID,
+		"runner_name":     event.Job.RunnerName,
+	})
+
+	if s.runEvents != nil {
+		if err := s.runEvents.HandleWorkflowJobStarted(ctx, event.RunID, event.Job.RunnerID, event.Job.RunnerName); err != nil {
+			log.WithError(err).Warn("Failed to mark job running from webhook")
+		}
+	}
+
+	runner, err := s.store.GetRunner(ctx, event.Job.RunnerID)
+	if err != nil {
+		log.WithError(err).Warn("Failed to get runner for webhook")
+
+		return
+	}
+
+	if runner == nil || runner.Busy {
+		return
+	}
+
+	runner.Busy = true
+	runner.UpdatedAt = time.Now()
+
+	if err := s.store.UpsertRunner(ctx, runner); err != nil {
+		log.WithError(err).Warn("Failed to mark runner busy")
+
+		return
+	}
+
+	s.BroadcastRunnerChange(runner)
+}
+
+// handleWorkflowJobRerun reopens a failed or cancelled job when a workflow job
+// of a newer attempt of its run starts, so runs re-run from GitHub are tracked
+// to their new outcome as soon as the re-run begins.
+func (s *server) handleWorkflowJobRerun(r *http.Request, event *webhook.WorkflowJobEvent) {
+	if event.RunAttempt <= 1 {
+		return
+	}
+
+	ctx := r.Context()
+	log := s.log.WithFields(logrus.Fields{
+		"run_id":  event.RunID,
+		"attempt": event.RunAttempt,
+	})
+
+	job, err := s.store.GetJobByRunID(ctx, event.RunID)
+	if err != nil {
+		log.WithError(err).Warn("Failed to get job for webhook")
+
+		return
+	}
+
+	// Jobs tracked before attempts were recorded saw the first attempt.
+	if job == nil || (job.Status != store.JobStatusFailed && job.Status != store.JobStatusCancelled) ||
+		event.RunAttempt <= max(job.RunAttempt, 1) {
+		return
+	}
+
+	if err := s.queue.MarkRerun(ctx, job.ID, event.RunAttempt); err != nil {
+		log.WithError(err).Warn("Failed to reopen re-run job")
+
+		return
+	}
+
+	// The logs and artifacts URLs cover the run's latest attempt, so they stay.
+	if err := s.store.UpdateJobRunAttempt(ctx, job.ID, event.RunAttempt, job.LogsURL, job.ArtifactsURL); err != nil {
+		log.WithError(err).Warn("Failed to record run attempt")
+	}
+
+	log.WithField("job_id", job.ID).Info("Reopened job for re-run workflow run")
+}
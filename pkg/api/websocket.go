@@ -1,10 +1,13 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"math/rand/v2"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethpandaops/dispatchoor/pkg/auth"
@@ -25,6 +28,19 @@ const (
 
 	// Maximum message size allowed from peer.
 	maxMessageSize = 512
+
+	// Time allowed for the peer to answer our close frame on shutdown.
+	closeGracePeriod = 2 * time.Second
+
+	// Range of the reconnect delay suggested to clients on shutdown. The delay
+	// is randomized so clients do not all reconnect at once.
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 5 * time.Second
+
+	// Maximum number of jobs whose last sent state is kept per client for
+	// delta updates. Once exceeded the cache starts over and jobs are sent in
+	// full again.
+	maxDeltaJobs = 1024
 )
 
 // createUpgrader creates a WebSocket upgrader with origin validation.
@@ -62,14 +78,20 @@ type MessageType string
 
 const (
 	// Server -> Client messages.
-	MessageTypeRunnerStatus MessageType = "runner_status"
-	MessageTypeQueueUpdate  MessageType = "queue_update"
-	MessageTypeJobState     MessageType = "job_state"
-	MessageTypeDispatch     MessageType = "dispatch"
-	MessageTypeSystemStatus MessageType = "system_status"
-	MessageTypeError        MessageType = "error"
-	MessageTypeSubscribed   MessageType = "subscribed"
-	MessageTypeUnsubscribed MessageType = "unsubscribed"
+	MessageTypeRunnerStatus   MessageType = "runner_status"
+	MessageTypeQueueUpdate    MessageType = "queue_update"
+	MessageTypeJobState       MessageType = "job_state"
+	MessageTypeJobDelta       MessageType = "job_delta"
+	MessageTypeDispatch       MessageType = "dispatch"
+	MessageTypeGroupState     MessageType = "group_state"
+	MessageTypeGroupStarved   MessageType = "group_starved"
+	MessageTypeJobStalled     MessageType = "job_stalled"
+	MessageTypeCampaignUpdate MessageType = "campaign_update"
+	MessageTypeSystemStatus   MessageType = "system_status"
+	MessageTypeError          MessageType = "error"
+	MessageTypeSubscribed     MessageType = "subscribed"
+	MessageTypeUnsubscribed   MessageType = "unsubscribed"
+	MessageTypeReconnect      MessageType = "reconnect"
 
 	// Client -> Server messages.
 	MessageTypeSubscribe   MessageType = "subscribe"
@@ -77,11 +99,25 @@ const (
 	MessageTypePing        MessageType = "ping"
 )
 
+// MessageFormat is how job state changes are sent to a group subscriber,
+// chosen when subscribing.
+type MessageFormat string
+
+const (
+	// MessageFormatFull sends the whole job on every change (the default).
+	MessageFormatFull MessageFormat = "full"
+	// MessageFormatDelta sends a job in full once, then only the fields that
+	// changed since as job_delta messages.
+	MessageFormatDelta MessageFormat = "delta"
+)
+
 // Message represents a WebSocket message.
 type Message struct {
 	Type    MessageType `json:"type"`
 	GroupID string      `json:"group_id,omitempty"`
-	Payload any         `json:"payload,omitempty"`
+	// Format is requested by subscribe messages and echoed in the reply.
+	Format  MessageFormat `json:"format,omitempty"`
+	Payload any           `json:"payload,omitempty"`
 }
 
 // Hub maintains the set of active clients and broadcasts messages to them.
@@ -106,9 +142,56 @@ type Hub struct {
 	// Broadcast messages to specific groups.
 	groupBroadcast chan *groupMessage
 
+	// draining is set on shutdown; new connections are rejected.
+	draining atomic.Bool
+
+	// canView reports whether a user may receive a group's events (nil allows
+	// everyone). It is checked on subscribe and again on every delivery, so
+	// visibility changes apply to existing subscriptions.
+	canView func(user *store.User, groupID string) bool
+
+	// redact returns a copy of a group message safe to send to subscribers,
+	// e.g. without secret inputs (nil sends messages as-is).
+	redact func(msg *Message) *Message
+
+	// compressionLevel enables permessage-deflate when non-zero, for messages
+	// of at least compressionMinSize bytes.
+	compressionLevel   int
+	compressionMinSize int
+
 	mu sync.RWMutex
 }
 
+// GroupStarvedPayload is sent when a group's oldest pending job has waited
+// longer than the starvation threshold.
+type GroupStarvedPayload struct {
+	GroupID    string `json:"group_id"`
+	JobID      string `json:"job_id"`
+	AgeSeconds int64  `json:"age_seconds"`
+}
+
+// JobStalledPayload is sent when a running job has had no heartbeat or run
+// update for longer than its template's stall timeout.
+type JobStalledPayload struct {
+	GroupID     string `json:"group_id"`
+	JobID       string `json:"job_id"`
+	IdleSeconds int64  `json:"idle_seconds"`
+}
+
+// JobDeltaPayload is sent to delta subscribers instead of a job's full state.
+// Changed holds the job's JSON fields that differ from the job_state or
+// job_delta last sent for it; fields that were dropped are null.
+type JobDeltaPayload struct {
+	ID      string                     `json:"id"`
+	Changed map[string]json.RawMessage `json:"changed"`
+}
+
+// ReconnectPayload is sent to clients before the server closes their connection.
+type ReconnectPayload struct {
+	Reason       string `json:"reason"`
+	RetryAfterMs int64  `json:"retry_after_ms"`
+}
+
 type groupMessage struct {
 	groupID string
 	msg     *Message
@@ -127,6 +210,26 @@ func NewHub(log logrus.FieldLogger) *Hub {
 	}
 }
 
+// SetAccessControl sets the group visibility check and the redaction applied to
+// group messages. It must be called before the hub starts.
+func (h *Hub) SetAccessControl(canView func(user *store.User, groupID string) bool, redact func(msg *Message) *Message) {
+	h.canView = canView
+	h.redact = redact
+}
+
+// SetCompression enables permessage-deflate for clients that support it, at
+// the given flate level for messages of at least minSize bytes. It must be
+// called before the hub starts.
+func (h *Hub) SetCompression(level, minSize int) {
+	h.compressionLevel = level
+	h.compressionMinSize = minSize
+}
+
+// allowed reports whether the user may receive the group's events.
+func (h *Hub) allowed(user *store.User, groupID string) bool {
+	return h.canView == nil || h.canView(user, groupID)
+}
+
 // Run starts the hub's main loop.
 func (h *Hub) Run(ctx context.Context) {
 	h.log.Info("Starting WebSocket hub")
@@ -185,6 +288,10 @@ func (h *Hub) Run(ctx context.Context) {
 
 			if clients, ok := h.subscriptions[gm.groupID]; ok {
 				for client := range clients {
+					if !h.allowed(client.user, gm.groupID) {
+						continue
+					}
+
 					select {
 					case client.send <- gm.msg:
 					default:
@@ -248,6 +355,10 @@ func (h *Hub) Broadcast(msg *Message) {
 func (h *Hub) BroadcastToGroup(groupID string, msg *Message) {
 	msg.GroupID = groupID
 
+	if h.redact != nil {
+		msg = h.redact(msg)
+	}
+
 	select {
 	case h.groupBroadcast <- &groupMessage{groupID: groupID, msg: msg}:
 	default:
@@ -287,6 +398,94 @@ func (h *Hub) BroadcastDispatch(job *store.Job) {
 	})
 }
 
+// BroadcastGroupState broadcasts a group state change (e.g. an automatic pause).
+func (h *Hub) BroadcastGroupState(group *store.Group) {
+	h.BroadcastToGroup(group.ID, &Message{
+		Type:    MessageTypeGroupState,
+		Payload: group,
+	})
+}
+
+// BroadcastGroupStarved broadcasts that a group's queue is starved.
+func (h *Hub) BroadcastGroupStarved(group *store.Group, job *store.Job, age time.Duration) {
+	h.BroadcastToGroup(group.ID, &Message{
+		Type: MessageTypeGroupStarved,
+		Payload: &GroupStarvedPayload{
+			GroupID:    group.ID,
+			JobID:      job.ID,
+			AgeSeconds: int64(age.Seconds()),
+		},
+	})
+}
+
+// BroadcastJobStalled broadcasts that a running job has stalled.
+func (h *Hub) BroadcastJobStalled(job *store.Job, idle time.Duration) {
+	h.BroadcastToGroup(job.GroupID, &Message{
+		Type: MessageTypeJobStalled,
+		Payload: &JobStalledPayload{
+			GroupID:     job.GroupID,
+			JobID:       job.ID,
+			IdleSeconds: int64(idle.Seconds()),
+		},
+	})
+}
+
+// BroadcastCampaignUpdate broadcasts a campaign's progress to all clients.
+// Campaigns span groups, so the update carries no job details.
+func (h *Hub) BroadcastCampaignUpdate(campaign *CampaignResponse) {
+	h.Broadcast(&Message{
+		Type:    MessageTypeCampaignUpdate,
+		Payload: campaign,
+	})
+}
+
+// Draining returns true once Shutdown has been called.
+func (h *Hub) Draining() bool {
+	return h.draining.Load()
+}
+
+// Shutdown stops accepting connections, asks every client to reconnect later
+// and closes their connections with a close frame. It waits until all clients
+// are gone or ctx expires.
+func (h *Hub) Shutdown(ctx context.Context) {
+	h.draining.Store(true)
+
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+
+	for client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	if len(clients) == 0 {
+		return
+	}
+
+	h.log.WithField("clients", len(clients)).Info("Closing WebSocket connections")
+
+	for _, client := range clients {
+		client.requestClose()
+	}
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for h.ClientCount() > 0 {
+		select {
+		case <-ctx.Done():
+			h.log.WithField("clients", h.ClientCount()).Warn("Timed out closing WebSocket connections")
+
+			for _, client := range clients {
+				_ = client.conn.Close()
+			}
+
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // ClientCount returns the number of connected clients.
 func (h *Hub) ClientCount() int {
 	h.mu.RLock()
@@ -302,22 +501,145 @@ type Client struct {
 	conn *websocket.Conn
 	user *store.User
 	send chan *Message
+
+	// closing is closed to make WritePump send a close frame.
+	closing   chan struct{}
+	closeOnce sync.Once
+	// done is closed when ReadPump exits.
+	done chan struct{}
+
+	// mu guards deltaGroups and sentJobs, which ReadPump changes on
+	// subscription and WritePump uses on delivery.
+	mu sync.Mutex
+	// deltaGroups are the groups subscribed to with the delta format.
+	deltaGroups map[string]bool
+	// sentJobs holds the fields of the job states last sent to the client
+	// for its delta groups, keyed by job ID.
+	sentJobs map[string]*sentJob
+}
+
+// sentJob is the state of a job last sent to a delta subscriber.
+type sentJob struct {
+	groupID string
+	fields  map[string]json.RawMessage
 }
 
 // NewClient creates a new WebSocket client.
 func NewClient(hub *Hub, conn *websocket.Conn, user *store.User, id string) *Client {
 	return &Client{
-		id:   id,
-		hub:  hub,
-		conn: conn,
-		user: user,
-		send: make(chan *Message, 256),
+		id:      id,
+		hub:     hub,
+		conn:    conn,
+		user:    user,
+		send:    make(chan *Message, 256),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+
+		deltaGroups: make(map[string]bool),
+		sentJobs:    make(map[string]*sentJob),
+	}
+}
+
+// setFormat records the format a group was subscribed to with. Jobs of the
+// group already sent are forgotten, so a new subscription starts with full
+// states.
+func (c *Client) setFormat(groupID string, format MessageFormat) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if format == MessageFormatDelta {
+		c.deltaGroups[groupID] = true
+	} else {
+		delete(c.deltaGroups, groupID)
+	}
+
+	for id, sent := range c.sentJobs {
+		if sent.groupID == groupID {
+			delete(c.sentJobs, id)
+		}
 	}
 }
 
+// slim converts a job_state message of a delta group into a job_delta with
+// the fields changed since the job was last sent. A job the client has not
+// been sent yet goes out in full. It returns nil when nothing changed.
+func (c *Client) slim(msg *Message) *Message {
+	job, ok := msg.Payload.(*store.Job)
+	if msg.Type != MessageTypeJobState || !ok {
+		return msg
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.deltaGroups[msg.GroupID] {
+		return msg
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return msg
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return msg
+	}
+
+	prev, seen := c.sentJobs[job.ID]
+
+	switch job.Status {
+	case store.JobStatusCompleted, store.JobStatusFailed, store.JobStatusCancelled:
+		// Finished jobs do not change again.
+		delete(c.sentJobs, job.ID)
+	default:
+		if !seen && len(c.sentJobs) >= maxDeltaJobs {
+			clear(c.sentJobs)
+		}
+
+		c.sentJobs[job.ID] = &sentJob{groupID: msg.GroupID, fields: fields}
+	}
+
+	if !seen || prev.groupID != msg.GroupID {
+		return msg
+	}
+
+	changed := make(map[string]json.RawMessage)
+
+	for key, value := range fields {
+		if !bytes.Equal(prev.fields[key], value) {
+			changed[key] = value
+		}
+	}
+
+	for key := range prev.fields {
+		if _, ok := fields[key]; !ok {
+			changed[key] = json.RawMessage("null")
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	return &Message{
+		Type:    MessageTypeJobDelta,
+		GroupID: msg.GroupID,
+		Payload: &JobDeltaPayload{ID: job.ID, Changed: changed},
+	}
+}
+
+// requestClose asks WritePump to close the connection gracefully.
+func (c *Client) requestClose() {
+	c.closeOnce.Do(func() {
+		close(c.closing)
+	})
+}
+
 // ReadPump pumps messages from the websocket connection to the hub.
 func (c *Client) ReadPump() {
 	defer func() {
+		close(c.done)
 		c.hub.unregister <- c
 		c.conn.Close()
 	}()
@@ -335,7 +657,8 @@ func (c *Client) ReadPump() {
 	for {
 		_, message, err := c.conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure,
+				websocket.CloseServiceRestart) {
 				c.hub.log.WithError(err).Warn("WebSocket read error")
 			}
 
@@ -376,6 +699,11 @@ func (c *Client) WritePump() {
 				return
 			}
 
+			msg = c.slim(msg)
+			if msg == nil {
+				continue
+			}
+
 			data, err := json.Marshal(msg)
 			if err != nil {
 				c.hub.log.WithError(err).Warn("Failed to marshal WebSocket message")
@@ -383,6 +711,8 @@ func (c *Client) WritePump() {
 				continue
 			}
 
+			c.conn.EnableWriteCompression(len(data) >= c.hub.compressionMinSize)
+
 			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
 				return
 			}
@@ -395,25 +725,86 @@ func (c *Client) WritePump() {
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
+
+		case <-c.closing:
+			c.writeClose()
+
+			return
 		}
 	}
 }
 
+// writeClose sends a reconnect hint and a close frame, then waits briefly for
+// the peer to answer the close before the connection is torn down.
+func (c *Client) writeClose() {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		return
+	}
+
+	delay := minReconnectDelay + rand.N(maxReconnectDelay-minReconnectDelay)
+
+	data, err := json.Marshal(&Message{
+		Type:    MessageTypeReconnect,
+		Payload: &ReconnectPayload{Reason: "shutdown", RetryAfterMs: delay.Milliseconds()},
+	})
+	if err == nil {
+		_ = c.conn.WriteMessage(websocket.TextMessage, data)
+	}
+
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseServiceRestart, "server shutting down")
+	if err := c.conn.WriteMessage(websocket.CloseMessage, closeMsg); err != nil {
+		return
+	}
+
+	select {
+	case <-c.done:
+	case <-time.After(closeGracePeriod):
+	}
+}
+
 // handleMessage handles incoming messages from the client.
 func (c *Client) handleMessage(msg *Message) {
 	switch msg.Type {
 	case MessageTypeSubscribe:
 		if msg.GroupID != "" {
+			if !c.hub.allowed(c.user, msg.GroupID) {
+				c.send <- &Message{
+					Type:    MessageTypeError,
+					GroupID: msg.GroupID,
+					Payload: "Not allowed to view group " + msg.GroupID,
+				}
+
+				return
+			}
+
+			format := msg.Format
+			if format == "" {
+				format = MessageFormatFull
+			}
+
+			if format != MessageFormatFull && format != MessageFormatDelta {
+				c.send <- &Message{
+					Type:    MessageTypeError,
+					GroupID: msg.GroupID,
+					Payload: "Unknown format " + string(format),
+				}
+
+				return
+			}
+
+			c.setFormat(msg.GroupID, format)
 			c.hub.Subscribe(c, msg.GroupID)
 			c.send <- &Message{
 				Type:    MessageTypeSubscribed,
 				GroupID: msg.GroupID,
+				Format:  format,
 			}
 		}
 
 	case MessageTypeUnsubscribe:
 		if msg.GroupID != "" {
 			c.hub.Unsubscribe(c, msg.GroupID)
+			c.setFormat(msg.GroupID, MessageFormatFull)
 			c.send <- &Message{
 				Type:    MessageTypeUnsubscribed,
 				GroupID: msg.GroupID,
@@ -459,8 +850,16 @@ func ServeWs(hub *Hub, authSvc auth.Service, allowedOrigins []string, w http.Res
 		return
 	}
 
+	if hub.Draining() {
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+
+		return
+	}
+
 	// Create upgrader with origin validation.
 	upgrader := createUpgrader(allowedOrigins)
+	upgrader.EnableCompression = hub.compressionLevel != 0
 
 	// Upgrade to WebSocket.
 	conn, err := upgrader.Upgrade(w, r, nil)
@@ -470,6 +869,13 @@ func ServeWs(hub *Hub, authSvc auth.Service, allowedOrigins []string, w http.Res
 		return
 	}
 
+	if hub.compressionLevel != 0 {
+		// Only applies once the client has agreed to permessage-deflate.
+		if err := conn.SetCompressionLevel(hub.compressionLevel); err != nil {
+			hub.log.WithError(err).Warn("Failed to set WebSocket compression level")
+		}
+	}
+
 	// Generate client ID.
 	clientID := r.Header.Get("X-Request-ID")
 	if clientID == "" {
@@ -6,7 +6,9 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -28,6 +30,14 @@ type Service interface {
 	ValidateSession(ctx context.Context, token string) (*store.User, error)
 	Logout(ctx context.Context, token string) error
 
+	// JWT sessions: login tokens become refresh tokens for signed access tokens.
+	IssueAccessToken(ctx context.Context, refreshToken string) (*store.User, string, time.Time, error)
+
+	// Sessions.
+	ListSessions(ctx context.Context, userID string) ([]*store.Session, error)
+	CurrentSession(ctx context.Context, token string) (*store.Session, error)
+	RevokeSession(ctx context.Context, user *store.User, sessionID string) error
+
 	// Authorization.
 	HasRole(user *store.User, role store.Role) bool
 	IsAdmin(user *store.User) bool
@@ -44,6 +54,14 @@ type Service interface {
 	ExchangeAuthCode(ctx context.Context, code string) (*store.User, string, error)
 }
 
+// ErrSessionNotFound is returned by RevokeSession when the session does not
+// exist or belongs to another user and the caller is not an admin.
+var ErrSessionNotFound = errors.New("session not found")
+
+// sessionTouchInterval limits how often a session's last-used time is written
+// while its client stays the same.
+const sessionTouchInterval = time.Minute
+
 // service implements Service.
 type service struct {
 	log        logrus.FieldLogger
@@ -112,17 +130,18 @@ func (s *service) syncBasicAuthUsers(ctx context.Context) error {
 
 		if existing == nil {
 			// Create new user.
-			user := &store.User{
+			existing = &store.User{
 				ID:           uuid.New().String(),
 				Username:     userCfg.Username,
 				PasswordHash: string(hash),
 				Role:         role,
 				AuthProvider: store.AuthProviderBasic,
+				GitHubID:     userCfg.GitHubID,
 				CreatedAt:    now,
 				UpdatedAt:    now,
 			}
 
-			if err := s.store.CreateUser(ctx, user); err != nil {
+			if err := s.store.CreateUser(ctx, existing); err != nil {
 				return fmt.Errorf("creating user %s: %w", userCfg.Username, err)
 			}
 
@@ -131,6 +150,7 @@ func (s *service) syncBasicAuthUsers(ctx context.Context) error {
 			// Update existing user.
 			existing.PasswordHash = string(hash)
 			existing.Role = role
+			existing.GitHubID = userCfg.GitHubID
 			existing.UpdatedAt = now
 
 			if err := s.store.UpdateUser(ctx, existing); err != nil {
@@ -139,6 +159,43 @@ func (s *service) syncBasicAuthUsers(ctx context.Context) error {
 
 			s.log.WithField("username", userCfg.Username).Debug("Updated basic auth user")
 		}
+
+		if userCfg.GitHubID != "" {
+			if err := s.mergeLinkedGitHubUser(ctx, existing); err != nil {
+				return fmt.Errorf("linking user %s: %w", userCfg.Username, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// mergeLinkedGitHubUser removes a GitHub user created for the linked GitHub ID
+// before the link existed, along with its sessions, so the ID resolves to the
+// basic user only.
+func (s *service) mergeLinkedGitHubUser(ctx context.Context, linked *store.User) error {
+	users, err := s.store.ListUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("listing users: %w", err)
+	}
+
+	for _, duplicate := range users {
+		if duplicate.GitHubID != linked.GitHubID || duplicate.AuthProvider != store.AuthProviderGitHub {
+			continue
+		}
+
+		if err := s.store.DeleteUserSessions(ctx, duplicate.ID); err != nil {
+			return fmt.Errorf("deleting sessions of %s: %w", duplicate.Username, err)
+		}
+
+		if err := s.store.DeleteUser(ctx, duplicate.ID); err != nil {
+			return fmt.Errorf("deleting user %s: %w", duplicate.Username, err)
+		}
+
+		s.log.WithFields(logrus.Fields{
+			"username":        linked.Username,
+			"github_username": duplicate.Username,
+		}).Info("Merged GitHub user into linked basic auth user")
 	}
 
 	return nil
@@ -197,6 +254,27 @@ func (s *service) AuthenticateGitHub(ctx context.Context, code string) (*store.U
 		return nil, "", fmt.Errorf("getting github user: %w", err)
 	}
 
+	// A basic user linked to this GitHub ID keeps its configured username and
+	// role, so both login methods share one account.
+	linked, err := s.store.GetUserByGitHubID(ctx, githubUser.ID)
+	if err != nil {
+		return nil, "", fmt.Errorf("getting user by github id: %w", err)
+	}
+
+	if linked != nil && linked.AuthProvider == store.AuthProviderBasic {
+		token, err := s.createSession(ctx, linked)
+		if err != nil {
+			return nil, "", fmt.Errorf("creating session: %w", err)
+		}
+
+		s.log.WithFields(logrus.Fields{
+			"username":        linked.Username,
+			"github_username": githubUser.Login,
+		}).Info("User authenticated via GitHub as linked basic auth user")
+
+		return linked, token, nil
+	}
+
 	// Determine role based on user or org membership.
 	// Role mappings also control access - if not in any mapping, login is rejected.
 	var role store.Role
@@ -215,7 +293,17 @@ func (s *service) AuthenticateGitHub(ctx context.Context, code string) (*store.U
 		}
 	}
 
-	// If no user mapping found, check org-based mapping.
+	// Then team-based mapping.
+	if !authorized && len(s.cfg.Auth.GitHub.TeamRoleMapping) > 0 {
+		teams, err := s.getGitHubUserTeams(ctx, accessToken)
+		if err != nil {
+			return nil, "", fmt.Errorf("getting github teams: %w", err)
+		}
+
+		role, authorized = teamRole(s.cfg.Auth.GitHub.TeamRoleMapping, teams)
+	}
+
+	// If no user or team mapping found, check org-based mapping.
 	if !authorized && len(s.cfg.Auth.GitHub.OrgRoleMapping) > 0 {
 		orgs, err := s.getGitHubUserOrgs(ctx, accessToken)
 		if err != nil {
@@ -238,10 +326,7 @@ func (s *service) AuthenticateGitHub(ctx context.Context, code string) (*store.U
 	}
 
 	// Get or create user.
-	user, err := s.store.GetUserByGitHubID(ctx, githubUser.ID)
-	if err != nil {
-		return nil, "", fmt.Errorf("getting user by github id: %w", err)
-	}
+	user := linked
 
 	now := time.Now()
 
@@ -284,8 +369,42 @@ func (s *service) AuthenticateGitHub(ctx context.Context, code string) (*store.U
 	return user, token, nil
 }
 
+// teamRole returns the role mapped to any of the user's teams, given as
+// "org/team-slug" and matched case-insensitively. When several teams match, the
+// most privileged role wins, so the result does not depend on team order.
+func teamRole(mapping map[string]string, teams []string) (store.Role, bool) {
+	var (
+		role  store.Role
+		found bool
+	)
+
+	for team, mappedRole := range mapping {
+		if !slices.ContainsFunc(teams, func(t string) bool { return strings.EqualFold(t, team) }) {
+			continue
+		}
+
+		if !found || store.Role(mappedRole) == store.RoleAdmin {
+			role = store.Role(mappedRole)
+			found = true
+		}
+	}
+
+	return role, found
+}
+
 // ValidateSession validates a session token and returns the associated user.
+// With JWT sessions only access tokens are accepted, and they are validated
+// without reading the store.
 func (s *service) ValidateSession(ctx context.Context, token string) (*store.User, error) {
+	if s.cfg.Auth.JWT.Enabled {
+		claims, err := s.parseAccessToken(token, false)
+		if err != nil {
+			return nil, err
+		}
+
+		return claims.user(), nil
+	}
+
 	tokenHash := hashToken(token)
 
 	session, err := s.store.GetSessionByToken(ctx, tokenHash)
@@ -313,16 +432,37 @@ func (s *service) ValidateSession(ctx context.Context, token string) (*store.Use
 		return nil, fmt.Errorf("user not found")
 	}
 
+	s.touchSession(ctx, session)
+
 	return user, nil
 }
 
-// Logout invalidates a session.
-func (s *service) Logout(ctx context.Context, token string) error {
-	tokenHash := hashToken(token)
+// touchSession records the session's last use and client, skipping the write
+// when the client is unchanged and the session was used recently.
+func (s *service) touchSession(ctx context.Context, session *store.Session) {
+	info, ok := clientInfoFromContext(ctx)
+	if !ok {
+		info = ClientInfo{IPAddress: session.IPAddress, UserAgent: session.UserAgent}
+	}
 
-	session, err := s.store.GetSessionByToken(ctx, tokenHash)
+	now := time.Now()
+
+	if session.LastUsedAt != nil && now.Sub(*session.LastUsedAt) < sessionTouchInterval &&
+		info.IPAddress == session.IPAddress && info.UserAgent == session.UserAgent {
+		return
+	}
+
+	if err := s.store.TouchSession(ctx, session.ID, now, info.IPAddress, info.UserAgent); err != nil {
+		s.log.WithError(err).Warn("Failed to record session use")
+	}
+}
+
+// Logout invalidates a session, given its token or, with JWT sessions, an
+// access token for it.
+func (s *service) Logout(ctx context.Context, token string) error {
+	session, err := s.CurrentSession(ctx, token)
 	if err != nil {
-		return fmt.Errorf("getting session: %w", err)
+		return err
 	}
 
 	if session == nil {
@@ -336,6 +476,81 @@ func (s *service) Logout(ctx context.Context, token string) error {
 	return nil
 }
 
+// ListSessions returns unexpired sessions, newest first, for one user or for
+// all users when userID is empty.
+func (s *service) ListSessions(ctx context.Context, userID string) ([]*store.Session, error) {
+	var (
+		sessions []*store.Session
+		err      error
+	)
+
+	if userID == "" {
+		sessions, err = s.store.ListSessions(ctx)
+	} else {
+		sessions, err = s.store.ListUserSessions(ctx, userID)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("listing sessions: %w", err)
+	}
+
+	now := time.Now()
+
+	active := make([]*store.Session, 0, len(sessions))
+
+	for i := len(sessions) - 1; i >= 0; i-- {
+		if now.Before(sessions[i].ExpiresAt) {
+			active = append(active, sessions[i])
+		}
+	}
+
+	return active, nil
+}
+
+// CurrentSession returns the session for a token, or nil if there is none.
+// With JWT sessions, an access token resolves to the session it was issued
+// for, even once expired.
+func (s *service) CurrentSession(ctx context.Context, token string) (*store.Session, error) {
+	if s.cfg.Auth.JWT.Enabled && isJWT(token) {
+		claims, err := s.parseAccessToken(token, true)
+		if err != nil {
+			return nil, nil
+		}
+
+		session, err := s.store.GetSession(ctx, claims.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("getting session: %w", err)
+		}
+
+		return session, nil
+	}
+
+	session, err := s.store.GetSessionByToken(ctx, hashToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("getting session: %w", err)
+	}
+
+	return session, nil
+}
+
+// RevokeSession deletes one of the user's sessions, or any session for admins.
+func (s *service) RevokeSession(ctx context.Context, user *store.User, sessionID string) error {
+	session, err := s.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("getting session: %w", err)
+	}
+
+	if session == nil || (session.UserID != user.ID && !s.IsAdmin(user)) {
+		return ErrSessionNotFound
+	}
+
+	if err := s.store.DeleteSession(ctx, session.ID); err != nil {
+		return fmt.Errorf("deleting session: %w", err)
+	}
+
+	return nil
+}
+
 // HasRole checks if a user has a specific role.
 func (s *service) HasRole(user *store.User, role store.Role) bool {
 	if user == nil {
@@ -372,13 +587,17 @@ func (s *service) createSession(ctx context.Context, user *store.User) (string,
 	}
 
 	now := time.Now()
+	info, _ := clientInfoFromContext(ctx)
 
 	session := &store.Session{
-		ID:        uuid.New().String(),
-		UserID:    user.ID,
-		TokenHash: hashToken(token),
-		ExpiresAt: now.Add(s.sessionTTL),
-		CreatedAt: now,
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		TokenHash:  hashToken(token),
+		ExpiresAt:  now.Add(s.sessionTTL),
+		CreatedAt:  now,
+		IPAddress:  info.IPAddress,
+		UserAgent:  info.UserAgent,
+		LastUsedAt: &now,
 	}
 
 	if err := s.store.CreateSession(ctx, session); err != nil {
@@ -553,3 +772,11 @@ func (s *service) ExchangeAuthCode(ctx context.Context, code string) (*store.Use
 
 	return user, token, nil
 }
+
+// CSRFToken derives the CSRF token for a session token. It is bound to the
+// session, so it needs no storage and is useless with any other session.
+func CSRFToken(sessionToken string) string {
+	hash := sha256.Sum256([]byte("csrf:" + sessionToken))
+
+	return hex.EncodeToString(hash[:])
+}
@@ -13,9 +13,11 @@ import (
 )
 
 const (
-	githubTokenURL   = "https://github.com/login/oauth/access_token"
-	githubUserURL    = "https://api.github.com/user"
-	githubOrgsURL    = "https://api.github.com/user/orgs"
+	githubTokenURL    = "https://github.com/login/oauth/access_token"
+	githubUserURL     = "https://api.github.com/user"
+	githubOrgsURL     = "https://api.github.com/user/orgs"
+	githubTeamsURL    = "https://api.github.com/user/teams"
+	githubTeamsPage   = 100
 	httpClientTimeout = 10 * time.Second
 )
 
@@ -157,3 +159,58 @@ func (s *service) getGitHubUserOrgs(ctx context.Context, accessToken string) ([]
 
 	return orgs, nil
 }
+
+// getGitHubUserTeams gets the teams the user belongs to across all
+// organizations, as "org/team-slug".
+func (s *service) getGitHubUserTeams(ctx context.Context, accessToken string) ([]string, error) {
+	client := &http.Client{Timeout: httpClientTimeout}
+
+	var teams []string
+
+	for page := 1; ; page++ {
+		pageURL := fmt.Sprintf("%s?per_page=%d&page=%d", githubTeamsURL, githubTeamsPage, page)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Accept", "application/vnd.github+json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("making request: %w", err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("reading response: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("github api error: status %d", resp.StatusCode)
+		}
+
+		var teamsResp []struct {
+			Slug         string `json:"slug"`
+			Organization struct {
+				Login string `json:"login"`
+			} `json:"organization"`
+		}
+
+		if err := json.Unmarshal(body, &teamsResp); err != nil {
+			return nil, fmt.Errorf("parsing response: %w", err)
+		}
+
+		for _, team := range teamsResp {
+			teams = append(teams, team.Organization.Login+"/"+team.Slug)
+		}
+
+		if len(teamsResp) < githubTeamsPage {
+			return teams, nil
+		}
+	}
+}
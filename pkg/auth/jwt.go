@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+)
+
+// jwtHeader is the encoded header of every access token. Tokens with any
+// other header are rejected, so the algorithm cannot be chosen by the client.
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// accessClaims are the claims of a JWT access token. They carry everything
+// ValidateSession needs, so validating an access token reads nothing from the
+// store.
+type accessClaims struct {
+	Subject      string             `json:"sub"`
+	SessionID    string             `json:"sid"`
+	Username     string             `json:"name"`
+	Role         store.Role         `json:"role"`
+	AuthProvider store.AuthProvider `json:"provider"`
+	GitHubID     string             `json:"github_id,omitempty"`
+	IssuedAt     int64              `json:"iat"`
+	ExpiresAt    int64              `json:"exp"`
+}
+
+// user returns the user described by the claims.
+func (c *accessClaims) user() *store.User {
+	return &store.User{
+		ID:           c.Subject,
+		Username:     c.Username,
+		Role:         c.Role,
+		AuthProvider: c.AuthProvider,
+		GitHubID:     c.GitHubID,
+	}
+}
+
+// isJWT reports whether a token is a JWT rather than an opaque session token,
+// which is URL-safe base64 and never contains a dot.
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// signJWT encodes and signs claims as an HS256 JWT.
+func signJWT(secret []byte, claims *accessClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("marshaling claims: %w", err)
+	}
+
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	return unsigned + "." + jwtSignature(secret, unsigned), nil
+}
+
+// parseJWT verifies a JWT signed by signJWT and returns its claims. Expired
+// tokens are rejected unless allowExpired is set.
+func parseJWT(secret []byte, token string, now time.Time, allowExpired bool) (*accessClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, fmt.Errorf("malformed access token")
+	}
+
+	expected := jwtSignature(secret, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(parts[2]), []byte(expected)) {
+		return nil, fmt.Errorf("invalid access token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decoding access token: %w", err)
+	}
+
+	var claims accessClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("parsing access token: %w", err)
+	}
+
+	if !allowExpired && now.Unix() >= claims.ExpiresAt {
+		return nil, fmt.Errorf("access token expired")
+	}
+
+	return &claims, nil
+}
+
+// jwtSignature returns the encoded HMAC-SHA256 of a JWT's header and payload.
+func jwtSignature(secret []byte, unsigned string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// IssueAccessToken exchanges a refresh token, the opaque token of a stored
+// session, for a signed access token valid for the configured access TTL.
+func (s *service) IssueAccessToken(ctx context.Context, refreshToken string) (*store.User, string, time.Time, error) {
+	if !s.cfg.Auth.JWT.Enabled {
+		return nil, "", time.Time{}, fmt.Errorf("jwt sessions are not enabled")
+	}
+
+	session, err := s.store.GetSessionByToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, "", time.Time{}, fmt.Errorf("getting session: %w", err)
+	}
+
+	now := time.Now()
+
+	if session == nil || now.After(session.ExpiresAt) {
+		return nil, "", time.Time{}, fmt.Errorf("refresh token invalid or expired")
+	}
+
+	user, err := s.store.GetUser(ctx, session.UserID)
+	if err != nil {
+		return nil, "", time.Time{}, fmt.Errorf("getting user: %w", err)
+	}
+
+	if user == nil {
+		return nil, "", time.Time{}, fmt.Errorf("user not found")
+	}
+
+	s.touchSession(ctx, session)
+
+	// Access tokens never outlive their session.
+	expiresAt := now.Add(s.cfg.Auth.JWT.AccessTTL)
+	if session.ExpiresAt.Before(expiresAt) {
+		expiresAt = session.ExpiresAt
+	}
+
+	token, err := signJWT([]byte(s.cfg.Auth.JWT.Secret), &accessClaims{
+		Subject:      user.ID,
+		SessionID:    session.ID,
+		Username:     user.Username,
+		Role:         user.Role,
+		AuthProvider: user.AuthProvider,
+		GitHubID:     user.GitHubID,
+		IssuedAt:     now.Unix(),
+		ExpiresAt:    expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, "", time.Time{}, fmt.Errorf("signing access token: %w", err)
+	}
+
+	return user, token, expiresAt, nil
+}
+
+// parseAccessToken verifies an access token issued by IssueAccessToken.
+func (s *service) parseAccessToken(token string, allowExpired bool) (*accessClaims, error) {
+	return parseJWT([]byte(s.cfg.Auth.JWT.Secret), token, time.Now(), allowExpired)
+}
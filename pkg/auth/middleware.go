@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"strings"
 
@@ -12,9 +13,40 @@ import (
 type contextKey string
 
 const (
-	userContextKey contextKey = "user"
+	userContextKey         contextKey = "user"
+	impersonatorContextKey contextKey = "impersonator"
+	clientInfoContextKey   contextKey = "client_info"
 )
 
+// ClientInfo identifies the client of a request, recorded on the sessions it
+// creates or uses.
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// ClientInfoMiddleware adds the request's ClientInfo to its context. It relies
+// on an earlier middleware (e.g. chi's RealIP) having resolved RemoteAddr.
+func ClientInfoMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+
+		info := ClientInfo{IPAddress: ip, UserAgent: r.UserAgent()}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientInfoContextKey, info)))
+	})
+}
+
+// clientInfoFromContext retrieves the ClientInfo added by ClientInfoMiddleware.
+func clientInfoFromContext(ctx context.Context) (ClientInfo, bool) {
+	info, ok := ctx.Value(clientInfoContextKey).(ClientInfo)
+
+	return info, ok
+}
+
 // UserFromContext retrieves the authenticated user from the context.
 func UserFromContext(ctx context.Context) *store.User {
 	user, ok := ctx.Value(userContextKey).(*store.User)
@@ -30,11 +62,27 @@ func ContextWithUser(ctx context.Context, user *store.User) context.Context {
 	return context.WithValue(ctx, userContextKey, user)
 }
 
+// ImpersonatorFromContext retrieves the admin acting as the context's user, or
+// nil when the request is not impersonated.
+func ImpersonatorFromContext(ctx context.Context) *store.User {
+	user, ok := ctx.Value(impersonatorContextKey).(*store.User)
+	if !ok {
+		return nil
+	}
+
+	return user
+}
+
+// ContextWithImpersonator records the admin acting as the context's user.
+func ContextWithImpersonator(ctx context.Context, admin *store.User) context.Context {
+	return context.WithValue(ctx, impersonatorContextKey, admin)
+}
+
 // AuthMiddleware creates middleware that validates session tokens.
 func AuthMiddleware(authSvc Service) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			token := extractToken(r)
+			token := TokenFromRequest(r)
 			if token == "" {
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
 
@@ -59,7 +107,7 @@ func AuthMiddleware(authSvc Service) func(http.Handler) http.Handler {
 func OptionalAuthMiddleware(authSvc Service) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			token := extractToken(r)
+			token := TokenFromRequest(r)
 			if token != "" {
 				user, err := authSvc.ValidateSession(r.Context(), token)
 				if err == nil && user != nil {
@@ -107,8 +155,9 @@ func RequireAdmin() func(http.Handler) http.Handler {
 	return RequireRole(store.RoleAdmin)
 }
 
-// extractToken extracts the bearer token from the request.
-func extractToken(r *http.Request) string {
+// TokenFromRequest extracts the session token from the Authorization header,
+// the session cookie, or the token query parameter, in that order.
+func TokenFromRequest(r *http.Request) string {
 	// Check Authorization header.
 	authHeader := r.Header.Get("Authorization")
 	if authHeader != "" {
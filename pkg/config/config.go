@@ -1,15 +1,25 @@
 package config
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"net/netip"
+	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/schedule"
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,14 +31,249 @@ type Config struct {
 	Dispatcher DispatcherConfig `yaml:"dispatcher"`
 	Auth       AuthConfig       `yaml:"auth"`
 	History    HistoryConfig    `yaml:"history"`
+	Queue      QueueConfig      `yaml:"queue"`
+	Lint       LintConfig       `yaml:"lint"`
+	Metrics    MetricsConfig    `yaml:"metrics"`
 	Groups     GroupsConfig     `yaml:"groups"`
+	Sync       SyncConfig       `yaml:"sync"`
+	// Notifications configures delivery of job and template subscriptions.
+	Notifications NotificationsConfig `yaml:"notifications"`
+	// ChatOps enqueues jobs from slash-commands in issue and pull request
+	// comments.
+	ChatOps ChatOpsConfig `yaml:"chatops"`
+	// Telemetry periodically pushes anonymized aggregate stats to a central
+	// endpoint.
+	Telemetry TelemetryConfig `yaml:"telemetry"`
+}
+
+// TelemetryConfig enables the status exporter, which pushes anonymized
+// aggregate stats (queue depths, job throughput and failure rates, runner
+// counts) to an endpoint run by whoever manages a fleet of instances. No
+// group, template, repository or user names are sent.
+type TelemetryConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Endpoint is the URL the stats are POSTed to as JSON.
+	Endpoint string `yaml:"endpoint"`
+	// Token is sent as a bearer token when set.
+	Token    string        `yaml:"token"`
+	Interval time.Duration `yaml:"interval"` // default 5m
+	// InstanceID identifies this instance in the reports. Defaults to a hash
+	// of the hostname, so replicas report separately unless they share one.
+	InstanceID string `yaml:"instance_id"`
+}
+
+// validate checks the telemetry settings.
+func (c TelemetryConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	u, err := url.Parse(c.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("endpoint must be an http or https URL")
+	}
+
+	if c.Interval < time.Minute {
+		return fmt.Errorf("interval must be at least 1m")
+	}
+
+	return nil
+}
+
+// ChatOpsConfig enables slash-commands such as
+// "/dispatch testnet-spam network=holesky" in issue and pull request comments,
+// delivered by the GitHub webhook.
+type ChatOpsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Repos lists the "owner/repo" repositories whose comments are read.
+	Repos []string `yaml:"repos"`
+	// Command is the slash-command that starts a comment (default "/dispatch").
+	Command string `yaml:"command"`
+	// UIURL is the base URL of the dispatchoor UI, used to link the job in
+	// replies. Replies name the job ID only when empty.
+	UIURL string `yaml:"ui_url"`
+	// IssueLabel turns new issues carrying this label, such as those opened
+	// from a "dispatch-request" issue form, into jobs. Issues are ignored
+	// when empty.
+	IssueLabel string `yaml:"issue_label"`
+}
+
+// validate checks the chatops settings. Comments arrive by webhook, so the
+// webhook secret must be set.
+func (c ChatOpsConfig) validate(webhookSecret string) error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if webhookSecret == "" {
+		return fmt.Errorf("github.webhook_secret is required to receive comments")
+	}
+
+	if len(c.Repos) == 0 {
+		return fmt.Errorf("repos must list at least one owner/repo")
+	}
+
+	for _, repo := range c.Repos {
+		if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("invalid repo %q, expected owner/repo", repo)
+		}
+	}
+
+	if !strings.HasPrefix(c.Command, "/") || strings.ContainsAny(c.Command, " \t\n") {
+		return fmt.Errorf("command must start with / and contain no whitespace")
+	}
+
+	if c.UIURL != "" {
+		u, err := url.Parse(c.UIURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("ui_url must be an http or https URL")
+		}
+	}
+
+	if strings.TrimSpace(c.IssueLabel) != c.IssueLabel {
+		return fmt.Errorf("issue_label must not start or end with whitespace")
+	}
+
+	return nil
+}
+
+// NotificationsConfig configures how subscription notifications are sent.
+type NotificationsConfig struct {
+	// Email configures the SMTP server used by email subscriptions. Email
+	// subscriptions are refused while Host is empty.
+	Email SMTPConfig `yaml:"email"`
+}
+
+// SMTPConfig contains SMTP server settings.
+type SMTPConfig struct {
+	Host string `yaml:"host"`
+	// Port defaults to 587.
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// From is the sender address of notification emails.
+	From string `yaml:"from"`
+}
+
+// SyncConfig controls how group and template changes from a config reload are
+// applied to the database.
+type SyncConfig struct {
+	// RequireApproval stages reloads that change groups or templates until an
+	// admin approves them. The config loaded at startup is always applied, and
+	// a reload cannot turn approval off until the server restarts.
+	RequireApproval bool `yaml:"require_approval"`
+	// GitRefreshInterval is how often templates are reloaded when a group
+	// reads them from git (0 = only on reload or a push webhook). It is read
+	// at startup only.
+	GitRefreshInterval time.Duration `yaml:"git_refresh_interval"`
+}
+
+// MetricsConfig bounds the group and template labels of job metrics, so installs
+// with many templates do not create unbounded series.
+type MetricsConfig struct {
+	// Groups and Templates list the IDs reported as job metric labels; other IDs
+	// are reported as "other". An empty list allows any ID up to MaxLabelValues.
+	Groups    []string `yaml:"groups"`
+	Templates []string `yaml:"templates"`
+	// MaxLabelValues caps the distinct IDs kept per label (default 100).
+	MaxLabelValues int `yaml:"max_label_values"`
 }
 
 // ServerConfig contains HTTP server settings.
 type ServerConfig struct {
-	Listen      string          `yaml:"listen"`
-	CORSOrigins []string        `yaml:"cors_origins"`
-	RateLimit   RateLimitConfig `yaml:"rate_limit"`
+	Listen string `yaml:"listen"`
+	// MetricsListen serves /metrics and /health on a second address without rate
+	// limiting. When set, /metrics is no longer served on Listen.
+	MetricsListen string   `yaml:"metrics_listen"`
+	CORSOrigins   []string `yaml:"cors_origins"`
+	// TrustedProxies lists the CIDRs, or single IPs, of reverse proxies whose
+	// X-Forwarded-For, True-Client-IP and X-Real-IP headers name the client.
+	// Empty ignores the headers, identifying clients by their connection.
+	TrustedProxies []string          `yaml:"trusted_proxies"`
+	RateLimit      RateLimitConfig   `yaml:"rate_limit"`
+	UI             UIConfig          `yaml:"ui"`
+	RequestLog     RequestLogConfig  `yaml:"request_log"`
+	TLS            TLSConfig         `yaml:"tls"`
+	Timeouts       TimeoutsConfig    `yaml:"timeouts"`
+	Limits         LimitsConfig      `yaml:"limits"`
+	Compression    CompressionConfig `yaml:"compression"`
+}
+
+// CompressionConfig compresses API responses and WebSocket messages for
+// clients on slow links. Payloads below MinSize are sent as-is, since
+// compressing them saves little and costs CPU.
+type CompressionConfig struct {
+	// Enabled gzips responses of clients sending Accept-Encoding: gzip.
+	Enabled bool `yaml:"enabled"`
+	// WebSocket negotiates permessage-deflate with WebSocket clients.
+	WebSocket bool `yaml:"websocket"`
+	// MinSize is the smallest payload, in bytes, that is compressed (default 1024).
+	MinSize int `yaml:"min_size"`
+	// Level is the compression level, from 1 (fastest) to 9 (smallest) (default 5).
+	Level int `yaml:"level"`
+}
+
+// LimitsConfig bounds the job inputs and labels the API accepts, so oversized
+// payloads are rejected before they reach the store or WebSocket clients.
+// Lengths are in bytes.
+type LimitsConfig struct {
+	MaxInputs           int `yaml:"max_inputs"`             // default 25
+	MaxInputKeyLength   int `yaml:"max_input_key_length"`   // default 256
+	MaxInputValueLength int `yaml:"max_input_value_length"` // default 16384
+	MaxLabels           int `yaml:"max_labels"`             // default 20
+	MaxLabelKeyLength   int `yaml:"max_label_key_length"`   // default 64
+	MaxLabelValueLength int `yaml:"max_label_value_length"` // default 256
+}
+
+// TimeoutsConfig contains HTTP server timeouts. Zero means no timeout unless noted.
+type TimeoutsConfig struct {
+	ReadHeader time.Duration `yaml:"read_header"` // default 10s
+	Read       time.Duration `yaml:"read"`
+	Write      time.Duration `yaml:"write"`
+	Idle       time.Duration `yaml:"idle"`
+	// Shutdown bounds draining WebSocket clients and in-flight requests on stop (default 10s).
+	Shutdown time.Duration `yaml:"shutdown"`
+}
+
+// TLS client authentication modes.
+const (
+	TLSClientAuthRequire       = "require"
+	TLSClientAuthVerifyIfGiven = "verify_if_given"
+)
+
+// TLSConfig contains HTTPS settings for the API listener. TLS is enabled when
+// cert_file and key_file are set.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// ClientCAFile enables mTLS: client certificates are verified against this CA bundle.
+	ClientCAFile string `yaml:"client_ca_file"`
+	// ClientAuth is "require" (default) or "verify_if_given" when client_ca_file is set.
+	ClientAuth string `yaml:"client_auth"`
+	// MinVersion is "1.2" (default) or "1.3".
+	MinVersion string `yaml:"min_version"`
+	// ReloadInterval is how often the files are checked for rotation (default 1m).
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+}
+
+// Enabled returns true if TLS is configured.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// RequestLogConfig contains HTTP request logging settings.
+type RequestLogConfig struct {
+	// SampleSuccessfulGets logs one in every N successful GET requests (default 1, all).
+	// Failed requests and other methods are always logged.
+	SampleSuccessfulGets int `yaml:"sample_successful_gets"`
+}
+
+// UIConfig controls how the web UI is served from the API server.
+type UIConfig struct {
+	// Disabled turns off serving the embedded UI under /.
+	Disabled bool `yaml:"disabled"`
+	// ExternalURL redirects UI requests to a separately hosted frontend instead.
+	ExternalURL string `yaml:"external_url"`
 }
 
 // RateLimitConfig contains rate limiting settings for different endpoint tiers.
@@ -37,11 +282,50 @@ type RateLimitConfig struct {
 	Auth          RateLimitTierConfig `yaml:"auth"`
 	Public        RateLimitTierConfig `yaml:"public"`
 	Authenticated RateLimitTierConfig `yaml:"authenticated"`
+	// Whitelist lists client CIDRs, or single IPs, that are never rate limited,
+	// such as monitoring or CI hosts.
+	Whitelist []string `yaml:"whitelist"`
 }
 
 // RateLimitTierConfig contains rate limit settings for a specific tier.
 type RateLimitTierConfig struct {
 	RequestsPerMinute int `yaml:"requests_per_minute"`
+	// Burst is how many requests a client may make at once (default
+	// requests_per_minute).
+	Burst int `yaml:"burst"`
+}
+
+// WhitelistPrefixes parses Whitelist. Single IPs become one-address prefixes.
+func (c RateLimitConfig) WhitelistPrefixes() ([]netip.Prefix, error) {
+	return parsePrefixes(c.Whitelist)
+}
+
+// TrustedProxyPrefixes parses TrustedProxies. Single IPs become one-address
+// prefixes.
+func (c ServerConfig) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	return parsePrefixes(c.TrustedProxies)
+}
+
+// parsePrefixes parses CIDRs and single IPs.
+func parsePrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+
+	for _, entry := range entries {
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q", entry)
+		}
+
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
 }
 
 // DatabaseConfig contains database connection settings.
@@ -49,8 +333,56 @@ type DatabaseConfig struct {
 	Driver   string         `yaml:"driver"`
 	SQLite   SQLiteConfig   `yaml:"sqlite"`
 	Postgres PostgresConfig `yaml:"postgres"`
+	Cache    CacheConfig    `yaml:"cache"`
+	Notify   NotifyConfig   `yaml:"notify"`
+	// SlowQueryThreshold is the duration above which store calls are logged
+	// (default 500ms, negative disables).
+	SlowQueryThreshold time.Duration     `yaml:"slow_query_threshold"`
+	Maintenance        MaintenanceConfig `yaml:"maintenance"`
+	// StatsInterval is how often table row counts and sizes are exported as
+	// metrics (default 5m, negative disables).
+	StatsInterval time.Duration `yaml:"stats_interval"`
 }
 
+// Database maintenance tasks.
+const (
+	MaintenanceTaskVacuum  = "vacuum"
+	MaintenanceTaskAnalyze = "analyze"
+)
+
+// MaintenanceConfig controls periodic database maintenance. SQLite vacuums and
+// analyzes the whole database; PostgreSQL vacuums and analyzes the tables that
+// grow over time (jobs, job_events and audit_log).
+type MaintenanceConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Interval time.Duration `yaml:"interval"` // default 24h
+	// Tasks lists the maintenance to run, "vacuum" and/or "analyze" (default both).
+	Tasks []string `yaml:"tasks"`
+}
+
+// HasTask reports whether the named maintenance task is enabled.
+func (c *MaintenanceConfig) HasTask(task string) bool {
+	return slices.Contains(c.Tasks, task)
+}
+
+// CacheConfig contains settings for the in-process read-through cache of groups and templates.
+type CacheConfig struct {
+	Enabled bool          `yaml:"enabled"`
+	TTL     time.Duration `yaml:"ttl"` // default 30s
+}
+
+// NotifyConfig shares job, runner and group changes between API replicas on
+// the same PostgreSQL database through LISTEN/NOTIFY, so every replica's
+// WebSocket clients see them and caches are invalidated without waiting for
+// their TTL.
+type NotifyConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Channel string `yaml:"channel"` // default dispatchoor_events
+}
+
+// notifyChannelPattern matches channel names usable unquoted in LISTEN.
+var notifyChannelPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
 // SQLiteConfig contains SQLite-specific settings.
 type SQLiteConfig struct {
 	Path string `yaml:"path"`
@@ -72,13 +404,142 @@ type GitHubConfig struct {
 	RunnersToken    string        `yaml:"runners_token"`
 	PollInterval    time.Duration `yaml:"poll_interval"`
 	RateLimitBuffer int           `yaml:"rate_limit_buffer"`
+	// WebhookSecret enables the workflow_job webhook endpoint. Deliveries must
+	// be signed with this secret.
+	WebhookSecret string `yaml:"webhook_secret"`
+	// HostedRunners also syncs the organizations' GitHub-hosted larger
+	// runners, so groups can dispatch to hosted capacity.
+	HostedRunners bool `yaml:"hosted_runners"`
+	// HTTP configures the transport of the GitHub API clients.
+	HTTP GitHubHTTPConfig `yaml:"http"`
+	// App authenticates the API clients as a GitHub App installation instead
+	// of with token. RunnersToken, when set, still takes over runner polling.
+	App GitHubAppConfig `yaml:"app"`
+}
+
+// GitHubAppConfig authenticates as an installation of a GitHub App. The
+// clients mint installation access tokens with the app's private key and
+// renew them before they expire.
+type GitHubAppConfig struct {
+	AppID          int64 `yaml:"app_id"`
+	InstallationID int64 `yaml:"installation_id"`
+	// PrivateKeyFile is the path of the app's PEM-encoded private key.
+	PrivateKeyFile string `yaml:"private_key_file"`
+}
+
+// Enabled reports whether an app is configured.
+func (c GitHubAppConfig) Enabled() bool {
+	return c.AppID != 0 || c.InstallationID != 0 || c.PrivateKeyFile != ""
+}
+
+// validate checks the app settings.
+func (c GitHubAppConfig) validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	if c.AppID <= 0 || c.InstallationID <= 0 {
+		return fmt.Errorf("app_id and installation_id must be positive")
+	}
+
+	if c.PrivateKeyFile == "" {
+		return fmt.Errorf("private_key_file is required")
+	}
+
+	return nil
+}
+
+// GitHubHTTPConfig configures how the GitHub API clients reach GitHub.
+type GitHubHTTPConfig struct {
+	// Timeout bounds each API call, including its retries.
+	Timeout time.Duration `yaml:"timeout"`
+	// Proxy is the proxy URL for API requests. When empty, HTTPS_PROXY,
+	// HTTP_PROXY and NO_PROXY are honoured.
+	Proxy string `yaml:"proxy"`
+	// Retry re-sends requests that failed with a 5xx or a secondary rate limit.
+	Retry GitHubRetryConfig `yaml:"retry"`
+	// RateLimit smooths bursts of requests with a token bucket per client.
+	RateLimit GitHubRateLimitConfig `yaml:"rate_limit"`
+}
+
+// GitHubRateLimitConfig is a token bucket in front of each GitHub API client.
+// Requests beyond Burst wait for a token, refilled at RequestsPerSecond.
+type GitHubRateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`
+}
+
+// GitHubRetryConfig controls retries of failed GitHub API requests. Waits
+// double from InitialBackoff up to MaxBackoff; a Retry-After from GitHub is
+// waited for instead, and the request is not retried when it asks for longer
+// than MaxBackoff.
+type GitHubRetryConfig struct {
+	// MaxAttempts is the number of times a request is sent (1 = no retries).
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
 }
 
 // DispatcherConfig contains dispatch loop settings.
 type DispatcherConfig struct {
-	Enabled          bool          `yaml:"enabled"`
-	Interval         time.Duration `yaml:"interval"`
-	TrackingInterval time.Duration `yaml:"tracking_interval"`
+	Enabled          bool                 `yaml:"enabled"`
+	Interval         time.Duration        `yaml:"interval"`
+	TrackingInterval time.Duration        `yaml:"tracking_interval"`
+	CircuitBreaker   CircuitBreakerConfig `yaml:"circuit_breaker"`
+	RefResolution    RefResolutionConfig  `yaml:"ref_resolution"`
+	RunnerSharing    RunnerSharingConfig  `yaml:"runner_sharing"`
+	// Concurrency is how many groups that share no runners are dispatched at
+	// once each cycle (default 4).
+	Concurrency int `yaml:"concurrency"`
+	// EventDriven also runs a dispatch cycle as soon as a job becomes pending
+	// or a runner becomes idle, instead of waiting for the next interval tick.
+	// Webhook deliveries trigger a cycle regardless of this setting.
+	EventDriven bool         `yaml:"event_driven"`
+	Reruns      RerunsConfig `yaml:"reruns"`
+}
+
+// RerunsConfig controls following workflow runs that are re-run from GitHub
+// after their job failed or was cancelled. Every Interval, the runs of jobs
+// that finished within Window are checked for a new attempt, which moves the
+// job back to triggered so it is tracked to the new outcome.
+type RerunsConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Window   time.Duration `yaml:"window"`   // default 1h
+	Interval time.Duration `yaml:"interval"` // default 1m
+}
+
+// CircuitBreakerConfig controls automatic pausing of groups whose jobs keep failing.
+// A group is paused when at least FailurePercent of its last Jobs finished jobs
+// failed, counting only jobs that finished within Window.
+type CircuitBreakerConfig struct {
+	Enabled        bool          `yaml:"enabled"`
+	Jobs           int           `yaml:"jobs"`
+	FailurePercent int           `yaml:"failure_percent"`
+	Window         time.Duration `yaml:"window"`
+}
+
+// RefResolutionConfig controls resolving job refs to commit SHAs at dispatch time.
+// When Enabled, the resolved SHA is recorded on the job. When DispatchSHA is also
+// set, the workflow is dispatched against the SHA instead of the ref.
+type RefResolutionConfig struct {
+	Enabled     bool `yaml:"enabled"`
+	DispatchSHA bool `yaml:"dispatch_sha"`
+}
+
+// Runner sharing modes.
+const (
+	RunnerSharingRoundRobin = "round_robin"
+	RunnerSharingWeighted   = "weighted"
+)
+
+// RunnerSharingConfig controls how idle runners are allocated between groups whose
+// runner labels overlap. With no mode set, groups are dispatched in list order
+// every cycle, so earlier groups win contended runners.
+type RunnerSharingConfig struct {
+	Mode string `yaml:"mode"`
+	// Weights maps group IDs to their share in weighted mode. Unlisted groups
+	// have a weight of 1.
+	Weights map[string]int `yaml:"weights"`
 }
 
 // AuthConfig contains authentication settings.
@@ -86,8 +547,36 @@ type AuthConfig struct {
 	SessionTTL time.Duration    `yaml:"session_ttl"`
 	Basic      BasicAuthConfig  `yaml:"basic"`
 	GitHub     GitHubAuthConfig `yaml:"github"`
+	JWT        JWTConfig        `yaml:"jwt"`
+	// APITokens are static bearer tokens for automation, such as workflows
+	// reporting their progress. They grant access to the endpoints that accept
+	// API tokens only, not to the rest of the API.
+	APITokens []APIToken `yaml:"api_tokens"`
+}
+
+// APIToken is a named static bearer token. The name identifies the caller in
+// logs and job events.
+type APIToken struct {
+	Name  string `yaml:"name"`
+	Token string `yaml:"token"`
+}
+
+// minAPITokenLength is the minimum length of an auth.api_tokens token.
+const minAPITokenLength = 32
+
+// JWTConfig enables stateless JWT sessions. Logins then return a refresh token,
+// backed by a stored session that lasts SessionTTL, and a signed access token
+// that lasts AccessTTL and is validated without a store lookup.
+type JWTConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Secret signs access tokens (HS256). At least 32 bytes.
+	Secret    string        `yaml:"secret"`
+	AccessTTL time.Duration `yaml:"access_ttl"`
 }
 
+// minJWTSecretLength is the minimum length of auth.jwt.secret.
+const minJWTSecretLength = 32
+
 // BasicAuthConfig contains basic auth settings.
 type BasicAuthConfig struct {
 	Enabled bool       `yaml:"enabled"`
@@ -99,6 +588,9 @@ type UserAuth struct {
 	Username string `yaml:"username"`
 	Password string `yaml:"password"`
 	Role     string `yaml:"role"`
+	// GitHubID links the user to a numeric GitHub user ID, so logging in with
+	// GitHub resolves to this account and its role instead of a separate user.
+	GitHubID string `yaml:"github_id"`
 }
 
 // GitHubAuthConfig contains GitHub OAuth settings.
@@ -109,12 +601,51 @@ type GitHubAuthConfig struct {
 	RedirectURL     string            `yaml:"redirect_url"`
 	OrgRoleMapping  map[string]string `yaml:"org_role_mapping"`
 	UserRoleMapping map[string]string `yaml:"user_role_mapping"`
+	// TeamRoleMapping maps "org/team-slug" to a role, checked after
+	// UserRoleMapping and before OrgRoleMapping.
+	TeamRoleMapping map[string]string `yaml:"team_role_mapping"`
 }
 
 // HistoryConfig contains job history retention settings.
 type HistoryConfig struct {
-	RetentionDays   int           `yaml:"retention_days"`   // default 30, -1 to disable
-	CleanupInterval time.Duration `yaml:"cleanup_interval"` // default 1h
+	RetentionDays   int           `yaml:"retention_days"`     // default 30, -1 to disable
+	CleanupInterval time.Duration `yaml:"cleanup_interval"`   // default 1h
+	CountCacheTTL   time.Duration `yaml:"count_cache_ttl"`    // default 30s, -1 to disable
+	MaxJobsPerGroup int           `yaml:"max_jobs_per_group"` // default 0 (unlimited)
+}
+
+// QueueConfig contains job queue maintenance settings.
+type QueueConfig struct {
+	CompactInterval time.Duration    `yaml:"compact_interval"` // default 0 (disabled)
+	Starvation      StarvationConfig `yaml:"starvation"`
+	Stats           QueueStatsConfig `yaml:"stats"`
+	// StrictInputs rejects template jobs with input keys the template does not
+	// declare in its inputs, catching typos before a run is dispatched.
+	StrictInputs bool `yaml:"strict_inputs"`
+	// StrictRefs checks with GitHub that a job's ref exists when it is added,
+	// instead of the dispatch failing later.
+	StrictRefs bool `yaml:"strict_refs"`
+}
+
+// StarvationConfig controls monitoring of how long jobs wait in the queue. The
+// oldest pending job age is always exported; when Threshold is set, a group
+// whose oldest pending job waited longer is reported once until it recovers.
+type StarvationConfig struct {
+	Threshold     time.Duration `yaml:"threshold"`      // default 0 (no notifications)
+	CheckInterval time.Duration `yaml:"check_interval"` // default 1m
+}
+
+// QueueStatsConfig controls sampling of each group's queue depth and idle
+// runners for backlog trend graphs.
+type QueueStatsConfig struct {
+	Interval  time.Duration `yaml:"interval"`  // default 1m
+	Retention time.Duration `yaml:"retention"` // default 720h (30 days)
+}
+
+// LintConfig contains settings for periodic template linting.
+type LintConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Interval time.Duration `yaml:"interval"` // default 24h
 }
 
 // GroupsConfig contains all group configurations.
@@ -131,20 +662,183 @@ type Group struct {
 	WorkflowDispatchTemplates      []WorkflowDispatchTemplate `yaml:"workflow_dispatch_templates"`
 	WorkflowDispatchTemplatesFiles []string                   `yaml:"workflow_dispatch_templates_files"`
 	WorkflowDispatchTemplatesURLs  []string                   `yaml:"workflow_dispatch_templates_urls"`
+	WorkflowDispatchTemplatesGit   []GitTemplateSource        `yaml:"workflow_dispatch_templates_git"`
+	// Viewers restricts which non-admin users receive the group's live events
+	// (empty = all users). Admins always see every group.
+	Viewers []string `yaml:"viewers"`
+	// SchedulingPolicy picks which pending job is dispatched next (empty = fifo).
+	SchedulingPolicy string `yaml:"scheduling_policy"`
+	// MaxPriority caps the priority jobs may be given (nil = no cap).
+	MaxPriority *int `yaml:"max_priority"`
+	// MaxRequeueLimit caps requeue_limit of auto-requeue jobs, which also
+	// stops jobs from requeueing forever (nil = no cap).
+	MaxRequeueLimit *int `yaml:"max_requeue_limit"`
+	// MinOnlineRunners holds dispatch, and reports the group as degraded, while
+	// fewer matching runners are online (0 = no minimum).
+	MinOnlineRunners int `yaml:"min_online_runners"`
+	// Inputs are defaults shared by all of the group's templates. A template's
+	// own inputs take precedence, and job inputs override both.
+	Inputs input.Map `yaml:"inputs"`
+	// Metadata is context shown on the group's queue page.
+	Metadata *GroupMetadata `yaml:"metadata"`
+}
+
+// GroupMetadata is freeform context about a group: who owns it, where to ask
+// about it and where its runbooks live.
+type GroupMetadata struct {
+	Team        string      `yaml:"team"`
+	ChatChannel string      `yaml:"chat_channel"`
+	Links       []GroupLink `yaml:"links"`
+	// Fields are any other key/value pairs worth showing.
+	Fields map[string]string `yaml:"fields"`
+}
+
+// GroupLink is a titled link about a group, e.g. to a runbook.
+type GroupLink struct {
+	Title string `yaml:"title"`
+	URL   string `yaml:"url"`
+}
+
+// GitTemplateSource is a workflow dispatch templates file in a git repository.
+type GitTemplateSource struct {
+	// URL is the repository to clone. Credentials may be put in it through
+	// environment variables; they are stripped wherever the source is shown.
+	URL string `yaml:"url"`
+	// Ref is the branch or tag to read (empty = the default branch).
+	Ref string `yaml:"ref"`
+	// Path is the templates file, relative to the repository root.
+	Path string `yaml:"path"`
+}
+
+// String describes the source without credentials, as url@ref:path.
+func (s GitTemplateSource) String() string {
+	ref := s.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	return fmt.Sprintf("%s@%s:%s", s.redactedURL(), ref, s.Path)
+}
+
+// Repo returns the owner/name of the repository: the last two segments of
+// the URL's path. Both URLs and scp-like (git@host:owner/name.git) addresses
+// are accepted.
+func (s GitTemplateSource) Repo() string {
+	path := s.URL
+
+	if u, err := url.Parse(s.URL); err == nil && u.Scheme != "" {
+		path = u.Path
+	} else if _, rest, ok := strings.Cut(s.URL, ":"); ok {
+		path = rest
+	}
+
+	segments := strings.Split(strings.TrimSuffix(strings.Trim(path, "/"), ".git"), "/")
+	if len(segments) > 2 {
+		segments = segments[len(segments)-2:]
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// redactedURL returns the URL without user info.
+func (s GitTemplateSource) redactedURL() string {
+	u, err := url.Parse(s.URL)
+	if err != nil || u.User == nil {
+		return s.URL
+	}
+
+	u.User = nil
+
+	return u.String()
+}
+
+// Group scheduling policies.
+const (
+	SchedulingPolicyFIFO             = "fifo"
+	SchedulingPolicyShortestJobFirst = "shortest_job_first"
+)
+
+// Reserved inputs are set by the dispatcher on every dispatch of a workflow
+// that declares them, so runs can report back and correlate their logs. Jobs
+// and templates may not set them.
+const (
+	ReservedInputJobID            = "dispatchoor_job_id"
+	ReservedInputGroup            = "dispatchoor_group"
+	ReservedInputCreatedBy        = "dispatchoor_created_by"
+	ReservedInputQueueWaitSeconds = "dispatchoor_queue_wait_seconds"
+)
+
+// ReservedInputs lists the reserved input names.
+var ReservedInputs = []string{
+	ReservedInputJobID,
+	ReservedInputGroup,
+	ReservedInputCreatedBy,
+	ReservedInputQueueWaitSeconds,
 }
 
 // WorkflowDispatchTemplate represents a workflow dispatch template configuration.
 type WorkflowDispatchTemplate struct {
-	ID         string            `yaml:"id"`
-	Name       string            `yaml:"name"`
-	Owner      string            `yaml:"owner"`
-	Repo       string            `yaml:"repo"`
-	WorkflowID string            `yaml:"workflow_id"`
-	Ref        string            `yaml:"ref"`
-	Inputs     map[string]string `yaml:"inputs"`
-	Labels     map[string]string `yaml:"labels"`
-	SourceType string            `yaml:"-"` // "inline", "file", or "url" - set during loading
-	SourcePath string            `yaml:"-"` // filename or URL (empty for inline) - set during loading
+	ID           string            `yaml:"id"`
+	Name         string            `yaml:"name"`
+	Owner        string            `yaml:"owner"`
+	Repo         string            `yaml:"repo"`
+	WorkflowID   string            `yaml:"workflow_id"`
+	Ref          string            `yaml:"ref"`
+	Inputs       input.Map         `yaml:"inputs"`
+	Labels       map[string]string `yaml:"labels"`
+	Deprecated   bool              `yaml:"deprecated"`
+	SunsetAt     *time.Time        `yaml:"sunset_at"`
+	Environment  string            `yaml:"environment"`   // deployment environment checked before dispatch
+	Category     string            `yaml:"category"`      // optional grouping shown in the UI
+	DisplayOrder int               `yaml:"display_order"` // lower values are listed first
+	// DispatchWindows restricts when jobs may be dispatched (empty = any time).
+	DispatchWindows []schedule.Window `yaml:"dispatch_windows"`
+	// SecretInputs lists inputs whose values are redacted from WebSocket broadcasts.
+	SecretInputs []string `yaml:"secret_inputs"`
+	// PinnedInputs lists inputs whose values jobs may not override.
+	PinnedInputs []string `yaml:"pinned_inputs"`
+	// MinIdleRunners holds dispatch until this many matching runners are idle,
+	// for workflows whose matrix jobs must start together.
+	MinIdleRunners int `yaml:"min_idle_runners"`
+	// DefaultPriority is the priority of jobs enqueued from the template.
+	DefaultPriority int `yaml:"default_priority"`
+	// KeepRunning keeps this many of the template's jobs queued or running at
+	// all times, enqueuing a new job whenever one finishes.
+	KeepRunning int `yaml:"keep_running"`
+	// StallTimeout flags a running job as stalled when neither a heartbeat
+	// from its workflow nor its GitHub run changed for this long.
+	StallTimeout time.Duration `yaml:"stall_timeout"`
+	// MaxDuration cancels a running job's GitHub run and fails the job with a
+	// timeout once it has run for this long since being dispatched.
+	MaxDuration time.Duration `yaml:"max_duration"`
+	// Canary dispatches a share of the template's jobs to an alternate
+	// workflow or ref, to roll out workflow changes gradually.
+	Canary *CanaryConfig `yaml:"canary"`
+	// SecretHandoff passes secret inputs to the workflow through short-lived
+	// GitHub Actions secrets, so their values never appear in run inputs.
+	SecretHandoff *SecretHandoffConfig `yaml:"secret_handoff"`
+	SourceType    string               `yaml:"-"` // "inline", "file", "url" or "git" - set during loading
+	SourcePath    string               `yaml:"-"` // filename, URL or git source (empty for inline) - set during loading
+}
+
+// SecretHandoffConfig lists the secret inputs handed off through GitHub
+// Actions secrets. Each is written to a secret before dispatch, the workflow
+// receives the secret's name as the input, and the secret is deleted once the
+// job has finished.
+type SecretHandoffConfig struct {
+	// Inputs must all be listed in the template's secret_inputs.
+	Inputs []string `yaml:"inputs"`
+	// Environment creates the secrets in the template's deployment environment
+	// rather than the repository.
+	Environment bool `yaml:"environment"`
+}
+
+// CanaryConfig routes a percentage of a template's jobs to an alternate
+// workflow file or ref. Unset fields keep the template's own.
+type CanaryConfig struct {
+	Percent    int    `yaml:"percent"`
+	WorkflowID string `yaml:"workflow_id"`
+	Ref        string `yaml:"ref"`
 }
 
 // Load reads and parses configuration from a YAML file.
@@ -176,6 +870,14 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("loading template URLs: %w", err)
 	}
 
+	// Load templates from git repositories.
+	if err := loadTemplateGit(&cfg); err != nil {
+		return nil, fmt.Errorf("loading template git sources: %w", err)
+	}
+
+	// Merge group input defaults into templates, after all are loaded.
+	applyGroupInputs(&cfg)
+
 	// Apply defaults.
 	applyDefaults(&cfg)
 
@@ -187,6 +889,25 @@ func Load(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// applyGroupInputs adds each group's inputs to the inputs of its templates
+// that do not set them.
+func applyGroupInputs(cfg *Config) {
+	for i := range cfg.Groups.GitHub {
+		group := &cfg.Groups.GitHub[i]
+		if len(group.Inputs) == 0 {
+			continue
+		}
+
+		for j := range group.WorkflowDispatchTemplates {
+			tmpl := &group.WorkflowDispatchTemplates[j]
+
+			inputs := maps.Clone(group.Inputs)
+			maps.Copy(inputs, tmpl.Inputs)
+			tmpl.Inputs = inputs
+		}
+	}
+}
+
 // markInlineTemplates marks templates defined inline in the config with source type.
 func markInlineTemplates(cfg *Config) {
 	for i := range cfg.Groups.GitHub {
@@ -296,6 +1017,127 @@ func loadTemplateURLs(cfg *Config) error {
 	return nil
 }
 
+// gitCloneTimeout bounds cloning one git template source.
+const gitCloneTimeout = 2 * time.Minute
+
+// loadTemplateGit loads workflow dispatch templates from files in git
+// repositories. Each repository and ref is cloned once per load.
+func loadTemplateGit(cfg *Config) error {
+	checkouts := make(map[string]*os.Root)
+
+	defer func() {
+		for _, root := range checkouts {
+			dir := root.Name()
+			_ = root.Close()
+			_ = os.RemoveAll(dir)
+		}
+	}()
+
+	for i := range cfg.Groups.GitHub {
+		group := &cfg.Groups.GitHub[i]
+
+		for _, src := range group.WorkflowDispatchTemplatesGit {
+			if src.URL == "" || src.Path == "" {
+				return fmt.Errorf("workflow_dispatch_templates_git for group %s: url and path are required", group.ID)
+			}
+
+			key := src.URL + "\x00" + src.Ref
+
+			root, ok := checkouts[key]
+			if !ok {
+				var err error
+
+				root, err = cloneGitSource(src)
+				if err != nil {
+					return fmt.Errorf("cloning %s for group %s: %w", src, group.ID, err)
+				}
+
+				checkouts[key] = root
+			}
+
+			data, err := readRootFile(root, src.Path)
+			if err != nil {
+				return fmt.Errorf("reading template git source %s for group %s: %w", src, group.ID, err)
+			}
+
+			// Expand environment variables.
+			expanded := expandEnvVars(string(data))
+
+			var templates []WorkflowDispatchTemplate
+			if err := yaml.Unmarshal([]byte(expanded), &templates); err != nil {
+				return fmt.Errorf("parsing template git source %s for group %s: %w", src, group.ID, err)
+			}
+
+			// Set source type and path for each template from git.
+			for j := range templates {
+				templates[j].SourceType = "git"
+				templates[j].SourcePath = src.String()
+			}
+
+			// Append templates from git to any existing templates.
+			group.WorkflowDispatchTemplates = append(group.WorkflowDispatchTemplates, templates...)
+		}
+	}
+
+	return nil
+}
+
+// cloneGitSource shallow clones the source's ref into a temporary directory
+// and opens it. The caller removes the directory.
+func cloneGitSource(src GitTemplateSource) (*os.Root, error) {
+	dir, err := os.MkdirTemp("", "dispatchoor-templates-")
+	if err != nil {
+		return nil, fmt.Errorf("creating checkout directory: %w", err)
+	}
+
+	args := []string{"clone", "--quiet", "--depth", "1", "--single-branch"}
+	if src.Ref != "" {
+		args = append(args, "--branch", src.Ref)
+	}
+
+	args = append(args, "--", src.URL, dir)
+
+	ctx, cancel := context.WithTimeout(context.Background(), gitCloneTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		_ = os.RemoveAll(dir)
+
+		// git may echo the URL, credentials included.
+		msg := strings.ReplaceAll(strings.TrimSpace(stderr.String()), src.URL, src.redactedURL())
+
+		return nil, fmt.Errorf("%w: %s", err, msg)
+	}
+
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+
+		return nil, fmt.Errorf("opening checkout: %w", err)
+	}
+
+	return root, nil
+}
+
+// readRootFile reads a file through root, so neither the path nor symlinks in
+// the checkout can reach files outside it.
+func readRootFile(root *os.Root, name string) ([]byte, error) {
+	f, err := root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	return io.ReadAll(f)
+}
+
 // expandEnvVars replaces ${VAR} and $VAR patterns with environment variable values.
 func expandEnvVars(s string) string {
 	// Match ${VAR} pattern.
@@ -329,6 +1171,44 @@ func applyDefaults(cfg *Config) {
 		cfg.Server.Listen = ":9090"
 	}
 
+	if cfg.Server.Timeouts.ReadHeader == 0 {
+		cfg.Server.Timeouts.ReadHeader = 10 * time.Second
+	}
+
+	if cfg.Server.Timeouts.Shutdown == 0 {
+		cfg.Server.Timeouts.Shutdown = 10 * time.Second
+	}
+
+	limitDefaults := []struct {
+		limit *int
+		value int
+	}{
+		{&cfg.Server.Limits.MaxInputs, 25},
+		{&cfg.Server.Limits.MaxInputKeyLength, 256},
+		{&cfg.Server.Limits.MaxInputValueLength, 16384},
+		{&cfg.Server.Limits.MaxLabels, 20},
+		{&cfg.Server.Limits.MaxLabelKeyLength, 64},
+		{&cfg.Server.Limits.MaxLabelValueLength, 256},
+	}
+
+	for _, d := range limitDefaults {
+		if *d.limit == 0 {
+			*d.limit = d.value
+		}
+	}
+
+	if cfg.Server.TLS.ReloadInterval == 0 {
+		cfg.Server.TLS.ReloadInterval = time.Minute
+	}
+
+	if cfg.Server.Compression.MinSize == 0 {
+		cfg.Server.Compression.MinSize = 1024
+	}
+
+	if cfg.Server.Compression.Level == 0 {
+		cfg.Server.Compression.Level = 5
+	}
+
 	if cfg.Database.Driver == "" {
 		cfg.Database.Driver = "sqlite"
 	}
@@ -345,6 +1225,14 @@ func applyDefaults(cfg *Config) {
 		cfg.Database.Postgres.SSLMode = "disable"
 	}
 
+	if cfg.Database.Cache.TTL == 0 {
+		cfg.Database.Cache.TTL = 30 * time.Second
+	}
+
+	if cfg.Database.Notify.Channel == "" {
+		cfg.Database.Notify.Channel = "dispatchoor_events"
+	}
+
 	if cfg.GitHub.PollInterval == 0 {
 		cfg.GitHub.PollInterval = 60 * time.Second
 	}
@@ -353,6 +1241,46 @@ func applyDefaults(cfg *Config) {
 		cfg.GitHub.RateLimitBuffer = 100
 	}
 
+	if cfg.GitHub.HTTP.Timeout == 0 {
+		cfg.GitHub.HTTP.Timeout = 60 * time.Second
+	}
+
+	if cfg.GitHub.HTTP.Retry.MaxAttempts == 0 {
+		cfg.GitHub.HTTP.Retry.MaxAttempts = 3
+	}
+
+	if cfg.GitHub.HTTP.Retry.InitialBackoff == 0 {
+		cfg.GitHub.HTTP.Retry.InitialBackoff = time.Second
+	}
+
+	if cfg.GitHub.HTTP.Retry.MaxBackoff == 0 {
+		cfg.GitHub.HTTP.Retry.MaxBackoff = 30 * time.Second
+	}
+
+	if cfg.GitHub.HTTP.RateLimit.RequestsPerSecond == 0 {
+		cfg.GitHub.HTTP.RateLimit.RequestsPerSecond = 10
+	}
+
+	if cfg.GitHub.HTTP.RateLimit.Burst == 0 {
+		cfg.GitHub.HTTP.RateLimit.Burst = 20
+	}
+
+	if cfg.Database.SlowQueryThreshold == 0 {
+		cfg.Database.SlowQueryThreshold = 500 * time.Millisecond
+	}
+
+	if cfg.Database.Maintenance.Interval == 0 {
+		cfg.Database.Maintenance.Interval = 24 * time.Hour
+	}
+
+	if cfg.Database.StatsInterval == 0 {
+		cfg.Database.StatsInterval = 5 * time.Minute
+	}
+
+	if len(cfg.Database.Maintenance.Tasks) == 0 {
+		cfg.Database.Maintenance.Tasks = []string{MaintenanceTaskVacuum, MaintenanceTaskAnalyze}
+	}
+
 	if cfg.Dispatcher.Interval == 0 {
 		cfg.Dispatcher.Interval = 30 * time.Second
 	}
@@ -361,10 +1289,42 @@ func applyDefaults(cfg *Config) {
 		cfg.Dispatcher.TrackingInterval = 30 * time.Second
 	}
 
+	if cfg.Dispatcher.Concurrency == 0 {
+		cfg.Dispatcher.Concurrency = 4
+	}
+
+	if cfg.Dispatcher.Reruns.Window == 0 {
+		cfg.Dispatcher.Reruns.Window = time.Hour
+	}
+
+	if cfg.Dispatcher.Reruns.Interval == 0 {
+		cfg.Dispatcher.Reruns.Interval = time.Minute
+	}
+
+	if cfg.Dispatcher.CircuitBreaker.Jobs == 0 {
+		cfg.Dispatcher.CircuitBreaker.Jobs = 10
+	}
+
+	if cfg.Dispatcher.CircuitBreaker.FailurePercent == 0 {
+		cfg.Dispatcher.CircuitBreaker.FailurePercent = 80
+	}
+
+	if cfg.Dispatcher.CircuitBreaker.Window == 0 {
+		cfg.Dispatcher.CircuitBreaker.Window = time.Hour
+	}
+
 	if cfg.Auth.SessionTTL == 0 {
 		cfg.Auth.SessionTTL = 24 * time.Hour
 	}
 
+	if cfg.Auth.JWT.AccessTTL == 0 {
+		cfg.Auth.JWT.AccessTTL = 15 * time.Minute
+	}
+
+	if cfg.Metrics.MaxLabelValues == 0 {
+		cfg.Metrics.MaxLabelValues = 100
+	}
+
 	if cfg.History.RetentionDays == 0 {
 		cfg.History.RetentionDays = 30
 	}
@@ -373,6 +1333,38 @@ func applyDefaults(cfg *Config) {
 		cfg.History.CleanupInterval = time.Hour
 	}
 
+	if cfg.History.CountCacheTTL == 0 {
+		cfg.History.CountCacheTTL = 30 * time.Second
+	}
+
+	if cfg.Lint.Interval == 0 {
+		cfg.Lint.Interval = 24 * time.Hour
+	}
+
+	if cfg.Notifications.Email.Host != "" && cfg.Notifications.Email.Port == 0 {
+		cfg.Notifications.Email.Port = 587
+	}
+
+	if cfg.ChatOps.Command == "" {
+		cfg.ChatOps.Command = "/dispatch"
+	}
+
+	if cfg.Telemetry.Interval == 0 {
+		cfg.Telemetry.Interval = 5 * time.Minute
+	}
+
+	if cfg.Queue.Starvation.CheckInterval == 0 {
+		cfg.Queue.Starvation.CheckInterval = time.Minute
+	}
+
+	if cfg.Queue.Stats.Interval == 0 {
+		cfg.Queue.Stats.Interval = time.Minute
+	}
+
+	if cfg.Queue.Stats.Retention == 0 {
+		cfg.Queue.Stats.Retention = 30 * 24 * time.Hour
+	}
+
 	// Set default rate limits per endpoint tier.
 	if cfg.Server.RateLimit.Auth.RequestsPerMinute == 0 {
 		cfg.Server.RateLimit.Auth.RequestsPerMinute = 10
@@ -386,6 +1378,14 @@ func applyDefaults(cfg *Config) {
 		cfg.Server.RateLimit.Authenticated.RequestsPerMinute = 120
 	}
 
+	for _, tier := range []*RateLimitTierConfig{
+		&cfg.Server.RateLimit.Auth, &cfg.Server.RateLimit.Public, &cfg.Server.RateLimit.Authenticated,
+	} {
+		if tier.Burst == 0 {
+			tier.Burst = tier.RequestsPerMinute
+		}
+	}
+
 	// Set default refs for workflow dispatch templates.
 	for i := range cfg.Groups.GitHub {
 		for j := range cfg.Groups.GitHub[i].WorkflowDispatchTemplates {
@@ -416,6 +1416,16 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("unsupported database driver: %s", c.Database.Driver)
 	}
 
+	if c.Database.Notify.Enabled {
+		if c.Database.Driver != "postgres" {
+			return fmt.Errorf("database.notify requires the postgres driver")
+		}
+
+		if !notifyChannelPattern.MatchString(c.Database.Notify.Channel) {
+			return fmt.Errorf("database.notify.channel must be a lowercase identifier of at most 63 characters")
+		}
+	}
+
 	// Validate auth config.
 	if !c.Auth.Basic.Enabled && !c.Auth.GitHub.Enabled {
 		return fmt.Errorf("at least one auth method (basic or github) must be enabled")
@@ -431,6 +1441,266 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.Dispatcher.RefResolution.DispatchSHA && !c.Dispatcher.RefResolution.Enabled {
+		return fmt.Errorf("dispatcher.ref_resolution.dispatch_sha requires dispatcher.ref_resolution.enabled")
+	}
+
+	// Validate the GitHub client transport.
+	if c.GitHub.HTTP.Timeout < 0 {
+		return fmt.Errorf("github.http.timeout must not be negative")
+	}
+
+	if proxy := c.GitHub.HTTP.Proxy; proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+			return fmt.Errorf("github.http.proxy must be an http, https or socks5 URL")
+		}
+	}
+
+	if retry := c.GitHub.HTTP.Retry; retry.MaxAttempts < 1 || retry.InitialBackoff < 0 || retry.MaxBackoff < retry.InitialBackoff {
+		return fmt.Errorf("github.http.retry: max_attempts must be at least 1 and max_backoff at least initial_backoff")
+	}
+
+	if limit := c.GitHub.HTTP.RateLimit; limit.RequestsPerSecond < 0 || limit.Burst < 1 {
+		return fmt.Errorf("github.http.rate_limit: requests_per_second must not be negative and burst must be at least 1")
+	}
+
+	if err := c.GitHub.App.validate(); err != nil {
+		return fmt.Errorf("github.app: %w", err)
+	}
+
+	if c.GitHub.App.Enabled() && c.GitHub.Token != "" {
+		return fmt.Errorf("github.token and github.app are mutually exclusive")
+	}
+
+	// Validate rate limits.
+	for _, tier := range []struct {
+		name string
+		cfg  RateLimitTierConfig
+	}{
+		{"auth", c.Server.RateLimit.Auth},
+		{"public", c.Server.RateLimit.Public},
+		{"authenticated", c.Server.RateLimit.Authenticated},
+	} {
+		if tier.cfg.RequestsPerMinute < 1 || tier.cfg.Burst < 1 {
+			return fmt.Errorf("server.rate_limit.%s: requests_per_minute and burst must be at least 1", tier.name)
+		}
+	}
+
+	if _, err := c.Server.RateLimit.WhitelistPrefixes(); err != nil {
+		return fmt.Errorf("server.rate_limit.whitelist: %w", err)
+	}
+
+	if _, err := c.Server.TrustedProxyPrefixes(); err != nil {
+		return fmt.Errorf("server.trusted_proxies: %w", err)
+	}
+
+	// Validate TLS.
+	if tls := c.Server.TLS; tls.Enabled() {
+		if tls.CertFile == "" || tls.KeyFile == "" {
+			return fmt.Errorf("server.tls.cert_file and server.tls.key_file must both be set")
+		}
+
+		switch tls.MinVersion {
+		case "", "1.2", "1.3":
+		default:
+			return fmt.Errorf("server.tls.min_version must be \"1.2\" or \"1.3\"")
+		}
+
+		switch tls.ClientAuth {
+		case "", TLSClientAuthRequire, TLSClientAuthVerifyIfGiven:
+		default:
+			return fmt.Errorf("server.tls.client_auth must be %q or %q",
+				TLSClientAuthRequire, TLSClientAuthVerifyIfGiven)
+		}
+
+		if tls.ClientAuth != "" && tls.ClientCAFile == "" {
+			return fmt.Errorf("server.tls.client_auth requires server.tls.client_ca_file")
+		}
+	} else if c.Server.TLS.ClientCAFile != "" {
+		return fmt.Errorf("server.tls.client_ca_file requires server.tls.cert_file and server.tls.key_file")
+	}
+
+	if t := c.Server.Timeouts; t.ReadHeader < 0 || t.Read < 0 || t.Write < 0 || t.Idle < 0 || t.Shutdown < 0 {
+		return fmt.Errorf("server.timeouts must not be negative")
+	}
+
+	if c.Server.MetricsListen != "" && c.Server.MetricsListen == c.Server.Listen {
+		return fmt.Errorf("server.metrics_listen must differ from server.listen")
+	}
+
+	for name, limit := range map[string]int{
+		"max_inputs":             c.Server.Limits.MaxInputs,
+		"max_input_key_length":   c.Server.Limits.MaxInputKeyLength,
+		"max_input_value_length": c.Server.Limits.MaxInputValueLength,
+		"max_labels":             c.Server.Limits.MaxLabels,
+		"max_label_key_length":   c.Server.Limits.MaxLabelKeyLength,
+		"max_label_value_length": c.Server.Limits.MaxLabelValueLength,
+	} {
+		if limit < 0 {
+			return fmt.Errorf("server.limits.%s must not be negative", name)
+		}
+	}
+
+	if c.Server.Compression.MinSize < 0 {
+		return fmt.Errorf("server.compression.min_size must not be negative")
+	}
+
+	if l := c.Server.Compression.Level; l < 1 || l > 9 {
+		return fmt.Errorf("server.compression.level must be between 1 and 9")
+	}
+
+	if c.Server.RequestLog.SampleSuccessfulGets < 0 {
+		return fmt.Errorf("server.request_log.sample_successful_gets must not be negative")
+	}
+
+	if c.Auth.JWT.Enabled {
+		if len(c.Auth.JWT.Secret) < minJWTSecretLength {
+			return fmt.Errorf("auth.jwt.secret must be at least %d bytes when jwt sessions are enabled", minJWTSecretLength)
+		}
+
+		if c.Auth.JWT.AccessTTL < 0 || c.Auth.JWT.AccessTTL > c.Auth.SessionTTL {
+			return fmt.Errorf("auth.jwt.access_ttl must be positive and at most auth.session_ttl")
+		}
+	}
+
+	apiTokenNames := make(map[string]bool, len(c.Auth.APITokens))
+
+	for _, token := range c.Auth.APITokens {
+		if token.Name == "" {
+			return fmt.Errorf("auth.api_tokens: name is required")
+		}
+
+		if apiTokenNames[token.Name] {
+			return fmt.Errorf("auth.api_tokens: duplicate name %q", token.Name)
+		}
+
+		apiTokenNames[token.Name] = true
+
+		if len(token.Token) < minAPITokenLength {
+			return fmt.Errorf("auth.api_tokens: %s token must be at least %d bytes", token.Name, minAPITokenLength)
+		}
+	}
+
+	for team := range c.Auth.GitHub.TeamRoleMapping {
+		if org, slug, ok := strings.Cut(team, "/"); !ok || org == "" || slug == "" || strings.Contains(slug, "/") {
+			return fmt.Errorf("auth.github.team_role_mapping: %q must be in org/team-slug form", team)
+		}
+	}
+
+	linkedGitHubIDs := make(map[string]string, len(c.Auth.Basic.Users))
+
+	for _, user := range c.Auth.Basic.Users {
+		if user.GitHubID == "" {
+			continue
+		}
+
+		if _, err := strconv.ParseInt(user.GitHubID, 10, 64); err != nil {
+			return fmt.Errorf("auth.basic.users: %s github_id %q must be a numeric GitHub user ID", user.Username, user.GitHubID)
+		}
+
+		if other, ok := linkedGitHubIDs[user.GitHubID]; ok {
+			return fmt.Errorf("auth.basic.users: github_id %s is linked to both %s and %s", user.GitHubID, other, user.Username)
+		}
+
+		linkedGitHubIDs[user.GitHubID] = user.Username
+	}
+
+	if c.Lint.Interval < 0 {
+		return fmt.Errorf("lint.interval must be positive")
+	}
+
+	if c.Database.Maintenance.Interval < 0 {
+		return fmt.Errorf("database.maintenance.interval must be positive")
+	}
+
+	for _, task := range c.Database.Maintenance.Tasks {
+		if task != MaintenanceTaskVacuum && task != MaintenanceTaskAnalyze {
+			return fmt.Errorf("database.maintenance.tasks: unknown task %q (must be %q or %q)",
+				task, MaintenanceTaskVacuum, MaintenanceTaskAnalyze)
+		}
+	}
+
+	if c.History.MaxJobsPerGroup < 0 {
+		return fmt.Errorf("history.max_jobs_per_group must not be negative")
+	}
+
+	if c.Metrics.MaxLabelValues < 0 {
+		return fmt.Errorf("metrics.max_label_values must not be negative")
+	}
+
+	if c.Sync.GitRefreshInterval < 0 {
+		return fmt.Errorf("sync.git_refresh_interval must not be negative")
+	}
+
+	if c.Notifications.Email.Host != "" && c.Notifications.Email.From == "" {
+		return fmt.Errorf("notifications.email.from is required when notifications.email.host is set")
+	}
+
+	if err := c.ChatOps.validate(c.GitHub.WebhookSecret); err != nil {
+		return fmt.Errorf("chatops: %w", err)
+	}
+
+	if err := c.Telemetry.validate(); err != nil {
+		return fmt.Errorf("telemetry: %w", err)
+	}
+
+	if c.Queue.Starvation.Threshold < 0 {
+		return fmt.Errorf("queue.starvation.threshold must not be negative")
+	}
+
+	if c.Queue.Starvation.CheckInterval < 0 {
+		return fmt.Errorf("queue.starvation.check_interval must be positive")
+	}
+
+	if c.Queue.Stats.Interval < 0 {
+		return fmt.Errorf("queue.stats.interval must be positive")
+	}
+
+	if c.Queue.Stats.Retention < 0 {
+		return fmt.Errorf("queue.stats.retention must be positive")
+	}
+
+	if c.Server.UI.ExternalURL != "" {
+		u, err := url.Parse(c.Server.UI.ExternalURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("server.ui.external_url must be an absolute http(s) URL")
+		}
+	}
+
+	// Validate runner sharing.
+	switch c.Dispatcher.RunnerSharing.Mode {
+	case "", RunnerSharingRoundRobin, RunnerSharingWeighted:
+	default:
+		return fmt.Errorf("dispatcher.runner_sharing.mode must be %q or %q",
+			RunnerSharingRoundRobin, RunnerSharingWeighted)
+	}
+
+	for groupID, weight := range c.Dispatcher.RunnerSharing.Weights {
+		if weight < 1 {
+			return fmt.Errorf("dispatcher.runner_sharing.weights.%s must be at least 1", groupID)
+		}
+	}
+
+	if c.Dispatcher.Concurrency < 0 {
+		return fmt.Errorf("dispatcher.concurrency must not be negative")
+	}
+
+	if c.Dispatcher.Reruns.Enabled && (c.Dispatcher.Reruns.Window < 0 || c.Dispatcher.Reruns.Interval < 0) {
+		return fmt.Errorf("dispatcher.reruns: window and interval must not be negative")
+	}
+
+	// Validate circuit breaker.
+	if c.Dispatcher.CircuitBreaker.Enabled {
+		if c.Dispatcher.CircuitBreaker.Jobs < 1 {
+			return fmt.Errorf("dispatcher.circuit_breaker.jobs must be at least 1")
+		}
+
+		if c.Dispatcher.CircuitBreaker.FailurePercent < 1 || c.Dispatcher.CircuitBreaker.FailurePercent > 100 {
+			return fmt.Errorf("dispatcher.circuit_breaker.failure_percent must be between 1 and 100")
+		}
+	}
+
 	// Validate groups.
 	groupIDs := make(map[string]bool)
 	jobIDs := make(map[string]bool)
@@ -450,6 +1720,39 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("group %s: runner_labels is required", group.ID)
 		}
 
+		switch group.SchedulingPolicy {
+		case "", SchedulingPolicyFIFO, SchedulingPolicyShortestJobFirst:
+		default:
+			return fmt.Errorf("group %s: scheduling_policy must be %q or %q",
+				group.ID, SchedulingPolicyFIFO, SchedulingPolicyShortestJobFirst)
+		}
+
+		if group.MaxRequeueLimit != nil && *group.MaxRequeueLimit < 0 {
+			return fmt.Errorf("group %s: max_requeue_limit must not be negative", group.ID)
+		}
+
+		if group.MinOnlineRunners < 0 {
+			return fmt.Errorf("group %s: min_online_runners must not be negative", group.ID)
+		}
+
+		if group.Metadata != nil {
+			for i, link := range group.Metadata.Links {
+				if link.Title == "" {
+					return fmt.Errorf("group %s: metadata.links[%d]: title is required", group.ID, i)
+				}
+
+				if u, err := url.Parse(link.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return fmt.Errorf("group %s: metadata.links[%d]: url must be an http or https URL", group.ID, i)
+				}
+			}
+		}
+
+		for _, key := range ReservedInputs {
+			if _, ok := group.Inputs[key]; ok {
+				return fmt.Errorf("group %s: input %q is reserved and set at dispatch", group.ID, key)
+			}
+		}
+
 		for _, tmpl := range group.WorkflowDispatchTemplates {
 			if tmpl.ID == "" {
 				return fmt.Errorf("group %s: workflow_dispatch_template id is required", group.ID)
@@ -472,6 +1775,75 @@ func (c *Config) Validate() error {
 			if tmpl.WorkflowID == "" {
 				return fmt.Errorf("template %s: workflow_id is required", tmpl.ID)
 			}
+
+			if tmpl.SunsetAt != nil && !tmpl.Deprecated {
+				return fmt.Errorf("template %s: sunset_at requires deprecated to be true", tmpl.ID)
+			}
+
+			for i := range tmpl.DispatchWindows {
+				if err := tmpl.DispatchWindows[i].Validate(); err != nil {
+					return fmt.Errorf("template %s: dispatch_windows[%d]: %w", tmpl.ID, i, err)
+				}
+			}
+
+			if tmpl.MinIdleRunners < 0 {
+				return fmt.Errorf("template %s: min_idle_runners must not be negative", tmpl.ID)
+			}
+
+			if tmpl.KeepRunning < 0 {
+				return fmt.Errorf("template %s: keep_running must not be negative", tmpl.ID)
+			}
+
+			if tmpl.StallTimeout < 0 {
+				return fmt.Errorf("template %s: stall_timeout must not be negative", tmpl.ID)
+			}
+
+			if tmpl.MaxDuration < 0 {
+				return fmt.Errorf("template %s: max_duration must not be negative", tmpl.ID)
+			}
+
+			if canary := tmpl.Canary; canary != nil {
+				if canary.Percent < 0 || canary.Percent > 100 {
+					return fmt.Errorf("template %s: canary.percent must be between 0 and 100", tmpl.ID)
+				}
+
+				if canary.WorkflowID == "" && canary.Ref == "" {
+					return fmt.Errorf("template %s: canary requires a workflow_id or ref", tmpl.ID)
+				}
+			}
+
+			if handoff := tmpl.SecretHandoff; handoff != nil {
+				if len(handoff.Inputs) == 0 {
+					return fmt.Errorf("template %s: secret_handoff.inputs is required", tmpl.ID)
+				}
+
+				for _, key := range handoff.Inputs {
+					if !slices.Contains(tmpl.SecretInputs, key) {
+						return fmt.Errorf("template %s: secret_handoff input %q is not in secret_inputs", tmpl.ID, key)
+					}
+				}
+
+				if handoff.Environment && tmpl.Environment == "" {
+					return fmt.Errorf("template %s: secret_handoff.environment requires an environment", tmpl.ID)
+				}
+			}
+
+			if group.MaxPriority != nil && tmpl.DefaultPriority > *group.MaxPriority {
+				return fmt.Errorf("template %s: default_priority %d exceeds the group's max_priority %d",
+					tmpl.ID, tmpl.DefaultPriority, *group.MaxPriority)
+			}
+
+			for _, key := range tmpl.PinnedInputs {
+				if _, ok := tmpl.Inputs[key]; !ok {
+					return fmt.Errorf("template %s: pinned input %q has no value in inputs", tmpl.ID, key)
+				}
+			}
+
+			for _, key := range ReservedInputs {
+				if _, ok := tmpl.Inputs[key]; ok {
+					return fmt.Errorf("template %s: input %q is reserved and set at dispatch", tmpl.ID, key)
+				}
+			}
 		}
 	}
 
@@ -503,6 +1875,11 @@ func (c *Config) HasGitHubToken() bool {
 	return c.GitHub.Token != ""
 }
 
+// HasGitHubApp returns true if a GitHub App is configured.
+func (c *Config) HasGitHubApp() bool {
+	return c.GitHub.App.Enabled()
+}
+
 // GetRunnersToken returns the token to use for listing runners.
 // Returns RunnersToken if configured, otherwise falls back to Token.
 func (c *Config) GetRunnersToken() string {
@@ -522,11 +1899,19 @@ func (c *Config) HasRunnersToken() bool {
 func (c *Config) String() string {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("Server: listen=%s\n", c.Server.Listen))
-	sb.WriteString(fmt.Sprintf("Database: driver=%s\n", c.Database.Driver))
-	sb.WriteString(fmt.Sprintf("GitHub: poll_interval=%s\n", c.GitHub.PollInterval))
-	sb.WriteString(fmt.Sprintf("Dispatcher: enabled=%t interval=%s tracking_interval=%s\n",
-		c.Dispatcher.Enabled, c.Dispatcher.Interval, c.Dispatcher.TrackingInterval))
+	if c.Server.MetricsListen != "" {
+		sb.WriteString(fmt.Sprintf("Server: listen=%s, metrics_listen=%s\n", c.Server.Listen, c.Server.MetricsListen))
+	} else {
+		sb.WriteString(fmt.Sprintf("Server: listen=%s\n", c.Server.Listen))
+	}
+	sb.WriteString(fmt.Sprintf("Database: driver=%s cache=%t notify=%t\n",
+		c.Database.Driver, c.Database.Cache.Enabled, c.Database.Notify.Enabled))
+	sb.WriteString(fmt.Sprintf("GitHub: poll_interval=%s webhooks=%t app=%t\n",
+		c.GitHub.PollInterval, c.GitHub.WebhookSecret != "", c.GitHub.App.Enabled()))
+	sb.WriteString(fmt.Sprintf("Dispatcher: enabled=%t interval=%s tracking_interval=%s concurrency=%d circuit_breaker=%t resolve_refs=%t runner_sharing=%q event_driven=%t\n",
+		c.Dispatcher.Enabled, c.Dispatcher.Interval, c.Dispatcher.TrackingInterval, c.Dispatcher.Concurrency,
+		c.Dispatcher.CircuitBreaker.Enabled, c.Dispatcher.RefResolution.Enabled, c.Dispatcher.RunnerSharing.Mode,
+		c.Dispatcher.EventDriven))
 	sb.WriteString(fmt.Sprintf("Auth: basic=%t github=%t\n",
 		c.Auth.Basic.Enabled, c.Auth.GitHub.Enabled))
 	sb.WriteString(fmt.Sprintf("Groups: %d\n", len(c.Groups.GitHub)))
@@ -0,0 +1,60 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// assignVariant picks whether a job of a canary template runs the canary or
+// the stable workflow, on its first dispatch, and records the choice so later
+// attempts and run tracking use the same workflow. Jobs of templates without a
+// canary are left without a variant.
+func (d *dispatcher) assignVariant(ctx context.Context, job *store.Job, template *store.JobTemplate) error {
+	if template == nil || template.CanaryPercent <= 0 || job.Variant != "" {
+		return nil
+	}
+
+	variant := store.JobVariantStable
+	if rand.IntN(100) < template.CanaryPercent {
+		variant = store.JobVariantCanary
+	}
+
+	if err := d.store.SetJobVariant(ctx, job.ID, variant); err != nil {
+		return fmt.Errorf("recording job variant: %w", err)
+	}
+
+	job.Variant = variant
+
+	d.log.WithFields(logrus.Fields{
+		"job_id":   job.ID,
+		"template": template.ID,
+		"variant":  variant,
+	}).Debug("Assigned job variant")
+
+	return nil
+}
+
+// templateWorkflow returns the workflow and ref a template's job runs: the
+// canary ones for canary jobs, where set, and the template's own otherwise.
+// Canary jobs fall back to the template's workflow once its canary is removed.
+func templateWorkflow(job *store.Job, template *store.JobTemplate) (workflowID, ref string) {
+	workflowID, ref = template.WorkflowID, template.Ref
+
+	if job.Variant != store.JobVariantCanary {
+		return workflowID, ref
+	}
+
+	if template.CanaryWorkflowID != "" {
+		workflowID = template.CanaryWorkflowID
+	}
+
+	if template.CanaryRef != "" {
+		ref = template.CanaryRef
+	}
+
+	return workflowID, ref
+}
@@ -0,0 +1,100 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// checkCircuitBreaker pauses a group when too many of its recent jobs failed.
+// It is called after a job in the group fails. The group stays paused until it
+// is manually unpaused; jobs that finished before the unpause are not counted
+// again afterwards.
+func (d *dispatcher) checkCircuitBreaker(ctx context.Context, groupID string) {
+	cfg := d.cfg.Dispatcher.CircuitBreaker
+	if !cfg.Enabled {
+		return
+	}
+
+	log := d.log.WithField("group", groupID)
+
+	group, err := d.store.GetGroup(ctx, groupID)
+	if err != nil || group == nil || group.Paused {
+		if err != nil {
+			log.WithError(err).Warn("Failed to get group for circuit breaker")
+		}
+
+		return
+	}
+
+	since := time.Now().Add(-cfg.Window)
+	if group.ResumedAt != nil && group.ResumedAt.After(since) {
+		since = *group.ResumedAt
+	}
+
+	// Cancelled jobs are usually user actions, so only completed and failed
+	// jobs count towards the failure rate.
+	history, err := d.store.ListJobHistory(ctx, store.HistoryQueryOpts{
+		GroupID:  groupID,
+		Statuses: []store.JobStatus{store.JobStatusCompleted, store.JobStatusFailed},
+		Limit:    cfg.Jobs,
+	})
+	if err != nil {
+		log.WithError(err).Warn("Failed to list job history for circuit breaker")
+
+		return
+	}
+
+	var total, failed int
+
+	for _, job := range history.Jobs {
+		if job.CompletedAt == nil || job.CompletedAt.Before(since) {
+			continue
+		}
+
+		total++
+
+		if job.Status == store.JobStatusFailed {
+			failed++
+		}
+	}
+
+	// Wait for a full sample before tripping.
+	if total < cfg.Jobs || failed*100 < cfg.FailurePercent*total {
+		return
+	}
+
+	reason := fmt.Sprintf("Auto-paused: %d of the last %d jobs failed", failed, total)
+
+	group.Paused = true
+	group.PausedReason = reason
+
+	if err := d.store.UpdateGroup(ctx, group); err != nil {
+		log.WithError(err).Error("Failed to auto-pause group")
+
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"failed": failed,
+		"total":  total,
+	}).Warn("Group auto-paused by circuit breaker")
+
+	if err := d.store.CreateAuditEntry(ctx, &store.AuditEntry{
+		ID:         uuid.New().String(),
+		Action:     store.AuditActionGroupAutoPaused,
+		EntityType: store.AuditEntityGroup,
+		EntityID:   groupID,
+		Actor:      "dispatcher",
+		Details:    reason,
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		log.WithError(err).Warn("Failed to create audit entry for group auto-pause")
+	}
+
+	d.notifyGroupChange(group)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/ethpandaops/dispatchoor/pkg/config"
 	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/metrics"
 	"github.com/ethpandaops/dispatchoor/pkg/queue"
 	"github.com/ethpandaops/dispatchoor/pkg/store"
 	"github.com/sirupsen/logrus"
@@ -16,11 +17,32 @@ import (
 // RunnerChangeCallback is called when a runner's status changes.
 type RunnerChangeCallback func(runner *store.Runner)
 
+// GroupChangeCallback is called when the dispatcher changes a group's state.
+type GroupChangeCallback func(group *store.Group)
+
+// JobStalledCallback is called when a running job is flagged as stalled, with
+// how long it has been idle.
+type JobStalledCallback func(job *store.Job, idle time.Duration)
+
 // Dispatcher defines the interface for the job dispatch service.
 type Dispatcher interface {
 	Start(ctx context.Context) error
 	Stop() error
 	SetRunnerChangeCallback(cb RunnerChangeCallback)
+	SetGroupChangeCallback(cb GroupChangeCallback)
+	SetJobStalledCallback(cb JobStalledCallback)
+	// Trigger requests a dispatch cycle as soon as possible. Requests made
+	// while a cycle runs are coalesced.
+	Trigger()
+	// SetIntervals changes the dispatch and run tracking intervals of the
+	// running loops.
+	SetIntervals(dispatch, tracking time.Duration)
+	// HandleWorkflowRun applies a workflow run delivered by a webhook to the
+	// job tracking it, as a tracking cycle would.
+	HandleWorkflowRun(ctx context.Context, run *github.WorkflowRun) error
+	// HandleWorkflowJobStarted marks the job tracking a run as running on the
+	// runner a webhook reported picking up one of the run's workflow jobs.
+	HandleWorkflowJobStarted(ctx context.Context, runID, runnerID int64, runnerName string) error
 }
 
 // dispatcher implements Dispatcher.
@@ -30,19 +52,49 @@ type dispatcher struct {
 	store    store.Store
 	queue    queue.Service
 	ghClient github.Client
+	metrics  *metrics.Metrics
 
+	// interval and trackingInterval are guarded by intervalMu, since they can
+	// be changed at runtime. retuneDispatch and retuneTracking wake the loops
+	// to pick up a change.
+	intervalMu       sync.Mutex
 	interval         time.Duration
 	trackingInterval time.Duration
+	retuneDispatch   chan struct{}
+	retuneTracking   chan struct{}
+	// concurrency bounds how many runner pools are dispatched at once.
+	concurrency int
 
 	cancel               context.CancelFunc
 	wg                   sync.WaitGroup
 	mu                   sync.Mutex
 	runnerChangeCallback RunnerChangeCallback
-
-	// workflowLocks provides per-workflow-template locking to prevent race conditions
-	// when multiple groups dispatch the same workflow. Key: "owner/repo/workflow_id".
-	workflowLocks   map[string]*sync.Mutex
-	workflowLocksMu sync.Mutex
+	groupChangeCallback  GroupChangeCallback
+	jobStalledCallback   JobStalledCallback
+
+	// scheduler orders groups that compete for shared runners. Guarded by mu.
+	scheduler *groupScheduler
+	// shortRunnerPools holds the groups with fewer runners online than their
+	// min_online_runners.
+	shortRunnerPoolsMu sync.Mutex
+	shortRunnerPools   map[string]bool
+
+	// trigger wakes the dispatch loop for event driven dispatch.
+	trigger chan struct{}
+
+	// trackMu serializes tracking a job between the tracking loop and
+	// webhook deliveries, so each transition is applied once.
+	trackMu sync.Mutex
+
+	// workflowInputs caches the inputs workflows declare, to decide which
+	// reserved inputs to send.
+	workflowInputsMu sync.Mutex
+	workflowInputs   map[string]workflowInputsEntry
+
+	// environmentErrors counts the consecutive failed environment checks of
+	// held jobs, by job ID.
+	environmentErrorsMu sync.Mutex
+	environmentErrors   map[string]int
 }
 
 // Ensure dispatcher implements Dispatcher.
@@ -55,16 +107,25 @@ func NewDispatcher(
 	st store.Store,
 	q queue.Service,
 	ghClient github.Client,
+	m *metrics.Metrics,
 ) Dispatcher {
 	return &dispatcher{
-		log:              log.WithField("component", "dispatcher"),
-		cfg:              cfg,
-		store:            st,
-		queue:            q,
-		ghClient:         ghClient,
-		interval:         cfg.Dispatcher.Interval,
-		trackingInterval: cfg.Dispatcher.TrackingInterval,
-		workflowLocks:    make(map[string]*sync.Mutex),
+		log:               log.WithField("component", "dispatcher"),
+		cfg:               cfg,
+		store:             st,
+		queue:             q,
+		ghClient:          ghClient,
+		metrics:           m,
+		interval:          cfg.Dispatcher.Interval,
+		trackingInterval:  cfg.Dispatcher.TrackingInterval,
+		concurrency:       cfg.Dispatcher.Concurrency,
+		scheduler:         newGroupScheduler(cfg.Dispatcher.RunnerSharing),
+		shortRunnerPools:  make(map[string]bool),
+		workflowInputs:    make(map[string]workflowInputsEntry),
+		environmentErrors: make(map[string]int),
+		trigger:           make(chan struct{}, 1),
+		retuneDispatch:    make(chan struct{}, 1),
+		retuneTracking:    make(chan struct{}, 1),
 	}
 }
 
@@ -76,7 +137,10 @@ func (d *dispatcher) Start(ctx context.Context) error {
 		return nil
 	}
 
-	d.log.WithField("interval", d.interval).Info("Starting dispatcher")
+	d.log.WithFields(logrus.Fields{
+		"interval":     d.dispatchInterval(),
+		"event_driven": d.cfg.Dispatcher.EventDriven,
+	}).Info("Starting dispatcher")
 
 	ctx, d.cancel = context.WithCancel(ctx)
 
@@ -90,6 +154,13 @@ func (d *dispatcher) Start(ctx context.Context) error {
 
 	go d.trackRunsLoop(ctx)
 
+	// Follow runs re-run from GitHub after their job finished.
+	if d.cfg.Dispatcher.Reruns.Enabled {
+		d.wg.Add(1)
+
+		go d.trackRerunsLoop(ctx)
+	}
+
 	return nil
 }
 
@@ -118,22 +189,87 @@ func (d *dispatcher) notifyRunnerChange(runner *store.Runner) {
 	}
 }
 
-// getWorkflowLock returns or creates a mutex for a specific workflow template.
-// This ensures sequential dispatch for jobs targeting the same workflow.
-func (d *dispatcher) getWorkflowLock(owner, repo, workflowID string) *sync.Mutex {
-	key := fmt.Sprintf("%s/%s/%s", owner, repo, workflowID)
+// SetGroupChangeCallback sets the callback for group state changes.
+func (d *dispatcher) SetGroupChangeCallback(cb GroupChangeCallback) {
+	d.groupChangeCallback = cb
+}
+
+// SetJobStalledCallback sets the callback for stalled jobs.
+func (d *dispatcher) SetJobStalledCallback(cb JobStalledCallback) {
+	d.jobStalledCallback = cb
+}
+
+// Trigger requests a dispatch cycle as soon as possible.
+func (d *dispatcher) Trigger() {
+	select {
+	case d.trigger <- struct{}{}:
+	default:
+		// A cycle is already pending.
+	}
+}
+
+// SetIntervals changes the dispatch and run tracking intervals. The loops
+// restart their tickers with the new intervals right away.
+func (d *dispatcher) SetIntervals(dispatch, tracking time.Duration) {
+	d.intervalMu.Lock()
+	changed := dispatch != d.interval || tracking != d.trackingInterval
+	d.interval = dispatch
+	d.trackingInterval = tracking
+	d.intervalMu.Unlock()
+
+	if !changed {
+		return
+	}
 
-	d.workflowLocksMu.Lock()
-	defer d.workflowLocksMu.Unlock()
+	d.log.WithFields(logrus.Fields{
+		"interval":          dispatch,
+		"tracking_interval": tracking,
+	}).Info("Changed dispatcher intervals")
 
-	if lock, ok := d.workflowLocks[key]; ok {
-		return lock
+	for _, ch := range []chan struct{}{d.retuneDispatch, d.retuneTracking} {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
 	}
+}
+
+// dispatchInterval returns the current dispatch interval.
+func (d *dispatcher) dispatchInterval() time.Duration {
+	d.intervalMu.Lock()
+	defer d.intervalMu.Unlock()
+
+	return d.interval
+}
 
-	lock := &sync.Mutex{}
-	d.workflowLocks[key] = lock
+// runTrackingInterval returns the current run tracking interval.
+func (d *dispatcher) runTrackingInterval() time.Duration {
+	d.intervalMu.Lock()
+	defer d.intervalMu.Unlock()
 
-	return lock
+	return d.trackingInterval
+}
+
+// notifyGroupChange calls the group callback if set.
+func (d *dispatcher) notifyGroupChange(group *store.Group) {
+	if d.groupChangeCallback != nil {
+		d.groupChangeCallback(group)
+	}
+}
+
+// lockWorkflow acquires the store-backed lock for a specific workflow and
+// returns a function releasing it. The lock is shared by every dispatchoor
+// instance using the database, which ensures sequential dispatch and run ID
+// matching for jobs targeting the same workflow.
+func (d *dispatcher) lockWorkflow(ctx context.Context, owner, repo, workflowID string) (func(), error) {
+	key := fmt.Sprintf("%s/%s/%s", owner, repo, workflowID)
+
+	unlock, err := d.store.AcquireWorkflowLock(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("locking workflow %s: %w", key, err)
+	}
+
+	return unlock, nil
 }
 
 // waitForRunID polls GitHub to find and match the run ID for a just-triggered job.
@@ -187,15 +323,14 @@ func (d *dispatcher) waitForRunID(
 }
 
 // getEffectiveWorkflowParams returns the effective workflow parameters,
-// preferring job overrides over template defaults.
-// For manual jobs (template == nil), only job fields are used.
+// preferring job overrides over template defaults (or the template's canary,
+// for canary jobs). For manual jobs (template == nil), only job fields are used.
 func getEffectiveWorkflowParams(job *store.Job, template *store.JobTemplate) (owner, repo, workflowID, ref string) {
 	// Start with template defaults if available.
 	if template != nil {
 		owner = template.Owner
 		repo = template.Repo
-		workflowID = template.WorkflowID
-		ref = template.Ref
+		workflowID, ref = templateWorkflow(job, template)
 	}
 
 	// Job overrides take precedence.
@@ -227,17 +362,23 @@ func (d *dispatcher) dispatchLoop(ctx context.Context) {
 		d.log.WithError(err).Error("Initial dispatch failed")
 	}
 
-	ticker := time.NewTicker(d.interval)
+	ticker := time.NewTicker(d.dispatchInterval())
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-d.retuneDispatch:
+			ticker.Reset(d.dispatchInterval())
+
+			continue
 		case <-ticker.C:
-			if err := d.dispatch(ctx); err != nil {
-				d.log.WithError(err).Error("Dispatch failed")
-			}
+		case <-d.trigger:
+		}
+
+		if err := d.dispatch(ctx); err != nil {
+			d.log.WithError(err).Error("Dispatch failed")
 		}
 	}
 }
@@ -253,27 +394,61 @@ func (d *dispatcher) dispatch(ctx context.Context) error {
 		return fmt.Errorf("listing groups: %w", err)
 	}
 
-	for _, group := range groups {
-		if !group.Enabled {
-			continue
-		}
+	start := time.Now()
+	cycle := newDispatchCycle()
 
-		if group.Paused {
-			d.log.WithField("group", group.ID).Debug("Group is paused, skipping dispatch")
+	active := make([]*store.Group, 0, len(groups))
 
-			continue
+	for _, group := range d.scheduler.order(groups) {
+		if group.Enabled && !group.Archived {
+			active = append(active, group)
 		}
+	}
 
-		if err := d.dispatchForGroup(ctx, group); err != nil {
-			d.log.WithError(err).WithField("group", group.ID).Error("Failed to dispatch for group")
-		}
+	// Groups that cannot share runners are dispatched concurrently, so slow
+	// GitHub calls for one group do not delay the others.
+	pools, err := d.runnerPools(ctx, active)
+	if err != nil {
+		return err
 	}
 
+	d.dispatchPools(ctx, pools, cycle, start)
+
+	d.scheduler.record(groups, cycle)
+	d.recordCycle(time.Since(start), cycle)
+
 	return nil
 }
 
+// slowCycleRatio is the share of the dispatch interval a cycle may take before
+// a warning is logged, since later ticks are delayed once a cycle overruns.
+const slowCycleRatio = 0.8
+
+// recordCycle exports the cycle's metrics and warns when the cycle took most
+// of the dispatch interval.
+func (d *dispatcher) recordCycle(duration time.Duration, cycle *dispatchCycle) {
+	d.metrics.RecordDispatcherCycle(duration.Seconds(), cycle.groups, cycle.jobs)
+
+	fields := logrus.Fields{
+		"duration": duration,
+		"groups":   cycle.groups,
+		"jobs":     cycle.jobs,
+	}
+
+	interval := d.dispatchInterval()
+
+	if duration < time.Duration(float64(interval)*slowCycleRatio) {
+		d.log.WithFields(fields).Debug("Dispatch cycle finished")
+
+		return
+	}
+
+	fields["interval"] = interval
+	d.log.WithFields(fields).Warn("Dispatch cycle took most of the dispatch interval")
+}
+
 // dispatchForGroup handles dispatching for a single group.
-func (d *dispatcher) dispatchForGroup(ctx context.Context, group *store.Group) error {
+func (d *dispatcher) dispatchForGroup(ctx context.Context, group *store.Group, cycle *dispatchCycle) error {
 	log := d.log.WithField("group", group.ID)
 
 	// Check if there are already triggered jobs waiting to start.
@@ -290,10 +465,11 @@ func (d *dispatcher) dispatchForGroup(ctx context.Context, group *store.Group) e
 		return nil
 	}
 
-	// Get the next pending job.
-	job, err := d.queue.Peek(ctx, group.ID)
+	// Get the next pending job whose template may be dispatched now, with its
+	// template (nil for manual jobs).
+	job, template, err := d.nextDispatchableJob(ctx, group, time.Now(), cycle)
 	if err != nil {
-		return fmt.Errorf("peeking queue: %w", err)
+		return err
 	}
 
 	if job == nil {
@@ -302,43 +478,70 @@ func (d *dispatcher) dispatchForGroup(ctx context.Context, group *store.Group) e
 		return nil
 	}
 
+	cycle.backlogged[group.ID] = true
+
 	// Get runners for this group's labels.
 	runners, err := d.store.ListRunnersByLabels(ctx, group.RunnerLabels)
 	if err != nil {
 		return fmt.Errorf("listing runners: %w", err)
 	}
 
-	// Find an idle runner.
-	var idleRunner *store.Runner
+	// Dispatching into a pool that has mostly gone offline only ends in
+	// timeouts, so the group waits until enough runners are back.
+	if !d.checkRunnerPool(log, group, runners) {
+		return nil
+	}
+
+	hostedFree, err := d.hostedFreeSlots(ctx, runners)
+	if err != nil {
+		return err
+	}
+
+	// Find the idle runners not already handed a job this cycle. Templates with
+	// min_idle_runners wait at the head of the queue until enough are idle, so
+	// their matrix jobs can all start. A hosted pool stands in for as many
+	// runners as it has free slots.
+	required := 1
+	if template != nil && template.MinIdleRunners > required {
+		required = template.MinIdleRunners
+	}
+
+	idleRunners := make([]*store.Runner, 0, required)
 
 	for _, runner := range runners {
-		if runner.Status == store.RunnerStatusOnline && !runner.Busy {
-			idleRunner = runner
+		if _, claimed := cycle.claimed[runner.ID]; claimed {
+			continue
+		}
+
+		for range min(idleSlots(runner, hostedFree), required-len(idleRunners)) {
+			idleRunners = append(idleRunners, runner)
+		}
 
+		if len(idleRunners) == required {
 			break
 		}
 	}
 
-	if idleRunner == nil {
+	if len(idleRunners) == 0 {
 		log.Debug("No idle runners available")
 
 		return nil
 	}
 
-	// Get the job template (may be nil for manual jobs).
-	var template *store.JobTemplate
+	if len(idleRunners) < required {
+		log.WithFields(logrus.Fields{
+			"job_id":           job.ID,
+			"idle_runners":     len(idleRunners),
+			"min_idle_runners": required,
+		}).Debug("Not enough idle runners for template, waiting")
 
-	if job.TemplateID != "" {
-		var err error
+		return nil
+	}
 
-		template, err = d.store.GetJobTemplate(ctx, job.TemplateID)
-		if err != nil {
-			return fmt.Errorf("getting job template: %w", err)
-		}
+	idleRunner := idleRunners[0]
 
-		if template == nil {
-			return fmt.Errorf("template not found: %s", job.TemplateID)
-		}
+	if err := d.assignVariant(ctx, job, template); err != nil {
+		return err
 	}
 
 	// Get effective workflow parameters (job override or template default).
@@ -350,11 +553,65 @@ func (d *dispatcher) dispatchForGroup(ctx context.Context, group *store.Group) e
 			owner, repo, workflowID, ref)
 	}
 
+	// Fail fast if the deployment environment would block the run (e.g. waiting
+	// for reviewer approval) rather than dispatching a run that never starts.
+	if template != nil && template.Environment != "" {
+		reason, err := d.checkEnvironment(ctx, owner, repo, ref, template.Environment)
+		if err != nil {
+			if !d.environmentCheckFailed(job.ID) {
+				// Hold the job and ask again on the next cycle.
+				log.WithError(err).WithField("environment", template.Environment).
+					Warn("Failed to check environment, holding job")
+
+				return nil
+			}
+
+			reason = fmt.Sprintf("GitHub did not answer after %d attempts: %v", maxEnvironmentCheckErrors, err)
+		} else {
+			d.environmentChecked(job.ID)
+		}
+
+		if reason != "" {
+			if markErr := d.queue.MarkFailed(ctx, job.ID, "Environment check failed: "+reason); markErr != nil {
+				log.WithError(markErr).Error("Failed to mark job as failed")
+			}
+
+			return fmt.Errorf("environment check failed for job %s: %s", job.ID, reason)
+		}
+	}
+
+	// Record the exact commit the run will use, since the ref may move later.
+	dispatchRef := ref
+
+	if d.cfg.Dispatcher.RefResolution.Enabled {
+		sha, err := d.ghClient.ResolveRef(ctx, owner, repo, ref)
+		if err != nil {
+			if d.cfg.Dispatcher.RefResolution.DispatchSHA {
+				return fmt.Errorf("resolving ref: %w", err)
+			}
+
+			log.WithError(err).WithField("ref", ref).Warn("Failed to resolve ref, dispatching without SHA")
+		} else {
+			job.ResolvedSHA = sha
+
+			if err := d.store.UpdateJob(ctx, job); err != nil {
+				return fmt.Errorf("storing resolved sha: %w", err)
+			}
+
+			if d.cfg.Dispatcher.RefResolution.DispatchSHA {
+				dispatchRef = sha
+			}
+		}
+	}
+
 	// Acquire per-workflow lock to prevent race conditions when multiple groups
-	// dispatch the same workflow. This ensures sequential dispatch and run ID matching.
-	workflowLock := d.getWorkflowLock(owner, repo, workflowID)
-	workflowLock.Lock()
-	defer workflowLock.Unlock()
+	// or instances dispatch the same workflow. This ensures sequential dispatch
+	// and run ID matching.
+	unlockWorkflow, err := d.lockWorkflow(ctx, owner, repo, workflowID)
+	if err != nil {
+		return err
+	}
+	defer unlockWorkflow()
 
 	logFields := logrus.Fields{
 		"job_id":   job.ID,
@@ -364,6 +621,12 @@ func (d *dispatcher) dispatchForGroup(ctx context.Context, group *store.Group) e
 		"workflow": workflowID,
 		"ref":      ref,
 	}
+	if job.ResolvedSHA != "" {
+		logFields["sha"] = job.ResolvedSHA
+	}
+	if job.Variant != "" {
+		logFields["variant"] = job.Variant
+	}
 	if template != nil {
 		logFields["template"] = template.Name
 	} else {
@@ -372,29 +635,52 @@ func (d *dispatcher) dispatchForGroup(ctx context.Context, group *store.Group) e
 
 	log.WithFields(logFields).Info("Dispatching job")
 
-	// Trigger the workflow dispatch.
-	if err := d.ghClient.TriggerWorkflowDispatch(
-		ctx,
-		owner,
-		repo,
-		workflowID,
-		ref,
-		job.Inputs,
-	); err != nil {
+	inputs, err := d.handOffSecrets(ctx, log, job, template, owner, repo,
+		d.dispatchInputs(ctx, job, owner, repo, workflowID, ref))
+	if err == nil {
+		// Trigger the workflow dispatch.
+		err = d.ghClient.TriggerWorkflowDispatch(ctx, owner, repo, workflowID, dispatchRef, inputs)
+	}
+
+	if err != nil {
 		// Mark the job as failed if we can't trigger.
 		if markErr := d.queue.MarkFailed(ctx, job.ID, fmt.Sprintf("Failed to trigger: %v", err)); markErr != nil {
 			log.WithError(markErr).Error("Failed to mark job as failed")
 		}
 
+		d.deleteHandoffSecrets(ctx, log, job)
+		d.checkCircuitBreaker(ctx, group.ID)
+
 		return fmt.Errorf("triggering workflow dispatch: %w", err)
 	}
 
+	// The run will occupy all the runners it waited for.
+	for _, runner := range idleRunners {
+		cycle.claimed[runner.ID] = struct{}{}
+	}
+
+	cycle.dispatched[group.ID] = true
+
+	d.metrics.RecordDispatch()
+
 	// Mark as triggered without a run ID initially.
 	// workflow_dispatch returns 204 No Content with no run ID.
 	if err := d.queue.MarkTriggered(ctx, job.ID, 0, ""); err != nil {
 		return fmt.Errorf("marking job as triggered: %w", err)
 	}
 
+	// Reload the job so run matching sees its triggered status and time.
+	triggered, err := d.queue.GetJob(ctx, job.ID)
+	if err != nil {
+		return fmt.Errorf("reloading triggered job: %w", err)
+	}
+
+	if triggered == nil {
+		return fmt.Errorf("triggered job %s not found", job.ID)
+	}
+
+	job = triggered
+
 	// Wait inline for the run ID to be found while holding the workflow lock.
 	// This prevents race conditions when multiple jobs trigger the same workflow.
 	if err := d.waitForRunID(ctx, job, owner, repo, workflowID); err != nil {
@@ -411,13 +697,15 @@ func (d *dispatcher) dispatchForGroup(ctx context.Context, group *store.Group) e
 func (d *dispatcher) trackRunsLoop(ctx context.Context) {
 	defer d.wg.Done()
 
-	ticker := time.NewTicker(d.trackingInterval)
+	ticker := time.NewTicker(d.runTrackingInterval())
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-d.retuneTracking:
+			ticker.Reset(d.runTrackingInterval())
 		case <-ticker.C:
 			if err := d.trackRuns(ctx); err != nil {
 				d.log.WithError(err).Error("Track runs failed")
@@ -444,18 +732,54 @@ func (d *dispatcher) trackRuns(ctx context.Context) error {
 		}
 	}
 
+	// Runs are fetched per repository where possible, to spare API calls.
+	runs := d.fetchTrackedRuns(ctx, jobs)
+
 	for _, job := range jobs {
-		if err := d.trackJob(ctx, job, claimedRunIDs); err != nil {
+		if err := d.trackCurrentJob(ctx, job.ID, claimedRunIDs, runs); err != nil {
 			d.log.WithError(err).WithField("job_id", job.ID).Error("Failed to track job")
 		}
 	}
 
+	// Jobs finished above, or cancelled or failed elsewhere, no longer need
+	// their hand-off secrets.
+	d.cleanupHandoffSecrets(ctx)
+
 	return nil
 }
 
+// trackCurrentJob tracks a job as it is now, under trackMu: a webhook may
+// have moved it on since the tracking cycle listed it.
+func (d *dispatcher) trackCurrentJob(
+	ctx context.Context,
+	jobID string,
+	claimedRunIDs map[int64]struct{},
+	runs map[int64]*github.WorkflowRun,
+) error {
+	d.trackMu.Lock()
+	defer d.trackMu.Unlock()
+
+	job, err := d.store.GetJob(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("getting job: %w", err)
+	}
+
+	if job == nil || (job.Status != store.JobStatusTriggered && job.Status != store.JobStatusRunning) {
+		return nil
+	}
+
+	return d.trackJob(ctx, job, claimedRunIDs, runs)
+}
+
 // trackJob updates the status of a single job.
 // claimedRunIDs is the set of run IDs already assigned to other jobs in this tracking cycle.
-func (d *dispatcher) trackJob(ctx context.Context, job *store.Job, claimedRunIDs map[int64]struct{}) error {
+// runs holds workflow runs already fetched this cycle, by ID.
+func (d *dispatcher) trackJob(
+	ctx context.Context,
+	job *store.Job,
+	claimedRunIDs map[int64]struct{},
+	runs map[int64]*github.WorkflowRun,
+) error {
 	log := d.log.WithField("job_id", job.ID)
 
 	// Get the template to know which repo to query (may be nil for manual jobs).
@@ -481,13 +805,15 @@ func (d *dispatcher) trackJob(ctx context.Context, job *store.Job, claimedRunIDs
 	// Acquire the per-workflow lock to prevent races with the dispatch path
 	// (waitForRunID) which also calls findWorkflowRun under the same lock.
 	if job.RunID == nil || *job.RunID == 0 {
-		workflowLock := d.getWorkflowLock(owner, repo, workflowID)
-		workflowLock.Lock()
+		unlockWorkflow, err := d.lockWorkflow(ctx, owner, repo, workflowID)
+		if err != nil {
+			return err
+		}
 
 		runID, runURL, err := d.findWorkflowRun(ctx, owner, repo, workflowID, job, claimedRunIDs)
 
 		if err != nil {
-			workflowLock.Unlock()
+			unlockWorkflow()
 
 			log.WithError(err).Debug("Could not find workflow run yet")
 
@@ -507,7 +833,7 @@ func (d *dispatcher) trackJob(ctx context.Context, job *store.Job, claimedRunIDs
 		job.RunURL = runURL
 
 		if err := d.store.UpdateJob(ctx, job); err != nil {
-			workflowLock.Unlock()
+			unlockWorkflow()
 
 			return fmt.Errorf("updating job with run ID: %w", err)
 		}
@@ -515,7 +841,7 @@ func (d *dispatcher) trackJob(ctx context.Context, job *store.Job, claimedRunIDs
 		// Mark this run as claimed so other jobs in the same tracking cycle won't steal it.
 		claimedRunIDs[runID] = struct{}{}
 
-		workflowLock.Unlock()
+		unlockWorkflow()
 
 		log.WithFields(logrus.Fields{
 			"run_id":  runID,
@@ -523,12 +849,19 @@ func (d *dispatcher) trackJob(ctx context.Context, job *store.Job, claimedRunIDs
 		}).Info("Found workflow run")
 	}
 
-	// Get the workflow run status.
-	run, err := d.ghClient.GetWorkflowRun(ctx, owner, repo, *job.RunID)
-	if err != nil {
-		return fmt.Errorf("getting workflow run: %w", err)
+	// Get the workflow run status, unless it was listed in bulk.
+	run, ok := runs[*job.RunID]
+	if !ok {
+		var err error
+
+		run, err = d.ghClient.GetWorkflowRun(ctx, owner, repo, *job.RunID)
+		if err != nil {
+			return fmt.Errorf("getting workflow run: %w", err)
+		}
 	}
 
+	d.recordRunAttempt(ctx, log, job, run)
+
 	// Update job status based on run status.
 	switch run.Status {
 	case "queued":
@@ -557,34 +890,23 @@ func (d *dispatcher) trackJob(ctx context.Context, job *store.Job, claimedRunIDs
 				}
 			}
 
-			if err := d.queue.MarkRunning(ctx, job.ID, runnerID, runnerName); err != nil {
-				return fmt.Errorf("marking job as running: %w", err)
+			if err := d.markRunning(ctx, log, job, runnerID, runnerName); err != nil {
+				return err
 			}
-
-			// Update runner busy status and notify.
-			if runnerID != 0 {
-				runner, err := d.store.GetRunner(ctx, runnerID)
-				if err != nil {
-					log.WithError(err).WithField("runner_id", runnerID).Warn("Failed to get runner by ID")
-				} else if runner == nil {
-					log.WithField("runner_id", runnerID).Warn("Runner not found by ID")
-				} else if !runner.Busy {
-					runner.Busy = true
-					if err := d.store.UpsertRunner(ctx, runner); err != nil {
-						log.WithError(err).Warn("Failed to update runner busy status")
-					} else {
-						d.notifyRunnerChange(runner)
-					}
-				}
+		} else {
+			timedOut, err := d.checkMaxDuration(ctx, log, job, template, owner, repo)
+			if err != nil {
+				return err
 			}
 
-			log.WithFields(logrus.Fields{
-				"runner_id":   runnerID,
-				"runner_name": runnerName,
-			}).Info("Job is now running")
+			if !timedOut {
+				d.checkStalled(ctx, log, job, template, run)
+			}
 		}
 
 	case "completed":
+		d.captureAnnotations(ctx, log, job, owner, repo)
+
 		switch run.Conclusion {
 		case "success":
 			if err := d.queue.MarkCompleted(ctx, job.ID); err != nil {
@@ -594,12 +916,19 @@ func (d *dispatcher) trackJob(ctx context.Context, job *store.Job, claimedRunIDs
 			log.Info("Job completed successfully")
 
 		case "failure", "timed_out":
-			if err := d.queue.MarkFailed(ctx, job.ID, fmt.Sprintf("Workflow %s", run.Conclusion)); err != nil {
+			msg := fmt.Sprintf("Workflow %s", run.Conclusion)
+			if run.RunAttempt > 1 {
+				msg = fmt.Sprintf("%s (attempt %d)", msg, run.RunAttempt)
+			}
+
+			if err := d.queue.MarkFailed(ctx, job.ID, msg); err != nil {
 				return fmt.Errorf("marking job as failed: %w", err)
 			}
 
 			log.WithField("conclusion", run.Conclusion).Info("Job failed")
 
+			d.checkCircuitBreaker(ctx, job.GroupID)
+
 		case "cancelled":
 			if err := d.queue.MarkCancelled(ctx, job.ID); err != nil {
 				return fmt.Errorf("marking job as cancelled: %w", err)
@@ -615,6 +944,38 @@ func (d *dispatcher) trackJob(ctx context.Context, job *store.Job, claimedRunIDs
 	return nil
 }
 
+// markRunning marks a triggered job as running on a runner, and the runner
+// busy.
+func (d *dispatcher) markRunning(ctx context.Context, log logrus.FieldLogger, job *store.Job, runnerID int64, runnerName string) error {
+	if err := d.queue.MarkRunning(ctx, job.ID, runnerID, runnerName); err != nil {
+		return fmt.Errorf("marking job as running: %w", err)
+	}
+
+	// Update runner busy status and notify.
+	if runnerID != 0 {
+		runner, err := d.store.GetRunner(ctx, runnerID)
+		if err != nil {
+			log.WithError(err).WithField("runner_id", runnerID).Warn("Failed to get runner by ID")
+		} else if runner == nil {
+			log.WithField("runner_id", runnerID).Warn("Runner not found by ID")
+		} else if !runner.Busy {
+			runner.Busy = true
+			if err := d.store.UpsertRunner(ctx, runner); err != nil {
+				log.WithError(err).Warn("Failed to update runner busy status")
+			} else {
+				d.notifyRunnerChange(runner)
+			}
+		}
+	}
+
+	log.WithFields(logrus.Fields{
+		"runner_id":   runnerID,
+		"runner_name": runnerName,
+	}).Info("Job is now running")
+
+	return nil
+}
+
 // buildClaimedRunIDs returns the set of run IDs currently assigned to triggered/running jobs.
 // This is used to prevent multiple jobs from claiming the same GitHub workflow run.
 func (d *dispatcher) buildClaimedRunIDs(ctx context.Context) (map[int64]struct{}, error) {
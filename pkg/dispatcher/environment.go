@@ -0,0 +1,87 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// maxEnvironmentCheckErrors is how many consecutive dispatch cycles a job is
+// held for while its environment cannot be checked before it is failed.
+const maxEnvironmentCheckErrors = 5
+
+// checkEnvironment verifies that a workflow deploying to the named environment can
+// start without manual intervention. It returns a non-empty reason when the job
+// should be failed instead of dispatched. API errors are returned as err; the
+// caller holds the job and retries, since a run dispatched unchecked may never
+// start.
+func (d *dispatcher) checkEnvironment(ctx context.Context, owner, repo, ref, name string) (string, error) {
+	env, err := d.ghClient.GetEnvironment(ctx, owner, repo, name)
+	if err != nil {
+		return "", err
+	}
+
+	if env == nil {
+		return fmt.Sprintf("environment %q does not exist in %s/%s", name, owner, repo), nil
+	}
+
+	if env.RequiredReviewers {
+		return fmt.Sprintf("environment %q requires reviewer approval, the run would wait indefinitely", name), nil
+	}
+
+	if env.BranchPolicies != nil && !refMatchesBranchPolicies(ref, env.BranchPolicies) {
+		return fmt.Sprintf("ref %q is not allowed to deploy to environment %q", ref, name), nil
+	}
+
+	if env.WaitTimer > 0 {
+		d.log.WithField("environment", name).
+			WithField("wait_timer_minutes", env.WaitTimer).
+			Info("Environment delays deployments with a wait timer")
+	}
+
+	return "", nil
+}
+
+// environmentCheckFailed records a failed environment check of the job and
+// reports whether it has now failed maxEnvironmentCheckErrors times in a row.
+func (d *dispatcher) environmentCheckFailed(jobID string) bool {
+	d.environmentErrorsMu.Lock()
+	defer d.environmentErrorsMu.Unlock()
+
+	d.environmentErrors[jobID]++
+	if d.environmentErrors[jobID] < maxEnvironmentCheckErrors {
+		return false
+	}
+
+	delete(d.environmentErrors, jobID)
+
+	return true
+}
+
+// environmentChecked forgets the failed environment checks of the job.
+func (d *dispatcher) environmentChecked(jobID string) {
+	d.environmentErrorsMu.Lock()
+	defer d.environmentErrorsMu.Unlock()
+
+	delete(d.environmentErrors, jobID)
+}
+
+// refMatchesBranchPolicies reports whether ref matches any of the environment's
+// deployment branch policy name patterns.
+func refMatchesBranchPolicies(ref string, patterns []string) bool {
+	ref = strings.TrimPrefix(ref, "refs/heads/")
+	ref = strings.TrimPrefix(ref, "refs/tags/")
+
+	for _, pattern := range patterns {
+		if pattern == ref {
+			return true
+		}
+
+		if ok, err := path.Match(pattern, ref); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
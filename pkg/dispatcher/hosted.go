@@ -0,0 +1,68 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+)
+
+// hostedFreeSlots returns how many more jobs each hosted pool among runners
+// can take, keyed by runner ID. The jobs in flight are counted now rather than
+// taken from the last poll, so a pool is not overfilled between polls. It
+// returns nil without querying the store when no runner is hosted.
+func (d *dispatcher) hostedFreeSlots(ctx context.Context, runners []*store.Runner) (map[int64]int, error) {
+	var pools []*store.Runner
+
+	for _, runner := range runners {
+		if runner.Hosted {
+			pools = append(pools, runner)
+		}
+	}
+
+	if len(pools) == 0 {
+		return nil, nil
+	}
+
+	groups, err := d.store.ListGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing groups: %w", err)
+	}
+
+	groupLabels := make(map[string][]string, len(groups))
+	for _, group := range groups {
+		groupLabels[group.ID] = group.RunnerLabels
+	}
+
+	jobs, err := d.store.ListJobsByStatus(ctx, store.JobStatusTriggered, store.JobStatusRunning)
+	if err != nil {
+		return nil, fmt.Errorf("listing active jobs: %w", err)
+	}
+
+	free := make(map[int64]int, len(pools))
+	for _, pool := range pools {
+		free[pool.ID] = max(pool.MaxRunners-github.HostedActiveJobs(pool, groupLabels, jobs), 0)
+	}
+
+	return free, nil
+}
+
+// idleSlots returns how many jobs a runner can take right now: one for an
+// online, idle self-hosted runner, and the free slots of an online hosted
+// pool.
+func idleSlots(runner *store.Runner, hostedFree map[int64]int) int {
+	if runner.Status != store.RunnerStatusOnline {
+		return 0
+	}
+
+	if runner.Hosted {
+		return hostedFree[runner.ID]
+	}
+
+	if runner.Busy {
+		return 0
+	}
+
+	return 1
+}
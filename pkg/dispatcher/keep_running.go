@@ -0,0 +1,66 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// keepRunningCreatedBy is the creator recorded on jobs enqueued to keep a
+// template's keep_running count.
+const keepRunningCreatedBy = "keep_running"
+
+// keepRunningForGroup tops up the jobs of each of the group's keep_running
+// templates, so that the configured number of them is always pending,
+// triggered or running. Paused jobs count towards the total, so pausing a job
+// does not start another in its place.
+func (d *dispatcher) keepRunningForGroup(ctx context.Context, group *store.Group, now time.Time) error {
+	templates, err := d.store.ListJobTemplatesByGroup(ctx, group.ID)
+	if err != nil {
+		return fmt.Errorf("listing job templates: %w", err)
+	}
+
+	var counts map[string]int
+
+	for _, template := range templates {
+		if template.KeepRunning <= 0 || !template.InConfig || template.IsSunset(now) {
+			continue
+		}
+
+		if counts == nil {
+			jobs, err := d.queue.ListByStatus(ctx, group.ID,
+				store.JobStatusPending, store.JobStatusTriggered, store.JobStatusRunning)
+			if err != nil {
+				return fmt.Errorf("listing active jobs: %w", err)
+			}
+
+			counts = make(map[string]int, len(templates))
+			for _, job := range jobs {
+				counts[job.TemplateID]++
+			}
+		}
+
+		for i := counts[template.ID]; i < template.KeepRunning; i++ {
+			job, err := d.queue.Enqueue(ctx, group.ID, template.ID, keepRunningCreatedBy, nil, nil)
+			if err != nil {
+				d.log.WithError(err).WithFields(logrus.Fields{
+					"group":    group.ID,
+					"template": template.ID,
+				}).Warn("Failed to enqueue keep_running job")
+
+				break
+			}
+
+			d.log.WithFields(logrus.Fields{
+				"group":    group.ID,
+				"template": template.ID,
+				"job_id":   job.ID,
+			}).Info("Enqueued keep_running job")
+		}
+	}
+
+	return nil
+}
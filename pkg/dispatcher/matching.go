@@ -0,0 +1,272 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/queue"
+	"github.com/ethpandaops/dispatchoor/pkg/schedule"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// MatchReason explains whether a pending job can be dispatched right now.
+type MatchReason string
+
+const (
+	// MatchReasonDispatchable means the next dispatch cycle would dispatch the job.
+	MatchReasonDispatchable MatchReason = "dispatchable"
+	// MatchReasonDispatcherDisabled means the dispatcher is disabled in the config.
+	MatchReasonDispatcherDisabled MatchReason = "dispatcher_disabled"
+	// MatchReasonGroupDisabled means the group is disabled.
+	MatchReasonGroupDisabled MatchReason = "group_disabled"
+	// MatchReasonGroupPaused means the group is paused, manually or by the
+	// circuit breaker.
+	MatchReasonGroupPaused MatchReason = "group_paused"
+	// MatchReasonTriggeredPending means the group waits for its triggered jobs
+	// to start running before dispatching more.
+	MatchReasonTriggeredPending MatchReason = "triggered_jobs_pending"
+	// MatchReasonJobPaused means the job itself is paused.
+	MatchReasonJobPaused MatchReason = "job_paused"
+	// MatchReasonNotBefore means the job is held until its not_before time.
+	MatchReasonNotBefore MatchReason = "not_before"
+	// MatchReasonOutsideWindow means the job's template is outside its dispatch windows.
+	MatchReasonOutsideWindow MatchReason = "outside_dispatch_window"
+	// MatchReasonQueuedBehind means another job of the group is dispatched first.
+	MatchReasonQueuedBehind MatchReason = "queued_behind"
+	// MatchReasonNotEnoughOnlineRunners means fewer runners matching the group
+	// are online than its min_online_runners.
+	MatchReasonNotEnoughOnlineRunners MatchReason = "not_enough_online_runners"
+	// MatchReasonNoIdleRunner means no runner matching the group is online and idle.
+	MatchReasonNoIdleRunner MatchReason = "no_idle_runner"
+	// MatchReasonNotEnoughIdleRunners means fewer runners are idle than the
+	// template's min_idle_runners.
+	MatchReasonNotEnoughIdleRunners MatchReason = "not_enough_idle_runners"
+)
+
+// GroupMatchReport describes how a group's pending jobs match its runners.
+type GroupMatchReport struct {
+	GroupID      string   `json:"group_id"`
+	RunnerLabels []string `json:"runner_labels"`
+	Paused       bool     `json:"paused"`
+	PausedReason string   `json:"paused_reason,omitempty"`
+	// TriggeredJobs counts jobs dispatched but not running yet.
+	TriggeredJobs int            `json:"triggered_jobs"`
+	Runners       []*RunnerMatch `json:"runners"`
+	IdleRunners   int            `json:"idle_runners"`
+	// OnlineRunners counts matching runners that are online, busy or not.
+	OnlineRunners    int `json:"online_runners"`
+	MinOnlineRunners int `json:"min_online_runners,omitempty"`
+	// NextJobID is the job the dispatcher picks next, blocked or not.
+	NextJobID string      `json:"next_job_id,omitempty"`
+	Jobs      []*JobMatch `json:"jobs"`
+	// Error is set when the group could not be evaluated.
+	Error string `json:"error,omitempty"`
+}
+
+// RunnerMatch is a runner whose labels match a group.
+type RunnerMatch struct {
+	ID     int64              `json:"id"`
+	Name   string             `json:"name"`
+	Status store.RunnerStatus `json:"status"`
+	Busy   bool               `json:"busy"`
+	Idle   bool               `json:"idle"`
+	// Hosted marks a GitHub-hosted larger runner pool, and FreeSlots is how
+	// many more jobs it can take.
+	Hosted    bool `json:"hosted,omitempty"`
+	FreeSlots int  `json:"free_slots,omitempty"`
+}
+
+// JobMatch explains whether a pending job can be dispatched right now.
+type JobMatch struct {
+	JobID      string      `json:"job_id"`
+	TemplateID string      `json:"template_id,omitempty"`
+	Position   int         `json:"position"`
+	Reason     MatchReason `json:"reason"`
+	Detail     string      `json:"detail,omitempty"`
+}
+
+// MatchingReport evaluates every active group's pending jobs with the rules of
+// a dispatch cycle, without dispatching anything. It does not need a running
+// dispatcher, so it also explains queues while the dispatcher is disabled.
+func MatchingReport(
+	ctx context.Context,
+	log logrus.FieldLogger,
+	cfg *config.Config,
+	st store.Store,
+	q queue.Service,
+	now time.Time,
+) ([]*GroupMatchReport, error) {
+	d := &dispatcher{log: log.WithField("component", "matching_report"), cfg: cfg, store: st, queue: q}
+
+	groups, err := st.ListGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing groups: %w", err)
+	}
+
+	reports := make([]*GroupMatchReport, 0, len(groups))
+
+	for _, group := range groups {
+		if group.Archived {
+			continue
+		}
+
+		report, err := d.matchGroup(ctx, group, now)
+		if err != nil {
+			report = &GroupMatchReport{GroupID: group.ID, RunnerLabels: group.RunnerLabels, Error: err.Error()}
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// matchGroup builds the matching report of one group, following the checks of
+// dispatch and dispatchForGroup in order.
+func (d *dispatcher) matchGroup(ctx context.Context, group *store.Group, now time.Time) (*GroupMatchReport, error) {
+	report := &GroupMatchReport{
+		GroupID:          group.ID,
+		RunnerLabels:     group.RunnerLabels,
+		Paused:           group.Paused,
+		PausedReason:     group.PausedReason,
+		MinOnlineRunners: group.MinOnlineRunners,
+		Runners:          []*RunnerMatch{},
+		Jobs:             []*JobMatch{},
+	}
+
+	runners, err := d.store.ListRunnersByLabels(ctx, group.RunnerLabels)
+	if err != nil {
+		return nil, fmt.Errorf("listing runners: %w", err)
+	}
+
+	report.OnlineRunners = countOnlineRunners(runners)
+
+	hostedFree, err := d.hostedFreeSlots(ctx, runners)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, runner := range runners {
+		slots := idleSlots(runner, hostedFree)
+		report.IdleRunners += slots
+
+		match := &RunnerMatch{
+			ID:     runner.ID,
+			Name:   runner.Name,
+			Status: runner.Status,
+			Busy:   runner.Busy,
+			Idle:   slots > 0,
+			Hosted: runner.Hosted,
+		}
+
+		if runner.Hosted {
+			match.Busy = slots == 0
+			match.FreeSlots = slots
+		}
+
+		report.Runners = append(report.Runners, match)
+	}
+
+	triggered, err := d.queue.ListByStatus(ctx, group.ID, store.JobStatusTriggered)
+	if err != nil {
+		return nil, fmt.Errorf("listing triggered jobs: %w", err)
+	}
+
+	report.TriggeredJobs = len(triggered)
+
+	pending, err := d.queue.ListPending(ctx, group.ID)
+	if err != nil {
+		return nil, fmt.Errorf("listing pending jobs: %w", err)
+	}
+
+	next, nextTemplate, err := d.nextDispatchableJob(ctx, group, now, newDispatchCycle())
+	if err != nil {
+		return nil, err
+	}
+
+	if next != nil {
+		report.NextJobID = next.ID
+	}
+
+	// Reasons that hold for every job of the group, in dispatch order.
+	var groupReason MatchReason
+
+	var groupDetail string
+
+	switch {
+	case !d.cfg.Dispatcher.Enabled:
+		groupReason = MatchReasonDispatcherDisabled
+	case !group.Enabled:
+		groupReason = MatchReasonGroupDisabled
+	case group.Paused:
+		groupReason, groupDetail = MatchReasonGroupPaused, group.PausedReason
+	case len(triggered) > 0:
+		groupReason = MatchReasonTriggeredPending
+		groupDetail = fmt.Sprintf("%d triggered job(s) have not started running", len(triggered))
+	case RunnerPoolShort(group, report.OnlineRunners):
+		groupReason = MatchReasonNotEnoughOnlineRunners
+		groupDetail = fmt.Sprintf("%d of %d required runners online", report.OnlineRunners, group.MinOnlineRunners)
+	}
+
+	templates := make(map[string]*store.JobTemplate)
+
+	for _, job := range pending {
+		match := &JobMatch{JobID: job.ID, TemplateID: job.TemplateID, Position: job.Position}
+		report.Jobs = append(report.Jobs, match)
+
+		var template *store.JobTemplate
+
+		if job.TemplateID != "" {
+			template = templates[job.TemplateID]
+			if template == nil {
+				if template, err = d.store.GetJobTemplate(ctx, job.TemplateID); err != nil {
+					return nil, fmt.Errorf("getting job template: %w", err)
+				}
+
+				templates[job.TemplateID] = template
+			}
+		}
+
+		switch {
+		case job.Paused:
+			match.Reason = MatchReasonJobPaused
+		case !job.Due(now):
+			match.Reason = MatchReasonNotBefore
+			match.Detail = "held until " + job.NotBefore.UTC().Format(time.RFC3339)
+		case template != nil && !schedule.Open(template.DispatchWindows, now):
+			match.Reason = MatchReasonOutsideWindow
+		case groupReason != "":
+			match.Reason, match.Detail = groupReason, groupDetail
+		case next == nil || job.ID != next.ID:
+			match.Reason = MatchReasonQueuedBehind
+			if next != nil {
+				match.Detail = "job " + next.ID + " is dispatched first"
+			}
+		default:
+			match.Reason, match.Detail = idleRunnerMatch(report.IdleRunners, nextTemplate)
+		}
+	}
+
+	return report, nil
+}
+
+// idleRunnerMatch applies the idle runner checks of dispatchForGroup to the
+// group's next job.
+func idleRunnerMatch(idle int, template *store.JobTemplate) (MatchReason, string) {
+	required := 1
+	if template != nil && template.MinIdleRunners > required {
+		required = template.MinIdleRunners
+	}
+
+	switch {
+	case idle == 0:
+		return MatchReasonNoIdleRunner, ""
+	case idle < required:
+		return MatchReasonNotEnoughIdleRunners, fmt.Sprintf("%d of %d required runners idle", idle, required)
+	default:
+		return MatchReasonDispatchable, ""
+	}
+}
@@ -0,0 +1,78 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// maxDuration returns the job's max duration: its own override, else its
+// template's. Zero means no limit.
+func maxDuration(job *store.Job, template *store.JobTemplate) time.Duration {
+	if job.MaxDurationSeconds != nil {
+		return time.Duration(*job.MaxDurationSeconds) * time.Second
+	}
+
+	if template != nil {
+		return time.Duration(template.MaxDurationSeconds) * time.Second
+	}
+
+	return 0
+}
+
+// checkMaxDuration cancels the GitHub run of a running job that has exceeded
+// its max duration since it was dispatched, and fails the job with a timeout.
+// It reports whether the job timed out. If the run cannot be cancelled the job
+// is left running, so the next tracking cycle tries again.
+func (d *dispatcher) checkMaxDuration(
+	ctx context.Context,
+	log logrus.FieldLogger,
+	job *store.Job,
+	template *store.JobTemplate,
+	owner, repo string,
+) (bool, error) {
+	limit := maxDuration(job, template)
+	if limit <= 0 || job.TriggeredAt == nil {
+		return false, nil
+	}
+
+	elapsed := time.Since(*job.TriggeredAt)
+	if elapsed < limit {
+		return false, nil
+	}
+
+	if err := d.ghClient.CancelWorkflowRun(ctx, owner, repo, *job.RunID); err != nil {
+		return false, fmt.Errorf("cancelling workflow run of timed out job: %w", err)
+	}
+
+	msg := fmt.Sprintf("timeout: exceeded max_duration of %s", limit)
+
+	if err := d.queue.MarkFailed(ctx, job.ID, msg); err != nil {
+		return false, fmt.Errorf("marking timed out job as failed: %w", err)
+	}
+
+	log.WithFields(logrus.Fields{
+		"elapsed":      elapsed.Truncate(time.Second),
+		"max_duration": limit,
+	}).Warn("Cancelled job that exceeded its max duration")
+
+	if err := d.store.CreateAuditEntry(ctx, &store.AuditEntry{
+		ID:         uuid.New().String(),
+		Action:     store.AuditActionJobFailed,
+		EntityType: store.AuditEntityJob,
+		EntityID:   job.ID,
+		Actor:      "dispatcher",
+		Details:    fmt.Sprintf("Run cancelled after %s, exceeding max_duration of %s", elapsed.Truncate(time.Second), limit),
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		log.WithError(err).Warn("Failed to create audit entry for timed out job")
+	}
+
+	d.checkCircuitBreaker(ctx, job.GroupID)
+
+	return true, nil
+}
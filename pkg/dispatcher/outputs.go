@@ -0,0 +1,147 @@
+package dispatcher
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// OutputAnnotationTitle is the annotation title that marks a workflow annotation
+// as carrying job outputs. Workflows report outputs with a workflow command:
+//
+//	echo "::notice title=dispatchoor-output::tps=1234%0Aerrors=0"
+//
+// Each line of the message is parsed as a key=value pair.
+const OutputAnnotationTitle = "dispatchoor-output"
+
+const (
+	// maxJobAnnotations bounds the failure annotations stored per job.
+	maxJobAnnotations = 50
+
+	// maxAnnotationMessageLength bounds the length of a stored annotation message.
+	maxAnnotationMessageLength = 1024
+)
+
+// captureAnnotations collects outputs and failure annotations reported by the
+// jobs of a completed workflow run and persists them on the job. Failures are
+// logged and do not prevent the job from being marked as finished.
+func (d *dispatcher) captureAnnotations(
+	ctx context.Context,
+	log logrus.FieldLogger,
+	job *store.Job,
+	owner, repo string,
+) {
+	runJobs, err := d.ghClient.ListWorkflowRunJobs(ctx, owner, repo, *job.RunID)
+	if err != nil {
+		log.WithError(err).Warn("Failed to list workflow jobs for annotations")
+
+		return
+	}
+
+	outputs := make(map[string]string)
+
+	var failures []store.JobAnnotation
+
+	for _, runJob := range runJobs {
+		annotations, err := d.ghClient.ListCheckRunAnnotations(ctx, owner, repo, runJob.ID)
+		if err != nil {
+			log.WithError(err).WithField("workflow_job_id", runJob.ID).Warn("Failed to list annotations")
+
+			continue
+		}
+
+		for key, value := range parseOutputAnnotations(annotations) {
+			outputs[key] = value
+		}
+
+		failures = appendFailureAnnotations(failures, runJob.Name, annotations)
+	}
+
+	if len(outputs) == 0 && len(failures) == 0 {
+		return
+	}
+
+	if len(outputs) > 0 {
+		job.Outputs = outputs
+	}
+
+	if len(failures) > 0 {
+		job.Annotations = failures
+	}
+
+	if err := d.store.UpdateJob(ctx, job); err != nil {
+		log.WithError(err).Warn("Failed to store job annotations")
+
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"outputs":  len(outputs),
+		"failures": len(failures),
+	}).Debug("Captured job annotations")
+}
+
+// appendFailureAnnotations appends the failure level annotations of a workflow
+// job, keeping at most maxJobAnnotations in total. Long messages are truncated.
+func appendFailureAnnotations(
+	failures []store.JobAnnotation,
+	workflowJob string,
+	annotations []*github.Annotation,
+) []store.JobAnnotation {
+	for _, a := range annotations {
+		if len(failures) >= maxJobAnnotations {
+			break
+		}
+
+		if a.Level != "failure" {
+			continue
+		}
+
+		message := a.Message
+		if len(message) > maxAnnotationMessageLength {
+			message = strings.ToValidUTF8(message[:maxAnnotationMessageLength], "") + "..."
+		}
+
+		failures = append(failures, store.JobAnnotation{
+			WorkflowJob: workflowJob,
+			Path:        a.Path,
+			StartLine:   a.StartLine,
+			EndLine:     a.EndLine,
+			Title:       a.Title,
+			Message:     message,
+		})
+	}
+
+	return failures
+}
+
+// parseOutputAnnotations extracts key=value pairs from output annotations.
+// Lines without a key are ignored; later values overwrite earlier ones.
+func parseOutputAnnotations(annotations []*github.Annotation) map[string]string {
+	outputs := make(map[string]string)
+
+	for _, a := range annotations {
+		if a.Title != OutputAnnotationTitle {
+			continue
+		}
+
+		for _, line := range strings.Split(a.Message, "\n") {
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+
+			key = strings.TrimSpace(key)
+			if key == "" {
+				continue
+			}
+
+			outputs[key] = strings.TrimSpace(value)
+		}
+	}
+
+	return outputs
+}
@@ -0,0 +1,155 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+)
+
+// runnerPools splits groups, kept in order, into pools of groups that may
+// compete for the same runners: two groups share a pool when some known runner
+// carries the labels of both. Pools share no runners, so they can be
+// dispatched concurrently while the groups within one are still offered
+// runners in scheduler order.
+func (d *dispatcher) runnerPools(ctx context.Context, groups []*store.Group) ([][]*store.Group, error) {
+	runners, err := d.store.ListRunners(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing runners: %w", err)
+	}
+
+	// parent is a union-find forest over group indexes.
+	parent := make([]int, len(groups))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(i int) int
+
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+
+		return parent[i]
+	}
+
+	for _, runner := range runners {
+		first := -1
+
+		for i, group := range groups {
+			if !runnerHasLabels(runner, group.RunnerLabels) {
+				continue
+			}
+
+			if first < 0 {
+				first = i
+
+				continue
+			}
+
+			parent[find(i)] = find(first)
+		}
+	}
+
+	poolIndex := make(map[int]int, len(groups))
+	pools := make([][]*store.Group, 0, len(groups))
+
+	for i, group := range groups {
+		root := find(i)
+
+		idx, ok := poolIndex[root]
+		if !ok {
+			idx = len(pools)
+			poolIndex[root] = idx
+			pools = append(pools, nil)
+		}
+
+		pools[idx] = append(pools[idx], group)
+	}
+
+	return pools, nil
+}
+
+// runnerHasLabels reports whether a runner carries all of the labels.
+func runnerHasLabels(runner *store.Runner, labels []string) bool {
+	for _, label := range labels {
+		if !slices.Contains(runner.Labels, label) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dispatchPools dispatches each pool on up to d.concurrency workers and
+// merges their allocations into cycle. Each pool gets its own dispatchCycle,
+// since no runner can be claimed by two pools.
+func (d *dispatcher) dispatchPools(ctx context.Context, pools [][]*store.Group, cycle *dispatchCycle, start time.Time) {
+	results := make([]*dispatchCycle, len(pools))
+	work := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for range max(1, min(d.concurrency, len(pools))) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range work {
+				results[i] = newDispatchCycle()
+
+				for _, group := range pools[i] {
+					d.dispatchGroup(ctx, group, results[i], start)
+				}
+			}
+		}()
+	}
+
+	for i := range pools {
+		work <- i
+	}
+
+	close(work)
+	wg.Wait()
+
+	for _, result := range results {
+		cycle.merge(result)
+	}
+}
+
+// dispatchGroup runs one group's part of a dispatch cycle. Errors, and
+// panics, are logged so that one failing group never holds up the others.
+func (d *dispatcher) dispatchGroup(ctx context.Context, group *store.Group, cycle *dispatchCycle, start time.Time) {
+	log := d.log.WithField("group", group.ID)
+
+	defer func() {
+		if r := recover(); r != nil {
+			d.metrics.RecordDispatcherError()
+			log.WithField("panic", r).Error("Panic while dispatching for group")
+		}
+	}()
+
+	// Paused groups keep their keep_running jobs queued for when they resume.
+	if err := d.keepRunningForGroup(ctx, group, start); err != nil {
+		d.metrics.RecordDispatcherError()
+		log.WithError(err).Error("Failed to keep jobs running for group")
+	}
+
+	if group.Paused {
+		log.Debug("Group is paused, skipping dispatch")
+
+		return
+	}
+
+	cycle.groups++
+
+	if err := d.dispatchForGroup(ctx, group, cycle); err != nil {
+		d.metrics.RecordDispatcherError()
+		log.WithError(err).Error("Failed to dispatch for group")
+	}
+}
@@ -0,0 +1,114 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// recordRunAttempt stores the attempt and log and artifact URLs of a job's
+// workflow run when they changed since the job was last tracked.
+func (d *dispatcher) recordRunAttempt(ctx context.Context, log logrus.FieldLogger, job *store.Job, run *github.WorkflowRun) {
+	if run.RunAttempt == job.RunAttempt && run.LogsURL == job.LogsURL && run.ArtifactsURL == job.ArtifactsURL {
+		return
+	}
+
+	if err := d.store.UpdateJobRunAttempt(ctx, job.ID, run.RunAttempt, run.LogsURL, run.ArtifactsURL); err != nil {
+		log.WithError(err).Warn("Failed to record workflow run attempt")
+
+		return
+	}
+
+	job.RunAttempt = run.RunAttempt
+	job.LogsURL = run.LogsURL
+	job.ArtifactsURL = run.ArtifactsURL
+}
+
+// trackRerunsLoop periodically checks recently finished jobs for re-run
+// workflow runs.
+func (d *dispatcher) trackRerunsLoop(ctx context.Context) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.cfg.Dispatcher.Reruns.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.trackReruns(ctx); err != nil {
+				d.log.WithError(err).Error("Track re-runs failed")
+			}
+		}
+	}
+}
+
+// trackReruns moves failed and cancelled jobs that finished within the re-run
+// window back to triggered when their workflow run has a newer attempt.
+func (d *dispatcher) trackReruns(ctx context.Context) error {
+	since := time.Now().Add(-d.cfg.Dispatcher.Reruns.Window)
+
+	jobs, err := d.store.ListJobsCompletedSince(ctx, since, store.JobStatusFailed, store.JobStatusCancelled)
+	if err != nil {
+		return fmt.Errorf("listing finished jobs: %w", err)
+	}
+
+	for _, job := range jobs {
+		if err := d.checkRerun(ctx, job); err != nil {
+			d.log.WithError(err).WithField("job_id", job.ID).Warn("Failed to check job for re-run")
+		}
+	}
+
+	return nil
+}
+
+// checkRerun moves a finished job back to triggered if its run was re-run.
+func (d *dispatcher) checkRerun(ctx context.Context, job *store.Job) error {
+	var template *store.JobTemplate
+
+	if job.TemplateID != "" {
+		var err error
+
+		template, err = d.store.GetJobTemplate(ctx, job.TemplateID)
+		if err != nil {
+			return fmt.Errorf("getting job template: %w", err)
+		}
+
+		if template == nil {
+			return nil
+		}
+	}
+
+	owner, repo, _, _ := getEffectiveWorkflowParams(job, template)
+
+	run, err := d.ghClient.GetWorkflowRun(ctx, owner, repo, *job.RunID)
+	if err != nil {
+		return fmt.Errorf("getting workflow run: %w", err)
+	}
+
+	// Jobs tracked before attempts were recorded saw the first attempt.
+	if run.RunAttempt <= max(job.RunAttempt, 1) {
+		return nil
+	}
+
+	log := d.log.WithFields(logrus.Fields{
+		"job_id":  job.ID,
+		"run_id":  run.ID,
+		"attempt": run.RunAttempt,
+	})
+
+	if err := d.queue.MarkRerun(ctx, job.ID, run.RunAttempt); err != nil {
+		return fmt.Errorf("marking job as re-run: %w", err)
+	}
+
+	d.recordRunAttempt(ctx, log, job, run)
+
+	log.Info("Workflow run was re-run, tracking new attempt")
+
+	return nil
+}
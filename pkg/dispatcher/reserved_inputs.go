@@ -0,0 +1,113 @@
+package dispatcher
+
+import (
+	"context"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/lint"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// workflowInputsTTL is how long the inputs a workflow declares are cached, so
+// a workflow file is fetched at most once per ref in that time.
+const workflowInputsTTL = 5 * time.Minute
+
+// workflowInputsEntry is a cached set of declared workflow inputs.
+type workflowInputsEntry struct {
+	declared  map[string]bool
+	expiresAt time.Time
+}
+
+// dispatchInputs returns the inputs a job is dispatched with: its own inputs
+// plus the reserved inputs its workflow declares on ref, so workflows that do
+// not declare them are unaffected. When the workflow file cannot be read the
+// job is dispatched with its own inputs.
+func (d *dispatcher) dispatchInputs(
+	ctx context.Context,
+	job *store.Job,
+	owner, repo, workflowID, ref string,
+) input.Map {
+	declared := d.declaredInputs(ctx, owner, repo, workflowID, ref)
+	if len(declared) == 0 {
+		return job.Inputs
+	}
+
+	now := time.Now()
+	reserved := input.Map{
+		config.ReservedInputJobID:            input.String(job.ID),
+		config.ReservedInputGroup:            input.String(job.GroupID),
+		config.ReservedInputCreatedBy:        input.String(job.CreatedBy),
+		config.ReservedInputQueueWaitSeconds: input.String(strconv.FormatInt(int64(now.Sub(job.CreatedAt).Seconds()), 10)),
+	}
+
+	var inputs input.Map
+
+	for name, value := range reserved {
+		if !declared[name] {
+			continue
+		}
+
+		if inputs == nil {
+			inputs = make(input.Map, len(job.Inputs)+len(reserved))
+			maps.Copy(inputs, job.Inputs)
+		}
+
+		inputs[name] = value
+	}
+
+	if inputs == nil {
+		return job.Inputs
+	}
+
+	return inputs
+}
+
+// declaredInputs returns the inputs the workflow declares on ref, from the
+// cache when possible. Errors are logged and yield no inputs, uncached.
+func (d *dispatcher) declaredInputs(ctx context.Context, owner, repo, workflowID, ref string) map[string]bool {
+	key := strings.Join([]string{owner, repo, workflowID, ref}, "/")
+	now := time.Now()
+
+	d.workflowInputsMu.Lock()
+	entry, ok := d.workflowInputs[key]
+	d.workflowInputsMu.Unlock()
+
+	if ok && now.Before(entry.expiresAt) {
+		return entry.declared
+	}
+
+	log := d.log.WithFields(logrus.Fields{
+		"owner":    owner,
+		"repo":     repo,
+		"workflow": workflowID,
+		"ref":      ref,
+	})
+
+	file, err := d.ghClient.GetWorkflowFile(ctx, owner, repo, workflowID, ref)
+	if err != nil {
+		log.WithError(err).Warn("Failed to fetch workflow file, dispatching without reserved inputs")
+
+		return nil
+	}
+
+	var declared map[string]bool
+
+	if file != nil {
+		declared, _, err = lint.DeclaredInputs(file.Content)
+		if err != nil {
+			log.WithError(err).Warn("Failed to parse workflow file, dispatching without reserved inputs")
+		}
+	}
+
+	d.workflowInputsMu.Lock()
+	d.workflowInputs[key] = workflowInputsEntry{declared: declared, expiresAt: now.Add(workflowInputsTTL)}
+	d.workflowInputsMu.Unlock()
+
+	return declared
+}
@@ -0,0 +1,133 @@
+package dispatcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// runBatchPerPage is the page size of bulk run listings.
+	runBatchPerPage = 100
+	// runBatchMaxPages bounds the pages listed per repository each tracking
+	// cycle. Runs not found within them are fetched one by one.
+	runBatchMaxPages = 3
+)
+
+// trackedRepo is a repository with runs to fetch in bulk.
+type trackedRepo struct {
+	owner, repo string
+	runIDs      map[int64]struct{}
+	// since is the creation time from which all of the runs are listed.
+	since time.Time
+}
+
+// fetchTrackedRuns lists the runs of tracked jobs in bulk, one listing per
+// repository that has at least two of them, instead of one GetWorkflowRun per
+// job. It returns the runs found by ID; jobs whose run is missing, e.g. after
+// a failed listing, are left to trackJob to fetch on their own.
+func (d *dispatcher) fetchTrackedRuns(ctx context.Context, jobs []*store.Job) map[int64]*github.WorkflowRun {
+	repos := make(map[string]*trackedRepo)
+	templates := make(map[string]*store.JobTemplate)
+
+	for _, job := range jobs {
+		if job.RunID == nil || *job.RunID == 0 || job.TriggeredAt == nil {
+			continue
+		}
+
+		var template *store.JobTemplate
+
+		if job.TemplateID != "" {
+			var ok bool
+
+			template, ok = templates[job.TemplateID]
+			if !ok {
+				var err error
+
+				template, err = d.store.GetJobTemplate(ctx, job.TemplateID)
+				if err != nil {
+					d.log.WithError(err).WithField("template_id", job.TemplateID).
+						Warn("Failed to get job template for bulk run listing")
+				}
+
+				templates[job.TemplateID] = template
+			}
+
+			if template == nil {
+				continue
+			}
+		}
+
+		owner, repo, _, _ := getEffectiveWorkflowParams(job, template)
+		key := owner + "/" + repo
+
+		tracked, ok := repos[key]
+		if !ok {
+			tracked = &trackedRepo{owner: owner, repo: repo, runIDs: make(map[int64]struct{}), since: *job.TriggeredAt}
+			repos[key] = tracked
+		}
+
+		tracked.runIDs[*job.RunID] = struct{}{}
+
+		if job.TriggeredAt.Before(tracked.since) {
+			tracked.since = *job.TriggeredAt
+		}
+	}
+
+	runs := make(map[int64]*github.WorkflowRun)
+
+	for _, tracked := range repos {
+		if len(tracked.runIDs) < 2 {
+			continue
+		}
+
+		d.listTrackedRuns(ctx, tracked, runs)
+	}
+
+	return runs
+}
+
+// listTrackedRuns pages through the repository's runs created since the
+// earliest of its jobs was triggered, until all of its runs are found.
+func (d *dispatcher) listTrackedRuns(ctx context.Context, tracked *trackedRepo, runs map[int64]*github.WorkflowRun) {
+	log := d.log.WithFields(logrus.Fields{
+		"owner": tracked.owner,
+		"repo":  tracked.repo,
+	})
+
+	// Allow for the same clock drift as findWorkflowRun.
+	since := tracked.since.Add(-30 * time.Second)
+	remaining := len(tracked.runIDs)
+
+	for page := 1; page <= runBatchMaxPages && remaining > 0; page++ {
+		listed, err := d.ghClient.ListRepoWorkflowRuns(ctx, tracked.owner, tracked.repo, github.ListWorkflowRunsOpts{
+			CreatedAt: &since,
+			PerPage:   runBatchPerPage,
+			Page:      page,
+		})
+		if err != nil {
+			log.WithError(err).Warn("Failed to list workflow runs in bulk, fetching runs one by one")
+
+			return
+		}
+
+		for _, run := range listed {
+			if _, ok := tracked.runIDs[run.ID]; ok {
+				runs[run.ID] = run
+				remaining--
+			}
+		}
+
+		if len(listed) < runBatchPerPage {
+			break
+		}
+	}
+
+	log.WithFields(logrus.Fields{
+		"runs":    len(tracked.runIDs),
+		"missing": remaining,
+	}).Debug("Listed tracked workflow runs in bulk")
+}
@@ -0,0 +1,60 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// HandleWorkflowRun applies a workflow run delivered by a webhook to the job
+// tracking it, without fetching the run from GitHub. Runs that no job has been
+// matched to yet are left to the tracking loop, which matches them.
+func (d *dispatcher) HandleWorkflowRun(ctx context.Context, run *github.WorkflowRun) error {
+	d.trackMu.Lock()
+	defer d.trackMu.Unlock()
+
+	job, err := d.trackedJobByRunID(ctx, run.ID)
+	if err != nil || job == nil {
+		return err
+	}
+
+	return d.trackJob(ctx, job, nil, map[int64]*github.WorkflowRun{run.ID: run})
+}
+
+// HandleWorkflowJobStarted marks the triggered job tracking a run as running
+// on the runner that picked up one of the run's workflow jobs, sparing the
+// tracking loop the lookup of the run's jobs.
+func (d *dispatcher) HandleWorkflowJobStarted(ctx context.Context, runID, runnerID int64, runnerName string) error {
+	d.trackMu.Lock()
+	defer d.trackMu.Unlock()
+
+	job, err := d.trackedJobByRunID(ctx, runID)
+	if err != nil || job == nil || job.Status != store.JobStatusTriggered {
+		return err
+	}
+
+	log := d.log.WithFields(logrus.Fields{
+		"job_id": job.ID,
+		"run_id": runID,
+	})
+
+	return d.markRunning(ctx, log, job, runnerID, runnerName)
+}
+
+// trackedJobByRunID returns the triggered or running job a run was matched
+// to, or nil.
+func (d *dispatcher) trackedJobByRunID(ctx context.Context, runID int64) (*store.Job, error) {
+	job, err := d.store.GetJobByRunID(ctx, runID)
+	if err != nil {
+		return nil, fmt.Errorf("getting job for run: %w", err)
+	}
+
+	if job == nil || (job.Status != store.JobStatusTriggered && job.Status != store.JobStatusRunning) {
+		return nil, nil
+	}
+
+	return job, nil
+}
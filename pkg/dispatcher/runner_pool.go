@@ -0,0 +1,57 @@
+package dispatcher
+
+import (
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// countOnlineRunners returns how many of runners are online, busy or not.
+func countOnlineRunners(runners []*store.Runner) int {
+	var online int
+
+	for _, runner := range runners {
+		if runner.Status == store.RunnerStatusOnline {
+			online++
+		}
+	}
+
+	return online
+}
+
+// RunnerPoolShort reports whether fewer of a group's runners are online than
+// its min_online_runners, in which case dispatch to the group is held.
+func RunnerPoolShort(group *store.Group, online int) bool {
+	return group.MinOnlineRunners > 0 && online < group.MinOnlineRunners
+}
+
+// checkRunnerPool reports whether the group has enough online runners to
+// dispatch to, logging when its pool falls below min_online_runners and when
+// it recovers.
+func (d *dispatcher) checkRunnerPool(log logrus.FieldLogger, group *store.Group, runners []*store.Runner) bool {
+	online := countOnlineRunners(runners)
+	short := RunnerPoolShort(group, online)
+
+	d.shortRunnerPoolsMu.Lock()
+	defer d.shortRunnerPoolsMu.Unlock()
+
+	if short != d.shortRunnerPools[group.ID] {
+		log = log.WithFields(logrus.Fields{
+			"online_runners":     online,
+			"min_online_runners": group.MinOnlineRunners,
+		})
+
+		if short {
+			log.Warn("Too few runners online, holding dispatch for group")
+		} else {
+			log.Info("Enough runners online again, resuming dispatch for group")
+		}
+	}
+
+	if short {
+		d.shortRunnerPools[group.ID] = true
+	} else {
+		delete(d.shortRunnerPools, group.ID)
+	}
+
+	return !short
+}
@@ -0,0 +1,132 @@
+package dispatcher
+
+import (
+	"sort"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+)
+
+// dispatchCycle tracks runner allocation within a single dispatch cycle.
+type dispatchCycle struct {
+	// claimed holds runners handed a job this cycle. The runner poller has not
+	// seen them go busy yet, so other groups must not pick them again.
+	claimed map[int64]struct{}
+	// backlogged holds groups that had a pending job this cycle.
+	backlogged map[string]bool
+	// dispatched holds groups that dispatched a job this cycle.
+	dispatched map[string]bool
+	// groups and jobs count the groups and pending jobs considered this cycle.
+	groups int
+	jobs   int
+}
+
+// merge adds the allocations of other, a cycle over different groups and
+// runners, to c.
+func (c *dispatchCycle) merge(other *dispatchCycle) {
+	for runnerID := range other.claimed {
+		c.claimed[runnerID] = struct{}{}
+	}
+
+	for groupID := range other.backlogged {
+		c.backlogged[groupID] = true
+	}
+
+	for groupID := range other.dispatched {
+		c.dispatched[groupID] = true
+	}
+
+	c.groups += other.groups
+	c.jobs += other.jobs
+}
+
+func newDispatchCycle() *dispatchCycle {
+	return &dispatchCycle{
+		claimed:    make(map[int64]struct{}),
+		backlogged: make(map[string]bool),
+		dispatched: make(map[string]bool),
+	}
+}
+
+// groupScheduler decides the order in which groups are offered idle runners
+// each cycle. Groups earlier in the order win runners shared with later groups.
+type groupScheduler struct {
+	cfg config.RunnerSharingConfig
+
+	// offset rotates the group order in round robin mode.
+	offset int
+	// vtime is each group's dispatch count divided by its weight in weighted
+	// mode. Groups with the lowest virtual time go first.
+	vtime map[string]float64
+}
+
+func newGroupScheduler(cfg config.RunnerSharingConfig) *groupScheduler {
+	return &groupScheduler{
+		cfg:   cfg,
+		vtime: make(map[string]float64),
+	}
+}
+
+// weight returns the configured weight for a group.
+func (s *groupScheduler) weight(groupID string) int {
+	if w, ok := s.cfg.Weights[groupID]; ok && w > 0 {
+		return w
+	}
+
+	return 1
+}
+
+// order returns the groups in the order they should be dispatched this cycle.
+func (s *groupScheduler) order(groups []*store.Group) []*store.Group {
+	ordered := make([]*store.Group, len(groups))
+	copy(ordered, groups)
+
+	switch s.cfg.Mode {
+	case config.RunnerSharingRoundRobin:
+		if len(ordered) == 0 {
+			return ordered
+		}
+
+		start := s.offset % len(ordered)
+		ordered = append(ordered[start:], ordered[:start]...)
+		s.offset++
+	case config.RunnerSharingWeighted:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return s.vtime[ordered[i].ID] < s.vtime[ordered[j].ID]
+		})
+	}
+
+	return ordered
+}
+
+// record updates the weighted mode state after a cycle.
+func (s *groupScheduler) record(groups []*store.Group, cycle *dispatchCycle) {
+	if s.cfg.Mode != config.RunnerSharingWeighted {
+		return
+	}
+
+	for groupID := range cycle.dispatched {
+		s.vtime[groupID] += 1 / float64(s.weight(groupID))
+	}
+
+	// Groups without pending work catch up to the least served backlogged
+	// group, so an idle group cannot build up credit and starve the others
+	// once it has jobs again.
+	floor, found := 0.0, false
+
+	for groupID := range cycle.backlogged {
+		if vt := s.vtime[groupID]; !found || vt < floor {
+			floor, found = vt, true
+		}
+	}
+
+	if !found {
+		return
+	}
+
+	for _, group := range groups {
+		if !cycle.backlogged[group.ID] && s.vtime[group.ID] < floor {
+			s.vtime[group.ID] = floor
+		}
+	}
+}
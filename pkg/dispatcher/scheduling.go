@@ -0,0 +1,71 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// durationHistoryWindow is how far back completed jobs are considered when
+// estimating template durations for shortest-job-first scheduling.
+const durationHistoryWindow = 30 * 24 * time.Hour
+
+// dispatchCandidate is a pending job that may be dispatched this cycle.
+type dispatchCandidate struct {
+	job      *store.Job
+	template *store.JobTemplate // nil for manual jobs
+}
+
+// shortestCandidate returns the candidate whose template has the shortest mean
+// duration over durationHistoryWindow. Manual jobs and templates without
+// completed history have no estimate and are preferred, so a new template runs
+// once to learn its duration instead of waiting behind every known job. Ties go
+// to the earlier queue position; candidates must be in queue order.
+func (d *dispatcher) shortestCandidate(
+	ctx context.Context,
+	groupID string,
+	now time.Time,
+	candidates []dispatchCandidate,
+) (dispatchCandidate, error) {
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	durations, err := d.store.GetTemplateDurations(ctx, groupID, now.Add(-durationHistoryWindow))
+	if err != nil {
+		return dispatchCandidate{}, fmt.Errorf("getting template durations: %w", err)
+	}
+
+	best := 0
+	bestDuration := candidateDuration(candidates[0], durations)
+
+	for i := 1; i < len(candidates); i++ {
+		if duration := candidateDuration(candidates[i], durations); duration < bestDuration {
+			best, bestDuration = i, duration
+		}
+	}
+
+	if best > 0 {
+		d.log.WithFields(logrus.Fields{
+			"group":             groupID,
+			"job_id":            candidates[best].job.ID,
+			"expected_duration": bestDuration,
+			"skipped_jobs":      best,
+		}).Debug("Shortest job first: dispatching job ahead of its queue position")
+	}
+
+	return candidates[best], nil
+}
+
+// candidateDuration returns the expected duration of a candidate, or zero when
+// it has no history.
+func candidateDuration(c dispatchCandidate, durations map[string]time.Duration) time.Duration {
+	if c.template == nil {
+		return 0
+	}
+
+	return durations[c.template.ID]
+}
@@ -0,0 +1,138 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// handoffSecretPrefix starts the names of the secrets created for hand-off.
+const handoffSecretPrefix = "DISPATCHOOR_"
+
+// handOffSecrets writes the values of the template's secret_handoff inputs to
+// GitHub Actions secrets and returns inputs with each value replaced by its
+// secret's name. The secrets are recorded on the job before they are written,
+// so they are deleted even if writing stops half-way.
+func (d *dispatcher) handOffSecrets(
+	ctx context.Context,
+	log logrus.FieldLogger,
+	job *store.Job,
+	template *store.JobTemplate,
+	owner, repo string,
+	inputs input.Map,
+) (input.Map, error) {
+	if template == nil || len(template.SecretHandoffInputs) == 0 {
+		return inputs, nil
+	}
+
+	secrets := &store.HandoffSecrets{Owner: owner, Repo: repo}
+	if template.SecretHandoffEnvironment {
+		secrets.Environment = template.Environment
+	}
+
+	handed := maps.Clone(inputs)
+	values := make(map[string]string, len(template.SecretHandoffInputs))
+
+	for _, key := range template.SecretHandoffInputs {
+		value, ok := inputs[key]
+		if !ok || value.String() == "" {
+			continue
+		}
+
+		name := handoffSecretName(job.ID, key)
+		secrets.Names = append(secrets.Names, name)
+		values[name] = value.String()
+		handed[key] = input.String(name)
+	}
+
+	if len(secrets.Names) == 0 {
+		return inputs, nil
+	}
+
+	if err := d.store.SetJobHandoffSecrets(ctx, job.ID, secrets); err != nil {
+		return nil, fmt.Errorf("recording hand-off secrets: %w", err)
+	}
+
+	job.HandoffSecrets = secrets
+
+	for _, name := range secrets.Names {
+		if err := d.ghClient.PutActionsSecret(ctx, owner, repo, secrets.Environment, name, values[name]); err != nil {
+			return nil, fmt.Errorf("writing hand-off secret: %w", err)
+		}
+	}
+
+	log.WithField("secrets", len(secrets.Names)).Debug("Handed off secret inputs")
+
+	return handed, nil
+}
+
+// handoffSecretName returns the name of the secret holding a job's input:
+// the prefix, the job ID and the input, upper-cased with anything but letters
+// and digits replaced by underscores, as GitHub requires.
+func handoffSecretName(jobID, key string) string {
+	sanitize := func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}
+
+	return handoffSecretPrefix + strings.Map(sanitize, strings.ReplaceAll(jobID, "-", "")) + "_" + strings.Map(sanitize, key)
+}
+
+// cleanupHandoffSecrets deletes the hand-off secrets of jobs that are no
+// longer pending, triggered or running. Secrets that fail to delete are
+// retried on the next call.
+func (d *dispatcher) cleanupHandoffSecrets(ctx context.Context) {
+	jobs, err := d.store.ListJobsWithHandoffSecrets(ctx)
+	if err != nil {
+		d.log.WithError(err).Warn("Failed to list jobs with hand-off secrets")
+
+		return
+	}
+
+	for _, job := range jobs {
+		switch job.Status {
+		case store.JobStatusPending, store.JobStatusTriggered, store.JobStatusRunning:
+			continue
+		}
+
+		d.deleteHandoffSecrets(ctx, d.log.WithField("job_id", job.ID), job)
+	}
+}
+
+// deleteHandoffSecrets deletes a job's hand-off secrets and clears their
+// record once all of them are gone.
+func (d *dispatcher) deleteHandoffSecrets(ctx context.Context, log logrus.FieldLogger, job *store.Job) {
+	secrets := job.HandoffSecrets
+	if secrets == nil {
+		return
+	}
+
+	for _, name := range secrets.Names {
+		if err := d.ghClient.DeleteActionsSecret(ctx, secrets.Owner, secrets.Repo, secrets.Environment, name); err != nil {
+			log.WithError(err).WithField("secret", name).Warn("Failed to delete hand-off secret, will retry")
+
+			return
+		}
+	}
+
+	if err := d.store.SetJobHandoffSecrets(ctx, job.ID, nil); err != nil {
+		log.WithError(err).Warn("Failed to clear hand-off secrets")
+
+		return
+	}
+
+	job.HandoffSecrets = nil
+
+	log.WithField("secrets", len(secrets.Names)).Info("Deleted hand-off secrets")
+}
@@ -0,0 +1,84 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// checkStalled flags a running job as stalled when its template sets a stall
+// timeout and neither a heartbeat from its workflow nor its GitHub run changed
+// for that long. The flag is raised once per stall and cleared when the run
+// changes again; heartbeats clear it through the API.
+func (d *dispatcher) checkStalled(
+	ctx context.Context,
+	log logrus.FieldLogger,
+	job *store.Job,
+	template *store.JobTemplate,
+	run *github.WorkflowRun,
+) {
+	if template == nil || template.StallTimeoutSeconds <= 0 {
+		return
+	}
+
+	lastActivity := run.UpdatedAt
+
+	for _, at := range []*time.Time{job.TriggeredAt, job.HeartbeatAt} {
+		if at != nil && at.After(lastActivity) {
+			lastActivity = *at
+		}
+	}
+
+	now := time.Now()
+	idle := now.Sub(lastActivity)
+	timeout := time.Duration(template.StallTimeoutSeconds) * time.Second
+
+	if idle < timeout {
+		if job.StalledAt != nil {
+			if err := d.store.SetJobStalled(ctx, job.ID, nil); err != nil {
+				log.WithError(err).Warn("Failed to clear stalled flag")
+			}
+		}
+
+		return
+	}
+
+	if job.StalledAt != nil {
+		return
+	}
+
+	if err := d.store.SetJobStalled(ctx, job.ID, &now); err != nil {
+		log.WithError(err).Error("Failed to flag job as stalled")
+
+		return
+	}
+
+	job.StalledAt = &now
+	idle = idle.Truncate(time.Second)
+
+	log.WithFields(logrus.Fields{
+		"idle":    idle,
+		"timeout": timeout,
+	}).Warn("Running job has stalled")
+
+	if err := d.store.CreateAuditEntry(ctx, &store.AuditEntry{
+		ID:         uuid.New().String(),
+		Action:     store.AuditActionJobStalled,
+		EntityType: store.AuditEntityJob,
+		EntityID:   job.ID,
+		Actor:      "dispatcher",
+		Details:    fmt.Sprintf("No heartbeat or run update for %s", idle),
+		CreatedAt:  now,
+	}); err != nil {
+		log.WithError(err).Warn("Failed to create audit entry for stalled job")
+	}
+
+	if d.jobStalledCallback != nil {
+		d.jobStalledCallback(job, idle)
+	}
+}
@@ -0,0 +1,93 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/schedule"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+)
+
+// nextDispatchableJob returns the first unpaused pending job in the group whose
+// template is inside one of its dispatch windows, along with the template (nil
+// for manual jobs). Jobs outside their window keep their position, so later
+// jobs of other templates can still be dispatched. Groups using the
+// shortest_job_first policy get the dispatchable job expected to finish soonest.
+func (d *dispatcher) nextDispatchableJob(
+	ctx context.Context,
+	group *store.Group,
+	now time.Time,
+	cycle *dispatchCycle,
+) (*store.Job, *store.JobTemplate, error) {
+	jobs, err := d.queue.ListPending(ctx, group.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing pending jobs: %w", err)
+	}
+
+	shortestFirst := group.SchedulingPolicy == config.SchedulingPolicyShortestJobFirst
+	templates := make(map[string]*store.JobTemplate)
+
+	var candidates []dispatchCandidate
+
+	for _, job := range jobs {
+		if job.Paused {
+			continue
+		}
+
+		cycle.jobs++
+
+		if !job.Due(now) {
+			continue
+		}
+
+		if job.TemplateID == "" {
+			if !shortestFirst {
+				return job, nil, nil
+			}
+
+			candidates = append(candidates, dispatchCandidate{job: job})
+
+			continue
+		}
+
+		template, ok := templates[job.TemplateID]
+		if !ok {
+			template, err = d.store.GetJobTemplate(ctx, job.TemplateID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("getting job template: %w", err)
+			}
+
+			if template == nil {
+				return nil, nil, fmt.Errorf("template not found: %s", job.TemplateID)
+			}
+
+			templates[job.TemplateID] = template
+		}
+
+		if !schedule.Open(template.DispatchWindows, now) {
+			d.log.WithField("job_id", job.ID).WithField("template", template.ID).
+				Debug("Template is outside its dispatch window, skipping job")
+
+			continue
+		}
+
+		if !shortestFirst {
+			return job, template, nil
+		}
+
+		candidates = append(candidates, dispatchCandidate{job: job, template: template})
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil, nil
+	}
+
+	next, err := d.shortestCandidate(ctx, group.ID, now, candidates)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return next.job, next.template, nil
+}
@@ -0,0 +1,189 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// defaultAPIURL is the GitHub API used without WithBaseURL.
+	defaultAPIURL = "https://api.github.com/"
+	// appJWTLifetime is how long an app JWT is valid. GitHub allows at most
+	// 10 minutes.
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockSkew backdates an app JWT's issue time, as GitHub
+	// recommends, so a clock running ahead of GitHub's is not rejected.
+	appJWTClockSkew = time.Minute
+	// appTokenRefreshWindow is how long before it expires an installation
+	// token is renewed. Installation tokens last an hour.
+	appTokenRefreshWindow = 5 * time.Minute
+)
+
+// WithAppAuth authenticates the client as an installation of a GitHub App,
+// minting and renewing installation tokens itself. The token given to
+// NewClient is ignored.
+func WithAppAuth(cfg config.GitHubAppConfig) ClientOption {
+	return func(c *client) {
+		c.app = &cfg
+	}
+}
+
+// tokenSource returns the source of the client's tokens: its static token,
+// or installation tokens of its app minted through httpClient.
+func (c *client) tokenSource(httpClient *http.Client) (oauth2.TokenSource, error) {
+	if c.app == nil {
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: c.token}), nil
+	}
+
+	key, err := loadAppPrivateKey(c.app.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := defaultAPIURL
+	if c.baseURL != "" {
+		baseURL = strings.TrimSuffix(c.baseURL, "/") + "/"
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"app_id":          c.app.AppID,
+		"installation_id": c.app.InstallationID,
+	}).Info("Authenticating as GitHub App installation")
+
+	return oauth2.ReuseTokenSourceWithExpiry(nil, &appTokenSource{
+		client:         httpClient,
+		baseURL:        baseURL,
+		appID:          c.app.AppID,
+		installationID: c.app.InstallationID,
+		key:            key,
+	}, appTokenRefreshWindow), nil
+}
+
+// loadAppPrivateKey reads a PEM-encoded RSA private key, in the PKCS #1 form
+// GitHub issues or as PKCS #8.
+func loadAppPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading GitHub App private key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key %s is not PEM-encoded", path)
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing GitHub App private key: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key %s is not an RSA key", path)
+	}
+
+	return key, nil
+}
+
+// appTokenSource mints installation access tokens for a GitHub App. It is
+// wrapped in a reusing source, so a token is only minted when the last one
+// nears expiry.
+type appTokenSource struct {
+	client         *http.Client
+	baseURL        string
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+}
+
+// Token implements oauth2.TokenSource.
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := s.jwt(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%sapp/installations/%d/access_tokens", s.baseURL, s.installationID)
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating installation token request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting installation token: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return nil, fmt.Errorf("requesting installation token: unexpected status %d: %s",
+			resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("decoding installation token: %w", err)
+	}
+
+	return &oauth2.Token{AccessToken: token.Token, Expiry: token.ExpiresAt}, nil
+}
+
+// jwt returns an RS256 JSON Web Token identifying the app, as GitHub requires
+// to mint installation tokens.
+func (s *appTokenSource) jwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("encoding JWT header: %w", err)
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(s.appID, 10),
+	})
+	if err != nil {
+		return "", fmt.Errorf("encoding JWT claims: %w", err)
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("signing JWT: %w", err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
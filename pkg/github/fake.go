@@ -0,0 +1,831 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+)
+
+// ErrFakeNotFound is returned by FakeClient for runs and refs it does not know.
+var ErrFakeNotFound = errors.New("not found")
+
+// fakeRunIDStart is the ID of the first workflow run created by a FakeClient.
+const fakeRunIDStart = 1000
+
+// Dispatch is a workflow_dispatch event received by a FakeClient.
+type Dispatch struct {
+	Owner      string
+	Repo       string
+	WorkflowID string
+	Ref        string
+	Inputs     input.Map
+	// RunID is the workflow run created for the dispatch.
+	RunID int64
+}
+
+// IssueComment is an issue or pull request comment created by a FakeClient.
+type IssueComment struct {
+	Owner  string
+	Repo   string
+	Number int
+	Body   string
+}
+
+// fakeRun is a workflow run held by a FakeClient.
+type fakeRun struct {
+	owner      string
+	repo       string
+	workflowID string
+	run        WorkflowRun
+	jobs       []*WorkflowJob
+}
+
+// FakeClient is an in-memory Client for tests. Every dispatch creates a queued
+// workflow run, which tests move along with StartRun and CompleteRun. Runners
+// set with SetRunners are returned for every organization and repository.
+type FakeClient struct {
+	mu              sync.Mutex
+	connected       bool
+	connectionError string
+	rateReset       time.Time
+	runners         []*Runner
+	hostedRunners   []*HostedRunner
+	runs            []*fakeRun
+	nextRunID       int64
+	nextJobID       int64
+	dispatches      []Dispatch
+	comments        []IssueComment
+	closedIssues    []IssueComment
+	errors          map[string]error
+	refs            map[string]string
+	environments    map[string]*Environment
+	permissions     map[string]*RepoPermissions
+	workflowFiles   map[string][]byte
+	annotations     map[int64][]*Annotation
+	secrets         map[string]string
+	dispatchHook    func(owner, repo, workflowID string)
+	calls           map[string]int
+}
+
+// Ensure FakeClient implements Client.
+var _ Client = (*FakeClient)(nil)
+
+// NewFakeClient creates a connected fake GitHub client with no runners or runs.
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		connected:     true,
+		rateReset:     time.Now().Add(time.Hour),
+		nextRunID:     fakeRunIDStart,
+		nextJobID:     fakeRunIDStart,
+		errors:        make(map[string]error),
+		refs:          make(map[string]string),
+		environments:  make(map[string]*Environment),
+		permissions:   make(map[string]*RepoPermissions),
+		workflowFiles: make(map[string][]byte),
+		annotations:   make(map[int64][]*Annotation),
+		secrets:       make(map[string]string),
+		calls:         make(map[string]int),
+	}
+}
+
+// SetConnected sets the connection status reported by the client.
+func (f *FakeClient) SetConnected(connected bool, connectionError string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.connected = connected
+	f.connectionError = connectionError
+}
+
+// SetError makes every call to the named Client method (e.g.
+// "TriggerWorkflowDispatch") fail with err. A nil err clears it.
+func (f *FakeClient) SetError(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		delete(f.errors, method)
+
+		return
+	}
+
+	f.errors[method] = err
+}
+
+// SetDispatchHook sets a function called at the start of every
+// TriggerWorkflowDispatch, without the fake's lock held, e.g. to hold up
+// dispatches to one repository. A nil hook clears it.
+func (f *FakeClient) SetDispatchHook(hook func(owner, repo, workflowID string)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.dispatchHook = hook
+}
+
+// SetRunners replaces the runners returned by ListOrgRunners and ListRepoRunners.
+func (f *FakeClient) SetRunners(runners ...*Runner) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.runners = make([]*Runner, 0, len(runners))
+	for _, r := range runners {
+		f.runners = append(f.runners, copyRunner(r))
+	}
+}
+
+// SetHostedRunners replaces the hosted runners returned by
+// ListOrgHostedRunners.
+func (f *FakeClient) SetHostedRunners(runners ...*HostedRunner) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.hostedRunners = make([]*HostedRunner, 0, len(runners))
+	for _, r := range runners {
+		runner := *r
+		f.hostedRunners = append(f.hostedRunners, &runner)
+	}
+}
+
+// SetRef makes ResolveRef return sha for ref in a repository.
+func (f *FakeClient) SetRef(owner, repo, ref, sha string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.refs[repoKey(owner, repo)+"@"+ref] = sha
+}
+
+// SetEnvironment sets the deployment environment returned by GetEnvironment.
+func (f *FakeClient) SetEnvironment(owner, repo string, env *Environment) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.environments[repoKey(owner, repo)+"/"+env.Name] = env
+}
+
+// SetRepoPermissions sets the permissions returned by GetRepoPermissions.
+// Repositories without permissions set report push and pull access.
+func (f *FakeClient) SetRepoPermissions(owner, repo string, perms *RepoPermissions) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.permissions[repoKey(owner, repo)] = perms
+}
+
+// SetWorkflowFile sets the workflow definition returned by GetWorkflowFile for
+// every ref.
+func (f *FakeClient) SetWorkflowFile(owner, repo, workflowID string, content []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.workflowFiles[repoKey(owner, repo)+"/"+workflowID] = content
+}
+
+// SetAnnotations sets the annotations of a workflow job's check run.
+func (f *FakeClient) SetAnnotations(workflowJobID int64, annotations ...*Annotation) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.annotations[workflowJobID] = annotations
+}
+
+// Calls returns how many times the named Client method has been called. Only
+// methods that SetError can fail are counted.
+func (f *FakeClient) Calls(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[method]
+}
+
+// Dispatches returns the workflow dispatches received so far, oldest first.
+func (f *FakeClient) Dispatches() []Dispatch {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Dispatch(nil), f.dispatches...)
+}
+
+// Comments returns the issue comments created so far, oldest first.
+func (f *FakeClient) Comments() []IssueComment {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]IssueComment(nil), f.comments...)
+}
+
+// ClosedIssues returns the issues closed so far, oldest first, without a body.
+func (f *FakeClient) ClosedIssues() []IssueComment {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]IssueComment(nil), f.closedIssues...)
+}
+
+// Secrets returns the Actions secrets that exist, by FakeSecretKey.
+func (f *FakeClient) Secrets() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return maps.Clone(f.secrets)
+}
+
+// FakeSecretKey is the key of a secret in FakeClient.Secrets: owner/repo/name
+// for repository secrets and owner/repo/environment/name for environment ones.
+func FakeSecretKey(owner, repo, environment, name string) string {
+	if environment == "" {
+		return owner + "/" + repo + "/" + name
+	}
+
+	return owner + "/" + repo + "/" + environment + "/" + name
+}
+
+// Run returns a copy of a workflow run, or nil if it does not exist.
+func (f *FakeClient) Run(runID int64) *WorkflowRun {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r := f.findRun(runID)
+	if r == nil {
+		return nil
+	}
+
+	run := r.run
+
+	return &run
+}
+
+// StartRun moves a queued run to in_progress with one workflow job on the
+// given runner and returns that job's ID.
+func (f *FakeClient) StartRun(runID, runnerID int64, runnerName string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r := f.findRun(runID)
+	if r == nil {
+		return 0, fmt.Errorf("run %d: %w", runID, ErrFakeNotFound)
+	}
+
+	if r.run.Status != "queued" {
+		return 0, fmt.Errorf("run %d is %s, not queued", runID, r.run.Status)
+	}
+
+	f.nextJobID++
+
+	now := time.Now()
+	job := &WorkflowJob{
+		ID:         f.nextJobID,
+		Name:       r.workflowID,
+		Status:     "in_progress",
+		RunnerID:   runnerID,
+		RunnerName: runnerName,
+		StartedAt:  now,
+	}
+
+	r.jobs = append(r.jobs, job)
+	r.run.Status = "in_progress"
+	r.run.UpdatedAt = now
+
+	return job.ID, nil
+}
+
+// CompleteRun completes a run and its jobs with the given conclusion, such as
+// "success", "failure" or "cancelled".
+func (f *FakeClient) CompleteRun(runID int64, conclusion string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r := f.findRun(runID)
+	if r == nil {
+		return fmt.Errorf("run %d: %w", runID, ErrFakeNotFound)
+	}
+
+	r.complete(conclusion)
+
+	return nil
+}
+
+// RerunRun re-runs a completed run as GitHub's "Re-run jobs" does: the run
+// keeps its ID, its attempt number increases and it is queued again.
+func (f *FakeClient) RerunRun(runID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r := f.findRun(runID)
+	if r == nil {
+		return fmt.Errorf("run %d: %w", runID, ErrFakeNotFound)
+	}
+
+	if r.run.Status != "completed" {
+		return fmt.Errorf("run %d is %s, not completed", runID, r.run.Status)
+	}
+
+	r.run.RunAttempt++
+	r.run.Status = "queued"
+	r.run.Conclusion = ""
+	r.run.UpdatedAt = time.Now()
+	r.jobs = nil
+
+	return nil
+}
+
+// Start implements Client.
+func (f *FakeClient) Start(context.Context) error {
+	return f.failure("Start")
+}
+
+// Stop implements Client.
+func (f *FakeClient) Stop() error {
+	return nil
+}
+
+// IsConnected implements Client.
+func (f *FakeClient) IsConnected() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.connected
+}
+
+// ConnectionError implements Client.
+func (f *FakeClient) ConnectionError() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.connectionError
+}
+
+// ListOrgRunners implements Client.
+func (f *FakeClient) ListOrgRunners(context.Context, string) ([]*Runner, error) {
+	return f.listRunners("ListOrgRunners")
+}
+
+// ListRepoRunners implements Client.
+func (f *FakeClient) ListRepoRunners(context.Context, string, string) ([]*Runner, error) {
+	return f.listRunners("ListRepoRunners")
+}
+
+// ListOrgHostedRunners implements Client.
+func (f *FakeClient) ListOrgHostedRunners(context.Context, string) ([]*HostedRunner, error) {
+	if err := f.failure("ListOrgHostedRunners"); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	runners := make([]*HostedRunner, 0, len(f.hostedRunners))
+	for _, r := range f.hostedRunners {
+		runner := *r
+		runners = append(runners, &runner)
+	}
+
+	return runners, nil
+}
+
+// TriggerWorkflowDispatch implements Client by recording the dispatch and
+// creating a queued run for it.
+func (f *FakeClient) TriggerWorkflowDispatch(
+	_ context.Context,
+	owner, repo, workflowID, ref string,
+	inputs input.Map,
+) error {
+	f.mu.Lock()
+	hook := f.dispatchHook
+	f.mu.Unlock()
+
+	if hook != nil {
+		hook(owner, repo, workflowID)
+	}
+
+	if err := f.failure("TriggerWorkflowDispatch"); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextRunID++
+
+	now := time.Now()
+	run := &fakeRun{
+		owner:      owner,
+		repo:       repo,
+		workflowID: workflowID,
+		run: WorkflowRun{
+			ID:         f.nextRunID,
+			Name:       workflowID,
+			Status:     "queued",
+			HTMLURL:    fmt.Sprintf("https://github.com/%s/%s/actions/runs/%d", owner, repo, f.nextRunID),
+			HeadBranch: ref,
+			HeadSHA:    f.refs[repoKey(owner, repo)+"@"+ref],
+			RunAttempt: 1,
+			LogsURL: fmt.Sprintf("https://api.github.com/repos/%s/%s/actions/runs/%d/logs",
+				owner, repo, f.nextRunID),
+			ArtifactsURL: fmt.Sprintf("https://api.github.com/repos/%s/%s/actions/runs/%d/artifacts",
+				owner, repo, f.nextRunID),
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+
+	f.runs = append(f.runs, run)
+
+	copied := make(input.Map, len(inputs))
+	for k, v := range inputs {
+		copied[k] = v
+	}
+
+	f.dispatches = append(f.dispatches, Dispatch{
+		Owner:      owner,
+		Repo:       repo,
+		WorkflowID: workflowID,
+		Ref:        ref,
+		Inputs:     copied,
+		RunID:      run.run.ID,
+	})
+
+	return nil
+}
+
+// GetWorkflowRun implements Client.
+func (f *FakeClient) GetWorkflowRun(_ context.Context, owner, repo string, runID int64) (*WorkflowRun, error) {
+	if err := f.failure("GetWorkflowRun"); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r := f.findRun(runID)
+	if r == nil || !r.inRepo(owner, repo) {
+		return nil, fmt.Errorf("getting workflow run %d: %w", runID, ErrFakeNotFound)
+	}
+
+	run := r.run
+
+	return &run, nil
+}
+
+// ListWorkflowRuns implements Client. Runs are returned newest first; the
+// Event option is ignored since every fake run is a workflow_dispatch run.
+func (f *FakeClient) ListWorkflowRuns(
+	_ context.Context,
+	owner, repo, workflowID string,
+	opts ListWorkflowRunsOpts,
+) ([]*WorkflowRun, error) {
+	return f.listRuns("ListWorkflowRuns", owner, repo, workflowID, opts)
+}
+
+// ListRepoWorkflowRuns implements Client like ListWorkflowRuns, across all
+// workflows of the repository.
+func (f *FakeClient) ListRepoWorkflowRuns(
+	_ context.Context,
+	owner, repo string,
+	opts ListWorkflowRunsOpts,
+) ([]*WorkflowRun, error) {
+	return f.listRuns("ListRepoWorkflowRuns", owner, repo, "", opts)
+}
+
+// listRuns returns a page of the repository's runs, newest first, limited to
+// workflowID unless it is empty.
+func (f *FakeClient) listRuns(method, owner, repo, workflowID string, opts ListWorkflowRunsOpts) ([]*WorkflowRun, error) {
+	if err := f.failure(method); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	perPage := opts.PerPage
+	if perPage <= 0 {
+		perPage = 10
+	}
+
+	skip := 0
+	if opts.Page > 1 {
+		skip = (opts.Page - 1) * perPage
+	}
+
+	var result []*WorkflowRun
+
+	for i := len(f.runs) - 1; i >= 0 && len(result) < perPage; i-- {
+		r := f.runs[i]
+
+		if !r.inRepo(owner, repo) || (workflowID != "" && r.workflowID != workflowID) {
+			continue
+		}
+
+		if opts.Branch != "" && r.run.HeadBranch != opts.Branch {
+			continue
+		}
+
+		if opts.Status != "" && r.run.Status != opts.Status {
+			continue
+		}
+
+		if opts.CreatedAt != nil && r.run.CreatedAt.Before(*opts.CreatedAt) {
+			continue
+		}
+
+		if skip > 0 {
+			skip--
+
+			continue
+		}
+
+		run := r.run
+		result = append(result, &run)
+	}
+
+	return result, nil
+}
+
+// ListWorkflowRunJobs implements Client.
+func (f *FakeClient) ListWorkflowRunJobs(_ context.Context, owner, repo string, runID int64) ([]*WorkflowJob, error) {
+	if err := f.failure("ListWorkflowRunJobs"); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r := f.findRun(runID)
+	if r == nil || !r.inRepo(owner, repo) {
+		return nil, fmt.Errorf("listing workflow jobs of run %d: %w", runID, ErrFakeNotFound)
+	}
+
+	jobs := make([]*WorkflowJob, 0, len(r.jobs))
+	for _, j := range r.jobs {
+		job := *j
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, nil
+}
+
+// CancelWorkflowRun implements Client. Unlike GitHub, the run is cancelled at once.
+func (f *FakeClient) CancelWorkflowRun(_ context.Context, owner, repo string, runID int64) error {
+	if err := f.failure("CancelWorkflowRun"); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r := f.findRun(runID)
+	if r == nil || !r.inRepo(owner, repo) {
+		return fmt.Errorf("cancelling workflow run %d: %w", runID, ErrFakeNotFound)
+	}
+
+	if r.run.Status != "completed" {
+		r.complete("cancelled")
+	}
+
+	return nil
+}
+
+// GetEnvironment implements Client.
+func (f *FakeClient) GetEnvironment(_ context.Context, owner, repo, name string) (*Environment, error) {
+	if err := f.failure("GetEnvironment"); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	env, ok := f.environments[repoKey(owner, repo)+"/"+name]
+	if !ok {
+		return nil, nil
+	}
+
+	copied := *env
+	copied.BranchPolicies = append([]string(nil), env.BranchPolicies...)
+
+	return &copied, nil
+}
+
+// ResolveRef implements Client.
+func (f *FakeClient) ResolveRef(_ context.Context, owner, repo, ref string) (string, error) {
+	if err := f.failure("ResolveRef"); err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sha, ok := f.refs[repoKey(owner, repo)+"@"+ref]
+	if !ok {
+		return "", fmt.Errorf("resolving ref %q: %w: %w", ref, ErrRefNotFound, ErrFakeNotFound)
+	}
+
+	return sha, nil
+}
+
+// GetRepoPermissions implements Client.
+func (f *FakeClient) GetRepoPermissions(_ context.Context, owner, repo string) (*RepoPermissions, error) {
+	if err := f.failure("GetRepoPermissions"); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	perms, ok := f.permissions[repoKey(owner, repo)]
+	if !ok {
+		return &RepoPermissions{Push: true, Pull: true}, nil
+	}
+
+	if perms == nil {
+		return nil, nil
+	}
+
+	copied := *perms
+
+	return &copied, nil
+}
+
+// GetWorkflowFile implements Client.
+func (f *FakeClient) GetWorkflowFile(_ context.Context, owner, repo, workflowID, _ string) (*WorkflowFile, error) {
+	if err := f.failure("GetWorkflowFile"); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	content, ok := f.workflowFiles[repoKey(owner, repo)+"/"+workflowID]
+	if !ok {
+		return nil, nil
+	}
+
+	path := workflowID
+	if !strings.Contains(path, "/") {
+		path = ".github/workflows/" + workflowID
+	}
+
+	return &WorkflowFile{Path: path, Content: append([]byte(nil), content...)}, nil
+}
+
+// ListCheckRunAnnotations implements Client.
+func (f *FakeClient) ListCheckRunAnnotations(_ context.Context, _, _ string, checkRunID int64) ([]*Annotation, error) {
+	if err := f.failure("ListCheckRunAnnotations"); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	annotations := make([]*Annotation, 0, len(f.annotations[checkRunID]))
+	for _, a := range f.annotations[checkRunID] {
+		annotation := *a
+		annotations = append(annotations, &annotation)
+	}
+
+	return annotations, nil
+}
+
+// CreateIssueComment implements Client.
+func (f *FakeClient) CreateIssueComment(_ context.Context, owner, repo string, number int, body string) error {
+	if err := f.failure("CreateIssueComment"); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.comments = append(f.comments, IssueComment{Owner: owner, Repo: repo, Number: number, Body: body})
+
+	return nil
+}
+
+// CloseIssue implements Client.
+func (f *FakeClient) CloseIssue(_ context.Context, owner, repo string, number int) error {
+	if err := f.failure("CloseIssue"); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closedIssues = append(f.closedIssues, IssueComment{Owner: owner, Repo: repo, Number: number})
+
+	return nil
+}
+
+// PutActionsSecret implements Client.
+func (f *FakeClient) PutActionsSecret(_ context.Context, owner, repo, environment, name, value string) error {
+	if err := f.failure("PutActionsSecret"); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.secrets[FakeSecretKey(owner, repo, environment, name)] = value
+
+	return nil
+}
+
+// DeleteActionsSecret implements Client.
+func (f *FakeClient) DeleteActionsSecret(_ context.Context, owner, repo, environment, name string) error {
+	if err := f.failure("DeleteActionsSecret"); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.secrets, FakeSecretKey(owner, repo, environment, name))
+
+	return nil
+}
+
+// RateLimitRemaining implements Client. The fake is never rate limited.
+func (f *FakeClient) RateLimitRemaining() int {
+	return 5000
+}
+
+// RateLimitReset implements Client.
+func (f *FakeClient) RateLimitReset() time.Time {
+	return f.rateReset
+}
+
+// failure counts a call to a method and returns the error set for it with
+// SetError.
+func (f *FakeClient) failure(method string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls[method]++
+
+	return f.errors[method]
+}
+
+// listRunners returns copies of the fake's runners, sorted by ID.
+func (f *FakeClient) listRunners(method string) ([]*Runner, error) {
+	if err := f.failure(method); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	runners := make([]*Runner, 0, len(f.runners))
+	for _, r := range f.runners {
+		runners = append(runners, copyRunner(r))
+	}
+
+	sort.Slice(runners, func(i, j int) bool { return runners[i].ID < runners[j].ID })
+
+	return runners, nil
+}
+
+// findRun returns the run with the given ID. The caller must hold f.mu.
+func (f *FakeClient) findRun(runID int64) *fakeRun {
+	for _, r := range f.runs {
+		if r.run.ID == runID {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// inRepo reports whether the run belongs to the repository.
+func (r *fakeRun) inRepo(owner, repo string) bool {
+	return strings.EqualFold(r.owner, owner) && strings.EqualFold(r.repo, repo)
+}
+
+// complete finishes the run and its unfinished jobs.
+func (r *fakeRun) complete(conclusion string) {
+	r.run.Status = "completed"
+	r.run.Conclusion = conclusion
+	r.run.UpdatedAt = time.Now()
+
+	for _, job := range r.jobs {
+		if job.Status != "completed" {
+			job.Status = "completed"
+			job.Conclusion = conclusion
+		}
+	}
+}
+
+// repoKey returns the case-insensitive map key of a repository.
+func repoKey(owner, repo string) string {
+	return strings.ToLower(owner + "/" + repo)
+}
+
+// copyRunner returns a deep copy of a runner.
+func copyRunner(r *Runner) *Runner {
+	runner := *r
+	runner.Labels = append([]string(nil), r.Labels...)
+
+	return &runner
+}
@@ -2,16 +2,29 @@ package github
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/metrics"
 	"github.com/google/go-github/v60/github"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/oauth2"
+	"golang.org/x/crypto/nacl/box"
 )
 
+// ErrRefNotFound is returned by ResolveRef when the branch, tag or SHA does not
+// exist in the repository, or the repository is not visible to the token.
+var ErrRefNotFound = errors.New("ref not found")
+
 // Client defines the interface for GitHub API operations.
 type Client interface {
 	Start(ctx context.Context) error
@@ -24,18 +37,44 @@ type Client interface {
 	// Runners.
 	ListOrgRunners(ctx context.Context, org string) ([]*Runner, error)
 	ListRepoRunners(ctx context.Context, owner, repo string) ([]*Runner, error)
+	// ListOrgHostedRunners lists an organization's GitHub-hosted larger
+	// runners, each of which is a pool scaling up to its maximum runners.
+	ListOrgHostedRunners(ctx context.Context, org string) ([]*HostedRunner, error)
 
 	// Workflows.
 	TriggerWorkflowDispatch(
 		ctx context.Context,
 		owner, repo, workflowID, ref string,
-		inputs map[string]string,
+		inputs input.Map,
 	) error
 	GetWorkflowRun(ctx context.Context, owner, repo string, runID int64) (*WorkflowRun, error)
 	ListWorkflowRuns(ctx context.Context, owner, repo, workflowID string, opts ListWorkflowRunsOpts) ([]*WorkflowRun, error)
+	// ListRepoWorkflowRuns lists the runs of every workflow in a repository.
+	ListRepoWorkflowRuns(ctx context.Context, owner, repo string, opts ListWorkflowRunsOpts) ([]*WorkflowRun, error)
 	ListWorkflowRunJobs(ctx context.Context, owner, repo string, runID int64) ([]*WorkflowJob, error)
 	CancelWorkflowRun(ctx context.Context, owner, repo string, runID int64) error
 
+	// Environments.
+	GetEnvironment(ctx context.Context, owner, repo, name string) (*Environment, error)
+
+	// Commits.
+	ResolveRef(ctx context.Context, owner, repo, ref string) (string, error)
+
+	// Repositories.
+	GetRepoPermissions(ctx context.Context, owner, repo string) (*RepoPermissions, error)
+	GetWorkflowFile(ctx context.Context, owner, repo, workflowID, ref string) (*WorkflowFile, error)
+
+	// Check runs.
+	ListCheckRunAnnotations(ctx context.Context, owner, repo string, checkRunID int64) ([]*Annotation, error)
+
+	// Issues.
+	CreateIssueComment(ctx context.Context, owner, repo string, number int, body string) error
+	CloseIssue(ctx context.Context, owner, repo string, number int) error
+
+	// Actions secrets. An empty environment means a repository secret.
+	PutActionsSecret(ctx context.Context, owner, repo, environment, name, value string) error
+	DeleteActionsSecret(ctx context.Context, owner, repo, environment, name string) error
+
 	// Rate limiting.
 	RateLimitRemaining() int
 	RateLimitReset() time.Time
@@ -48,6 +87,8 @@ type ListWorkflowRunsOpts struct {
 	Status    string
 	CreatedAt *time.Time
 	PerPage   int
+	// Page is the 1-based page of results to return (default 1).
+	Page int
 }
 
 // Runner represents a GitHub Actions runner.
@@ -60,6 +101,21 @@ type Runner struct {
 	Labels []string
 }
 
+// HostedRunner represents a GitHub-hosted larger runner. Unlike a self-hosted
+// runner it is a pool: GitHub starts a machine for each job, up to MaxRunners
+// at once, so it is never busy itself.
+type HostedRunner struct {
+	ID       int64
+	Name     string
+	Platform string // e.g. linux-x64
+	Status   string // Ready, Provisioning, Shutdown, Deleting or Stuck
+	// MaxRunners is how many jobs the pool runs concurrently.
+	MaxRunners int
+}
+
+// HostedRunnerStatusReady is the status of a hosted runner accepting jobs.
+const HostedRunnerStatusReady = "Ready"
+
 // WorkflowRun represents a GitHub Actions workflow run.
 type WorkflowRun struct {
 	ID         int64
@@ -67,8 +123,17 @@ type WorkflowRun struct {
 	Status     string // queued, in_progress, completed
 	Conclusion string // success, failure, cancelled, etc.
 	HTMLURL    string
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	WorkflowID int64
+	HeadBranch string
+	HeadSHA    string
+	// RunAttempt starts at 1 and increases each time the run is re-run.
+	RunAttempt int
+	// LogsURL and ArtifactsURL are API URLs of the latest attempt's logs
+	// archive and artifacts.
+	LogsURL      string
+	ArtifactsURL string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
 
 // WorkflowJob represents a job within a GitHub Actions workflow run.
@@ -79,13 +144,55 @@ type WorkflowJob struct {
 	Conclusion string // success, failure, cancelled, etc.
 	RunnerID   int64
 	RunnerName string
+	Labels     []string
 	StartedAt  time.Time
 }
 
+// Annotation represents a check run annotation, such as one emitted by a
+// workflow command like "::notice title=...::message".
+type Annotation struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Level     string // notice, warning, failure
+	Title     string
+	Message   string
+}
+
+// Environment represents the protection settings of a GitHub deployment environment.
+type Environment struct {
+	Name string
+	// RequiredReviewers is true when deployments need manual approval.
+	RequiredReviewers bool
+	// WaitTimer is the number of minutes deployments are delayed before starting.
+	WaitTimer int
+	// BranchPolicies lists the ref name patterns allowed to deploy when custom
+	// branch policies are enabled. Nil means any ref may deploy.
+	BranchPolicies []string
+}
+
+// RepoPermissions describes the access the authenticated token has to a repository.
+type RepoPermissions struct {
+	Admin bool
+	Push  bool
+	Pull  bool
+}
+
+// WorkflowFile is the source of a workflow definition at a specific ref.
+type WorkflowFile struct {
+	Path    string
+	Content []byte
+}
+
 // client implements Client.
 type client struct {
 	log             logrus.FieldLogger
 	token           string
+	app             *config.GitHubAppConfig
+	baseURL         string
+	httpConfig      *config.GitHubHTTPConfig
+	name            string
+	metrics         *metrics.Metrics
 	gh              *github.Client
 	mu              sync.RWMutex
 	rateRemaining   int
@@ -97,12 +204,46 @@ type client struct {
 // Ensure client implements Client.
 var _ Client = (*client)(nil)
 
+// ClientOption configures a GitHub client.
+type ClientOption func(c *client)
+
+// WithBaseURL points the client at a different API endpoint, such as a GitHub
+// Enterprise Server API or a fake API in tests.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithHTTPConfig sets the client's timeout, proxy and retries. Without it the
+// client uses the default transport and does not retry.
+func WithHTTPConfig(cfg config.GitHubHTTPConfig) ClientOption {
+	return func(c *client) {
+		c.httpConfig = &cfg
+	}
+}
+
+// WithMetrics reports the client's metrics, such as its rate limiter queue
+// depth, under the given client name.
+func WithMetrics(name string, m *metrics.Metrics) ClientOption {
+	return func(c *client) {
+		c.name = name
+		c.metrics = m
+	}
+}
+
 // NewClient creates a new GitHub client.
-func NewClient(log logrus.FieldLogger, token string) Client {
-	return &client{
+func NewClient(log logrus.FieldLogger, token string, opts ...ClientOption) Client {
+	c := &client{
 		log:   log.WithField("component", "github"),
 		token: token,
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
 // Start initializes the GitHub client.
@@ -111,10 +252,21 @@ func NewClient(log logrus.FieldLogger, token string) Client {
 func (c *client) Start(ctx context.Context) error {
 	c.log.Info("Initializing GitHub client")
 
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: c.token})
-	tc := oauth2.NewClient(ctx, ts)
+	httpClient, err := c.newHTTPClient()
+	if err != nil {
+		return fmt.Errorf("creating HTTP client: %w", err)
+	}
+
+	c.gh = github.NewClient(httpClient)
 
-	c.gh = github.NewClient(tc)
+	if c.baseURL != "" {
+		baseURL, err := url.Parse(strings.TrimSuffix(c.baseURL, "/") + "/")
+		if err != nil {
+			return fmt.Errorf("parsing base URL: %w", err)
+		}
+
+		c.gh.BaseURL = baseURL
+	}
 
 	// Test authentication by getting rate limit.
 	rate, _, err := c.gh.RateLimit.Get(ctx)
@@ -165,6 +317,14 @@ func (c *client) updateRateLimit(resp *github.Response) {
 	c.rateReset = resp.Rate.Reset.Time
 }
 
+// addQueueDepth reports a change in the number of requests waiting for the
+// client's rate limiter.
+func (c *client) addQueueDepth(delta float64) {
+	if c.metrics != nil {
+		c.metrics.AddGitHubAPIQueueDepth(c.name, delta)
+	}
+}
+
 // RateLimitRemaining returns the remaining API calls.
 func (c *client) RateLimitRemaining() int {
 	c.mu.RLock()
@@ -271,6 +431,66 @@ func (c *client) ListRepoRunners(ctx context.Context, owner, repo string) ([]*Ru
 	return allRunners, nil
 }
 
+// hostedRunnersPage is a page of the hosted runners API, which go-github does
+// not cover yet.
+type hostedRunnersPage struct {
+	TotalCount int `json:"total_count"`
+	Runners    []struct {
+		ID             int64  `json:"id"`
+		Name           string `json:"name"`
+		Platform       string `json:"platform"`
+		Status         string `json:"status"`
+		MaximumRunners int    `json:"maximum_runners"`
+	} `json:"runners"`
+}
+
+// ListOrgHostedRunners lists all GitHub-hosted larger runners for an
+// organization.
+func (c *client) ListOrgHostedRunners(ctx context.Context, org string) ([]*HostedRunner, error) {
+	c.log.WithField("org", org).Debug("Listing organization hosted runners")
+
+	var allRunners []*HostedRunner
+
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("orgs/%s/actions/hosted-runners?per_page=100&page=%d", url.PathEscape(org), page)
+
+		req, err := c.gh.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating hosted runners request: %w", err)
+		}
+
+		var runners hostedRunnersPage
+
+		resp, err := c.gh.Do(ctx, req, &runners)
+		if err != nil {
+			return nil, fmt.Errorf("listing org hosted runners: %w", err)
+		}
+
+		c.updateRateLimit(resp)
+
+		for _, r := range runners.Runners {
+			allRunners = append(allRunners, &HostedRunner{
+				ID:         r.ID,
+				Name:       r.Name,
+				Platform:   r.Platform,
+				Status:     r.Status,
+				MaxRunners: r.MaximumRunners,
+			})
+		}
+
+		if resp.NextPage == 0 || len(runners.Runners) == 0 {
+			break
+		}
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"org":   org,
+		"count": len(allRunners),
+	}).Debug("Listed organization hosted runners")
+
+	return allRunners, nil
+}
+
 // convertRunner converts a GitHub runner to our Runner type.
 func convertRunner(r *github.Runner) *Runner {
 	labels := make([]string, 0, len(r.Labels))
@@ -296,7 +516,7 @@ func convertRunner(r *github.Runner) *Runner {
 func (c *client) TriggerWorkflowDispatch(
 	ctx context.Context,
 	owner, repo, workflowID, ref string,
-	inputs map[string]string,
+	inputs input.Map,
 ) error {
 	c.log.WithFields(logrus.Fields{
 		"owner":    owner,
@@ -352,55 +572,62 @@ func (c *client) GetWorkflowRun(ctx context.Context, owner, repo string, runID i
 
 	c.updateRateLimit(resp)
 
-	return &WorkflowRun{
-		ID:         run.GetID(),
-		Name:       run.GetName(),
-		Status:     run.GetStatus(),
-		Conclusion: run.GetConclusion(),
-		HTMLURL:    run.GetHTMLURL(),
-		CreatedAt:  run.GetCreatedAt().Time,
-		UpdatedAt:  run.GetUpdatedAt().Time,
-	}, nil
+	return ConvertWorkflowRun(run), nil
 }
 
-// ListWorkflowRuns lists workflow runs for a specific workflow.
-func (c *client) ListWorkflowRuns(
-	ctx context.Context,
-	owner, repo, workflowID string,
-	opts ListWorkflowRunsOpts,
-) ([]*WorkflowRun, error) {
-	c.log.WithFields(logrus.Fields{
-		"owner":    owner,
-		"repo":     repo,
-		"workflow": workflowID,
-	}).Debug("Listing workflow runs")
+// convertWorkflowRun converts a workflow run returned by the GitHub API.
+func ConvertWorkflowRun(run *github.WorkflowRun) *WorkflowRun {
+	return &WorkflowRun{
+		ID:           run.GetID(),
+		Name:         run.GetName(),
+		Status:       run.GetStatus(),
+		Conclusion:   run.GetConclusion(),
+		HTMLURL:      run.GetHTMLURL(),
+		WorkflowID:   run.GetWorkflowID(),
+		HeadBranch:   run.GetHeadBranch(),
+		HeadSHA:      run.GetHeadSHA(),
+		RunAttempt:   run.GetRunAttempt(),
+		LogsURL:      run.GetLogsURL(),
+		ArtifactsURL: run.GetArtifactsURL(),
+		CreatedAt:    run.GetCreatedAt().Time,
+		UpdatedAt:    run.GetUpdatedAt().Time,
+	}
+}
 
+// listWorkflowRunsOptions converts ListWorkflowRunsOpts for the GitHub API.
+func listWorkflowRunsOptions(opts ListWorkflowRunsOpts) *github.ListWorkflowRunsOptions {
 	perPage := opts.PerPage
 	if perPage <= 0 {
 		perPage = 10
 	}
 
 	listOpts := &github.ListWorkflowRunsOptions{
-		ListOptions: github.ListOptions{PerPage: perPage},
-	}
-
-	if opts.Branch != "" {
-		listOpts.Branch = opts.Branch
-	}
-
-	if opts.Event != "" {
-		listOpts.Event = opts.Event
-	}
-
-	if opts.Status != "" {
-		listOpts.Status = opts.Status
+		Branch:      opts.Branch,
+		Event:       opts.Event,
+		Status:      opts.Status,
+		ListOptions: github.ListOptions{PerPage: perPage, Page: opts.Page},
 	}
 
 	if opts.CreatedAt != nil {
 		listOpts.Created = ">=" + opts.CreatedAt.Format(time.RFC3339)
 	}
 
-	runs, resp, err := c.gh.Actions.ListWorkflowRunsByFileName(ctx, owner, repo, workflowID, listOpts)
+	return listOpts
+}
+
+// ListWorkflowRuns lists workflow runs for a specific workflow.
+func (c *client) ListWorkflowRuns(
+	ctx context.Context,
+	owner, repo, workflowID string,
+	opts ListWorkflowRunsOpts,
+) ([]*WorkflowRun, error) {
+	c.log.WithFields(logrus.Fields{
+		"owner":    owner,
+		"repo":     repo,
+		"workflow": workflowID,
+	}).Debug("Listing workflow runs")
+
+	runs, resp, err := c.gh.Actions.ListWorkflowRunsByFileName(ctx, owner, repo, workflowID, listWorkflowRunsOptions(opts))
 	if err != nil {
 		return nil, fmt.Errorf("listing workflow runs: %w", err)
 	}
@@ -410,15 +637,7 @@ func (c *client) ListWorkflowRuns(
 	result := make([]*WorkflowRun, 0, len(runs.WorkflowRuns))
 
 	for _, run := range runs.WorkflowRuns {
-		result = append(result, &WorkflowRun{
-			ID:         run.GetID(),
-			Name:       run.GetName(),
-			Status:     run.GetStatus(),
-			Conclusion: run.GetConclusion(),
-			HTMLURL:    run.GetHTMLURL(),
-			CreatedAt:  run.GetCreatedAt().Time,
-			UpdatedAt:  run.GetUpdatedAt().Time,
-		})
+		result = append(result, ConvertWorkflowRun(run))
 	}
 
 	c.log.WithFields(logrus.Fields{
@@ -431,6 +650,31 @@ func (c *client) ListWorkflowRuns(
 	return result, nil
 }
 
+// ListRepoWorkflowRuns lists the workflow runs of a repository, across all
+// of its workflows.
+func (c *client) ListRepoWorkflowRuns(ctx context.Context, owner, repo string, opts ListWorkflowRunsOpts) ([]*WorkflowRun, error) {
+	c.log.WithFields(logrus.Fields{
+		"owner": owner,
+		"repo":  repo,
+		"page":  opts.Page,
+	}).Debug("Listing repository workflow runs")
+
+	runs, resp, err := c.gh.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, listWorkflowRunsOptions(opts))
+	if err != nil {
+		return nil, fmt.Errorf("listing repository workflow runs: %w", err)
+	}
+
+	c.updateRateLimit(resp)
+
+	result := make([]*WorkflowRun, 0, len(runs.WorkflowRuns))
+
+	for _, run := range runs.WorkflowRuns {
+		result = append(result, ConvertWorkflowRun(run))
+	}
+
+	return result, nil
+}
+
 // ListWorkflowRunJobs lists jobs for a specific workflow run.
 func (c *client) ListWorkflowRunJobs(ctx context.Context, owner, repo string, runID int64) ([]*WorkflowJob, error) {
 	c.log.WithFields(logrus.Fields{
@@ -461,6 +705,7 @@ func (c *client) ListWorkflowRunJobs(ctx context.Context, owner, repo string, ru
 				Conclusion: job.GetConclusion(),
 				RunnerID:   job.GetRunnerID(),
 				RunnerName: job.GetRunnerName(),
+				Labels:     job.Labels,
 			}
 
 			if job.StartedAt != nil {
@@ -487,6 +732,167 @@ func (c *client) ListWorkflowRunJobs(ctx context.Context, owner, repo string, ru
 	return allJobs, nil
 }
 
+// GetEnvironment returns the protection settings of a deployment environment.
+// Returns nil if the environment does not exist.
+func (c *client) GetEnvironment(ctx context.Context, owner, repo, name string) (*Environment, error) {
+	env, resp, err := c.gh.Repositories.GetEnvironment(ctx, owner, repo, name)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("getting environment: %w", err)
+	}
+
+	c.updateRateLimit(resp)
+
+	result := &Environment{Name: env.GetName()}
+
+	for _, rule := range env.ProtectionRules {
+		switch rule.GetType() {
+		case "required_reviewers":
+			result.RequiredReviewers = true
+		case "wait_timer":
+			result.WaitTimer = rule.GetWaitTimer()
+		}
+	}
+
+	if policy := env.DeploymentBranchPolicy; policy != nil {
+		if policy.GetCustomBranchPolicies() {
+			policies, resp, err := c.gh.Repositories.ListDeploymentBranchPolicies(ctx, owner, repo, name)
+			if err != nil {
+				return nil, fmt.Errorf("listing deployment branch policies: %w", err)
+			}
+
+			c.updateRateLimit(resp)
+
+			result.BranchPolicies = make([]string, 0, len(policies.BranchPolicies))
+			for _, p := range policies.BranchPolicies {
+				result.BranchPolicies = append(result.BranchPolicies, p.GetName())
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ListCheckRunAnnotations lists annotations for a check run.
+// The check run ID of a workflow job is the same as its job ID.
+func (c *client) ListCheckRunAnnotations(
+	ctx context.Context,
+	owner, repo string,
+	checkRunID int64,
+) ([]*Annotation, error) {
+	c.log.WithFields(logrus.Fields{
+		"owner":        owner,
+		"repo":         repo,
+		"check_run_id": checkRunID,
+	}).Debug("Listing check run annotations")
+
+	var allAnnotations []*Annotation
+
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		annotations, resp, err := c.gh.Checks.ListCheckRunAnnotations(ctx, owner, repo, checkRunID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("listing check run annotations: %w", err)
+		}
+
+		c.updateRateLimit(resp)
+
+		for _, a := range annotations {
+			allAnnotations = append(allAnnotations, &Annotation{
+				Path:      a.GetPath(),
+				StartLine: a.GetStartLine(),
+				EndLine:   a.GetEndLine(),
+				Level:     a.GetAnnotationLevel(),
+				Title:     a.GetTitle(),
+				Message:   a.GetMessage(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	return allAnnotations, nil
+}
+
+// ResolveRef returns the commit SHA a branch, tag, or SHA currently points to.
+func (c *client) ResolveRef(ctx context.Context, owner, repo, ref string) (string, error) {
+	sha, resp, err := c.gh.Repositories.GetCommitSHA1(ctx, owner, repo, ref, "")
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity) {
+			return "", fmt.Errorf("resolving ref %q: %w", ref, ErrRefNotFound)
+		}
+
+		return "", fmt.Errorf("resolving ref %q: %w", ref, err)
+	}
+
+	c.updateRateLimit(resp)
+
+	return sha, nil
+}
+
+// GetRepoPermissions returns the token's permissions on a repository.
+// Returns nil if the repository does not exist or is not visible to the token.
+func (c *client) GetRepoPermissions(ctx context.Context, owner, repo string) (*RepoPermissions, error) {
+	r, resp, err := c.gh.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("getting repository: %w", err)
+	}
+
+	c.updateRateLimit(resp)
+
+	perms := r.GetPermissions()
+
+	return &RepoPermissions{
+		Admin: perms["admin"],
+		Push:  perms["push"],
+		Pull:  perms["pull"],
+	}, nil
+}
+
+// GetWorkflowFile fetches the definition of a workflow (identified by file name)
+// at the given ref. Returns nil if the workflow or its file does not exist there.
+func (c *client) GetWorkflowFile(ctx context.Context, owner, repo, workflowID, ref string) (*WorkflowFile, error) {
+	path := workflowID
+	if !strings.Contains(path, "/") {
+		path = ".github/workflows/" + workflowID
+	}
+
+	file, _, resp, err := c.gh.Repositories.GetContents(ctx, owner, repo, path,
+		&github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("getting workflow file: %w", err)
+	}
+
+	c.updateRateLimit(resp)
+
+	if file == nil {
+		return nil, fmt.Errorf("workflow path %s is a directory", path)
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("decoding workflow file: %w", err)
+	}
+
+	return &WorkflowFile{Path: path, Content: []byte(content)}, nil
+}
+
 // CancelWorkflowRun cancels a workflow run.
 func (c *client) CancelWorkflowRun(ctx context.Context, owner, repo string, runID int64) error {
 	c.log.WithFields(logrus.Fields{
@@ -510,3 +916,165 @@ func (c *client) CancelWorkflowRun(ctx context.Context, owner, repo string, runI
 
 	return nil
 }
+
+// CreateIssueComment comments on an issue or pull request.
+func (c *client) CreateIssueComment(ctx context.Context, owner, repo string, number int, body string) error {
+	_, resp, err := c.gh.Issues.CreateComment(ctx, owner, repo, number, &github.IssueComment{Body: &body})
+	if err != nil {
+		return fmt.Errorf("creating issue comment: %w", err)
+	}
+
+	c.updateRateLimit(resp)
+
+	return nil
+}
+
+// CloseIssue closes an issue as completed.
+func (c *client) CloseIssue(ctx context.Context, owner, repo string, number int) error {
+	state, reason := "closed", "completed"
+
+	_, resp, err := c.gh.Issues.Edit(ctx, owner, repo, number, &github.IssueRequest{State: &state, StateReason: &reason})
+	if err != nil {
+		return fmt.Errorf("closing issue: %w", err)
+	}
+
+	c.updateRateLimit(resp)
+
+	return nil
+}
+
+// PutActionsSecret creates or updates a GitHub Actions secret of a repository,
+// or of one of its deployment environments. The value is encrypted with the
+// repository's or environment's public key before it is sent.
+func (c *client) PutActionsSecret(ctx context.Context, owner, repo, environment, name, value string) error {
+	var (
+		repoID int
+		key    *github.PublicKey
+		resp   *github.Response
+		err    error
+	)
+
+	if environment == "" {
+		key, resp, err = c.gh.Actions.GetRepoPublicKey(ctx, owner, repo)
+	} else {
+		if repoID, err = c.repoID(ctx, owner, repo); err != nil {
+			return err
+		}
+
+		key, resp, err = c.gh.Actions.GetEnvPublicKey(ctx, repoID, environment)
+	}
+
+	if err != nil {
+		return fmt.Errorf("getting secrets public key: %w", err)
+	}
+
+	c.updateRateLimit(resp)
+
+	encrypted, err := sealSecret(key.GetKey(), value)
+	if err != nil {
+		return err
+	}
+
+	secret := &github.EncryptedSecret{Name: name, KeyID: key.GetKeyID(), EncryptedValue: encrypted}
+
+	if environment == "" {
+		resp, err = c.gh.Actions.CreateOrUpdateRepoSecret(ctx, owner, repo, secret)
+	} else {
+		resp, err = c.gh.Actions.CreateOrUpdateEnvSecret(ctx, repoID, environment, secret)
+	}
+
+	if err != nil {
+		return fmt.Errorf("writing secret %s: %w", name, err)
+	}
+
+	c.updateRateLimit(resp)
+
+	return nil
+}
+
+// DeleteActionsSecret deletes a GitHub Actions secret of a repository or of
+// one of its deployment environments. Secrets that do not exist are ignored.
+func (c *client) DeleteActionsSecret(ctx context.Context, owner, repo, environment, name string) error {
+	var (
+		resp *github.Response
+		err  error
+	)
+
+	if environment == "" {
+		resp, err = c.gh.Actions.DeleteRepoSecret(ctx, owner, repo, name)
+	} else {
+		repoID, idErr := c.repoID(ctx, owner, repo)
+		if idErr != nil {
+			return idErr
+		}
+
+		resp, err = c.gh.Actions.DeleteEnvSecret(ctx, repoID, environment, name)
+	}
+
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil
+		}
+
+		return fmt.Errorf("deleting secret %s: %w", name, err)
+	}
+
+	c.updateRateLimit(resp)
+
+	return nil
+}
+
+// repoID returns the numeric ID of a repository, which the environment
+// secrets API is addressed by.
+func (c *client) repoID(ctx context.Context, owner, repo string) (int, error) {
+	r, resp, err := c.gh.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return 0, fmt.Errorf("getting repository: %w", err)
+	}
+
+	c.updateRateLimit(resp)
+
+	return int(r.GetID()), nil
+}
+
+// sealSecret encrypts a secret value for GitHub with a base64 encoded
+// libsodium public key, returning the base64 encoded sealed box.
+func sealSecret(publicKey, value string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(decoded) != 32 {
+		return "", fmt.Errorf("invalid secrets public key")
+	}
+
+	var recipient [32]byte
+
+	copy(recipient[:], decoded)
+
+	sealed, err := box.SealAnonymous(nil, []byte(value), &recipient, rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("encrypting secret: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// ParseRunURL extracts the owner, repository and run ID from a workflow run URL
+// such as https://github.com/owner/repo/actions/runs/123. Attempt and job
+// suffixes are ignored.
+func ParseRunURL(rawURL string) (owner, repo string, runID int64, err error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", "", 0, fmt.Errorf("parsing run URL: %w", err)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 5 || parts[2] != "actions" || parts[3] != "runs" {
+		return "", "", 0, fmt.Errorf("invalid run URL %q, expected https://github.com/{owner}/{repo}/actions/runs/{id}", rawURL)
+	}
+
+	runID, err = strconv.ParseInt(parts[4], 10, 64)
+	if err != nil || runID <= 0 {
+		return "", "", 0, fmt.Errorf("invalid run ID %q in run URL", parts[4])
+	}
+
+	return parts[0], parts[1], runID, nil
+}
@@ -0,0 +1,71 @@
+package github
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+)
+
+// HostedRunnerID returns the store ID of a hosted runner. GitHub numbers
+// hosted and self-hosted runners separately, so hosted runners are stored
+// under their negated ID to keep the two apart.
+func HostedRunnerID(id int64) int64 {
+	return -id
+}
+
+// hostedPoolRunner converts a hosted runner to a store runner. Workflows
+// target a larger runner by its name, which is therefore its only label.
+func hostedPoolRunner(r *HostedRunner, now time.Time) *store.Runner {
+	status := store.RunnerStatusOnline
+	if r.Status != HostedRunnerStatusReady {
+		status = store.RunnerStatusOffline
+	}
+
+	platformOS, _, _ := strings.Cut(r.Platform, "-")
+
+	return &store.Runner{
+		ID:         HostedRunnerID(r.ID),
+		Name:       r.Name,
+		Labels:     []string{r.Name},
+		Status:     status,
+		OS:         platformOS,
+		Hosted:     true,
+		MaxRunners: r.MaxRunners,
+		LastSeenAt: now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// HostedActiveJobs counts the jobs in flight on a hosted pool: the given
+// triggered and running jobs of the groups, keyed by ID in groupLabels, whose
+// runner labels the pool carries. GitHub does not report how many of a pool's
+// machines are in use, so dispatchoor's own jobs are all it can count.
+func HostedActiveJobs(pool *store.Runner, groupLabels map[string][]string, jobs []*store.Job) int {
+	var active int
+
+	for _, job := range jobs {
+		labels, ok := groupLabels[job.GroupID]
+		if !ok {
+			continue
+		}
+
+		matches := true
+
+		for _, label := range labels {
+			if !slices.Contains(pool.Labels, label) {
+				matches = false
+
+				break
+			}
+		}
+
+		if matches {
+			active++
+		}
+	}
+
+	return active
+}
@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethpandaops/dispatchoor/pkg/config"
@@ -19,6 +20,9 @@ type Poller interface {
 	Stop() error
 	ForceRefresh(ctx context.Context) error
 	SetRunnerChangeCallback(cb RunnerChangeCallback)
+	// SetRateLimitBuffer changes the remaining rate limit below which polls
+	// are skipped.
+	SetRateLimitBuffer(buffer int)
 }
 
 // poller implements Poller.
@@ -29,7 +33,7 @@ type poller struct {
 	store                store.Store
 	metrics              Metrics
 	interval             time.Duration
-	rateLimitBuffer      int
+	rateLimitBuffer      atomic.Int64
 	cancel               context.CancelFunc
 	wg                   sync.WaitGroup
 	mu                   sync.Mutex
@@ -53,15 +57,18 @@ func NewPoller(
 	st store.Store,
 	m Metrics,
 ) Poller {
-	return &poller{
-		log:             log.WithField("component", "poller"),
-		cfg:             cfg,
-		client:          client,
-		store:           st,
-		metrics:         m,
-		interval:        cfg.GitHub.PollInterval,
-		rateLimitBuffer: cfg.GitHub.RateLimitBuffer,
+	p := &poller{
+		log:      log.WithField("component", "poller"),
+		cfg:      cfg,
+		client:   client,
+		store:    st,
+		metrics:  m,
+		interval: cfg.GitHub.PollInterval,
 	}
+
+	p.rateLimitBuffer.Store(int64(cfg.GitHub.RateLimitBuffer))
+
+	return p
 }
 
 // Start begins the polling loop.
@@ -108,6 +115,11 @@ func (p *poller) SetRunnerChangeCallback(cb RunnerChangeCallback) {
 	p.runnerChangeCallback = cb
 }
 
+// SetRateLimitBuffer changes the rate limit buffer from the next poll on.
+func (p *poller) SetRateLimitBuffer(buffer int) {
+	p.rateLimitBuffer.Store(int64(buffer))
+}
+
 // notifyRunnerChange calls the callback if set.
 func (p *poller) notifyRunnerChange(runner *store.Runner) {
 	if p.runnerChangeCallback != nil {
@@ -144,11 +156,11 @@ func (p *poller) poll(ctx context.Context) error {
 	remaining := p.client.RateLimitRemaining()
 	p.metrics.SetGitHubRateLimit(float64(remaining))
 
-	if remaining < p.rateLimitBuffer {
+	if buffer := int(p.rateLimitBuffer.Load()); remaining < buffer {
 		resetAt := p.client.RateLimitReset()
 		p.log.WithFields(logrus.Fields{
 			"remaining": remaining,
-			"buffer":    p.rateLimitBuffer,
+			"buffer":    buffer,
 			"reset_at":  resetAt,
 		}).Warn("Rate limit too low, skipping poll")
 
@@ -205,13 +217,15 @@ func (p *poller) poll(ctx context.Context) error {
 	// Update store with runner status.
 	now := time.Now()
 
+	storeRunners := make([]*store.Runner, 0, len(allRunners))
+
 	for _, r := range allRunners {
 		status := store.RunnerStatusOnline
 		if r.Status != "online" {
 			status = store.RunnerStatusOffline
 		}
 
-		runner := &store.Runner{
+		storeRunners = append(storeRunners, &store.Runner{
 			ID:         r.ID,
 			Name:       r.Name,
 			Labels:     r.Labels,
@@ -221,21 +235,27 @@ func (p *poller) poll(ctx context.Context) error {
 			LastSeenAt: now,
 			CreatedAt:  now,
 			UpdatedAt:  now,
-		}
+		})
+	}
 
+	if p.cfg.GitHub.HostedRunners {
+		storeRunners = append(storeRunners, p.pollHostedRunners(ctx, orgs, now)...)
+	}
+
+	for _, runner := range storeRunners {
 		if err := p.store.UpsertRunner(ctx, runner); err != nil {
-			p.log.WithError(err).WithField("runner", r.Name).Error("Failed to upsert runner")
+			p.log.WithError(err).WithField("runner", runner.Name).Error("Failed to upsert runner")
 
 			continue
 		}
 
 		// Check if runner state changed and notify.
-		prev, existed := previousState[r.ID]
-		if !existed || prev.Status != status || prev.Busy != r.Busy {
+		prev, existed := previousState[runner.ID]
+		if !existed || prev.Status != runner.Status || prev.Busy != runner.Busy {
 			p.log.WithFields(logrus.Fields{
-				"runner":      r.Name,
-				"status":      status,
-				"busy":        r.Busy,
+				"runner":      runner.Name,
+				"status":      runner.Status,
+				"busy":        runner.Busy,
 				"prev_status": prev.Status,
 				"prev_busy":   prev.Busy,
 				"new":         !existed,
@@ -256,9 +276,46 @@ func (p *poller) poll(ctx context.Context) error {
 	p.metrics.SetGitHubRateLimit(float64(remaining))
 
 	p.log.WithFields(logrus.Fields{
-		"runners":        len(allRunners),
+		"runners":        len(storeRunners),
 		"rate_remaining": remaining,
 	}).Info("Poll completed")
 
 	return nil
 }
+
+// pollHostedRunners fetches the hosted larger runners of the orgs. Each is a
+// pool, marked busy while the groups it serves have as many jobs in flight as
+// it has maximum runners.
+func (p *poller) pollHostedRunners(ctx context.Context, orgs map[string]bool, now time.Time) []*store.Runner {
+	jobs, err := p.store.ListJobsByStatus(ctx, store.JobStatusTriggered, store.JobStatusRunning)
+	if err != nil {
+		p.log.WithError(err).Error("Failed to list active jobs for hosted runners")
+
+		return nil
+	}
+
+	groupLabels := make(map[string][]string, len(p.cfg.Groups.GitHub))
+	for _, group := range p.cfg.Groups.GitHub {
+		groupLabels[group.ID] = group.RunnerLabels
+	}
+
+	var runners []*store.Runner
+
+	for org := range orgs {
+		hosted, err := p.client.ListOrgHostedRunners(ctx, org)
+		if err != nil {
+			p.log.WithError(err).WithField("org", org).Error("Failed to list org hosted runners")
+
+			continue
+		}
+
+		for _, r := range hosted {
+			pool := hostedPoolRunner(r, now)
+			pool.Busy = HostedActiveJobs(pool, groupLabels, jobs) >= pool.MaxRunners
+
+			runners = append(runners, pool)
+		}
+	}
+
+	return runners
+}
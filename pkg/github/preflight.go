@@ -0,0 +1,142 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// Permission names reported by pre-flight checks.
+const (
+	PermissionActionsWrite = "actions:write"
+	PermissionRunnersRead  = "runners:read"
+)
+
+// PermissionCheck is the result of verifying that a token can perform the
+// operations dispatchoor needs against an org or repository.
+type PermissionCheck struct {
+	// Target is "owner/repo" for repository checks or the org name for runner checks.
+	Target     string
+	Permission string
+	OK         bool
+	Error      string
+	// Templates lists the IDs of templates that depend on this permission.
+	Templates []string
+}
+
+// CheckPermissions verifies, for every template in the store, that the dispatch
+// client can trigger workflows in the template's repository and that the runners
+// client can list runners of the template's owner. Either client may be nil, in
+// which case its checks are skipped. Failures are logged per template and returned
+// rather than treated as fatal, so they surface before the first dispatch.
+func CheckPermissions(
+	ctx context.Context,
+	log logrus.FieldLogger,
+	st store.Store,
+	runnersClient, dispatchClient Client,
+) ([]*PermissionCheck, error) {
+	log = log.WithField("component", "preflight")
+
+	groups, err := st.ListGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing groups: %w", err)
+	}
+
+	repos := make(map[string][]string)
+	orgs := make(map[string][]string)
+
+	for _, group := range groups {
+		templates, err := st.ListJobTemplatesByGroup(ctx, group.ID)
+		if err != nil {
+			return nil, fmt.Errorf("listing templates for group %s: %w", group.ID, err)
+		}
+
+		for _, tmpl := range templates {
+			if tmpl.Owner == "" || tmpl.Repo == "" {
+				continue
+			}
+
+			repo := tmpl.Owner + "/" + tmpl.Repo
+			repos[repo] = append(repos[repo], tmpl.ID)
+			orgs[tmpl.Owner] = append(orgs[tmpl.Owner], tmpl.ID)
+		}
+	}
+
+	var checks []*PermissionCheck
+
+	if dispatchClient != nil && dispatchClient.IsConnected() {
+		for _, target := range sortedKeys(repos) {
+			check := &PermissionCheck{
+				Target:     target,
+				Permission: PermissionActionsWrite,
+				Templates:  repos[target],
+			}
+
+			owner, repo, _ := strings.Cut(target, "/")
+
+			perms, err := dispatchClient.GetRepoPermissions(ctx, owner, repo)
+
+			switch {
+			case err != nil:
+				check.Error = err.Error()
+			case perms == nil:
+				check.Error = "repository not found or not accessible with the dispatch token"
+			case !perms.Push:
+				check.Error = "dispatch token lacks write access to the repository"
+			default:
+				check.OK = true
+			}
+
+			checks = append(checks, check)
+		}
+	}
+
+	if runnersClient != nil && runnersClient.IsConnected() {
+		for _, org := range sortedKeys(orgs) {
+			check := &PermissionCheck{
+				Target:     org,
+				Permission: PermissionRunnersRead,
+				Templates:  orgs[org],
+			}
+
+			if _, err := runnersClient.ListOrgRunners(ctx, org); err != nil {
+				check.Error = err.Error()
+			} else {
+				check.OK = true
+			}
+
+			checks = append(checks, check)
+		}
+	}
+
+	for _, check := range checks {
+		if check.OK {
+			continue
+		}
+
+		log.WithFields(logrus.Fields{
+			"target":     check.Target,
+			"permission": check.Permission,
+			"templates":  check.Templates,
+			"error":      check.Error,
+		}).Warn("Permission check failed")
+	}
+
+	return checks, nil
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,263 @@
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
+)
+
+// secondaryRateLimitMarkers are phrases GitHub uses in the bodies of responses
+// rejected by its secondary (abuse) rate limits.
+var secondaryRateLimitMarkers = []string{"secondary rate limit", "abuse detection"}
+
+// maxInspectedBody bounds how much of a rate limited response is read to
+// detect secondary rate limits.
+const maxInspectedBody = 64 << 10
+
+// newHTTPClient builds the HTTP client of an API client: an oauth2 transport,
+// carrying the token or the app's installation tokens, over a retrying transport over a rate limiter over a proxy-aware transport.
+// Retries pass through the rate limiter like any other request.
+func (c *client) newHTTPClient() (*http.Client, error) {
+	cfg := c.httpConfig
+
+	if cfg == nil {
+		ts, err := c.tokenSource(http.DefaultClient)
+		if err != nil {
+			return nil, err
+		}
+
+		return &http.Client{Transport: &oauth2.Transport{Source: ts}}, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("parsing proxy URL: %w", err)
+		}
+
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	var base http.RoundTripper = transport
+
+	if limit := cfg.RateLimit; limit.RequestsPerSecond > 0 {
+		base = &limitTransport{
+			base:    base,
+			limiter: rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), limit.Burst),
+			waiting: c.addQueueDepth,
+		}
+	}
+
+	if cfg.Retry.MaxAttempts > 1 {
+		base = &retryTransport{log: c.log, base: base, retry: cfg.Retry}
+	}
+
+	// Installation tokens are minted through the same transport, unauthenticated.
+	ts, err := c.tokenSource(&http.Client{Timeout: cfg.Timeout, Transport: base})
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: &oauth2.Transport{Source: ts, Base: base},
+	}, nil
+}
+
+// limitTransport holds requests until its token bucket has a token, so bursts
+// such as tracking hundreds of runs at once are spread out.
+type limitTransport struct {
+	base    http.RoundTripper
+	limiter *rate.Limiter
+	// waiting is called with +1 when a request starts waiting and -1 when it
+	// stops.
+	waiting func(delta float64)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.waiting(1)
+	err := t.limiter.Wait(req.Context())
+	t.waiting(-1)
+
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+
+		return nil, fmt.Errorf("waiting for GitHub rate limiter: %w", err)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// retryTransport re-sends requests that failed with a server error or hit a
+// secondary rate limit. Server errors are only retried for GET and HEAD
+// requests, since a failed dispatch may still have created a run; requests
+// rejected by a secondary rate limit were never processed and are retried
+// for any method.
+type retryTransport struct {
+	log   logrus.FieldLogger
+	base  http.RoundTripper
+	retry config.GitHubRetryConfig
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		wait, reason := t.retryDelay(req, resp, attempt)
+		if reason == "" || attempt >= t.retry.MaxAttempts {
+			return resp, nil
+		}
+
+		// Requests whose body cannot be replayed are not retried.
+		var body io.ReadCloser
+
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+
+			if body, err = req.GetBody(); err != nil {
+				return resp, nil
+			}
+		}
+
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxInspectedBody))
+		_ = resp.Body.Close()
+
+		t.log.WithFields(logrus.Fields{
+			"method":  req.Method,
+			"path":    req.URL.Path,
+			"status":  resp.StatusCode,
+			"attempt": attempt,
+			"wait":    wait,
+		}).Warn("Retrying GitHub request after " + reason)
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if body != nil {
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryDelay decides whether a response is retried and how long to wait
+// first. It returns an empty reason for responses that are not retried,
+// including those whose Retry-After asks for longer than the maximum backoff.
+func (t *retryTransport) retryDelay(req *http.Request, resp *http.Response, attempt int) (time.Duration, string) {
+	var reason string
+
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError:
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			return 0, ""
+		}
+
+		reason = "server error"
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		if !isSecondaryRateLimit(resp) {
+			return 0, ""
+		}
+
+		t.log.WithFields(logrus.Fields{
+			"method":      req.Method,
+			"path":        req.URL.Path,
+			"retry_after": resp.Header.Get("Retry-After"),
+		}).Warn("GitHub secondary rate limit hit")
+
+		reason = "secondary rate limit"
+	default:
+		return 0, ""
+	}
+
+	wait, ok := retryAfter(resp, time.Now())
+	if !ok {
+		wait = t.retry.InitialBackoff << (attempt - 1)
+		if wait <= 0 || wait > t.retry.MaxBackoff {
+			wait = t.retry.MaxBackoff
+		}
+	}
+
+	if wait > t.retry.MaxBackoff {
+		return 0, ""
+	}
+
+	return wait, reason
+}
+
+// isSecondaryRateLimit reports whether a 403 or 429 response comes from a
+// secondary rate limit rather than a permission error or the primary limit.
+// The inspected part of the body is put back for the caller.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests && resp.Header.Get("Retry-After") != "" {
+		return true
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxInspectedBody))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+
+	if err != nil {
+		return false
+	}
+
+	message := strings.ToLower(string(data))
+	for _, marker := range secondaryRateLimitMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryAfter returns the wait GitHub asks for in a Retry-After header, or
+// until X-RateLimit-Reset when the rate limit is exhausted.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if value := resp.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+
+		if at, err := http.ParseTime(value); err == nil {
+			return max(at.Sub(now), 0), true
+		}
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now), 0), true
+		}
+	}
+
+	return 0, false
+}
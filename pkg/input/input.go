@@ -0,0 +1,257 @@
+// Package input holds workflow_dispatch input values. GitHub accepts strings,
+// booleans and numbers, so values keep their type from the config or API
+// request through storage to the dispatch.
+package input
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kind is the type of an input value.
+type Kind uint8
+
+const (
+	KindString Kind = iota
+	KindBool
+	KindNumber
+)
+
+// String returns the name of the kind as used by workflow input types.
+func (k Kind) String() string {
+	switch k {
+	case KindBool:
+		return "boolean"
+	case KindNumber:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// errInvalidValue is returned when decoding a value that is not a string,
+// boolean or number.
+var errInvalidValue = errors.New("input values must be strings, booleans or numbers")
+
+// Value is a typed input value. The zero value is the empty string.
+type Value struct {
+	kind Kind
+	// raw is the string form: the string itself, "true" or "false", or the
+	// number as written.
+	raw string
+}
+
+// String returns a string value.
+func String(s string) Value {
+	return Value{kind: KindString, raw: s}
+}
+
+// Bool returns a boolean value.
+func Bool(b bool) Value {
+	return Value{kind: KindBool, raw: strconv.FormatBool(b)}
+}
+
+// Number returns a number value from its literal, which must be a valid JSON
+// number.
+func Number(literal string) (Value, error) {
+	if !json.Valid([]byte(literal)) {
+		return Value{}, fmt.Errorf("invalid number %q", literal)
+	}
+
+	if _, err := strconv.ParseFloat(literal, 64); err != nil {
+		return Value{}, fmt.Errorf("invalid number %q", literal)
+	}
+
+	return Value{kind: KindNumber, raw: literal}, nil
+}
+
+// Kind returns the type of the value.
+func (v Value) Kind() Kind {
+	return v.kind
+}
+
+// String returns the string form of the value, as GitHub shows it in
+// github.event.inputs.
+func (v Value) String() string {
+	return v.raw
+}
+
+// As returns the value converted to kind, or false if its string form is not
+// a valid value of that kind. Every value converts to a string.
+func (v Value) As(kind Kind) (Value, bool) {
+	switch kind {
+	case KindBool:
+		if v.raw != "true" && v.raw != "false" {
+			return v, false
+		}
+
+		return Bool(v.raw == "true"), true
+	case KindNumber:
+		n, err := Number(v.raw)
+
+		return n, err == nil
+	default:
+		return String(v.raw), true
+	}
+}
+
+// MarshalJSON encodes the value as a JSON string, boolean or number.
+func (v Value) MarshalJSON() ([]byte, error) {
+	switch v.kind {
+	case KindBool, KindNumber:
+		return []byte(v.raw), nil
+	default:
+		return json.Marshal(v.raw)
+	}
+}
+
+// UnmarshalJSON decodes a JSON string, boolean or number.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case len(data) == 0:
+		return errInvalidValue
+	case data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+
+		*v = String(s)
+	case bytes.Equal(data, []byte("true")), bytes.Equal(data, []byte("false")):
+		*v = Bool(data[0] == 't')
+	case data[0] == '-' || (data[0] >= '0' && data[0] <= '9'):
+		n, err := Number(string(data))
+		if err != nil {
+			return err
+		}
+
+		*v = n
+	default:
+		return errInvalidValue
+	}
+
+	return nil
+}
+
+// UnmarshalYAML decodes a YAML scalar. Unquoted booleans and numbers keep
+// their type; everything else, including quoted values, is a string.
+func (v *Value) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: %w", node.Line, errInvalidValue)
+	}
+
+	switch node.ShortTag() {
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return err
+		}
+
+		*v = Bool(b)
+	case "!!int", "!!float":
+		// YAML numbers that are not JSON numbers, such as 0x1F or .inf, are
+		// kept as written.
+		n, err := Number(node.Value)
+		if err != nil {
+			n = String(node.Value)
+		}
+
+		*v = n
+	case "!!null":
+		return fmt.Errorf("line %d: %w", node.Line, errInvalidValue)
+	default:
+		*v = String(node.Value)
+	}
+
+	return nil
+}
+
+// MarshalYAML encodes the value as a YAML string, boolean or number.
+func (v Value) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: v.raw}
+
+	switch v.kind {
+	case KindBool:
+		node.Tag = "!!bool"
+	case KindNumber:
+		node.Tag = "!!float"
+		if _, err := strconv.ParseInt(v.raw, 10, 64); err == nil {
+			node.Tag = "!!int"
+		}
+	default:
+		node.Tag = "!!str"
+	}
+
+	return node, nil
+}
+
+// Map holds named input values.
+type Map map[string]Value
+
+// FromStrings returns a map of string values.
+func FromStrings(values map[string]string) Map {
+	if values == nil {
+		return nil
+	}
+
+	m := make(Map, len(values))
+	for name, value := range values {
+		m[name] = String(value)
+	}
+
+	return m
+}
+
+// Merge returns a copy of m with overrides applied, converted as by Like.
+func (m Map) Merge(overrides Map) Map {
+	merged := make(Map, len(m)+len(overrides))
+	maps.Copy(merged, m)
+	maps.Copy(merged, overrides.Like(m))
+
+	return merged
+}
+
+// Like returns a copy of m with each value converted to the kind of the
+// same input in ref when its string form allows, so "true" from a form keeps
+// a boolean input a boolean.
+func (m Map) Like(ref Map) Map {
+	if m == nil {
+		return nil
+	}
+
+	converted := make(Map, len(m))
+
+	for name, value := range m {
+		if current, ok := ref[name]; ok {
+			if v, ok := value.As(current.Kind()); ok {
+				value = v
+			}
+		}
+
+		converted[name] = value
+	}
+
+	return converted
+}
+
+// Strings returns the string forms of the values.
+func (m Map) Strings() map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	values := make(map[string]string, len(m))
+	for name, value := range m {
+		values[name] = value.String()
+	}
+
+	return values
+}
@@ -0,0 +1,244 @@
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"gopkg.in/yaml.v3"
+)
+
+// Issue codes reported by Template.
+const (
+	CodeWorkflowMissing      = "workflow_missing"
+	CodeWorkflowInvalid      = "workflow_invalid"
+	CodeDispatchMissing      = "dispatch_trigger_missing"
+	CodeUnknownInput         = "unknown_input"
+	CodeMissingRequiredInput = "missing_required_input"
+	CodeInvalidChoice        = "invalid_choice"
+	CodeInvalidBoolean       = "invalid_boolean"
+)
+
+// ErrNotConnected is returned when the GitHub client is unavailable.
+var ErrNotConnected = errors.New("github client not connected")
+
+// workflowInput is a single workflow_dispatch input declaration.
+type workflowInput struct {
+	Required bool     `yaml:"required"`
+	Type     string   `yaml:"type"`
+	Default  any      `yaml:"default"`
+	Options  []string `yaml:"options"`
+}
+
+// workflowDispatch is the workflow_dispatch trigger of a workflow.
+type workflowDispatch struct {
+	Inputs map[string]*workflowInput `yaml:"inputs"`
+}
+
+// Template checks a template against its workflow definition on the template's
+// ref. It reports a removed workflow or dispatch trigger, template inputs the
+// workflow no longer declares (renamed or removed inputs), required inputs the
+// template does not set, and defaults that do not match the input type.
+func Template(ctx context.Context, gh github.Client, tmpl *store.JobTemplate) (*store.TemplateLint, error) {
+	if gh == nil || !gh.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result := &store.TemplateLint{
+		TemplateID: tmpl.ID,
+		Ref:        tmpl.Ref,
+		Issues:     []*store.LintIssue{},
+		CheckedAt:  time.Now(),
+	}
+
+	file, err := gh.GetWorkflowFile(ctx, tmpl.Owner, tmpl.Repo, tmpl.WorkflowID, tmpl.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("fetching workflow: %w", err)
+	}
+
+	if file == nil {
+		addIssue(result, store.LintSeverityError, CodeWorkflowMissing, "",
+			fmt.Sprintf("workflow %s not found in %s/%s on ref %s",
+				tmpl.WorkflowID, tmpl.Owner, tmpl.Repo, tmpl.Ref))
+
+		return finish(result), nil
+	}
+
+	result.WorkflowPath = file.Path
+
+	dispatch, found, err := parseDispatch(file.Content)
+	if err != nil {
+		addIssue(result, store.LintSeverityError, CodeWorkflowInvalid, "",
+			fmt.Sprintf("failed to parse workflow: %v", err))
+
+		return finish(result), nil
+	}
+
+	if !found {
+		addIssue(result, store.LintSeverityError, CodeDispatchMissing, "",
+			"workflow has no workflow_dispatch trigger")
+
+		return finish(result), nil
+	}
+
+	declared := dispatch.Inputs
+	if declared == nil {
+		declared = map[string]*workflowInput{}
+	}
+
+	for _, name := range sortedKeys(tmpl.DefaultInputs) {
+		input, ok := declared[name]
+		if !ok {
+			addIssue(result, store.LintSeverityError, CodeUnknownInput, name,
+				fmt.Sprintf("input %q is not declared by the workflow (renamed or removed?)", name))
+
+			continue
+		}
+
+		if input == nil {
+			continue
+		}
+
+		value := tmpl.DefaultInputs[name].String()
+
+		switch input.Type {
+		case "choice":
+			if len(input.Options) > 0 && !contains(input.Options, value) {
+				addIssue(result, store.LintSeverityError, CodeInvalidChoice, name,
+					fmt.Sprintf("value %q is not one of: %s", value, strings.Join(input.Options, ", ")))
+			}
+		case "boolean":
+			if value != "true" && value != "false" {
+				addIssue(result, store.LintSeverityError, CodeInvalidBoolean, name,
+					fmt.Sprintf("value %q is not a boolean", value))
+			}
+		}
+	}
+
+	names := make([]string, 0, len(declared))
+	for name := range declared {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		input := declared[name]
+		if input == nil || !input.Required || input.Default != nil {
+			continue
+		}
+
+		if _, ok := tmpl.DefaultInputs[name]; ok {
+			continue
+		}
+
+		// Jobs can still supply the input at enqueue time, so this is only a warning.
+		addIssue(result, store.LintSeverityWarning, CodeMissingRequiredInput, name,
+			fmt.Sprintf("required input %q has no default in the workflow or the template", name))
+	}
+
+	return finish(result), nil
+}
+
+// DeclaredInputs returns the names of the inputs declared by a workflow's
+// workflow_dispatch trigger, and false if it has no such trigger.
+func DeclaredInputs(content []byte) (map[string]bool, bool, error) {
+	dispatch, found, err := parseDispatch(content)
+	if err != nil || !found {
+		return nil, found, err
+	}
+
+	declared := make(map[string]bool, len(dispatch.Inputs))
+	for name := range dispatch.Inputs {
+		declared[name] = true
+	}
+
+	return declared, true, nil
+}
+
+// parseDispatch extracts the workflow_dispatch trigger from a workflow file.
+// The trigger may be given as a string, a list of events or a mapping.
+func parseDispatch(content []byte) (*workflowDispatch, bool, error) {
+	var wf struct {
+		On yaml.Node `yaml:"on"`
+	}
+
+	if err := yaml.Unmarshal(content, &wf); err != nil {
+		return nil, false, err
+	}
+
+	switch wf.On.Kind {
+	case yaml.ScalarNode:
+		return &workflowDispatch{}, wf.On.Value == "workflow_dispatch", nil
+	case yaml.SequenceNode:
+		for _, event := range wf.On.Content {
+			if event.Value == "workflow_dispatch" {
+				return &workflowDispatch{}, true, nil
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(wf.On.Content); i += 2 {
+			if wf.On.Content[i].Value != "workflow_dispatch" {
+				continue
+			}
+
+			dispatch := &workflowDispatch{}
+			if err := wf.On.Content[i+1].Decode(dispatch); err != nil {
+				return nil, false, fmt.Errorf("decoding workflow_dispatch: %w", err)
+			}
+
+			return dispatch, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// addIssue appends an issue to the lint result.
+func addIssue(result *store.TemplateLint, severity store.LintSeverity, code, input, message string) {
+	result.Issues = append(result.Issues, &store.LintIssue{
+		Severity: severity,
+		Code:     code,
+		Input:    input,
+		Message:  message,
+	})
+}
+
+// finish sets OK on the result based on its issues.
+func finish(result *store.TemplateLint) *store.TemplateLint {
+	result.OK = true
+
+	for _, issue := range result.Issues {
+		if issue.Severity == store.LintSeverityError {
+			result.OK = false
+		}
+	}
+
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,132 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// Service periodically lints every template and stores the results.
+type Service interface {
+	Start(ctx context.Context) error
+	Stop() error
+}
+
+// service implements Service.
+type service struct {
+	log    logrus.FieldLogger
+	cfg    *config.Config
+	store  store.Store
+	github github.Client
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Ensure service implements Service.
+var _ Service = (*service)(nil)
+
+// NewService creates a new template lint service.
+func NewService(log logrus.FieldLogger, cfg *config.Config, st store.Store, gh github.Client) Service {
+	return &service{
+		log:    log.WithField("component", "lint"),
+		cfg:    cfg,
+		store:  st,
+		github: gh,
+		done:   make(chan struct{}),
+	}
+}
+
+// Start begins the lint loop.
+func (s *service) Start(ctx context.Context) error {
+	s.log.WithField("interval", s.cfg.Lint.Interval).Info("Starting template lint service")
+
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	go s.run(ctx)
+
+	return nil
+}
+
+// Stop stops the lint loop.
+func (s *service) Stop() error {
+	s.log.Info("Stopping template lint service")
+
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+
+	return nil
+}
+
+// run lints all templates every interval.
+func (s *service) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.Lint.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.lintAll(ctx); err != nil {
+				s.log.WithError(err).Error("Failed to lint templates")
+			}
+		}
+	}
+}
+
+// lintAll lints every template and stores the results.
+func (s *service) lintAll(ctx context.Context) error {
+	groups, err := s.store.ListGroups(ctx)
+	if err != nil {
+		return fmt.Errorf("listing groups: %w", err)
+	}
+
+	var checked, failed int
+
+	for _, group := range groups {
+		templates, err := s.store.ListJobTemplatesByGroup(ctx, group.ID)
+		if err != nil {
+			return fmt.Errorf("listing templates for group %s: %w", group.ID, err)
+		}
+
+		for _, tmpl := range templates {
+			result, err := Template(ctx, s.github, tmpl)
+			if err != nil {
+				s.log.WithError(err).WithField("template_id", tmpl.ID).Warn("Failed to lint template")
+
+				continue
+			}
+
+			if err := s.store.UpsertTemplateLint(ctx, result); err != nil {
+				return fmt.Errorf("storing lint result for template %s: %w", tmpl.ID, err)
+			}
+
+			checked++
+
+			if !result.OK {
+				failed++
+
+				s.log.WithFields(logrus.Fields{
+					"template_id": tmpl.ID,
+					"issues":      len(result.Issues),
+				}).Warn("Template lint found errors")
+			}
+		}
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"checked": checked,
+		"failed":  failed,
+	}).Info("Linted templates")
+
+	return nil
+}
@@ -0,0 +1,147 @@
+// Package maintenance runs periodic database maintenance, so space freed by
+// pruned jobs is reclaimed and planner statistics stay current on long-running
+// installs.
+package maintenance
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// Status describes the maintenance schedule and the outcome of the last run.
+type Status struct {
+	Interval  time.Duration
+	NextRunAt time.Time
+	// LastRunAt is nil until the first run has finished.
+	LastRunAt *time.Time
+	Duration  time.Duration
+	Results   []*store.MaintenanceResult
+	Error     string
+}
+
+// Service periodically runs store maintenance and records the last outcome.
+type Service interface {
+	Start(ctx context.Context) error
+	Stop() error
+	Status() Status
+}
+
+// service implements Service.
+type service struct {
+	log    logrus.FieldLogger
+	cfg    *config.MaintenanceConfig
+	store  store.Store
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// Ensure service implements Service.
+var _ Service = (*service)(nil)
+
+// NewService creates a new maintenance scheduler.
+func NewService(log logrus.FieldLogger, cfg *config.Config, st store.Store) Service {
+	return &service{
+		log:    log.WithField("component", "maintenance"),
+		cfg:    &cfg.Database.Maintenance,
+		store:  st,
+		done:   make(chan struct{}),
+		status: Status{Interval: cfg.Database.Maintenance.Interval},
+	}
+}
+
+// Start begins the maintenance loop. The first run happens one interval after
+// startup, so restarts do not vacuum the database every time.
+func (s *service) Start(ctx context.Context) error {
+	s.log.WithFields(logrus.Fields{
+		"interval": s.cfg.Interval,
+		"tasks":    s.cfg.Tasks,
+	}).Info("Starting database maintenance scheduler")
+
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.mu.Lock()
+	s.status.NextRunAt = time.Now().Add(s.cfg.Interval)
+	s.mu.Unlock()
+
+	go s.run(ctx)
+
+	return nil
+}
+
+// Stop stops the maintenance loop, waiting for a running pass to finish.
+func (s *service) Stop() error {
+	s.log.Info("Stopping database maintenance scheduler")
+
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+
+	return nil
+}
+
+// Status returns the schedule and the outcome of the last run.
+func (s *service) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.status
+}
+
+// run performs maintenance one interval after the previous run finished.
+func (s *service) run(ctx context.Context) {
+	defer close(s.done)
+
+	timer := time.NewTimer(s.cfg.Interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			s.runOnce(ctx)
+			timer.Reset(s.cfg.Interval)
+		}
+	}
+}
+
+// runOnce runs the configured tasks and records the outcome.
+func (s *service) runOnce(ctx context.Context) {
+	start := time.Now()
+
+	results, err := s.store.RunMaintenance(ctx, store.MaintenanceOpts{
+		Vacuum:  s.cfg.HasTask(config.MaintenanceTaskVacuum),
+		Analyze: s.cfg.HasTask(config.MaintenanceTaskAnalyze),
+	})
+
+	finished := time.Now()
+	log := s.log.WithField("duration", finished.Sub(start).String())
+
+	status := Status{
+		Interval:  s.cfg.Interval,
+		NextRunAt: finished.Add(s.cfg.Interval),
+		LastRunAt: &finished,
+		Duration:  finished.Sub(start),
+		Results:   results,
+	}
+
+	if err != nil {
+		status.Error = err.Error()
+		log.WithError(err).Error("Database maintenance failed")
+	} else {
+		log.WithField("tasks", len(results)).Info("Database maintenance completed")
+	}
+
+	s.mu.Lock()
+	s.status = status
+	s.mu.Unlock()
+}
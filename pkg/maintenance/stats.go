@@ -0,0 +1,108 @@
+package maintenance
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/metrics"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// StatsSampler periodically exports the database and table sizes as metrics,
+// so growth of the jobs and audit tables shows up long before it hurts.
+type StatsSampler interface {
+	Start(ctx context.Context) error
+	Stop() error
+}
+
+// statsSampler implements StatsSampler.
+type statsSampler struct {
+	log      logrus.FieldLogger
+	interval time.Duration
+	store    store.Store
+	metrics  *metrics.Metrics
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// Ensure statsSampler implements StatsSampler.
+var _ StatsSampler = (*statsSampler)(nil)
+
+// NewStatsSampler creates a new database stats sampler.
+func NewStatsSampler(log logrus.FieldLogger, cfg *config.Config, st store.Store, m *metrics.Metrics) StatsSampler {
+	return &statsSampler{
+		log:      log.WithField("component", "database_stats"),
+		interval: cfg.Database.StatsInterval,
+		store:    st,
+		metrics:  m,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start samples once right away and then every interval.
+func (s *statsSampler) Start(ctx context.Context) error {
+	s.log.WithField("interval", s.interval).Info("Starting database stats sampler")
+
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	go s.run(ctx)
+
+	return nil
+}
+
+// Stop stops the sampling loop.
+func (s *statsSampler) Stop() error {
+	s.log.Info("Stopping database stats sampler")
+
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+
+	return nil
+}
+
+// run samples every interval.
+func (s *statsSampler) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.sample(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sample exports the current database stats.
+func (s *statsSampler) sample(ctx context.Context) {
+	stats, err := s.store.DatabaseStats(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.log.WithError(err).Warn("Failed to read database stats")
+		}
+
+		return
+	}
+
+	s.metrics.SetStoreDatabaseSize(float64(stats.SizeBytes))
+
+	for _, table := range stats.Tables {
+		var size *float64
+
+		if table.SizeBytes != nil {
+			bytes := float64(*table.SizeBytes)
+			size = &bytes
+		}
+
+		s.metrics.SetStoreTableStats(table.Table, float64(table.Rows), size)
+	}
+}
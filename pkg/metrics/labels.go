@@ -0,0 +1,65 @@
+package metrics
+
+import "sync"
+
+const (
+	// OtherLabelValue replaces label values dropped by the cardinality guard.
+	OtherLabelValue = "other"
+	// ManualTemplateLabel is the template label of jobs without a template.
+	ManualTemplateLabel = "manual"
+	// DefaultMaxLabelValues is the default number of distinct values kept per
+	// job metric label.
+	DefaultMaxLabelValues = 100
+)
+
+// labelGuard bounds the distinct values of a metric label. With an allow-list
+// only listed values are kept; otherwise the first max values seen are kept.
+// Everything else is reported as OtherLabelValue.
+type labelGuard struct {
+	mu    sync.Mutex
+	allow map[string]struct{}
+	seen  map[string]struct{}
+	max   int
+}
+
+func newLabelGuard(allow []string, maxValues int) *labelGuard {
+	g := &labelGuard{
+		seen: make(map[string]struct{}),
+		max:  maxValues,
+	}
+
+	if len(allow) > 0 {
+		g.allow = make(map[string]struct{}, len(allow))
+		for _, v := range allow {
+			g.allow[v] = struct{}{}
+		}
+	}
+
+	return g
+}
+
+// value returns the label value to report for v.
+func (g *labelGuard) value(v string) string {
+	if g.allow != nil {
+		if _, ok := g.allow[v]; ok {
+			return v
+		}
+
+		return OtherLabelValue
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.seen[v]; ok {
+		return v
+	}
+
+	if g.max > 0 && len(g.seen) >= g.max {
+		return OtherLabelValue
+	}
+
+	g.seen[v] = struct{}{}
+
+	return v
+}
@@ -16,8 +16,12 @@ type Metrics struct {
 	JobsFailed    *prometheus.CounterVec
 	JobsCancelled *prometheus.CounterVec
 
+	// History.
+	HistoryJobsPruned *prometheus.CounterVec
+
 	// Queue.
-	QueueSize *prometheus.GaugeVec
+	QueueSize             *prometheus.GaugeVec
+	QueueOldestPendingAge *prometheus.GaugeVec
 
 	// Runners.
 	RunnersTotal  *prometheus.GaugeVec
@@ -33,14 +37,29 @@ type Metrics struct {
 	DispatcherDispatchesTotal prometheus.Counter
 	DispatcherErrorsTotal     prometheus.Counter
 	DispatcherLastCycleTime   prometheus.Gauge
+	DispatcherCycleDuration   prometheus.Histogram
+	DispatcherCycleGroups     prometheus.Histogram
+	DispatcherCycleJobs       prometheus.Histogram
 
 	// GitHub API.
 	GitHubAPIRequestsTotal   *prometheus.CounterVec
 	GitHubAPIErrorsTotal     *prometheus.CounterVec
 	GitHubRateLimitRemaining prometheus.Gauge
+	GitHubAPIQueueDepth      *prometheus.GaugeVec
+
+	// Store.
+	StoreQueryDuration    *prometheus.HistogramVec
+	StoreQueryErrorsTotal *prometheus.CounterVec
+	StoreDatabaseSize     prometheus.Gauge
+	StoreTableRows        *prometheus.GaugeVec
+	StoreTableSize        *prometheus.GaugeVec
 
 	// Build info.
 	BuildInfo *prometheus.GaugeVec
+
+	// jobGroups and jobTemplates bound the group and template labels of job metrics.
+	jobGroups    *labelGuard
+	jobTemplates *labelGuard
 }
 
 // New creates a new Metrics instance and registers all metrics.
@@ -53,7 +72,7 @@ func New() *Metrics {
 				Name:      "jobs_created_total",
 				Help:      "Total number of jobs created",
 			},
-			[]string{"group"},
+			[]string{"group", "template"},
 		),
 		JobsTriggered: promauto.NewCounterVec(
 			prometheus.CounterOpts{
@@ -61,7 +80,7 @@ func New() *Metrics {
 				Name:      "jobs_triggered_total",
 				Help:      "Total number of jobs triggered",
 			},
-			[]string{"group"},
+			[]string{"group", "template"},
 		),
 		JobsCompleted: promauto.NewCounterVec(
 			prometheus.CounterOpts{
@@ -69,7 +88,7 @@ func New() *Metrics {
 				Name:      "jobs_completed_total",
 				Help:      "Total number of jobs completed successfully",
 			},
-			[]string{"group"},
+			[]string{"group", "template"},
 		),
 		JobsFailed: promauto.NewCounterVec(
 			prometheus.CounterOpts{
@@ -77,7 +96,7 @@ func New() *Metrics {
 				Name:      "jobs_failed_total",
 				Help:      "Total number of jobs failed",
 			},
-			[]string{"group"},
+			[]string{"group", "template"},
 		),
 		JobsCancelled: promauto.NewCounterVec(
 			prometheus.CounterOpts{
@@ -85,7 +104,17 @@ func New() *Metrics {
 				Name:      "jobs_cancelled_total",
 				Help:      "Total number of jobs cancelled",
 			},
-			[]string{"group"},
+			[]string{"group", "template"},
+		),
+
+		// History.
+		HistoryJobsPruned: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "history_jobs_pruned_total",
+				Help:      "Total number of finished jobs deleted from history",
+			},
+			[]string{"reason"},
 		),
 
 		// Queue.
@@ -97,6 +126,14 @@ func New() *Metrics {
 			},
 			[]string{"group", "status"},
 		),
+		QueueOldestPendingAge: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "queue_oldest_pending_age_seconds",
+				Help:      "Age of the oldest pending job in the queue (0 when empty)",
+			},
+			[]string{"group"},
+		),
 
 		// Runners.
 		RunnersTotal: promauto.NewGaugeVec(
@@ -172,6 +209,30 @@ func New() *Metrics {
 				Help:      "Timestamp of the last dispatcher cycle",
 			},
 		),
+		DispatcherCycleDuration: promauto.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "dispatcher_cycle_duration_seconds",
+				Help:      "Duration of dispatcher cycles in seconds",
+				Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+			},
+		),
+		DispatcherCycleGroups: promauto.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "dispatcher_cycle_groups",
+				Help:      "Number of groups considered per dispatcher cycle",
+				Buckets:   []float64{0, 1, 2, 5, 10, 20, 50, 100},
+			},
+		),
+		DispatcherCycleJobs: promauto.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "dispatcher_cycle_jobs",
+				Help:      "Number of pending jobs considered per dispatcher cycle",
+				Buckets:   []float64{0, 1, 5, 10, 25, 50, 100, 250, 500, 1000},
+			},
+		),
 
 		// GitHub API.
 		GitHubAPIRequestsTotal: promauto.NewCounterVec(
@@ -197,6 +258,56 @@ func New() *Metrics {
 				Help:      "Remaining GitHub API rate limit",
 			},
 		),
+		GitHubAPIQueueDepth: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "github_api_queue_depth",
+				Help:      "GitHub API requests waiting for the client's rate limiter",
+			},
+			[]string{"client"},
+		),
+
+		// Store.
+		StoreQueryDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "store_query_duration_seconds",
+				Help:      "Store method duration in seconds",
+				Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+			},
+			[]string{"method"},
+		),
+		StoreQueryErrorsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "store_query_errors_total",
+				Help:      "Total number of failed store method calls",
+			},
+			[]string{"method"},
+		),
+		StoreDatabaseSize: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "store_database_size_bytes",
+				Help:      "Size of the database in bytes",
+			},
+		),
+		StoreTableRows: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "store_table_rows",
+				Help:      "Rows per table (estimated for PostgreSQL)",
+			},
+			[]string{"table"},
+		),
+		StoreTableSize: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "store_table_size_bytes",
+				Help:      "On-disk size per table in bytes, including indexes",
+			},
+			[]string{"table"},
+		),
 
 		// Build info.
 		BuildInfo: promauto.NewGaugeVec(
@@ -207,39 +318,68 @@ func New() *Metrics {
 			},
 			[]string{"version", "commit", "date"},
 		),
+
+		jobGroups:    newLabelGuard(nil, DefaultMaxLabelValues),
+		jobTemplates: newLabelGuard(nil, DefaultMaxLabelValues),
 	}
 
 	return m
 }
 
+// SetJobLabelLimits bounds the group and template labels of job metrics. Only
+// IDs in a non-empty allow-list are reported; without one, the first maxValues
+// IDs seen are. Other IDs are aggregated into "other". It must be called before
+// any job metrics are recorded.
+func (m *Metrics) SetJobLabelLimits(groups, templates []string, maxValues int) {
+	m.jobGroups = newLabelGuard(groups, maxValues)
+	m.jobTemplates = newLabelGuard(templates, maxValues)
+}
+
+// jobLabels returns the bounded group and template label values for a job.
+func (m *Metrics) jobLabels(group, template string) (string, string) {
+	if template == "" {
+		template = ManualTemplateLabel
+	} else {
+		template = m.jobTemplates.value(template)
+	}
+
+	return m.jobGroups.value(group), template
+}
+
 // SetBuildInfo sets the build info metric.
 func (m *Metrics) SetBuildInfo(version, commit, date string) {
 	m.BuildInfo.WithLabelValues(version, commit, date).Set(1)
 }
 
 // RecordJobCreated increments the jobs created counter.
-func (m *Metrics) RecordJobCreated(group string) {
-	m.JobsCreated.WithLabelValues(group).Inc()
+func (m *Metrics) RecordJobCreated(group, template string) {
+	m.JobsCreated.WithLabelValues(m.jobLabels(group, template)).Inc()
 }
 
 // RecordJobTriggered increments the jobs triggered counter.
-func (m *Metrics) RecordJobTriggered(group string) {
-	m.JobsTriggered.WithLabelValues(group).Inc()
+func (m *Metrics) RecordJobTriggered(group, template string) {
+	m.JobsTriggered.WithLabelValues(m.jobLabels(group, template)).Inc()
 }
 
 // RecordJobCompleted increments the jobs completed counter.
-func (m *Metrics) RecordJobCompleted(group string) {
-	m.JobsCompleted.WithLabelValues(group).Inc()
+func (m *Metrics) RecordJobCompleted(group, template string) {
+	m.JobsCompleted.WithLabelValues(m.jobLabels(group, template)).Inc()
 }
 
 // RecordJobFailed increments the jobs failed counter.
-func (m *Metrics) RecordJobFailed(group string) {
-	m.JobsFailed.WithLabelValues(group).Inc()
+func (m *Metrics) RecordJobFailed(group, template string) {
+	m.JobsFailed.WithLabelValues(m.jobLabels(group, template)).Inc()
 }
 
 // RecordJobCancelled increments the jobs cancelled counter.
-func (m *Metrics) RecordJobCancelled(group string) {
-	m.JobsCancelled.WithLabelValues(group).Inc()
+func (m *Metrics) RecordJobCancelled(group, template string) {
+	m.JobsCancelled.WithLabelValues(m.jobLabels(group, template)).Inc()
+}
+
+// RecordJobsPruned records finished jobs deleted from history, by reason
+// ("retention" or "max_jobs").
+func (m *Metrics) RecordJobsPruned(reason string, count int64) {
+	m.HistoryJobsPruned.WithLabelValues(reason).Add(float64(count))
 }
 
 // SetQueueSize sets the queue size gauge.
@@ -247,6 +387,11 @@ func (m *Metrics) SetQueueSize(group, status string, size float64) {
 	m.QueueSize.WithLabelValues(group, status).Set(size)
 }
 
+// SetOldestPendingAge sets the oldest pending job age gauge.
+func (m *Metrics) SetOldestPendingAge(group string, seconds float64) {
+	m.QueueOldestPendingAge.WithLabelValues(group).Set(seconds)
+}
+
 // SetRunnerCounts sets runner count gauges.
 func (m *Metrics) SetRunnerCounts(group string, total, online, busy float64) {
 	m.RunnersTotal.WithLabelValues(group).Set(total)
@@ -260,10 +405,14 @@ func (m *Metrics) RecordHTTPRequest(method, path, status string, duration float6
 	m.HTTPRequestDuration.WithLabelValues(method, path).Observe(duration)
 }
 
-// RecordDispatcherCycle records a dispatcher cycle.
-func (m *Metrics) RecordDispatcherCycle() {
+// RecordDispatcherCycle records a dispatcher cycle with its duration and the
+// number of groups and pending jobs it considered.
+func (m *Metrics) RecordDispatcherCycle(duration float64, groups, jobs int) {
 	m.DispatcherCyclesTotal.Inc()
 	m.DispatcherLastCycleTime.SetToCurrentTime()
+	m.DispatcherCycleDuration.Observe(duration)
+	m.DispatcherCycleGroups.Observe(float64(groups))
+	m.DispatcherCycleJobs.Observe(float64(jobs))
 }
 
 // RecordDispatch records a successful dispatch.
@@ -290,3 +439,32 @@ func (m *Metrics) RecordGitHubAPIError(endpoint string) {
 func (m *Metrics) SetGitHubRateLimit(remaining float64) {
 	m.GitHubRateLimitRemaining.Set(remaining)
 }
+
+// AddGitHubAPIQueueDepth adjusts the number of requests waiting for a GitHub
+// client's rate limiter.
+func (m *Metrics) AddGitHubAPIQueueDepth(client string, delta float64) {
+	m.GitHubAPIQueueDepth.WithLabelValues(client).Add(delta)
+}
+
+// RecordStoreQuery records the duration of a store method call.
+func (m *Metrics) RecordStoreQuery(method string, duration float64, failed bool) {
+	m.StoreQueryDuration.WithLabelValues(method).Observe(duration)
+
+	if failed {
+		m.StoreQueryErrorsTotal.WithLabelValues(method).Inc()
+	}
+}
+
+// SetStoreDatabaseSize sets the size of the database.
+func (m *Metrics) SetStoreDatabaseSize(bytes float64) {
+	m.StoreDatabaseSize.Set(bytes)
+}
+
+// SetStoreTableStats sets a table's row count and, when known, its size.
+func (m *Metrics) SetStoreTableStats(table string, rows float64, sizeBytes *float64) {
+	m.StoreTableRows.WithLabelValues(table).Set(rows)
+
+	if sizeBytes != nil {
+		m.StoreTableSize.WithLabelValues(table).Set(*sizeBytes)
+	}
+}
@@ -0,0 +1,333 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// transitionBuffer bounds the transitions waiting for delivery. Further
+	// transitions are dropped, so a slow channel cannot hold up the queue.
+	transitionBuffer = 256
+	// deliveryTimeout bounds each Slack or webhook request.
+	deliveryTimeout = 10 * time.Second
+)
+
+// Service delivers the state transitions of jobs to the users subscribed to
+// the job or its template.
+type Service interface {
+	Start(ctx context.Context) error
+	Stop() error
+	// JobTransition queues the notifications of a job that moved from one
+	// status into its current one. It never blocks.
+	JobTransition(job *store.Job, from store.JobStatus)
+}
+
+// transition is a job state change waiting for delivery.
+type transition struct {
+	job  store.Job
+	from store.JobStatus
+}
+
+// service implements Service.
+type service struct {
+	log         logrus.FieldLogger
+	cfg         *config.Config
+	store       store.Store
+	client      *http.Client
+	transitions chan transition
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// Ensure service implements Service.
+var _ Service = (*service)(nil)
+
+// NewService creates a new notification service.
+func NewService(log logrus.FieldLogger, cfg *config.Config, st store.Store) Service {
+	return &service{
+		log:         log.WithField("component", "notify"),
+		cfg:         cfg,
+		store:       st,
+		client:      &http.Client{Timeout: deliveryTimeout},
+		transitions: make(chan transition, transitionBuffer),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start begins delivering notifications.
+func (s *service) Start(ctx context.Context) error {
+	s.log.Info("Starting notification service")
+
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	go s.run(ctx)
+
+	return nil
+}
+
+// Stop stops delivering notifications. Transitions still queued are dropped.
+func (s *service) Stop() error {
+	s.log.Info("Stopping notification service")
+
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+
+	return nil
+}
+
+// JobTransition queues the notifications of a job transition.
+func (s *service) JobTransition(job *store.Job, from store.JobStatus) {
+	select {
+	case s.transitions <- transition{job: *job, from: from}:
+	default:
+		s.log.WithFields(logrus.Fields{
+			"job_id": job.ID,
+			"status": job.Status,
+		}).Warn("Notification buffer full, dropping job transition")
+	}
+}
+
+// run delivers queued transitions until ctx is cancelled.
+func (s *service) run(ctx context.Context) {
+	defer close(s.done)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-s.transitions:
+			s.deliver(ctx, &t)
+		}
+	}
+}
+
+// deliver sends a transition to every matching subscription of the job and
+// its template, and to the job's own notify targets. Subscriptions to a job
+// are removed once it has finished.
+func (s *service) deliver(ctx context.Context, t *transition) {
+	log := s.log.WithField("job_id", t.job.ID)
+
+	subs, err := s.store.ListSubscriptionsByTarget(ctx, store.SubscriptionTargetJob, t.job.ID)
+	if err != nil {
+		log.WithError(err).Warn("Failed to list job subscriptions")
+
+		return
+	}
+
+	if t.job.TemplateID != "" {
+		templateSubs, err := s.store.ListSubscriptionsByTarget(ctx, store.SubscriptionTargetTemplate, t.job.TemplateID)
+		if err != nil {
+			log.WithError(err).Warn("Failed to list template subscriptions")
+
+			return
+		}
+
+		subs = append(subs, templateSubs...)
+	}
+
+	// Notify targets are delivered like subscriptions without an owner.
+	for _, target := range t.job.NotifyTargets {
+		subs = append(subs, &store.Subscription{
+			TargetType:  store.SubscriptionTargetJob,
+			TargetID:    t.job.ID,
+			Channel:     target.Channel,
+			Destination: target.Destination,
+			Statuses:    target.Statuses,
+		})
+	}
+
+	// A destination following both a job and its template is notified once.
+	sent := make(map[string]struct{}, len(subs))
+
+	for _, sub := range subs {
+		if len(sub.Statuses) > 0 && !slices.Contains(sub.Statuses, t.job.Status) {
+			continue
+		}
+
+		key := string(sub.Channel) + "|" + sub.Destination
+		if _, ok := sent[key]; ok {
+			continue
+		}
+
+		sent[key] = struct{}{}
+
+		if err := s.send(ctx, sub, t); err != nil {
+			log.WithError(err).WithFields(logrus.Fields{
+				"subscription_id": sub.ID,
+				"channel":         sub.Channel,
+			}).Warn("Failed to deliver notification")
+		}
+	}
+
+	switch t.job.Status {
+	case store.JobStatusCompleted, store.JobStatusFailed, store.JobStatusCancelled:
+		if err := s.store.DeleteSubscriptionsByTarget(ctx, store.SubscriptionTargetJob, t.job.ID); err != nil {
+			log.WithError(err).Warn("Failed to delete subscriptions of finished job")
+		}
+	}
+}
+
+// send delivers a transition to one subscription.
+func (s *service) send(ctx context.Context, sub *store.Subscription, t *transition) error {
+	switch sub.Channel {
+	case store.SubscriptionChannelSlack:
+		return s.post(ctx, sub.Destination, map[string]string{"text": message(t)})
+	case store.SubscriptionChannelWebhook:
+		return s.post(ctx, sub.Destination, newWebhookPayload(sub, t))
+	case store.SubscriptionChannelEmail:
+		return s.sendEmail(sub.Destination, t)
+	default:
+		return fmt.Errorf("unknown channel %q", sub.Channel)
+	}
+}
+
+// WebhookPayload is the JSON body posted to webhook subscriptions. Job inputs
+// are left out, as they may hold secrets. SubscriptionID is empty for a job's
+// notify targets.
+type WebhookPayload struct {
+	SubscriptionID string          `json:"subscription_id,omitempty"`
+	JobID          string          `json:"job_id"`
+	GroupID        string          `json:"group_id"`
+	TemplateID     string          `json:"template_id,omitempty"`
+	Name           string          `json:"name,omitempty"`
+	FromStatus     store.JobStatus `json:"from_status,omitempty"`
+	Status         store.JobStatus `json:"status"`
+	RunURL         string          `json:"run_url,omitempty"`
+	ErrorMessage   string          `json:"error_message,omitempty"`
+	Timestamp      time.Time       `json:"timestamp"`
+}
+
+// newWebhookPayload builds the webhook body of a transition.
+func newWebhookPayload(sub *store.Subscription, t *transition) *WebhookPayload {
+	payload := &WebhookPayload{
+		SubscriptionID: sub.ID,
+		JobID:          t.job.ID,
+		GroupID:        t.job.GroupID,
+		TemplateID:     t.job.TemplateID,
+		FromStatus:     t.from,
+		Status:         t.job.Status,
+		RunURL:         t.job.RunURL,
+		ErrorMessage:   t.job.ErrorMessage,
+		Timestamp:      time.Now(),
+	}
+
+	if t.job.Name != nil {
+		payload.Name = *t.job.Name
+	}
+
+	return payload
+}
+
+// post sends body as JSON to url and expects a 2xx response.
+func (s *service) post(ctx context.Context, url string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshaling body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// sendEmail mails a transition to address through the configured SMTP server.
+func (s *service) sendEmail(address string, t *transition) error {
+	smtpCfg := s.cfg.Notifications.Email
+	if smtpCfg.Host == "" {
+		return fmt.Errorf("notifications.email is not configured")
+	}
+
+	var auth smtp.Auth
+	if smtpCfg.Username != "" {
+		auth = smtp.PlainAuth("", smtpCfg.Username, smtpCfg.Password, smtpCfg.Host)
+	}
+
+	var msg strings.Builder
+
+	fmt.Fprintf(&msg, "From: %s\r\n", smtpCfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", address)
+	fmt.Fprintf(&msg, "Subject: [dispatchoor] %s is %s\r\n", headerValue(jobLabel(&t.job)), t.job.Status)
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(message(t))
+	msg.WriteString("\r\n")
+
+	addr := net.JoinHostPort(smtpCfg.Host, strconv.Itoa(smtpCfg.Port))
+
+	if err := smtp.SendMail(addr, auth, smtpCfg.From, []string{address}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("sending email: %w", err)
+	}
+
+	return nil
+}
+
+// headerValue strips line breaks from a value written into a mail header.
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
+
+// message is the plain text description of a transition.
+func message(t *transition) string {
+	var msg strings.Builder
+
+	fmt.Fprintf(&msg, "Job %s in group %s", jobLabel(&t.job), t.job.GroupID)
+
+	if t.from == "" {
+		fmt.Fprintf(&msg, " was created (%s)", t.job.Status)
+	} else {
+		fmt.Fprintf(&msg, " moved from %s to %s", t.from, t.job.Status)
+	}
+
+	if t.job.ErrorMessage != "" {
+		fmt.Fprintf(&msg, ": %s", t.job.ErrorMessage)
+	}
+
+	if t.job.RunURL != "" {
+		fmt.Fprintf(&msg, "\n%s", t.job.RunURL)
+	}
+
+	return msg.String()
+}
+
+// jobLabel names a job by its name or template, followed by its ID.
+func jobLabel(job *store.Job) string {
+	switch {
+	case job.Name != nil && *job.Name != "":
+		return fmt.Sprintf("%s (%s)", *job.Name, job.ID)
+	case job.TemplateID != "":
+		return fmt.Sprintf("%s (%s)", job.TemplateID, job.ID)
+	default:
+		return job.ID
+	}
+}
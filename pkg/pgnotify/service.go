@@ -0,0 +1,251 @@
+// Package pgnotify shares job, runner and group changes between API replicas
+// on the same PostgreSQL database through LISTEN/NOTIFY, so each replica can
+// feed its WebSocket hub and drop its caches without a separate message bus.
+//
+// Notifications only carry what changed and its ID; receivers load the current
+// state from the database. Delivery is best effort: changes published while a
+// replica's listener is reconnecting are lost, which receivers learn about
+// through a KindResync event.
+package pgnotify
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// Kind is what an event is about.
+type Kind string
+
+const (
+	// KindJob is a job change; ID is the job ID.
+	KindJob Kind = "job"
+	// KindRunner is a runner change; ID is the runner ID.
+	KindRunner Kind = "runner"
+	// KindGroup is a group state change; ID is the group ID.
+	KindGroup Kind = "group"
+	// KindCache is a group or template write that invalidates caches.
+	KindCache Kind = "cache"
+	// KindSettings is a change of the runtime settings.
+	KindSettings Kind = "settings"
+	// KindResync is raised locally when the listener reconnects and may have
+	// missed events.
+	KindResync Kind = "resync"
+)
+
+const (
+	// publishBuffer is how many events may wait to be sent before further
+	// events are dropped.
+	publishBuffer = 1024
+	// publishTimeout bounds sending one notification.
+	publishTimeout = 5 * time.Second
+	// pingInterval is how often an idle listener checks its connection.
+	pingInterval = 90 * time.Second
+)
+
+// Event is a change announced by a replica.
+type Event struct {
+	Kind Kind   `json:"kind"`
+	ID   string `json:"id,omitempty"`
+	// Origin identifies the replica that published the event.
+	Origin string `json:"origin"`
+}
+
+// EventCallback is called for each event published by another replica.
+type EventCallback func(event Event)
+
+// Service publishes this replica's changes and delivers other replicas'.
+type Service interface {
+	Start(ctx context.Context) error
+	Stop() error
+	// Publish announces a change to the other replicas without blocking.
+	Publish(kind Kind, id string)
+	// SetEventCallback sets the function called with other replicas' events.
+	SetEventCallback(cb EventCallback)
+}
+
+// service implements Service.
+type service struct {
+	log      logrus.FieldLogger
+	dsn      string
+	channel  string
+	origin   string
+	db       *sql.DB
+	listener *pq.Listener
+	events   chan Event
+	callback EventCallback
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// Ensure service implements Service.
+var _ Service = (*service)(nil)
+
+// NewService creates a new LISTEN/NOTIFY service for the configured database.
+func NewService(log logrus.FieldLogger, cfg *config.Config) Service {
+	return &service{
+		log:     log.WithField("component", "pgnotify"),
+		dsn:     cfg.GetDSN(),
+		channel: cfg.Database.Notify.Channel,
+		origin:  uuid.New().String(),
+		events:  make(chan Event, publishBuffer),
+		done:    make(chan struct{}),
+	}
+}
+
+// SetEventCallback sets the function called with other replicas' events. It
+// must be called before Start.
+func (s *service) SetEventCallback(cb EventCallback) {
+	s.callback = cb
+}
+
+// Start opens the publishing connection and begins listening.
+func (s *service) Start(ctx context.Context) error {
+	s.log.WithField("channel", s.channel).Info("Starting replica notifications")
+
+	db, err := sql.Open("postgres", s.dsn)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+
+		return fmt.Errorf("pinging database: %w", err)
+	}
+
+	s.db = db
+
+	s.listener = pq.NewListener(s.dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			s.log.WithError(err).Warn("Replica notification listener disconnected")
+		case pq.ListenerEventReconnected:
+			s.log.Info("Replica notification listener reconnected")
+		case pq.ListenerEventConnectionAttemptFailed:
+			s.log.WithError(err).Debug("Replica notification listener failed to reconnect")
+		}
+	})
+
+	if err := s.listener.Listen(s.channel); err != nil {
+		_ = s.listener.Close()
+		_ = db.Close()
+
+		return fmt.Errorf("listening on %s: %w", s.channel, err)
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	go s.run(ctx)
+
+	return nil
+}
+
+// Stop stops listening and publishing.
+func (s *service) Stop() error {
+	s.log.Info("Stopping replica notifications")
+
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+
+	if s.listener != nil {
+		if err := s.listener.Close(); err != nil {
+			s.log.WithError(err).Warn("Failed to close replica notification listener")
+		}
+	}
+
+	if s.db != nil {
+		return s.db.Close()
+	}
+
+	return nil
+}
+
+// Publish queues an event to send, dropping it if the buffer is full.
+func (s *service) Publish(kind Kind, id string) {
+	select {
+	case s.events <- Event{Kind: kind, ID: id, Origin: s.origin}:
+	default:
+		s.log.WithFields(logrus.Fields{"kind": kind, "id": id}).Warn("Dropped replica notification, buffer full")
+	}
+}
+
+// run sends queued events and delivers received ones until ctx is done.
+func (s *service) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-s.events:
+			s.send(ctx, event)
+		case n := <-s.listener.Notify:
+			s.receive(n)
+		case <-ticker.C:
+			go func() {
+				if err := s.listener.Ping(); err != nil {
+					s.log.WithError(err).Debug("Replica notification listener ping failed")
+				}
+			}()
+		}
+	}
+}
+
+// send notifies the other replicas of an event.
+func (s *service) send(ctx context.Context, event Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to encode replica notification")
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, s.channel, string(payload)); err != nil {
+		s.log.WithError(err).WithField("kind", event.Kind).Warn("Failed to send replica notification")
+	}
+}
+
+// receive decodes a notification and hands it to the callback unless this
+// replica sent it. A nil notification means the listener reconnected.
+func (s *service) receive(n *pq.Notification) {
+	if s.callback == nil {
+		return
+	}
+
+	if n == nil {
+		s.callback(Event{Kind: KindResync})
+
+		return
+	}
+
+	var event Event
+	if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+		s.log.WithError(err).Warn("Ignoring malformed replica notification")
+
+		return
+	}
+
+	if event.Origin == s.origin {
+		return
+	}
+
+	s.callback(event)
+}
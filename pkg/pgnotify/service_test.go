@@ -0,0 +1,83 @@
+package pgnotify
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestService(t *testing.T) *service {
+	t.Helper()
+
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	svc, ok := NewService(log, &config.Config{}).(*service)
+	if !ok {
+		t.Fatal("Expected the pgnotify service implementation")
+	}
+
+	return svc
+}
+
+func TestReceive(t *testing.T) {
+	svc := newTestService(t)
+
+	// Without a callback notifications are ignored.
+	svc.receive(nil)
+
+	var received []Event
+
+	svc.SetEventCallback(func(event Event) { received = append(received, event) })
+
+	notification := func(event Event) *pq.Notification {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			t.Fatalf("Failed to encode event: %v", err)
+		}
+
+		return &pq.Notification{Extra: string(payload)}
+	}
+
+	other := Event{Kind: KindJob, ID: "job-1", Origin: "other-replica"}
+
+	svc.receive(notification(Event{Kind: KindJob, ID: "job-2", Origin: svc.origin}))
+	svc.receive(notification(other))
+	svc.receive(&pq.Notification{Extra: "not json"})
+	svc.receive(nil)
+
+	want := []Event{other, {Kind: KindResync}}
+
+	if len(received) != len(want) {
+		t.Fatalf("Expected events %+v, got %+v", want, received)
+	}
+
+	for i := range want {
+		if received[i] != want[i] {
+			t.Errorf("Expected event %d to be %+v, got %+v", i, want[i], received[i])
+		}
+	}
+}
+
+func TestPublishDropsWhenFull(t *testing.T) {
+	svc := newTestService(t)
+
+	svc.Publish(KindCache, "")
+
+	if event := <-svc.events; event != (Event{Kind: KindCache, Origin: svc.origin}) {
+		t.Errorf("Expected the event to carry this replica's origin, got %+v", event)
+	}
+
+	// Nothing sends, so the buffer fills and Publish must not block.
+	for range publishBuffer + 10 {
+		svc.Publish(KindRunner, "1")
+	}
+
+	if queued := len(svc.events); queued != publishBuffer {
+		t.Errorf("Expected %d queued events, got %d", publishBuffer, queued)
+	}
+}
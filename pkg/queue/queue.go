@@ -3,10 +3,17 @@ package queue
 import (
 	"context"
 	"fmt"
+	"maps"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/ethpandaops/dispatchoor/pkg/auth"
 	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/metrics"
 	"github.com/ethpandaops/dispatchoor/pkg/store"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
@@ -15,10 +22,16 @@ import (
 // JobChangeCallback is called when a job state changes.
 type JobChangeCallback func(job *store.Job)
 
+// JobTransitionCallback is called when a job moves from one status into its
+// current one. From is empty when the job was just created.
+type JobTransitionCallback func(job *store.Job, from store.JobStatus)
+
 // EnqueueOptions contains optional parameters for enqueueing a job.
 type EnqueueOptions struct {
 	AutoRequeue  bool
 	RequeueLimit *int
+	// Priority overrides the template's default priority.
+	Priority *int
 	// Manual job fields (used when no template is specified).
 	Name       string
 	Owner      string
@@ -26,17 +39,31 @@ type EnqueueOptions struct {
 	WorkflowID string
 	Ref        string
 	Labels     map[string]string
+	// CampaignID adds the job to a campaign.
+	CampaignID string
+	// MaxDurationSeconds overrides the template's max duration.
+	MaxDurationSeconds *int
+	// NotifyTargets are notified of the job's transitions.
+	NotifyTargets []store.JobNotifyTarget
+	// NotBefore holds the job until the given time.
+	NotBefore *time.Time
+	// SourceIssue is the owner/repo#number issue the job was requested in.
+	SourceIssue string
 }
 
 // UpdateJobOptions contains parameters for updating a job.
 type UpdateJobOptions struct {
-	Inputs     map[string]string
+	Inputs     input.Map
 	Name       *string
 	Owner      *string
 	Repo       *string
 	WorkflowID *string
 	Ref        *string
 	Labels     map[string]string
+	Priority   *int
+	// NotBefore reschedules the job, and ClearNotBefore makes it due now.
+	NotBefore      *time.Time
+	ClearNotBefore bool
 }
 
 // Service defines the interface for queue operations.
@@ -45,11 +72,13 @@ type Service interface {
 	Stop() error
 
 	// Queue operations.
-	Enqueue(ctx context.Context, groupID, templateID, createdBy string, inputs map[string]string, opts *EnqueueOptions) (*store.Job, error)
+	Enqueue(ctx context.Context, groupID, templateID, createdBy string, inputs input.Map, opts *EnqueueOptions) (*store.Job, error)
 	Dequeue(ctx context.Context, groupID string) (*store.Job, error)
 	Peek(ctx context.Context, groupID string) (*store.Job, error)
 	Remove(ctx context.Context, jobID string) error
 	Reorder(ctx context.Context, groupID string, jobIDs []string) error
+	Compact(ctx context.Context, groupID string) (int, error)
+	Requeue(ctx context.Context, jobID, targetGroupID, createdBy string, inputs input.Map) (*store.Job, error)
 
 	// Queries.
 	GetJob(ctx context.Context, jobID string) (*store.Job, error)
@@ -64,14 +93,19 @@ type Service interface {
 	MarkCompleted(ctx context.Context, jobID string) error
 	MarkFailed(ctx context.Context, jobID, errMsg string) error
 	MarkCancelled(ctx context.Context, jobID string) error
+	MarkRerun(ctx context.Context, jobID string, attempt int) error
 
 	// Pause/Unpause.
 	Pause(ctx context.Context, jobID string) (*store.Job, error)
 	Unpause(ctx context.Context, jobID string) (*store.Job, error)
 
 	// Update.
-	UpdateInputs(ctx context.Context, jobID string, inputs map[string]string) error
+	UpdateInputs(ctx context.Context, jobID string, inputs input.Map) error
 	UpdateJob(ctx context.Context, jobID string, opts *UpdateJobOptions) error
+	ReassignOwner(ctx context.Context, jobID, createdBy string) (*store.Job, error)
+	SetRetained(ctx context.Context, jobID string, retained bool) (*store.Job, error)
+	SetAssignee(ctx context.Context, jobID, assignee string) (*store.Job, error)
+	SetNotifyTargets(ctx context.Context, jobID string, targets []store.JobNotifyTarget) (*store.Job, error)
 
 	// Auto-requeue control.
 	DisableAutoRequeue(ctx context.Context, jobID string) (*store.Job, error)
@@ -79,26 +113,42 @@ type Service interface {
 
 	// Callbacks.
 	SetJobChangeCallback(cb JobChangeCallback)
+	SetJobTransitionCallback(cb JobTransitionCallback)
 }
 
 // service implements Service.
 type service struct {
-	log               logrus.FieldLogger
-	cfg               *config.Config
-	store             store.Store
-	mu                sync.Mutex
-	jobChangeCallback JobChangeCallback
+	log                logrus.FieldLogger
+	cfg                *config.Config
+	store              store.Store
+	metrics            *metrics.Metrics
+	mu                 sync.Mutex
+	jobChangeCallback  JobChangeCallback
+	transitionCallback JobTransitionCallback
+
+	// historyCounts caches history total counts by filter, so paging through
+	// a large history does not run COUNT(*) for every page.
+	historyCountsMu sync.Mutex
+	historyCounts   map[string]historyCount
+}
+
+// historyCount is a cached history total count.
+type historyCount struct {
+	count     int
+	expiresAt time.Time
 }
 
 // Ensure service implements Service.
 var _ Service = (*service)(nil)
 
 // NewService creates a new queue service.
-func NewService(log logrus.FieldLogger, cfg *config.Config, st store.Store) Service {
+func NewService(log logrus.FieldLogger, cfg *config.Config, st store.Store, m *metrics.Metrics) Service {
 	return &service{
-		log:   log.WithField("component", "queue"),
-		cfg:   cfg,
-		store: st,
+		log:           log.WithField("component", "queue"),
+		cfg:           cfg,
+		store:         st,
+		metrics:       m,
+		historyCounts: make(map[string]historyCount),
 	}
 }
 
@@ -106,19 +156,57 @@ func NewService(log logrus.FieldLogger, cfg *config.Config, st store.Store) Serv
 func (s *service) Start(ctx context.Context) error {
 	s.log.Info("Starting queue service")
 
-	// Start job cleanup goroutine if retention is enabled.
-	if s.cfg.History.RetentionDays > 0 {
+	// Start job cleanup goroutine if retention or a per-group cap is enabled.
+	if s.cfg.History.RetentionDays > 0 || s.cfg.History.MaxJobsPerGroup > 0 {
 		go s.cleanupOldJobs(ctx)
 	}
 
+	// Start queue compaction goroutine if enabled.
+	if s.cfg.Queue.CompactInterval > 0 {
+		go s.compactQueues(ctx)
+	}
+
 	return nil
 }
 
-// cleanupOldJobs periodically removes old completed/failed/cancelled jobs.
+// compactQueues periodically renumbers the pending job positions of every group.
+func (s *service) compactQueues(ctx context.Context) {
+	s.log.WithField("compact_interval", s.cfg.Queue.CompactInterval).
+		Info("Starting queue compaction goroutine")
+
+	ticker := time.NewTicker(s.cfg.Queue.CompactInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("Stopping queue compaction goroutine")
+
+			return
+		case <-ticker.C:
+			groups, err := s.store.ListGroups(ctx)
+			if err != nil {
+				s.log.WithError(err).Error("Failed to list groups for queue compaction")
+
+				continue
+			}
+
+			for _, group := range groups {
+				if _, err := s.Compact(ctx, group.ID); err != nil {
+					s.log.WithError(err).WithField("group_id", group.ID).Error("Failed to compact queue")
+				}
+			}
+		}
+	}
+}
+
+// cleanupOldJobs periodically removes completed/failed/cancelled jobs that are
+// older than the retention period or beyond the per-group cap.
 func (s *service) cleanupOldJobs(ctx context.Context) {
 	s.log.WithFields(logrus.Fields{
-		"retention_days":   s.cfg.History.RetentionDays,
-		"cleanup_interval": s.cfg.History.CleanupInterval,
+		"retention_days":     s.cfg.History.RetentionDays,
+		"max_jobs_per_group": s.cfg.History.MaxJobsPerGroup,
+		"cleanup_interval":   s.cfg.History.CleanupInterval,
 	}).Info("Starting job history cleanup goroutine")
 
 	ticker := time.NewTicker(s.cfg.History.CleanupInterval)
@@ -131,21 +219,76 @@ func (s *service) cleanupOldJobs(ctx context.Context) {
 
 			return
 		case <-ticker.C:
-			cutoff := time.Now().AddDate(0, 0, -s.cfg.History.RetentionDays)
+			if s.cfg.History.RetentionDays > 0 {
+				s.pruneOldJobs(ctx)
+			}
 
-			count, err := s.store.DeleteOldJobs(ctx, cutoff)
-			if err != nil {
-				s.log.WithError(err).Error("Failed to cleanup old jobs")
-			} else if count > 0 {
-				s.log.WithFields(logrus.Fields{
-					"deleted_count":  count,
-					"retention_days": s.cfg.History.RetentionDays,
-				}).Info("Cleaned up old jobs")
+			if s.cfg.History.MaxJobsPerGroup > 0 {
+				s.pruneExcessJobs(ctx)
 			}
 		}
 	}
 }
 
+// pruneOldJobs deletes finished jobs older than the retention period.
+func (s *service) pruneOldJobs(ctx context.Context) {
+	cutoff := time.Now().AddDate(0, 0, -s.cfg.History.RetentionDays)
+
+	count, err := s.store.DeleteOldJobs(ctx, cutoff)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to cleanup old jobs")
+
+		return
+	}
+
+	if count == 0 {
+		return
+	}
+
+	if s.metrics != nil {
+		s.metrics.RecordJobsPruned("retention", count)
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"deleted_count":  count,
+		"retention_days": s.cfg.History.RetentionDays,
+	}).Info("Cleaned up old jobs")
+}
+
+// pruneExcessJobs deletes the oldest finished jobs of every group beyond
+// history.max_jobs_per_group.
+func (s *service) pruneExcessJobs(ctx context.Context) {
+	groups, err := s.store.ListGroups(ctx)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to list groups for job history cleanup")
+
+		return
+	}
+
+	for _, group := range groups {
+		count, err := s.store.DeleteExcessJobs(ctx, group.ID, s.cfg.History.MaxJobsPerGroup)
+		if err != nil {
+			s.log.WithError(err).WithField("group_id", group.ID).Error("Failed to cleanup excess jobs")
+
+			continue
+		}
+
+		if count == 0 {
+			continue
+		}
+
+		if s.metrics != nil {
+			s.metrics.RecordJobsPruned("max_jobs", count)
+		}
+
+		s.log.WithFields(logrus.Fields{
+			"group_id":           group.ID,
+			"deleted_count":      count,
+			"max_jobs_per_group": s.cfg.History.MaxJobsPerGroup,
+		}).Info("Cleaned up excess jobs")
+	}
+}
+
 // Stop shuts down the queue service.
 func (s *service) Stop() error {
 	s.log.Info("Stopping queue service")
@@ -158,6 +301,11 @@ func (s *service) SetJobChangeCallback(cb JobChangeCallback) {
 	s.jobChangeCallback = cb
 }
 
+// SetJobTransitionCallback sets the callback for job status transitions.
+func (s *service) SetJobTransitionCallback(cb JobTransitionCallback) {
+	s.transitionCallback = cb
+}
+
 // notifyJobChange calls the callback if set.
 func (s *service) notifyJobChange(job *store.Job) {
 	if s.jobChangeCallback != nil {
@@ -165,18 +313,127 @@ func (s *service) notifyJobChange(job *store.Job) {
 	}
 }
 
+// recordJobMetric counts a job that entered its current status.
+func (s *service) recordJobMetric(job *store.Job) {
+	if s.metrics == nil {
+		return
+	}
+
+	switch job.Status {
+	case store.JobStatusPending:
+		s.metrics.RecordJobCreated(job.GroupID, job.TemplateID)
+	case store.JobStatusTriggered:
+		s.metrics.RecordJobTriggered(job.GroupID, job.TemplateID)
+	case store.JobStatusCompleted:
+		s.metrics.RecordJobCompleted(job.GroupID, job.TemplateID)
+	case store.JobStatusFailed:
+		s.metrics.RecordJobFailed(job.GroupID, job.TemplateID)
+	case store.JobStatusCancelled:
+		s.metrics.RecordJobCancelled(job.GroupID, job.TemplateID)
+	}
+}
+
+// recordJobEvent persists the transition of a job from the given status into
+// its current one. The actor is the user in ctx, falling back to fallbackActor
+// and then "system". Failures are logged rather than failing the transition.
+// The transition callback is called either way.
+func (s *service) recordJobEvent(ctx context.Context, job *store.Job, from store.JobStatus, fallbackActor, message string) {
+	event := &store.JobEvent{
+		ID:         uuid.New().String(),
+		JobID:      job.ID,
+		FromStatus: from,
+		ToStatus:   job.Status,
+		Actor:      actorFromContext(ctx, fallbackActor),
+		RunID:      job.RunID,
+		RunnerName: job.RunnerName,
+		Message:    message,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.store.CreateJobEvent(ctx, event); err != nil {
+		s.log.WithError(err).WithField("job_id", job.ID).Warn("Failed to record job event")
+	}
+
+	if s.transitionCallback != nil {
+		s.transitionCallback(job, from)
+	}
+}
+
+// actorFromContext returns the user in ctx, falling back to fallback and then
+// "system".
+func actorFromContext(ctx context.Context, fallback string) string {
+	if user := auth.UserFromContext(ctx); user != nil {
+		return user.Username
+	}
+
+	if fallback != "" {
+		return fallback
+	}
+
+	return "system"
+}
+
+// recordQueueChange persists a manual change to a group's queue with the queue
+// state of the affected jobs before and after it. Failures are logged rather
+// than failing the change.
+func (s *service) recordQueueChange(
+	ctx context.Context,
+	groupID, jobID string,
+	op store.QueueChangeOperation,
+	before, after []*store.Job,
+) {
+	change := &store.QueueChange{
+		ID:        uuid.New().String(),
+		GroupID:   groupID,
+		JobID:     jobID,
+		Operation: op,
+		Actor:     actorFromContext(ctx, ""),
+		Before:    queueSnapshot(before),
+		After:     queueSnapshot(after),
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.store.CreateQueueChange(ctx, change); err != nil {
+		s.log.WithError(err).WithFields(logrus.Fields{
+			"group_id":  groupID,
+			"operation": op,
+		}).Warn("Failed to record queue change")
+	}
+}
+
+// queueSnapshot returns the queue state of jobs, in the given order.
+func queueSnapshot(jobs []*store.Job) []store.QueueSnapshotEntry {
+	entries := make([]store.QueueSnapshotEntry, 0, len(jobs))
+	for _, job := range jobs {
+		entries = append(entries, store.QueueSnapshotEntry{
+			JobID:    job.ID,
+			Position: job.Position,
+			Priority: job.Priority,
+			Paused:   job.Paused,
+		})
+	}
+
+	return entries
+}
+
 // Enqueue adds a new job to the queue.
 // If templateID is empty, this creates a manual job using fields from opts.
 func (s *service) Enqueue(
 	ctx context.Context,
 	groupID, templateID, createdBy string,
-	inputs map[string]string,
+	inputs input.Map,
 	opts *EnqueueOptions,
 ) (*store.Job, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	var mergedInputs map[string]string
+	if err := s.checkGroupArchived(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	var mergedInputs input.Map
+
+	priority := 0
 
 	if templateID != "" {
 		// Template-based job: verify template exists.
@@ -193,28 +450,42 @@ func (s *service) Enqueue(
 			return nil, fmt.Errorf("template %s does not belong to group %s", templateID, groupID)
 		}
 
-		// Merge inputs with template defaults.
-		mergedInputs = make(map[string]string, len(template.DefaultInputs))
-		for k, v := range template.DefaultInputs {
-			mergedInputs[k] = v
+		if template.IsSunset(time.Now()) {
+			return nil, fmt.Errorf("template %s was sunset on %s and no longer accepts jobs",
+				templateID, template.SunsetAt.UTC().Format(time.RFC3339))
+		}
+
+		if template.Deprecated {
+			s.log.WithFields(logrus.Fields{
+				"template_id": templateID,
+				"group_id":    groupID,
+				"sunset_at":   template.SunsetAt,
+			}).Warn("Enqueuing job for deprecated template")
 		}
 
-		for k, v := range inputs {
-			mergedInputs[k] = v
+		if err := s.checkInputs(template, inputs); err != nil {
+			return nil, err
 		}
+
+		priority = template.DefaultPriority
+
+		// Merge inputs with template defaults.
+		mergedInputs = template.DefaultInputs.Merge(inputs)
 	} else {
-		// Manual job: validate required fields.
-		if opts == nil || opts.Owner == "" || opts.Repo == "" || opts.WorkflowID == "" || opts.Ref == "" {
-			return nil, fmt.Errorf("manual jobs require owner, repo, workflow_id, and ref")
+		// Manual job: the options name the workflow to dispatch.
+		manual, err := manualJobOptions(opts)
+		if err != nil {
+			return nil, err
 		}
 
-		// Use inputs as-is for manual jobs.
-		if inputs != nil {
-			mergedInputs = make(map[string]string, len(inputs))
-			for k, v := range inputs {
-				mergedInputs[k] = v
-			}
+		opts = manual
+
+		if err := s.checkInputs(nil, inputs); err != nil {
+			return nil, err
 		}
+
+		// Use inputs as-is for manual jobs.
+		mergedInputs = maps.Clone(inputs)
 	}
 
 	// Get max position.
@@ -229,7 +500,7 @@ func (s *service) Enqueue(
 		ID:         uuid.New().String(),
 		GroupID:    groupID,
 		TemplateID: templateID,
-		Priority:   0,
+		Priority:   priority,
 		Position:   maxPos + 1,
 		Status:     store.JobStatusPending,
 		Inputs:     mergedInputs,
@@ -243,6 +514,10 @@ func (s *service) Enqueue(
 		job.AutoRequeue = opts.AutoRequeue
 		job.RequeueLimit = opts.RequeueLimit
 
+		if opts.Priority != nil {
+			job.Priority = *opts.Priority
+		}
+
 		// For manual jobs (or template jobs with overrides), set the workflow fields.
 		if opts.Name != "" {
 			job.Name = &opts.Name
@@ -267,6 +542,16 @@ func (s *service) Enqueue(
 		if len(opts.Labels) > 0 {
 			job.Labels = opts.Labels
 		}
+
+		job.CampaignID = opts.CampaignID
+		job.MaxDurationSeconds = opts.MaxDurationSeconds
+		job.NotifyTargets = opts.NotifyTargets
+		job.NotBefore = opts.NotBefore
+		job.SourceIssue = opts.SourceIssue
+	}
+
+	if err := s.checkGroupCaps(ctx, job); err != nil {
+		return nil, err
 	}
 
 	if err := s.store.CreateJob(ctx, job); err != nil {
@@ -287,11 +572,151 @@ func (s *service) Enqueue(
 
 	s.log.WithFields(logFields).Info("Job enqueued")
 
+	s.recordJobEvent(ctx, job, "", createdBy, "")
+	s.recordJobMetric(job)
+	s.notifyJobChange(job)
+
+	return job, nil
+}
+
+// Requeue creates a new pending job from a finished job, optionally overriding
+// inputs and targeting a different group. The new job records the original in
+// RequeuedFrom. Auto-requeue settings are not carried over.
+//
+// When a template job is requeued into another group, the template's workflow
+// settings are copied onto the new job as overrides, since templates belong to
+// a single group.
+func (s *service) Requeue(
+	ctx context.Context,
+	jobID, targetGroupID, createdBy string,
+	inputs input.Map,
+) (*store.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	original, err := s.store.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("getting job: %w", err)
+	}
+
+	if original == nil {
+		return nil, fmt.Errorf("job not found: %s", jobID)
+	}
+
+	switch original.Status {
+	case store.JobStatusCompleted, store.JobStatusFailed, store.JobStatusCancelled:
+	default:
+		return nil, fmt.Errorf("cannot requeue job: current status is %s", original.Status)
+	}
+
+	if targetGroupID == "" {
+		targetGroupID = original.GroupID
+	}
+
+	if err := s.checkGroupArchived(ctx, targetGroupID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	job := &store.Job{
+		ID:           uuid.New().String(),
+		GroupID:      targetGroupID,
+		TemplateID:   original.TemplateID,
+		Status:       store.JobStatusPending,
+		CreatedBy:    createdBy,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		Name:         original.Name,
+		Owner:        original.Owner,
+		Repo:         original.Repo,
+		WorkflowID:   original.WorkflowID,
+		Ref:          original.Ref,
+		Labels:       original.Labels,
+		RequeuedFrom: &original.ID,
+		CampaignID:   original.CampaignID,
+
+		MaxDurationSeconds: original.MaxDurationSeconds,
+		NotifyTargets:      original.NotifyTargets,
+	}
+
+	if original.TemplateID != "" {
+		template, err := s.store.GetJobTemplate(ctx, original.TemplateID)
+		if err != nil {
+			return nil, fmt.Errorf("getting template: %w", err)
+		}
+
+		if template == nil {
+			return nil, fmt.Errorf("template not found: %s", original.TemplateID)
+		}
+
+		if template.GroupID == targetGroupID && template.IsSunset(now) {
+			return nil, fmt.Errorf("template %s was sunset on %s and no longer accepts jobs",
+				template.ID, template.SunsetAt.UTC().Format(time.RFC3339))
+		}
+
+		if template.GroupID == targetGroupID {
+			if err := s.checkInputs(template, inputs); err != nil {
+				return nil, err
+			}
+
+			job.Priority = template.DefaultPriority
+		}
+
+		if template.GroupID != targetGroupID {
+			job.TemplateID = ""
+			job.Name = firstNonEmpty(job.Name, template.Name)
+			job.Owner = firstNonEmpty(job.Owner, template.Owner)
+			job.Repo = firstNonEmpty(job.Repo, template.Repo)
+			job.WorkflowID = firstNonEmpty(job.WorkflowID, template.WorkflowID)
+			job.Ref = firstNonEmpty(job.Ref, template.Ref)
+
+			if job.Labels == nil && len(template.Labels) > 0 {
+				job.Labels = template.Labels
+			}
+		}
+	}
+
+	// Start from the inputs the original job ran with.
+	job.Inputs = original.Inputs.Merge(inputs)
+
+	maxPos, err := s.store.GetMaxPosition(ctx, targetGroupID)
+	if err != nil {
+		return nil, fmt.Errorf("getting max position: %w", err)
+	}
+
+	job.Position = maxPos + 1
+
+	if err := s.checkGroupCaps(ctx, job); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.CreateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("creating job: %w", err)
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"original_job_id": original.ID,
+		"new_job_id":      job.ID,
+		"group_id":        targetGroupID,
+	}).Info("Job requeued")
+
+	s.recordJobEvent(ctx, job, "", job.CreatedBy, fmt.Sprintf("Requeued from job %s", original.ID))
+	s.recordJobMetric(job)
 	s.notifyJobChange(job)
 
 	return job, nil
 }
 
+// firstNonEmpty returns override if it is set, otherwise a pointer to fallback.
+func firstNonEmpty(override *string, fallback string) *string {
+	if override != nil && *override != "" {
+		return override
+	}
+
+	return &fallback
+}
+
 // Dequeue removes and returns the next pending job from the queue.
 func (s *service) Dequeue(ctx context.Context, groupID string) (*store.Job, error) {
 	s.mu.Lock()
@@ -329,12 +754,18 @@ func (s *service) Remove(ctx context.Context, jobID string) error {
 		return fmt.Errorf("cannot remove job with status %s", job.Status)
 	}
 
+	if job.Retained {
+		return fmt.Errorf("cannot remove retained job, unretain it first")
+	}
+
 	if err := s.store.DeleteJob(ctx, jobID); err != nil {
 		return fmt.Errorf("deleting job: %w", err)
 	}
 
 	s.log.WithField("job_id", jobID).Info("Job removed from queue")
 
+	s.recordQueueChange(ctx, job.GroupID, jobID, store.QueueChangeDelete, []*store.Job{job}, nil)
+
 	return nil
 }
 
@@ -363,6 +794,11 @@ func (s *service) Reorder(ctx context.Context, groupID string, jobIDs []string)
 		}
 	}
 
+	before, err := s.ListPending(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("listing pending jobs: %w", err)
+	}
+
 	if err := s.store.ReorderJobs(ctx, groupID, jobIDs); err != nil {
 		return fmt.Errorf("reordering jobs: %w", err)
 	}
@@ -372,17 +808,89 @@ func (s *service) Reorder(ctx context.Context, groupID string, jobIDs []string)
 		"count":    len(jobIDs),
 	}).Info("Jobs reordered")
 
+	after, err := s.ListPending(ctx, groupID)
+	if err != nil {
+		s.log.WithError(err).WithField("group_id", groupID).Warn("Failed to list pending jobs after reorder")
+	} else {
+		s.recordQueueChange(ctx, groupID, "", store.QueueChangeReorder, before, after)
+	}
+
 	return nil
 }
 
+// Compact renumbers the pending jobs of a group to consecutive positions,
+// keeping their order. It returns the number of jobs that moved.
+func (s *service) Compact(ctx context.Context, groupID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed, err := s.store.CompactJobPositions(ctx, groupID)
+	if err != nil {
+		return 0, fmt.Errorf("compacting job positions: %w", err)
+	}
+
+	if changed > 0 {
+		s.log.WithFields(logrus.Fields{
+			"group_id": groupID,
+			"count":    changed,
+		}).Info("Queue compacted")
+	}
+
+	return changed, nil
+}
+
 // GetJob retrieves a job by ID.
 func (s *service) GetJob(ctx context.Context, jobID string) (*store.Job, error) {
 	return s.store.GetJob(ctx, jobID)
 }
 
-// ListPending returns all pending jobs for a group.
+// ListPending returns all pending jobs for a group, in dispatch order.
 func (s *service) ListPending(ctx context.Context, groupID string) ([]*store.Job, error) {
-	return s.store.ListJobsByGroup(ctx, groupID, store.JobStatusPending)
+	jobs, err := s.store.ListJobsByGroup(ctx, groupID, store.JobStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	SortPending(jobs)
+
+	return jobs, nil
+}
+
+// SortPending orders pending jobs for dispatch: higher priority first, then by
+// queue position.
+func SortPending(jobs []*store.Job) {
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].Priority != jobs[j].Priority {
+			return jobs[i].Priority > jobs[j].Priority
+		}
+
+		return jobs[i].Position < jobs[j].Position
+	})
+}
+
+// SetQueuePositions sets QueuePosition and AheadCount on the unpaused pending
+// jobs among jobs, which should hold all of a group's pending jobs. Paused jobs
+// are skipped by the dispatcher, so they get no position and are not counted
+// as ahead of anyone. The order of jobs itself is left unchanged.
+func SetQueuePositions(jobs []*store.Job) {
+	waiting := make([]*store.Job, 0, len(jobs))
+
+	for _, job := range jobs {
+		job.QueuePosition = nil
+		job.AheadCount = nil
+
+		if job.Status == store.JobStatusPending && !job.Paused {
+			waiting = append(waiting, job)
+		}
+	}
+
+	SortPending(waiting)
+
+	for i, job := range waiting {
+		position, ahead := i+1, i
+		job.QueuePosition = &position
+		job.AheadCount = &ahead
+	}
 }
 
 // ListByStatus returns jobs with the given statuses.
@@ -406,11 +914,76 @@ func (s *service) ListHistory(ctx context.Context, groupID string, limit int) ([
 }
 
 // ListHistoryPaginated returns paginated history with cursor support.
+// Total counts are served from a short-lived cache when available.
 func (s *service) ListHistoryPaginated(
 	ctx context.Context,
 	opts store.HistoryQueryOpts,
 ) (*store.HistoryResult, error) {
-	return s.store.ListJobHistory(ctx, opts)
+	ttl := s.cfg.History.CountCacheTTL
+	if opts.SkipCount || ttl <= 0 {
+		return s.store.ListJobHistory(ctx, opts)
+	}
+
+	key := historyCountKey(opts)
+
+	s.historyCountsMu.Lock()
+	cached, ok := s.historyCounts[key]
+	s.historyCountsMu.Unlock()
+
+	if ok && time.Now().Before(cached.expiresAt) {
+		opts.SkipCount = true
+
+		result, err := s.store.ListJobHistory(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		result.TotalCount = cached.count
+		result.CountCached = true
+
+		return result, nil
+	}
+
+	result, err := s.store.ListJobHistory(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s.historyCountsMu.Lock()
+	now := time.Now()
+
+	// Drop expired entries so the cache does not grow with every filter combination.
+	for k, entry := range s.historyCounts {
+		if now.After(entry.expiresAt) {
+			delete(s.historyCounts, k)
+		}
+	}
+
+	s.historyCounts[key] = historyCount{count: result.TotalCount, expiresAt: now.Add(ttl)}
+	s.historyCountsMu.Unlock()
+
+	return result, nil
+}
+
+// historyCountKey identifies the filters a history total count applies to.
+// Pagination options are not part of the key since they do not change the total.
+func historyCountKey(opts store.HistoryQueryOpts) string {
+	statuses := make([]string, len(opts.Statuses))
+	for i, status := range opts.Statuses {
+		statuses[i] = string(status)
+	}
+
+	sort.Strings(statuses)
+
+	labels := make([]string, 0, len(opts.Labels))
+	for k, v := range opts.Labels {
+		labels = append(labels, k+"="+v)
+	}
+
+	sort.Strings(labels)
+
+	return opts.GroupID + "|" + opts.TemplateID + "|" + strings.Join(statuses, ",") + "|" + strings.Join(labels, ",") +
+		"|" + strconv.FormatBool(opts.RetainedOnly) + "|" + opts.Assignee
 }
 
 // MarkTriggered marks a job as triggered.
@@ -431,6 +1004,7 @@ func (s *service) MarkTriggered(ctx context.Context, jobID string, runID int64,
 		return fmt.Errorf("cannot mark job as triggered: current status is %s", job.Status)
 	}
 
+	from := job.Status
 	now := time.Now()
 	job.Status = store.JobStatusTriggered
 	job.TriggeredAt = &now
@@ -447,6 +1021,8 @@ func (s *service) MarkTriggered(ctx context.Context, jobID string, runID int64,
 		"run_id": runID,
 	}).Info("Job marked as triggered")
 
+	s.recordJobEvent(ctx, job, from, "", "")
+	s.recordJobMetric(job)
 	s.notifyJobChange(job)
 
 	return nil
@@ -470,6 +1046,7 @@ func (s *service) MarkRunning(ctx context.Context, jobID string, runnerID int64,
 		return fmt.Errorf("cannot mark job as running: current status is %s", job.Status)
 	}
 
+	from := job.Status
 	job.Status = store.JobStatusRunning
 	if runnerID != 0 {
 		job.RunnerID = &runnerID
@@ -487,6 +1064,7 @@ func (s *service) MarkRunning(ctx context.Context, jobID string, runnerID int64,
 		"runner":    runnerName,
 	}).Info("Job marked as running")
 
+	s.recordJobEvent(ctx, job, from, "", "")
 	s.notifyJobChange(job)
 
 	return nil
@@ -506,6 +1084,7 @@ func (s *service) MarkCompleted(ctx context.Context, jobID string) error {
 		return fmt.Errorf("job not found: %s", jobID)
 	}
 
+	from := job.Status
 	now := time.Now()
 	job.Status = store.JobStatusCompleted
 	job.CompletedAt = &now
@@ -517,6 +1096,8 @@ func (s *service) MarkCompleted(ctx context.Context, jobID string) error {
 
 	s.log.WithField("job_id", jobID).Info("Job marked as completed")
 
+	s.recordJobEvent(ctx, job, from, "", "")
+	s.recordJobMetric(job)
 	s.notifyJobChange(job)
 
 	// Auto-requeue if enabled.
@@ -539,6 +1120,7 @@ func (s *service) MarkFailed(ctx context.Context, jobID, errMsg string) error {
 		return fmt.Errorf("job not found: %s", jobID)
 	}
 
+	from := job.Status
 	now := time.Now()
 	job.Status = store.JobStatusFailed
 	job.CompletedAt = &now
@@ -554,6 +1136,8 @@ func (s *service) MarkFailed(ctx context.Context, jobID, errMsg string) error {
 		"error":  errMsg,
 	}).Info("Job marked as failed")
 
+	s.recordJobEvent(ctx, job, from, "", errMsg)
+	s.recordJobMetric(job)
 	s.notifyJobChange(job)
 
 	// Auto-requeue if enabled.
@@ -562,6 +1146,67 @@ func (s *service) MarkFailed(ctx context.Context, jobID, errMsg string) error {
 	return nil
 }
 
+// MarkRerun moves a failed or cancelled job whose workflow run was re-run from
+// GitHub back to triggered, so the run is tracked to the new attempt's outcome.
+// The outcome of the attempt that finished is kept in the job's attempt history.
+func (s *service) MarkRerun(ctx context.Context, jobID string, attempt int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := s.store.GetJob(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("getting job: %w", err)
+	}
+
+	if job == nil {
+		return fmt.Errorf("job not found: %s", jobID)
+	}
+
+	if job.Status != store.JobStatusFailed && job.Status != store.JobStatusCancelled {
+		return fmt.Errorf("cannot mark job as re-run: current status is %s", job.Status)
+	}
+
+	// Jobs tracked before attempts were recorded finished their first attempt.
+	finished := &store.JobRunAttempt{
+		JobID:        job.ID,
+		Attempt:      max(job.RunAttempt, 1),
+		Status:       job.Status,
+		ErrorMessage: job.ErrorMessage,
+		RunnerName:   job.RunnerName,
+		CompletedAt:  job.CompletedAt,
+	}
+
+	if job.LogsURL != "" {
+		finished.LogsURL = fmt.Sprintf("%s/attempts/%d/logs", strings.TrimSuffix(job.LogsURL, "/logs"), finished.Attempt)
+	}
+
+	if err := s.store.CreateJobRunAttempt(ctx, finished); err != nil {
+		return fmt.Errorf("recording finished run attempt: %w", err)
+	}
+
+	from := job.Status
+	job.Status = store.JobStatusTriggered
+	job.CompletedAt = nil
+	job.ErrorMessage = ""
+	job.RunnerID = nil
+	job.RunnerName = ""
+	job.UpdatedAt = time.Now()
+
+	if err := s.store.UpdateJob(ctx, job); err != nil {
+		return fmt.Errorf("updating job: %w", err)
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"job_id":  jobID,
+		"attempt": attempt,
+	}).Info("Job marked as re-run")
+
+	s.recordJobEvent(ctx, job, from, "", fmt.Sprintf("Workflow run re-run (attempt %d)", attempt))
+	s.notifyJobChange(job)
+
+	return nil
+}
+
 // MarkCancelled marks a job as cancelled.
 func (s *service) MarkCancelled(ctx context.Context, jobID string) error {
 	s.mu.Lock()
@@ -581,6 +1226,7 @@ func (s *service) MarkCancelled(ctx context.Context, jobID string) error {
 		return fmt.Errorf("cannot cancel job with status %s", job.Status)
 	}
 
+	from := job.Status
 	now := time.Now()
 	job.Status = store.JobStatusCancelled
 	job.CompletedAt = &now
@@ -592,6 +1238,8 @@ func (s *service) MarkCancelled(ctx context.Context, jobID string) error {
 
 	s.log.WithField("job_id", jobID).Info("Job marked as cancelled")
 
+	s.recordJobEvent(ctx, job, from, "", "")
+	s.recordJobMetric(job)
 	s.notifyJobChange(job)
 
 	// Auto-requeue if enabled.
@@ -622,6 +1270,7 @@ func (s *service) Pause(ctx context.Context, jobID string) (*store.Job, error) {
 		return job, nil // Already paused
 	}
 
+	before := *job
 	job.Paused = true
 	job.UpdatedAt = time.Now()
 
@@ -631,6 +1280,8 @@ func (s *service) Pause(ctx context.Context, jobID string) (*store.Job, error) {
 
 	s.log.WithField("job_id", jobID).Info("Job paused")
 
+	s.recordQueueChange(ctx, job.GroupID, jobID, store.QueueChangePause, []*store.Job{&before}, []*store.Job{job})
+
 	s.notifyJobChange(job)
 
 	return job, nil
@@ -658,6 +1309,7 @@ func (s *service) Unpause(ctx context.Context, jobID string) (*store.Job, error)
 		return job, nil // Already unpaused
 	}
 
+	before := *job
 	job.Paused = false
 	job.UpdatedAt = time.Now()
 
@@ -667,13 +1319,347 @@ func (s *service) Unpause(ctx context.Context, jobID string) (*store.Job, error)
 
 	s.log.WithField("job_id", jobID).Info("Job unpaused")
 
+	s.recordQueueChange(ctx, job.GroupID, jobID, store.QueueChangeUnpause, []*store.Job{&before}, []*store.Job{job})
+
+	s.notifyJobChange(job)
+
+	return job, nil
+}
+
+// ReassignOwner changes who the job is attributed to. The first reassignment
+// records the original creator in OriginalCreatedBy.
+func (s *service) ReassignOwner(ctx context.Context, jobID, createdBy string) (*store.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := s.store.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("getting job: %w", err)
+	}
+
+	if job == nil {
+		return nil, fmt.Errorf("job not found: %s", jobID)
+	}
+
+	if job.CreatedBy == createdBy {
+		return job, nil
+	}
+
+	if job.OriginalCreatedBy == "" {
+		job.OriginalCreatedBy = job.CreatedBy
+	}
+
+	previous := job.CreatedBy
+	job.CreatedBy = createdBy
+	job.UpdatedAt = time.Now()
+
+	if err := s.store.UpdateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("updating job: %w", err)
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"job_id": jobID,
+		"from":   previous,
+		"to":     createdBy,
+	}).Info("Job owner reassigned")
+
+	s.notifyJobChange(job)
+
+	return job, nil
+}
+
+// SetRetained marks a finished job as retained, so history cleanup never
+// deletes it, or returns it to normal retention.
+func (s *service) SetRetained(ctx context.Context, jobID string, retained bool) (*store.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := s.store.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("getting job: %w", err)
+	}
+
+	if job == nil {
+		return nil, fmt.Errorf("job not found: %s", jobID)
+	}
+
+	switch job.Status {
+	case store.JobStatusCompleted, store.JobStatusFailed, store.JobStatusCancelled:
+	default:
+		return nil, fmt.Errorf("cannot retain job with status %s", job.Status)
+	}
+
+	if job.Retained == retained {
+		return job, nil
+	}
+
+	if err := s.store.SetJobRetained(ctx, jobID, retained); err != nil {
+		return nil, fmt.Errorf("setting job retained: %w", err)
+	}
+
+	job.Retained = retained
+
+	s.log.WithFields(logrus.Fields{
+		"job_id":   jobID,
+		"retained": retained,
+	}).Info("Job retention changed")
+
+	s.notifyJobChange(job)
+
+	return job, nil
+}
+
+// SetAssignee assigns a failed job to the user investigating it, or clears the
+// assignee when assignee is empty. Clearing is allowed in any status so jobs
+// re-run after assignment can still be released.
+func (s *service) SetAssignee(ctx context.Context, jobID, assignee string) (*store.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := s.store.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("getting job: %w", err)
+	}
+
+	if job == nil {
+		return nil, fmt.Errorf("job not found: %s", jobID)
+	}
+
+	if assignee != "" && job.Status != store.JobStatusFailed {
+		return nil, fmt.Errorf("cannot assign job with status %s", job.Status)
+	}
+
+	if job.Assignee == assignee {
+		return job, nil
+	}
+
+	var assignedAt *time.Time
+
+	if assignee != "" {
+		now := time.Now()
+		assignedAt = &now
+	}
+
+	if err := s.store.SetJobAssignee(ctx, jobID, assignee, assignedAt); err != nil {
+		return nil, fmt.Errorf("setting job assignee: %w", err)
+	}
+
+	job.Assignee = assignee
+	job.AssignedAt = assignedAt
+
+	s.log.WithFields(logrus.Fields{
+		"job_id":   jobID,
+		"assignee": assignee,
+	}).Info("Job assignee changed")
+
 	s.notifyJobChange(job)
 
 	return job, nil
 }
 
+// SetNotifyTargets replaces the notify targets of a job that has not finished;
+// nil clears them.
+func (s *service) SetNotifyTargets(ctx context.Context, jobID string, targets []store.JobNotifyTarget) (*store.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := s.store.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("getting job: %w", err)
+	}
+
+	if job == nil {
+		return nil, fmt.Errorf("job not found: %s", jobID)
+	}
+
+	switch job.Status {
+	case store.JobStatusCompleted, store.JobStatusFailed, store.JobStatusCancelled:
+		return nil, fmt.Errorf("cannot change notify targets of job with status %s", job.Status)
+	}
+
+	if err := s.store.SetJobNotifyTargets(ctx, jobID, targets); err != nil {
+		return nil, fmt.Errorf("setting job notify targets: %w", err)
+	}
+
+	job.NotifyTargets = targets
+
+	s.log.WithFields(logrus.Fields{
+		"job_id":  jobID,
+		"targets": len(targets),
+	}).Info("Job notify targets changed")
+
+	s.notifyJobChange(job)
+
+	return job, nil
+}
+
+// checkGroupArchived rejects new jobs for archived groups.
+func (s *service) checkGroupArchived(ctx context.Context, groupID string) error {
+	group, err := s.store.GetGroup(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("getting group: %w", err)
+	}
+
+	if group != nil && group.Archived {
+		return fmt.Errorf("group %s is archived and no longer accepts jobs", groupID)
+	}
+
+	return nil
+}
+
+// checkGroupCaps enforces the group's max_priority and max_requeue_limit on a
+// job about to be stored. Auto-requeue jobs without a requeue limit get the
+// group's maximum as their limit, so they cannot requeue forever.
+func (s *service) checkGroupCaps(ctx context.Context, job *store.Job) error {
+	group, err := s.store.GetGroup(ctx, job.GroupID)
+	if err != nil {
+		return fmt.Errorf("getting group: %w", err)
+	}
+
+	if group == nil {
+		return nil
+	}
+
+	if group.MaxPriority != nil && job.Priority > *group.MaxPriority {
+		return fmt.Errorf("priority %d exceeds the maximum of %d for group %s",
+			job.Priority, *group.MaxPriority, group.ID)
+	}
+
+	if !job.AutoRequeue || group.MaxRequeueLimit == nil {
+		return nil
+	}
+
+	if job.RequeueLimit == nil {
+		limit := *group.MaxRequeueLimit
+		job.RequeueLimit = &limit
+	} else if *job.RequeueLimit > *group.MaxRequeueLimit {
+		return fmt.Errorf("requeue_limit %d exceeds the maximum of %d for group %s",
+			*job.RequeueLimit, *group.MaxRequeueLimit, group.ID)
+	}
+
+	return nil
+}
+
+// manualJobOptions validates the workflow a manual job dispatches and returns
+// a copy of opts with surrounding whitespace trimmed from its fields.
+func manualJobOptions(opts *EnqueueOptions) (*EnqueueOptions, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("manual jobs require owner, repo, workflow_id, and ref")
+	}
+
+	manual := *opts
+	manual.Name = strings.TrimSpace(manual.Name)
+	manual.Owner = strings.TrimSpace(manual.Owner)
+	manual.Repo = strings.TrimSpace(manual.Repo)
+	manual.WorkflowID = strings.TrimSpace(manual.WorkflowID)
+	manual.Ref = strings.TrimSpace(manual.Ref)
+
+	if manual.Owner == "" || manual.Repo == "" || manual.WorkflowID == "" || manual.Ref == "" {
+		return nil, fmt.Errorf("manual jobs require owner, repo, workflow_id, and ref")
+	}
+
+	if strings.ContainsAny(manual.Owner, "/ \t") || strings.ContainsAny(manual.Repo, "/ \t") {
+		return nil, fmt.Errorf("manual job owner %q and repo %q must not contain slashes or whitespace",
+			manual.Owner, manual.Repo)
+	}
+
+	if strings.ContainsAny(manual.WorkflowID, " \t") {
+		return nil, fmt.Errorf("manual job workflow_id %q must not contain whitespace", manual.WorkflowID)
+	}
+
+	return &manual, nil
+}
+
+// checkInputs rejects reserved inputs, values for the template's pinned inputs
+// that differ from its defaults and, when strict inputs are enabled, input keys
+// the template does not declare. Manual jobs have no template and are only
+// checked for reserved inputs.
+func (s *service) checkInputs(template *store.JobTemplate, inputs input.Map) error {
+	var reserved []string
+
+	for _, key := range config.ReservedInputs {
+		if _, ok := inputs[key]; ok {
+			reserved = append(reserved, key)
+		}
+	}
+
+	if len(reserved) > 0 {
+		return fmt.Errorf("inputs are reserved and set at dispatch: %s", strings.Join(reserved, ", "))
+	}
+
+	if template == nil {
+		return nil
+	}
+
+	if err := checkPinnedInputs(template, inputs); err != nil {
+		return err
+	}
+
+	if !s.cfg.Queue.StrictInputs {
+		return nil
+	}
+
+	var unknown []string
+
+	for k := range inputs {
+		if _, ok := template.DefaultInputs[k]; !ok {
+			unknown = append(unknown, k)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	declared := make([]string, 0, len(template.DefaultInputs))
+	for k := range template.DefaultInputs {
+		declared = append(declared, k)
+	}
+
+	sort.Strings(unknown)
+	sort.Strings(declared)
+
+	return fmt.Errorf("unknown inputs for template %s: %s (declared: %s)",
+		template.ID, strings.Join(unknown, ", "), strings.Join(declared, ", "))
+}
+
+// checkPinnedInputs rejects inputs that override a pinned template input.
+// Passing a pinned input with its default value is allowed.
+func checkPinnedInputs(template *store.JobTemplate, inputs input.Map) error {
+	var overridden []string
+
+	for _, key := range template.PinnedInputs {
+		if value, ok := inputs[key]; ok && value.String() != template.DefaultInputs[key].String() {
+			overridden = append(overridden, key)
+		}
+	}
+
+	if len(overridden) == 0 {
+		return nil
+	}
+
+	sort.Strings(overridden)
+
+	return fmt.Errorf("pinned inputs for template %s cannot be overridden: %s",
+		template.ID, strings.Join(overridden, ", "))
+}
+
+// checkJobInputs runs checkInputs against the template of an existing job.
+func (s *service) checkJobInputs(ctx context.Context, job *store.Job, inputs input.Map) error {
+	if job.TemplateID == "" {
+		return s.checkInputs(nil, inputs)
+	}
+
+	template, err := s.store.GetJobTemplate(ctx, job.TemplateID)
+	if err != nil {
+		return fmt.Errorf("getting template: %w", err)
+	}
+
+	return s.checkInputs(template, inputs)
+}
+
 // UpdateInputs updates the inputs for a pending job.
-func (s *service) UpdateInputs(ctx context.Context, jobID string, inputs map[string]string) error {
+func (s *service) UpdateInputs(ctx context.Context, jobID string, inputs input.Map) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -690,7 +1676,11 @@ func (s *service) UpdateInputs(ctx context.Context, jobID string, inputs map[str
 		return fmt.Errorf("cannot update inputs for job with status %s", job.Status)
 	}
 
-	job.Inputs = inputs
+	if err := s.checkJobInputs(ctx, job, inputs); err != nil {
+		return err
+	}
+
+	job.Inputs = inputs.Like(job.Inputs)
 	job.UpdatedAt = time.Now()
 
 	if err := s.store.UpdateJob(ctx, job); err != nil {
@@ -722,7 +1712,11 @@ func (s *service) UpdateJob(ctx context.Context, jobID string, opts *UpdateJobOp
 
 	// Update fields if provided.
 	if opts.Inputs != nil {
-		job.Inputs = opts.Inputs
+		if err := s.checkJobInputs(ctx, job, opts.Inputs); err != nil {
+			return err
+		}
+
+		job.Inputs = opts.Inputs.Like(job.Inputs)
 	}
 
 	if opts.Name != nil {
@@ -749,6 +1743,21 @@ func (s *service) UpdateJob(ctx context.Context, jobID string, opts *UpdateJobOp
 		job.Labels = opts.Labels
 	}
 
+	if opts.ClearNotBefore {
+		job.NotBefore = nil
+	} else if opts.NotBefore != nil {
+		job.NotBefore = opts.NotBefore
+	}
+
+	before := *job
+	if opts.Priority != nil {
+		job.Priority = *opts.Priority
+
+		if err := s.checkGroupCaps(ctx, job); err != nil {
+			return err
+		}
+	}
+
 	job.UpdatedAt = time.Now()
 
 	if err := s.store.UpdateJob(ctx, job); err != nil {
@@ -757,6 +1766,10 @@ func (s *service) UpdateJob(ctx context.Context, jobID string, opts *UpdateJobOp
 
 	s.log.WithField("job_id", jobID).Info("Job updated")
 
+	if job.Priority != before.Priority {
+		s.recordQueueChange(ctx, job.GroupID, jobID, store.QueueChangePriority, []*store.Job{&before}, []*store.Job{job})
+	}
+
 	s.notifyJobChange(job)
 
 	return nil
@@ -817,6 +1830,10 @@ func (s *service) UpdateAutoRequeue(ctx context.Context, jobID string, autoReque
 	job.RequeueLimit = requeueLimit
 	job.UpdatedAt = time.Now()
 
+	if err := s.checkGroupCaps(ctx, job); err != nil {
+		return nil, err
+	}
+
 	if err := s.store.UpdateJob(ctx, job); err != nil {
 		return nil, fmt.Errorf("updating job: %w", err)
 	}
@@ -849,6 +1866,25 @@ func (s *service) maybeAutoRequeue(ctx context.Context, job *store.Job) {
 		return
 	}
 
+	// Sunset templates no longer accept jobs, including auto-requeued ones.
+	if job.TemplateID != "" {
+		template, err := s.store.GetJobTemplate(ctx, job.TemplateID)
+		if err != nil {
+			s.log.WithError(err).WithField("job_id", job.ID).Warn("Failed to get template for auto-requeue")
+
+			return
+		}
+
+		if template != nil && template.IsSunset(time.Now()) {
+			s.log.WithFields(logrus.Fields{
+				"job_id":      job.ID,
+				"template_id": job.TemplateID,
+			}).Info("Skipping auto-requeue for sunset template")
+
+			return
+		}
+	}
+
 	// Get max position for the new job.
 	maxPos, err := s.store.GetMaxPosition(ctx, job.GroupID)
 	if err != nil {
@@ -881,6 +1917,10 @@ func (s *service) maybeAutoRequeue(ctx context.Context, job *store.Job) {
 		WorkflowID: job.WorkflowID,
 		Ref:        job.Ref,
 		Labels:     job.Labels,
+		CampaignID: job.CampaignID,
+
+		MaxDurationSeconds: job.MaxDurationSeconds,
+		NotifyTargets:      job.NotifyTargets,
 	}
 
 	if err := s.store.CreateJob(ctx, newJob); err != nil {
@@ -895,5 +1935,7 @@ func (s *service) maybeAutoRequeue(ctx context.Context, job *store.Job) {
 		"requeue_count":   newJob.RequeueCount,
 	}).Info("Job auto-requeued")
 
+	s.recordJobEvent(ctx, newJob, "", "system", fmt.Sprintf("Auto-requeued from job %s", job.ID))
+	s.recordJobMetric(newJob)
 	s.notifyJobChange(newJob)
 }
@@ -0,0 +1,170 @@
+// Package queuestats periodically samples each group's queue depth and idle
+// runners into a compact table, so backlog trends can be graphed over days
+// and weeks.
+package queuestats
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// pruneInterval is how often samples older than the retention are deleted.
+const pruneInterval = time.Hour
+
+// Service periodically records queue stat samples of every group.
+type Service interface {
+	Start(ctx context.Context) error
+	Stop() error
+}
+
+// service implements Service.
+type service struct {
+	log      logrus.FieldLogger
+	cfg      *config.QueueStatsConfig
+	store    store.Store
+	cancel   context.CancelFunc
+	done     chan struct{}
+	prunedAt time.Time
+}
+
+// Ensure service implements Service.
+var _ Service = (*service)(nil)
+
+// NewService creates a new queue stats sampler.
+func NewService(log logrus.FieldLogger, cfg *config.Config, st store.Store) Service {
+	return &service{
+		log:   log.WithField("component", "queuestats"),
+		cfg:   &cfg.Queue.Stats,
+		store: st,
+		done:  make(chan struct{}),
+	}
+}
+
+// Start begins the sampling loop.
+func (s *service) Start(ctx context.Context) error {
+	s.log.WithFields(logrus.Fields{
+		"interval":  s.cfg.Interval,
+		"retention": s.cfg.Retention,
+	}).Info("Starting queue stats sampler")
+
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	go s.run(ctx)
+
+	return nil
+}
+
+// Stop stops the sampling loop.
+func (s *service) Stop() error {
+	s.log.Info("Stopping queue stats sampler")
+
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+
+	return nil
+}
+
+// run samples every interval.
+func (s *service) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.sample(ctx, time.Now()); err != nil {
+				s.log.WithError(err).Error("Failed to sample queue stats")
+			}
+		}
+	}
+}
+
+// sample records the queue depth and idle runners of every unarchived group,
+// and deletes samples past the retention once per prune interval.
+func (s *service) sample(ctx context.Context, now time.Time) error {
+	groups, err := s.store.ListGroups(ctx)
+	if err != nil {
+		return fmt.Errorf("listing groups: %w", err)
+	}
+
+	counts, err := s.store.GetGroupJobCounts(ctx, now)
+	if err != nil {
+		return fmt.Errorf("counting group jobs: %w", err)
+	}
+
+	runners, err := s.store.ListRunners(ctx)
+	if err != nil {
+		return fmt.Errorf("listing runners: %w", err)
+	}
+
+	byGroup := make(map[string]*store.GroupJobCounts, len(counts))
+	for _, count := range counts {
+		byGroup[count.GroupID] = count
+	}
+
+	samples := make([]*store.QueueStatSample, 0, len(groups))
+	sampledAt := now.Truncate(time.Second)
+
+	for _, group := range groups {
+		if group.Archived {
+			continue
+		}
+
+		sample := &store.QueueStatSample{GroupID: group.ID, SampledAt: sampledAt}
+
+		if count := byGroup[group.ID]; count != nil {
+			sample.Pending = count.Pending
+			sample.Running = count.Triggered + count.Running
+		}
+
+		for _, runner := range runners {
+			if runner.Status == store.RunnerStatusOnline && !runner.Busy && hasLabels(runner, group.RunnerLabels) {
+				sample.IdleRunners++
+			}
+		}
+
+		samples = append(samples, sample)
+	}
+
+	if err := s.store.CreateQueueStatSamples(ctx, samples); err != nil {
+		return fmt.Errorf("storing samples: %w", err)
+	}
+
+	if now.Sub(s.prunedAt) >= pruneInterval {
+		deleted, err := s.store.DeleteQueueStatSamplesBefore(ctx, now.Add(-s.cfg.Retention))
+		if err != nil {
+			return fmt.Errorf("pruning samples: %w", err)
+		}
+
+		s.prunedAt = now
+
+		if deleted > 0 {
+			s.log.WithField("deleted", deleted).Debug("Pruned queue stat samples")
+		}
+	}
+
+	return nil
+}
+
+// hasLabels reports whether the runner has every one of labels.
+func hasLabels(runner *store.Runner, labels []string) bool {
+	for _, label := range labels {
+		if !slices.Contains(runner.Labels, label) {
+			return false
+		}
+	}
+
+	return true
+}
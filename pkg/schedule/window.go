@@ -0,0 +1,168 @@
+// Package schedule evaluates the time windows in which templates may be dispatched.
+package schedule
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	// Embed the timezone database so windows work in minimal container images.
+	_ "time/tzdata"
+)
+
+// minutesPerDay is the number of minutes in a day.
+const minutesPerDay = 24 * 60
+
+// weekdays maps accepted day names to time.Weekday values.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// Window is a recurring period in which a template may be dispatched. Start and
+// End are HH:MM clock times in Timezone (default UTC); End may be "24:00", and
+// an End before Start spans midnight, in which case Days refer to the day the
+// window opens. An empty Days list means every day.
+type Window struct {
+	Days     []string `yaml:"days" json:"days,omitempty"`
+	Start    string   `yaml:"start" json:"start"`
+	End      string   `yaml:"end" json:"end"`
+	Timezone string   `yaml:"timezone" json:"timezone,omitempty"`
+
+	// resolved caches the parsed clock times and location, set by Validate.
+	resolved *resolvedWindow
+}
+
+// resolvedWindow is a window parsed for evaluation.
+type resolvedWindow struct {
+	start int // minutes since midnight
+	end   int
+	loc   *time.Location
+}
+
+// Validate checks that the window can be evaluated, and caches its parsed
+// times and location so Contains does not parse them on every call.
+func (w *Window) Validate() error {
+	for _, day := range w.Days {
+		if _, ok := weekdays[strings.ToLower(day)]; !ok {
+			return fmt.Errorf("unknown day %q", day)
+		}
+	}
+
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+
+	end, err := parseClock(w.End)
+	if err != nil {
+		return fmt.Errorf("end: %w", err)
+	}
+
+	if start == minutesPerDay {
+		return fmt.Errorf("start must be before 24:00")
+	}
+
+	if start == end {
+		return fmt.Errorf("start and end must differ")
+	}
+
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return fmt.Errorf("timezone: %w", err)
+	}
+
+	w.resolved = &resolvedWindow{start: start, end: end, loc: loc}
+
+	return nil
+}
+
+// Contains reports whether t falls inside the window. Windows not validated
+// are parsed on each call, and invalid windows never contain t.
+func (w *Window) Contains(t time.Time) bool {
+	r := w.resolved
+	if r == nil {
+		probe := *w
+		if err := probe.Validate(); err != nil {
+			return false
+		}
+
+		r = probe.resolved
+	}
+
+	start, end := r.start, r.end
+
+	t = t.In(r.loc)
+	now := t.Hour()*60 + t.Minute()
+
+	if start < end {
+		return w.onDay(t.Weekday()) && now >= start && now < end
+	}
+
+	// The window spans midnight: the part after midnight belongs to the
+	// previous day's window.
+	if now >= start {
+		return w.onDay(t.Weekday())
+	}
+
+	return now < end && w.onDay((t.Weekday()+6)%7)
+}
+
+// onDay reports whether the window opens on the given weekday.
+func (w *Window) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+
+	for _, name := range w.Days {
+		if weekdays[strings.ToLower(name)] == day {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Equal reports whether two window lists define the same windows.
+func Equal(a, b []Window) bool {
+	return slices.EqualFunc(a, b, func(x, y Window) bool {
+		return slices.Equal(x.Days, y.Days) && x.Start == y.Start && x.End == y.End && x.Timezone == y.Timezone
+	})
+}
+
+// Open reports whether t falls inside any of the windows. No windows means
+// always open.
+func Open(windows []Window, t time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+
+	for i := range windows {
+		if windows[i].Contains(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseClock parses an HH:MM time into minutes since midnight.
+func parseClock(value string) (int, error) {
+	var hour, minute int
+
+	if _, err := fmt.Sscanf(value, "%d:%d", &hour, &minute); err != nil || len(value) != 5 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+
+	if hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+
+	return hour*60 + minute, nil
+}
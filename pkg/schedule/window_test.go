@@ -0,0 +1,104 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowContains(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", value, err)
+		}
+
+		return parsed
+	}
+
+	weeknights := Window{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "22:00", End: "06:00"}
+	office := Window{Start: "09:00", End: "17:00", Timezone: "Europe/Berlin"}
+	dstGap := Window{Start: "01:30", End: "03:30", Timezone: "Europe/Berlin"}
+	untilMidnight := Window{Days: []string{"Saturday"}, Start: "18:00", End: "24:00"}
+
+	tests := []struct {
+		name   string
+		window Window
+		t      string
+		want   bool
+	}{
+		// 2026-03-13 is a Friday.
+		{"same day before start", weeknights, "2026-03-13T21:59:00Z", false},
+		{"opening minute", weeknights, "2026-03-13T22:00:00Z", true},
+		{"after midnight belongs to the opening day", weeknights, "2026-03-14T05:59:00Z", true},
+		{"closing minute is outside", weeknights, "2026-03-14T06:00:00Z", false},
+		{"after midnight of a day it does not open", weeknights, "2026-03-16T03:00:00Z", false},
+		{"saturday evening does not open", weeknights, "2026-03-14T23:00:00Z", false},
+		{"sunday night opens for monday", weeknights, "2026-03-16T23:00:00Z", true},
+		{"24:00 end includes the last minute", untilMidnight, "2026-03-14T23:59:00Z", true},
+		{"24:00 end excludes the next day", untilMidnight, "2026-03-15T00:00:00Z", false},
+		{"24:00 end opens at start", untilMidnight, "2026-03-14T18:00:00Z", true},
+		// Berlin is UTC+1 in winter and UTC+2 from 2026-03-29.
+		{"local time in winter", office, "2026-03-27T08:30:00Z", true},
+		{"local time in summer", office, "2026-03-30T07:30:00Z", true},
+		{"local close in summer", office, "2026-03-30T15:30:00Z", false},
+		{"before the clocks skip", dstGap, "2026-03-29T00:45:00Z", true},
+		{"after the clocks skip", dstGap, "2026-03-29T01:15:00Z", true},
+		{"after close on the short day", dstGap, "2026-03-29T01:45:00Z", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			validated := tc.window
+			if err := validated.Validate(); err != nil {
+				t.Fatalf("Failed to validate window: %v", err)
+			}
+
+			if got := validated.Contains(at(tc.t)); got != tc.want {
+				t.Errorf("Expected Contains(%s) = %t, got %t", tc.t, tc.want, got)
+			}
+
+			// Windows not validated, such as decoded ones, agree.
+			if got := tc.window.Contains(at(tc.t)); got != tc.want {
+				t.Errorf("Expected unvalidated Contains(%s) = %t, got %t", tc.t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestWindowValidate(t *testing.T) {
+	for _, w := range []Window{
+		{Start: "24:00", End: "06:00"},
+		{Start: "09:00", End: "09:00"},
+		{Start: "9:00", End: "17:00"},
+		{Start: "09:00", End: "24:30"},
+		{Days: []string{"funday"}, Start: "09:00", End: "17:00"},
+		{Start: "09:00", End: "17:00", Timezone: "Mars/Olympus"},
+	} {
+		if err := w.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", w)
+		}
+
+		if w.Contains(time.Now()) {
+			t.Errorf("Expected invalid %+v to never contain a time", w)
+		}
+	}
+}
+
+func TestEqualIgnoresResolution(t *testing.T) {
+	validated := []Window{{Days: []string{"mon"}, Start: "09:00", End: "17:00", Timezone: "UTC"}}
+	if err := validated[0].Validate(); err != nil {
+		t.Fatalf("Failed to validate window: %v", err)
+	}
+
+	decoded := []Window{{Days: []string{"mon"}, Start: "09:00", End: "17:00", Timezone: "UTC"}}
+
+	if !Equal(validated, decoded) {
+		t.Error("Expected a validated window to equal its decoded copy")
+	}
+
+	decoded[0].End = "18:00"
+
+	if Equal(validated, decoded) {
+		t.Error("Expected windows with different ends to differ")
+	}
+}
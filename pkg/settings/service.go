@@ -0,0 +1,310 @@
+// Package settings manages the values admins can tune at runtime: the
+// dispatch and run tracking intervals and the rate limit buffer of the runner
+// poller. The configuration provides their defaults; overrides are persisted
+// to the store and applied to the running services without a restart.
+package settings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// Keys of the runtime settings, as stored and named in the API.
+const (
+	KeyDispatchInterval = "dispatch_interval"
+	KeyTrackingInterval = "tracking_interval"
+	KeyRateLimitBuffer  = "rate_limit_buffer"
+)
+
+// Keys lists every runtime setting.
+var Keys = []string{KeyDispatchInterval, KeyTrackingInterval, KeyRateLimitBuffer}
+
+const (
+	// minInterval and maxInterval bound the dispatch and tracking intervals.
+	minInterval = time.Second
+	maxInterval = time.Hour
+	// maxRateLimitBuffer is the hourly rate limit of a GitHub App installation,
+	// the highest a token can have.
+	maxRateLimitBuffer = 15000
+)
+
+// ErrInvalid is returned, wrapped, for an update with an invalid value.
+var ErrInvalid = errors.New("invalid setting")
+
+// Values are runtime settings.
+type Values struct {
+	DispatchInterval time.Duration
+	TrackingInterval time.Duration
+	RateLimitBuffer  int
+}
+
+// Update changes runtime settings. Nil fields are left as they are, and the
+// keys in Reset return to their configured defaults.
+type Update struct {
+	DispatchInterval *time.Duration
+	TrackingInterval *time.Duration
+	RateLimitBuffer  *int
+	Reset            []string
+}
+
+// ChangeCallback is called with the effective values whenever they change.
+type ChangeCallback func(values Values)
+
+// UpdateCallback is called after an update has been persisted, e.g. to tell
+// other replicas to reload.
+type UpdateCallback func()
+
+// Service holds the effective runtime settings.
+type Service interface {
+	// Start loads the persisted overrides and applies them.
+	Start(ctx context.Context) error
+	Stop() error
+	// Values returns the effective settings.
+	Values() Values
+	// Defaults returns the settings from the configuration.
+	Defaults() Values
+	// Update validates and persists an update and applies it.
+	Update(ctx context.Context, update Update, actor string) (Values, error)
+	// Reload re-reads the overrides, e.g. after another replica changed them.
+	Reload(ctx context.Context) error
+	SetChangeCallback(cb ChangeCallback)
+	SetUpdateCallback(cb UpdateCallback)
+}
+
+// service implements Service.
+type service struct {
+	log      logrus.FieldLogger
+	store    store.Store
+	defaults Values
+
+	mu             sync.Mutex
+	values         Values
+	callback       ChangeCallback
+	updateCallback UpdateCallback
+}
+
+// Ensure service implements Service.
+var _ Service = (*service)(nil)
+
+// NewService creates a new runtime settings service.
+func NewService(log logrus.FieldLogger, cfg *config.Config, st store.Store) Service {
+	defaults := Values{
+		DispatchInterval: cfg.Dispatcher.Interval,
+		TrackingInterval: cfg.Dispatcher.TrackingInterval,
+		RateLimitBuffer:  cfg.GitHub.RateLimitBuffer,
+	}
+
+	return &service{
+		log:      log.WithField("component", "settings"),
+		store:    st,
+		defaults: defaults,
+		values:   defaults,
+	}
+}
+
+// Start loads the persisted overrides.
+func (s *service) Start(ctx context.Context) error {
+	if err := s.Reload(ctx); err != nil {
+		return fmt.Errorf("loading runtime settings: %w", err)
+	}
+
+	return nil
+}
+
+// Stop is a no-op; the service has no background work.
+func (s *service) Stop() error {
+	return nil
+}
+
+// Values returns the effective settings.
+func (s *service) Values() Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.values
+}
+
+// Defaults returns the settings from the configuration.
+func (s *service) Defaults() Values {
+	return s.defaults
+}
+
+// SetChangeCallback sets the callback for changes of the effective settings.
+func (s *service) SetChangeCallback(cb ChangeCallback) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.callback = cb
+}
+
+// SetUpdateCallback sets the callback for persisted updates.
+func (s *service) SetUpdateCallback(cb UpdateCallback) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.updateCallback = cb
+}
+
+// Update validates an update, persists it and applies the result.
+func (s *service) Update(ctx context.Context, update Update, actor string) (Values, error) {
+	overrides, err := validate(update)
+	if err != nil {
+		return Values{}, err
+	}
+
+	now := time.Now()
+
+	for _, key := range update.Reset {
+		if err := s.store.DeleteSetting(ctx, key); err != nil {
+			return Values{}, err
+		}
+	}
+
+	for key, value := range overrides {
+		if err := s.store.UpsertSetting(ctx, &store.Setting{
+			Key:       key,
+			Value:     value,
+			UpdatedBy: actor,
+			UpdatedAt: now,
+		}); err != nil {
+			return Values{}, err
+		}
+	}
+
+	if err := s.Reload(ctx); err != nil {
+		return Values{}, err
+	}
+
+	s.mu.Lock()
+	cb := s.updateCallback
+	s.mu.Unlock()
+
+	if cb != nil {
+		cb()
+	}
+
+	return s.Values(), nil
+}
+
+// validate checks an update and returns its overrides in their stored form.
+func validate(update Update) (map[string]string, error) {
+	for _, key := range update.Reset {
+		if !slices.Contains(Keys, key) {
+			return nil, fmt.Errorf("%w: unknown setting %q", ErrInvalid, key)
+		}
+	}
+
+	overrides := make(map[string]string)
+
+	intervals := []struct {
+		key   string
+		value *time.Duration
+	}{
+		{KeyDispatchInterval, update.DispatchInterval},
+		{KeyTrackingInterval, update.TrackingInterval},
+	}
+
+	for _, interval := range intervals {
+		if interval.value == nil {
+			continue
+		}
+
+		if *interval.value < minInterval || *interval.value > maxInterval {
+			return nil, fmt.Errorf("%w: %s must be between %s and %s", ErrInvalid, interval.key, minInterval, maxInterval)
+		}
+
+		overrides[interval.key] = interval.value.String()
+	}
+
+	if update.RateLimitBuffer != nil {
+		if *update.RateLimitBuffer < 0 || *update.RateLimitBuffer > maxRateLimitBuffer {
+			return nil, fmt.Errorf("%w: %s must be between 0 and %d", ErrInvalid, KeyRateLimitBuffer, maxRateLimitBuffer)
+		}
+
+		overrides[KeyRateLimitBuffer] = strconv.Itoa(*update.RateLimitBuffer)
+	}
+
+	for key := range overrides {
+		if slices.Contains(update.Reset, key) {
+			return nil, fmt.Errorf("%w: %s cannot be both set and reset", ErrInvalid, key)
+		}
+	}
+
+	return overrides, nil
+}
+
+// Reload re-reads the overrides and, if the effective settings changed,
+// calls the change callback.
+func (s *service) Reload(ctx context.Context) error {
+	settings, err := s.store.ListSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	values := s.defaults
+
+	for _, setting := range settings {
+		if err := s.apply(&values, setting); err != nil {
+			s.log.WithError(err).WithField("key", setting.Key).Warn("Ignoring invalid runtime setting")
+		}
+	}
+
+	s.mu.Lock()
+	changed := values != s.values
+	s.values = values
+	cb := s.callback
+	s.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"dispatch_interval": values.DispatchInterval,
+		"tracking_interval": values.TrackingInterval,
+		"rate_limit_buffer": values.RateLimitBuffer,
+	}).Info("Applied runtime settings")
+
+	if cb != nil {
+		cb(values)
+	}
+
+	return nil
+}
+
+// apply parses a stored override into values.
+func (s *service) apply(values *Values, setting *store.Setting) error {
+	switch setting.Key {
+	case KeyDispatchInterval, KeyTrackingInterval:
+		interval, err := time.ParseDuration(setting.Value)
+		if err != nil {
+			return err
+		}
+
+		if setting.Key == KeyDispatchInterval {
+			values.DispatchInterval = interval
+		} else {
+			values.TrackingInterval = interval
+		}
+	case KeyRateLimitBuffer:
+		buffer, err := strconv.Atoi(setting.Value)
+		if err != nil {
+			return err
+		}
+
+		values.RateLimitBuffer = buffer
+	default:
+		return fmt.Errorf("unknown setting %q", setting.Key)
+	}
+
+	return nil
+}
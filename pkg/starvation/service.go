@@ -0,0 +1,208 @@
+package starvation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/metrics"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// StarvedCallback is called when a group's oldest pending job has waited
+// longer than the starvation threshold.
+type StarvedCallback func(group *store.Group, job *store.Job, age time.Duration)
+
+// Service periodically records the age of each group's oldest pending job and
+// raises a notification when it exceeds the configured threshold.
+type Service interface {
+	Start(ctx context.Context) error
+	Stop() error
+	SetStarvedCallback(cb StarvedCallback)
+}
+
+// service implements Service.
+type service struct {
+	log             logrus.FieldLogger
+	cfg             *config.Config
+	store           store.Store
+	metrics         *metrics.Metrics
+	starvedCallback StarvedCallback
+	cancel          context.CancelFunc
+	done            chan struct{}
+
+	// starved holds the groups already notified about, so a notification is
+	// sent once per starvation episode rather than on every check.
+	starved map[string]struct{}
+}
+
+// Ensure service implements Service.
+var _ Service = (*service)(nil)
+
+// NewService creates a new starvation monitor.
+func NewService(log logrus.FieldLogger, cfg *config.Config, st store.Store, m *metrics.Metrics) Service {
+	return &service{
+		log:     log.WithField("component", "starvation"),
+		cfg:     cfg,
+		store:   st,
+		metrics: m,
+		done:    make(chan struct{}),
+		starved: make(map[string]struct{}),
+	}
+}
+
+// Start begins the monitor loop.
+func (s *service) Start(ctx context.Context) error {
+	s.log.WithFields(logrus.Fields{
+		"interval":  s.cfg.Queue.Starvation.CheckInterval,
+		"threshold": s.cfg.Queue.Starvation.Threshold,
+	}).Info("Starting queue starvation monitor")
+
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	go s.run(ctx)
+
+	return nil
+}
+
+// Stop stops the monitor loop.
+func (s *service) Stop() error {
+	s.log.Info("Stopping queue starvation monitor")
+
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+
+	return nil
+}
+
+// SetStarvedCallback sets the callback for starved groups.
+func (s *service) SetStarvedCallback(cb StarvedCallback) {
+	s.starvedCallback = cb
+}
+
+// run checks every group each interval.
+func (s *service) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.Queue.Starvation.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.check(ctx); err != nil {
+				s.log.WithError(err).Error("Failed to check queue starvation")
+			}
+		}
+	}
+}
+
+// check updates the oldest pending age of every group and notifies about
+// groups that crossed the threshold.
+func (s *service) check(ctx context.Context) error {
+	groups, err := s.store.ListGroups(ctx)
+	if err != nil {
+		return fmt.Errorf("listing groups: %w", err)
+	}
+
+	now := time.Now()
+	threshold := s.cfg.Queue.Starvation.Threshold
+
+	for _, group := range groups {
+		jobs, err := s.store.ListJobsByGroup(ctx, group.ID, store.JobStatusPending)
+		if err != nil {
+			return fmt.Errorf("listing pending jobs for group %s: %w", group.ID, err)
+		}
+
+		oldest := OldestPending(jobs, now)
+
+		var age time.Duration
+		if oldest != nil {
+			age = now.Sub(waitingSince(oldest))
+		}
+
+		if s.metrics != nil {
+			s.metrics.SetOldestPendingAge(group.ID, age.Seconds())
+		}
+
+		// Paused, disabled and archived groups hold their jobs on purpose.
+		if threshold <= 0 || oldest == nil || age < threshold || group.Paused || !group.Enabled || group.Archived {
+			delete(s.starved, group.ID)
+
+			continue
+		}
+
+		if _, ok := s.starved[group.ID]; ok {
+			continue
+		}
+
+		s.starved[group.ID] = struct{}{}
+
+		s.notify(ctx, group, oldest, age)
+	}
+
+	return nil
+}
+
+// notify logs and audits a starved group and calls the callback.
+func (s *service) notify(ctx context.Context, group *store.Group, job *store.Job, age time.Duration) {
+	age = age.Truncate(time.Second)
+
+	s.log.WithFields(logrus.Fields{
+		"group_id":  group.ID,
+		"job_id":    job.ID,
+		"age":       age,
+		"threshold": s.cfg.Queue.Starvation.Threshold,
+	}).Warn("Oldest pending job has exceeded the starvation threshold")
+
+	if err := s.store.CreateAuditEntry(ctx, &store.AuditEntry{
+		ID:         uuid.New().String(),
+		Action:     store.AuditActionGroupStarved,
+		EntityType: store.AuditEntityGroup,
+		EntityID:   group.ID,
+		Actor:      "system",
+		Details:    fmt.Sprintf("Job %s has been pending for %s", job.ID, age),
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		s.log.WithError(err).Warn("Failed to create audit entry for starved group")
+	}
+
+	if s.starvedCallback != nil {
+		s.starvedCallback(group, job, age)
+	}
+}
+
+// OldestPending returns the longest waiting unpaused job, or nil if there is
+// none. Paused jobs and jobs not due until later are held on purpose and are
+// not counted; a job with a not_before waits from that time.
+func OldestPending(jobs []*store.Job, now time.Time) *store.Job {
+	var oldest *store.Job
+
+	for _, job := range jobs {
+		if job.Status != store.JobStatusPending || job.Paused || !job.Due(now) {
+			continue
+		}
+
+		if oldest == nil || waitingSince(job).Before(waitingSince(oldest)) {
+			oldest = job
+		}
+	}
+
+	return oldest
+}
+
+// waitingSince returns when a pending job started waiting for a runner.
+func waitingSince(job *store.Job) time.Time {
+	if job.NotBefore != nil && job.NotBefore.After(job.CreatedAt) {
+		return *job.NotBefore
+	}
+
+	return job.CreatedAt
+}
@@ -0,0 +1,364 @@
+package store
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/schedule"
+	"github.com/sirupsen/logrus"
+)
+
+// CachedStore wraps a Store with an in-process read-through cache for groups and
+// job templates. These are read on every dispatch cycle and most API calls but only
+// change on config sync or admin actions, so caching them avoids repeated queries.
+//
+// Any group or template write made through the CachedStore invalidates the whole
+// cache. Entries also expire after the configured TTL so that writes made by other
+// processes sharing the same database are eventually picked up, or sooner when
+// those processes announce their writes and Invalidate is called.
+type CachedStore struct {
+	Store
+
+	log logrus.FieldLogger
+	ttl time.Duration
+
+	mu               sync.RWMutex
+	generation       uint64
+	groups           map[string]cacheEntry[*Group]
+	groupList        *cacheEntry[[]*Group]
+	templates        map[string]cacheEntry[*JobTemplate]
+	templatesByGroup map[string]cacheEntry[[]*JobTemplate]
+	onChange         func()
+}
+
+// cacheEntry is a cached value with its expiry time.
+type cacheEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+// Ensure CachedStore implements Store.
+var _ Store = (*CachedStore)(nil)
+
+// NewCachedStore wraps st with a read-through cache whose entries live for ttl.
+func NewCachedStore(log logrus.FieldLogger, st Store, ttl time.Duration) *CachedStore {
+	c := &CachedStore{
+		Store: st,
+		log:   log.WithField("component", "store_cache"),
+		ttl:   ttl,
+	}
+
+	c.reset()
+
+	return c
+}
+
+// Invalidate drops all cached entries.
+func (c *CachedStore) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.log.Debug("Invalidating store cache")
+
+	// Bump the generation so in-flight reads that started before this write
+	// don't repopulate the cache with stale data.
+	c.generation++
+	c.reset()
+}
+
+// SetChangeCallback sets a function called after each group or template write
+// made through the CachedStore, for example to tell other processes to drop
+// their caches.
+func (c *CachedStore) SetChangeCallback(cb func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onChange = cb
+}
+
+// changed invalidates the cache after a write and runs the change callback.
+func (c *CachedStore) changed() {
+	c.Invalidate()
+
+	c.mu.RLock()
+	cb := c.onChange
+	c.mu.RUnlock()
+
+	if cb != nil {
+		cb()
+	}
+}
+
+// reset clears the cache. Callers must hold c.mu.
+func (c *CachedStore) reset() {
+	c.groups = make(map[string]cacheEntry[*Group])
+	c.groupList = nil
+	c.templates = make(map[string]cacheEntry[*JobTemplate])
+	c.templatesByGroup = make(map[string]cacheEntry[[]*JobTemplate])
+}
+
+// ============================================================================
+// Groups
+// ============================================================================
+
+// GetGroup retrieves a group by ID, serving from cache when possible.
+func (c *CachedStore) GetGroup(ctx context.Context, id string) (*Group, error) {
+	now := time.Now()
+
+	c.mu.RLock()
+	entry, ok := c.groups[id]
+	gen := c.generation
+	c.mu.RUnlock()
+
+	if ok && now.Before(entry.expiresAt) {
+		return copyGroup(entry.value), nil
+	}
+
+	group, err := c.Store.GetGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.generation == gen {
+		c.groups[id] = cacheEntry[*Group]{value: copyGroup(group), expiresAt: now.Add(c.ttl)}
+	}
+	c.mu.Unlock()
+
+	return group, nil
+}
+
+// ListGroups retrieves all groups, serving from cache when possible.
+func (c *CachedStore) ListGroups(ctx context.Context) ([]*Group, error) {
+	now := time.Now()
+
+	c.mu.RLock()
+	entry := c.groupList
+	gen := c.generation
+	c.mu.RUnlock()
+
+	if entry != nil && now.Before(entry.expiresAt) {
+		return copyGroups(entry.value), nil
+	}
+
+	groups, err := c.Store.ListGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.generation == gen {
+		c.groupList = &cacheEntry[[]*Group]{value: copyGroups(groups), expiresAt: now.Add(c.ttl)}
+	}
+	c.mu.Unlock()
+
+	return groups, nil
+}
+
+// CreateGroup creates a group and invalidates the cache.
+func (c *CachedStore) CreateGroup(ctx context.Context, group *Group) error {
+	defer c.changed()
+
+	return c.Store.CreateGroup(ctx, group)
+}
+
+// UpdateGroup updates a group and invalidates the cache.
+func (c *CachedStore) UpdateGroup(ctx context.Context, group *Group) error {
+	defer c.changed()
+
+	return c.Store.UpdateGroup(ctx, group)
+}
+
+// DeleteGroup deletes a group and invalidates the cache.
+func (c *CachedStore) DeleteGroup(ctx context.Context, id string) error {
+	defer c.changed()
+
+	return c.Store.DeleteGroup(ctx, id)
+}
+
+// ============================================================================
+// Job Templates
+// ============================================================================
+
+// GetJobTemplate retrieves a job template by ID, serving from cache when possible.
+func (c *CachedStore) GetJobTemplate(ctx context.Context, id string) (*JobTemplate, error) {
+	now := time.Now()
+
+	c.mu.RLock()
+	entry, ok := c.templates[id]
+	gen := c.generation
+	c.mu.RUnlock()
+
+	if ok && now.Before(entry.expiresAt) {
+		return copyTemplate(entry.value), nil
+	}
+
+	template, err := c.Store.GetJobTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.generation == gen {
+		c.templates[id] = cacheEntry[*JobTemplate]{value: copyTemplate(template), expiresAt: now.Add(c.ttl)}
+	}
+	c.mu.Unlock()
+
+	return template, nil
+}
+
+// ListJobTemplatesByGroup retrieves all job templates for a group, serving from cache when possible.
+func (c *CachedStore) ListJobTemplatesByGroup(ctx context.Context, groupID string) ([]*JobTemplate, error) {
+	now := time.Now()
+
+	c.mu.RLock()
+	entry, ok := c.templatesByGroup[groupID]
+	gen := c.generation
+	c.mu.RUnlock()
+
+	if ok && now.Before(entry.expiresAt) {
+		return copyTemplates(entry.value), nil
+	}
+
+	templates, err := c.Store.ListJobTemplatesByGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.generation == gen {
+		c.templatesByGroup[groupID] = cacheEntry[[]*JobTemplate]{value: copyTemplates(templates), expiresAt: now.Add(c.ttl)}
+	}
+	c.mu.Unlock()
+
+	return templates, nil
+}
+
+// CreateJobTemplate creates a job template and invalidates the cache.
+func (c *CachedStore) CreateJobTemplate(ctx context.Context, template *JobTemplate) error {
+	defer c.changed()
+
+	return c.Store.CreateJobTemplate(ctx, template)
+}
+
+// UpdateJobTemplate updates a job template and invalidates the cache.
+func (c *CachedStore) UpdateJobTemplate(ctx context.Context, template *JobTemplate) error {
+	defer c.changed()
+
+	return c.Store.UpdateJobTemplate(ctx, template)
+}
+
+// DeleteJobTemplate deletes a job template and invalidates the cache.
+func (c *CachedStore) DeleteJobTemplate(ctx context.Context, id string) error {
+	defer c.changed()
+
+	return c.Store.DeleteJobTemplate(ctx, id)
+}
+
+// DeleteJobTemplatesByGroup deletes a group's job templates and invalidates the cache.
+func (c *CachedStore) DeleteJobTemplatesByGroup(ctx context.Context, groupID string) error {
+	defer c.changed()
+
+	return c.Store.DeleteJobTemplatesByGroup(ctx, groupID)
+}
+
+// UpdateTemplateInConfig updates a template's in_config status and invalidates the cache.
+func (c *CachedStore) UpdateTemplateInConfig(ctx context.Context, id string, inConfig bool) error {
+	defer c.changed()
+
+	return c.Store.UpdateTemplateInConfig(ctx, id, inConfig)
+}
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+// Cached values are deep-copied on the way in and out so callers that modify
+// the returned structs, their slices or their maps (e.g. before calling
+// UpdateGroup) never mutate the cache.
+
+func copyGroup(group *Group) *Group {
+	if group == nil {
+		return nil
+	}
+
+	cp := *group
+	cp.RunnerLabels = slices.Clone(group.RunnerLabels)
+	cp.ResumedAt = copyPtr(group.ResumedAt)
+	cp.ArchivedAt = copyPtr(group.ArchivedAt)
+	cp.MaxPriority = copyPtr(group.MaxPriority)
+	cp.MaxRequeueLimit = copyPtr(group.MaxRequeueLimit)
+
+	if group.Metadata != nil {
+		metadata := *group.Metadata
+		metadata.Links = slices.Clone(group.Metadata.Links)
+		metadata.Fields = maps.Clone(group.Metadata.Fields)
+		cp.Metadata = &metadata
+	}
+
+	return &cp
+}
+
+func copyGroups(groups []*Group) []*Group {
+	if groups == nil {
+		return nil
+	}
+
+	out := make([]*Group, len(groups))
+	for i, group := range groups {
+		out[i] = copyGroup(group)
+	}
+
+	return out
+}
+
+func copyTemplate(template *JobTemplate) *JobTemplate {
+	if template == nil {
+		return nil
+	}
+
+	cp := *template
+	cp.DefaultInputs = maps.Clone(template.DefaultInputs)
+	cp.Labels = maps.Clone(template.Labels)
+	cp.SunsetAt = copyPtr(template.SunsetAt)
+	cp.PinnedInputs = slices.Clone(template.PinnedInputs)
+	cp.SecretHandoffInputs = slices.Clone(template.SecretHandoffInputs)
+	cp.Overrides = copyPtr(template.Overrides)
+
+	if template.DispatchWindows != nil {
+		cp.DispatchWindows = make([]schedule.Window, len(template.DispatchWindows))
+		for i, window := range template.DispatchWindows {
+			window.Days = slices.Clone(window.Days)
+			cp.DispatchWindows[i] = window
+		}
+	}
+
+	return &cp
+}
+
+func copyTemplates(templates []*JobTemplate) []*JobTemplate {
+	if templates == nil {
+		return nil
+	}
+
+	out := make([]*JobTemplate, len(templates))
+	for i, template := range templates {
+		out[i] = copyTemplate(template)
+	}
+
+	return out
+}
+
+// copyPtr returns a pointer to a copy of *p, or nil.
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+
+	v := *p
+
+	return &v
+}
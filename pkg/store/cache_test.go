@@ -0,0 +1,311 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/schedule"
+	"github.com/sirupsen/logrus"
+)
+
+// countingStore counts the group reads reaching the wrapped store and, when
+// fill is set, holds each one after reading until fill is closed.
+type countingStore struct {
+	Store
+
+	groupReads atomic.Int32
+	entered    chan struct{}
+	fill       chan struct{}
+}
+
+func (s *countingStore) GetGroup(ctx context.Context, id string) (*Group, error) {
+	s.groupReads.Add(1)
+
+	group, err := s.Store.GetGroup(ctx, id)
+
+	if s.fill != nil {
+		s.entered <- struct{}{}
+		<-s.fill
+	}
+
+	return group, err
+}
+
+// newCacheTestStore returns a migrated SQLite store holding one group and one
+// template, wrapped in a counting store.
+func newCacheTestStore(t *testing.T) *countingStore {
+	t.Helper()
+
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	st := NewSQLiteStore(log, filepath.Join(t.TempDir(), "test.db"))
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+
+	t.Cleanup(func() { _ = st.Stop() })
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	if err := st.CreateGroup(ctx, &Group{
+		ID: "group", Name: "Group", RunnerLabels: []string{"self-hosted"}, Enabled: true,
+		Metadata:  &GroupMetadata{Team: "platform", Fields: map[string]string{"tier": "1"}},
+		CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create group: %v", err)
+	}
+
+	if err := st.CreateJobTemplate(ctx, &JobTemplate{
+		ID: "template", GroupID: "group", Name: "Template", Owner: "ethpandaops", Repo: "tests",
+		WorkflowID: "test.yml", Ref: "main", SourceType: "inline",
+		DefaultInputs:   input.Map{"network": input.String("hoodi")},
+		Labels:          map[string]string{"team": "devops"},
+		PinnedInputs:    []string{"network"},
+		DispatchWindows: []schedule.Window{{Days: []string{"mon"}, Start: "09:00", End: "17:00"}},
+		CreatedAt:       now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+
+	return &countingStore{Store: st}
+}
+
+func newTestCachedStore(st Store, ttl time.Duration) *CachedStore {
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	return NewCachedStore(log, st, ttl)
+}
+
+func TestCachedStoreInvalidatesOnWrite(t *testing.T) {
+	ctx := context.Background()
+	backing := newCacheTestStore(t)
+	cache := newTestCachedStore(backing, time.Hour)
+
+	for range 3 {
+		if _, err := cache.GetGroup(ctx, "group"); err != nil {
+			t.Fatalf("Failed to get group: %v", err)
+		}
+	}
+
+	if reads := backing.groupReads.Load(); reads != 1 {
+		t.Fatalf("Expected reads to be served from the cache, got %d store reads", reads)
+	}
+
+	group, err := cache.GetGroup(ctx, "group")
+	if err != nil {
+		t.Fatalf("Failed to get group: %v", err)
+	}
+
+	group.Name = "Renamed"
+
+	if err := cache.UpdateGroup(ctx, group); err != nil {
+		t.Fatalf("Failed to update group: %v", err)
+	}
+
+	got, err := cache.GetGroup(ctx, "group")
+	if err != nil || got.Name != "Renamed" {
+		t.Errorf("Expected the update to be read back, got %+v (%v)", got, err)
+	}
+
+	if reads := backing.groupReads.Load(); reads != 2 {
+		t.Errorf("Expected the write to invalidate the cached group, got %d store reads", reads)
+	}
+}
+
+func TestCachedStoreReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	cache := newTestCachedStore(newCacheTestStore(t), time.Hour)
+
+	// The first read fills the cache and the second is served from it; modify
+	// what both return.
+	for range 2 {
+		group, err := cache.GetGroup(ctx, "group")
+		if err != nil {
+			t.Fatalf("Failed to get group: %v", err)
+		}
+
+		group.RunnerLabels[0] = "mutated"
+		group.Metadata.Fields["tier"] = "mutated"
+		group.Metadata.Team = "mutated"
+	}
+
+	groups, err := cache.ListGroups(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list groups: %v", err)
+	}
+
+	groups[0].RunnerLabels[0] = "mutated"
+
+	got, err := cache.GetGroup(ctx, "group")
+	if err != nil {
+		t.Fatalf("Failed to get group: %v", err)
+	}
+
+	if got.RunnerLabels[0] != "self-hosted" || got.Metadata.Fields["tier"] != "1" || got.Metadata.Team != "platform" {
+		t.Errorf("Expected cached group to be unchanged, got %+v %+v", got, got.Metadata)
+	}
+
+	if groups, _ := cache.ListGroups(ctx); groups[0].RunnerLabels[0] != "self-hosted" {
+		t.Errorf("Expected cached group list to be unchanged, got %v", groups[0].RunnerLabels)
+	}
+
+	for range 2 {
+		template, err := cache.GetJobTemplate(ctx, "template")
+		if err != nil {
+			t.Fatalf("Failed to get template: %v", err)
+		}
+
+		template.DefaultInputs["network"] = input.String("mutated")
+		template.Labels["team"] = "mutated"
+		template.PinnedInputs[0] = "mutated"
+		template.DispatchWindows[0].Days[0] = "sun"
+
+		templates, err := cache.ListJobTemplatesByGroup(ctx, "group")
+		if err != nil {
+			t.Fatalf("Failed to list templates: %v", err)
+		}
+
+		templates[0].Labels["team"] = "mutated"
+	}
+
+	template, err := cache.GetJobTemplate(ctx, "template")
+	if err != nil {
+		t.Fatalf("Failed to get template: %v", err)
+	}
+
+	if template.DefaultInputs["network"].String() != "hoodi" || template.Labels["team"] != "devops" ||
+		template.PinnedInputs[0] != "network" || template.DispatchWindows[0].Days[0] != "mon" {
+		t.Errorf("Expected cached template to be unchanged, got %+v", template)
+	}
+
+	if templates, _ := cache.ListJobTemplatesByGroup(ctx, "group"); templates[0].Labels["team"] != "devops" {
+		t.Errorf("Expected cached template list to be unchanged, got %v", templates[0].Labels)
+	}
+}
+
+func TestCachedStoreGenerationGuard(t *testing.T) {
+	ctx := context.Background()
+	backing := newCacheTestStore(t)
+	backing.entered = make(chan struct{})
+	backing.fill = make(chan struct{})
+	cache := newTestCachedStore(backing, time.Hour)
+
+	done := make(chan *Group)
+
+	go func() {
+		group, _ := cache.GetGroup(ctx, "group")
+		done <- group
+	}()
+
+	// The fill has read the group; a write lands before it is stored.
+	<-backing.entered
+
+	renamed, err := backing.Store.GetGroup(ctx, "group")
+	if err != nil {
+		t.Fatalf("Failed to get group: %v", err)
+	}
+
+	renamed.Name = "Renamed"
+
+	if err := backing.Store.UpdateGroup(ctx, renamed); err != nil {
+		t.Fatalf("Failed to update group: %v", err)
+	}
+
+	cache.Invalidate()
+	close(backing.fill)
+
+	if stale := <-done; stale.Name != "Group" {
+		t.Fatalf("Expected the racing fill to read the old group, got %q", stale.Name)
+	}
+
+	backing.fill = nil
+
+	got, err := cache.GetGroup(ctx, "group")
+	if err != nil || got.Name != "Renamed" {
+		t.Errorf("Expected the racing fill not to be cached, got %+v (%v)", got, err)
+	}
+}
+
+func TestCachedStoreTTL(t *testing.T) {
+	ctx := context.Background()
+	backing := newCacheTestStore(t)
+	cache := newTestCachedStore(backing, 50*time.Millisecond)
+
+	if _, err := cache.GetGroup(ctx, "group"); err != nil {
+		t.Fatalf("Failed to get group: %v", err)
+	}
+
+	// Another process writes to the database, bypassing the cache.
+	renamed, err := backing.Store.GetGroup(ctx, "group")
+	if err != nil {
+		t.Fatalf("Failed to get group: %v", err)
+	}
+
+	renamed.Name = "Renamed"
+
+	if err := backing.Store.UpdateGroup(ctx, renamed); err != nil {
+		t.Fatalf("Failed to update group: %v", err)
+	}
+
+	if got, _ := cache.GetGroup(ctx, "group"); got.Name != "Group" {
+		t.Errorf("Expected the cached group before expiry, got %q", got.Name)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if got, _ := cache.GetGroup(ctx, "group"); got.Name != "Renamed" {
+		t.Errorf("Expected the write to be read after expiry, got %q", got.Name)
+	}
+}
+
+func TestCachedStoreChangeCallback(t *testing.T) {
+	ctx := context.Background()
+	cache := newTestCachedStore(newCacheTestStore(t), time.Hour)
+
+	var changes atomic.Int32
+
+	cache.SetChangeCallback(func() { changes.Add(1) })
+
+	if _, err := cache.GetGroup(ctx, "group"); err != nil {
+		t.Fatalf("Failed to get group: %v", err)
+	}
+
+	if _, err := cache.ListJobTemplatesByGroup(ctx, "group"); err != nil {
+		t.Fatalf("Failed to list templates: %v", err)
+	}
+
+	if n := changes.Load(); n != 0 {
+		t.Errorf("Expected reads not to report changes, got %d", n)
+	}
+
+	if err := cache.UpdateTemplateInConfig(ctx, "template", false); err != nil {
+		t.Fatalf("Failed to update template: %v", err)
+	}
+
+	if err := cache.DeleteGroup(ctx, "group"); err != nil {
+		t.Fatalf("Failed to delete group: %v", err)
+	}
+
+	if n := changes.Load(); n != 2 {
+		t.Errorf("Expected each write to report a change, got %d", n)
+	}
+
+	// Invalidations announced by other processes are not reported back.
+	cache.Invalidate()
+
+	if n := changes.Load(); n != 2 {
+		t.Errorf("Expected Invalidate not to report a change, got %d", n)
+	}
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"sort"
+	"time"
+)
+
+// fillHistoryStatsBuckets expands sparse per-index counts into opts.Buckets
+// consecutive buckets, filling gaps with zeros, and sums the totals.
+func fillHistoryStatsBuckets(
+	opts HistoryStatsOpts, bucketDuration time.Duration, counts map[int]*HistoryStatsBucket,
+) ([]*HistoryStatsBucket, HistoryStatsTotals) {
+	buckets := make([]*HistoryStatsBucket, opts.Buckets)
+	totals := HistoryStatsTotals{}
+
+	for i := range opts.Buckets {
+		bucketTime := opts.Start.Add(time.Duration(i) * bucketDuration)
+
+		if existing, ok := counts[i]; ok {
+			existing.Timestamp = bucketTime
+			buckets[i] = existing
+			totals.Completed += existing.Completed
+			totals.Failed += existing.Failed
+			totals.Cancelled += existing.Cancelled
+		} else {
+			buckets[i] = &HistoryStatsBucket{
+				Timestamp: bucketTime,
+			}
+		}
+	}
+
+	return buckets, totals
+}
+
+// buildHistoryStatsSeries turns per-key sparse counts into sorted, zero-filled series.
+func buildHistoryStatsSeries(
+	opts HistoryStatsOpts, bucketDuration time.Duration, counts map[string]map[int]*HistoryStatsBucket,
+) []*HistoryStatsSeries {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	series := make([]*HistoryStatsSeries, 0, len(keys))
+
+	for _, key := range keys {
+		buckets, totals := fillHistoryStatsBuckets(opts, bucketDuration, counts[key])
+		series = append(series, &HistoryStatsSeries{
+			Key:     key,
+			Buckets: buckets,
+			Totals:  totals,
+		})
+	}
+
+	return series
+}
@@ -0,0 +1,845 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Metrics interface for store query instrumentation.
+type Metrics interface {
+	RecordStoreQuery(method string, duration float64, failed bool)
+}
+
+// InstrumentedStore wraps a Store and records the latency of every call as a
+// metric labelled with the method name. Calls slower than the configured
+// threshold are logged at warn level so expensive queries show up without
+// enabling debug logging.
+type InstrumentedStore struct {
+	Store
+
+	log       logrus.FieldLogger
+	metrics   Metrics
+	threshold time.Duration
+}
+
+// Ensure InstrumentedStore implements Store.
+var _ Store = (*InstrumentedStore)(nil)
+
+// NewInstrumentedStore wraps st with query instrumentation. A negative
+// threshold disables slow query logging, as a negative
+// database.slow_query_threshold does; the config turns zero into its 500ms
+// default before it gets here.
+func NewInstrumentedStore(log logrus.FieldLogger, st Store, m Metrics, threshold time.Duration) *InstrumentedStore {
+	return &InstrumentedStore{
+		Store:     st,
+		log:       log.WithField("component", "store"),
+		metrics:   m,
+		threshold: threshold,
+	}
+}
+
+// observe records a finished store call.
+func (s *InstrumentedStore) observe(method string, start time.Time, err error) {
+	elapsed := time.Since(start)
+
+	s.metrics.RecordStoreQuery(method, elapsed.Seconds(), err != nil)
+
+	if s.threshold > 0 && elapsed >= s.threshold {
+		s.log.WithFields(logrus.Fields{
+			"method":   method,
+			"duration": elapsed.String(),
+		}).Warn("Slow store query")
+	}
+}
+
+// instrument runs fn, a store call returning a value, and records it.
+func instrument[T any](s *InstrumentedStore, method string, fn func() (T, error)) (T, error) {
+	start := time.Now()
+	result, err := fn()
+	s.observe(method, start, err)
+
+	return result, err
+}
+
+// instrumentExec runs fn, a store call returning only an error, and records it.
+func (s *InstrumentedStore) instrumentExec(method string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	s.observe(method, start, err)
+
+	return err
+}
+
+// The methods below forward to the wrapped store and record each call.
+
+// ============================================================================
+// Health check
+// ============================================================================
+
+func (s *InstrumentedStore) Ping(ctx context.Context) error {
+	return s.instrumentExec("Ping", func() error {
+		return s.Store.Ping(ctx)
+	})
+}
+
+// ============================================================================
+// Groups
+// ============================================================================
+
+func (s *InstrumentedStore) CreateGroup(ctx context.Context, group *Group) error {
+	return s.instrumentExec("CreateGroup", func() error {
+		return s.Store.CreateGroup(ctx, group)
+	})
+}
+
+func (s *InstrumentedStore) GetGroup(ctx context.Context, id string) (*Group, error) {
+	return instrument(s, "GetGroup", func() (*Group, error) {
+		return s.Store.GetGroup(ctx, id)
+	})
+}
+
+func (s *InstrumentedStore) ListGroups(ctx context.Context) ([]*Group, error) {
+	return instrument(s, "ListGroups", func() ([]*Group, error) {
+		return s.Store.ListGroups(ctx)
+	})
+}
+
+func (s *InstrumentedStore) UpdateGroup(ctx context.Context, group *Group) error {
+	return s.instrumentExec("UpdateGroup", func() error {
+		return s.Store.UpdateGroup(ctx, group)
+	})
+}
+
+func (s *InstrumentedStore) DeleteGroup(ctx context.Context, id string) error {
+	return s.instrumentExec("DeleteGroup", func() error {
+		return s.Store.DeleteGroup(ctx, id)
+	})
+}
+
+// ============================================================================
+// Job Templates
+// ============================================================================
+
+func (s *InstrumentedStore) CreateJobTemplate(ctx context.Context, template *JobTemplate) error {
+	return s.instrumentExec("CreateJobTemplate", func() error {
+		return s.Store.CreateJobTemplate(ctx, template)
+	})
+}
+
+func (s *InstrumentedStore) GetJobTemplate(ctx context.Context, id string) (*JobTemplate, error) {
+	return instrument(s, "GetJobTemplate", func() (*JobTemplate, error) {
+		return s.Store.GetJobTemplate(ctx, id)
+	})
+}
+
+func (s *InstrumentedStore) ListJobTemplatesByGroup(ctx context.Context, groupID string) ([]*JobTemplate, error) {
+	return instrument(s, "ListJobTemplatesByGroup", func() ([]*JobTemplate, error) {
+		return s.Store.ListJobTemplatesByGroup(ctx, groupID)
+	})
+}
+
+func (s *InstrumentedStore) UpdateJobTemplate(ctx context.Context, template *JobTemplate) error {
+	return s.instrumentExec("UpdateJobTemplate", func() error {
+		return s.Store.UpdateJobTemplate(ctx, template)
+	})
+}
+
+func (s *InstrumentedStore) DeleteJobTemplate(ctx context.Context, id string) error {
+	return s.instrumentExec("DeleteJobTemplate", func() error {
+		return s.Store.DeleteJobTemplate(ctx, id)
+	})
+}
+
+func (s *InstrumentedStore) DeleteJobTemplatesByGroup(ctx context.Context, groupID string) error {
+	return s.instrumentExec("DeleteJobTemplatesByGroup", func() error {
+		return s.Store.DeleteJobTemplatesByGroup(ctx, groupID)
+	})
+}
+
+func (s *InstrumentedStore) UpdateTemplateInConfig(ctx context.Context, id string, inConfig bool) error {
+	return s.instrumentExec("UpdateTemplateInConfig", func() error {
+		return s.Store.UpdateTemplateInConfig(ctx, id, inConfig)
+	})
+}
+
+func (s *InstrumentedStore) HasAnyJobs(ctx context.Context, templateID string) (bool, error) {
+	return instrument(s, "HasAnyJobs", func() (bool, error) {
+		return s.Store.HasAnyJobs(ctx, templateID)
+	})
+}
+
+// ============================================================================
+// Template Lints
+// ============================================================================
+
+func (s *InstrumentedStore) UpsertTemplateLint(ctx context.Context, lint *TemplateLint) error {
+	return s.instrumentExec("UpsertTemplateLint", func() error {
+		return s.Store.UpsertTemplateLint(ctx, lint)
+	})
+}
+
+func (s *InstrumentedStore) GetTemplateLint(ctx context.Context, templateID string) (*TemplateLint, error) {
+	return instrument(s, "GetTemplateLint", func() (*TemplateLint, error) {
+		return s.Store.GetTemplateLint(ctx, templateID)
+	})
+}
+
+// ============================================================================
+// Jobs
+// ============================================================================
+
+func (s *InstrumentedStore) CreateJob(ctx context.Context, job *Job) error {
+	return s.instrumentExec("CreateJob", func() error {
+		return s.Store.CreateJob(ctx, job)
+	})
+}
+
+func (s *InstrumentedStore) GetJob(ctx context.Context, id string) (*Job, error) {
+	return instrument(s, "GetJob", func() (*Job, error) {
+		return s.Store.GetJob(ctx, id)
+	})
+}
+
+func (s *InstrumentedStore) GetJobByRunID(ctx context.Context, runID int64) (*Job, error) {
+	return instrument(s, "GetJobByRunID", func() (*Job, error) {
+		return s.Store.GetJobByRunID(ctx, runID)
+	})
+}
+
+func (s *InstrumentedStore) ListJobsByGroup(
+	ctx context.Context, groupID string, statuses ...JobStatus,
+) ([]*Job, error) {
+	return instrument(s, "ListJobsByGroup", func() ([]*Job, error) {
+		return s.Store.ListJobsByGroup(ctx, groupID, statuses...)
+	})
+}
+
+func (s *InstrumentedStore) ListPendingJobs(ctx context.Context, opts PendingQueryOpts) (*PendingResult, error) {
+	return instrument(s, "ListPendingJobs", func() (*PendingResult, error) {
+		return s.Store.ListPendingJobs(ctx, opts)
+	})
+}
+
+func (s *InstrumentedStore) CountQueueJobs(ctx context.Context, groupID string) ([]*QueueCount, error) {
+	return instrument(s, "CountQueueJobs", func() ([]*QueueCount, error) {
+		return s.Store.CountQueueJobs(ctx, groupID)
+	})
+}
+
+func (s *InstrumentedStore) GetGroupJobCounts(ctx context.Context, since time.Time) ([]*GroupJobCounts, error) {
+	return instrument(s, "GetGroupJobCounts", func() ([]*GroupJobCounts, error) {
+		return s.Store.GetGroupJobCounts(ctx, since)
+	})
+}
+
+func (s *InstrumentedStore) ListJobsByIDPrefix(ctx context.Context, prefix string, limit int) ([]*Job, error) {
+	return instrument(s, "ListJobsByIDPrefix", func() ([]*Job, error) {
+		return s.Store.ListJobsByIDPrefix(ctx, prefix, limit)
+	})
+}
+
+func (s *InstrumentedStore) ListRecentFailures(ctx context.Context, since time.Time, limit int) ([]*Job, error) {
+	return instrument(s, "ListRecentFailures", func() ([]*Job, error) {
+		return s.Store.ListRecentFailures(ctx, since, limit)
+	})
+}
+
+func (s *InstrumentedStore) ListJobsByStatus(ctx context.Context, statuses ...JobStatus) ([]*Job, error) {
+	return instrument(s, "ListJobsByStatus", func() ([]*Job, error) {
+		return s.Store.ListJobsByStatus(ctx, statuses...)
+	})
+}
+
+func (s *InstrumentedStore) ListJobsCompletedSince(ctx context.Context, since time.Time, statuses ...JobStatus) ([]*Job, error) {
+	return instrument(s, "ListJobsCompletedSince", func() ([]*Job, error) {
+		return s.Store.ListJobsCompletedSince(ctx, since, statuses...)
+	})
+}
+
+func (s *InstrumentedStore) ListJobHistory(ctx context.Context, opts HistoryQueryOpts) (*HistoryResult, error) {
+	return instrument(s, "ListJobHistory", func() (*HistoryResult, error) {
+		return s.Store.ListJobHistory(ctx, opts)
+	})
+}
+
+func (s *InstrumentedStore) GetHistoryStats(ctx context.Context, opts HistoryStatsOpts) (*HistoryStatsResult, error) {
+	return instrument(s, "GetHistoryStats", func() (*HistoryStatsResult, error) {
+		return s.Store.GetHistoryStats(ctx, opts)
+	})
+}
+
+func (s *InstrumentedStore) GetHistoryTimeBounds(
+	ctx context.Context, groupID string,
+) (oldest, newest *time.Time, err error) {
+	start := time.Now()
+	oldest, newest, err = s.Store.GetHistoryTimeBounds(ctx, groupID)
+	s.observe("GetHistoryTimeBounds", start, err)
+
+	return oldest, newest, err
+}
+
+func (s *InstrumentedStore) GetTemplateDurations(
+	ctx context.Context, groupID string, since time.Time,
+) (map[string]time.Duration, error) {
+	return instrument(s, "GetTemplateDurations", func() (map[string]time.Duration, error) {
+		return s.Store.GetTemplateDurations(ctx, groupID, since)
+	})
+}
+
+func (s *InstrumentedStore) UpdateJob(ctx context.Context, job *Job) error {
+	return s.instrumentExec("UpdateJob", func() error {
+		return s.Store.UpdateJob(ctx, job)
+	})
+}
+
+func (s *InstrumentedStore) UpdateJobProgress(ctx context.Context, jobID string, progress *JobProgress) error {
+	return s.instrumentExec("UpdateJobProgress", func() error {
+		return s.Store.UpdateJobProgress(ctx, jobID, progress)
+	})
+}
+
+func (s *InstrumentedStore) RecordJobHeartbeat(ctx context.Context, jobID string, at time.Time) error {
+	return s.instrumentExec("RecordJobHeartbeat", func() error {
+		return s.Store.RecordJobHeartbeat(ctx, jobID, at)
+	})
+}
+
+func (s *InstrumentedStore) SetJobStalled(ctx context.Context, jobID string, stalledAt *time.Time) error {
+	return s.instrumentExec("SetJobStalled", func() error {
+		return s.Store.SetJobStalled(ctx, jobID, stalledAt)
+	})
+}
+
+func (s *InstrumentedStore) SetJobRetained(ctx context.Context, jobID string, retained bool) error {
+	return s.instrumentExec("SetJobRetained", func() error {
+		return s.Store.SetJobRetained(ctx, jobID, retained)
+	})
+}
+
+func (s *InstrumentedStore) SetJobVariant(ctx context.Context, jobID string, variant JobVariant) error {
+	return s.instrumentExec("SetJobVariant", func() error {
+		return s.Store.SetJobVariant(ctx, jobID, variant)
+	})
+}
+
+func (s *InstrumentedStore) SetJobHandoffSecrets(ctx context.Context, jobID string, secrets *HandoffSecrets) error {
+	return s.instrumentExec("SetJobHandoffSecrets", func() error {
+		return s.Store.SetJobHandoffSecrets(ctx, jobID, secrets)
+	})
+}
+
+func (s *InstrumentedStore) SetJobNotifyTargets(ctx context.Context, jobID string, targets []JobNotifyTarget) error {
+	return s.instrumentExec("SetJobNotifyTargets", func() error {
+		return s.Store.SetJobNotifyTargets(ctx, jobID, targets)
+	})
+}
+
+func (s *InstrumentedStore) ListJobsWithHandoffSecrets(ctx context.Context) ([]*Job, error) {
+	return instrument(s, "ListJobsWithHandoffSecrets", func() ([]*Job, error) {
+		return s.Store.ListJobsWithHandoffSecrets(ctx)
+	})
+}
+
+func (s *InstrumentedStore) SetJobAssignee(ctx context.Context, jobID, assignee string, assignedAt *time.Time) error {
+	return s.instrumentExec("SetJobAssignee", func() error {
+		return s.Store.SetJobAssignee(ctx, jobID, assignee, assignedAt)
+	})
+}
+
+func (s *InstrumentedStore) UpdateJobRunAttempt(ctx context.Context, jobID string, attempt int, logsURL, artifactsURL string) error {
+	return s.instrumentExec("UpdateJobRunAttempt", func() error {
+		return s.Store.UpdateJobRunAttempt(ctx, jobID, attempt, logsURL, artifactsURL)
+	})
+}
+
+func (s *InstrumentedStore) DeleteJob(ctx context.Context, id string) error {
+	return s.instrumentExec("DeleteJob", func() error {
+		return s.Store.DeleteJob(ctx, id)
+	})
+}
+
+func (s *InstrumentedStore) DeleteOldJobs(ctx context.Context, olderThan time.Time) (int64, error) {
+	return instrument(s, "DeleteOldJobs", func() (int64, error) {
+		return s.Store.DeleteOldJobs(ctx, olderThan)
+	})
+}
+
+func (s *InstrumentedStore) DeleteExcessJobs(ctx context.Context, groupID string, keep int) (int64, error) {
+	return instrument(s, "DeleteExcessJobs", func() (int64, error) {
+		return s.Store.DeleteExcessJobs(ctx, groupID, keep)
+	})
+}
+
+func (s *InstrumentedStore) ReorderJobs(ctx context.Context, groupID string, jobIDs []string) error {
+	return s.instrumentExec("ReorderJobs", func() error {
+		return s.Store.ReorderJobs(ctx, groupID, jobIDs)
+	})
+}
+
+func (s *InstrumentedStore) GetNextPendingJob(ctx context.Context, groupID string) (*Job, error) {
+	return instrument(s, "GetNextPendingJob", func() (*Job, error) {
+		return s.Store.GetNextPendingJob(ctx, groupID)
+	})
+}
+
+func (s *InstrumentedStore) CompactJobPositions(ctx context.Context, groupID string) (int, error) {
+	return instrument(s, "CompactJobPositions", func() (int, error) {
+		return s.Store.CompactJobPositions(ctx, groupID)
+	})
+}
+
+func (s *InstrumentedStore) GetMaxPosition(ctx context.Context, groupID string) (int, error) {
+	return instrument(s, "GetMaxPosition", func() (int, error) {
+		return s.Store.GetMaxPosition(ctx, groupID)
+	})
+}
+
+// ============================================================================
+// Runners
+// ============================================================================
+
+func (s *InstrumentedStore) UpsertRunner(ctx context.Context, runner *Runner) error {
+	return s.instrumentExec("UpsertRunner", func() error {
+		return s.Store.UpsertRunner(ctx, runner)
+	})
+}
+
+func (s *InstrumentedStore) GetRunner(ctx context.Context, id int64) (*Runner, error) {
+	return instrument(s, "GetRunner", func() (*Runner, error) {
+		return s.Store.GetRunner(ctx, id)
+	})
+}
+
+func (s *InstrumentedStore) GetRunnerByName(ctx context.Context, name string) (*Runner, error) {
+	return instrument(s, "GetRunnerByName", func() (*Runner, error) {
+		return s.Store.GetRunnerByName(ctx, name)
+	})
+}
+
+func (s *InstrumentedStore) ListRunners(ctx context.Context) ([]*Runner, error) {
+	return instrument(s, "ListRunners", func() ([]*Runner, error) {
+		return s.Store.ListRunners(ctx)
+	})
+}
+
+func (s *InstrumentedStore) ListRunnersByLabels(ctx context.Context, labels []string) ([]*Runner, error) {
+	return instrument(s, "ListRunnersByLabels", func() ([]*Runner, error) {
+		return s.Store.ListRunnersByLabels(ctx, labels)
+	})
+}
+
+func (s *InstrumentedStore) DeleteRunner(ctx context.Context, id int64) error {
+	return s.instrumentExec("DeleteRunner", func() error {
+		return s.Store.DeleteRunner(ctx, id)
+	})
+}
+
+func (s *InstrumentedStore) DeleteStaleRunners(ctx context.Context, olderThan time.Time) error {
+	return s.instrumentExec("DeleteStaleRunners", func() error {
+		return s.Store.DeleteStaleRunners(ctx, olderThan)
+	})
+}
+
+// ============================================================================
+// Users
+// ============================================================================
+
+func (s *InstrumentedStore) CreateUser(ctx context.Context, user *User) error {
+	return s.instrumentExec("CreateUser", func() error {
+		return s.Store.CreateUser(ctx, user)
+	})
+}
+
+func (s *InstrumentedStore) GetUser(ctx context.Context, id string) (*User, error) {
+	return instrument(s, "GetUser", func() (*User, error) {
+		return s.Store.GetUser(ctx, id)
+	})
+}
+
+func (s *InstrumentedStore) GetUserByUsername(ctx context.Context, username string) (*User, error) {
+	return instrument(s, "GetUserByUsername", func() (*User, error) {
+		return s.Store.GetUserByUsername(ctx, username)
+	})
+}
+
+func (s *InstrumentedStore) GetUserByGitHubID(ctx context.Context, githubID string) (*User, error) {
+	return instrument(s, "GetUserByGitHubID", func() (*User, error) {
+		return s.Store.GetUserByGitHubID(ctx, githubID)
+	})
+}
+
+func (s *InstrumentedStore) ListUsers(ctx context.Context) ([]*User, error) {
+	return instrument(s, "ListUsers", func() ([]*User, error) {
+		return s.Store.ListUsers(ctx)
+	})
+}
+
+func (s *InstrumentedStore) UpdateUser(ctx context.Context, user *User) error {
+	return s.instrumentExec("UpdateUser", func() error {
+		return s.Store.UpdateUser(ctx, user)
+	})
+}
+
+func (s *InstrumentedStore) DeleteUser(ctx context.Context, id string) error {
+	return s.instrumentExec("DeleteUser", func() error {
+		return s.Store.DeleteUser(ctx, id)
+	})
+}
+
+// ============================================================================
+// Sessions
+// ============================================================================
+
+func (s *InstrumentedStore) CreateSession(ctx context.Context, session *Session) error {
+	return s.instrumentExec("CreateSession", func() error {
+		return s.Store.CreateSession(ctx, session)
+	})
+}
+
+func (s *InstrumentedStore) GetSession(ctx context.Context, id string) (*Session, error) {
+	return instrument(s, "GetSession", func() (*Session, error) {
+		return s.Store.GetSession(ctx, id)
+	})
+}
+
+func (s *InstrumentedStore) GetSessionByToken(ctx context.Context, tokenHash string) (*Session, error) {
+	return instrument(s, "GetSessionByToken", func() (*Session, error) {
+		return s.Store.GetSessionByToken(ctx, tokenHash)
+	})
+}
+
+func (s *InstrumentedStore) ListSessions(ctx context.Context) ([]*Session, error) {
+	return instrument(s, "ListSessions", func() ([]*Session, error) {
+		return s.Store.ListSessions(ctx)
+	})
+}
+
+func (s *InstrumentedStore) ListUserSessions(ctx context.Context, userID string) ([]*Session, error) {
+	return instrument(s, "ListUserSessions", func() ([]*Session, error) {
+		return s.Store.ListUserSessions(ctx, userID)
+	})
+}
+
+func (s *InstrumentedStore) TouchSession(ctx context.Context, id string, usedAt time.Time, ipAddress, userAgent string) error {
+	return s.instrumentExec("TouchSession", func() error {
+		return s.Store.TouchSession(ctx, id, usedAt, ipAddress, userAgent)
+	})
+}
+
+func (s *InstrumentedStore) DeleteSession(ctx context.Context, id string) error {
+	return s.instrumentExec("DeleteSession", func() error {
+		return s.Store.DeleteSession(ctx, id)
+	})
+}
+
+func (s *InstrumentedStore) DeleteExpiredSessions(ctx context.Context) error {
+	return s.instrumentExec("DeleteExpiredSessions", func() error {
+		return s.Store.DeleteExpiredSessions(ctx)
+	})
+}
+
+func (s *InstrumentedStore) DeleteUserSessions(ctx context.Context, userID string) error {
+	return s.instrumentExec("DeleteUserSessions", func() error {
+		return s.Store.DeleteUserSessions(ctx, userID)
+	})
+}
+
+// ============================================================================
+// OAuth States (CSRF protection)
+// ============================================================================
+
+func (s *InstrumentedStore) CreateOAuthState(ctx context.Context, state *OAuthState) error {
+	return s.instrumentExec("CreateOAuthState", func() error {
+		return s.Store.CreateOAuthState(ctx, state)
+	})
+}
+
+func (s *InstrumentedStore) GetOAuthState(ctx context.Context, state string) (*OAuthState, error) {
+	return instrument(s, "GetOAuthState", func() (*OAuthState, error) {
+		return s.Store.GetOAuthState(ctx, state)
+	})
+}
+
+func (s *InstrumentedStore) DeleteOAuthState(ctx context.Context, state string) error {
+	return s.instrumentExec("DeleteOAuthState", func() error {
+		return s.Store.DeleteOAuthState(ctx, state)
+	})
+}
+
+func (s *InstrumentedStore) DeleteExpiredOAuthStates(ctx context.Context) error {
+	return s.instrumentExec("DeleteExpiredOAuthStates", func() error {
+		return s.Store.DeleteExpiredOAuthStates(ctx)
+	})
+}
+
+// ============================================================================
+// Auth Codes (one-time exchange codes)
+// ============================================================================
+
+func (s *InstrumentedStore) CreateAuthCode(ctx context.Context, code *AuthCode) error {
+	return s.instrumentExec("CreateAuthCode", func() error {
+		return s.Store.CreateAuthCode(ctx, code)
+	})
+}
+
+func (s *InstrumentedStore) GetAuthCode(ctx context.Context, code string) (*AuthCode, error) {
+	return instrument(s, "GetAuthCode", func() (*AuthCode, error) {
+		return s.Store.GetAuthCode(ctx, code)
+	})
+}
+
+func (s *InstrumentedStore) DeleteAuthCode(ctx context.Context, code string) error {
+	return s.instrumentExec("DeleteAuthCode", func() error {
+		return s.Store.DeleteAuthCode(ctx, code)
+	})
+}
+
+func (s *InstrumentedStore) DeleteExpiredAuthCodes(ctx context.Context) error {
+	return s.instrumentExec("DeleteExpiredAuthCodes", func() error {
+		return s.Store.DeleteExpiredAuthCodes(ctx)
+	})
+}
+
+// ============================================================================
+// Audit
+// ============================================================================
+
+func (s *InstrumentedStore) CreateQueueStatSamples(ctx context.Context, samples []*QueueStatSample) error {
+	return s.instrumentExec("CreateQueueStatSamples", func() error {
+		return s.Store.CreateQueueStatSamples(ctx, samples)
+	})
+}
+
+func (s *InstrumentedStore) ListQueueStatSamples(
+	ctx context.Context,
+	groupID string,
+	since time.Time,
+) ([]*QueueStatSample, error) {
+	return instrument(s, "ListQueueStatSamples", func() ([]*QueueStatSample, error) {
+		return s.Store.ListQueueStatSamples(ctx, groupID, since)
+	})
+}
+
+func (s *InstrumentedStore) DeleteQueueStatSamplesBefore(ctx context.Context, before time.Time) (int64, error) {
+	return instrument(s, "DeleteQueueStatSamplesBefore", func() (int64, error) {
+		return s.Store.DeleteQueueStatSamplesBefore(ctx, before)
+	})
+}
+
+func (s *InstrumentedStore) ListSettings(ctx context.Context) ([]*Setting, error) {
+	return instrument(s, "ListSettings", func() ([]*Setting, error) {
+		return s.Store.ListSettings(ctx)
+	})
+}
+
+func (s *InstrumentedStore) UpsertSetting(ctx context.Context, setting *Setting) error {
+	return s.instrumentExec("UpsertSetting", func() error {
+		return s.Store.UpsertSetting(ctx, setting)
+	})
+}
+
+func (s *InstrumentedStore) DeleteSetting(ctx context.Context, key string) error {
+	return s.instrumentExec("DeleteSetting", func() error {
+		return s.Store.DeleteSetting(ctx, key)
+	})
+}
+
+func (s *InstrumentedStore) CreateAuditEntry(ctx context.Context, entry *AuditEntry) error {
+	return s.instrumentExec("CreateAuditEntry", func() error {
+		return s.Store.CreateAuditEntry(ctx, entry)
+	})
+}
+
+func (s *InstrumentedStore) ListAuditEntries(ctx context.Context, opts AuditQueryOpts) ([]*AuditEntry, int, error) {
+	start := time.Now()
+	entries, total, err := s.Store.ListAuditEntries(ctx, opts)
+	s.observe("ListAuditEntries", start, err)
+
+	return entries, total, err
+}
+
+// ============================================================================
+// Saved Filters
+// ============================================================================
+
+func (s *InstrumentedStore) CreateSavedFilter(ctx context.Context, filter *SavedFilter) error {
+	return s.instrumentExec("CreateSavedFilter", func() error {
+		return s.Store.CreateSavedFilter(ctx, filter)
+	})
+}
+
+func (s *InstrumentedStore) GetSavedFilter(ctx context.Context, id string) (*SavedFilter, error) {
+	return instrument(s, "GetSavedFilter", func() (*SavedFilter, error) {
+		return s.Store.GetSavedFilter(ctx, id)
+	})
+}
+
+func (s *InstrumentedStore) ListSavedFiltersByUser(ctx context.Context, userID string) ([]*SavedFilter, error) {
+	return instrument(s, "ListSavedFiltersByUser", func() ([]*SavedFilter, error) {
+		return s.Store.ListSavedFiltersByUser(ctx, userID)
+	})
+}
+
+func (s *InstrumentedStore) UpdateSavedFilter(ctx context.Context, filter *SavedFilter) error {
+	return s.instrumentExec("UpdateSavedFilter", func() error {
+		return s.Store.UpdateSavedFilter(ctx, filter)
+	})
+}
+
+func (s *InstrumentedStore) CreateSubscription(ctx context.Context, sub *Subscription) error {
+	return s.instrumentExec("CreateSubscription", func() error {
+		return s.Store.CreateSubscription(ctx, sub)
+	})
+}
+
+func (s *InstrumentedStore) GetSubscription(ctx context.Context, id string) (*Subscription, error) {
+	return instrument(s, "GetSubscription", func() (*Subscription, error) {
+		return s.Store.GetSubscription(ctx, id)
+	})
+}
+
+func (s *InstrumentedStore) ListSubscriptionsByUser(ctx context.Context, userID string) ([]*Subscription, error) {
+	return instrument(s, "ListSubscriptionsByUser", func() ([]*Subscription, error) {
+		return s.Store.ListSubscriptionsByUser(ctx, userID)
+	})
+}
+
+func (s *InstrumentedStore) ListSubscriptionsByTarget(
+	ctx context.Context,
+	targetType SubscriptionTarget,
+	targetID string,
+) ([]*Subscription, error) {
+	return instrument(s, "ListSubscriptionsByTarget", func() ([]*Subscription, error) {
+		return s.Store.ListSubscriptionsByTarget(ctx, targetType, targetID)
+	})
+}
+
+func (s *InstrumentedStore) DeleteSubscription(ctx context.Context, id string) error {
+	return s.instrumentExec("DeleteSubscription", func() error {
+		return s.Store.DeleteSubscription(ctx, id)
+	})
+}
+
+func (s *InstrumentedStore) DeleteSubscriptionsByTarget(
+	ctx context.Context,
+	targetType SubscriptionTarget,
+	targetID string,
+) error {
+	return s.instrumentExec("DeleteSubscriptionsByTarget", func() error {
+		return s.Store.DeleteSubscriptionsByTarget(ctx, targetType, targetID)
+	})
+}
+
+func (s *InstrumentedStore) DeleteSavedFilter(ctx context.Context, id string) error {
+	return s.instrumentExec("DeleteSavedFilter", func() error {
+		return s.Store.DeleteSavedFilter(ctx, id)
+	})
+}
+
+// ============================================================================
+// Job Events
+// ============================================================================
+
+func (s *InstrumentedStore) CreateJobEvent(ctx context.Context, event *JobEvent) error {
+	return s.instrumentExec("CreateJobEvent", func() error {
+		return s.Store.CreateJobEvent(ctx, event)
+	})
+}
+
+func (s *InstrumentedStore) ListJobEvents(ctx context.Context, jobID string) ([]*JobEvent, error) {
+	return instrument(s, "ListJobEvents", func() ([]*JobEvent, error) {
+		return s.Store.ListJobEvents(ctx, jobID)
+	})
+}
+
+// ============================================================================
+// Job Run Attempts
+// ============================================================================
+
+func (s *InstrumentedStore) CreateJobRunAttempt(ctx context.Context, attempt *JobRunAttempt) error {
+	return s.instrumentExec("CreateJobRunAttempt", func() error {
+		return s.Store.CreateJobRunAttempt(ctx, attempt)
+	})
+}
+
+func (s *InstrumentedStore) ListJobRunAttempts(ctx context.Context, jobID string) ([]*JobRunAttempt, error) {
+	return instrument(s, "ListJobRunAttempts", func() ([]*JobRunAttempt, error) {
+		return s.Store.ListJobRunAttempts(ctx, jobID)
+	})
+}
+
+// ============================================================================
+// Campaigns
+// ============================================================================
+
+func (s *InstrumentedStore) CreateCampaign(ctx context.Context, campaign *Campaign) error {
+	return s.instrumentExec("CreateCampaign", func() error {
+		return s.Store.CreateCampaign(ctx, campaign)
+	})
+}
+
+func (s *InstrumentedStore) GetCampaign(ctx context.Context, id string) (*Campaign, error) {
+	return instrument(s, "GetCampaign", func() (*Campaign, error) {
+		return s.Store.GetCampaign(ctx, id)
+	})
+}
+
+func (s *InstrumentedStore) ListCampaigns(ctx context.Context) ([]*Campaign, error) {
+	return instrument(s, "ListCampaigns", func() ([]*Campaign, error) {
+		return s.Store.ListCampaigns(ctx)
+	})
+}
+
+func (s *InstrumentedStore) ListJobsByCampaign(ctx context.Context, campaignID string) ([]*Job, error) {
+	return instrument(s, "ListJobsByCampaign", func() ([]*Job, error) {
+		return s.Store.ListJobsByCampaign(ctx, campaignID)
+	})
+}
+
+// ============================================================================
+// Queue Changes
+// ============================================================================
+
+func (s *InstrumentedStore) CreateQueueChange(ctx context.Context, change *QueueChange) error {
+	return s.instrumentExec("CreateQueueChange", func() error {
+		return s.Store.CreateQueueChange(ctx, change)
+	})
+}
+
+func (s *InstrumentedStore) ListQueueChanges(
+	ctx context.Context, opts QueueChangeQueryOpts,
+) ([]*QueueChange, int, error) {
+	start := time.Now()
+	changes, total, err := s.Store.ListQueueChanges(ctx, opts)
+	s.observe("ListQueueChanges", start, err)
+
+	return changes, total, err
+}
+
+// ============================================================================
+// Workflow Locks
+// ============================================================================
+
+// AcquireWorkflowLock is not recorded: it blocks while another dispatch holds
+// the lock, which would be reported as a slow query.
+func (s *InstrumentedStore) AcquireWorkflowLock(ctx context.Context, key string) (func(), error) {
+	return s.Store.AcquireWorkflowLock(ctx, key)
+}
+
+// ============================================================================
+// Maintenance
+// ============================================================================
+
+// RunMaintenance is not recorded either: vacuuming a large database is
+// expected to take a while and would be reported as a slow query.
+func (s *InstrumentedStore) RunMaintenance(ctx context.Context, opts MaintenanceOpts) ([]*MaintenanceResult, error) {
+	return s.Store.RunMaintenance(ctx, opts)
+}
+
+func (s *InstrumentedStore) DatabaseStats(ctx context.Context) (*DatabaseStats, error) {
+	return instrument(s, "DatabaseStats", func() (*DatabaseStats, error) {
+		return s.Store.DatabaseStats(ctx)
+	})
+}
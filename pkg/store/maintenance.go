@@ -0,0 +1,32 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// runMaintenance executes maintenance statements in order, recording each
+// outcome and carrying on after failures.
+func runMaintenance(ctx context.Context, db *sql.DB, statements []string) ([]*MaintenanceResult, error) {
+	results := make([]*MaintenanceResult, 0, len(statements))
+
+	var errs []error
+
+	for _, statement := range statements {
+		start := time.Now()
+		_, err := db.ExecContext(ctx, statement)
+
+		result := &MaintenanceResult{Task: statement, Duration: time.Since(start)}
+		if err != nil {
+			result.Error = err.Error()
+			errs = append(errs, fmt.Errorf("running %s: %w", statement, err))
+		}
+
+		results = append(results, result)
+	}
+
+	return results, errors.Join(errs...)
+}
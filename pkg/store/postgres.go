@@ -3,9 +3,11 @@ package store
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -17,16 +19,30 @@ type PostgresStore struct {
 	log logrus.FieldLogger
 	dsn string
 	db  *sql.DB
+
+	// lockSlots bounds the connections pinned by workflow locks, each held or
+	// awaited lock taking one, so locks cannot exhaust the pool.
+	lockSlots chan struct{}
 }
 
 // Ensure PostgresStore implements Store.
 var _ Store = (*PostgresStore)(nil)
 
+const (
+	// postgresMaxOpenConns is the size of the connection pool.
+	postgresMaxOpenConns = 25
+
+	// postgresMaxLockConns is how many pool connections workflow locks may
+	// pin, leaving the rest for queries made while locks are held.
+	postgresMaxLockConns = 10
+)
+
 // NewPostgresStore creates a new PostgreSQL store.
 func NewPostgresStore(log logrus.FieldLogger, dsn string) Store {
 	return &PostgresStore{
-		log: log.WithField("component", "store"),
-		dsn: dsn,
+		log:       log.WithField("component", "store"),
+		dsn:       dsn,
+		lockSlots: make(chan struct{}, postgresMaxLockConns),
 	}
 }
 
@@ -40,7 +56,7 @@ func (s *PostgresStore) Start(ctx context.Context) error {
 	}
 
 	// Configure connection pool.
-	db.SetMaxOpenConns(25)
+	db.SetMaxOpenConns(postgresMaxOpenConns)
 	db.SetMaxIdleConns(5)
 	db.SetConnMaxLifetime(5 * time.Minute)
 
@@ -275,6 +291,397 @@ func (s *PostgresStore) Migrate(ctx context.Context) error {
 		EXCEPTION
 			WHEN duplicate_column THEN NULL;
 		END $$`,
+		// Migration: Add deprecation columns to job_templates table.
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN deprecated BOOLEAN DEFAULT FALSE;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN sunset_at TIMESTAMPTZ;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add outputs column to jobs table.
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN outputs JSONB;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add environment column to job_templates table.
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN environment TEXT NOT NULL DEFAULT '';
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add auto-pause columns to groups table.
+		`DO $$ BEGIN
+			ALTER TABLE groups ADD COLUMN paused_reason TEXT NOT NULL DEFAULT '';
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE groups ADD COLUMN resumed_at TIMESTAMPTZ;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add requeued_from column to jobs table.
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN requeued_from TEXT;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add resolved_sha column to jobs table.
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN resolved_sha TEXT;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Template lint results table.
+		`CREATE TABLE IF NOT EXISTS template_lints (
+			template_id TEXT PRIMARY KEY REFERENCES job_templates(id) ON DELETE CASCADE,
+			ref TEXT NOT NULL,
+			workflow_path TEXT NOT NULL DEFAULT '',
+			ok BOOLEAN NOT NULL DEFAULT FALSE,
+			issues JSONB NOT NULL DEFAULT '[]',
+			checked_at TIMESTAMPTZ NOT NULL
+		)`,
+		// Saved filters table.
+		`CREATE TABLE IF NOT EXISTS saved_filters (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			view TEXT NOT NULL,
+			group_id TEXT NOT NULL DEFAULT '',
+			statuses TEXT NOT NULL DEFAULT '[]',
+			labels TEXT NOT NULL DEFAULT '{}',
+			template_id TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL,
+			UNIQUE (user_id, view, name)
+		)`,
+		// Migration: Add category and display_order columns to job_templates table.
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN category TEXT NOT NULL DEFAULT '';
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN display_order INTEGER NOT NULL DEFAULT 0;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add original_created_by column to jobs table.
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN original_created_by TEXT;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add dispatch_windows column to job_templates table.
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN dispatch_windows JSONB;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add annotations column to jobs table.
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN annotations JSONB;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add archived and archived_at columns to groups table.
+		`DO $$ BEGIN
+			ALTER TABLE groups ADD COLUMN archived BOOLEAN NOT NULL DEFAULT false;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE groups ADD COLUMN archived_at TIMESTAMPTZ;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add pinned_inputs column to job_templates table.
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN pinned_inputs JSONB;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add min_idle_runners column to job_templates table.
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN min_idle_runners INTEGER NOT NULL DEFAULT 0;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Job events table (status transitions of each job).
+		`CREATE TABLE IF NOT EXISTS job_events (
+			id TEXT PRIMARY KEY,
+			job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+			from_status TEXT NOT NULL DEFAULT '',
+			to_status TEXT NOT NULL,
+			actor TEXT NOT NULL DEFAULT '',
+			run_id BIGINT,
+			runner_name TEXT NOT NULL DEFAULT '',
+			message TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_job_events_job ON job_events(job_id, created_at)`,
+		// Campaigns table (named sets of jobs tracked together).
+		`CREATE TABLE IF NOT EXISTS campaigns (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			metadata JSONB NOT NULL DEFAULT '{}',
+			created_by TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		)`,
+		// Migration: Add campaign_id column to jobs table.
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN campaign_id TEXT;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`CREATE INDEX IF NOT EXISTS idx_jobs_campaign ON jobs(campaign_id)`,
+		// Migration: Add scheduling_policy column to groups table.
+		`DO $$ BEGIN
+			ALTER TABLE groups ADD COLUMN scheduling_policy TEXT NOT NULL DEFAULT '';
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Queue changes table (manual queue edits with before/after snapshots).
+		// Not tied to jobs, so the record of a deleted job is kept.
+		`CREATE TABLE IF NOT EXISTS queue_changes (
+			id TEXT PRIMARY KEY,
+			group_id TEXT NOT NULL,
+			job_id TEXT NOT NULL DEFAULT '',
+			operation TEXT NOT NULL,
+			actor TEXT NOT NULL DEFAULT '',
+			before_snapshot JSONB NOT NULL DEFAULT '[]',
+			after_snapshot JSONB NOT NULL DEFAULT '[]',
+			created_at TIMESTAMPTZ NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_queue_changes_group ON queue_changes(group_id, created_at)`,
+		// Migration: Add client metadata columns to sessions table.
+		`DO $$ BEGIN
+			ALTER TABLE sessions ADD COLUMN ip_address TEXT NOT NULL DEFAULT '';
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE sessions ADD COLUMN user_agent TEXT NOT NULL DEFAULT '';
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE sessions ADD COLUMN last_used_at TIMESTAMPTZ;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add enqueue caps to groups and default priority to job_templates.
+		`DO $$ BEGIN
+			ALTER TABLE groups ADD COLUMN max_priority INTEGER;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE groups ADD COLUMN max_requeue_limit INTEGER;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN default_priority INTEGER NOT NULL DEFAULT 0;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add keep_running column to job_templates table.
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN keep_running INTEGER NOT NULL DEFAULT 0;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Index pending jobs in dispatch order for queue pagination.
+		`CREATE INDEX IF NOT EXISTS idx_jobs_group_status_order ON jobs(group_id, status, priority DESC, position, id)`,
+		// Migration: Add progress column to jobs table.
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN progress TEXT;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add stall detection columns.
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN stall_timeout_seconds INTEGER NOT NULL DEFAULT 0;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN heartbeat_at TIMESTAMPTZ;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN stalled_at TIMESTAMPTZ;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add subscriptions table.
+		`CREATE TABLE IF NOT EXISTS subscriptions (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			target_type TEXT NOT NULL,
+			target_id TEXT NOT NULL,
+			channel TEXT NOT NULL,
+			destination TEXT NOT NULL,
+			statuses TEXT NOT NULL DEFAULT '[]',
+			created_at TIMESTAMPTZ NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_subscriptions_target ON subscriptions(target_type, target_id)`,
+		// Migration: Add queue_stats table.
+		`CREATE TABLE IF NOT EXISTS queue_stats (
+			group_id TEXT NOT NULL,
+			sampled_at TIMESTAMPTZ NOT NULL,
+			pending INTEGER NOT NULL,
+			running INTEGER NOT NULL,
+			idle_runners INTEGER NOT NULL,
+			PRIMARY KEY (group_id, sampled_at)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_queue_stats_sampled_at ON queue_stats(sampled_at)`,
+		// Migration: Add job_run_attempts table.
+		`CREATE TABLE IF NOT EXISTS job_run_attempts (
+			job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+			attempt INTEGER NOT NULL,
+			status TEXT NOT NULL,
+			error_message TEXT NOT NULL DEFAULT '',
+			runner_name TEXT NOT NULL DEFAULT '',
+			logs_url TEXT NOT NULL DEFAULT '',
+			completed_at TIMESTAMPTZ,
+			PRIMARY KEY (job_id, attempt)
+		)`,
+		// Migration: Add workflow run attempt columns.
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN run_attempt INTEGER NOT NULL DEFAULT 0;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN logs_url TEXT;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN artifacts_url TEXT;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add retained column to jobs table.
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN retained BOOLEAN NOT NULL DEFAULT false;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add assignee columns to jobs table.
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN assignee TEXT;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN assigned_at TIMESTAMPTZ;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add canary columns.
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN canary_percent INTEGER NOT NULL DEFAULT 0;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN canary_workflow_id TEXT NOT NULL DEFAULT '';
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN canary_ref TEXT NOT NULL DEFAULT '';
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN variant TEXT;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE groups ADD COLUMN min_online_runners INTEGER NOT NULL DEFAULT 0;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN secret_handoff_inputs TEXT;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN secret_handoff_environment BOOLEAN NOT NULL DEFAULT false;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN handoff_secrets TEXT;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN max_duration_seconds INTEGER NOT NULL DEFAULT 0;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN max_duration_seconds INTEGER;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN notify_targets TEXT;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		// Migration: Add settings table.
+		`CREATE TABLE IF NOT EXISTS settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL,
+			updated_by TEXT NOT NULL DEFAULT '',
+			updated_at TIMESTAMPTZ NOT NULL
+		)`,
+		`DO $$ BEGIN
+			ALTER TABLE groups ADD COLUMN metadata JSONB;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE job_templates ADD COLUMN overrides JSONB;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE runners ADD COLUMN hosted BOOLEAN NOT NULL DEFAULT FALSE;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE runners ADD COLUMN max_runners INTEGER NOT NULL DEFAULT 0;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN not_before TIMESTAMPTZ;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
+		`DO $$ BEGIN
+			ALTER TABLE jobs ADD COLUMN source_issue TEXT;
+		EXCEPTION
+			WHEN duplicate_column THEN NULL;
+		END $$`,
 	}
 
 	for _, migration := range migrations {
@@ -297,11 +704,17 @@ func (s *PostgresStore) CreateGroup(ctx context.Context, group *Group) error {
 		return fmt.Errorf("marshaling runner_labels: %w", err)
 	}
 
+	metadataJSON, err := marshalGroupMetadata(group.Metadata)
+	if err != nil {
+		return err
+	}
+
 	_, err = s.db.ExecContext(ctx, `
-		INSERT INTO groups (id, name, description, runner_labels, enabled, paused, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO groups (id, name, description, runner_labels, enabled, paused, paused_reason, resumed_at, archived, archived_at, created_at, updated_at, scheduling_policy, max_priority, max_requeue_limit, min_online_runners, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 	`, group.ID, group.Name, group.Description, string(labelsJSON),
-		group.Enabled, group.Paused, group.CreatedAt, group.UpdatedAt)
+		group.Enabled, group.Paused, group.PausedReason, group.ResumedAt, group.Archived, group.ArchivedAt,
+		group.CreatedAt, group.UpdatedAt, group.SchedulingPolicy, group.MaxPriority, group.MaxRequeueLimit, group.MinOnlineRunners, metadataJSON)
 
 	if err != nil {
 		return fmt.Errorf("inserting group: %w", err)
@@ -312,15 +725,10 @@ func (s *PostgresStore) CreateGroup(ctx context.Context, group *Group) error {
 
 // GetGroup retrieves a group by ID.
 func (s *PostgresStore) GetGroup(ctx context.Context, id string) (*Group, error) {
-	var group Group
-
-	var labelsJSON string
-
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, name, description, runner_labels, enabled, paused, created_at, updated_at
+	group, err := scanGroup(s.db.QueryRowContext(ctx, `
+		SELECT `+groupSelectColumns()+`
 		FROM groups WHERE id = $1
-	`, id).Scan(&group.ID, &group.Name, &group.Description, &labelsJSON,
-		&group.Enabled, &group.Paused, &group.CreatedAt, &group.UpdatedAt)
+	`, id))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -330,17 +738,13 @@ func (s *PostgresStore) GetGroup(ctx context.Context, id string) (*Group, error)
 		return nil, fmt.Errorf("querying group: %w", err)
 	}
 
-	if err := json.Unmarshal([]byte(labelsJSON), &group.RunnerLabels); err != nil {
-		return nil, fmt.Errorf("unmarshaling runner_labels: %w", err)
-	}
-
-	return &group, nil
+	return group, nil
 }
 
 // ListGroups retrieves all groups.
 func (s *PostgresStore) ListGroups(ctx context.Context) ([]*Group, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, name, description, runner_labels, enabled, paused, created_at, updated_at
+		SELECT `+groupSelectColumns()+`
 		FROM groups ORDER BY name
 	`)
 	if err != nil {
@@ -352,20 +756,12 @@ func (s *PostgresStore) ListGroups(ctx context.Context) ([]*Group, error) {
 	var groups []*Group
 
 	for rows.Next() {
-		var group Group
-
-		var labelsJSON string
-
-		if err := rows.Scan(&group.ID, &group.Name, &group.Description, &labelsJSON,
-			&group.Enabled, &group.Paused, &group.CreatedAt, &group.UpdatedAt); err != nil {
+		group, err := scanGroup(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scanning group: %w", err)
 		}
 
-		if err := json.Unmarshal([]byte(labelsJSON), &group.RunnerLabels); err != nil {
-			return nil, fmt.Errorf("unmarshaling runner_labels: %w", err)
-		}
-
-		groups = append(groups, &group)
+		groups = append(groups, group)
 	}
 
 	return groups, rows.Err()
@@ -378,12 +774,21 @@ func (s *PostgresStore) UpdateGroup(ctx context.Context, group *Group) error {
 		return fmt.Errorf("marshaling runner_labels: %w", err)
 	}
 
+	metadataJSON, err := marshalGroupMetadata(group.Metadata)
+	if err != nil {
+		return err
+	}
+
 	group.UpdatedAt = time.Now()
 
 	_, err = s.db.ExecContext(ctx, `
-		UPDATE groups SET name = $1, description = $2, runner_labels = $3, enabled = $4, paused = $5, updated_at = $6
-		WHERE id = $7
-	`, group.Name, group.Description, string(labelsJSON), group.Enabled, group.Paused, group.UpdatedAt, group.ID)
+		UPDATE groups SET name = $1, description = $2, runner_labels = $3, enabled = $4, paused = $5, paused_reason = $6, resumed_at = $7,
+			archived = $8, archived_at = $9, updated_at = $10, scheduling_policy = $11,
+			max_priority = $12, max_requeue_limit = $13, min_online_runners = $14, metadata = $15
+		WHERE id = $16
+	`, group.Name, group.Description, string(labelsJSON), group.Enabled, group.Paused,
+		group.PausedReason, group.ResumedAt, group.Archived, group.ArchivedAt, group.UpdatedAt, group.SchedulingPolicy,
+		group.MaxPriority, group.MaxRequeueLimit, group.MinOnlineRunners, metadataJSON, group.ID)
 
 	if err != nil {
 		return fmt.Errorf("updating group: %w", err)
@@ -418,12 +823,36 @@ func (s *PostgresStore) CreateJobTemplate(ctx context.Context, template *JobTemp
 		return fmt.Errorf("marshaling labels: %w", err)
 	}
 
+	windowsJSON, err := marshalDispatchWindows(template.DispatchWindows)
+	if err != nil {
+		return err
+	}
+
+	pinnedJSON, err := marshalPinnedInputs(template.PinnedInputs)
+	if err != nil {
+		return err
+	}
+
+	handoffJSON, err := marshalSecretHandoffInputs(template.SecretHandoffInputs)
+	if err != nil {
+		return err
+	}
+
+	overridesJSON, err := marshalTemplateOverrides(template.Overrides)
+	if err != nil {
+		return err
+	}
+
 	_, err = s.db.ExecContext(ctx, `
-		INSERT INTO job_templates (id, group_id, name, owner, repo, workflow_id, ref, default_inputs, labels, in_config, source_type, source_path, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		INSERT INTO job_templates (id, group_id, name, owner, repo, workflow_id, ref, default_inputs, labels, in_config, source_type, source_path, deprecated, sunset_at, environment, category, display_order, dispatch_windows, pinned_inputs, min_idle_runners, default_priority, keep_running, stall_timeout_seconds, canary_percent, canary_workflow_id, canary_ref, secret_handoff_inputs, secret_handoff_environment, max_duration_seconds, overrides, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32)
 	`, template.ID, template.GroupID, template.Name, template.Owner, template.Repo,
 		template.WorkflowID, template.Ref, string(inputsJSON), string(labelsJSON), template.InConfig,
-		template.SourceType, template.SourcePath, template.CreatedAt, template.UpdatedAt)
+		template.SourceType, template.SourcePath, template.Deprecated, template.SunsetAt, template.Environment,
+		template.Category, template.DisplayOrder, windowsJSON, pinnedJSON, template.MinIdleRunners,
+		template.DefaultPriority, template.KeepRunning, template.StallTimeoutSeconds, template.CanaryPercent, template.CanaryWorkflowID,
+		template.CanaryRef, handoffJSON, template.SecretHandoffEnvironment, template.MaxDurationSeconds,
+		overridesJSON, template.CreatedAt, template.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("inserting job_template: %w", err)
@@ -434,16 +863,10 @@ func (s *PostgresStore) CreateJobTemplate(ctx context.Context, template *JobTemp
 
 // GetJobTemplate retrieves a job template by ID.
 func (s *PostgresStore) GetJobTemplate(ctx context.Context, id string) (*JobTemplate, error) {
-	var template JobTemplate
-
-	var inputsJSON, labelsJSON sql.NullString
-
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, group_id, name, owner, repo, workflow_id, ref, default_inputs, labels, in_config, source_type, source_path, created_at, updated_at
+	template, err := scanTemplate(s.db.QueryRowContext(ctx, `
+		SELECT `+templateSelectColumns()+`
 		FROM job_templates WHERE id = $1
-	`, id).Scan(&template.ID, &template.GroupID, &template.Name, &template.Owner,
-		&template.Repo, &template.WorkflowID, &template.Ref, &inputsJSON, &labelsJSON,
-		&template.InConfig, &template.SourceType, &template.SourcePath, &template.CreatedAt, &template.UpdatedAt)
+	`, id))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -453,26 +876,14 @@ func (s *PostgresStore) GetJobTemplate(ctx context.Context, id string) (*JobTemp
 		return nil, fmt.Errorf("querying job_template: %w", err)
 	}
 
-	if inputsJSON.Valid && inputsJSON.String != "" {
-		if err := json.Unmarshal([]byte(inputsJSON.String), &template.DefaultInputs); err != nil {
-			return nil, fmt.Errorf("unmarshaling default_inputs: %w", err)
-		}
-	}
-
-	if labelsJSON.Valid && labelsJSON.String != "" {
-		if err := json.Unmarshal([]byte(labelsJSON.String), &template.Labels); err != nil {
-			return nil, fmt.Errorf("unmarshaling labels: %w", err)
-		}
-	}
-
-	return &template, nil
+	return template, nil
 }
 
 // ListJobTemplatesByGroup retrieves all job templates for a group.
 func (s *PostgresStore) ListJobTemplatesByGroup(ctx context.Context, groupID string) ([]*JobTemplate, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, group_id, name, owner, repo, workflow_id, ref, default_inputs, labels, in_config, source_type, source_path, created_at, updated_at
-		FROM job_templates WHERE group_id = $1 ORDER BY name
+		SELECT `+templateSelectColumns()+`
+		FROM job_templates WHERE group_id = $1 ORDER BY display_order, name
 	`, groupID)
 	if err != nil {
 		return nil, fmt.Errorf("querying job_templates: %w", err)
@@ -483,29 +894,12 @@ func (s *PostgresStore) ListJobTemplatesByGroup(ctx context.Context, groupID str
 	var templates []*JobTemplate
 
 	for rows.Next() {
-		var template JobTemplate
-
-		var inputsJSON, labelsJSON sql.NullString
-
-		if err := rows.Scan(&template.ID, &template.GroupID, &template.Name, &template.Owner,
-			&template.Repo, &template.WorkflowID, &template.Ref, &inputsJSON, &labelsJSON,
-			&template.InConfig, &template.SourceType, &template.SourcePath, &template.CreatedAt, &template.UpdatedAt); err != nil {
+		template, err := scanTemplate(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scanning job_template: %w", err)
 		}
 
-		if inputsJSON.Valid && inputsJSON.String != "" {
-			if err := json.Unmarshal([]byte(inputsJSON.String), &template.DefaultInputs); err != nil {
-				return nil, fmt.Errorf("unmarshaling default_inputs: %w", err)
-			}
-		}
-
-		if labelsJSON.Valid && labelsJSON.String != "" {
-			if err := json.Unmarshal([]byte(labelsJSON.String), &template.Labels); err != nil {
-				return nil, fmt.Errorf("unmarshaling labels: %w", err)
-			}
-		}
-
-		templates = append(templates, &template)
+		templates = append(templates, template)
 	}
 
 	return templates, rows.Err()
@@ -523,13 +917,40 @@ func (s *PostgresStore) UpdateJobTemplate(ctx context.Context, template *JobTemp
 		return fmt.Errorf("marshaling labels: %w", err)
 	}
 
+	windowsJSON, err := marshalDispatchWindows(template.DispatchWindows)
+	if err != nil {
+		return err
+	}
+
+	pinnedJSON, err := marshalPinnedInputs(template.PinnedInputs)
+	if err != nil {
+		return err
+	}
+
+	handoffJSON, err := marshalSecretHandoffInputs(template.SecretHandoffInputs)
+	if err != nil {
+		return err
+	}
+
+	overridesJSON, err := marshalTemplateOverrides(template.Overrides)
+	if err != nil {
+		return err
+	}
+
 	template.UpdatedAt = time.Now()
 
 	_, err = s.db.ExecContext(ctx, `
-		UPDATE job_templates SET name = $1, owner = $2, repo = $3, workflow_id = $4, ref = $5, default_inputs = $6, labels = $7, in_config = $8, source_type = $9, source_path = $10, updated_at = $11
-		WHERE id = $12
+		UPDATE job_templates SET name = $1, owner = $2, repo = $3, workflow_id = $4, ref = $5, default_inputs = $6, labels = $7, in_config = $8, source_type = $9, source_path = $10, deprecated = $11, sunset_at = $12, environment = $13,
+			category = $14, display_order = $15, dispatch_windows = $16, pinned_inputs = $17, min_idle_runners = $18, default_priority = $19, keep_running = $20, stall_timeout_seconds = $21,
+			canary_percent = $22, canary_workflow_id = $23, canary_ref = $24, secret_handoff_inputs = $25,
+			secret_handoff_environment = $26, max_duration_seconds = $27, overrides = $28, updated_at = $29
+		WHERE id = $30
 	`, template.Name, template.Owner, template.Repo, template.WorkflowID, template.Ref,
-		string(inputsJSON), string(labelsJSON), template.InConfig, template.SourceType, template.SourcePath, template.UpdatedAt, template.ID)
+		string(inputsJSON), string(labelsJSON), template.InConfig, template.SourceType, template.SourcePath,
+		template.Deprecated, template.SunsetAt, template.Environment, template.Category, template.DisplayOrder,
+		windowsJSON, pinnedJSON, template.MinIdleRunners, template.DefaultPriority, template.KeepRunning,
+		template.StallTimeoutSeconds, template.CanaryPercent, template.CanaryWorkflowID, template.CanaryRef,
+		handoffJSON, template.SecretHandoffEnvironment, template.MaxDurationSeconds, overridesJSON, template.UpdatedAt, template.ID)
 
 	if err != nil {
 		return fmt.Errorf("updating job_template: %w", err)
@@ -584,6 +1005,61 @@ func (s *PostgresStore) HasAnyJobs(ctx context.Context, templateID string) (bool
 	return count > 0, nil
 }
 
+// ============================================================================
+// Template Lints
+// ============================================================================
+
+// UpsertTemplateLint stores the latest lint result for a template.
+func (s *PostgresStore) UpsertTemplateLint(ctx context.Context, lint *TemplateLint) error {
+	issuesJSON, err := json.Marshal(lint.Issues)
+	if err != nil {
+		return fmt.Errorf("marshaling issues: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO template_lints (template_id, ref, workflow_path, ok, issues, checked_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT(template_id) DO UPDATE SET
+			ref = excluded.ref,
+			workflow_path = excluded.workflow_path,
+			ok = excluded.ok,
+			issues = excluded.issues,
+			checked_at = excluded.checked_at
+	`, lint.TemplateID, lint.Ref, lint.WorkflowPath, lint.OK, string(issuesJSON), lint.CheckedAt)
+
+	if err != nil {
+		return fmt.Errorf("upserting template_lint: %w", err)
+	}
+
+	return nil
+}
+
+// GetTemplateLint retrieves the latest lint result for a template.
+func (s *PostgresStore) GetTemplateLint(ctx context.Context, templateID string) (*TemplateLint, error) {
+	var lint TemplateLint
+
+	var issuesJSON string
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT template_id, ref, workflow_path, ok, issues, checked_at
+		FROM template_lints WHERE template_id = $1
+	`, templateID).Scan(&lint.TemplateID, &lint.Ref, &lint.WorkflowPath, &lint.OK, &issuesJSON, &lint.CheckedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("querying template_lint: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(issuesJSON), &lint.Issues); err != nil {
+		return nil, fmt.Errorf("unmarshaling issues: %w", err)
+	}
+
+	return &lint, nil
+}
+
 // ============================================================================
 // Jobs
 // ============================================================================
@@ -606,13 +1082,21 @@ func (s *PostgresStore) CreateJob(ctx context.Context, job *Job) error {
 		templateID = sql.NullString{String: job.TemplateID, Valid: true}
 	}
 
+	campaignID := sql.NullString{String: job.CampaignID, Valid: job.CampaignID != ""}
+
+	notifyJSON, err := marshalNotifyTargets(job.NotifyTargets)
+	if err != nil {
+		return err
+	}
+
 	_, err = s.db.ExecContext(ctx, `
 		INSERT INTO jobs (id, group_id, template_id, priority, position, status, paused, auto_requeue, requeue_limit, requeue_count, inputs, created_by,
-		                  name, owner, repo, workflow_id, ref, labels, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		                  name, owner, repo, workflow_id, ref, labels, requeued_from, created_at, updated_at, campaign_id, max_duration_seconds, notify_targets, not_before, source_issue)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26)
 	`, job.ID, job.GroupID, templateID, job.Priority, job.Position, job.Status, job.Paused,
 		job.AutoRequeue, job.RequeueLimit, job.RequeueCount, string(inputsJSON), job.CreatedBy,
-		job.Name, job.Owner, job.Repo, job.WorkflowID, job.Ref, string(labelsJSON), job.CreatedAt, job.UpdatedAt)
+		job.Name, job.Owner, job.Repo, job.WorkflowID, job.Ref, string(labelsJSON), job.RequeuedFrom, job.CreatedAt, job.UpdatedAt,
+		campaignID, job.MaxDurationSeconds, notifyJSON, job.NotBefore, job.SourceIssue)
 
 	if err != nil {
 		return fmt.Errorf("inserting job: %w", err)
@@ -623,31 +1107,10 @@ func (s *PostgresStore) CreateJob(ctx context.Context, job *Job) error {
 
 // GetJob retrieves a job by ID.
 func (s *PostgresStore) GetJob(ctx context.Context, id string) (*Job, error) {
-	var job Job
-
-	var inputsJSON sql.NullString
-
-	var triggeredAt, completedAt sql.NullTime
-
-	var runID sql.NullInt64
-
-	var requeueLimit sql.NullInt64
-
-	var runURL, runnerName, errorMessage, createdBy sql.NullString
-
-	var runnerID sql.NullInt64
-
-	var templateID, name, owner, repo, workflowID, ref, labelsJSON sql.NullString
-
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, group_id, template_id, priority, position, status, paused, auto_requeue, requeue_limit, requeue_count, inputs, created_by,
-			   triggered_at, run_id, run_url, runner_id, runner_name, completed_at, error_message, created_at, updated_at,
-			   name, owner, repo, workflow_id, ref, labels
+	job, err := scanJob(s.db.QueryRowContext(ctx, `
+		SELECT `+jobSelectColumns("")+`
 		FROM jobs WHERE id = $1
-	`, id).Scan(&job.ID, &job.GroupID, &templateID, &job.Priority, &job.Position, &job.Status,
-		&job.Paused, &job.AutoRequeue, &requeueLimit, &job.RequeueCount, &inputsJSON, &createdBy, &triggeredAt, &runID, &runURL, &runnerID, &runnerName, &completedAt,
-		&errorMessage, &job.CreatedAt, &job.UpdatedAt,
-		&name, &owner, &repo, &workflowID, &ref, &labelsJSON)
+	`, id))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -657,69 +1120,25 @@ func (s *PostgresStore) GetJob(ctx context.Context, id string) (*Job, error) {
 		return nil, fmt.Errorf("querying job: %w", err)
 	}
 
-	if templateID.Valid {
-		job.TemplateID = templateID.String
-	}
-
-	if inputsJSON.Valid && inputsJSON.String != "" {
-		if err := json.Unmarshal([]byte(inputsJSON.String), &job.Inputs); err != nil {
-			return nil, fmt.Errorf("unmarshaling inputs: %w", err)
-		}
-	}
-
-	if triggeredAt.Valid {
-		job.TriggeredAt = &triggeredAt.Time
-	}
-
-	if completedAt.Valid {
-		job.CompletedAt = &completedAt.Time
-	}
-
-	if runID.Valid {
-		job.RunID = &runID.Int64
-	}
-
-	if runnerID.Valid {
-		job.RunnerID = &runnerID.Int64
-	}
-
-	if requeueLimit.Valid {
-		limit := int(requeueLimit.Int64)
-		job.RequeueLimit = &limit
-	}
-
-	job.RunURL = runURL.String
-	job.RunnerName = runnerName.String
-	job.ErrorMessage = errorMessage.String
-	job.CreatedBy = createdBy.String
-
-	if name.Valid {
-		job.Name = &name.String
-	}
-
-	if owner.Valid {
-		job.Owner = &owner.String
-	}
-
-	if repo.Valid {
-		job.Repo = &repo.String
-	}
+	return job, nil
+}
 
-	if workflowID.Valid {
-		job.WorkflowID = &workflowID.String
-	}
+// GetJobByRunID retrieves the job linked to a workflow run.
+func (s *PostgresStore) GetJobByRunID(ctx context.Context, runID int64) (*Job, error) {
+	job, err := scanJob(s.db.QueryRowContext(ctx, `
+		SELECT `+jobSelectColumns("")+`
+		FROM jobs WHERE run_id = $1 LIMIT 1
+	`, runID))
 
-	if ref.Valid {
-		job.Ref = &ref.String
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
 
-	if labelsJSON.Valid && labelsJSON.String != "" {
-		if err := json.Unmarshal([]byte(labelsJSON.String), &job.Labels); err != nil {
-			return nil, fmt.Errorf("unmarshaling job labels: %w", err)
-		}
+	if err != nil {
+		return nil, fmt.Errorf("querying job by run_id: %w", err)
 	}
 
-	return &job, nil
+	return job, nil
 }
 
 // ListJobsByGroup retrieves jobs for a group, optionally filtered by status.
@@ -727,9 +1146,7 @@ func (s *PostgresStore) ListJobsByGroup(
 	ctx context.Context, groupID string, statuses ...JobStatus,
 ) ([]*Job, error) {
 	query := `
-		SELECT id, group_id, template_id, priority, position, status, paused, auto_requeue, requeue_limit, requeue_count, inputs, created_by,
-			   triggered_at, run_id, run_url, runner_id, runner_name, completed_at, error_message, created_at, updated_at,
-			   name, owner, repo, workflow_id, ref, labels
+		SELECT ` + jobSelectColumns("") + `
 		FROM jobs WHERE group_id = $1
 	`
 
@@ -758,127 +1175,229 @@ func (s *PostgresStore) ListJobsByGroup(
 	return s.queryJobs(ctx, query, args...)
 }
 
-// ListJobsByStatus retrieves all jobs with the given statuses.
-func (s *PostgresStore) ListJobsByStatus(ctx context.Context, statuses ...JobStatus) ([]*Job, error) {
-	if len(statuses) == 0 {
-		return nil, nil
+// ListPendingJobs retrieves a page of a group's pending jobs in dispatch order.
+func (s *PostgresStore) ListPendingJobs(ctx context.Context, opts PendingQueryOpts) (*PendingResult, error) {
+	query := `
+		SELECT ` + jobSelectColumns("") + `
+		FROM jobs WHERE group_id = $1 AND status = 'pending'
+	`
+	args := []any{opts.GroupID}
+	result := &PendingResult{}
+
+	if after := opts.After; after != nil {
+		query += ` AND (priority < $2 OR (priority = $2 AND (position > $3 OR (position = $3 AND id > $4))))`
+		args = append(args, after.Priority, after.Position, after.ID)
+
+		if err := s.db.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM jobs
+			WHERE group_id = $1 AND status = 'pending' AND paused = false
+			AND (priority > $2 OR (priority = $2 AND (position < $3 OR (position = $3 AND id <= $4))))
+		`, opts.GroupID, after.Priority, after.Position, after.ID,
+		).Scan(&result.Ahead); err != nil {
+			return nil, fmt.Errorf("counting pending jobs ahead: %w", err)
+		}
 	}
 
-	placeholders := make([]string, len(statuses))
-	args := make([]any, len(statuses))
+	// Fetch one extra to check if more exist.
+	query += fmt.Sprintf(" ORDER BY priority DESC, position, id LIMIT %d", opts.Limit+1)
 
-	for i, status := range statuses {
-		placeholders[i] = fmt.Sprintf("$%d", i+1)
-		args[i] = status
+	jobs, err := s.queryJobs(ctx, query, args...)
+	if err != nil {
+		return nil, err
 	}
 
-	query := fmt.Sprintf(`
-		SELECT id, group_id, template_id, priority, position, status, paused, auto_requeue, requeue_limit, requeue_count, inputs, created_by,
-			   triggered_at, run_id, run_url, runner_id, runner_name, completed_at, error_message, created_at, updated_at,
-			   name, owner, repo, workflow_id, ref, labels
-		FROM jobs WHERE status IN (%s) ORDER BY position
-	`, strings.Join(placeholders, ","))
+	if len(jobs) > opts.Limit {
+		result.HasMore = true
+		jobs = jobs[:opts.Limit]
+	}
 
-	return s.queryJobs(ctx, query, args...)
+	result.Jobs = jobs
+
+	return result, nil
 }
 
-func (s *PostgresStore) queryJobs(ctx context.Context, query string, args ...any) ([]*Job, error) {
-	rows, err := s.db.QueryContext(ctx, query, args...)
+// CountQueueJobs counts a group's pending, triggered and running jobs by
+// template, status and paused state.
+func (s *PostgresStore) CountQueueJobs(ctx context.Context, groupID string) ([]*QueueCount, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT COALESCE(template_id, ''), status, COALESCE(paused, false), COUNT(*)
+		FROM jobs
+		WHERE group_id = $1 AND status IN ('pending', 'triggered', 'running')
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3
+	`, groupID)
 	if err != nil {
-		return nil, fmt.Errorf("querying jobs: %w", err)
+		return nil, fmt.Errorf("counting queue jobs: %w", err)
 	}
 
 	defer rows.Close()
 
-	var jobs []*Job
+	var counts []*QueueCount
 
 	for rows.Next() {
-		var job Job
-
-		var inputsJSON sql.NullString
+		var count QueueCount
+		if err := rows.Scan(&count.TemplateID, &count.Status, &count.Paused, &count.Count); err != nil {
+			return nil, fmt.Errorf("scanning queue count: %w", err)
+		}
 
-		var triggeredAt, completedAt sql.NullTime
+		counts = append(counts, &count)
+	}
 
-		var runID sql.NullInt64
+	return counts, rows.Err()
+}
 
-		var requeueLimit sql.NullInt64
+// GetGroupJobCounts counts the active jobs of every group, and the jobs each
+// finished since the given time, in two aggregate queries.
+func (s *PostgresStore) GetGroupJobCounts(ctx context.Context, since time.Time) ([]*GroupJobCounts, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT group_id,
+			SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = 'triggered' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = 'running' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = 'completed' AND completed_at >= $1 THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = 'failed' AND completed_at >= $1 THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = 'cancelled' AND completed_at >= $1 THEN 1 ELSE 0 END)
+		FROM jobs
+		WHERE status IN ('pending', 'triggered', 'running') OR completed_at >= $1
+		GROUP BY group_id
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("counting group jobs: %w", err)
+	}
 
-		var runURL, runnerName, errorMessage, createdBy sql.NullString
+	defer rows.Close()
 
-		var runnerID sql.NullInt64
+	var counts []*GroupJobCounts
 
-		var templateID, name, owner, repo, workflowID, ref, labelsJSON sql.NullString
+	byGroup := make(map[string]*GroupJobCounts)
 
-		if err := rows.Scan(&job.ID, &job.GroupID, &templateID, &job.Priority, &job.Position,
-			&job.Status, &job.Paused, &job.AutoRequeue, &requeueLimit, &job.RequeueCount, &inputsJSON, &createdBy, &triggeredAt, &runID, &runURL, &runnerID, &runnerName,
-			&completedAt, &errorMessage, &job.CreatedAt, &job.UpdatedAt,
-			&name, &owner, &repo, &workflowID, &ref, &labelsJSON); err != nil {
-			return nil, fmt.Errorf("scanning job: %w", err)
+	for rows.Next() {
+		var count GroupJobCounts
+		if err := rows.Scan(&count.GroupID, &count.Pending, &count.Triggered, &count.Running,
+			&count.Completed, &count.Failed, &count.Cancelled); err != nil {
+			return nil, fmt.Errorf("scanning group job counts: %w", err)
 		}
 
-		if templateID.Valid {
-			job.TemplateID = templateID.String
-		}
+		counts = append(counts, &count)
+		byGroup[count.GroupID] = &count
+	}
 
-		if inputsJSON.Valid && inputsJSON.String != "" {
-			if err := json.Unmarshal([]byte(inputsJSON.String), &job.Inputs); err != nil {
-				return nil, fmt.Errorf("unmarshaling inputs: %w", err)
-			}
-		}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating group job counts: %w", err)
+	}
 
-		if triggeredAt.Valid {
-			job.TriggeredAt = &triggeredAt.Time
-		}
+	oldest, err := s.queryJobs(ctx, `
+		SELECT `+jobSelectColumns("")+`
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY group_id ORDER BY created_at, id) AS oldest_rank
+			FROM jobs WHERE status = 'pending' AND paused = false AND (not_before IS NULL OR not_before <= $1)
+		) ranked
+		WHERE oldest_rank = 1
+	`, time.Now())
+	if err != nil {
+		return nil, err
+	}
 
-		if completedAt.Valid {
-			job.CompletedAt = &completedAt.Time
+	for _, job := range oldest {
+		if count := byGroup[job.GroupID]; count != nil {
+			count.OldestPending = job
 		}
+	}
 
-		if runID.Valid {
-			job.RunID = &runID.Int64
-		}
+	return counts, nil
+}
 
-		if runnerID.Valid {
-			job.RunnerID = &runnerID.Int64
-		}
+// ListRecentFailures retrieves the jobs of all groups that failed since the
+// given time, most recent first.
+func (s *PostgresStore) ListRecentFailures(ctx context.Context, since time.Time, limit int) ([]*Job, error) {
+	query := `
+		SELECT ` + jobSelectColumns("") + `
+		FROM jobs
+		WHERE status = 'failed' AND completed_at >= $1
+		ORDER BY completed_at DESC, id DESC
+	` + fmt.Sprintf(" LIMIT %d", limit)
 
-		if requeueLimit.Valid {
-			limit := int(requeueLimit.Int64)
-			job.RequeueLimit = &limit
-		}
+	return s.queryJobs(ctx, query, since)
+}
 
-		job.RunURL = runURL.String
-		job.RunnerName = runnerName.String
-		job.ErrorMessage = errorMessage.String
-		job.CreatedBy = createdBy.String
+// ListJobsByIDPrefix retrieves the most recently created jobs whose ID starts
+// with prefix. The prefix must not contain LIKE wildcards.
+func (s *PostgresStore) ListJobsByIDPrefix(ctx context.Context, prefix string, limit int) ([]*Job, error) {
+	query := `
+		SELECT ` + jobSelectColumns("") + `
+		FROM jobs
+		WHERE id LIKE $1
+		ORDER BY created_at DESC, id DESC
+	` + fmt.Sprintf(" LIMIT %d", limit)
 
-		if name.Valid {
-			job.Name = &name.String
-		}
+	return s.queryJobs(ctx, query, prefix+"%")
+}
 
-		if owner.Valid {
-			job.Owner = &owner.String
-		}
+// ListJobsByStatus retrieves all jobs with the given statuses.
+func (s *PostgresStore) ListJobsByStatus(ctx context.Context, statuses ...JobStatus) ([]*Job, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
 
-		if repo.Valid {
-			job.Repo = &repo.String
-		}
+	placeholders := make([]string, len(statuses))
+	args := make([]any, len(statuses))
 
-		if workflowID.Valid {
-			job.WorkflowID = &workflowID.String
-		}
+	for i, status := range statuses {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = status
+	}
 
-		if ref.Valid {
-			job.Ref = &ref.String
-		}
+	query := fmt.Sprintf(`
+		SELECT `+jobSelectColumns("")+`
+		FROM jobs WHERE status IN (%s) ORDER BY position
+	`, strings.Join(placeholders, ","))
 
-		if labelsJSON.Valid && labelsJSON.String != "" {
-			if err := json.Unmarshal([]byte(labelsJSON.String), &job.Labels); err != nil {
-				return nil, fmt.Errorf("unmarshaling job labels: %w", err)
-			}
+	return s.queryJobs(ctx, query, args...)
+}
+
+// ListJobsCompletedSince returns jobs in one of statuses that have a workflow
+// run and completed at or after since, oldest first.
+func (s *PostgresStore) ListJobsCompletedSince(ctx context.Context, since time.Time, statuses ...JobStatus) ([]*Job, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(statuses))
+	args := make([]any, 0, len(statuses)+1)
+
+	for i, status := range statuses {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args = append(args, status)
+	}
+
+	args = append(args, since)
+
+	query := fmt.Sprintf(`
+		SELECT `+jobSelectColumns("")+`
+		FROM jobs WHERE status IN (%s) AND run_id IS NOT NULL AND completed_at >= $%d
+		ORDER BY completed_at
+	`, strings.Join(placeholders, ","), len(args))
+
+	return s.queryJobs(ctx, query, args...)
+}
+
+func (s *PostgresStore) queryJobs(ctx context.Context, query string, args ...any) ([]*Job, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying jobs: %w", err)
+	}
+
+	defer rows.Close()
+
+	var jobs []*Job
+
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning job: %w", err)
 		}
 
-		jobs = append(jobs, &job)
+		jobs = append(jobs, job)
 	}
 
 	return jobs, rows.Err()
@@ -896,18 +1415,30 @@ func (s *PostgresStore) UpdateJob(ctx context.Context, job *Job) error {
 		return fmt.Errorf("marshaling labels: %w", err)
 	}
 
+	outputsJSON, err := json.Marshal(job.Outputs)
+	if err != nil {
+		return fmt.Errorf("marshaling outputs: %w", err)
+	}
+
+	annotationsJSON, err := json.Marshal(job.Annotations)
+	if err != nil {
+		return fmt.Errorf("marshaling annotations: %w", err)
+	}
+
 	job.UpdatedAt = time.Now()
 
 	_, err = s.db.ExecContext(ctx, `
 		UPDATE jobs SET priority = $1, position = $2, status = $3, paused = $4, auto_requeue = $5, requeue_limit = $6, requeue_count = $7, inputs = $8,
 			   triggered_at = $9, run_id = $10, run_url = $11, runner_id = $12, runner_name = $13,
 			   completed_at = $14, error_message = $15, updated_at = $16,
-			   name = $17, owner = $18, repo = $19, workflow_id = $20, ref = $21, labels = $22
-		WHERE id = $23
+			   name = $17, owner = $18, repo = $19, workflow_id = $20, ref = $21, labels = $22, outputs = $23,
+			   resolved_sha = $24, created_by = $25, original_created_by = $26, annotations = $27, not_before = $28
+		WHERE id = $29
 	`, job.Priority, job.Position, job.Status, job.Paused, job.AutoRequeue, job.RequeueLimit, job.RequeueCount, string(inputsJSON),
 		job.TriggeredAt, job.RunID, job.RunURL, job.RunnerID, job.RunnerName,
 		job.CompletedAt, job.ErrorMessage, job.UpdatedAt,
-		job.Name, job.Owner, job.Repo, job.WorkflowID, job.Ref, string(labelsJSON), job.ID)
+		job.Name, job.Owner, job.Repo, job.WorkflowID, job.Ref, string(labelsJSON), string(outputsJSON),
+		job.ResolvedSHA, job.CreatedBy, job.OriginalCreatedBy, string(annotationsJSON), job.NotBefore, job.ID)
 
 	if err != nil {
 		return fmt.Errorf("updating job: %w", err)
@@ -916,30 +1447,177 @@ func (s *PostgresStore) UpdateJob(ctx context.Context, job *Job) error {
 	return nil
 }
 
-// DeleteJob deletes a job by ID.
-func (s *PostgresStore) DeleteJob(ctx context.Context, id string) error {
-	_, err := s.db.ExecContext(ctx, `DELETE FROM jobs WHERE id = $1`, id)
+// UpdateJobProgress sets the progress reported by a job's workflow run. A
+// progress report also counts as a heartbeat.
+func (s *PostgresStore) UpdateJobProgress(ctx context.Context, jobID string, progress *JobProgress) error {
+	data, err := json.Marshal(progress)
 	if err != nil {
-		return fmt.Errorf("deleting job: %w", err)
+		return fmt.Errorf("marshaling progress: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE jobs SET progress = $1, heartbeat_at = $2, stalled_at = NULL, updated_at = $3 WHERE id = $4
+	`, string(data), progress.UpdatedAt, time.Now(), jobID)
+	if err != nil {
+		return fmt.Errorf("updating job progress: %w", err)
 	}
 
 	return nil
 }
 
-// DeleteOldJobs deletes completed, failed, or cancelled jobs older than the given time.
-func (s *PostgresStore) DeleteOldJobs(ctx context.Context, olderThan time.Time) (int64, error) {
-	result, err := s.db.ExecContext(ctx, `
-		DELETE FROM jobs
-		WHERE status IN ('completed', 'failed', 'cancelled')
-		AND completed_at < $1
-	`, olderThan)
+// RecordJobHeartbeat records a heartbeat from a job's workflow run, clearing
+// its stalled flag.
+func (s *PostgresStore) RecordJobHeartbeat(ctx context.Context, jobID string, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET heartbeat_at = $1, stalled_at = NULL WHERE id = $2`, at, jobID)
 	if err != nil {
-		return 0, fmt.Errorf("deleting old jobs: %w", err)
+		return fmt.Errorf("recording job heartbeat: %w", err)
 	}
 
-	count, err := result.RowsAffected()
+	return nil
+}
+
+// SetJobStalled flags a job as stalled since stalledAt, or clears the flag
+// when stalledAt is nil.
+func (s *PostgresStore) SetJobStalled(ctx context.Context, jobID string, stalledAt *time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET stalled_at = $1 WHERE id = $2`, stalledAt, jobID)
 	if err != nil {
-		return 0, fmt.Errorf("getting rows affected: %w", err)
+		return fmt.Errorf("setting job stalled: %w", err)
+	}
+
+	return nil
+}
+
+// SetJobRetained sets whether a job is kept by history cleanup.
+func (s *PostgresStore) SetJobRetained(ctx context.Context, jobID string, retained bool) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET retained = $1 WHERE id = $2`, retained, jobID)
+	if err != nil {
+		return fmt.Errorf("setting job retained: %w", err)
+	}
+
+	return nil
+}
+
+// SetJobVariant records which variant of a canary template a job uses.
+func (s *PostgresStore) SetJobVariant(ctx context.Context, jobID string, variant JobVariant) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET variant = $1 WHERE id = $2`, string(variant), jobID)
+	if err != nil {
+		return fmt.Errorf("setting job variant: %w", err)
+	}
+
+	return nil
+}
+
+// SetJobHandoffSecrets records the hand-off secrets of a job; nil clears them.
+func (s *PostgresStore) SetJobHandoffSecrets(ctx context.Context, jobID string, secrets *HandoffSecrets) error {
+	data, err := marshalHandoffSecrets(secrets)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE jobs SET handoff_secrets = $1 WHERE id = $2`, data, jobID); err != nil {
+		return fmt.Errorf("setting job handoff secrets: %w", err)
+	}
+
+	return nil
+}
+
+// SetJobNotifyTargets replaces the notify targets of a job; nil clears them.
+func (s *PostgresStore) SetJobNotifyTargets(ctx context.Context, jobID string, targets []JobNotifyTarget) error {
+	data, err := marshalNotifyTargets(targets)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE jobs SET notify_targets = $1 WHERE id = $2`, data, jobID); err != nil {
+		return fmt.Errorf("setting job notify targets: %w", err)
+	}
+
+	return nil
+}
+
+// ListJobsWithHandoffSecrets returns the jobs whose hand-off secrets have not
+// been deleted yet.
+func (s *PostgresStore) ListJobsWithHandoffSecrets(ctx context.Context) ([]*Job, error) {
+	return s.queryJobs(ctx, `
+		SELECT `+jobSelectColumns("")+`
+		FROM jobs WHERE handoff_secrets IS NOT NULL ORDER BY created_at
+	`)
+}
+
+// SetJobAssignee sets who is investigating a job; an empty assignee clears it.
+func (s *PostgresStore) SetJobAssignee(ctx context.Context, jobID, assignee string, assignedAt *time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET assignee = $1, assigned_at = $2 WHERE id = $3`,
+		sql.NullString{String: assignee, Valid: assignee != ""}, assignedAt, jobID)
+	if err != nil {
+		return fmt.Errorf("setting job assignee: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateJobRunAttempt records the attempt of a job's workflow run and the URLs
+// of its logs and artifacts.
+func (s *PostgresStore) UpdateJobRunAttempt(ctx context.Context, jobID string, attempt int, logsURL, artifactsURL string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET run_attempt = $1, logs_url = $2, artifacts_url = $3 WHERE id = $4`,
+		attempt, logsURL, artifactsURL, jobID)
+	if err != nil {
+		return fmt.Errorf("updating job run attempt: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteJob deletes a job by ID.
+func (s *PostgresStore) DeleteJob(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM jobs WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting job: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteOldJobs deletes completed, failed, or cancelled jobs older than the
+// given time, except retained ones.
+func (s *PostgresStore) DeleteOldJobs(ctx context.Context, olderThan time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM jobs
+		WHERE status IN ('completed', 'failed', 'cancelled')
+		AND completed_at < $1
+		AND NOT retained
+	`, olderThan)
+	if err != nil {
+		return 0, fmt.Errorf("deleting old jobs: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+
+	return count, nil
+}
+
+// DeleteExcessJobs deletes the completed, failed, or cancelled jobs of a group
+// beyond the newest keep, in history order. Retained jobs are neither deleted
+// nor counted.
+func (s *PostgresStore) DeleteExcessJobs(ctx context.Context, groupID string, keep int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM jobs
+		WHERE id IN (
+			SELECT id FROM jobs
+			WHERE group_id = $1 AND status IN ('completed', 'failed', 'cancelled') AND NOT retained
+			ORDER BY completed_at DESC, id DESC
+			OFFSET $2
+		)
+	`, groupID, keep)
+	if err != nil {
+		return 0, fmt.Errorf("deleting excess jobs: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
 	}
 
 	return count, nil
@@ -968,9 +1646,7 @@ func (s *PostgresStore) ListJobHistory(ctx context.Context, opts HistoryQueryOpt
 	needsJoin := len(opts.Labels) > 0
 
 	query := `
-		SELECT j.id, j.group_id, j.template_id, j.priority, j.position, j.status, j.paused, j.auto_requeue, j.requeue_limit, j.requeue_count, j.inputs, j.created_by,
-			   j.triggered_at, j.run_id, j.run_url, j.runner_id, j.runner_name, j.completed_at, j.error_message, j.created_at, j.updated_at,
-			   j.name, j.owner, j.repo, j.workflow_id, j.ref, j.labels
+		SELECT ` + jobSelectColumns("j.") + `
 		FROM jobs j
 	`
 
@@ -983,6 +1659,22 @@ func (s *PostgresStore) ListJobHistory(ctx context.Context, opts HistoryQueryOpt
 		AND j.status IN (%s)
 	`, strings.Join(statusPlaceholders, ","))
 
+	if opts.TemplateID != "" {
+		query += fmt.Sprintf(" AND j.template_id = $%d", paramNum)
+		args = append(args, opts.TemplateID)
+		paramNum++
+	}
+
+	if opts.RetainedOnly {
+		query += " AND j.retained"
+	}
+
+	if opts.Assignee != "" {
+		query += fmt.Sprintf(" AND j.assignee = $%d", paramNum)
+		args = append(args, opts.Assignee)
+		paramNum++
+	}
+
 	// Add label filters using PostgreSQL JSONB extraction.
 	for key, value := range opts.Labels {
 		query += fmt.Sprintf(" AND CAST(t.labels AS jsonb)->>$%d = $%d", paramNum, paramNum+1)
@@ -991,17 +1683,27 @@ func (s *PostgresStore) ListJobHistory(ctx context.Context, opts HistoryQueryOpt
 	}
 
 	if opts.Before != nil {
-		query += fmt.Sprintf(" AND j.completed_at < $%d", paramNum)
-		args = append(args, *opts.Before)
-		paramNum++
+		if opts.BeforeID != "" {
+			query += fmt.Sprintf(" AND (j.completed_at, j.id) < ($%d, $%d)", paramNum, paramNum+1)
+			args = append(args, *opts.Before, opts.BeforeID)
+			paramNum += 2
+		} else {
+			query += fmt.Sprintf(" AND j.completed_at < $%d", paramNum)
+			args = append(args, *opts.Before)
+			paramNum++
+		}
 	}
 
-	query += " ORDER BY j.completed_at DESC"
+	query += " ORDER BY j.completed_at DESC, j.id DESC"
 
 	// Fetch one extra to check if more exist.
 	fetchLimit := opts.Limit + 1
 	query += fmt.Sprintf(" LIMIT %d", fetchLimit)
 
+	if opts.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", opts.Offset)
+	}
+
 	jobs, err := s.queryJobs(ctx, query, args...)
 	if err != nil {
 		return nil, err
@@ -1022,6 +1724,11 @@ func (s *PostgresStore) ListJobHistory(ctx context.Context, opts HistoryQueryOpt
 	if len(result.Jobs) > 0 {
 		lastJob := result.Jobs[len(result.Jobs)-1]
 		result.NextCursor = lastJob.CompletedAt
+		result.NextCursorID = lastJob.ID
+	}
+
+	if opts.SkipCount {
+		return result, nil
 	}
 
 	// Get total count with same filters.
@@ -1045,6 +1752,22 @@ func (s *PostgresStore) ListJobHistory(ctx context.Context, opts HistoryQueryOpt
 		AND j.status IN (%s)
 	`, strings.Join(countStatusPlaceholders, ","))
 
+	if opts.TemplateID != "" {
+		countQuery += fmt.Sprintf(" AND j.template_id = $%d", countParamNum)
+		countArgs = append(countArgs, opts.TemplateID)
+		countParamNum++
+	}
+
+	if opts.RetainedOnly {
+		countQuery += " AND j.retained"
+	}
+
+	if opts.Assignee != "" {
+		countQuery += fmt.Sprintf(" AND j.assignee = $%d", countParamNum)
+		countArgs = append(countArgs, opts.Assignee)
+		countParamNum++
+	}
+
 	for key, value := range opts.Labels {
 		countQuery += fmt.Sprintf(" AND CAST(t.labels AS jsonb)->>$%d = $%d", countParamNum, countParamNum+1)
 		countArgs = append(countArgs, key, value)
@@ -1091,6 +1814,57 @@ func (s *PostgresStore) GetHistoryTimeBounds(ctx context.Context, groupID string
 	return oldest, newest, nil
 }
 
+// GetTemplateDurations returns the mean time from trigger to completion of each
+// template's successfully completed jobs in a group, over jobs completed since
+// the given time. Templates with no such jobs are absent from the map.
+func (s *PostgresStore) GetTemplateDurations(
+	ctx context.Context, groupID string, since time.Time,
+) (map[string]time.Duration, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT template_id, triggered_at, completed_at
+		FROM jobs
+		WHERE group_id = $1 AND status = 'completed' AND template_id IS NOT NULL AND template_id != ''
+		AND triggered_at IS NOT NULL AND completed_at IS NOT NULL AND completed_at >= $2
+	`, groupID, since)
+	if err != nil {
+		return nil, fmt.Errorf("querying template durations: %w", err)
+	}
+
+	defer rows.Close()
+
+	totals := make(map[string]time.Duration)
+	counts := make(map[string]int)
+
+	for rows.Next() {
+		var (
+			templateID               string
+			triggeredAt, completedAt time.Time
+		)
+
+		if err := rows.Scan(&templateID, &triggeredAt, &completedAt); err != nil {
+			return nil, fmt.Errorf("scanning template duration: %w", err)
+		}
+
+		if completedAt.Before(triggeredAt) {
+			continue
+		}
+
+		totals[templateID] += completedAt.Sub(triggeredAt)
+		counts[templateID]++
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating template durations: %w", err)
+	}
+
+	durations := make(map[string]time.Duration, len(totals))
+	for templateID, total := range totals {
+		durations[templateID] = total / time.Duration(counts[templateID])
+	}
+
+	return durations, nil
+}
+
 // GetHistoryStats retrieves aggregated job statistics for a time range.
 func (s *PostgresStore) GetHistoryStats(ctx context.Context, opts HistoryStatsOpts) (*HistoryStatsResult, error) {
 	// Calculate bucket duration.
@@ -1151,29 +1925,9 @@ func (s *PostgresStore) GetHistoryStats(ctx context.Context, opts HistoryStatsOp
 	}
 
 	// Build the full buckets slice, filling empty buckets with zeros.
-	buckets := make([]*HistoryStatsBucket, opts.Buckets)
-	totals := HistoryStatsTotals{}
-
-	for i := range opts.Buckets {
-		bucketTime := opts.Start.Add(time.Duration(i) * bucketDuration)
-
-		if existing, ok := bucketCounts[i]; ok {
-			existing.Timestamp = bucketTime
-			buckets[i] = existing
-			totals.Completed += existing.Completed
-			totals.Failed += existing.Failed
-			totals.Cancelled += existing.Cancelled
-		} else {
-			buckets[i] = &HistoryStatsBucket{
-				Timestamp: bucketTime,
-				Completed: 0,
-				Failed:    0,
-				Cancelled: 0,
-			}
-		}
-	}
+	buckets, totals := fillHistoryStatsBuckets(opts, bucketDuration, bucketCounts)
 
-	return &HistoryStatsResult{
+	result := &HistoryStatsResult{
 		Buckets: buckets,
 		Range: HistoryStatsRange{
 			Start:          opts.Start,
@@ -1181,7 +1935,99 @@ func (s *PostgresStore) GetHistoryStats(ctx context.Context, opts HistoryStatsOp
 			BucketDuration: bucketDuration,
 		},
 		Totals: totals,
-	}, nil
+	}
+
+	if opts.GroupBy != "" {
+		series, err := s.getHistoryStatsSeries(ctx, opts, bucketSeconds, bucketDuration)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Series = series
+	}
+
+	return result, nil
+}
+
+// getHistoryStatsSeries returns per-bucket counts split by the dimension in opts.GroupBy.
+func (s *PostgresStore) getHistoryStatsSeries(
+	ctx context.Context, opts HistoryStatsOpts, bucketSeconds int64, bucketDuration time.Duration,
+) ([]*HistoryStatsSeries, error) {
+	var keyExpr string
+
+	args := []any{opts.Start, bucketSeconds, opts.GroupID, opts.Start, opts.End}
+
+	switch opts.GroupBy {
+	case HistoryStatsByTemplate:
+		keyExpr = "COALESCE(j.template_id, '')"
+	case HistoryStatsByCreatedBy:
+		keyExpr = "COALESCE(j.created_by, '')"
+	case HistoryStatsByVariant:
+		keyExpr = "COALESCE(j.variant, '')"
+	case HistoryStatsByLabel:
+		// Job-level label overrides take precedence over template labels.
+		keyExpr = "COALESCE(CAST(j.labels AS jsonb)->>$6, CAST(t.labels AS jsonb)->>$6, '')"
+		args = append(args, opts.LabelKey)
+	default:
+		return nil, fmt.Errorf("unsupported history stats dimension: %s", opts.GroupBy)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			FLOOR((EXTRACT(EPOCH FROM j.completed_at) - EXTRACT(EPOCH FROM $1::timestamptz)) / $2)::INTEGER AS bucket_idx,
+			%s AS dim_key,
+			SUM(CASE WHEN j.status = 'completed' THEN 1 ELSE 0 END) AS completed,
+			SUM(CASE WHEN j.status = 'failed' THEN 1 ELSE 0 END) AS failed,
+			SUM(CASE WHEN j.status = 'cancelled' THEN 1 ELSE 0 END) AS cancelled
+		FROM jobs j
+		LEFT JOIN job_templates t ON j.template_id = t.id
+		WHERE j.group_id = $3
+		AND j.completed_at >= $4
+		AND j.completed_at < $5
+		AND j.status IN ('completed', 'failed', 'cancelled')
+		GROUP BY bucket_idx, dim_key
+		ORDER BY bucket_idx
+	`, keyExpr)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying history stats series: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]map[int]*HistoryStatsBucket)
+
+	for rows.Next() {
+		var (
+			bucketIdx                    int
+			key                          string
+			completed, failed, cancelled int
+		)
+
+		if err := rows.Scan(&bucketIdx, &key, &completed, &failed, &cancelled); err != nil {
+			return nil, fmt.Errorf("scanning history stats series row: %w", err)
+		}
+
+		if bucketIdx < 0 || bucketIdx >= opts.Buckets {
+			continue
+		}
+
+		if counts[key] == nil {
+			counts[key] = make(map[int]*HistoryStatsBucket)
+		}
+
+		counts[key][bucketIdx] = &HistoryStatsBucket{
+			Completed: completed,
+			Failed:    failed,
+			Cancelled: cancelled,
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating history stats series rows: %w", err)
+	}
+
+	return buildHistoryStatsSeries(opts, bucketDuration, counts), nil
 }
 
 // ReorderJobs updates job positions based on the provided order.
@@ -1207,17 +2053,90 @@ func (s *PostgresStore) ReorderJobs(ctx context.Context, groupID string, jobIDs
 	return tx.Commit()
 }
 
+// CompactJobPositions renumbers the pending jobs of a group to consecutive
+// positions starting at 0, keeping their current order. It returns the number
+// of jobs whose position changed.
+func (s *PostgresStore) CompactJobPositions(ctx context.Context, groupID string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, position FROM jobs
+		WHERE group_id = $1 AND status = $2
+		ORDER BY position, created_at, id
+	`, groupID, JobStatusPending)
+	if err != nil {
+		return 0, fmt.Errorf("listing pending jobs: %w", err)
+	}
+
+	type jobPosition struct {
+		id       string
+		position int
+	}
+
+	var jobs []jobPosition
+
+	for rows.Next() {
+		var jp jobPosition
+		if err := rows.Scan(&jp.id, &jp.position); err != nil {
+			rows.Close()
+
+			return 0, fmt.Errorf("scanning job position: %w", err)
+		}
+
+		jobs = append(jobs, jp)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterating job positions: %w", err)
+	}
+
+	changed := 0
+	now := time.Now()
+
+	for i, jp := range jobs {
+		if jp.position == i {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE jobs SET position = $1, updated_at = $2 WHERE id = $3
+		`, i, now, jp.id); err != nil {
+			return 0, fmt.Errorf("updating job position: %w", err)
+		}
+
+		changed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return changed, nil
+}
+
 // GetNextPendingJob retrieves the next pending job for a group (lowest position).
-// Paused jobs are excluded from selection.
+// Paused jobs and jobs whose not_before is still ahead are excluded from
+// selection.
 func (s *PostgresStore) GetNextPendingJob(ctx context.Context, groupID string) (*Job, error) {
 	jobs, err := s.ListJobsByGroup(ctx, groupID, JobStatusPending)
 	if err != nil {
 		return nil, err
 	}
 
-	// Find first non-paused job.
+	now := time.Now()
+
+	// Find first non-paused job that is due.
 	for _, job := range jobs {
-		if !job.Paused {
+		if !job.Paused && job.Due(now) {
 			return job, nil
 		}
 	}
@@ -1256,8 +2175,8 @@ func (s *PostgresStore) UpsertRunner(ctx context.Context, runner *Runner) error
 	}
 
 	_, err = s.db.ExecContext(ctx, `
-		INSERT INTO runners (id, name, labels, status, busy, os, last_seen_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO runners (id, name, labels, status, busy, os, last_seen_at, created_at, updated_at, hosted, max_runners)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		ON CONFLICT(id) DO UPDATE SET
 			name = EXCLUDED.name,
 			labels = EXCLUDED.labels,
@@ -1265,9 +2184,11 @@ func (s *PostgresStore) UpsertRunner(ctx context.Context, runner *Runner) error
 			busy = EXCLUDED.busy,
 			os = EXCLUDED.os,
 			last_seen_at = EXCLUDED.last_seen_at,
-			updated_at = EXCLUDED.updated_at
+			updated_at = EXCLUDED.updated_at,
+			hosted = EXCLUDED.hosted,
+			max_runners = EXCLUDED.max_runners
 	`, runner.ID, runner.Name, string(labelsJSON), runner.Status, runner.Busy,
-		runner.OS, runner.LastSeenAt, runner.CreatedAt, runner.UpdatedAt)
+		runner.OS, runner.LastSeenAt, runner.CreatedAt, runner.UpdatedAt, runner.Hosted, runner.MaxRunners)
 
 	if err != nil {
 		return fmt.Errorf("upserting runner: %w", err)
@@ -1283,10 +2204,10 @@ func (s *PostgresStore) GetRunner(ctx context.Context, id int64) (*Runner, error
 	var labelsJSON string
 
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, name, labels, status, busy, os, last_seen_at, created_at, updated_at
+		SELECT id, name, labels, status, busy, os, last_seen_at, created_at, updated_at, hosted, max_runners
 		FROM runners WHERE id = $1
 	`, id).Scan(&runner.ID, &runner.Name, &labelsJSON, &runner.Status, &runner.Busy,
-		&runner.OS, &runner.LastSeenAt, &runner.CreatedAt, &runner.UpdatedAt)
+		&runner.OS, &runner.LastSeenAt, &runner.CreatedAt, &runner.UpdatedAt, &runner.Hosted, &runner.MaxRunners)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -1310,10 +2231,10 @@ func (s *PostgresStore) GetRunnerByName(ctx context.Context, name string) (*Runn
 	var labelsJSON string
 
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, name, labels, status, busy, os, last_seen_at, created_at, updated_at
+		SELECT id, name, labels, status, busy, os, last_seen_at, created_at, updated_at, hosted, max_runners
 		FROM runners WHERE name = $1
 	`, name).Scan(&runner.ID, &runner.Name, &labelsJSON, &runner.Status, &runner.Busy,
-		&runner.OS, &runner.LastSeenAt, &runner.CreatedAt, &runner.UpdatedAt)
+		&runner.OS, &runner.LastSeenAt, &runner.CreatedAt, &runner.UpdatedAt, &runner.Hosted, &runner.MaxRunners)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -1333,7 +2254,7 @@ func (s *PostgresStore) GetRunnerByName(ctx context.Context, name string) (*Runn
 // ListRunners retrieves all runners.
 func (s *PostgresStore) ListRunners(ctx context.Context) ([]*Runner, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, name, labels, status, busy, os, last_seen_at, created_at, updated_at
+		SELECT id, name, labels, status, busy, os, last_seen_at, created_at, updated_at, hosted, max_runners
 		FROM runners ORDER BY name
 	`)
 	if err != nil {
@@ -1354,7 +2275,7 @@ func (s *PostgresStore) ListRunnersByLabels(ctx context.Context, labels []string
 
 	// Build query using JSONB contains for each label.
 	query := `
-		SELECT id, name, labels, status, busy, os, last_seen_at, created_at, updated_at
+		SELECT id, name, labels, status, busy, os, last_seen_at, created_at, updated_at, hosted, max_runners
 		FROM runners WHERE `
 
 	conditions := make([]string, len(labels))
@@ -1387,7 +2308,7 @@ func (s *PostgresStore) scanRunners(rows *sql.Rows) ([]*Runner, error) {
 		var labelsJSON string
 
 		if err := rows.Scan(&runner.ID, &runner.Name, &labelsJSON, &runner.Status, &runner.Busy,
-			&runner.OS, &runner.LastSeenAt, &runner.CreatedAt, &runner.UpdatedAt); err != nil {
+			&runner.OS, &runner.LastSeenAt, &runner.CreatedAt, &runner.UpdatedAt, &runner.Hosted, &runner.MaxRunners); err != nil {
 			return nil, fmt.Errorf("scanning runner: %w", err)
 		}
 
@@ -1518,6 +2439,38 @@ func (s *PostgresStore) GetUserByGitHubID(ctx context.Context, githubID string)
 	return &user, nil
 }
 
+// ListUsers retrieves all users.
+func (s *PostgresStore) ListUsers(ctx context.Context) ([]*User, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, username, password_hash, role, auth_provider, github_id, created_at, updated_at
+		FROM users ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying users: %w", err)
+	}
+
+	defer rows.Close()
+
+	var users []*User
+
+	for rows.Next() {
+		var user User
+
+		var passwordHash, githubID sql.NullString
+
+		if err := rows.Scan(&user.ID, &user.Username, &passwordHash, &user.Role, &user.AuthProvider,
+			&githubID, &user.CreatedAt, &user.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning user: %w", err)
+		}
+
+		user.PasswordHash = passwordHash.String
+		user.GitHubID = githubID.String
+		users = append(users, &user)
+	}
+
+	return users, rows.Err()
+}
+
 // UpdateUser updates an existing user.
 func (s *PostgresStore) UpdateUser(ctx context.Context, user *User) error {
 	user.UpdatedAt = time.Now()
@@ -1551,9 +2504,10 @@ func (s *PostgresStore) DeleteUser(ctx context.Context, id string) error {
 // CreateSession creates a new session.
 func (s *PostgresStore) CreateSession(ctx context.Context, session *Session) error {
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO sessions (id, user_id, token_hash, expires_at, created_at)
-		VALUES ($1, $2, $3, $4, $5)
-	`, session.ID, session.UserID, session.TokenHash, session.ExpiresAt, session.CreatedAt)
+		INSERT INTO sessions (`+sessionSelectColumns()+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, session.ID, session.UserID, session.TokenHash, session.ExpiresAt, session.CreatedAt,
+		session.IPAddress, session.UserAgent, session.LastUsedAt)
 
 	if err != nil {
 		return fmt.Errorf("inserting session: %w", err)
@@ -1564,12 +2518,8 @@ func (s *PostgresStore) CreateSession(ctx context.Context, session *Session) err
 
 // GetSession retrieves a session by ID.
 func (s *PostgresStore) GetSession(ctx context.Context, id string) (*Session, error) {
-	var session Session
-
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, user_id, token_hash, expires_at, created_at
-		FROM sessions WHERE id = $1
-	`, id).Scan(&session.ID, &session.UserID, &session.TokenHash, &session.ExpiresAt, &session.CreatedAt)
+	session, err := scanSession(s.db.QueryRowContext(ctx,
+		`SELECT `+sessionSelectColumns()+` FROM sessions WHERE id = $1`, id))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -1579,17 +2529,13 @@ func (s *PostgresStore) GetSession(ctx context.Context, id string) (*Session, er
 		return nil, fmt.Errorf("querying session: %w", err)
 	}
 
-	return &session, nil
+	return session, nil
 }
 
 // GetSessionByToken retrieves a session by token hash.
 func (s *PostgresStore) GetSessionByToken(ctx context.Context, tokenHash string) (*Session, error) {
-	var session Session
-
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, user_id, token_hash, expires_at, created_at
-		FROM sessions WHERE token_hash = $1
-	`, tokenHash).Scan(&session.ID, &session.UserID, &session.TokenHash, &session.ExpiresAt, &session.CreatedAt)
+	session, err := scanSession(s.db.QueryRowContext(ctx,
+		`SELECT `+sessionSelectColumns()+` FROM sessions WHERE token_hash = $1`, tokenHash))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -1599,35 +2545,81 @@ func (s *PostgresStore) GetSessionByToken(ctx context.Context, tokenHash string)
 		return nil, fmt.Errorf("querying session by token: %w", err)
 	}
 
-	return &session, nil
+	return session, nil
 }
 
-// DeleteSession deletes a session by ID.
-func (s *PostgresStore) DeleteSession(ctx context.Context, id string) error {
-	_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = $1`, id)
-	if err != nil {
-		return fmt.Errorf("deleting session: %w", err)
-	}
+// ListSessions retrieves all sessions, including expired ones not yet cleaned up.
+func (s *PostgresStore) ListSessions(ctx context.Context) ([]*Session, error) {
+	return s.querySessions(ctx, `SELECT `+sessionSelectColumns()+` FROM sessions ORDER BY created_at`)
+}
 
-	return nil
+// ListUserSessions retrieves a user's sessions, including expired ones not yet cleaned up.
+func (s *PostgresStore) ListUserSessions(ctx context.Context, userID string) ([]*Session, error) {
+	return s.querySessions(ctx,
+		`SELECT `+sessionSelectColumns()+` FROM sessions WHERE user_id = $1 ORDER BY created_at`, userID)
 }
 
-// DeleteExpiredSessions deletes all expired sessions.
-func (s *PostgresStore) DeleteExpiredSessions(ctx context.Context) error {
-	_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at < $1`, time.Now())
+// querySessions runs a query selecting sessionSelectColumns.
+func (s *PostgresStore) querySessions(ctx context.Context, query string, args ...any) ([]*Session, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("deleting expired sessions: %w", err)
+		return nil, fmt.Errorf("querying sessions: %w", err)
 	}
 
-	return nil
-}
+	defer rows.Close()
 
-// DeleteUserSessions deletes all sessions for a user.
-func (s *PostgresStore) DeleteUserSessions(ctx context.Context, userID string) error {
-	_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = $1`, userID)
-	if err != nil {
-		return fmt.Errorf("deleting user sessions: %w", err)
-	}
+	var sessions []*Session
+
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning session: %w", err)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// TouchSession records a request made with a session.
+func (s *PostgresStore) TouchSession(ctx context.Context, id string, usedAt time.Time, ipAddress, userAgent string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE sessions SET last_used_at = $1, ip_address = $2, user_agent = $3 WHERE id = $4
+	`, usedAt, ipAddress, userAgent, id)
+	if err != nil {
+		return fmt.Errorf("touching session: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSession deletes a session by ID.
+func (s *PostgresStore) DeleteSession(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting session: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredSessions deletes all expired sessions.
+func (s *PostgresStore) DeleteExpiredSessions(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at < $1`, time.Now())
+	if err != nil {
+		return fmt.Errorf("deleting expired sessions: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteUserSessions deletes all sessions for a user.
+func (s *PostgresStore) DeleteUserSessions(ctx context.Context, userID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("deleting user sessions: %w", err)
+	}
 
 	return nil
 }
@@ -1867,3 +2859,697 @@ func (s *PostgresStore) DeleteExpiredAuthCodes(ctx context.Context) error {
 
 	return nil
 }
+
+// ============================================================================
+// Saved Filters
+// ============================================================================
+
+// CreateSavedFilter creates a new saved filter.
+func (s *PostgresStore) CreateSavedFilter(ctx context.Context, filter *SavedFilter) error {
+	statuses, labels, err := marshalSavedFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO saved_filters (`+savedFilterSelectColumns()+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, filter.ID, filter.UserID, filter.Name, filter.View, filter.GroupID, statuses, labels,
+		filter.TemplateID, filter.CreatedAt, filter.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("inserting saved_filter: %w", err)
+	}
+
+	return nil
+}
+
+// GetSavedFilter retrieves a saved filter by ID.
+func (s *PostgresStore) GetSavedFilter(ctx context.Context, id string) (*SavedFilter, error) {
+	filter, err := scanSavedFilter(s.db.QueryRowContext(ctx, `
+		SELECT `+savedFilterSelectColumns()+`
+		FROM saved_filters WHERE id = $1
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("querying saved_filter: %w", err)
+	}
+
+	return filter, nil
+}
+
+// ListSavedFiltersByUser retrieves all saved filters of a user, ordered by view and name.
+func (s *PostgresStore) ListSavedFiltersByUser(ctx context.Context, userID string) ([]*SavedFilter, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+savedFilterSelectColumns()+`
+		FROM saved_filters WHERE user_id = $1
+		ORDER BY view, name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying saved_filters: %w", err)
+	}
+
+	defer rows.Close()
+
+	var filters []*SavedFilter
+
+	for rows.Next() {
+		filter, err := scanSavedFilter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning saved_filter: %w", err)
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters, rows.Err()
+}
+
+// UpdateSavedFilter updates an existing saved filter.
+func (s *PostgresStore) UpdateSavedFilter(ctx context.Context, filter *SavedFilter) error {
+	filter.UpdatedAt = time.Now()
+
+	statuses, labels, err := marshalSavedFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE saved_filters
+		SET name = $1, view = $2, group_id = $3, statuses = $4, labels = $5,
+			template_id = $6, updated_at = $7
+		WHERE id = $8
+	`, filter.Name, filter.View, filter.GroupID, statuses, labels, filter.TemplateID, filter.UpdatedAt, filter.ID)
+
+	if err != nil {
+		return fmt.Errorf("updating saved_filter: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSavedFilter deletes a saved filter.
+func (s *PostgresStore) DeleteSavedFilter(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM saved_filters WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting saved_filter: %w", err)
+	}
+
+	return nil
+}
+
+// ============================================================================
+// Subscriptions
+// ============================================================================
+
+// CreateSubscription creates a new subscription.
+func (s *PostgresStore) CreateSubscription(ctx context.Context, sub *Subscription) error {
+	statuses, err := json.Marshal(sub.Statuses)
+	if err != nil {
+		return fmt.Errorf("marshaling statuses: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO subscriptions (`+subscriptionSelectColumns()+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, sub.ID, sub.UserID, sub.TargetType, sub.TargetID, sub.Channel, sub.Destination, string(statuses), sub.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("inserting subscription: %w", err)
+	}
+
+	return nil
+}
+
+// GetSubscription retrieves a subscription by ID.
+func (s *PostgresStore) GetSubscription(ctx context.Context, id string) (*Subscription, error) {
+	sub, err := scanSubscription(s.db.QueryRowContext(ctx, `
+		SELECT `+subscriptionSelectColumns()+`
+		FROM subscriptions WHERE id = $1
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("querying subscription: %w", err)
+	}
+
+	return sub, nil
+}
+
+// ListSubscriptionsByUser retrieves all subscriptions of a user, oldest first.
+func (s *PostgresStore) ListSubscriptionsByUser(ctx context.Context, userID string) ([]*Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+subscriptionSelectColumns()+`
+		FROM subscriptions WHERE user_id = $1
+		ORDER BY created_at, id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying subscriptions: %w", err)
+	}
+
+	return scanSubscriptions(rows)
+}
+
+// ListSubscriptionsByTarget retrieves all subscriptions to a job or template.
+func (s *PostgresStore) ListSubscriptionsByTarget(
+	ctx context.Context,
+	targetType SubscriptionTarget,
+	targetID string,
+) ([]*Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+subscriptionSelectColumns()+`
+		FROM subscriptions WHERE target_type = $1 AND target_id = $2
+		ORDER BY created_at, id
+	`, targetType, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("querying subscriptions: %w", err)
+	}
+
+	return scanSubscriptions(rows)
+}
+
+// DeleteSubscription deletes a subscription.
+func (s *PostgresStore) DeleteSubscription(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting subscription: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSubscriptionsByTarget deletes all subscriptions to a job or template.
+func (s *PostgresStore) DeleteSubscriptionsByTarget(ctx context.Context, targetType SubscriptionTarget, targetID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM subscriptions WHERE target_type = $1 AND target_id = $2`,
+		targetType, targetID)
+	if err != nil {
+		return fmt.Errorf("deleting subscriptions: %w", err)
+	}
+
+	return nil
+}
+
+// ============================================================================
+// Job Events
+// ============================================================================
+
+// CreateJobEvent records a job status transition.
+func (s *PostgresStore) CreateJobEvent(ctx context.Context, event *JobEvent) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO job_events (`+jobEventSelectColumns()+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, event.ID, event.JobID, event.FromStatus, event.ToStatus, event.Actor, event.RunID,
+		event.RunnerName, event.Message, event.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("inserting job_event: %w", err)
+	}
+
+	return nil
+}
+
+// ListJobEvents retrieves the events of a job, oldest first.
+func (s *PostgresStore) ListJobEvents(ctx context.Context, jobID string) ([]*JobEvent, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+jobEventSelectColumns()+`
+		FROM job_events WHERE job_id = $1
+		ORDER BY created_at, id
+	`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("querying job_events: %w", err)
+	}
+
+	defer rows.Close()
+
+	var events []*JobEvent
+
+	for rows.Next() {
+		event, err := scanJobEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning job_event: %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// ============================================================================
+// Job Run Attempts
+// ============================================================================
+
+// CreateJobRunAttempt records a finished run attempt of a job. An attempt
+// already recorded for the job is left unchanged.
+func (s *PostgresStore) CreateJobRunAttempt(ctx context.Context, attempt *JobRunAttempt) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO job_run_attempts (`+strings.Join(jobRunAttemptColumns, ", ")+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (job_id, attempt) DO NOTHING
+	`, attempt.JobID, attempt.Attempt, attempt.Status, attempt.ErrorMessage, attempt.RunnerName,
+		attempt.LogsURL, attempt.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("inserting job_run_attempt: %w", err)
+	}
+
+	return nil
+}
+
+// ListJobRunAttempts retrieves the recorded run attempts of a job, oldest first.
+func (s *PostgresStore) ListJobRunAttempts(ctx context.Context, jobID string) ([]*JobRunAttempt, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+strings.Join(jobRunAttemptColumns, ", ")+`
+		FROM job_run_attempts WHERE job_id = $1
+		ORDER BY attempt
+	`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("querying job_run_attempts: %w", err)
+	}
+
+	defer rows.Close()
+
+	var attempts []*JobRunAttempt
+
+	for rows.Next() {
+		attempt, err := scanJobRunAttempt(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning job_run_attempt: %w", err)
+		}
+
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, rows.Err()
+}
+
+// ============================================================================
+// Campaigns
+// ============================================================================
+
+// CreateCampaign creates a new campaign.
+func (s *PostgresStore) CreateCampaign(ctx context.Context, campaign *Campaign) error {
+	metadataJSON, err := json.Marshal(campaign.Metadata)
+	if err != nil {
+		return fmt.Errorf("marshaling metadata: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO campaigns (`+campaignSelectColumns()+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, campaign.ID, campaign.Name, campaign.Description, string(metadataJSON), campaign.CreatedBy,
+		campaign.CreatedAt, campaign.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("inserting campaign: %w", err)
+	}
+
+	return nil
+}
+
+// GetCampaign retrieves a campaign by ID.
+func (s *PostgresStore) GetCampaign(ctx context.Context, id string) (*Campaign, error) {
+	campaign, err := scanCampaign(s.db.QueryRowContext(ctx, `
+		SELECT `+campaignSelectColumns()+`
+		FROM campaigns WHERE id = $1
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("querying campaign: %w", err)
+	}
+
+	return campaign, nil
+}
+
+// ListCampaigns retrieves all campaigns, newest first.
+func (s *PostgresStore) ListCampaigns(ctx context.Context) ([]*Campaign, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+campaignSelectColumns()+`
+		FROM campaigns
+		ORDER BY created_at DESC, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying campaigns: %w", err)
+	}
+
+	defer rows.Close()
+
+	var campaigns []*Campaign
+
+	for rows.Next() {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning campaign: %w", err)
+		}
+
+		campaigns = append(campaigns, campaign)
+	}
+
+	return campaigns, rows.Err()
+}
+
+// ListJobsByCampaign retrieves the jobs of a campaign, oldest first.
+func (s *PostgresStore) ListJobsByCampaign(ctx context.Context, campaignID string) ([]*Job, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+jobSelectColumns("")+`
+		FROM jobs WHERE campaign_id = $1
+		ORDER BY created_at, id
+	`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("querying jobs: %w", err)
+	}
+
+	defer rows.Close()
+
+	var jobs []*Job
+
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning job: %w", err)
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// ============================================================================
+// Queue Changes
+// ============================================================================
+
+// CreateQueueChange records a manual change to a group's queue.
+func (s *PostgresStore) CreateQueueChange(ctx context.Context, change *QueueChange) error {
+	before, after, err := marshalQueueSnapshots(change)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO queue_changes (`+queueChangeSelectColumns()+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, change.ID, change.GroupID, change.JobID, change.Operation, change.Actor, before, after, change.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("inserting queue_change: %w", err)
+	}
+
+	return nil
+}
+
+// ListQueueChanges retrieves the queue changes of a group, newest first, with
+// the total number of matches ignoring Limit and Offset.
+func (s *PostgresStore) ListQueueChanges(ctx context.Context, opts QueueChangeQueryOpts) ([]*QueueChange, int, error) {
+	where := ` WHERE group_id = $1`
+	args := []any{opts.GroupID}
+
+	if opts.JobID != "" {
+		// Reorders record every pending job, so match them by snapshot content.
+		where += ` AND (job_id = $2 OR (job_id = '' AND before_snapshot @> $3))`
+		match, err := json.Marshal([]map[string]string{{"job_id": opts.JobID}})
+		if err != nil {
+			return nil, 0, fmt.Errorf("marshaling job_id: %w", err)
+		}
+
+		args = append(args, opts.JobID, string(match))
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM queue_changes`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting queue_changes: %w", err)
+	}
+
+	query := `SELECT ` + queueChangeSelectColumns() + ` FROM queue_changes` + where + ` ORDER BY created_at DESC, id`
+
+	if opts.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", opts.Limit)
+	}
+
+	if opts.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", opts.Offset)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("querying queue_changes: %w", err)
+	}
+
+	defer rows.Close()
+
+	var changes []*QueueChange
+
+	for rows.Next() {
+		change, err := scanQueueChange(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scanning queue_change: %w", err)
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, total, rows.Err()
+}
+
+// ============================================================================
+// Workflow Locks
+// ============================================================================
+
+// AcquireWorkflowLock takes a session-level advisory lock on a dedicated
+// connection. The lock is released with the connection if the process dies,
+// so it needs no lease. At most postgresMaxLockConns locks are held or
+// awaited at once; further callers wait for a slot.
+func (s *PostgresStore) AcquireWorkflowLock(ctx context.Context, key string) (func(), error) {
+	select {
+	case s.lockSlots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("acquiring workflow lock: %w", ctx.Err())
+	}
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		<-s.lockSlots
+
+		return nil, fmt.Errorf("getting connection for workflow lock: %w", err)
+	}
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock(hashtext($1))`, key); err != nil {
+		_ = conn.Close()
+		<-s.lockSlots
+
+		return nil, fmt.Errorf("acquiring workflow lock: %w", err)
+	}
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			defer func() { <-s.lockSlots }()
+
+			// Release even if the caller's context has been cancelled.
+			if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, key); err != nil {
+				s.log.WithError(err).WithField("key", key).Warn("Failed to release workflow lock")
+
+				// Discard the connection so the lock cannot outlive this call.
+				_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+			}
+
+			_ = conn.Close()
+		})
+	}, nil
+}
+
+// ============================================================================
+// Queue Stats
+// ============================================================================
+
+// CreateQueueStatSamples stores queue stat samples. A sample repeating the
+// group and time of a stored one is ignored.
+func (s *PostgresStore) CreateQueueStatSamples(ctx context.Context, samples []*QueueStatSample) error {
+	for _, sample := range samples {
+		if _, err := s.db.ExecContext(ctx, `
+			INSERT INTO queue_stats (group_id, sampled_at, pending, running, idle_runners)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (group_id, sampled_at) DO NOTHING
+		`, sample.GroupID, sample.SampledAt, sample.Pending, sample.Running, sample.IdleRunners); err != nil {
+			return fmt.Errorf("inserting queue_stats: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListQueueStatSamples retrieves a group's queue stat samples taken at or
+// after since, oldest first.
+func (s *PostgresStore) ListQueueStatSamples(ctx context.Context, groupID string, since time.Time) ([]*QueueStatSample, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT group_id, sampled_at, pending, running, idle_runners
+		FROM queue_stats WHERE group_id = $1 AND sampled_at >= $2
+		ORDER BY sampled_at
+	`, groupID, since)
+	if err != nil {
+		return nil, fmt.Errorf("querying queue_stats: %w", err)
+	}
+
+	defer rows.Close()
+
+	var samples []*QueueStatSample
+
+	for rows.Next() {
+		var sample QueueStatSample
+
+		if err := rows.Scan(&sample.GroupID, &sample.SampledAt, &sample.Pending, &sample.Running,
+			&sample.IdleRunners); err != nil {
+			return nil, fmt.Errorf("scanning queue_stats: %w", err)
+		}
+
+		samples = append(samples, &sample)
+	}
+
+	return samples, rows.Err()
+}
+
+// DeleteQueueStatSamplesBefore deletes the queue stat samples of every group
+// taken before the given time and returns how many were deleted.
+func (s *PostgresStore) DeleteQueueStatSamplesBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM queue_stats WHERE sampled_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting queue_stats: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// ============================================================================
+// Settings
+// ============================================================================
+
+// ListSettings returns every runtime setting override, ordered by key.
+func (s *PostgresStore) ListSettings(ctx context.Context) ([]*Setting, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT key, value, updated_by, updated_at FROM settings ORDER BY key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying settings: %w", err)
+	}
+
+	defer rows.Close()
+
+	var settings []*Setting
+
+	for rows.Next() {
+		var setting Setting
+
+		if err := rows.Scan(&setting.Key, &setting.Value, &setting.UpdatedBy, &setting.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning settings: %w", err)
+		}
+
+		settings = append(settings, &setting)
+	}
+
+	return settings, rows.Err()
+}
+
+// UpsertSetting creates or replaces a runtime setting override.
+func (s *PostgresStore) UpsertSetting(ctx context.Context, setting *Setting) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO settings (key, value, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT(key) DO UPDATE SET
+			value = excluded.value, updated_by = excluded.updated_by, updated_at = excluded.updated_at
+	`, setting.Key, setting.Value, setting.UpdatedBy, setting.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upserting setting: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSetting removes a runtime setting override, if there is one.
+func (s *PostgresStore) DeleteSetting(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM settings WHERE key = $1`, key); err != nil {
+		return fmt.Errorf("deleting setting: %w", err)
+	}
+
+	return nil
+}
+
+// ============================================================================
+// Maintenance
+// ============================================================================
+
+// maintenanceTables are the tables that churn as jobs come and go, and so
+// benefit most from regular vacuuming.
+var maintenanceTables = []string{"jobs", "job_events", "audit_log"}
+
+// RunMaintenance vacuums and/or analyzes the tables in maintenanceTables. Plain
+// VACUUM does not block reads or writes.
+func (s *PostgresStore) RunMaintenance(ctx context.Context, opts MaintenanceOpts) ([]*MaintenanceResult, error) {
+	var command string
+
+	switch {
+	case opts.Vacuum && opts.Analyze:
+		command = "VACUUM ANALYZE"
+	case opts.Vacuum:
+		command = "VACUUM"
+	case opts.Analyze:
+		command = "ANALYZE"
+	default:
+		return nil, nil
+	}
+
+	statements := make([]string, 0, len(maintenanceTables))
+	for _, table := range maintenanceTables {
+		statements = append(statements, command+" "+table)
+	}
+
+	return runMaintenance(ctx, s.db, statements)
+}
+
+// DatabaseStats reports the size of the database and, from the statistics
+// collector, the estimated live rows and total size of each table in the
+// current schema.
+func (s *PostgresStore) DatabaseStats(ctx context.Context) (*DatabaseStats, error) {
+	stats := &DatabaseStats{Driver: "postgres"}
+
+	if err := s.db.QueryRowContext(ctx, `SELECT pg_database_size(current_database())`).Scan(&stats.SizeBytes); err != nil {
+		return nil, fmt.Errorf("reading database size: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT relname, n_live_tup, pg_total_relation_size(relid)
+		FROM pg_stat_user_tables WHERE schemaname = current_schema()
+		ORDER BY relname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying table stats: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			table TableStats
+			size  int64
+		)
+
+		if err := rows.Scan(&table.Table, &table.Rows, &size); err != nil {
+			return nil, fmt.Errorf("scanning table stats: %w", err)
+		}
+
+		table.SizeBytes = &size
+		stats.Tables = append(stats.Tables, &table)
+	}
+
+	return stats, rows.Err()
+}
@@ -0,0 +1,725 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethpandaops/dispatchoor/pkg/schedule"
+)
+
+// jobColumns lists the jobs table columns read by scanJob, in scan order.
+var jobColumns = []string{
+	"id", "group_id", "template_id", "priority", "position", "status", "paused",
+	"auto_requeue", "requeue_limit", "requeue_count", "inputs", "created_by",
+	"triggered_at", "run_id", "run_url", "runner_id", "runner_name", "completed_at",
+	"error_message", "created_at", "updated_at",
+	"name", "owner", "repo", "workflow_id", "ref", "labels",
+	"outputs", "requeued_from", "resolved_sha", "original_created_by", "annotations",
+	"campaign_id", "progress", "heartbeat_at", "stalled_at",
+	"run_attempt", "logs_url", "artifacts_url", "retained",
+	"assignee", "assigned_at", "variant", "handoff_secrets", "max_duration_seconds",
+	"notify_targets", "not_before", "source_issue",
+}
+
+// jobSelectColumns returns the job column list for a SELECT clause, with each
+// column qualified by prefix (e.g. "j.") when the query joins other tables.
+func jobSelectColumns(prefix string) string {
+	if prefix == "" {
+		return strings.Join(jobColumns, ", ")
+	}
+
+	qualified := make([]string, len(jobColumns))
+	for i, col := range jobColumns {
+		qualified[i] = prefix + col
+	}
+
+	return strings.Join(qualified, ", ")
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanJob scans a row selected with jobSelectColumns into a Job.
+// Scan errors (including sql.ErrNoRows) are returned unwrapped.
+func scanJob(row rowScanner) (*Job, error) {
+	var job Job
+
+	var inputsJSON, labelsJSON, outputsJSON, annotationsJSON, progressJSON sql.NullString
+
+	var triggeredAt, completedAt, heartbeatAt, stalledAt sql.NullTime
+
+	var runID, runnerID, requeueLimit, maxDuration sql.NullInt64
+
+	var runURL, runnerName, errorMessage, createdBy sql.NullString
+
+	var templateID, name, owner, repo, workflowID, ref, requeuedFrom, resolvedSHA, originalCreatedBy, campaignID sql.NullString
+
+	var logsURL, artifactsURL, assignee, variant, handoffJSON, notifyJSON, sourceIssue sql.NullString
+
+	var assignedAt, notBefore sql.NullTime
+
+	if err := row.Scan(&job.ID, &job.GroupID, &templateID, &job.Priority, &job.Position, &job.Status,
+		&job.Paused, &job.AutoRequeue, &requeueLimit, &job.RequeueCount, &inputsJSON, &createdBy,
+		&triggeredAt, &runID, &runURL, &runnerID, &runnerName, &completedAt,
+		&errorMessage, &job.CreatedAt, &job.UpdatedAt,
+		&name, &owner, &repo, &workflowID, &ref, &labelsJSON,
+		&outputsJSON, &requeuedFrom, &resolvedSHA, &originalCreatedBy, &annotationsJSON,
+		&campaignID, &progressJSON, &heartbeatAt, &stalledAt,
+		&job.RunAttempt, &logsURL, &artifactsURL, &job.Retained,
+		&assignee, &assignedAt, &variant, &handoffJSON, &maxDuration,
+		&notifyJSON, &notBefore, &sourceIssue); err != nil {
+		return nil, err
+	}
+
+	job.TemplateID = templateID.String
+
+	if inputsJSON.Valid && inputsJSON.String != "" {
+		if err := json.Unmarshal([]byte(inputsJSON.String), &job.Inputs); err != nil {
+			return nil, fmt.Errorf("unmarshaling inputs: %w", err)
+		}
+	}
+
+	if triggeredAt.Valid {
+		job.TriggeredAt = &triggeredAt.Time
+	}
+
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+
+	if heartbeatAt.Valid {
+		job.HeartbeatAt = &heartbeatAt.Time
+	}
+
+	if stalledAt.Valid {
+		job.StalledAt = &stalledAt.Time
+	}
+
+	if assignedAt.Valid {
+		job.AssignedAt = &assignedAt.Time
+	}
+
+	if notBefore.Valid {
+		job.NotBefore = &notBefore.Time
+	}
+
+	job.SourceIssue = sourceIssue.String
+
+	if runID.Valid {
+		job.RunID = &runID.Int64
+	}
+
+	if runnerID.Valid {
+		job.RunnerID = &runnerID.Int64
+	}
+
+	if requeueLimit.Valid {
+		limit := int(requeueLimit.Int64)
+		job.RequeueLimit = &limit
+	}
+
+	if maxDuration.Valid {
+		seconds := int(maxDuration.Int64)
+		job.MaxDurationSeconds = &seconds
+	}
+
+	job.RunURL = runURL.String
+	job.RunnerName = runnerName.String
+	job.ErrorMessage = errorMessage.String
+	job.CreatedBy = createdBy.String
+	job.ResolvedSHA = resolvedSHA.String
+	job.OriginalCreatedBy = originalCreatedBy.String
+	job.CampaignID = campaignID.String
+	job.LogsURL = logsURL.String
+	job.ArtifactsURL = artifactsURL.String
+	job.Assignee = assignee.String
+	job.Variant = JobVariant(variant.String)
+
+	if name.Valid {
+		job.Name = &name.String
+	}
+
+	if owner.Valid {
+		job.Owner = &owner.String
+	}
+
+	if repo.Valid {
+		job.Repo = &repo.String
+	}
+
+	if workflowID.Valid {
+		job.WorkflowID = &workflowID.String
+	}
+
+	if ref.Valid {
+		job.Ref = &ref.String
+	}
+
+	if requeuedFrom.Valid {
+		job.RequeuedFrom = &requeuedFrom.String
+	}
+
+	if labelsJSON.Valid && labelsJSON.String != "" {
+		if err := json.Unmarshal([]byte(labelsJSON.String), &job.Labels); err != nil {
+			return nil, fmt.Errorf("unmarshaling labels: %w", err)
+		}
+	}
+
+	if outputsJSON.Valid && outputsJSON.String != "" {
+		if err := json.Unmarshal([]byte(outputsJSON.String), &job.Outputs); err != nil {
+			return nil, fmt.Errorf("unmarshaling outputs: %w", err)
+		}
+	}
+
+	if annotationsJSON.Valid && annotationsJSON.String != "" {
+		if err := json.Unmarshal([]byte(annotationsJSON.String), &job.Annotations); err != nil {
+			return nil, fmt.Errorf("unmarshaling annotations: %w", err)
+		}
+	}
+
+	if progressJSON.Valid && progressJSON.String != "" {
+		if err := json.Unmarshal([]byte(progressJSON.String), &job.Progress); err != nil {
+			return nil, fmt.Errorf("unmarshaling progress: %w", err)
+		}
+	}
+
+	if handoffJSON.Valid && handoffJSON.String != "" {
+		if err := json.Unmarshal([]byte(handoffJSON.String), &job.HandoffSecrets); err != nil {
+			return nil, fmt.Errorf("unmarshaling handoff_secrets: %w", err)
+		}
+	}
+
+	if notifyJSON.Valid && notifyJSON.String != "" {
+		if err := json.Unmarshal([]byte(notifyJSON.String), &job.NotifyTargets); err != nil {
+			return nil, fmt.Errorf("unmarshaling notify_targets: %w", err)
+		}
+	}
+
+	return &job, nil
+}
+
+// templateColumns lists the job_templates table columns read by scanTemplate, in scan order.
+var templateColumns = []string{
+	"id", "group_id", "name", "owner", "repo", "workflow_id", "ref", "default_inputs", "labels",
+	"in_config", "source_type", "source_path", "deprecated", "sunset_at", "environment",
+	"category", "display_order", "dispatch_windows", "pinned_inputs", "min_idle_runners",
+	"default_priority", "keep_running", "stall_timeout_seconds", "canary_percent", "canary_workflow_id",
+	"canary_ref", "secret_handoff_inputs", "secret_handoff_environment", "max_duration_seconds",
+	"overrides", "created_at", "updated_at",
+}
+
+// templateSelectColumns returns the template column list for a SELECT clause.
+func templateSelectColumns() string {
+	return strings.Join(templateColumns, ", ")
+}
+
+// scanTemplate scans a row selected with templateSelectColumns into a JobTemplate.
+// Scan errors (including sql.ErrNoRows) are returned unwrapped.
+func scanTemplate(row rowScanner) (*JobTemplate, error) {
+	var template JobTemplate
+
+	var inputsJSON, labelsJSON, windowsJSON, pinnedJSON, handoffJSON, overridesJSON sql.NullString
+
+	var sunsetAt sql.NullTime
+
+	if err := row.Scan(&template.ID, &template.GroupID, &template.Name, &template.Owner,
+		&template.Repo, &template.WorkflowID, &template.Ref, &inputsJSON, &labelsJSON,
+		&template.InConfig, &template.SourceType, &template.SourcePath, &template.Deprecated, &sunsetAt,
+		&template.Environment, &template.Category, &template.DisplayOrder, &windowsJSON,
+		&pinnedJSON, &template.MinIdleRunners, &template.DefaultPriority, &template.KeepRunning,
+		&template.StallTimeoutSeconds, &template.CanaryPercent, &template.CanaryWorkflowID,
+		&template.CanaryRef, &handoffJSON, &template.SecretHandoffEnvironment,
+		&template.MaxDurationSeconds, &overridesJSON, &template.CreatedAt, &template.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if inputsJSON.Valid && inputsJSON.String != "" {
+		if err := json.Unmarshal([]byte(inputsJSON.String), &template.DefaultInputs); err != nil {
+			return nil, fmt.Errorf("unmarshaling default_inputs: %w", err)
+		}
+	}
+
+	if labelsJSON.Valid && labelsJSON.String != "" {
+		if err := json.Unmarshal([]byte(labelsJSON.String), &template.Labels); err != nil {
+			return nil, fmt.Errorf("unmarshaling labels: %w", err)
+		}
+	}
+
+	if windowsJSON.Valid && windowsJSON.String != "" {
+		if err := json.Unmarshal([]byte(windowsJSON.String), &template.DispatchWindows); err != nil {
+			return nil, fmt.Errorf("unmarshaling dispatch_windows: %w", err)
+		}
+
+		// Resolve the windows once per load rather than on every check. An
+		// invalid window is kept and never opens.
+		for i := range template.DispatchWindows {
+			_ = template.DispatchWindows[i].Validate()
+		}
+	}
+
+	if pinnedJSON.Valid && pinnedJSON.String != "" {
+		if err := json.Unmarshal([]byte(pinnedJSON.String), &template.PinnedInputs); err != nil {
+			return nil, fmt.Errorf("unmarshaling pinned_inputs: %w", err)
+		}
+	}
+
+	if handoffJSON.Valid && handoffJSON.String != "" {
+		if err := json.Unmarshal([]byte(handoffJSON.String), &template.SecretHandoffInputs); err != nil {
+			return nil, fmt.Errorf("unmarshaling secret_handoff_inputs: %w", err)
+		}
+	}
+
+	if overridesJSON.Valid && overridesJSON.String != "" {
+		if err := json.Unmarshal([]byte(overridesJSON.String), &template.Overrides); err != nil {
+			return nil, fmt.Errorf("unmarshaling overrides: %w", err)
+		}
+	}
+
+	if sunsetAt.Valid {
+		template.SunsetAt = &sunsetAt.Time
+	}
+
+	return &template, nil
+}
+
+// marshalDispatchWindows encodes a template's dispatch windows, storing NULL
+// when there are none.
+func marshalDispatchWindows(windows []schedule.Window) (sql.NullString, error) {
+	if len(windows) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(windows)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("marshaling dispatch_windows: %w", err)
+	}
+
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// groupColumns lists the groups table columns read by scanGroup, in scan order.
+var groupColumns = []string{
+	"id", "name", "description", "runner_labels", "enabled", "paused", "paused_reason", "resumed_at",
+	"archived", "archived_at", "created_at", "updated_at", "scheduling_policy",
+	"max_priority", "max_requeue_limit", "min_online_runners", "metadata",
+}
+
+// groupSelectColumns returns the group column list for a SELECT clause.
+func groupSelectColumns() string {
+	return strings.Join(groupColumns, ", ")
+}
+
+// scanGroup scans a row selected with groupSelectColumns into a Group.
+// Scan errors (including sql.ErrNoRows) are returned unwrapped.
+func scanGroup(row rowScanner) (*Group, error) {
+	var group Group
+
+	var labelsJSON string
+
+	var resumedAt, archivedAt sql.NullTime
+
+	var maxPriority, maxRequeueLimit sql.NullInt64
+
+	var metadataJSON sql.NullString
+
+	if err := row.Scan(&group.ID, &group.Name, &group.Description, &labelsJSON,
+		&group.Enabled, &group.Paused, &group.PausedReason, &resumedAt,
+		&group.Archived, &archivedAt, &group.CreatedAt, &group.UpdatedAt,
+		&group.SchedulingPolicy, &maxPriority, &maxRequeueLimit, &group.MinOnlineRunners,
+		&metadataJSON); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(labelsJSON), &group.RunnerLabels); err != nil {
+		return nil, fmt.Errorf("unmarshaling runner_labels: %w", err)
+	}
+
+	if resumedAt.Valid {
+		group.ResumedAt = &resumedAt.Time
+	}
+
+	if archivedAt.Valid {
+		group.ArchivedAt = &archivedAt.Time
+	}
+
+	if maxPriority.Valid {
+		limit := int(maxPriority.Int64)
+		group.MaxPriority = &limit
+	}
+
+	if maxRequeueLimit.Valid {
+		limit := int(maxRequeueLimit.Int64)
+		group.MaxRequeueLimit = &limit
+	}
+
+	if metadataJSON.Valid && metadataJSON.String != "" {
+		if err := json.Unmarshal([]byte(metadataJSON.String), &group.Metadata); err != nil {
+			return nil, fmt.Errorf("unmarshaling metadata: %w", err)
+		}
+	}
+
+	return &group, nil
+}
+
+// marshalTemplateOverrides encodes a template's overrides, storing NULL when
+// there are none.
+func marshalTemplateOverrides(overrides *TemplateOverrides) (sql.NullString, error) {
+	if overrides == nil {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("marshaling overrides: %w", err)
+	}
+
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// marshalGroupMetadata encodes a group's metadata, storing NULL when there is
+// none.
+func marshalGroupMetadata(metadata *GroupMetadata) (sql.NullString, error) {
+	if metadata == nil {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("marshaling metadata: %w", err)
+	}
+
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// savedFilterColumns lists the saved_filters table columns read by scanSavedFilter, in scan order.
+var savedFilterColumns = []string{
+	"id", "user_id", "name", "view", "group_id", "statuses", "labels", "template_id",
+	"created_at", "updated_at",
+}
+
+// savedFilterSelectColumns returns the saved filter column list for a SELECT clause.
+func savedFilterSelectColumns() string {
+	return strings.Join(savedFilterColumns, ", ")
+}
+
+// scanSavedFilter scans a row selected with savedFilterSelectColumns into a SavedFilter.
+// Scan errors (including sql.ErrNoRows) are returned unwrapped.
+func scanSavedFilter(row rowScanner) (*SavedFilter, error) {
+	var filter SavedFilter
+
+	var statusesJSON, labelsJSON string
+
+	if err := row.Scan(&filter.ID, &filter.UserID, &filter.Name, &filter.View, &filter.GroupID,
+		&statusesJSON, &labelsJSON, &filter.TemplateID, &filter.CreatedAt, &filter.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(statusesJSON), &filter.Statuses); err != nil {
+		return nil, fmt.Errorf("unmarshaling statuses: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(labelsJSON), &filter.Labels); err != nil {
+		return nil, fmt.Errorf("unmarshaling labels: %w", err)
+	}
+
+	return &filter, nil
+}
+
+// marshalSavedFilter encodes the JSON columns of a saved filter.
+func marshalSavedFilter(filter *SavedFilter) (statuses, labels string, err error) {
+	statusesJSON, err := json.Marshal(filter.Statuses)
+	if err != nil {
+		return "", "", fmt.Errorf("marshaling statuses: %w", err)
+	}
+
+	labelsJSON, err := json.Marshal(filter.Labels)
+	if err != nil {
+		return "", "", fmt.Errorf("marshaling labels: %w", err)
+	}
+
+	return string(statusesJSON), string(labelsJSON), nil
+}
+
+// subscriptionColumns lists the subscriptions table columns read by scanSubscription, in scan order.
+var subscriptionColumns = []string{
+	"id", "user_id", "target_type", "target_id", "channel", "destination", "statuses", "created_at",
+}
+
+// subscriptionSelectColumns returns the subscription column list for a SELECT clause.
+func subscriptionSelectColumns() string {
+	return strings.Join(subscriptionColumns, ", ")
+}
+
+// scanSubscription scans a row selected with subscriptionSelectColumns into a Subscription.
+// Scan errors (including sql.ErrNoRows) are returned unwrapped.
+func scanSubscription(row rowScanner) (*Subscription, error) {
+	var sub Subscription
+
+	var statusesJSON string
+
+	if err := row.Scan(&sub.ID, &sub.UserID, &sub.TargetType, &sub.TargetID, &sub.Channel,
+		&sub.Destination, &statusesJSON, &sub.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(statusesJSON), &sub.Statuses); err != nil {
+		return nil, fmt.Errorf("unmarshaling statuses: %w", err)
+	}
+
+	return &sub, nil
+}
+
+// scanSubscriptions scans every row of rows into subscriptions and closes rows.
+func scanSubscriptions(rows *sql.Rows) ([]*Subscription, error) {
+	defer rows.Close()
+
+	var subs []*Subscription
+
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning subscription: %w", err)
+		}
+
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// sessionColumns lists the sessions table columns read by scanSession, in scan order.
+var sessionColumns = []string{
+	"id", "user_id", "token_hash", "expires_at", "created_at",
+	"ip_address", "user_agent", "last_used_at",
+}
+
+// sessionSelectColumns returns the session column list for a SELECT clause.
+func sessionSelectColumns() string {
+	return strings.Join(sessionColumns, ", ")
+}
+
+// scanSession scans a row selected with sessionSelectColumns into a Session.
+// Scan errors (including sql.ErrNoRows) are returned unwrapped.
+func scanSession(row rowScanner) (*Session, error) {
+	var session Session
+
+	var lastUsedAt sql.NullTime
+
+	if err := row.Scan(&session.ID, &session.UserID, &session.TokenHash, &session.ExpiresAt,
+		&session.CreatedAt, &session.IPAddress, &session.UserAgent, &lastUsedAt); err != nil {
+		return nil, err
+	}
+
+	if lastUsedAt.Valid {
+		session.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return &session, nil
+}
+
+// jobEventColumns lists the job_events table columns read by scanJobEvent, in scan order.
+var jobEventColumns = []string{
+	"id", "job_id", "from_status", "to_status", "actor", "run_id", "runner_name", "message", "created_at",
+}
+
+// jobEventSelectColumns returns the job event column list for a SELECT clause.
+func jobEventSelectColumns() string {
+	return strings.Join(jobEventColumns, ", ")
+}
+
+// jobRunAttemptColumns lists the job_run_attempts table columns read by
+// scanJobRunAttempt, in scan order.
+var jobRunAttemptColumns = []string{
+	"job_id", "attempt", "status", "error_message", "runner_name", "logs_url", "completed_at",
+}
+
+// scanJobRunAttempt scans a row selected with jobRunAttemptColumns into a
+// JobRunAttempt. Scan errors are returned unwrapped.
+func scanJobRunAttempt(row rowScanner) (*JobRunAttempt, error) {
+	var (
+		attempt     JobRunAttempt
+		completedAt sql.NullTime
+	)
+
+	if err := row.Scan(&attempt.JobID, &attempt.Attempt, &attempt.Status, &attempt.ErrorMessage,
+		&attempt.RunnerName, &attempt.LogsURL, &completedAt); err != nil {
+		return nil, err
+	}
+
+	if completedAt.Valid {
+		attempt.CompletedAt = &completedAt.Time
+	}
+
+	return &attempt, nil
+}
+
+// scanJobEvent scans a row selected with jobEventSelectColumns into a JobEvent.
+// Scan errors are returned unwrapped.
+func scanJobEvent(row rowScanner) (*JobEvent, error) {
+	var (
+		event JobEvent
+		runID sql.NullInt64
+	)
+
+	if err := row.Scan(&event.ID, &event.JobID, &event.FromStatus, &event.ToStatus, &event.Actor,
+		&runID, &event.RunnerName, &event.Message, &event.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if runID.Valid {
+		event.RunID = &runID.Int64
+	}
+
+	return &event, nil
+}
+
+// queueChangeColumns lists the queue_changes table columns read by scanQueueChange, in scan order.
+var queueChangeColumns = []string{
+	"id", "group_id", "job_id", "operation", "actor", "before_snapshot", "after_snapshot", "created_at",
+}
+
+// queueChangeSelectColumns returns the queue change column list for a SELECT clause.
+func queueChangeSelectColumns() string {
+	return strings.Join(queueChangeColumns, ", ")
+}
+
+// scanQueueChange scans a row selected with queueChangeSelectColumns into a
+// QueueChange. Scan errors are returned unwrapped.
+func scanQueueChange(row rowScanner) (*QueueChange, error) {
+	var (
+		change                QueueChange
+		beforeJSON, afterJSON string
+	)
+
+	if err := row.Scan(&change.ID, &change.GroupID, &change.JobID, &change.Operation, &change.Actor,
+		&beforeJSON, &afterJSON, &change.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(beforeJSON), &change.Before); err != nil {
+		return nil, fmt.Errorf("unmarshaling before_snapshot: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(afterJSON), &change.After); err != nil {
+		return nil, fmt.Errorf("unmarshaling after_snapshot: %w", err)
+	}
+
+	return &change, nil
+}
+
+// marshalQueueSnapshots encodes the snapshots of a queue change for storage.
+func marshalQueueSnapshots(change *QueueChange) (before, after string, err error) {
+	beforeJSON, err := json.Marshal(nonNilSnapshot(change.Before))
+	if err != nil {
+		return "", "", fmt.Errorf("marshaling before_snapshot: %w", err)
+	}
+
+	afterJSON, err := json.Marshal(nonNilSnapshot(change.After))
+	if err != nil {
+		return "", "", fmt.Errorf("marshaling after_snapshot: %w", err)
+	}
+
+	return string(beforeJSON), string(afterJSON), nil
+}
+
+// nonNilSnapshot stores empty snapshots as [] rather than null.
+func nonNilSnapshot(entries []QueueSnapshotEntry) []QueueSnapshotEntry {
+	if entries == nil {
+		return []QueueSnapshotEntry{}
+	}
+
+	return entries
+}
+
+// campaignColumns lists the campaigns table columns read by scanCampaign, in scan order.
+var campaignColumns = []string{
+	"id", "name", "description", "metadata", "created_by", "created_at", "updated_at",
+}
+
+// campaignSelectColumns returns the campaign column list for a SELECT clause.
+func campaignSelectColumns() string {
+	return strings.Join(campaignColumns, ", ")
+}
+
+// scanCampaign scans a row selected with campaignSelectColumns into a Campaign.
+// Scan errors (including sql.ErrNoRows) are returned unwrapped.
+func scanCampaign(row rowScanner) (*Campaign, error) {
+	var (
+		campaign     Campaign
+		metadataJSON sql.NullString
+	)
+
+	if err := row.Scan(&campaign.ID, &campaign.Name, &campaign.Description, &metadataJSON,
+		&campaign.CreatedBy, &campaign.CreatedAt, &campaign.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if metadataJSON.Valid && metadataJSON.String != "" {
+		if err := json.Unmarshal([]byte(metadataJSON.String), &campaign.Metadata); err != nil {
+			return nil, fmt.Errorf("unmarshaling metadata: %w", err)
+		}
+	}
+
+	return &campaign, nil
+}
+
+// marshalPinnedInputs encodes a template's pinned input keys, storing NULL
+// when there are none.
+func marshalPinnedInputs(keys []string) (sql.NullString, error) {
+	return marshalInputKeys("pinned_inputs", keys)
+}
+
+// marshalSecretHandoffInputs encodes a template's secret hand-off input keys,
+// storing NULL when there are none.
+func marshalSecretHandoffInputs(keys []string) (sql.NullString, error) {
+	return marshalInputKeys("secret_handoff_inputs", keys)
+}
+
+// marshalInputKeys encodes the input keys of the named column, storing NULL
+// when there are none.
+func marshalInputKeys(column string, keys []string) (sql.NullString, error) {
+	if len(keys) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("marshaling %s: %w", column, err)
+	}
+
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// marshalNotifyTargets encodes a job's notify targets, storing NULL when there
+// are none.
+func marshalNotifyTargets(targets []JobNotifyTarget) (sql.NullString, error) {
+	if len(targets) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(targets)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("marshaling notify_targets: %w", err)
+	}
+
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// marshalHandoffSecrets encodes a job's hand-off secrets, storing NULL when
+// there are none.
+func marshalHandoffSecrets(secrets *HandoffSecrets) (sql.NullString, error) {
+	if secrets == nil {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("marshaling handoff_secrets: %w", err)
+	}
+
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
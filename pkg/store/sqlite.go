@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/sirupsen/logrus"
 )
@@ -17,6 +19,9 @@ type SQLiteStore struct {
 	log  logrus.FieldLogger
 	path string
 	db   *sql.DB
+
+	// lockLease is the lease of a workflow lock, renewed while it is held.
+	lockLease time.Duration
 }
 
 // Ensure SQLiteStore implements Store.
@@ -25,8 +30,9 @@ var _ Store = (*SQLiteStore)(nil)
 // NewSQLiteStore creates a new SQLite store.
 func NewSQLiteStore(log logrus.FieldLogger, path string) Store {
 	return &SQLiteStore{
-		log:  log.WithField("component", "store"),
-		path: path,
+		log:       log.WithField("component", "store"),
+		path:      path,
+		lockLease: workflowLockLease,
 	}
 }
 
@@ -204,6 +210,200 @@ func (s *SQLiteStore) Migrate(ctx context.Context) error {
 		// Migration: Add source_type and source_path columns to job_templates table.
 		`ALTER TABLE job_templates ADD COLUMN source_type TEXT NOT NULL DEFAULT 'inline'`,
 		`ALTER TABLE job_templates ADD COLUMN source_path TEXT NOT NULL DEFAULT ''`,
+		// Migration: Add deprecation columns to job_templates table.
+		`ALTER TABLE job_templates ADD COLUMN deprecated INTEGER DEFAULT 0`,
+		`ALTER TABLE job_templates ADD COLUMN sunset_at TIMESTAMP`,
+		// Migration: Add outputs column to jobs table.
+		`ALTER TABLE jobs ADD COLUMN outputs TEXT`,
+		// Migration: Add environment column to job_templates table.
+		`ALTER TABLE job_templates ADD COLUMN environment TEXT NOT NULL DEFAULT ''`,
+		// Migration: Add auto-pause columns to groups table.
+		`ALTER TABLE groups ADD COLUMN paused_reason TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE groups ADD COLUMN resumed_at TIMESTAMP`,
+		// Migration: Add requeued_from column to jobs table.
+		`ALTER TABLE jobs ADD COLUMN requeued_from TEXT`,
+		// Migration: Add resolved_sha column to jobs table.
+		`ALTER TABLE jobs ADD COLUMN resolved_sha TEXT`,
+		// Template lint results table.
+		`CREATE TABLE IF NOT EXISTS template_lints (
+			template_id TEXT PRIMARY KEY REFERENCES job_templates(id) ON DELETE CASCADE,
+			ref TEXT NOT NULL,
+			workflow_path TEXT NOT NULL DEFAULT '',
+			ok INTEGER NOT NULL DEFAULT 0,
+			issues TEXT NOT NULL DEFAULT '[]',
+			checked_at TIMESTAMP NOT NULL
+		)`,
+		// Saved filters table.
+		`CREATE TABLE IF NOT EXISTS saved_filters (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			view TEXT NOT NULL,
+			group_id TEXT NOT NULL DEFAULT '',
+			statuses TEXT NOT NULL DEFAULT '[]',
+			labels TEXT NOT NULL DEFAULT '{}',
+			template_id TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			UNIQUE (user_id, view, name)
+		)`,
+		// Migration: Add category and display_order columns to job_templates table.
+		`ALTER TABLE job_templates ADD COLUMN category TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE job_templates ADD COLUMN display_order INTEGER NOT NULL DEFAULT 0`,
+		// Migration: Add original_created_by column to jobs table.
+		`ALTER TABLE jobs ADD COLUMN original_created_by TEXT`,
+		// Migration: Add dispatch_windows column to job_templates table.
+		`ALTER TABLE job_templates ADD COLUMN dispatch_windows TEXT`,
+		// Migration: Add annotations column to jobs table.
+		`ALTER TABLE jobs ADD COLUMN annotations TEXT`,
+		// Workflow locks table (serializes dispatch across processes).
+		`CREATE TABLE IF NOT EXISTS workflow_locks (
+			key TEXT PRIMARY KEY,
+			holder TEXT NOT NULL,
+			expires_at TIMESTAMP NOT NULL
+		)`,
+		// Migration: Add archived and archived_at columns to groups table.
+		`ALTER TABLE groups ADD COLUMN archived INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE groups ADD COLUMN archived_at TIMESTAMP`,
+		// Migration: Add pinned_inputs column to job_templates table.
+		`ALTER TABLE job_templates ADD COLUMN pinned_inputs TEXT`,
+		// Migration: Add min_idle_runners column to job_templates table.
+		`ALTER TABLE job_templates ADD COLUMN min_idle_runners INTEGER NOT NULL DEFAULT 0`,
+		// Job events table (status transitions of each job).
+		`CREATE TABLE IF NOT EXISTS job_events (
+			id TEXT PRIMARY KEY,
+			job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+			from_status TEXT NOT NULL DEFAULT '',
+			to_status TEXT NOT NULL,
+			actor TEXT NOT NULL DEFAULT '',
+			run_id INTEGER,
+			runner_name TEXT NOT NULL DEFAULT '',
+			message TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_job_events_job ON job_events(job_id, created_at)`,
+		// Campaigns table (named sets of jobs tracked together).
+		`CREATE TABLE IF NOT EXISTS campaigns (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			metadata TEXT NOT NULL DEFAULT '{}',
+			created_by TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		// Migration: Add campaign_id column to jobs table.
+		`ALTER TABLE jobs ADD COLUMN campaign_id TEXT`,
+		`CREATE INDEX IF NOT EXISTS idx_jobs_campaign ON jobs(campaign_id)`,
+		// Migration: Add scheduling_policy column to groups table.
+		`ALTER TABLE groups ADD COLUMN scheduling_policy TEXT NOT NULL DEFAULT ''`,
+		// Queue changes table (manual queue edits with before/after snapshots).
+		// Not tied to jobs, so the record of a deleted job is kept.
+		`CREATE TABLE IF NOT EXISTS queue_changes (
+			id TEXT PRIMARY KEY,
+			group_id TEXT NOT NULL,
+			job_id TEXT NOT NULL DEFAULT '',
+			operation TEXT NOT NULL,
+			actor TEXT NOT NULL DEFAULT '',
+			before_snapshot TEXT NOT NULL DEFAULT '[]',
+			after_snapshot TEXT NOT NULL DEFAULT '[]',
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_queue_changes_group ON queue_changes(group_id, created_at)`,
+		// Migration: Add client metadata columns to sessions table.
+		`ALTER TABLE sessions ADD COLUMN ip_address TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE sessions ADD COLUMN user_agent TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE sessions ADD COLUMN last_used_at TIMESTAMP`,
+		// Migration: Add enqueue caps to groups and default priority to job_templates.
+		`ALTER TABLE groups ADD COLUMN max_priority INTEGER`,
+		`ALTER TABLE groups ADD COLUMN max_requeue_limit INTEGER`,
+		`ALTER TABLE job_templates ADD COLUMN default_priority INTEGER NOT NULL DEFAULT 0`,
+		// Migration: Add keep_running column to job_templates table.
+		`ALTER TABLE job_templates ADD COLUMN keep_running INTEGER NOT NULL DEFAULT 0`,
+		// Migration: Index pending jobs in dispatch order for queue pagination.
+		`CREATE INDEX IF NOT EXISTS idx_jobs_group_status_order ON jobs(group_id, status, priority DESC, position, id)`,
+		// Migration: Add progress column to jobs table.
+		`ALTER TABLE jobs ADD COLUMN progress TEXT`,
+		// Migration: Add stall detection columns.
+		`ALTER TABLE job_templates ADD COLUMN stall_timeout_seconds INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE jobs ADD COLUMN heartbeat_at TIMESTAMP`,
+		`ALTER TABLE jobs ADD COLUMN stalled_at TIMESTAMP`,
+		// Migration: Add subscriptions table.
+		`CREATE TABLE IF NOT EXISTS subscriptions (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			target_type TEXT NOT NULL,
+			target_id TEXT NOT NULL,
+			channel TEXT NOT NULL,
+			destination TEXT NOT NULL,
+			statuses TEXT NOT NULL DEFAULT '[]',
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_subscriptions_target ON subscriptions(target_type, target_id)`,
+		// Migration: Add queue_stats table.
+		`CREATE TABLE IF NOT EXISTS queue_stats (
+			group_id TEXT NOT NULL,
+			sampled_at TIMESTAMP NOT NULL,
+			pending INTEGER NOT NULL,
+			running INTEGER NOT NULL,
+			idle_runners INTEGER NOT NULL,
+			PRIMARY KEY (group_id, sampled_at)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_queue_stats_sampled_at ON queue_stats(sampled_at)`,
+		// Migration: Add job_run_attempts table.
+		`CREATE TABLE IF NOT EXISTS job_run_attempts (
+			job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+			attempt INTEGER NOT NULL,
+			status TEXT NOT NULL,
+			error_message TEXT NOT NULL DEFAULT '',
+			runner_name TEXT NOT NULL DEFAULT '',
+			logs_url TEXT NOT NULL DEFAULT '',
+			completed_at TIMESTAMP,
+			PRIMARY KEY (job_id, attempt)
+		)`,
+		// Migration: Add workflow run attempt columns.
+		`ALTER TABLE jobs ADD COLUMN run_attempt INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE jobs ADD COLUMN logs_url TEXT`,
+		`ALTER TABLE jobs ADD COLUMN artifacts_url TEXT`,
+		// Migration: Add retained column to jobs table.
+		`ALTER TABLE jobs ADD COLUMN retained INTEGER NOT NULL DEFAULT 0`,
+		// Migration: Add assignee columns to jobs table.
+		`ALTER TABLE jobs ADD COLUMN assignee TEXT`,
+		`ALTER TABLE jobs ADD COLUMN assigned_at TIMESTAMP`,
+		// Migration: Add canary columns.
+		`ALTER TABLE job_templates ADD COLUMN canary_percent INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE job_templates ADD COLUMN canary_workflow_id TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE job_templates ADD COLUMN canary_ref TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE jobs ADD COLUMN variant TEXT`,
+		// Migration: Add min_online_runners column to groups.
+		`ALTER TABLE groups ADD COLUMN min_online_runners INTEGER NOT NULL DEFAULT 0`,
+		// Migration: Add secret hand-off columns.
+		`ALTER TABLE job_templates ADD COLUMN secret_handoff_inputs TEXT`,
+		`ALTER TABLE job_templates ADD COLUMN secret_handoff_environment INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE jobs ADD COLUMN handoff_secrets TEXT`,
+		// Migration: Add max_duration_seconds columns.
+		`ALTER TABLE job_templates ADD COLUMN max_duration_seconds INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE jobs ADD COLUMN max_duration_seconds INTEGER`,
+		// Migration: Add notify_targets column to jobs.
+		`ALTER TABLE jobs ADD COLUMN notify_targets TEXT`,
+		// Migration: Add settings table.
+		`CREATE TABLE IF NOT EXISTS settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL,
+			updated_by TEXT NOT NULL DEFAULT '',
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		// Migration: Add metadata column to groups.
+		`ALTER TABLE groups ADD COLUMN metadata TEXT`,
+		// Migration: Add overrides column to job_templates.
+		`ALTER TABLE job_templates ADD COLUMN overrides TEXT`,
+		// Migration: Add hosted runner pool columns to runners.
+		`ALTER TABLE runners ADD COLUMN hosted INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE runners ADD COLUMN max_runners INTEGER NOT NULL DEFAULT 0`,
+		// Migration: Add not_before column to jobs.
+		`ALTER TABLE jobs ADD COLUMN not_before TIMESTAMP`,
+		// Migration: Add source_issue column to jobs.
+		`ALTER TABLE jobs ADD COLUMN source_issue TEXT`,
 	}
 
 	for _, migration := range migrations {
@@ -283,7 +483,28 @@ func (s *SQLiteStore) migrateJobsTemplateIdNullable(ctx context.Context) error {
 			repo TEXT,
 			workflow_id TEXT,
 			ref TEXT,
-			labels TEXT
+			labels TEXT,
+			outputs TEXT,
+			requeued_from TEXT,
+			resolved_sha TEXT,
+			original_created_by TEXT,
+			annotations TEXT,
+			campaign_id TEXT,
+			progress TEXT,
+			heartbeat_at TIMESTAMP,
+			stalled_at TIMESTAMP,
+			run_attempt INTEGER NOT NULL DEFAULT 0,
+			logs_url TEXT,
+			artifacts_url TEXT,
+			retained INTEGER NOT NULL DEFAULT 0,
+			assignee TEXT,
+			assigned_at TIMESTAMP,
+			variant TEXT,
+			handoff_secrets TEXT,
+			max_duration_seconds INTEGER,
+			notify_targets TEXT,
+			not_before TIMESTAMP,
+			source_issue TEXT
 		)
 	`)
 	if err != nil {
@@ -295,7 +516,10 @@ func (s *SQLiteStore) migrateJobsTemplateIdNullable(ctx context.Context) error {
 		INSERT INTO jobs_new
 		SELECT id, group_id, template_id, priority, position, status, inputs, created_by,
 			   triggered_at, run_id, run_url, runner_name, completed_at, error_message, created_at, updated_at,
-			   paused, auto_requeue, requeue_limit, requeue_count, runner_id, name, owner, repo, workflow_id, ref, labels
+			   paused, auto_requeue, requeue_limit, requeue_count, runner_id, name, owner, repo, workflow_id, ref, labels, outputs, requeued_from, resolved_sha,
+			   original_created_by, annotations, campaign_id, progress,
+			   heartbeat_at, stalled_at, run_attempt, logs_url, artifacts_url, retained,
+			   assignee, assigned_at, variant, handoff_secrets, max_duration_seconds, notify_targets, not_before, source_issue
 		FROM jobs
 	`)
 	if err != nil {
@@ -317,6 +541,8 @@ func (s *SQLiteStore) migrateJobsTemplateIdNullable(ctx context.Context) error {
 		"CREATE INDEX IF NOT EXISTS idx_jobs_group_status ON jobs(group_id, status)",
 		"CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status)",
 		"CREATE INDEX IF NOT EXISTS idx_jobs_completed_at ON jobs(completed_at)",
+		"CREATE INDEX IF NOT EXISTS idx_jobs_campaign ON jobs(campaign_id)",
+		"CREATE INDEX IF NOT EXISTS idx_jobs_group_status_order ON jobs(group_id, status, priority DESC, position, id)",
 	}
 
 	for _, idx := range indexes {
@@ -339,11 +565,17 @@ func (s *SQLiteStore) CreateGroup(ctx context.Context, group *Group) error {
 		return fmt.Errorf("marshaling runner_labels: %w", err)
 	}
 
+	metadataJSON, err := marshalGroupMetadata(group.Metadata)
+	if err != nil {
+		return err
+	}
+
 	_, err = s.db.ExecContext(ctx, `
-		INSERT INTO groups (id, name, description, runner_labels, enabled, paused, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO groups (id, name, description, runner_labels, enabled, paused, paused_reason, resumed_at, archived, archived_at, created_at, updated_at, scheduling_policy, max_priority, max_requeue_limit, min_online_runners, metadata)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, group.ID, group.Name, group.Description, string(labelsJSON),
-		group.Enabled, group.Paused, group.CreatedAt, group.UpdatedAt)
+		group.Enabled, group.Paused, group.PausedReason, group.ResumedAt, group.Archived, group.ArchivedAt,
+		group.CreatedAt, group.UpdatedAt, group.SchedulingPolicy, group.MaxPriority, group.MaxRequeueLimit, group.MinOnlineRunners, metadataJSON)
 
 	if err != nil {
 		return fmt.Errorf("inserting group: %w", err)
@@ -354,17 +586,10 @@ func (s *SQLiteStore) CreateGroup(ctx context.Context, group *Group) error {
 
 // GetGroup retrieves a group by ID.
 func (s *SQLiteStore) GetGroup(ctx context.Context, id string) (*Group, error) {
-	var group Group
-
-	var labelsJSON string
-
-	var enabled, paused int
-
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, name, description, runner_labels, enabled, paused, created_at, updated_at
+	group, err := scanGroup(s.db.QueryRowContext(ctx, `
+		SELECT `+groupSelectColumns()+`
 		FROM groups WHERE id = ?
-	`, id).Scan(&group.ID, &group.Name, &group.Description, &labelsJSON,
-		&enabled, &paused, &group.CreatedAt, &group.UpdatedAt)
+	`, id))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -374,20 +599,13 @@ func (s *SQLiteStore) GetGroup(ctx context.Context, id string) (*Group, error) {
 		return nil, fmt.Errorf("querying group: %w", err)
 	}
 
-	if err := json.Unmarshal([]byte(labelsJSON), &group.RunnerLabels); err != nil {
-		return nil, fmt.Errorf("unmarshaling runner_labels: %w", err)
-	}
-
-	group.Enabled = enabled == 1
-	group.Paused = paused == 1
-
-	return &group, nil
+	return group, nil
 }
 
 // ListGroups retrieves all groups.
 func (s *SQLiteStore) ListGroups(ctx context.Context) ([]*Group, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, name, description, runner_labels, enabled, paused, created_at, updated_at
+		SELECT `+groupSelectColumns()+`
 		FROM groups ORDER BY name
 	`)
 	if err != nil {
@@ -399,24 +617,12 @@ func (s *SQLiteStore) ListGroups(ctx context.Context) ([]*Group, error) {
 	var groups []*Group
 
 	for rows.Next() {
-		var group Group
-
-		var labelsJSON string
-
-		var enabled, paused int
-
-		if err := rows.Scan(&group.ID, &group.Name, &group.Description, &labelsJSON,
-			&enabled, &paused, &group.CreatedAt, &group.UpdatedAt); err != nil {
+		group, err := scanGroup(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scanning group: %w", err)
 		}
 
-		if err := json.Unmarshal([]byte(labelsJSON), &group.RunnerLabels); err != nil {
-			return nil, fmt.Errorf("unmarshaling runner_labels: %w", err)
-		}
-
-		group.Enabled = enabled == 1
-		group.Paused = paused == 1
-		groups = append(groups, &group)
+		groups = append(groups, group)
 	}
 
 	return groups, rows.Err()
@@ -429,12 +635,21 @@ func (s *SQLiteStore) UpdateGroup(ctx context.Context, group *Group) error {
 		return fmt.Errorf("marshaling runner_labels: %w", err)
 	}
 
+	metadataJSON, err := marshalGroupMetadata(group.Metadata)
+	if err != nil {
+		return err
+	}
+
 	group.UpdatedAt = time.Now()
 
 	_, err = s.db.ExecContext(ctx, `
-		UPDATE groups SET name = ?, description = ?, runner_labels = ?, enabled = ?, paused = ?, updated_at = ?
+		UPDATE groups SET name = ?, description = ?, runner_labels = ?, enabled = ?, paused = ?, paused_reason = ?, resumed_at = ?,
+			archived = ?, archived_at = ?, updated_at = ?, scheduling_policy = ?,
+			max_priority = ?, max_requeue_limit = ?, min_online_runners = ?, metadata = ?
 		WHERE id = ?
-	`, group.Name, group.Description, string(labelsJSON), group.Enabled, group.Paused, group.UpdatedAt, group.ID)
+	`, group.Name, group.Description, string(labelsJSON), group.Enabled, group.Paused,
+		group.PausedReason, group.ResumedAt, group.Archived, group.ArchivedAt, group.UpdatedAt, group.SchedulingPolicy,
+		group.MaxPriority, group.MaxRequeueLimit, group.MinOnlineRunners, metadataJSON, group.ID)
 
 	if err != nil {
 		return fmt.Errorf("updating group: %w", err)
@@ -469,12 +684,36 @@ func (s *SQLiteStore) CreateJobTemplate(ctx context.Context, template *JobTempla
 		return fmt.Errorf("marshaling labels: %w", err)
 	}
 
+	windowsJSON, err := marshalDispatchWindows(template.DispatchWindows)
+	if err != nil {
+		return err
+	}
+
+	pinnedJSON, err := marshalPinnedInputs(template.PinnedInputs)
+	if err != nil {
+		return err
+	}
+
+	handoffJSON, err := marshalSecretHandoffInputs(template.SecretHandoffInputs)
+	if err != nil {
+		return err
+	}
+
+	overridesJSON, err := marshalTemplateOverrides(template.Overrides)
+	if err != nil {
+		return err
+	}
+
 	_, err = s.db.ExecContext(ctx, `
-		INSERT INTO job_templates (id, group_id, name, owner, repo, workflow_id, ref, default_inputs, labels, in_config, source_type, source_path, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO job_templates (id, group_id, name, owner, repo, workflow_id, ref, default_inputs, labels, in_config, source_type, source_path, deprecated, sunset_at, environment, category, display_order, dispatch_windows, pinned_inputs, min_idle_runners, default_priority, keep_running, stall_timeout_seconds, canary_percent, canary_workflow_id, canary_ref, secret_handoff_inputs, secret_handoff_environment, max_duration_seconds, overrides, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, template.ID, template.GroupID, template.Name, template.Owner, template.Repo,
 		template.WorkflowID, template.Ref, string(inputsJSON), string(labelsJSON), template.InConfig,
-		template.SourceType, template.SourcePath, template.CreatedAt, template.UpdatedAt)
+		template.SourceType, template.SourcePath, template.Deprecated, template.SunsetAt, template.Environment,
+		template.Category, template.DisplayOrder, windowsJSON, pinnedJSON, template.MinIdleRunners,
+		template.DefaultPriority, template.KeepRunning, template.StallTimeoutSeconds, template.CanaryPercent, template.CanaryWorkflowID,
+		template.CanaryRef, handoffJSON, template.SecretHandoffEnvironment, template.MaxDurationSeconds,
+		overridesJSON, template.CreatedAt, template.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("inserting job_template: %w", err)
@@ -485,18 +724,10 @@ func (s *SQLiteStore) CreateJobTemplate(ctx context.Context, template *JobTempla
 
 // GetJobTemplate retrieves a job template by ID.
 func (s *SQLiteStore) GetJobTemplate(ctx context.Context, id string) (*JobTemplate, error) {
-	var template JobTemplate
-
-	var inputsJSON, labelsJSON sql.NullString
-
-	var inConfig int
-
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, group_id, name, owner, repo, workflow_id, ref, default_inputs, labels, in_config, source_type, source_path, created_at, updated_at
+	template, err := scanTemplate(s.db.QueryRowContext(ctx, `
+		SELECT `+templateSelectColumns()+`
 		FROM job_templates WHERE id = ?
-	`, id).Scan(&template.ID, &template.GroupID, &template.Name, &template.Owner,
-		&template.Repo, &template.WorkflowID, &template.Ref, &inputsJSON, &labelsJSON,
-		&inConfig, &template.SourceType, &template.SourcePath, &template.CreatedAt, &template.UpdatedAt)
+	`, id))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -506,28 +737,14 @@ func (s *SQLiteStore) GetJobTemplate(ctx context.Context, id string) (*JobTempla
 		return nil, fmt.Errorf("querying job_template: %w", err)
 	}
 
-	if inputsJSON.Valid && inputsJSON.String != "" {
-		if err := json.Unmarshal([]byte(inputsJSON.String), &template.DefaultInputs); err != nil {
-			return nil, fmt.Errorf("unmarshaling default_inputs: %w", err)
-		}
-	}
-
-	if labelsJSON.Valid && labelsJSON.String != "" {
-		if err := json.Unmarshal([]byte(labelsJSON.String), &template.Labels); err != nil {
-			return nil, fmt.Errorf("unmarshaling labels: %w", err)
-		}
-	}
-
-	template.InConfig = inConfig == 1
-
-	return &template, nil
+	return template, nil
 }
 
 // ListJobTemplatesByGroup retrieves all job templates for a group.
 func (s *SQLiteStore) ListJobTemplatesByGroup(ctx context.Context, groupID string) ([]*JobTemplate, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, group_id, name, owner, repo, workflow_id, ref, default_inputs, labels, in_config, source_type, source_path, created_at, updated_at
-		FROM job_templates WHERE group_id = ? ORDER BY name
+		SELECT `+templateSelectColumns()+`
+		FROM job_templates WHERE group_id = ? ORDER BY display_order, name
 	`, groupID)
 	if err != nil {
 		return nil, fmt.Errorf("querying job_templates: %w", err)
@@ -538,32 +755,12 @@ func (s *SQLiteStore) ListJobTemplatesByGroup(ctx context.Context, groupID strin
 	var templates []*JobTemplate
 
 	for rows.Next() {
-		var template JobTemplate
-
-		var inputsJSON, labelsJSON sql.NullString
-
-		var inConfig int
-
-		if err := rows.Scan(&template.ID, &template.GroupID, &template.Name, &template.Owner,
-			&template.Repo, &template.WorkflowID, &template.Ref, &inputsJSON, &labelsJSON,
-			&inConfig, &template.SourceType, &template.SourcePath, &template.CreatedAt, &template.UpdatedAt); err != nil {
+		template, err := scanTemplate(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scanning job_template: %w", err)
 		}
 
-		if inputsJSON.Valid && inputsJSON.String != "" {
-			if err := json.Unmarshal([]byte(inputsJSON.String), &template.DefaultInputs); err != nil {
-				return nil, fmt.Errorf("unmarshaling default_inputs: %w", err)
-			}
-		}
-
-		if labelsJSON.Valid && labelsJSON.String != "" {
-			if err := json.Unmarshal([]byte(labelsJSON.String), &template.Labels); err != nil {
-				return nil, fmt.Errorf("unmarshaling labels: %w", err)
-			}
-		}
-
-		template.InConfig = inConfig == 1
-		templates = append(templates, &template)
+		templates = append(templates, template)
 	}
 
 	return templates, rows.Err()
@@ -581,13 +778,40 @@ func (s *SQLiteStore) UpdateJobTemplate(ctx context.Context, template *JobTempla
 		return fmt.Errorf("marshaling labels: %w", err)
 	}
 
+	windowsJSON, err := marshalDispatchWindows(template.DispatchWindows)
+	if err != nil {
+		return err
+	}
+
+	pinnedJSON, err := marshalPinnedInputs(template.PinnedInputs)
+	if err != nil {
+		return err
+	}
+
+	handoffJSON, err := marshalSecretHandoffInputs(template.SecretHandoffInputs)
+	if err != nil {
+		return err
+	}
+
+	overridesJSON, err := marshalTemplateOverrides(template.Overrides)
+	if err != nil {
+		return err
+	}
+
 	template.UpdatedAt = time.Now()
 
 	_, err = s.db.ExecContext(ctx, `
-		UPDATE job_templates SET name = ?, owner = ?, repo = ?, workflow_id = ?, ref = ?, default_inputs = ?, labels = ?, in_config = ?, source_type = ?, source_path = ?, updated_at = ?
+		UPDATE job_templates SET name = ?, owner = ?, repo = ?, workflow_id = ?, ref = ?, default_inputs = ?, labels = ?, in_config = ?, source_type = ?, source_path = ?, deprecated = ?, sunset_at = ?, environment = ?,
+			category = ?, display_order = ?, dispatch_windows = ?, pinned_inputs = ?, min_idle_runners = ?, default_priority = ?, keep_running = ?, stall_timeout_seconds = ?,
+			canary_percent = ?, canary_workflow_id = ?, canary_ref = ?, secret_handoff_inputs = ?,
+			secret_handoff_environment = ?, max_duration_seconds = ?, overrides = ?, updated_at = ?
 		WHERE id = ?
 	`, template.Name, template.Owner, template.Repo, template.WorkflowID, template.Ref,
-		string(inputsJSON), string(labelsJSON), template.InConfig, template.SourceType, template.SourcePath, template.UpdatedAt, template.ID)
+		string(inputsJSON), string(labelsJSON), template.InConfig, template.SourceType, template.SourcePath,
+		template.Deprecated, template.SunsetAt, template.Environment, template.Category, template.DisplayOrder,
+		windowsJSON, pinnedJSON, template.MinIdleRunners, template.DefaultPriority, template.KeepRunning,
+		template.StallTimeoutSeconds, template.CanaryPercent, template.CanaryWorkflowID, template.CanaryRef,
+		handoffJSON, template.SecretHandoffEnvironment, template.MaxDurationSeconds, overridesJSON, template.UpdatedAt, template.ID)
 
 	if err != nil {
 		return fmt.Errorf("updating job_template: %w", err)
@@ -642,6 +866,61 @@ func (s *SQLiteStore) HasAnyJobs(ctx context.Context, templateID string) (bool,
 	return count > 0, nil
 }
 
+// ============================================================================
+// Template Lints
+// ============================================================================
+
+// UpsertTemplateLint stores the latest lint result for a template.
+func (s *SQLiteStore) UpsertTemplateLint(ctx context.Context, lint *TemplateLint) error {
+	issuesJSON, err := json.Marshal(lint.Issues)
+	if err != nil {
+		return fmt.Errorf("marshaling issues: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO template_lints (template_id, ref, workflow_path, ok, issues, checked_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(template_id) DO UPDATE SET
+			ref = excluded.ref,
+			workflow_path = excluded.workflow_path,
+			ok = excluded.ok,
+			issues = excluded.issues,
+			checked_at = excluded.checked_at
+	`, lint.TemplateID, lint.Ref, lint.WorkflowPath, lint.OK, string(issuesJSON), lint.CheckedAt)
+
+	if err != nil {
+		return fmt.Errorf("upserting template_lint: %w", err)
+	}
+
+	return nil
+}
+
+// GetTemplateLint retrieves the latest lint result for a template.
+func (s *SQLiteStore) GetTemplateLint(ctx context.Context, templateID string) (*TemplateLint, error) {
+	var lint TemplateLint
+
+	var issuesJSON string
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT template_id, ref, workflow_path, ok, issues, checked_at
+		FROM template_lints WHERE template_id = ?
+	`, templateID).Scan(&lint.TemplateID, &lint.Ref, &lint.WorkflowPath, &lint.OK, &issuesJSON, &lint.CheckedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("querying template_lint: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(issuesJSON), &lint.Issues); err != nil {
+		return nil, fmt.Errorf("unmarshaling issues: %w", err)
+	}
+
+	return &lint, nil
+}
+
 // ============================================================================
 // Jobs
 // ============================================================================
@@ -669,13 +948,20 @@ func (s *SQLiteStore) CreateJob(ctx context.Context, job *Job) error {
 		templateID = sql.NullString{String: job.TemplateID, Valid: true}
 	}
 
+	campaignID := sql.NullString{String: job.CampaignID, Valid: job.CampaignID != ""}
+
+	notifyJSON, err := marshalNotifyTargets(job.NotifyTargets)
+	if err != nil {
+		return err
+	}
+
 	_, err = s.db.ExecContext(ctx, `
-		INSERT INTO jobs (id, group_id, template_id, priority, position, status, paused, auto_requeue, requeue_limit, requeue_count, inputs, created_by, name, owner, repo, workflow_id, ref, labels, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO jobs (id, group_id, template_id, priority, position, status, paused, auto_requeue, requeue_limit, requeue_count, inputs, created_by, name, owner, repo, workflow_id, ref, labels, requeued_from, created_at, updated_at, campaign_id, max_duration_seconds, notify_targets, not_before, source_issue)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, job.ID, job.GroupID, templateID, job.Priority, job.Position, job.Status, job.Paused,
 		job.AutoRequeue, job.RequeueLimit, job.RequeueCount, string(inputsJSON), job.CreatedBy,
-		job.Name, job.Owner, job.Repo, job.WorkflowID, job.Ref, labelsJSON,
-		job.CreatedAt, job.UpdatedAt)
+		job.Name, job.Owner, job.Repo, job.WorkflowID, job.Ref, labelsJSON, job.RequeuedFrom,
+		job.CreatedAt, job.UpdatedAt, campaignID, job.MaxDurationSeconds, notifyJSON, job.NotBefore, job.SourceIssue)
 
 	if err != nil {
 		return fmt.Errorf("inserting job: %w", err)
@@ -686,31 +972,10 @@ func (s *SQLiteStore) CreateJob(ctx context.Context, job *Job) error {
 
 // GetJob retrieves a job by ID.
 func (s *SQLiteStore) GetJob(ctx context.Context, id string) (*Job, error) {
-	var job Job
-
-	var inputsJSON sql.NullString
-
-	var triggeredAt, completedAt sql.NullTime
-
-	var runID, requeueLimit sql.NullInt64
-
-	var paused, autoRequeue int
-
-	var runURL, runnerName, errorMessage, createdBy sql.NullString
-
-	var runnerID sql.NullInt64
-
-	var templateID, name, owner, repo, workflowID, ref, labelsJSON sql.NullString
-
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, group_id, template_id, priority, position, status, paused, auto_requeue, requeue_limit, requeue_count, inputs, created_by,
-			   triggered_at, run_id, run_url, runner_id, runner_name, completed_at, error_message, created_at, updated_at,
-			   name, owner, repo, workflow_id, ref, labels
+	job, err := scanJob(s.db.QueryRowContext(ctx, `
+		SELECT `+jobSelectColumns("")+`
 		FROM jobs WHERE id = ?
-	`, id).Scan(&job.ID, &job.GroupID, &templateID, &job.Priority, &job.Position, &job.Status,
-		&paused, &autoRequeue, &requeueLimit, &job.RequeueCount, &inputsJSON, &createdBy, &triggeredAt, &runID, &runURL, &runnerID, &runnerName, &completedAt,
-		&errorMessage, &job.CreatedAt, &job.UpdatedAt,
-		&name, &owner, &repo, &workflowID, &ref, &labelsJSON)
+	`, id))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -720,72 +985,25 @@ func (s *SQLiteStore) GetJob(ctx context.Context, id string) (*Job, error) {
 		return nil, fmt.Errorf("querying job: %w", err)
 	}
 
-	if templateID.Valid {
-		job.TemplateID = templateID.String
-	}
-
-	if inputsJSON.Valid && inputsJSON.String != "" {
-		if err := json.Unmarshal([]byte(inputsJSON.String), &job.Inputs); err != nil {
-			return nil, fmt.Errorf("unmarshaling inputs: %w", err)
-		}
-	}
-
-	if triggeredAt.Valid {
-		job.TriggeredAt = &triggeredAt.Time
-	}
-
-	if completedAt.Valid {
-		job.CompletedAt = &completedAt.Time
-	}
-
-	if runID.Valid {
-		job.RunID = &runID.Int64
-	}
-
-	if runnerID.Valid {
-		job.RunnerID = &runnerID.Int64
-	}
-
-	job.Paused = paused == 1
-	job.AutoRequeue = autoRequeue == 1
-
-	if requeueLimit.Valid {
-		limit := int(requeueLimit.Int64)
-		job.RequeueLimit = &limit
-	}
-
-	job.RunURL = runURL.String
-	job.RunnerName = runnerName.String
-	job.ErrorMessage = errorMessage.String
-	job.CreatedBy = createdBy.String
-
-	if name.Valid {
-		job.Name = &name.String
-	}
-
-	if owner.Valid {
-		job.Owner = &owner.String
-	}
-
-	if repo.Valid {
-		job.Repo = &repo.String
-	}
+	return job, nil
+}
 
-	if workflowID.Valid {
-		job.WorkflowID = &workflowID.String
-	}
+// GetJobByRunID retrieves the job linked to a workflow run.
+func (s *SQLiteStore) GetJobByRunID(ctx context.Context, runID int64) (*Job, error) {
+	job, err := scanJob(s.db.QueryRowContext(ctx, `
+		SELECT `+jobSelectColumns("")+`
+		FROM jobs WHERE run_id = ? LIMIT 1
+	`, runID))
 
-	if ref.Valid {
-		job.Ref = &ref.String
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
 
-	if labelsJSON.Valid && labelsJSON.String != "" {
-		if err := json.Unmarshal([]byte(labelsJSON.String), &job.Labels); err != nil {
-			return nil, fmt.Errorf("unmarshaling labels: %w", err)
-		}
+	if err != nil {
+		return nil, fmt.Errorf("querying job by run_id: %w", err)
 	}
 
-	return &job, nil
+	return job, nil
 }
 
 // ListJobsByGroup retrieves jobs for a group, optionally filtered by status.
@@ -793,9 +1011,7 @@ func (s *SQLiteStore) ListJobsByGroup(
 	ctx context.Context, groupID string, statuses ...JobStatus,
 ) ([]*Job, error) {
 	query := `
-		SELECT id, group_id, template_id, priority, position, status, paused, auto_requeue, requeue_limit, requeue_count, inputs, created_by,
-			   triggered_at, run_id, run_url, runner_id, runner_name, completed_at, error_message, created_at, updated_at,
-			   name, owner, repo, workflow_id, ref, labels
+		SELECT ` + jobSelectColumns("") + `
 		FROM jobs WHERE group_id = ?
 	`
 
@@ -822,134 +1038,233 @@ func (s *SQLiteStore) ListJobsByGroup(
 	return s.queryJobs(ctx, query, args...)
 }
 
-// ListJobsByStatus retrieves all jobs with the given statuses.
-func (s *SQLiteStore) ListJobsByStatus(ctx context.Context, statuses ...JobStatus) ([]*Job, error) {
-	if len(statuses) == 0 {
-		return nil, nil
+// ListPendingJobs retrieves a page of a group's pending jobs in dispatch order.
+func (s *SQLiteStore) ListPendingJobs(ctx context.Context, opts PendingQueryOpts) (*PendingResult, error) {
+	query := `
+		SELECT ` + jobSelectColumns("") + `
+		FROM jobs WHERE group_id = ? AND status = 'pending'
+	`
+	args := []any{opts.GroupID}
+	result := &PendingResult{}
+
+	if after := opts.After; after != nil {
+		query += ` AND (priority < ? OR (priority = ? AND (position > ? OR (position = ? AND id > ?))))`
+		args = append(args, after.Priority, after.Priority, after.Position, after.Position, after.ID)
+
+		if err := s.db.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM jobs
+			WHERE group_id = ? AND status = 'pending' AND paused = ?
+			AND (priority > ? OR (priority = ? AND (position < ? OR (position = ? AND id <= ?))))
+		`, opts.GroupID, false, after.Priority, after.Priority, after.Position, after.Position, after.ID,
+		).Scan(&result.Ahead); err != nil {
+			return nil, fmt.Errorf("counting pending jobs ahead: %w", err)
+		}
 	}
 
-	placeholders := make([]string, len(statuses))
-	args := make([]any, len(statuses))
+	// Fetch one extra to check if more exist.
+	query += fmt.Sprintf(" ORDER BY priority DESC, position, id LIMIT %d", opts.Limit+1)
 
-	for i, status := range statuses {
-		placeholders[i] = "?"
-		args[i] = status
+	jobs, err := s.queryJobs(ctx, query, args...)
+	if err != nil {
+		return nil, err
 	}
 
-	query := fmt.Sprintf(`
-		SELECT id, group_id, template_id, priority, position, status, paused, auto_requeue, requeue_limit, requeue_count, inputs, created_by,
-			   triggered_at, run_id, run_url, runner_id, runner_name, completed_at, error_message, created_at, updated_at,
-			   name, owner, repo, workflow_id, ref, labels
-		FROM jobs WHERE status IN (%s) ORDER BY position
-	`, strings.Join(placeholders, ","))
+	if len(jobs) > opts.Limit {
+		result.HasMore = true
+		jobs = jobs[:opts.Limit]
+	}
 
-	return s.queryJobs(ctx, query, args...)
+	result.Jobs = jobs
+
+	return result, nil
 }
 
-func (s *SQLiteStore) queryJobs(ctx context.Context, query string, args ...any) ([]*Job, error) {
-	rows, err := s.db.QueryContext(ctx, query, args...)
+// CountQueueJobs counts a group's pending, triggered and running jobs by
+// template, status and paused state.
+func (s *SQLiteStore) CountQueueJobs(ctx context.Context, groupID string) ([]*QueueCount, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT COALESCE(template_id, ''), status, COALESCE(paused, 0), COUNT(*)
+		FROM jobs
+		WHERE group_id = ? AND status IN ('pending', 'triggered', 'running')
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3
+	`, groupID)
 	if err != nil {
-		return nil, fmt.Errorf("querying jobs: %w", err)
+		return nil, fmt.Errorf("counting queue jobs: %w", err)
 	}
 
 	defer rows.Close()
 
-	var jobs []*Job
+	var counts []*QueueCount
 
 	for rows.Next() {
-		var job Job
-
-		var inputsJSON sql.NullString
-
-		var triggeredAt, completedAt sql.NullTime
+		var count QueueCount
+		if err := rows.Scan(&count.TemplateID, &count.Status, &count.Paused, &count.Count); err != nil {
+			return nil, fmt.Errorf("scanning queue count: %w", err)
+		}
 
-		var runID, requeueLimit sql.NullInt64
+		counts = append(counts, &count)
+	}
 
-		var paused, autoRequeue int
+	return counts, rows.Err()
+}
 
-		var runURL, runnerName, errorMessage, createdBy sql.NullString
+// GetGroupJobCounts counts the active jobs of every group, and the jobs each
+// finished since the given time, in two aggregate queries.
+func (s *SQLiteStore) GetGroupJobCounts(ctx context.Context, since time.Time) ([]*GroupJobCounts, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT group_id,
+			SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = 'triggered' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = 'running' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = 'completed' AND completed_at >= ? THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = 'failed' AND completed_at >= ? THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = 'cancelled' AND completed_at >= ? THEN 1 ELSE 0 END)
+		FROM jobs
+		WHERE status IN ('pending', 'triggered', 'running') OR completed_at >= ?
+		GROUP BY group_id
+	`, since, since, since, since)
+	if err != nil {
+		return nil, fmt.Errorf("counting group jobs: %w", err)
+	}
 
-		var runnerID sql.NullInt64
+	defer rows.Close()
 
-		var templateID, name, owner, repo, workflowID, ref, labelsJSON sql.NullString
+	var counts []*GroupJobCounts
 
-		if err := rows.Scan(&job.ID, &job.GroupID, &templateID, &job.Priority, &job.Position,
-			&job.Status, &paused, &autoRequeue, &requeueLimit, &job.RequeueCount, &inputsJSON, &createdBy, &triggeredAt, &runID, &runURL, &runnerID, &runnerName,
-			&completedAt, &errorMessage, &job.CreatedAt, &job.UpdatedAt,
-			&name, &owner, &repo, &workflowID, &ref, &labelsJSON); err != nil {
-			return nil, fmt.Errorf("scanning job: %w", err)
-		}
+	byGroup := make(map[string]*GroupJobCounts)
 
-		if templateID.Valid {
-			job.TemplateID = templateID.String
+	for rows.Next() {
+		var count GroupJobCounts
+		if err := rows.Scan(&count.GroupID, &count.Pending, &count.Triggered, &count.Running,
+			&count.Completed, &count.Failed, &count.Cancelled); err != nil {
+			return nil, fmt.Errorf("scanning group job counts: %w", err)
 		}
 
-		if inputsJSON.Valid && inputsJSON.String != "" {
-			if err := json.Unmarshal([]byte(inputsJSON.String), &job.Inputs); err != nil {
-				return nil, fmt.Errorf("unmarshaling inputs: %w", err)
-			}
-		}
+		counts = append(counts, &count)
+		byGroup[count.GroupID] = &count
+	}
 
-		if triggeredAt.Valid {
-			job.TriggeredAt = &triggeredAt.Time
-		}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating group job counts: %w", err)
+	}
 
-		if completedAt.Valid {
-			job.CompletedAt = &completedAt.Time
-		}
+	oldest, err := s.queryJobs(ctx, `
+		SELECT `+jobSelectColumns("")+`
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY group_id ORDER BY created_at, id) AS oldest_rank
+			FROM jobs WHERE status = 'pending' AND paused = ? AND (not_before IS NULL OR not_before <= ?)
+		) ranked
+		WHERE oldest_rank = 1
+	`, false, time.Now())
+	if err != nil {
+		return nil, err
+	}
 
-		if runID.Valid {
-			job.RunID = &runID.Int64
+	for _, job := range oldest {
+		if count := byGroup[job.GroupID]; count != nil {
+			count.OldestPending = job
 		}
+	}
 
-		if runnerID.Valid {
-			job.RunnerID = &runnerID.Int64
-		}
+	return counts, nil
+}
 
-		job.Paused = paused == 1
-		job.AutoRequeue = autoRequeue == 1
+// ListRecentFailures retrieves the jobs of all groups that failed since the
+// given time, most recent first.
+func (s *SQLiteStore) ListRecentFailures(ctx context.Context, since time.Time, limit int) ([]*Job, error) {
+	query := `
+		SELECT ` + jobSelectColumns("") + `
+		FROM jobs
+		WHERE status = 'failed' AND completed_at >= ?
+		ORDER BY completed_at DESC, id DESC
+	` + fmt.Sprintf(" LIMIT %d", limit)
 
-		if requeueLimit.Valid {
-			limit := int(requeueLimit.Int64)
-			job.RequeueLimit = &limit
-		}
+	return s.queryJobs(ctx, query, since)
+}
 
-		job.RunURL = runURL.String
-		job.RunnerName = runnerName.String
-		job.ErrorMessage = errorMessage.String
-		job.CreatedBy = createdBy.String
+// ListJobsByIDPrefix retrieves the most recently created jobs whose ID starts
+// with prefix. The prefix must not contain LIKE wildcards.
+func (s *SQLiteStore) ListJobsByIDPrefix(ctx context.Context, prefix string, limit int) ([]*Job, error) {
+	query := `
+		SELECT ` + jobSelectColumns("") + `
+		FROM jobs
+		WHERE id LIKE ?
+		ORDER BY created_at DESC, id DESC
+	` + fmt.Sprintf(" LIMIT %d", limit)
 
-		if name.Valid {
-			job.Name = &name.String
-		}
+	return s.queryJobs(ctx, query, prefix+"%")
+}
 
-		if owner.Valid {
-			job.Owner = &owner.String
-		}
+// ListJobsByStatus retrieves all jobs with the given statuses.
+func (s *SQLiteStore) ListJobsByStatus(ctx context.Context, statuses ...JobStatus) ([]*Job, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
 
-		if repo.Valid {
-			job.Repo = &repo.String
-		}
+	placeholders := make([]string, len(statuses))
+	args := make([]any, len(statuses))
 
-		if workflowID.Valid {
-			job.WorkflowID = &workflowID.String
-		}
+	for i, status := range statuses {
+		placeholders[i] = "?"
+		args[i] = status
+	}
 
-		if ref.Valid {
-			job.Ref = &ref.String
-		}
+	query := fmt.Sprintf(`
+		SELECT `+jobSelectColumns("")+`
+		FROM jobs WHERE status IN (%s) ORDER BY position
+	`, strings.Join(placeholders, ","))
 
-		if labelsJSON.Valid && labelsJSON.String != "" {
-			if err := json.Unmarshal([]byte(labelsJSON.String), &job.Labels); err != nil {
-				return nil, fmt.Errorf("unmarshaling labels: %w", err)
-			}
-		}
+	return s.queryJobs(ctx, query, args...)
+}
 
-		jobs = append(jobs, &job)
+// ListJobsCompletedSince returns jobs in one of statuses that have a workflow
+// run and completed at or after since, oldest first.
+func (s *SQLiteStore) ListJobsCompletedSince(ctx context.Context, since time.Time, statuses ...JobStatus) ([]*Job, error) {
+	if len(statuses) == 0 {
+		return nil, nil
 	}
 
-	return jobs, rows.Err()
-}
+	placeholders := make([]string, len(statuses))
+	args := make([]any, 0, len(statuses)+1)
+
+	for i, status := range statuses {
+		placeholders[i] = "?"
+		args = append(args, status)
+	}
+
+	args = append(args, since)
+
+	query := fmt.Sprintf(`
+		SELECT `+jobSelectColumns("")+`
+		FROM jobs WHERE status IN (%s) AND run_id IS NOT NULL AND completed_at >= ?
+		ORDER BY completed_at
+	`, strings.Join(placeholders, ","))
+
+	return s.queryJobs(ctx, query, args...)
+}
+
+func (s *SQLiteStore) queryJobs(ctx context.Context, query string, args ...any) ([]*Job, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying jobs: %w", err)
+	}
+
+	defer rows.Close()
+
+	var jobs []*Job
+
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning job: %w", err)
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
 
 // UpdateJob updates an existing job.
 func (s *SQLiteStore) UpdateJob(ctx context.Context, job *Job) error {
@@ -968,18 +1283,40 @@ func (s *SQLiteStore) UpdateJob(ctx context.Context, job *Job) error {
 		labelsJSON = sql.NullString{String: string(data), Valid: true}
 	}
 
+	var outputsJSON sql.NullString
+	if job.Outputs != nil {
+		data, err := json.Marshal(job.Outputs)
+		if err != nil {
+			return fmt.Errorf("marshaling outputs: %w", err)
+		}
+
+		outputsJSON = sql.NullString{String: string(data), Valid: true}
+	}
+
+	var annotationsJSON sql.NullString
+	if job.Annotations != nil {
+		data, err := json.Marshal(job.Annotations)
+		if err != nil {
+			return fmt.Errorf("marshaling annotations: %w", err)
+		}
+
+		annotationsJSON = sql.NullString{String: string(data), Valid: true}
+	}
+
 	job.UpdatedAt = time.Now()
 
 	_, err = s.db.ExecContext(ctx, `
 		UPDATE jobs SET priority = ?, position = ?, status = ?, paused = ?, auto_requeue = ?, requeue_limit = ?, requeue_count = ?, inputs = ?,
 			   triggered_at = ?, run_id = ?, run_url = ?, runner_id = ?, runner_name = ?,
 			   completed_at = ?, error_message = ?, updated_at = ?,
-			   name = ?, owner = ?, repo = ?, workflow_id = ?, ref = ?, labels = ?
+			   name = ?, owner = ?, repo = ?, workflow_id = ?, ref = ?, labels = ?, outputs = ?,
+			   resolved_sha = ?, created_by = ?, original_created_by = ?, annotations = ?, not_before = ?
 		WHERE id = ?
 	`, job.Priority, job.Position, job.Status, job.Paused, job.AutoRequeue, job.RequeueLimit, job.RequeueCount, string(inputsJSON),
 		job.TriggeredAt, job.RunID, job.RunURL, job.RunnerID, job.RunnerName,
 		job.CompletedAt, job.ErrorMessage, job.UpdatedAt,
-		job.Name, job.Owner, job.Repo, job.WorkflowID, job.Ref, labelsJSON,
+		job.Name, job.Owner, job.Repo, job.WorkflowID, job.Ref, labelsJSON, outputsJSON,
+		job.ResolvedSHA, job.CreatedBy, job.OriginalCreatedBy, annotationsJSON, job.NotBefore,
 		job.ID)
 
 	if err != nil {
@@ -989,6 +1326,126 @@ func (s *SQLiteStore) UpdateJob(ctx context.Context, job *Job) error {
 	return nil
 }
 
+// UpdateJobProgress sets the progress reported by a job's workflow run. A
+// progress report also counts as a heartbeat.
+func (s *SQLiteStore) UpdateJobProgress(ctx context.Context, jobID string, progress *JobProgress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("marshaling progress: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE jobs SET progress = ?, heartbeat_at = ?, stalled_at = NULL, updated_at = ? WHERE id = ?
+	`, string(data), progress.UpdatedAt, time.Now(), jobID)
+	if err != nil {
+		return fmt.Errorf("updating job progress: %w", err)
+	}
+
+	return nil
+}
+
+// RecordJobHeartbeat records a heartbeat from a job's workflow run, clearing
+// its stalled flag.
+func (s *SQLiteStore) RecordJobHeartbeat(ctx context.Context, jobID string, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET heartbeat_at = ?, stalled_at = NULL WHERE id = ?`, at, jobID)
+	if err != nil {
+		return fmt.Errorf("recording job heartbeat: %w", err)
+	}
+
+	return nil
+}
+
+// SetJobStalled flags a job as stalled since stalledAt, or clears the flag
+// when stalledAt is nil.
+func (s *SQLiteStore) SetJobStalled(ctx context.Context, jobID string, stalledAt *time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET stalled_at = ? WHERE id = ?`, stalledAt, jobID)
+	if err != nil {
+		return fmt.Errorf("setting job stalled: %w", err)
+	}
+
+	return nil
+}
+
+// SetJobRetained sets whether a job is kept by history cleanup.
+func (s *SQLiteStore) SetJobRetained(ctx context.Context, jobID string, retained bool) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET retained = ? WHERE id = ?`, retained, jobID)
+	if err != nil {
+		return fmt.Errorf("setting job retained: %w", err)
+	}
+
+	return nil
+}
+
+// SetJobVariant records which variant of a canary template a job uses.
+func (s *SQLiteStore) SetJobVariant(ctx context.Context, jobID string, variant JobVariant) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET variant = ? WHERE id = ?`, string(variant), jobID)
+	if err != nil {
+		return fmt.Errorf("setting job variant: %w", err)
+	}
+
+	return nil
+}
+
+// SetJobHandoffSecrets records the hand-off secrets of a job; nil clears them.
+func (s *SQLiteStore) SetJobHandoffSecrets(ctx context.Context, jobID string, secrets *HandoffSecrets) error {
+	data, err := marshalHandoffSecrets(secrets)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE jobs SET handoff_secrets = ? WHERE id = ?`, data, jobID); err != nil {
+		return fmt.Errorf("setting job handoff secrets: %w", err)
+	}
+
+	return nil
+}
+
+// SetJobNotifyTargets replaces the notify targets of a job; nil clears them.
+func (s *SQLiteStore) SetJobNotifyTargets(ctx context.Context, jobID string, targets []JobNotifyTarget) error {
+	data, err := marshalNotifyTargets(targets)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE jobs SET notify_targets = ? WHERE id = ?`, data, jobID); err != nil {
+		return fmt.Errorf("setting job notify targets: %w", err)
+	}
+
+	return nil
+}
+
+// ListJobsWithHandoffSecrets returns the jobs whose hand-off secrets have not
+// been deleted yet.
+func (s *SQLiteStore) ListJobsWithHandoffSecrets(ctx context.Context) ([]*Job, error) {
+	return s.queryJobs(ctx, `
+		SELECT `+jobSelectColumns("")+`
+		FROM jobs WHERE handoff_secrets IS NOT NULL ORDER BY created_at
+	`)
+}
+
+// SetJobAssignee sets who is investigating a job; an empty assignee clears it.
+func (s *SQLiteStore) SetJobAssignee(ctx context.Context, jobID, assignee string, assignedAt *time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET assignee = ?, assigned_at = ? WHERE id = ?`,
+		sql.NullString{String: assignee, Valid: assignee != ""}, assignedAt, jobID)
+	if err != nil {
+		return fmt.Errorf("setting job assignee: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateJobRunAttempt records the attempt of a job's workflow run and the URLs
+// of its logs and artifacts.
+func (s *SQLiteStore) UpdateJobRunAttempt(ctx context.Context, jobID string, attempt int, logsURL, artifactsURL string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET run_attempt = ?, logs_url = ?, artifacts_url = ? WHERE id = ?`,
+		attempt, logsURL, artifactsURL, jobID)
+	if err != nil {
+		return fmt.Errorf("updating job run attempt: %w", err)
+	}
+
+	return nil
+}
+
 // DeleteJob deletes a job by ID.
 func (s *SQLiteStore) DeleteJob(ctx context.Context, id string) error {
 	_, err := s.db.ExecContext(ctx, `DELETE FROM jobs WHERE id = ?`, id)
@@ -999,12 +1456,14 @@ func (s *SQLiteStore) DeleteJob(ctx context.Context, id string) error {
 	return nil
 }
 
-// DeleteOldJobs deletes completed, failed, or cancelled jobs older than the given time.
+// DeleteOldJobs deletes completed, failed, or cancelled jobs older than the
+// given time, except retained ones.
 func (s *SQLiteStore) DeleteOldJobs(ctx context.Context, olderThan time.Time) (int64, error) {
 	result, err := s.db.ExecContext(ctx, `
 		DELETE FROM jobs
 		WHERE status IN ('completed', 'failed', 'cancelled')
 		AND completed_at < ?
+		AND NOT retained
 	`, olderThan)
 	if err != nil {
 		return 0, fmt.Errorf("deleting old jobs: %w", err)
@@ -1018,6 +1477,41 @@ func (s *SQLiteStore) DeleteOldJobs(ctx context.Context, olderThan time.Time) (i
 	return count, nil
 }
 
+// DeleteExcessJobs deletes the completed, failed, or cancelled jobs of a group
+// beyond the newest keep, in history order. Retained jobs are neither deleted
+// nor counted.
+func (s *SQLiteStore) DeleteExcessJobs(ctx context.Context, groupID string, keep int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM jobs
+		WHERE id IN (
+			SELECT id FROM jobs
+			WHERE group_id = ? AND status IN ('completed', 'failed', 'cancelled') AND NOT retained
+			ORDER BY completed_at DESC, id DESC
+			LIMIT -1 OFFSET ?
+		)
+	`, groupID, keep)
+	if err != nil {
+		return 0, fmt.Errorf("deleting excess jobs: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+
+	return count, nil
+}
+
+// jsonPathEscaper escapes a key for a double-quoted JSON path label.
+var jsonPathEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// jsonKeyPath returns the json_extract path of a top-level key. The key is
+// quoted, so keys containing ".", "[" or quotes address the key itself, as
+// PostgreSQL's ->> does.
+func jsonKeyPath(key string) string {
+	return `$."` + jsonPathEscaper.Replace(key) + `"`
+}
+
 // ListJobHistory retrieves paginated job history with cursor-based pagination.
 func (s *SQLiteStore) ListJobHistory(ctx context.Context, opts HistoryQueryOpts) (*HistoryResult, error) {
 	// Determine which statuses to filter by.
@@ -1039,9 +1533,7 @@ func (s *SQLiteStore) ListJobHistory(ctx context.Context, opts HistoryQueryOpts)
 	needsJoin := len(opts.Labels) > 0
 
 	query := `
-		SELECT j.id, j.group_id, j.template_id, j.priority, j.position, j.status, j.paused, j.auto_requeue, j.requeue_limit, j.requeue_count, j.inputs, j.created_by,
-			   j.triggered_at, j.run_id, j.run_url, j.runner_id, j.runner_name, j.completed_at, j.error_message, j.created_at, j.updated_at,
-			   j.name, j.owner, j.repo, j.workflow_id, j.ref, j.labels
+		SELECT ` + jobSelectColumns("j.") + `
 		FROM jobs j
 	`
 
@@ -1054,23 +1546,46 @@ func (s *SQLiteStore) ListJobHistory(ctx context.Context, opts HistoryQueryOpts)
 		AND j.status IN (%s)
 	`, strings.Join(statusPlaceholders, ","))
 
+	if opts.TemplateID != "" {
+		query += " AND j.template_id = ?"
+		args = append(args, opts.TemplateID)
+	}
+
+	if opts.RetainedOnly {
+		query += " AND j.retained"
+	}
+
+	if opts.Assignee != "" {
+		query += " AND j.assignee = ?"
+		args = append(args, opts.Assignee)
+	}
+
 	// Add label filters using SQLite JSON extraction.
 	for key, value := range opts.Labels {
 		query += " AND json_extract(t.labels, ?) = ?"
-		args = append(args, "$."+key, value)
+		args = append(args, jsonKeyPath(key), value)
 	}
 
 	if opts.Before != nil {
-		query += " AND j.completed_at < ?"
-		args = append(args, *opts.Before)
+		if opts.BeforeID != "" {
+			query += " AND (j.completed_at < ? OR (j.completed_at = ? AND j.id < ?))"
+			args = append(args, *opts.Before, *opts.Before, opts.BeforeID)
+		} else {
+			query += " AND j.completed_at < ?"
+			args = append(args, *opts.Before)
+		}
 	}
 
-	query += " ORDER BY j.completed_at DESC"
+	query += " ORDER BY j.completed_at DESC, j.id DESC"
 
 	// Fetch one extra to check if more exist.
 	fetchLimit := opts.Limit + 1
 	query += fmt.Sprintf(" LIMIT %d", fetchLimit)
 
+	if opts.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", opts.Offset)
+	}
+
 	jobs, err := s.queryJobs(ctx, query, args...)
 	if err != nil {
 		return nil, err
@@ -1091,6 +1606,11 @@ func (s *SQLiteStore) ListJobHistory(ctx context.Context, opts HistoryQueryOpts)
 	if len(result.Jobs) > 0 {
 		lastJob := result.Jobs[len(result.Jobs)-1]
 		result.NextCursor = lastJob.CompletedAt
+		result.NextCursorID = lastJob.ID
+	}
+
+	if opts.SkipCount {
+		return result, nil
 	}
 
 	// Get total count with same filters.
@@ -1109,9 +1629,23 @@ func (s *SQLiteStore) ListJobHistory(ctx context.Context, opts HistoryQueryOpts)
 		countArgs = append(countArgs, status)
 	}
 
+	if opts.TemplateID != "" {
+		countQuery += " AND j.template_id = ?"
+		countArgs = append(countArgs, opts.TemplateID)
+	}
+
+	if opts.RetainedOnly {
+		countQuery += " AND j.retained"
+	}
+
+	if opts.Assignee != "" {
+		countQuery += " AND j.assignee = ?"
+		countArgs = append(countArgs, opts.Assignee)
+	}
+
 	for key, value := range opts.Labels {
 		countQuery += " AND json_extract(t.labels, ?) = ?"
-		countArgs = append(countArgs, "$."+key, value)
+		countArgs = append(countArgs, jsonKeyPath(key), value)
 	}
 
 	var totalCount int
@@ -1170,6 +1704,57 @@ func (s *SQLiteStore) GetHistoryTimeBounds(ctx context.Context, groupID string)
 	return oldest, newest, nil
 }
 
+// GetTemplateDurations returns the mean time from trigger to completion of each
+// template's successfully completed jobs in a group, over jobs completed since
+// the given time. Templates with no such jobs are absent from the map.
+func (s *SQLiteStore) GetTemplateDurations(
+	ctx context.Context, groupID string, since time.Time,
+) (map[string]time.Duration, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT template_id, triggered_at, completed_at
+		FROM jobs
+		WHERE group_id = ? AND status = 'completed' AND template_id IS NOT NULL AND template_id != ''
+		AND triggered_at IS NOT NULL AND completed_at IS NOT NULL AND completed_at >= ?
+	`, groupID, since)
+	if err != nil {
+		return nil, fmt.Errorf("querying template durations: %w", err)
+	}
+
+	defer rows.Close()
+
+	totals := make(map[string]time.Duration)
+	counts := make(map[string]int)
+
+	for rows.Next() {
+		var (
+			templateID               string
+			triggeredAt, completedAt time.Time
+		)
+
+		if err := rows.Scan(&templateID, &triggeredAt, &completedAt); err != nil {
+			return nil, fmt.Errorf("scanning template duration: %w", err)
+		}
+
+		if completedAt.Before(triggeredAt) {
+			continue
+		}
+
+		totals[templateID] += completedAt.Sub(triggeredAt)
+		counts[templateID]++
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating template durations: %w", err)
+	}
+
+	durations := make(map[string]time.Duration, len(totals))
+	for templateID, total := range totals {
+		durations[templateID] = total / time.Duration(counts[templateID])
+	}
+
+	return durations, nil
+}
+
 // GetHistoryStats retrieves aggregated job statistics for a time range.
 func (s *SQLiteStore) GetHistoryStats(ctx context.Context, opts HistoryStatsOpts) (*HistoryStatsResult, error) {
 	// Calculate bucket duration.
@@ -1230,29 +1815,9 @@ func (s *SQLiteStore) GetHistoryStats(ctx context.Context, opts HistoryStatsOpts
 	}
 
 	// Build the full buckets slice, filling empty buckets with zeros.
-	buckets := make([]*HistoryStatsBucket, opts.Buckets)
-	totals := HistoryStatsTotals{}
-
-	for i := range opts.Buckets {
-		bucketTime := opts.Start.Add(time.Duration(i) * bucketDuration)
-
-		if existing, ok := bucketCounts[i]; ok {
-			existing.Timestamp = bucketTime
-			buckets[i] = existing
-			totals.Completed += existing.Completed
-			totals.Failed += existing.Failed
-			totals.Cancelled += existing.Cancelled
-		} else {
-			buckets[i] = &HistoryStatsBucket{
-				Timestamp: bucketTime,
-				Completed: 0,
-				Failed:    0,
-				Cancelled: 0,
-			}
-		}
-	}
+	buckets, totals := fillHistoryStatsBuckets(opts, bucketDuration, bucketCounts)
 
-	return &HistoryStatsResult{
+	result := &HistoryStatsResult{
 		Buckets: buckets,
 		Range: HistoryStatsRange{
 			Start:          opts.Start,
@@ -1260,7 +1825,101 @@ func (s *SQLiteStore) GetHistoryStats(ctx context.Context, opts HistoryStatsOpts
 			BucketDuration: bucketDuration,
 		},
 		Totals: totals,
-	}, nil
+	}
+
+	if opts.GroupBy != "" {
+		series, err := s.getHistoryStatsSeries(ctx, opts, bucketSeconds, bucketDuration)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Series = series
+	}
+
+	return result, nil
+}
+
+// getHistoryStatsSeries returns per-bucket counts split by the dimension in opts.GroupBy.
+func (s *SQLiteStore) getHistoryStatsSeries(
+	ctx context.Context, opts HistoryStatsOpts, bucketSeconds int64, bucketDuration time.Duration,
+) ([]*HistoryStatsSeries, error) {
+	var keyExpr string
+
+	args := []any{opts.Start, bucketSeconds}
+
+	switch opts.GroupBy {
+	case HistoryStatsByTemplate:
+		keyExpr = "COALESCE(j.template_id, '')"
+	case HistoryStatsByCreatedBy:
+		keyExpr = "COALESCE(j.created_by, '')"
+	case HistoryStatsByVariant:
+		keyExpr = "COALESCE(j.variant, '')"
+	case HistoryStatsByLabel:
+		// Job-level label overrides take precedence over template labels.
+		keyExpr = "COALESCE(json_extract(j.labels, ?), json_extract(t.labels, ?), '')"
+		args = append(args, jsonKeyPath(opts.LabelKey), jsonKeyPath(opts.LabelKey))
+	default:
+		return nil, fmt.Errorf("unsupported history stats dimension: %s", opts.GroupBy)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			CAST((strftime('%%s', j.completed_at) - strftime('%%s', ?)) / ? AS INTEGER) AS bucket_idx,
+			%s AS dim_key,
+			SUM(CASE WHEN j.status = 'completed' THEN 1 ELSE 0 END) AS completed,
+			SUM(CASE WHEN j.status = 'failed' THEN 1 ELSE 0 END) AS failed,
+			SUM(CASE WHEN j.status = 'cancelled' THEN 1 ELSE 0 END) AS cancelled
+		FROM jobs j
+		LEFT JOIN job_templates t ON j.template_id = t.id
+		WHERE j.group_id = ?
+		AND j.completed_at >= ?
+		AND j.completed_at < ?
+		AND j.status IN ('completed', 'failed', 'cancelled')
+		GROUP BY bucket_idx, dim_key
+		ORDER BY bucket_idx
+	`, keyExpr)
+
+	args = append(args, opts.GroupID, opts.Start, opts.End)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying history stats series: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]map[int]*HistoryStatsBucket)
+
+	for rows.Next() {
+		var (
+			bucketIdx                    int
+			key                          string
+			completed, failed, cancelled int
+		)
+
+		if err := rows.Scan(&bucketIdx, &key, &completed, &failed, &cancelled); err != nil {
+			return nil, fmt.Errorf("scanning history stats series row: %w", err)
+		}
+
+		if bucketIdx < 0 || bucketIdx >= opts.Buckets {
+			continue
+		}
+
+		if counts[key] == nil {
+			counts[key] = make(map[int]*HistoryStatsBucket)
+		}
+
+		counts[key][bucketIdx] = &HistoryStatsBucket{
+			Completed: completed,
+			Failed:    failed,
+			Cancelled: cancelled,
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating history stats series rows: %w", err)
+	}
+
+	return buildHistoryStatsSeries(opts, bucketDuration, counts), nil
 }
 
 // ReorderJobs updates job positions based on the provided order.
@@ -1286,17 +1945,90 @@ func (s *SQLiteStore) ReorderJobs(ctx context.Context, groupID string, jobIDs []
 	return tx.Commit()
 }
 
+// CompactJobPositions renumbers the pending jobs of a group to consecutive
+// positions starting at 0, keeping their current order. It returns the number
+// of jobs whose position changed.
+func (s *SQLiteStore) CompactJobPositions(ctx context.Context, groupID string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, position FROM jobs
+		WHERE group_id = ? AND status = ?
+		ORDER BY position, created_at, id
+	`, groupID, JobStatusPending)
+	if err != nil {
+		return 0, fmt.Errorf("listing pending jobs: %w", err)
+	}
+
+	type jobPosition struct {
+		id       string
+		position int
+	}
+
+	var jobs []jobPosition
+
+	for rows.Next() {
+		var jp jobPosition
+		if err := rows.Scan(&jp.id, &jp.position); err != nil {
+			rows.Close()
+
+			return 0, fmt.Errorf("scanning job position: %w", err)
+		}
+
+		jobs = append(jobs, jp)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterating job positions: %w", err)
+	}
+
+	changed := 0
+	now := time.Now()
+
+	for i, jp := range jobs {
+		if jp.position == i {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE jobs SET position = ?, updated_at = ? WHERE id = ?
+		`, i, now, jp.id); err != nil {
+			return 0, fmt.Errorf("updating job position: %w", err)
+		}
+
+		changed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return changed, nil
+}
+
 // GetNextPendingJob retrieves the next pending job for a group (lowest position).
-// Paused jobs are excluded from selection.
+// Paused jobs and jobs whose not_before is still ahead are excluded from
+// selection.
 func (s *SQLiteStore) GetNextPendingJob(ctx context.Context, groupID string) (*Job, error) {
 	jobs, err := s.ListJobsByGroup(ctx, groupID, JobStatusPending)
 	if err != nil {
 		return nil, err
 	}
 
-	// Find first non-paused job.
+	now := time.Now()
+
+	// Find first non-paused job that is due.
 	for _, job := range jobs {
-		if !job.Paused {
+		if !job.Paused && job.Due(now) {
 			return job, nil
 		}
 	}
@@ -1335,8 +2067,8 @@ func (s *SQLiteStore) UpsertRunner(ctx context.Context, runner *Runner) error {
 	}
 
 	_, err = s.db.ExecContext(ctx, `
-		INSERT INTO runners (id, name, labels, status, busy, os, last_seen_at, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO runners (id, name, labels, status, busy, os, last_seen_at, created_at, updated_at, hosted, max_runners)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			name = excluded.name,
 			labels = excluded.labels,
@@ -1344,9 +2076,11 @@ func (s *SQLiteStore) UpsertRunner(ctx context.Context, runner *Runner) error {
 			busy = excluded.busy,
 			os = excluded.os,
 			last_seen_at = excluded.last_seen_at,
-			updated_at = excluded.updated_at
+			updated_at = excluded.updated_at,
+			hosted = excluded.hosted,
+			max_runners = excluded.max_runners
 	`, runner.ID, runner.Name, string(labelsJSON), runner.Status, runner.Busy,
-		runner.OS, runner.LastSeenAt, runner.CreatedAt, runner.UpdatedAt)
+		runner.OS, runner.LastSeenAt, runner.CreatedAt, runner.UpdatedAt, runner.Hosted, runner.MaxRunners)
 
 	if err != nil {
 		return fmt.Errorf("upserting runner: %w", err)
@@ -1361,13 +2095,13 @@ func (s *SQLiteStore) GetRunner(ctx context.Context, id int64) (*Runner, error)
 
 	var labelsJSON string
 
-	var busy int
+	var busy, hosted int
 
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, name, labels, status, busy, os, last_seen_at, created_at, updated_at
+		SELECT id, name, labels, status, busy, os, last_seen_at, created_at, updated_at, hosted, max_runners
 		FROM runners WHERE id = ?
 	`, id).Scan(&runner.ID, &runner.Name, &labelsJSON, &runner.Status, &busy,
-		&runner.OS, &runner.LastSeenAt, &runner.CreatedAt, &runner.UpdatedAt)
+		&runner.OS, &runner.LastSeenAt, &runner.CreatedAt, &runner.UpdatedAt, &hosted, &runner.MaxRunners)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -1382,6 +2116,7 @@ func (s *SQLiteStore) GetRunner(ctx context.Context, id int64) (*Runner, error)
 	}
 
 	runner.Busy = busy == 1
+	runner.Hosted = hosted == 1
 
 	return &runner, nil
 }
@@ -1392,13 +2127,13 @@ func (s *SQLiteStore) GetRunnerByName(ctx context.Context, name string) (*Runner
 
 	var labelsJSON string
 
-	var busy int
+	var busy, hosted int
 
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, name, labels, status, busy, os, last_seen_at, created_at, updated_at
+		SELECT id, name, labels, status, busy, os, last_seen_at, created_at, updated_at, hosted, max_runners
 		FROM runners WHERE name = ?
 	`, name).Scan(&runner.ID, &runner.Name, &labelsJSON, &runner.Status, &busy,
-		&runner.OS, &runner.LastSeenAt, &runner.CreatedAt, &runner.UpdatedAt)
+		&runner.OS, &runner.LastSeenAt, &runner.CreatedAt, &runner.UpdatedAt, &hosted, &runner.MaxRunners)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -1413,6 +2148,7 @@ func (s *SQLiteStore) GetRunnerByName(ctx context.Context, name string) (*Runner
 	}
 
 	runner.Busy = busy == 1
+	runner.Hosted = hosted == 1
 
 	return &runner, nil
 }
@@ -1420,7 +2156,7 @@ func (s *SQLiteStore) GetRunnerByName(ctx context.Context, name string) (*Runner
 // ListRunners retrieves all runners.
 func (s *SQLiteStore) ListRunners(ctx context.Context) ([]*Runner, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, name, labels, status, busy, os, last_seen_at, created_at, updated_at
+		SELECT id, name, labels, status, busy, os, last_seen_at, created_at, updated_at, hosted, max_runners
 		FROM runners ORDER BY name
 	`)
 	if err != nil {
@@ -1475,10 +2211,10 @@ func (s *SQLiteStore) scanRunners(rows *sql.Rows) ([]*Runner, error) {
 
 		var labelsJSON string
 
-		var busy int
+		var busy, hosted int
 
 		if err := rows.Scan(&runner.ID, &runner.Name, &labelsJSON, &runner.Status, &busy,
-			&runner.OS, &runner.LastSeenAt, &runner.CreatedAt, &runner.UpdatedAt); err != nil {
+			&runner.OS, &runner.LastSeenAt, &runner.CreatedAt, &runner.UpdatedAt, &hosted, &runner.MaxRunners); err != nil {
 			return nil, fmt.Errorf("scanning runner: %w", err)
 		}
 
@@ -1487,6 +2223,7 @@ func (s *SQLiteStore) scanRunners(rows *sql.Rows) ([]*Runner, error) {
 		}
 
 		runner.Busy = busy == 1
+		runner.Hosted = hosted == 1
 		runners = append(runners, &runner)
 	}
 
@@ -1610,10 +2347,42 @@ func (s *SQLiteStore) GetUserByGitHubID(ctx context.Context, githubID string) (*
 	return &user, nil
 }
 
-// UpdateUser updates an existing user.
-func (s *SQLiteStore) UpdateUser(ctx context.Context, user *User) error {
-	user.UpdatedAt = time.Now()
-
+// ListUsers retrieves all users.
+func (s *SQLiteStore) ListUsers(ctx context.Context) ([]*User, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, username, password_hash, role, auth_provider, github_id, created_at, updated_at
+		FROM users ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying users: %w", err)
+	}
+
+	defer rows.Close()
+
+	var users []*User
+
+	for rows.Next() {
+		var user User
+
+		var passwordHash, githubID sql.NullString
+
+		if err := rows.Scan(&user.ID, &user.Username, &passwordHash, &user.Role, &user.AuthProvider,
+			&githubID, &user.CreatedAt, &user.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning user: %w", err)
+		}
+
+		user.PasswordHash = passwordHash.String
+		user.GitHubID = githubID.String
+		users = append(users, &user)
+	}
+
+	return users, rows.Err()
+}
+
+// UpdateUser updates an existing user.
+func (s *SQLiteStore) UpdateUser(ctx context.Context, user *User) error {
+	user.UpdatedAt = time.Now()
+
 	_, err := s.db.ExecContext(ctx, `
 		UPDATE users SET username = ?, password_hash = ?, role = ?, github_id = ?, updated_at = ?
 		WHERE id = ?
@@ -1643,9 +2412,10 @@ func (s *SQLiteStore) DeleteUser(ctx context.Context, id string) error {
 // CreateSession creates a new session.
 func (s *SQLiteStore) CreateSession(ctx context.Context, session *Session) error {
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO sessions (id, user_id, token_hash, expires_at, created_at)
-		VALUES (?, ?, ?, ?, ?)
-	`, session.ID, session.UserID, session.TokenHash, session.ExpiresAt, session.CreatedAt)
+		INSERT INTO sessions (`+sessionSelectColumns()+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, session.ID, session.UserID, session.TokenHash, session.ExpiresAt, session.CreatedAt,
+		session.IPAddress, session.UserAgent, session.LastUsedAt)
 
 	if err != nil {
 		return fmt.Errorf("inserting session: %w", err)
@@ -1656,12 +2426,8 @@ func (s *SQLiteStore) CreateSession(ctx context.Context, session *Session) error
 
 // GetSession retrieves a session by ID.
 func (s *SQLiteStore) GetSession(ctx context.Context, id string) (*Session, error) {
-	var session Session
-
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, user_id, token_hash, expires_at, created_at
-		FROM sessions WHERE id = ?
-	`, id).Scan(&session.ID, &session.UserID, &session.TokenHash, &session.ExpiresAt, &session.CreatedAt)
+	session, err := scanSession(s.db.QueryRowContext(ctx,
+		`SELECT `+sessionSelectColumns()+` FROM sessions WHERE id = ?`, id))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -1671,17 +2437,13 @@ func (s *SQLiteStore) GetSession(ctx context.Context, id string) (*Session, erro
 		return nil, fmt.Errorf("querying session: %w", err)
 	}
 
-	return &session, nil
+	return session, nil
 }
 
 // GetSessionByToken retrieves a session by token hash.
 func (s *SQLiteStore) GetSessionByToken(ctx context.Context, tokenHash string) (*Session, error) {
-	var session Session
-
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, user_id, token_hash, expires_at, created_at
-		FROM sessions WHERE token_hash = ?
-	`, tokenHash).Scan(&session.ID, &session.UserID, &session.TokenHash, &session.ExpiresAt, &session.CreatedAt)
+	session, err := scanSession(s.db.QueryRowContext(ctx,
+		`SELECT `+sessionSelectColumns()+` FROM sessions WHERE token_hash = ?`, tokenHash))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -1691,7 +2453,53 @@ func (s *SQLiteStore) GetSessionByToken(ctx context.Context, tokenHash string) (
 		return nil, fmt.Errorf("querying session by token: %w", err)
 	}
 
-	return &session, nil
+	return session, nil
+}
+
+// ListSessions retrieves all sessions, including expired ones not yet cleaned up.
+func (s *SQLiteStore) ListSessions(ctx context.Context) ([]*Session, error) {
+	return s.querySessions(ctx, `SELECT `+sessionSelectColumns()+` FROM sessions ORDER BY created_at`)
+}
+
+// ListUserSessions retrieves a user's sessions, including expired ones not yet cleaned up.
+func (s *SQLiteStore) ListUserSessions(ctx context.Context, userID string) ([]*Session, error) {
+	return s.querySessions(ctx,
+		`SELECT `+sessionSelectColumns()+` FROM sessions WHERE user_id = ? ORDER BY created_at`, userID)
+}
+
+// querySessions runs a query selecting sessionSelectColumns.
+func (s *SQLiteStore) querySessions(ctx context.Context, query string, args ...any) ([]*Session, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying sessions: %w", err)
+	}
+
+	defer rows.Close()
+
+	var sessions []*Session
+
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning session: %w", err)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// TouchSession records a request made with a session.
+func (s *SQLiteStore) TouchSession(ctx context.Context, id string, usedAt time.Time, ipAddress, userAgent string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE sessions SET last_used_at = ?, ip_address = ?, user_agent = ? WHERE id = ?
+	`, usedAt, ipAddress, userAgent, id)
+	if err != nil {
+		return fmt.Errorf("touching session: %w", err)
+	}
+
+	return nil
 }
 
 // DeleteSession deletes a session by ID.
@@ -1952,3 +2760,799 @@ func (s *SQLiteStore) DeleteExpiredAuthCodes(ctx context.Context) error {
 
 	return nil
 }
+
+// ============================================================================
+// Saved Filters
+// ============================================================================
+
+// CreateSavedFilter creates a new saved filter.
+func (s *SQLiteStore) CreateSavedFilter(ctx context.Context, filter *SavedFilter) error {
+	statuses, labels, err := marshalSavedFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO saved_filters (`+savedFilterSelectColumns()+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, filter.ID, filter.UserID, filter.Name, filter.View, filter.GroupID, statuses, labels,
+		filter.TemplateID, filter.CreatedAt, filter.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("inserting saved_filter: %w", err)
+	}
+
+	return nil
+}
+
+// GetSavedFilter retrieves a saved filter by ID.
+func (s *SQLiteStore) GetSavedFilter(ctx context.Context, id string) (*SavedFilter, error) {
+	filter, err := scanSavedFilter(s.db.QueryRowContext(ctx, `
+		SELECT `+savedFilterSelectColumns()+`
+		FROM saved_filters WHERE id = ?
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("querying saved_filter: %w", err)
+	}
+
+	return filter, nil
+}
+
+// ListSavedFiltersByUser retrieves all saved filters of a user, ordered by view and name.
+func (s *SQLiteStore) ListSavedFiltersByUser(ctx context.Context, userID string) ([]*SavedFilter, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+savedFilterSelectColumns()+`
+		FROM saved_filters WHERE user_id = ?
+		ORDER BY view, name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying saved_filters: %w", err)
+	}
+
+	defer rows.Close()
+
+	var filters []*SavedFilter
+
+	for rows.Next() {
+		filter, err := scanSavedFilter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning saved_filter: %w", err)
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters, rows.Err()
+}
+
+// UpdateSavedFilter updates an existing saved filter.
+func (s *SQLiteStore) UpdateSavedFilter(ctx context.Context, filter *SavedFilter) error {
+	filter.UpdatedAt = time.Now()
+
+	statuses, labels, err := marshalSavedFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE saved_filters
+		SET name = ?, view = ?, group_id = ?, statuses = ?, labels = ?,
+			template_id = ?, updated_at = ?
+		WHERE id = ?
+	`, filter.Name, filter.View, filter.GroupID, statuses, labels, filter.TemplateID, filter.UpdatedAt, filter.ID)
+
+	if err != nil {
+		return fmt.Errorf("updating saved_filter: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSavedFilter deletes a saved filter.
+func (s *SQLiteStore) DeleteSavedFilter(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM saved_filters WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting saved_filter: %w", err)
+	}
+
+	return nil
+}
+
+// ============================================================================
+// Subscriptions
+// ============================================================================
+
+// CreateSubscription creates a new subscription.
+func (s *SQLiteStore) CreateSubscription(ctx context.Context, sub *Subscription) error {
+	statuses, err := json.Marshal(sub.Statuses)
+	if err != nil {
+		return fmt.Errorf("marshaling statuses: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO subscriptions (`+subscriptionSelectColumns()+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, sub.ID, sub.UserID, sub.TargetType, sub.TargetID, sub.Channel, sub.Destination, string(statuses), sub.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("inserting subscription: %w", err)
+	}
+
+	return nil
+}
+
+// GetSubscription retrieves a subscription by ID.
+func (s *SQLiteStore) GetSubscription(ctx context.Context, id string) (*Subscription, error) {
+	sub, err := scanSubscription(s.db.QueryRowContext(ctx, `
+		SELECT `+subscriptionSelectColumns()+`
+		FROM subscriptions WHERE id = ?
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("querying subscription: %w", err)
+	}
+
+	return sub, nil
+}
+
+// ListSubscriptionsByUser retrieves all subscriptions of a user, oldest first.
+func (s *SQLiteStore) ListSubscriptionsByUser(ctx context.Context, userID string) ([]*Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+subscriptionSelectColumns()+`
+		FROM subscriptions WHERE user_id = ?
+		ORDER BY created_at, id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying subscriptions: %w", err)
+	}
+
+	return scanSubscriptions(rows)
+}
+
+// ListSubscriptionsByTarget retrieves all subscriptions to a job or template.
+func (s *SQLiteStore) ListSubscriptionsByTarget(
+	ctx context.Context,
+	targetType SubscriptionTarget,
+	targetID string,
+) ([]*Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+subscriptionSelectColumns()+`
+		FROM subscriptions WHERE target_type = ? AND target_id = ?
+		ORDER BY created_at, id
+	`, targetType, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("querying subscriptions: %w", err)
+	}
+
+	return scanSubscriptions(rows)
+}
+
+// DeleteSubscription deletes a subscription.
+func (s *SQLiteStore) DeleteSubscription(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM subscriptions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting subscription: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSubscriptionsByTarget deletes all subscriptions to a job or template.
+func (s *SQLiteStore) DeleteSubscriptionsByTarget(ctx context.Context, targetType SubscriptionTarget, targetID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM subscriptions WHERE target_type = ? AND target_id = ?`,
+		targetType, targetID)
+	if err != nil {
+		return fmt.Errorf("deleting subscriptions: %w", err)
+	}
+
+	return nil
+}
+
+// ============================================================================
+// Job Events
+// ============================================================================
+
+// CreateJobEvent records a job status transition.
+func (s *SQLiteStore) CreateJobEvent(ctx context.Context, event *JobEvent) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO job_events (`+jobEventSelectColumns()+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, event.ID, event.JobID, event.FromStatus, event.ToStatus, event.Actor, event.RunID,
+		event.RunnerName, event.Message, event.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("inserting job_event: %w", err)
+	}
+
+	return nil
+}
+
+// ListJobEvents retrieves the events of a job, oldest first.
+func (s *SQLiteStore) ListJobEvents(ctx context.Context, jobID string) ([]*JobEvent, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+jobEventSelectColumns()+`
+		FROM job_events WHERE job_id = ?
+		ORDER BY created_at, id
+	`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("querying job_events: %w", err)
+	}
+
+	defer rows.Close()
+
+	var events []*JobEvent
+
+	for rows.Next() {
+		event, err := scanJobEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning job_event: %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// ============================================================================
+// Job Run Attempts
+// ============================================================================
+
+// CreateJobRunAttempt records a finished run attempt of a job. An attempt
+// already recorded for the job is left unchanged.
+func (s *SQLiteStore) CreateJobRunAttempt(ctx context.Context, attempt *JobRunAttempt) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO job_run_attempts (`+strings.Join(jobRunAttemptColumns, ", ")+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (job_id, attempt) DO NOTHING
+	`, attempt.JobID, attempt.Attempt, attempt.Status, attempt.ErrorMessage, attempt.RunnerName,
+		attempt.LogsURL, attempt.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("inserting job_run_attempt: %w", err)
+	}
+
+	return nil
+}
+
+// ListJobRunAttempts retrieves the recorded run attempts of a job, oldest first.
+func (s *SQLiteStore) ListJobRunAttempts(ctx context.Context, jobID string) ([]*JobRunAttempt, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+strings.Join(jobRunAttemptColumns, ", ")+`
+		FROM job_run_attempts WHERE job_id = ?
+		ORDER BY attempt
+	`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("querying job_run_attempts: %w", err)
+	}
+
+	defer rows.Close()
+
+	var attempts []*JobRunAttempt
+
+	for rows.Next() {
+		attempt, err := scanJobRunAttempt(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning job_run_attempt: %w", err)
+		}
+
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, rows.Err()
+}
+
+// ============================================================================
+// Campaigns
+// ============================================================================
+
+// CreateCampaign creates a new campaign.
+func (s *SQLiteStore) CreateCampaign(ctx context.Context, campaign *Campaign) error {
+	metadataJSON, err := json.Marshal(campaign.Metadata)
+	if err != nil {
+		return fmt.Errorf("marshaling metadata: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO campaigns (`+campaignSelectColumns()+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, campaign.ID, campaign.Name, campaign.Description, string(metadataJSON), campaign.CreatedBy,
+		campaign.CreatedAt, campaign.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("inserting campaign: %w", err)
+	}
+
+	return nil
+}
+
+// GetCampaign retrieves a campaign by ID.
+func (s *SQLiteStore) GetCampaign(ctx context.Context, id string) (*Campaign, error) {
+	campaign, err := scanCampaign(s.db.QueryRowContext(ctx, `
+		SELECT `+campaignSelectColumns()+`
+		FROM campaigns WHERE id = ?
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("querying campaign: %w", err)
+	}
+
+	return campaign, nil
+}
+
+// ListCampaigns retrieves all campaigns, newest first.
+func (s *SQLiteStore) ListCampaigns(ctx context.Context) ([]*Campaign, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+campaignSelectColumns()+`
+		FROM campaigns
+		ORDER BY created_at DESC, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying campaigns: %w", err)
+	}
+
+	defer rows.Close()
+
+	var campaigns []*Campaign
+
+	for rows.Next() {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning campaign: %w", err)
+		}
+
+		campaigns = append(campaigns, campaign)
+	}
+
+	return campaigns, rows.Err()
+}
+
+// ListJobsByCampaign retrieves the jobs of a campaign, oldest first.
+func (s *SQLiteStore) ListJobsByCampaign(ctx context.Context, campaignID string) ([]*Job, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+jobSelectColumns("")+`
+		FROM jobs WHERE campaign_id = ?
+		ORDER BY created_at, id
+	`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("querying jobs: %w", err)
+	}
+
+	defer rows.Close()
+
+	var jobs []*Job
+
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning job: %w", err)
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// ============================================================================
+// Queue Changes
+// ============================================================================
+
+// CreateQueueChange records a manual change to a group's queue.
+func (s *SQLiteStore) CreateQueueChange(ctx context.Context, change *QueueChange) error {
+	before, after, err := marshalQueueSnapshots(change)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO queue_changes (`+queueChangeSelectColumns()+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, change.ID, change.GroupID, change.JobID, change.Operation, change.Actor, before, after, change.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("inserting queue_change: %w", err)
+	}
+
+	return nil
+}
+
+// ListQueueChanges retrieves the queue changes of a group, newest first, with
+// the total number of matches ignoring Limit and Offset.
+func (s *SQLiteStore) ListQueueChanges(ctx context.Context, opts QueueChangeQueryOpts) ([]*QueueChange, int, error) {
+	where := ` WHERE group_id = ?`
+	args := []any{opts.GroupID}
+
+	if opts.JobID != "" {
+		// Reorders record every pending job, so match them by snapshot content.
+		where += ` AND (job_id = ? OR (job_id = '' AND before_snapshot LIKE ?))`
+		jobIDJSON, err := json.Marshal(opts.JobID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("marshaling job_id: %w", err)
+		}
+
+		args = append(args, opts.JobID, `%"job_id":`+string(jobIDJSON)+`%`)
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM queue_changes`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting queue_changes: %w", err)
+	}
+
+	query := `SELECT ` + queueChangeSelectColumns() + ` FROM queue_changes` + where + ` ORDER BY created_at DESC, id`
+
+	if opts.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", opts.Limit)
+	}
+
+	if opts.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", opts.Offset)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("querying queue_changes: %w", err)
+	}
+
+	defer rows.Close()
+
+	var changes []*QueueChange
+
+	for rows.Next() {
+		change, err := scanQueueChange(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scanning queue_change: %w", err)
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, total, rows.Err()
+}
+
+// ============================================================================
+// Workflow Locks
+// ============================================================================
+
+const (
+	// workflowLockLease is how long a lock is held without renewal before
+	// another process may take it over, so locks left by a crashed process do
+	// not block forever. Held locks are renewed every third of it.
+	workflowLockLease = 5 * time.Minute
+
+	// workflowLockRetryInterval is how often a held lock is retried.
+	workflowLockRetryInterval = 100 * time.Millisecond
+)
+
+// AcquireWorkflowLock takes a row in the workflow_locks table, waiting for
+// the current holder to release it or for its lease to expire. The lease is
+// renewed until the lock is released, however long it is held.
+func (s *SQLiteStore) AcquireWorkflowLock(ctx context.Context, key string) (func(), error) {
+	holder := uuid.New().String()
+
+	ticker := time.NewTicker(workflowLockRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+
+		result, err := s.db.ExecContext(ctx, `
+			INSERT INTO workflow_locks (key, holder, expires_at)
+			VALUES (?, ?, ?)
+			ON CONFLICT(key) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+			WHERE workflow_locks.expires_at < ?
+		`, key, holder, now.Add(s.lockLease), now)
+		if err != nil {
+			return nil, fmt.Errorf("acquiring workflow lock: %w", err)
+		}
+
+		if n, _ := result.RowsAffected(); n > 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("acquiring workflow lock: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go s.renewWorkflowLock(key, holder, stop, done)
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-done
+
+			// Release even if the caller's context has been cancelled.
+			if _, err := s.db.ExecContext(context.Background(),
+				`DELETE FROM workflow_locks WHERE key = ? AND holder = ?`, key, holder); err != nil {
+				s.log.WithError(err).WithField("key", key).Warn("Failed to release workflow lock")
+			}
+		})
+	}, nil
+}
+
+// renewWorkflowLock extends the lease of a held workflow lock until stop is
+// closed, then closes done.
+func (s *SQLiteStore) renewWorkflowLock(key, holder string, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.lockLease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		result, err := s.db.ExecContext(context.Background(),
+			`UPDATE workflow_locks SET expires_at = ? WHERE key = ? AND holder = ?`,
+			time.Now().Add(s.lockLease), key, holder)
+		if err != nil {
+			s.log.WithError(err).WithField("key", key).Warn("Failed to renew workflow lock")
+
+			continue
+		}
+
+		if n, _ := result.RowsAffected(); n == 0 {
+			s.log.WithField("key", key).Error("Workflow lock lease expired while held")
+
+			return
+		}
+	}
+}
+
+// ============================================================================
+// Queue Stats
+// ============================================================================
+
+// CreateQueueStatSamples stores queue stat samples. A sample repeating the
+// group and time of a stored one is ignored.
+func (s *SQLiteStore) CreateQueueStatSamples(ctx context.Context, samples []*QueueStatSample) error {
+	for _, sample := range samples {
+		if _, err := s.db.ExecContext(ctx, `
+			INSERT INTO queue_stats (group_id, sampled_at, pending, running, idle_runners)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (group_id, sampled_at) DO NOTHING
+		`, sample.GroupID, sample.SampledAt, sample.Pending, sample.Running, sample.IdleRunners); err != nil {
+			return fmt.Errorf("inserting queue_stats: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListQueueStatSamples retrieves a group's queue stat samples taken at or
+// after since, oldest first.
+func (s *SQLiteStore) ListQueueStatSamples(ctx context.Context, groupID string, since time.Time) ([]*QueueStatSample, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT group_id, sampled_at, pending, running, idle_runners
+		FROM queue_stats WHERE group_id = ? AND sampled_at >= ?
+		ORDER BY sampled_at
+	`, groupID, since)
+	if err != nil {
+		return nil, fmt.Errorf("querying queue_stats: %w", err)
+	}
+
+	defer rows.Close()
+
+	var samples []*QueueStatSample
+
+	for rows.Next() {
+		var sample QueueStatSample
+
+		if err := rows.Scan(&sample.GroupID, &sample.SampledAt, &sample.Pending, &sample.Running,
+			&sample.IdleRunners); err != nil {
+			return nil, fmt.Errorf("scanning queue_stats: %w", err)
+		}
+
+		samples = append(samples, &sample)
+	}
+
+	return samples, rows.Err()
+}
+
+// DeleteQueueStatSamplesBefore deletes the queue stat samples of every group
+// taken before the given time and returns how many were deleted.
+func (s *SQLiteStore) DeleteQueueStatSamplesBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM queue_stats WHERE sampled_at < ?`, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting queue_stats: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// ============================================================================
+// Settings
+// ============================================================================
+
+// ListSettings returns every runtime setting override, ordered by key.
+func (s *SQLiteStore) ListSettings(ctx context.Context) ([]*Setting, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT key, value, updated_by, updated_at FROM settings ORDER BY key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying settings: %w", err)
+	}
+
+	defer rows.Close()
+
+	var settings []*Setting
+
+	for rows.Next() {
+		var setting Setting
+
+		if err := rows.Scan(&setting.Key, &setting.Value, &setting.UpdatedBy, &setting.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning settings: %w", err)
+		}
+
+		settings = append(settings, &setting)
+	}
+
+	return settings, rows.Err()
+}
+
+// UpsertSetting creates or replaces a runtime setting override.
+func (s *SQLiteStore) UpsertSetting(ctx context.Context, setting *Setting) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO settings (key, value, updated_by, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET
+			value = excluded.value, updated_by = excluded.updated_by, updated_at = excluded.updated_at
+	`, setting.Key, setting.Value, setting.UpdatedBy, setting.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upserting setting: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSetting removes a runtime setting override, if there is one.
+func (s *SQLiteStore) DeleteSetting(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM settings WHERE key = ?`, key); err != nil {
+		return fmt.Errorf("deleting setting: %w", err)
+	}
+
+	return nil
+}
+
+// ============================================================================
+// Maintenance
+// ============================================================================
+
+// RunMaintenance vacuums and/or analyzes the whole database. VACUUM rewrites
+// the database file and blocks writers while it runs.
+func (s *SQLiteStore) RunMaintenance(ctx context.Context, opts MaintenanceOpts) ([]*MaintenanceResult, error) {
+	var statements []string
+
+	if opts.Vacuum {
+		statements = append(statements, "VACUUM")
+	}
+
+	if opts.Analyze {
+		statements = append(statements, "ANALYZE")
+	}
+
+	return runMaintenance(ctx, s.db, statements)
+}
+
+// DatabaseStats counts the rows of every table and sizes the database from its
+// page counts. Table sizes come from the dbstat virtual table, when SQLite is
+// built with it.
+func (s *SQLiteStore) DatabaseStats(ctx context.Context) (*DatabaseStats, error) {
+	stats := &DatabaseStats{Driver: "sqlite"}
+
+	var pageSize, pageCount, freePages int64
+
+	for _, pragma := range []struct {
+		name string
+		dst  *int64
+	}{
+		{"page_size", &pageSize},
+		{"page_count", &pageCount},
+		{"freelist_count", &freePages},
+	} {
+		if err := s.db.QueryRowContext(ctx, "PRAGMA "+pragma.name).Scan(pragma.dst); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", pragma.name, err)
+		}
+	}
+
+	freeBytes := freePages * pageSize
+	stats.SizeBytes = pageCount * pageSize
+	stats.FreeBytes = &freeBytes
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("listing tables: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		table := &TableStats{}
+
+		if err := rows.Scan(&table.Table); err != nil {
+			return nil, fmt.Errorf("scanning tables: %w", err)
+		}
+
+		stats.Tables = append(stats.Tables, table)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("listing tables: %w", err)
+	}
+
+	sizes := s.tableSizes(ctx)
+
+	for _, table := range stats.Tables {
+		query := `SELECT COUNT(*) FROM "` + strings.ReplaceAll(table.Table, `"`, `""`) + `"`
+		if err := s.db.QueryRowContext(ctx, query).Scan(&table.Rows); err != nil {
+			return nil, fmt.Errorf("counting rows of %s: %w", table.Table, err)
+		}
+
+		if size, ok := sizes[table.Table]; ok {
+			table.SizeBytes = &size
+		}
+	}
+
+	return stats, nil
+}
+
+// tableSizes returns the bytes used by each table and its indexes, or nil when
+// SQLite is built without the dbstat virtual table.
+func (s *SQLiteStore) tableSizes(ctx context.Context) map[string]int64 {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT m.tbl_name, SUM(d.pgsize)
+		FROM dbstat d JOIN sqlite_master m ON m.name = d.name
+		GROUP BY m.tbl_name
+	`)
+	if err != nil {
+		s.log.WithError(err).Debug("Table sizes are unavailable")
+
+		return nil
+	}
+
+	defer rows.Close()
+
+	sizes := make(map[string]int64)
+
+	for rows.Next() {
+		var (
+			table string
+			size  int64
+		)
+
+		if err := rows.Scan(&table, &size); err != nil {
+			s.log.WithError(err).Debug("Table sizes are unavailable")
+
+			return nil
+		}
+
+		sizes[table] = size
+	}
+
+	return sizes
+}
@@ -3,6 +3,9 @@ package store
 import (
 	"context"
 	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/schedule"
 )
 
 // Store defines the interface for database operations.
@@ -31,18 +34,42 @@ type Store interface {
 	UpdateTemplateInConfig(ctx context.Context, id string, inConfig bool) error
 	HasAnyJobs(ctx context.Context, templateID string) (bool, error)
 
+	// Template Lints.
+	UpsertTemplateLint(ctx context.Context, lint *TemplateLint) error
+	GetTemplateLint(ctx context.Context, templateID string) (*TemplateLint, error)
+
 	// Jobs.
 	CreateJob(ctx context.Context, job *Job) error
 	GetJob(ctx context.Context, id string) (*Job, error)
+	GetJobByRunID(ctx context.Context, runID int64) (*Job, error)
 	ListJobsByGroup(ctx context.Context, groupID string, statuses ...JobStatus) ([]*Job, error)
+	ListPendingJobs(ctx context.Context, opts PendingQueryOpts) (*PendingResult, error)
+	CountQueueJobs(ctx context.Context, groupID string) ([]*QueueCount, error)
+	GetGroupJobCounts(ctx context.Context, since time.Time) ([]*GroupJobCounts, error)
+	ListRecentFailures(ctx context.Context, since time.Time, limit int) ([]*Job, error)
+	ListJobsByIDPrefix(ctx context.Context, prefix string, limit int) ([]*Job, error)
 	ListJobsByStatus(ctx context.Context, statuses ...JobStatus) ([]*Job, error)
+	ListJobsCompletedSince(ctx context.Context, since time.Time, statuses ...JobStatus) ([]*Job, error)
 	ListJobHistory(ctx context.Context, opts HistoryQueryOpts) (*HistoryResult, error)
 	GetHistoryStats(ctx context.Context, opts HistoryStatsOpts) (*HistoryStatsResult, error)
 	GetHistoryTimeBounds(ctx context.Context, groupID string) (oldest, newest *time.Time, err error)
+	GetTemplateDurations(ctx context.Context, groupID string, since time.Time) (map[string]time.Duration, error)
 	UpdateJob(ctx context.Context, job *Job) error
+	UpdateJobProgress(ctx context.Context, jobID string, progress *JobProgress) error
+	RecordJobHeartbeat(ctx context.Context, jobID string, at time.Time) error
+	SetJobStalled(ctx context.Context, jobID string, stalledAt *time.Time) error
+	UpdateJobRunAttempt(ctx context.Context, jobID string, attempt int, logsURL, artifactsURL string) error
+	SetJobRetained(ctx context.Context, jobID string, retained bool) error
+	SetJobAssignee(ctx context.Context, jobID, assignee string, assignedAt *time.Time) error
+	SetJobVariant(ctx context.Context, jobID string, variant JobVariant) error
+	SetJobHandoffSecrets(ctx context.Context, jobID string, secrets *HandoffSecrets) error
+	ListJobsWithHandoffSecrets(ctx context.Context) ([]*Job, error)
+	SetJobNotifyTargets(ctx context.Context, jobID string, targets []JobNotifyTarget) error
 	DeleteJob(ctx context.Context, id string) error
 	DeleteOldJobs(ctx context.Context, olderThan time.Time) (int64, error)
+	DeleteExcessJobs(ctx context.Context, groupID string, keep int) (int64, error)
 	ReorderJobs(ctx context.Context, groupID string, jobIDs []string) error
+	CompactJobPositions(ctx context.Context, groupID string) (int, error)
 	GetNextPendingJob(ctx context.Context, groupID string) (*Job, error)
 	GetMaxPosition(ctx context.Context, groupID string) (int, error)
 
@@ -60,6 +87,7 @@ type Store interface {
 	GetUser(ctx context.Context, id string) (*User, error)
 	GetUserByUsername(ctx context.Context, username string) (*User, error)
 	GetUserByGitHubID(ctx context.Context, githubID string) (*User, error)
+	ListUsers(ctx context.Context) ([]*User, error)
 	UpdateUser(ctx context.Context, user *User) error
 	DeleteUser(ctx context.Context, id string) error
 
@@ -67,6 +95,9 @@ type Store interface {
 	CreateSession(ctx context.Context, session *Session) error
 	GetSession(ctx context.Context, id string) (*Session, error)
 	GetSessionByToken(ctx context.Context, tokenHash string) (*Session, error)
+	ListSessions(ctx context.Context) ([]*Session, error)
+	ListUserSessions(ctx context.Context, userID string) ([]*Session, error)
+	TouchSession(ctx context.Context, id string, usedAt time.Time, ipAddress, userAgent string) error
 	DeleteSession(ctx context.Context, id string) error
 	DeleteExpiredSessions(ctx context.Context) error
 	DeleteUserSessions(ctx context.Context, userID string) error
@@ -83,24 +114,144 @@ type Store interface {
 	DeleteAuthCode(ctx context.Context, code string) error
 	DeleteExpiredAuthCodes(ctx context.Context) error
 
+	// Saved Filters.
+	CreateSavedFilter(ctx context.Context, filter *SavedFilter) error
+	GetSavedFilter(ctx context.Context, id string) (*SavedFilter, error)
+	ListSavedFiltersByUser(ctx context.Context, userID string) ([]*SavedFilter, error)
+	UpdateSavedFilter(ctx context.Context, filter *SavedFilter) error
+	DeleteSavedFilter(ctx context.Context, id string) error
+
+	// Subscriptions.
+	CreateSubscription(ctx context.Context, sub *Subscription) error
+	GetSubscription(ctx context.Context, id string) (*Subscription, error)
+	ListSubscriptionsByUser(ctx context.Context, userID string) ([]*Subscription, error)
+	ListSubscriptionsByTarget(ctx context.Context, targetType SubscriptionTarget, targetID string) ([]*Subscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	DeleteSubscriptionsByTarget(ctx context.Context, targetType SubscriptionTarget, targetID string) error
+
+	// Job Events.
+	CreateJobEvent(ctx context.Context, event *JobEvent) error
+	ListJobEvents(ctx context.Context, jobID string) ([]*JobEvent, error)
+
+	// Job Run Attempts.
+	CreateJobRunAttempt(ctx context.Context, attempt *JobRunAttempt) error
+	ListJobRunAttempts(ctx context.Context, jobID string) ([]*JobRunAttempt, error)
+
+	// Campaigns.
+	CreateCampaign(ctx context.Context, campaign *Campaign) error
+	GetCampaign(ctx context.Context, id string) (*Campaign, error)
+	ListCampaigns(ctx context.Context) ([]*Campaign, error)
+	ListJobsByCampaign(ctx context.Context, campaignID string) ([]*Job, error)
+
+	// Queue Changes.
+	CreateQueueChange(ctx context.Context, change *QueueChange) error
+	ListQueueChanges(ctx context.Context, opts QueueChangeQueryOpts) ([]*QueueChange, int, error)
+
+	// Queue Stats.
+	CreateQueueStatSamples(ctx context.Context, samples []*QueueStatSample) error
+	ListQueueStatSamples(ctx context.Context, groupID string, since time.Time) ([]*QueueStatSample, error)
+	DeleteQueueStatSamplesBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// Settings.
+	ListSettings(ctx context.Context) ([]*Setting, error)
+	UpsertSetting(ctx context.Context, setting *Setting) error
+	DeleteSetting(ctx context.Context, key string) error
+
 	// Audit.
 	CreateAuditEntry(ctx context.Context, entry *AuditEntry) error
 	ListAuditEntries(ctx context.Context, opts AuditQueryOpts) ([]*AuditEntry, int, error)
 
+	// Workflow locks (shared by every process using the database).
+	// AcquireWorkflowLock blocks until the lock for key is held or ctx is done,
+	// and returns a function that releases it.
+	AcquireWorkflowLock(ctx context.Context, key string) (func(), error)
+
+	// Maintenance. Every statement is attempted; failures are recorded on
+	// their result and joined into the returned error.
+	RunMaintenance(ctx context.Context, opts MaintenanceOpts) ([]*MaintenanceResult, error)
+	// DatabaseStats reports the size of the database and of each table.
+	DatabaseStats(ctx context.Context) (*DatabaseStats, error)
+
 	// Migrations.
 	Migrate(ctx context.Context) error
 }
 
+// MaintenanceOpts selects the database maintenance done by RunMaintenance.
+type MaintenanceOpts struct {
+	Vacuum  bool // reclaim the space left by deleted rows
+	Analyze bool // refresh query planner statistics
+}
+
+// MaintenanceResult is the outcome of one maintenance statement.
+type MaintenanceResult struct {
+	Task     string        `json:"task"` // the statement, e.g. "VACUUM ANALYZE jobs"
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// DatabaseStats describes the size of the database and of its tables.
+type DatabaseStats struct {
+	Driver string `json:"driver"`
+	// SizeBytes is the size of the whole database, and FreeBytes the part of
+	// it that is allocated but unused (SQLite only; reclaimed by VACUUM).
+	SizeBytes int64         `json:"size_bytes"`
+	FreeBytes *int64        `json:"free_bytes,omitempty"`
+	Tables    []*TableStats `json:"tables"`
+}
+
+// TableStats is the row count and on-disk size of one table.
+type TableStats struct {
+	Table string `json:"table"`
+	// Rows is exact for SQLite and the planner's live row estimate for
+	// PostgreSQL.
+	Rows int64 `json:"rows"`
+	// SizeBytes includes indexes and, for PostgreSQL, TOAST data. It is left
+	// out when SQLite is built without the dbstat table.
+	SizeBytes *int64 `json:"size_bytes,omitempty"`
+}
+
 // Group represents a runner pool.
 type Group struct {
-	ID           string    `json:"id"`
-	Name         string    `json:"name"`
-	Description  string    `json:"description"`
-	RunnerLabels []string  `json:"runner_labels"`
-	Enabled      bool      `json:"enabled"`
-	Paused       bool      `json:"paused"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	RunnerLabels []string   `json:"runner_labels"`
+	Enabled      bool       `json:"enabled"`
+	Paused       bool       `json:"paused"`
+	PausedReason string     `json:"paused_reason,omitempty"` // set when paused automatically
+	ResumedAt    *time.Time `json:"resumed_at,omitempty"`    // last manual unpause
+	Archived     bool       `json:"archived"`                // not dispatched or listed, history kept
+	ArchivedAt   *time.Time `json:"archived_at,omitempty"`
+	// SchedulingPolicy orders the group's pending jobs for dispatch (empty = fifo).
+	SchedulingPolicy string `json:"scheduling_policy,omitempty"`
+	// MaxPriority caps the priority of the group's jobs (nil = no cap).
+	MaxPriority *int `json:"max_priority,omitempty"`
+	// MaxRequeueLimit caps the requeue limit of auto-requeue jobs; with a cap
+	// set, no job requeues forever (nil = no cap).
+	MaxRequeueLimit *int `json:"max_requeue_limit,omitempty"`
+	// MinOnlineRunners is the number of online matching runners below which
+	// dispatch is held (0 = no minimum).
+	MinOnlineRunners int `json:"min_online_runners"`
+	// Metadata is context shown on the group's queue page (nil = none).
+	Metadata  *GroupMetadata `json:"metadata,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// GroupMetadata is freeform context about a group: who owns it, where to ask
+// about it and where its runbooks live.
+type GroupMetadata struct {
+	Team        string      `json:"team,omitempty" example:"platform"`
+	ChatChannel string      `json:"chat_channel,omitempty" example:"#sync-tests"`
+	Links       []GroupLink `json:"links,omitempty"`
+	// Fields are any other key/value pairs worth showing.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// GroupLink is a titled link about a group, e.g. to a runbook or dashboard.
+type GroupLink struct {
+	Title string `json:"title" example:"Runbook"`
+	URL   string `json:"url" example:"https://wiki.example.com/sync-tests"`
 }
 
 // JobTemplate represents a workflow dispatch job configuration.
@@ -112,13 +263,101 @@ type JobTemplate struct {
 	Repo          string            `json:"repo"`
 	WorkflowID    string            `json:"workflow_id"`
 	Ref           string            `json:"ref"`
-	DefaultInputs map[string]string `json:"default_inputs"`
+	DefaultInputs input.Map         `json:"default_inputs"`
 	Labels        map[string]string `json:"labels"`
 	InConfig      bool              `json:"in_config"`
-	SourceType    string            `json:"source_type"` // "inline", "file", or "url"
+	SourceType    string            `json:"source_type"` // "inline", "file", "url", "git" or "api"
 	SourcePath    string            `json:"source_path"` // filename or URL (empty for inline)
-	CreatedAt     time.Time         `json:"created_at"`
-	UpdatedAt     time.Time         `json:"updated_at"`
+	Deprecated    bool              `json:"deprecated"`
+	SunsetAt      *time.Time        `json:"sunset_at,omitempty"`   // enqueues are rejected after this time
+	Environment   string            `json:"environment,omitempty"` // GitHub deployment environment used by the workflow
+	Category      string            `json:"category,omitempty"`    // optional UI grouping
+	DisplayOrder  int               `json:"display_order"`         // sort key within the group, then by name
+	// DispatchWindows restricts when the template's jobs may be dispatched.
+	DispatchWindows []schedule.Window `json:"dispatch_windows,omitempty"`
+	// PinnedInputs are default input keys that jobs may not override.
+	PinnedInputs []string `json:"pinned_inputs,omitempty"`
+	// MinIdleRunners is the number of idle matching runners required before the
+	// template's jobs are dispatched (0 or 1 = any idle runner).
+	MinIdleRunners int `json:"min_idle_runners"`
+	// DefaultPriority is the priority of jobs enqueued from the template.
+	DefaultPriority int `json:"default_priority"`
+	// KeepRunning is the number of the template's jobs the dispatcher keeps
+	// pending, triggered or running, enqueuing a new job as each finishes
+	// (0 = disabled).
+	KeepRunning int `json:"keep_running"`
+	// StallTimeoutSeconds flags the template's running jobs as stalled when
+	// neither a heartbeat nor their GitHub run changed for this long
+	// (0 = disabled).
+	StallTimeoutSeconds int `json:"stall_timeout_seconds"`
+	// MaxDurationSeconds cancels the template's running jobs and fails them
+	// with a timeout once they have run for this long since being dispatched
+	// (0 = no limit).
+	MaxDurationSeconds int `json:"max_duration_seconds"`
+	// CanaryPercent of the template's jobs are dispatched with
+	// CanaryWorkflowID and CanaryRef in place of WorkflowID and Ref, where
+	// set (0 = no canary).
+	CanaryPercent    int    `json:"canary_percent"`
+	CanaryWorkflowID string `json:"canary_workflow_id,omitempty"`
+	CanaryRef        string `json:"canary_ref,omitempty"`
+	// SecretHandoffInputs are written to short-lived GitHub Actions secrets at
+	// dispatch, and the workflow receives the secrets' names in their place.
+	// With SecretHandoffEnvironment the secrets belong to Environment rather
+	// than the repository.
+	SecretHandoffInputs      []string `json:"secret_handoff_inputs,omitempty"`
+	SecretHandoffEnvironment bool     `json:"secret_handoff_environment,omitempty"`
+	// Overrides are fields of a template from config changed through the API;
+	// config syncs apply them over the config (nil = none).
+	Overrides *TemplateOverrides `json:"overrides,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// TemplateSourceAPI is the source type of templates created through the API,
+// e.g. by cloning. Config syncs neither update nor remove them.
+const TemplateSourceAPI = "api"
+
+// TemplateOverrides are API changes to a template from config, kept across
+// config syncs until they are reset.
+type TemplateOverrides struct {
+	Owner     string    `json:"owner,omitempty" example:"ethpandaops"`
+	Repo      string    `json:"repo,omitempty" example:"syncoor-tests"`
+	Ref       string    `json:"ref,omitempty" example:"main"`
+	UpdatedBy string    `json:"updated_by" example:"alice"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsSunset returns true if the template is deprecated and its sunset date has passed.
+func (t *JobTemplate) IsSunset(now time.Time) bool {
+	return t.Deprecated && t.SunsetAt != nil && !now.Before(*t.SunsetAt)
+}
+
+// LintSeverity represents how serious a template lint issue is.
+type LintSeverity string
+
+const (
+	// LintSeverityError means dispatching the template will fail.
+	LintSeverityError LintSeverity = "error"
+	// LintSeverityWarning means the template has drifted but still dispatches.
+	LintSeverityWarning LintSeverity = "warning"
+)
+
+// LintIssue is a single problem found when linting a template against its workflow.
+type LintIssue struct {
+	Severity LintSeverity `json:"severity"`
+	Code     string       `json:"code"`
+	Input    string       `json:"input,omitempty"`
+	Message  string       `json:"message"`
+}
+
+// TemplateLint is the latest lint result for a template.
+type TemplateLint struct {
+	TemplateID   string       `json:"template_id"`
+	Ref          string       `json:"ref"`
+	WorkflowPath string       `json:"workflow_path,omitempty"`
+	OK           bool         `json:"ok"`
+	Issues       []*LintIssue `json:"issues"`
+	CheckedAt    time.Time    `json:"checked_at"`
 }
 
 // JobStatus represents the state of a job.
@@ -133,29 +372,37 @@ const (
 	JobStatusCancelled JobStatus = "cancelled"
 )
 
+// JobVariant is which of a canary template's workflows a job was dispatched to.
+type JobVariant string
+
+const (
+	JobVariantStable JobVariant = "stable"
+	JobVariantCanary JobVariant = "canary"
+)
+
 // Job represents a queued or executed workflow dispatch.
 type Job struct {
-	ID           string            `json:"id"`
-	GroupID      string            `json:"group_id"`
-	TemplateID   string            `json:"template_id"`
-	Priority     int               `json:"priority"`
-	Position     int               `json:"position"`
-	Status       JobStatus         `json:"status"`
-	Paused       bool              `json:"paused"`
-	AutoRequeue  bool              `json:"auto_requeue"`
-	RequeueLimit *int              `json:"requeue_limit"`
-	RequeueCount int               `json:"requeue_count"`
-	Inputs       map[string]string `json:"inputs"`
-	CreatedBy    string            `json:"created_by"`
-	TriggeredAt  *time.Time        `json:"triggered_at"`
-	RunID        *int64            `json:"run_id"`
-	RunURL       string            `json:"run_url"`
-	RunnerID     *int64            `json:"runner_id"`
-	RunnerName   string            `json:"runner_name"`
-	CompletedAt  *time.Time        `json:"completed_at"`
-	ErrorMessage string            `json:"error_message"`
-	CreatedAt    time.Time         `json:"created_at"`
-	UpdatedAt    time.Time         `json:"updated_at"`
+	ID           string     `json:"id"`
+	GroupID      string     `json:"group_id"`
+	TemplateID   string     `json:"template_id"`
+	Priority     int        `json:"priority"`
+	Position     int        `json:"position"`
+	Status       JobStatus  `json:"status"`
+	Paused       bool       `json:"paused"`
+	AutoRequeue  bool       `json:"auto_requeue"`
+	RequeueLimit *int       `json:"requeue_limit"`
+	RequeueCount int        `json:"requeue_count"`
+	Inputs       input.Map  `json:"inputs"`
+	CreatedBy    string     `json:"created_by"`
+	TriggeredAt  *time.Time `json:"triggered_at"`
+	RunID        *int64     `json:"run_id"`
+	RunURL       string     `json:"run_url"`
+	RunnerID     *int64     `json:"runner_id"`
+	RunnerName   string     `json:"runner_name"`
+	CompletedAt  *time.Time `json:"completed_at"`
+	ErrorMessage string     `json:"error_message"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
 
 	// Override fields (nil/empty means use template value).
 	Name       *string           `json:"name,omitempty"`
@@ -164,6 +411,143 @@ type Job struct {
 	WorkflowID *string           `json:"workflow_id,omitempty"`
 	Ref        *string           `json:"ref,omitempty"`
 	Labels     map[string]string `json:"labels,omitempty"`
+
+	// Outputs holds structured results reported by the workflow run, captured
+	// when the job completes.
+	Outputs map[string]string `json:"outputs,omitempty"`
+
+	// RequeuedFrom is the ID of the job this job was manually requeued from.
+	RequeuedFrom *string `json:"requeued_from,omitempty"`
+
+	// ResolvedSHA is the commit the job's ref pointed to when it was dispatched.
+	ResolvedSHA string `json:"resolved_sha,omitempty"`
+
+	// OriginalCreatedBy is who enqueued the job, set the first time CreatedBy
+	// is reassigned. Empty means the job was never reassigned.
+	OriginalCreatedBy string `json:"original_created_by,omitempty"`
+
+	// Annotations are the failure annotations reported by the job's workflow
+	// run, served separately by the annotations endpoint.
+	Annotations []JobAnnotation `json:"-"`
+
+	// CampaignID is the campaign the job belongs to, if any.
+	CampaignID string `json:"campaign_id,omitempty"`
+
+	// MaxDurationSeconds overrides the template's MaxDurationSeconds for this
+	// job, and is the only limit for manual jobs. It is set when the job is
+	// created.
+	MaxDurationSeconds *int `json:"max_duration_seconds,omitempty"`
+
+	// Progress is the latest progress reported by the job's workflow run. It is
+	// written by UpdateJobProgress only, never by UpdateJob.
+	Progress *JobProgress `json:"progress,omitempty"`
+
+	// HeartbeatAt is when the job's workflow last sent a heartbeat or progress
+	// report. StalledAt is set while a running job is flagged as stalled. Both
+	// are written by their own store methods, never by UpdateJob.
+	HeartbeatAt *time.Time `json:"heartbeat_at,omitempty"`
+	StalledAt   *time.Time `json:"stalled_at,omitempty"`
+
+	// RunAttempt is the attempt of the workflow run last seen, increasing when
+	// the run is re-run from GitHub. LogsURL and ArtifactsURL are the API URLs
+	// of that attempt's logs archive and artifacts. All three are written by
+	// UpdateJobRunAttempt only, never by UpdateJob.
+	RunAttempt   int    `json:"run_attempt,omitempty"`
+	LogsURL      string `json:"logs_url,omitempty"`
+	ArtifactsURL string `json:"artifacts_url,omitempty"`
+
+	// Retained jobs are never deleted by history retention or the per-group
+	// history cap. It is written by SetJobRetained only, never by UpdateJob.
+	Retained bool `json:"retained"`
+
+	// Assignee is the user investigating a failed job, and AssignedAt when
+	// they took it. They are written by SetJobAssignee only, never by
+	// UpdateJob.
+	Assignee   string     `json:"assignee,omitempty"`
+	AssignedAt *time.Time `json:"assigned_at,omitempty"`
+
+	// Variant is the JobVariant a job of a canary template was dispatched as,
+	// picked on its first dispatch. It is written by SetJobVariant only, never
+	// by UpdateJob.
+	Variant JobVariant `json:"variant,omitempty"`
+
+	// HandoffSecrets are the secrets created to pass the job's secret inputs,
+	// kept until they are deleted after the job finishes. It is written by
+	// SetJobHandoffSecrets only, never by UpdateJob.
+	HandoffSecrets *HandoffSecrets `json:"handoff_secrets,omitempty"`
+
+	// NotifyTargets are notified of the job's transitions in addition to its
+	// subscribers. They are set when the job is created and by
+	// SetJobNotifyTargets, never by UpdateJob.
+	NotifyTargets []JobNotifyTarget `json:"notify_targets,omitempty"`
+
+	// NotBefore is the earliest time the job may be dispatched. Until then it
+	// keeps its place in the queue while later jobs are dispatched past it.
+	NotBefore *time.Time `json:"not_before,omitempty"`
+
+	// SourceIssue is the GitHub issue the job was requested in, as
+	// owner/repo#number. The issue is closed once the job finishes.
+	SourceIssue string `json:"source_issue,omitempty"`
+
+	// QueuePosition (1-based) and AheadCount are computed for unpaused pending
+	// jobs when they are served by the API; they are not stored.
+	QueuePosition *int `json:"queue_position,omitempty"`
+	AheadCount    *int `json:"ahead_count,omitempty"`
+
+	// InputsOverridden are the inputs that differ from the current defaults of
+	// the job's template, computed when jobs are served by the API; they are
+	// not stored.
+	InputsOverridden map[string]InputOverride `json:"inputs_overridden,omitempty"`
+}
+
+// Due returns true if the job's not_before, if any, has passed.
+func (j *Job) Due(now time.Time) bool {
+	return j.NotBefore == nil || !now.Before(*j.NotBefore)
+}
+
+// InputOverride is a job input that differs from its template default.
+// Default is nil for an input the template does not declare.
+type InputOverride struct {
+	Value   input.Value  `json:"value" swaggertype:"string" example:"nethermind"`
+	Default *input.Value `json:"default" swaggertype:"string" example:"geth"`
+}
+
+// JobNotifyTarget is where one job's transitions are delivered, through the
+// same channels as subscriptions.
+type JobNotifyTarget struct {
+	Channel SubscriptionChannel `json:"channel"`
+	// Destination is an email address for email, or a URL for slack and webhook.
+	Destination string      `json:"destination"`
+	Statuses    []JobStatus `json:"statuses,omitempty"` // empty = every transition
+}
+
+// HandoffSecrets are GitHub Actions secrets holding a job's secret inputs.
+// Environment is empty for repository secrets.
+type HandoffSecrets struct {
+	Owner       string   `json:"owner"`
+	Repo        string   `json:"repo"`
+	Environment string   `json:"environment,omitempty"`
+	Names       []string `json:"names"`
+}
+
+// JobProgress is a progress report from a running job's workflow.
+type JobProgress struct {
+	// Percent is the completion percentage (0-100), if the workflow knows it.
+	Percent   *int      `json:"percent,omitempty"`
+	Stage     string    `json:"stage,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// JobAnnotation is a failure annotation, such as a failed step or a compiler
+// error, reported by one of the workflow jobs of a job's run.
+type JobAnnotation struct {
+	WorkflowJob string `json:"workflow_job"`
+	Path        string `json:"path"`
+	StartLine   int    `json:"start_line"`
+	EndLine     int    `json:"end_line"`
+	Title       string `json:"title,omitempty"`
+	Message     string `json:"message"`
 }
 
 // RunnerStatus represents the status of a GitHub Actions runner.
@@ -176,15 +560,19 @@ const (
 
 // Runner represents a GitHub Actions runner.
 type Runner struct {
-	ID         int64        `json:"id"`
-	Name       string       `json:"name"`
-	Labels     []string     `json:"labels"`
-	Status     RunnerStatus `json:"status"`
-	Busy       bool         `json:"busy"`
-	OS         string       `json:"os"`
-	LastSeenAt time.Time    `json:"last_seen_at"`
-	CreatedAt  time.Time    `json:"created_at"`
-	UpdatedAt  time.Time    `json:"updated_at"`
+	ID     int64        `json:"id"`
+	Name   string       `json:"name"`
+	Labels []string     `json:"labels"`
+	Status RunnerStatus `json:"status"`
+	Busy   bool         `json:"busy"`
+	OS     string       `json:"os"`
+	// Hosted marks a GitHub-hosted larger runner. It is a pool that runs up
+	// to MaxRunners jobs at once, and is busy once that many are in flight.
+	Hosted     bool      `json:"hosted"`
+	MaxRunners int       `json:"max_runners,omitempty"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
 }
 
 // AuthProvider represents the authentication provider for a user.
@@ -215,6 +603,59 @@ type User struct {
 	UpdatedAt    time.Time    `json:"updated_at"`
 }
 
+// SavedFilterView is the page a saved filter applies to.
+type SavedFilterView string
+
+const (
+	SavedFilterViewQueue   SavedFilterView = "queue"
+	SavedFilterViewHistory SavedFilterView = "history"
+)
+
+// SavedFilter is a named queue or history filter saved by a user.
+type SavedFilter struct {
+	ID         string            `json:"id"`
+	UserID     string            `json:"user_id"`
+	Name       string            `json:"name"`
+	View       SavedFilterView   `json:"view"`
+	GroupID    string            `json:"group_id,omitempty"`    // empty = any group
+	Statuses   []JobStatus       `json:"statuses"`              // empty = all statuses of the view
+	Labels     map[string]string `json:"labels"`                // template labels (AND logic)
+	TemplateID string            `json:"template_id,omitempty"` // empty = all templates
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// SubscriptionTarget is what a subscription follows.
+type SubscriptionTarget string
+
+const (
+	SubscriptionTargetJob      SubscriptionTarget = "job"
+	SubscriptionTargetTemplate SubscriptionTarget = "template"
+)
+
+// SubscriptionChannel is how a subscription's notifications are delivered.
+type SubscriptionChannel string
+
+const (
+	SubscriptionChannelEmail   SubscriptionChannel = "email"
+	SubscriptionChannelSlack   SubscriptionChannel = "slack"
+	SubscriptionChannelWebhook SubscriptionChannel = "webhook"
+)
+
+// Subscription notifies a user of the state transitions of a job, or of every
+// job of a template.
+type Subscription struct {
+	ID         string              `json:"id"`
+	UserID     string              `json:"user_id"`
+	TargetType SubscriptionTarget  `json:"target_type"`
+	TargetID   string              `json:"target_id"`
+	Channel    SubscriptionChannel `json:"channel"`
+	// Destination is an email address for email, or a URL for slack and webhook.
+	Destination string      `json:"destination"`
+	Statuses    []JobStatus `json:"statuses"` // empty = every transition
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
 // Session represents an active user session.
 type Session struct {
 	ID        string    `json:"id"`
@@ -222,6 +663,11 @@ type Session struct {
 	TokenHash string    `json:"-"`
 	ExpiresAt time.Time `json:"expires_at"`
 	CreatedAt time.Time `json:"created_at"`
+	// IPAddress and UserAgent are those of the most recent request made with
+	// the session.
+	IPAddress  string     `json:"ip_address,omitempty"`
+	UserAgent  string     `json:"user_agent,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
 }
 
 // OAuthState represents a CSRF state token for OAuth flows.
@@ -243,29 +689,208 @@ type AuthCode struct {
 type AuditAction string
 
 const (
-	AuditActionJobCreated   AuditAction = "job_created"
-	AuditActionJobTriggered AuditAction = "job_triggered"
-	AuditActionJobCompleted AuditAction = "job_completed"
-	AuditActionJobFailed    AuditAction = "job_failed"
-	AuditActionJobCancelled AuditAction = "job_cancelled"
-	AuditActionJobReordered AuditAction = "job_reordered"
-	AuditActionUserLogin    AuditAction = "user_login"
-	AuditActionUserLogout   AuditAction = "user_logout"
-	AuditActionConfigReload AuditAction = "config_reload"
+	AuditActionJobCreated         AuditAction = "job_created"
+	AuditActionJobTriggered       AuditAction = "job_triggered"
+	AuditActionJobCompleted       AuditAction = "job_completed"
+	AuditActionJobFailed          AuditAction = "job_failed"
+	AuditActionJobCancelled       AuditAction = "job_cancelled"
+	AuditActionJobReordered       AuditAction = "job_reordered"
+	AuditActionJobReassigned      AuditAction = "job_reassigned"
+	AuditActionJobRestored        AuditAction = "job_restored"
+	AuditActionJobStalled         AuditAction = "job_stalled"
+	AuditActionJobRetained        AuditAction = "job_retained"
+	AuditActionJobUnretained      AuditAction = "job_unretained"
+	AuditActionJobAssigned        AuditAction = "job_assigned"
+	AuditActionJobUnassigned      AuditAction = "job_unassigned"
+	AuditActionUserLogin          AuditAction = "user_login"
+	AuditActionUserLogout         AuditAction = "user_logout"
+	AuditActionConfigReload       AuditAction = "config_reload"
+	AuditActionGroupAutoPaused    AuditAction = "group_auto_paused"
+	AuditActionGroupStarved       AuditAction = "group_starved"
+	AuditActionGroupArchived      AuditAction = "group_archived"
+	AuditActionGroupUnarchived    AuditAction = "group_unarchived"
+	AuditActionCampaignCreated    AuditAction = "campaign_created"
+	AuditActionCampaignPaused     AuditAction = "campaign_paused"
+	AuditActionCampaignUnpaused   AuditAction = "campaign_unpaused"
+	AuditActionCampaignCancelled  AuditAction = "campaign_cancelled"
+	AuditActionConfigSyncStaged   AuditAction = "config_sync_staged"
+	AuditActionConfigSyncApproved AuditAction = "config_sync_approved"
+	AuditActionConfigSyncRejected AuditAction = "config_sync_rejected"
+	AuditActionUserImpersonated   AuditAction = "user_impersonated"
+	AuditActionSettingsUpdated    AuditAction = "settings_updated"
+	AuditActionTemplateCloned     AuditAction = "template_cloned"
+	AuditActionTemplateEdited     AuditAction = "template_edited"
+	AuditActionTemplateDeleted    AuditAction = "template_deleted"
 )
 
 // AuditEntityType represents the type of entity being audited.
 type AuditEntityType string
 
 const (
-	AuditEntityJob     AuditEntityType = "job"
-	AuditEntityGroup   AuditEntityType = "group"
-	AuditEntityRunner  AuditEntityType = "runner"
-	AuditEntityUser    AuditEntityType = "user"
-	AuditEntitySession AuditEntityType = "session"
-	AuditEntitySystem  AuditEntityType = "system"
+	AuditEntityJob      AuditEntityType = "job"
+	AuditEntityGroup    AuditEntityType = "group"
+	AuditEntityRunner   AuditEntityType = "runner"
+	AuditEntityUser     AuditEntityType = "user"
+	AuditEntitySession  AuditEntityType = "session"
+	AuditEntitySystem   AuditEntityType = "system"
+	AuditEntityCampaign AuditEntityType = "campaign"
+	AuditEntityTemplate AuditEntityType = "template"
 )
 
+// JobEvent records a status transition of a job.
+type JobEvent struct {
+	ID    string `json:"id"`
+	JobID string `json:"job_id"`
+	// FromStatus is empty for the event that created the job.
+	FromStatus JobStatus `json:"from_status,omitempty"`
+	ToStatus   JobStatus `json:"to_status"`
+	// Actor is the user behind the transition, or "system" for the dispatcher and tracker.
+	Actor      string    `json:"actor"`
+	RunID      *int64    `json:"run_id,omitempty"`
+	RunnerName string    `json:"runner_name,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// JobRunAttempt is a finished attempt of a job's workflow run, kept when the
+// run is re-run from GitHub and the job is reopened for the next attempt.
+type JobRunAttempt struct {
+	JobID   string `json:"job_id"`
+	Attempt int    `json:"attempt"`
+	// Status is how the attempt ended: failed or cancelled.
+	Status       JobStatus  `json:"status"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	RunnerName   string     `json:"runner_name,omitempty"`
+	LogsURL      string     `json:"logs_url,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at"`
+}
+
+// Campaign is a named set of jobs, possibly across groups, that are tracked
+// and controlled together.
+type Campaign struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Metadata    map[string]string `json:"metadata"`
+	CreatedBy   string            `json:"created_by"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// QueueChangeOperation is a manual change to a group's queue.
+type QueueChangeOperation string
+
+// Queue change operations.
+const (
+	QueueChangeReorder  QueueChangeOperation = "reorder"
+	QueueChangePause    QueueChangeOperation = "pause"
+	QueueChangeUnpause  QueueChangeOperation = "unpause"
+	QueueChangePriority QueueChangeOperation = "priority"
+	QueueChangeDelete   QueueChangeOperation = "delete"
+)
+
+// QueueChange records who changed a group's queue and the queue state of the
+// affected jobs before and after the change.
+type QueueChange struct {
+	ID      string `json:"id"`
+	GroupID string `json:"group_id"`
+	// JobID is the job changed, empty for reorders which affect the whole queue.
+	JobID     string               `json:"job_id,omitempty"`
+	Operation QueueChangeOperation `json:"operation"`
+	Actor     string               `json:"actor"`
+	// Before and After hold the affected jobs in dispatch order. After is empty
+	// for deletes.
+	Before    []QueueSnapshotEntry `json:"before"`
+	After     []QueueSnapshotEntry `json:"after"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+// QueueSnapshotEntry is the queue state of one job in a QueueChange.
+type QueueSnapshotEntry struct {
+	JobID    string `json:"job_id"`
+	Position int    `json:"position"`
+	Priority int    `json:"priority"`
+	Paused   bool   `json:"paused"`
+}
+
+// PendingQueryOpts contains options for paging through a group's pending jobs
+// in dispatch order (priority descending, then position).
+type PendingQueryOpts struct {
+	GroupID string
+	Limit   int
+	After   *PendingCursor // cursor: fetch jobs after this one in dispatch order
+}
+
+// PendingCursor is the place of a pending job in dispatch order. Unlike an
+// offset it stays valid while jobs ahead of it are dispatched.
+type PendingCursor struct {
+	Priority int
+	Position int
+	ID       string // tie-breaker for jobs sharing a priority and position
+}
+
+// PendingResult contains a page of pending jobs.
+type PendingResult struct {
+	Jobs    []*Job
+	HasMore bool
+	// Ahead is the number of unpaused pending jobs before the page.
+	Ahead int
+}
+
+// QueueCount is the number of a group's active jobs of one template, status
+// and paused state.
+type QueueCount struct {
+	TemplateID string // empty for manual jobs
+	Status     JobStatus
+	Paused     bool
+	Count      int
+}
+
+// GroupJobCounts holds aggregate job counts of one group.
+type GroupJobCounts struct {
+	GroupID   string
+	Pending   int // pending jobs, paused or not
+	Triggered int
+	Running   int
+	// OldestPending is the group's oldest unpaused pending job that is due,
+	// nil when nothing is waiting.
+	OldestPending *Job
+	// Completed, Failed and Cancelled count the jobs finished since the time
+	// given to GetGroupJobCounts.
+	Completed int
+	Failed    int
+	Cancelled int
+}
+
+// QueueStatSample is a group's queue depth and idle runners at one moment,
+// sampled periodically to graph backlog trends.
+type QueueStatSample struct {
+	GroupID   string
+	SampledAt time.Time
+	// Pending counts pending jobs, paused or not.
+	Pending int
+	// Running counts triggered and running jobs.
+	Running     int
+	IdleRunners int
+}
+
+// QueueChangeQueryOpts contains options for querying queue changes.
+type QueueChangeQueryOpts struct {
+	GroupID string
+	JobID   string // matches changes to this job, including reorders that moved it
+	Limit   int
+	Offset  int
+}
+
+// Setting is a runtime override of a configured value, e.g. the dispatch
+// interval. Value holds it in its text form, such as "10s".
+type Setting struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	UpdatedBy string    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 // AuditEntry represents an audit log entry.
 type AuditEntry struct {
 	ID         string          `json:"id"`
@@ -291,29 +916,53 @@ type AuditQueryOpts struct {
 
 // HistoryQueryOpts contains options for querying job history.
 type HistoryQueryOpts struct {
-	GroupID  string
-	Limit    int
-	Before   *time.Time        // cursor: fetch jobs completed before this time
-	Statuses []JobStatus       // filter by status (multi-select, empty = all history statuses)
-	Labels   map[string]string // filter by template labels (AND logic)
+	GroupID    string
+	Limit      int
+	Before     *time.Time        // cursor: fetch jobs completed before this time
+	BeforeID   string            // cursor tie-breaker: with Before, also fetch jobs completed at Before with a lower ID
+	Offset     int               // skip this many jobs (alternative to cursor pagination)
+	Statuses   []JobStatus       // filter by status (multi-select, empty = all history statuses)
+	Labels     map[string]string // filter by template labels (AND logic)
+	TemplateID string            // filter by template (empty = all templates)
+	// RetainedOnly limits the history to retained jobs.
+	RetainedOnly bool
+	// Assignee limits the history to jobs assigned to this user.
+	Assignee string
+
+	// SkipCount skips the COUNT(*) query; TotalCount is left at zero.
+	SkipCount bool
 }
 
 // HistoryResult contains paginated history results.
 type HistoryResult struct {
-	Jobs       []*Job
-	HasMore    bool
-	NextCursor *time.Time // completed_at of the last job
-	TotalCount int
+	Jobs         []*Job
+	HasMore      bool
+	NextCursor   *time.Time // completed_at of the last job
+	NextCursorID string     // ID of the last job
+	TotalCount   int
+	CountCached  bool // TotalCount was served from a cache and may be slightly stale
 }
 
 // HistoryStatsOpts contains options for querying history statistics.
 type HistoryStatsOpts struct {
-	GroupID string
-	Start   time.Time
-	End     time.Time
-	Buckets int // number of time buckets to return
+	GroupID  string
+	Start    time.Time
+	End      time.Time
+	Buckets  int                   // number of time buckets to return
+	GroupBy  HistoryStatsDimension // optional; adds one series per dimension value
+	LabelKey string                // label key to group by when GroupBy is HistoryStatsByLabel
 }
 
+// HistoryStatsDimension is an optional dimension to split history statistics by.
+type HistoryStatsDimension string
+
+const (
+	HistoryStatsByTemplate  HistoryStatsDimension = "template_id"
+	HistoryStatsByLabel     HistoryStatsDimension = "label"
+	HistoryStatsByCreatedBy HistoryStatsDimension = "created_by"
+	HistoryStatsByVariant   HistoryStatsDimension = "variant"
+)
+
 // HistoryStatsBucket contains aggregated job counts for a time bucket.
 type HistoryStatsBucket struct {
 	Timestamp time.Time `json:"timestamp"`
@@ -336,9 +985,17 @@ type HistoryStatsTotals struct {
 	Cancelled int `json:"cancelled"`
 }
 
+// HistoryStatsSeries contains aggregated job counts for a single dimension value.
+type HistoryStatsSeries struct {
+	Key     string                `json:"key"` // dimension value; empty when unset (e.g. manual jobs)
+	Buckets []*HistoryStatsBucket `json:"buckets"`
+	Totals  HistoryStatsTotals    `json:"totals"`
+}
+
 // HistoryStatsResult contains aggregated history statistics.
 type HistoryStatsResult struct {
 	Buckets []*HistoryStatsBucket `json:"buckets"`
 	Range   HistoryStatsRange     `json:"range"`
 	Totals  HistoryStatsTotals    `json:"totals"`
+	Series  []*HistoryStatsSeries `json:"series,omitempty"`
 }
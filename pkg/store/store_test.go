@@ -0,0 +1,447 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// insertPattern matches an INSERT statement up to the opening of its value
+// list.
+var insertPattern = regexp.MustCompile(`INSERT INTO (\w+)\s*\(([^)]*)\)\s*VALUES\s*\(`)
+
+// TestInsertPlaceholders checks that every INSERT of both backends binds as
+// many values as it names columns. A mismatch only fails at runtime on the
+// backend that has it, and tests mostly run on SQLite.
+func TestInsertPlaceholders(t *testing.T) {
+	for _, file := range []string{"postgres.go", "sqlite.go"} {
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", file, err)
+		}
+
+		var checked int
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			lit, ok := n.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+
+			sql, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				return true
+			}
+
+			m := insertPattern.FindStringSubmatchIndex(sql)
+			if m == nil {
+				return true
+			}
+
+			// Value lists built at runtime, such as multi-row inserts, are
+			// not written out in the literal.
+			values, ok := closeParen(sql[m[1]:])
+			if !ok || strings.Contains(values, "%") {
+				return true
+			}
+
+			checked++
+
+			table, columns := sql[m[2]:m[3]], len(splitTopLevel(sql[m[4]:m[5]]))
+			if placeholders := len(splitTopLevel(values)); columns != placeholders {
+				t.Errorf("%s: INSERT INTO %s names %d columns but binds %d values", file, table, columns, placeholders)
+			}
+
+			return true
+		})
+
+		if checked == 0 {
+			t.Errorf("%s: found no INSERT statements to check", file)
+		}
+	}
+}
+
+// closeParen returns s up to the parenthesis closing an already open one.
+func closeParen(s string) (string, bool) {
+	depth := 1
+
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return s[:i], true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// splitTopLevel splits s on commas outside parentheses.
+func splitTopLevel(s string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// testStores returns a migrated SQLite store and, when
+// DISPATCHOOR_TEST_POSTGRES_DSN is set, a migrated PostgreSQL store.
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	stores := map[string]Store{
+		"sqlite": NewSQLiteStore(log, filepath.Join(t.TempDir(), "test.db")),
+	}
+
+	if dsn := os.Getenv("DISPATCHOOR_TEST_POSTGRES_DSN"); dsn != "" {
+		stores["postgres"] = NewPostgresStore(log, dsn)
+	}
+
+	for name, st := range stores {
+		if err := st.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start %s store: %v", name, err)
+		}
+
+		t.Cleanup(func() { _ = st.Stop() })
+
+		if err := st.Migrate(context.Background()); err != nil {
+			t.Fatalf("Failed to migrate %s store: %v", name, err)
+		}
+	}
+
+	return stores
+}
+
+// TestJobTemplateRoundTrip creates, reads and updates a template on every
+// available backend. Set DISPATCHOOR_TEST_POSTGRES_DSN to cover PostgreSQL.
+func TestJobTemplateRoundTrip(t *testing.T) {
+	for name, st := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Second)
+			suffix := uuid.NewString()[:8]
+
+			group := &Group{
+				ID: "group-" + suffix, Name: "Group", RunnerLabels: []string{"self-hosted"}, Enabled: true,
+				CreatedAt: now, UpdatedAt: now,
+			}
+			if err := st.CreateGroup(ctx, group); err != nil {
+				t.Fatalf("Failed to create group: %v", err)
+			}
+
+			template := &JobTemplate{
+				ID: "template-" + suffix, GroupID: group.ID, Name: "Template", Owner: "ethpandaops", Repo: "tests",
+				WorkflowID: "test.yml", Ref: "main", DefaultInputs: input.Map{"network": input.String("hoodi")},
+				Labels: map[string]string{"team": "devops"}, InConfig: true, SourceType: "inline",
+				Category: "Load", DisplayOrder: 3, PinnedInputs: []string{"network"}, MinIdleRunners: 2,
+				DefaultPriority: 5, StallTimeoutSeconds: 600, MaxDurationSeconds: 3600,
+				CanaryPercent: 10, CanaryRef: "next", CreatedAt: now, UpdatedAt: now,
+			}
+			if err := st.CreateJobTemplate(ctx, template); err != nil {
+				t.Fatalf("Failed to create template: %v", err)
+			}
+
+			got, err := st.GetJobTemplate(ctx, template.ID)
+			if err != nil || got == nil {
+				t.Fatalf("Failed to get template: %v", err)
+			}
+
+			if got.Category != "Load" || got.DisplayOrder != 3 || len(got.PinnedInputs) != 1 ||
+				got.MinIdleRunners != 2 || got.DefaultPriority != 5 || got.CanaryPercent != 10 ||
+				got.CanaryRef != "next" || got.DefaultInputs["network"].String() != "hoodi" {
+				t.Errorf("Unexpected template %+v", got)
+			}
+
+			got.Category = "Soak"
+			got.DisplayOrder = 1
+
+			if err := st.UpdateJobTemplate(ctx, got); err != nil {
+				t.Fatalf("Failed to update template: %v", err)
+			}
+
+			updated, err := st.GetJobTemplate(ctx, template.ID)
+			if err != nil || updated == nil || updated.Category != "Soak" || updated.DisplayOrder != 1 {
+				t.Errorf("Expected the update to persist, got %+v (%v)", updated, err)
+			}
+		})
+	}
+}
+
+// TestHistoryLabelKeyPaths filters history by label keys that are not plain
+// identifiers, which must match the key itself on every backend.
+func TestHistoryLabelKeyPaths(t *testing.T) {
+	for name, st := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Second)
+			suffix := uuid.NewString()[:8]
+
+			group := &Group{ID: "group-" + suffix, Name: "Group", Enabled: true, CreatedAt: now, UpdatedAt: now}
+			if err := st.CreateGroup(ctx, group); err != nil {
+				t.Fatalf("Failed to create group: %v", err)
+			}
+
+			template := &JobTemplate{
+				ID: "template-" + suffix, GroupID: group.ID, Name: "Template", Owner: "ethpandaops", Repo: "tests",
+				WorkflowID: "test.yml", Ref: "main", SourceType: "inline", CreatedAt: now, UpdatedAt: now,
+				Labels: map[string]string{"team.name": "devops", "team": "ops", `say "hi"`: "yes"},
+			}
+			if err := st.CreateJobTemplate(ctx, template); err != nil {
+				t.Fatalf("Failed to create template: %v", err)
+			}
+
+			job := &Job{
+				ID: "job-" + suffix, GroupID: group.ID, TemplateID: template.ID, Status: JobStatusCompleted,
+				CreatedBy: "alice", CreatedAt: now, UpdatedAt: now, CompletedAt: &now,
+			}
+			if err := st.CreateJob(ctx, job); err != nil {
+				t.Fatalf("Failed to create job: %v", err)
+			}
+
+			for _, tc := range []struct {
+				labels map[string]string
+				want   int
+			}{
+				{map[string]string{"team.name": "devops"}, 1},
+				{map[string]string{"team.name": "ops"}, 0},
+				{map[string]string{`say "hi"`: "yes"}, 1},
+				{map[string]string{"team[0]": "ops"}, 0},
+			} {
+				result, err := st.ListJobHistory(ctx, HistoryQueryOpts{GroupID: group.ID, Limit: 10, Labels: tc.labels})
+				if err != nil {
+					t.Fatalf("Failed to list history for %v: %v", tc.labels, err)
+				}
+
+				if len(result.Jobs) != tc.want || result.TotalCount != tc.want {
+					t.Errorf("Expected %d jobs for %v, got %d (total %d)", tc.want, tc.labels, len(result.Jobs), result.TotalCount)
+				}
+			}
+		})
+	}
+}
+
+// TestWorkflowLock checks that a workflow lock excludes other holders of the
+// same key until released, on every available backend.
+func TestWorkflowLock(t *testing.T) {
+	for name, st := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			key := "ethpandaops/tests/" + uuid.NewString()
+
+			unlock, err := st.AcquireWorkflowLock(context.Background(), key)
+			if err != nil {
+				t.Fatalf("Failed to acquire lock: %v", err)
+			}
+
+			other, err := st.AcquireWorkflowLock(context.Background(), key+"-other")
+			if err != nil {
+				t.Fatalf("Expected another key to be lockable: %v", err)
+			}
+
+			other()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+			defer cancel()
+
+			if _, err := st.AcquireWorkflowLock(ctx, key); err == nil {
+				t.Fatal("Expected a held lock to block another holder")
+			}
+
+			acquired := make(chan func(), 1)
+
+			go func() {
+				next, err := st.AcquireWorkflowLock(context.Background(), key)
+				if err != nil {
+					t.Errorf("Failed to acquire released lock: %v", err)
+				}
+
+				acquired <- next
+			}()
+
+			unlock()
+			unlock()
+
+			select {
+			case next := <-acquired:
+				if next != nil {
+					next()
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Expected the lock to be acquired once released")
+			}
+		})
+	}
+}
+
+// TestSQLiteWorkflowLockRenewal checks that a lock held longer than its lease
+// is renewed rather than taken over.
+func TestSQLiteWorkflowLockRenewal(t *testing.T) {
+	st, ok := testStores(t)["sqlite"].(*SQLiteStore)
+	if !ok {
+		t.Fatal("Expected a SQLite store")
+	}
+
+	st.lockLease = 300 * time.Millisecond
+
+	unlock, err := st.AcquireWorkflowLock(context.Background(), "ethpandaops/tests/renewed")
+	if err != nil {
+		t.Fatalf("Failed to acquire lock: %v", err)
+	}
+
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*st.lockLease)
+	defer cancel()
+
+	if _, err := st.AcquireWorkflowLock(ctx, "ethpandaops/tests/renewed"); err == nil {
+		t.Fatal("Expected a renewed lease to outlast several lease periods")
+	}
+}
+
+// TestPostgresWorkflowLockSlots checks that workflow locks pin at most
+// postgresMaxLockConns connections, so queries still get one. Set
+// DISPATCHOOR_TEST_POSTGRES_DSN to run it.
+func TestPostgresWorkflowLockSlots(t *testing.T) {
+	st, ok := testStores(t)["postgres"].(*PostgresStore)
+	if !ok {
+		t.Skip("DISPATCHOOR_TEST_POSTGRES_DSN is not set")
+	}
+
+	unlocks := make([]func(), 0, postgresMaxLockConns)
+
+	for i := range postgresMaxLockConns {
+		unlock, err := st.AcquireWorkflowLock(context.Background(), fmt.Sprintf("ethpandaops/tests/slot-%d", i))
+		if err != nil {
+			t.Fatalf("Failed to acquire lock %d: %v", i, err)
+		}
+
+		unlocks = append(unlocks, unlock)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	if _, err := st.AcquireWorkflowLock(ctx, "ethpandaops/tests/slot-extra"); err == nil {
+		t.Error("Expected a lock beyond the slot limit to wait")
+	}
+
+	if _, err := st.ListGroups(context.Background()); err != nil {
+		t.Errorf("Expected queries to run while locks hold their slots: %v", err)
+	}
+
+	for _, unlock := range unlocks {
+		unlock()
+	}
+
+	unlock, err := st.AcquireWorkflowLock(context.Background(), "ethpandaops/tests/slot-extra")
+	if err != nil {
+		t.Fatalf("Failed to acquire lock once slots were freed: %v", err)
+	}
+
+	unlock()
+}
+
+// TestSQLiteJobsRebuildKeepsIndexes migrates a database whose jobs table
+// still requires template_id, which rebuilds the table, and checks that the
+// rebuilt table has every index a fresh one does.
+func TestSQLiteJobsRebuildKeepsIndexes(t *testing.T) {
+	st, ok := testStores(t)["sqlite"].(*SQLiteStore)
+	if !ok {
+		t.Fatal("Expected a SQLite store")
+	}
+
+	ctx := context.Background()
+
+	jobIndexes := func() []string {
+		rows, err := st.db.QueryContext(ctx,
+			`SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = 'jobs' AND sql IS NOT NULL ORDER BY name`)
+		if err != nil {
+			t.Fatalf("Failed to list indexes: %v", err)
+		}
+
+		defer rows.Close()
+
+		var names []string
+
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				t.Fatalf("Failed to scan index: %v", err)
+			}
+
+			names = append(names, name)
+		}
+
+		return names
+	}
+
+	want := jobIndexes()
+
+	var schema string
+	if err := st.db.QueryRowContext(ctx, `SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'jobs'`).Scan(&schema); err != nil {
+		t.Fatalf("Failed to read jobs schema: %v", err)
+	}
+
+	legacy := strings.Replace(strings.Replace(schema, "CREATE TABLE jobs", "CREATE TABLE jobs_legacy", 1),
+		"template_id TEXT REFERENCES", "template_id TEXT NOT NULL REFERENCES", 1)
+	if legacy == schema {
+		t.Fatal("Expected the jobs schema to declare a nullable template_id")
+	}
+
+	for _, stmt := range []string{
+		"PRAGMA foreign_keys=OFF",
+		legacy,
+		"DROP TABLE jobs",
+		"ALTER TABLE jobs_legacy RENAME TO jobs",
+		"PRAGMA foreign_keys=ON",
+	} {
+		if _, err := st.db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("Failed to restore the legacy jobs table (%s): %v", stmt, err)
+		}
+	}
+
+	if err := st.migrateJobsTemplateIdNullable(ctx); err != nil {
+		t.Fatalf("Failed to rebuild jobs: %v", err)
+	}
+
+	if got := jobIndexes(); !slices.Equal(got, want) {
+		t.Errorf("Expected rebuilt jobs indexes %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,275 @@
+// Package telemetry periodically pushes anonymized aggregate stats of an
+// instance to a central endpoint, so a platform team running many dispatchoor
+// instances can watch queue depths, throughput and failure rates in one place.
+// Only totals are reported; no group, template, repository or user names
+// leave the instance.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+// pushTimeout bounds a single push to the endpoint.
+const pushTimeout = 10 * time.Second
+
+// Report is the payload pushed to the endpoint.
+type Report struct {
+	InstanceID string    `json:"instance_id"`
+	Version    string    `json:"version"`
+	ReportedAt time.Time `json:"reported_at"`
+	// WindowSeconds is the period the job throughput covers, the export
+	// interval.
+	WindowSeconds int           `json:"window_seconds"`
+	Groups        int           `json:"groups"`
+	Queue         QueueReport   `json:"queue"`
+	Jobs          JobsReport    `json:"jobs"`
+	Runners       RunnersReport `json:"runners"`
+}
+
+// QueueReport is the current queue depth across all groups.
+type QueueReport struct {
+	Pending int `json:"pending"`
+	// Running counts triggered and running jobs.
+	Running int `json:"running"`
+	// OldestPendingSeconds is how long the oldest unpaused pending job has
+	// waited, zero when nothing is waiting.
+	OldestPendingSeconds int `json:"oldest_pending_seconds"`
+}
+
+// JobsReport counts the jobs finished within the window.
+type JobsReport struct {
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	Cancelled int `json:"cancelled"`
+	// FailureRate is failed jobs over completed and failed ones, zero when
+	// none finished.
+	FailureRate float64 `json:"failure_rate"`
+}
+
+// RunnersReport counts the known runners.
+type RunnersReport struct {
+	Total  int `json:"total"`
+	Online int `json:"online"`
+	Busy   int `json:"busy"`
+}
+
+// Service periodically pushes a report to the configured endpoint.
+type Service interface {
+	Start(ctx context.Context) error
+	Stop() error
+}
+
+// service implements Service.
+type service struct {
+	log        logrus.FieldLogger
+	cfg        *config.TelemetryConfig
+	store      store.Store
+	client     *http.Client
+	version    string
+	instanceID string
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+// Ensure service implements Service.
+var _ Service = (*service)(nil)
+
+// NewService creates a new telemetry exporter reporting the given version.
+func NewService(log logrus.FieldLogger, cfg *config.Config, st store.Store, version string) Service {
+	instanceID := cfg.Telemetry.InstanceID
+	if instanceID == "" {
+		instanceID = defaultInstanceID()
+	}
+
+	return &service{
+		log:        log.WithField("component", "telemetry"),
+		cfg:        &cfg.Telemetry,
+		store:      st,
+		client:     &http.Client{Timeout: pushTimeout},
+		version:    version,
+		instanceID: instanceID,
+		done:       make(chan struct{}),
+	}
+}
+
+// defaultInstanceID hashes the hostname, so instances are told apart without
+// revealing their names.
+func defaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	sum := sha256.Sum256([]byte(hostname))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// Start begins the export loop.
+func (s *service) Start(ctx context.Context) error {
+	s.log.WithFields(logrus.Fields{
+		"endpoint":    s.cfg.Endpoint,
+		"interval":    s.cfg.Interval,
+		"instance_id": s.instanceID,
+	}).Info("Starting telemetry exporter")
+
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	go s.run(ctx)
+
+	return nil
+}
+
+// Stop stops the export loop.
+func (s *service) Stop() error {
+	s.log.Info("Stopping telemetry exporter")
+
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+
+	return nil
+}
+
+// run pushes a report every interval.
+func (s *service) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.export(ctx, time.Now()); err != nil && ctx.Err() == nil {
+				s.log.WithError(err).Warn("Failed to export telemetry")
+			}
+		}
+	}
+}
+
+// export builds a report and pushes it.
+func (s *service) export(ctx context.Context, now time.Time) error {
+	report, err := s.report(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	return s.push(ctx, report)
+}
+
+// report aggregates the current stats, counting jobs finished within the
+// last interval.
+func (s *service) report(ctx context.Context, now time.Time) (*Report, error) {
+	groups, err := s.store.ListGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing groups: %w", err)
+	}
+
+	counts, err := s.store.GetGroupJobCounts(ctx, now.Add(-s.cfg.Interval))
+	if err != nil {
+		return nil, fmt.Errorf("counting group jobs: %w", err)
+	}
+
+	runners, err := s.store.ListRunners(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing runners: %w", err)
+	}
+
+	report := &Report{
+		InstanceID:    s.instanceID,
+		Version:       s.version,
+		ReportedAt:    now.UTC().Truncate(time.Second),
+		WindowSeconds: int(s.cfg.Interval.Seconds()),
+	}
+
+	for _, group := range groups {
+		if !group.Archived {
+			report.Groups++
+		}
+	}
+
+	var oldest time.Time
+
+	for _, count := range counts {
+		report.Queue.Pending += count.Pending
+		report.Queue.Running += count.Triggered + count.Running
+		report.Jobs.Completed += count.Completed
+		report.Jobs.Failed += count.Failed
+		report.Jobs.Cancelled += count.Cancelled
+
+		if count.OldestPending != nil && (oldest.IsZero() || count.OldestPending.CreatedAt.Before(oldest)) {
+			oldest = count.OldestPending.CreatedAt
+		}
+	}
+
+	if !oldest.IsZero() {
+		report.Queue.OldestPendingSeconds = int(now.Sub(oldest).Seconds())
+	}
+
+	if finished := report.Jobs.Completed + report.Jobs.Failed; finished > 0 {
+		report.Jobs.FailureRate = float64(report.Jobs.Failed) / float64(finished)
+	}
+
+	for _, runner := range runners {
+		report.Runners.Total++
+
+		if runner.Status == store.RunnerStatusOnline {
+			report.Runners.Online++
+		}
+
+		if runner.Busy {
+			report.Runners.Busy++
+		}
+	}
+
+	return report, nil
+}
+
+// push POSTs a report to the endpoint and expects a 2xx response.
+func (s *service) push(ctx context.Context, report *Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshaling report: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.Endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "dispatchoor/"+s.version)
+
+	if s.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending report: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	st := store.NewSQLiteStore(log, filepath.Join(t.TempDir(), "test.db"))
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+	defer func() { _ = st.Stop() }()
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	// Names that must not leave the instance.
+	secrets := []string{"secret-group", "Secret Group", "secret-template", "secret-repo", "secret-user", "secret-runner"}
+
+	for _, group := range []*store.Group{
+		{ID: "secret-group", Name: "Secret Group", RunnerLabels: []string{"self-hosted"}, Enabled: true},
+		{ID: "archived-group", Name: "Archived", Enabled: true, Archived: true},
+	} {
+		group.CreatedAt, group.UpdatedAt = now, now
+
+		if err := st.CreateGroup(ctx, group); err != nil {
+			t.Fatalf("Failed to create group: %v", err)
+		}
+	}
+
+	if err := st.CreateJobTemplate(ctx, &store.JobTemplate{
+		ID: "secret-template", GroupID: "secret-group", Name: "Template", Owner: "ethpandaops", Repo: "secret-repo",
+		WorkflowID: "test.yml", Ref: "main", SourceType: "inline", CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+
+	for i, tc := range []struct {
+		status   store.JobStatus
+		age      time.Duration
+		finished time.Duration
+	}{
+		{store.JobStatusPending, 10 * time.Minute, 0},
+		{store.JobStatusPending, time.Minute, 0},
+		{store.JobStatusRunning, time.Minute, 0},
+		{store.JobStatusCompleted, time.Hour, time.Minute},
+		{store.JobStatusCompleted, time.Hour, 2 * time.Minute},
+		{store.JobStatusFailed, time.Hour, 3 * time.Minute},
+		// Finished before the window.
+		{store.JobStatusFailed, 2 * time.Hour, time.Hour},
+	} {
+		job := &store.Job{
+			ID: fmt.Sprintf("job-%d", i), GroupID: "secret-group", TemplateID: "secret-template",
+			Status: tc.status, Position: i, CreatedBy: "secret-user",
+			CreatedAt: now.Add(-tc.age), UpdatedAt: now,
+		}
+
+		if err := st.CreateJob(ctx, job); err != nil {
+			t.Fatalf("Failed to create job: %v", err)
+		}
+
+		if tc.finished != 0 {
+			completedAt := now.Add(-tc.finished)
+			job.CompletedAt = &completedAt
+
+			if err := st.UpdateJob(ctx, job); err != nil {
+				t.Fatalf("Failed to update job: %v", err)
+			}
+		}
+	}
+
+	for _, runner := range []*store.Runner{
+		{ID: 1, Name: "secret-runner", Status: store.RunnerStatusOnline, Busy: true},
+		{ID: 2, Name: "idle", Status: store.RunnerStatusOnline},
+		{ID: 3, Name: "offline", Status: store.RunnerStatusOffline},
+	} {
+		runner.Labels = []string{"self-hosted"}
+		runner.LastSeenAt, runner.CreatedAt, runner.UpdatedAt = now, now, now
+
+		if err := st.UpsertRunner(ctx, runner); err != nil {
+			t.Fatalf("Failed to upsert runner: %v", err)
+		}
+	}
+
+	var (
+		body   []byte
+		header http.Header
+	)
+
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer endpoint.Close()
+
+	cfg := &config.Config{Telemetry: config.TelemetryConfig{
+		Enabled: true, Endpoint: endpoint.URL, Token: "telemetry-token", Interval: 30 * time.Minute,
+		InstanceID: "instance-1",
+	}}
+
+	svc, ok := NewService(log, cfg, st, "v1.2.3").(*service)
+	if !ok {
+		t.Fatal("Expected the telemetry service implementation")
+	}
+
+	if err := svc.export(ctx, now); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	if got := header.Get("Authorization"); got != "Bearer telemetry-token" {
+		t.Errorf("Expected bearer token, got %q", got)
+	}
+
+	var report Report
+	if err := json.Unmarshal(body, &report); err != nil {
+		t.Fatalf("Failed to decode report %s: %v", body, err)
+	}
+
+	want := Report{
+		InstanceID:    "instance-1",
+		Version:       "v1.2.3",
+		ReportedAt:    now,
+		WindowSeconds: 1800,
+		Groups:        1,
+		Queue:         QueueReport{Pending: 2, Running: 1, OldestPendingSeconds: 600},
+		Jobs:          JobsReport{Completed: 2, Failed: 1, FailureRate: 1.0 / 3},
+		Runners:       RunnersReport{Total: 3, Online: 2, Busy: 1},
+	}
+
+	if !report.ReportedAt.Equal(want.ReportedAt) {
+		t.Errorf("Expected reported_at %s, got %s", want.ReportedAt, report.ReportedAt)
+	}
+
+	report.ReportedAt = want.ReportedAt
+
+	if report != want {
+		t.Errorf("Expected report %+v, got %+v", want, report)
+	}
+
+	for _, secret := range secrets {
+		if strings.Contains(string(body), secret) {
+			t.Errorf("Expected %q not to be reported, got %s", secret, body)
+		}
+	}
+
+	endpoint.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	if err := svc.export(ctx, now); err == nil {
+		t.Error("Expected a failed push to be reported")
+	}
+}
@@ -0,0 +1,719 @@
+package testing
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// GitHubServer is a fake GitHub REST API served over HTTP. It answers the
+// endpoints used by github.Client from the state of a github.FakeClient, so
+// tests exercise the real client's request and response handling.
+type GitHubServer struct {
+	// Fake holds the server's state. Configure runners and advance runs on it.
+	Fake *github.FakeClient
+	// URL is the base URL of the API.
+	URL string
+	// InstallationTokenTTL is how long the GitHub App installation tokens
+	// the server mints last. Defaults to an hour, as on GitHub.
+	InstallationTokenTTL time.Duration
+
+	server *httptest.Server
+
+	mu                 sync.Mutex
+	installationTokens int
+	lastAuthorization  string
+}
+
+// NewGitHubServer starts a fake GitHub API, which is closed when the test ends.
+func NewGitHubServer(t TB) *GitHubServer {
+	t.Helper()
+
+	s := &GitHubServer{Fake: github.NewFakeClient()}
+
+	s.server = httptest.NewServer(s.routes())
+	s.URL = s.server.URL
+
+	t.Cleanup(s.server.Close)
+
+	return s
+}
+
+// Client returns a started github.Client that talks to the server.
+func (s *GitHubServer) Client(t TB, log logrus.FieldLogger) github.Client {
+	t.Helper()
+
+	client := github.NewClient(log, "fake-token", github.WithBaseURL(s.URL))
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start GitHub client: %v", err)
+	}
+
+	return client
+}
+
+// routes builds the API router.
+func (s *GitHubServer) routes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(s.rateLimitHeaders)
+	r.Use(s.recordAuthorization)
+
+	r.Get("/rate_limit", s.handleRateLimit)
+	r.Post("/app/installations/{id}/access_tokens", s.handleCreateInstallationToken)
+	r.Get("/orgs/{org}/actions/runners", s.handleListOrgRunners)
+	r.Get("/orgs/{org}/actions/hosted-runners", s.handleListOrgHostedRunners)
+
+	r.Route("/repos/{owner}/{repo}", func(r chi.Router) {
+		r.Get("/", s.handleGetRepo)
+		r.Get("/actions/runners", s.handleListRepoRunners)
+		r.Post("/actions/workflows/{workflow}/dispatches", s.handleDispatch)
+		r.Get("/actions/workflows/{workflow}/runs", s.handleListRuns)
+		r.Get("/actions/runs", s.handleListRepoRuns)
+		r.Get("/actions/runs/{runID}", s.handleGetRun)
+		r.Get("/actions/runs/{runID}/jobs", s.handleListRunJobs)
+		r.Post("/actions/runs/{runID}/cancel", s.handleCancelRun)
+		r.Get("/environments/{name}", s.handleGetEnvironment)
+		r.Get("/environments/{name}/deployment-branch-policies", s.handleListBranchPolicies)
+		r.Get("/check-runs/{id}/annotations", s.handleListAnnotations)
+		r.Get("/commits/*", s.handleGetCommitSHA)
+		r.Get("/contents/*", s.handleGetContents)
+		r.Post("/issues/{number}/comments", s.handleCreateIssueComment)
+		r.Patch("/issues/{number}", s.handleEditIssue)
+	})
+
+	return r
+}
+
+// rateLimitHeaders sets the rate limit headers read by the client.
+func (s *GitHubServer) rateLimitHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(s.Fake.RateLimitRemaining()))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(s.Fake.RateLimitReset().Unix(), 10))
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// recordAuthorization remembers the Authorization header of API requests.
+func (s *GitHubServer) recordAuthorization(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/app/") {
+			s.mu.Lock()
+			s.lastAuthorization = r.Header.Get("Authorization")
+			s.mu.Unlock()
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LastAuthorization returns the Authorization header of the last API request,
+// not counting installation token requests.
+func (s *GitHubServer) LastAuthorization() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastAuthorization
+}
+
+// InstallationTokens returns how many installation tokens have been minted.
+func (s *GitHubServer) InstallationTokens() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.installationTokens
+}
+
+// handleCreateInstallationToken mints an installation token named after the
+// installation and a counter, such as "ghs_42_1". The app JWT's signature is
+// not checked.
+func (s *GitHubServer) handleCreateInstallationToken(w http.ResponseWriter, r *http.Request) {
+	jwt, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || strings.Count(jwt, ".") != 2 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "A JSON web token could not be decoded"})
+
+		return
+	}
+
+	ttl := s.InstallationTokenTTL
+	if ttl == 0 {
+		ttl = time.Hour
+	}
+
+	s.mu.Lock()
+	s.installationTokens++
+	n := s.installationTokens
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"token":      fmt.Sprintf("ghs_%s_%d", chi.URLParam(r, "id"), n),
+		"expires_at": time.Now().Add(ttl).UTC().Format(time.RFC3339),
+	})
+}
+
+func (s *GitHubServer) handleRateLimit(w http.ResponseWriter, _ *http.Request) {
+	core := map[string]any{
+		"limit":     5000,
+		"remaining": s.Fake.RateLimitRemaining(),
+		"reset":     s.Fake.RateLimitReset().Unix(),
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"resources": map[string]any{"core": core},
+		"rate":      core,
+	})
+}
+
+func (s *GitHubServer) handleListOrgRunners(w http.ResponseWriter, r *http.Request) {
+	runners, err := s.Fake.ListOrgRunners(r.Context(), chi.URLParam(r, "org"))
+	if err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	writeRunners(w, runners)
+}
+
+func (s *GitHubServer) handleListOrgHostedRunners(w http.ResponseWriter, r *http.Request) {
+	runners, err := s.Fake.ListOrgHostedRunners(r.Context(), chi.URLParam(r, "org"))
+	if err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	items := make([]map[string]any, 0, len(runners))
+
+	for _, runner := range runners {
+		items = append(items, map[string]any{
+			"id":              runner.ID,
+			"name":            runner.Name,
+			"platform":        runner.Platform,
+			"status":          runner.Status,
+			"maximum_runners": runner.MaxRunners,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"total_count": len(items),
+		"runners":     items,
+	})
+}
+
+func (s *GitHubServer) handleListRepoRunners(w http.ResponseWriter, r *http.Request) {
+	runners, err := s.Fake.ListRepoRunners(r.Context(), chi.URLParam(r, "owner"), chi.URLParam(r, "repo"))
+	if err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	writeRunners(w, runners)
+}
+
+func (s *GitHubServer) handleGetRepo(w http.ResponseWriter, r *http.Request) {
+	owner, repo := chi.URLParam(r, "owner"), chi.URLParam(r, "repo")
+
+	perms, err := s.Fake.GetRepoPermissions(r.Context(), owner, repo)
+	if err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	if perms == nil {
+		writeNotFound(w)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"name":      repo,
+		"full_name": owner + "/" + repo,
+		"permissions": map[string]bool{
+			"admin": perms.Admin,
+			"push":  perms.Push,
+			"pull":  perms.Pull,
+		},
+	})
+}
+
+func (s *GitHubServer) handleDispatch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Ref    string    `json:"ref"`
+		Inputs input.Map `json:"inputs"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Problems parsing JSON"})
+
+		return
+	}
+
+	if err := s.Fake.TriggerWorkflowDispatch(r.Context(), chi.URLParam(r, "owner"), chi.URLParam(r, "repo"),
+		chi.URLParam(r, "workflow"), body.Ref, body.Inputs); err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *GitHubServer) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	opts, ok := listRunsOpts(w, r)
+	if !ok {
+		return
+	}
+
+	runs, err := s.Fake.ListWorkflowRuns(r.Context(), chi.URLParam(r, "owner"), chi.URLParam(r, "repo"),
+		chi.URLParam(r, "workflow"), opts)
+	if err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	writeRuns(w, runs)
+}
+
+func (s *GitHubServer) handleListRepoRuns(w http.ResponseWriter, r *http.Request) {
+	opts, ok := listRunsOpts(w, r)
+	if !ok {
+		return
+	}
+
+	runs, err := s.Fake.ListRepoWorkflowRuns(r.Context(), chi.URLParam(r, "owner"), chi.URLParam(r, "repo"), opts)
+	if err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	writeRuns(w, runs)
+}
+
+// listRunsOpts parses the query of a run list request, writing an error
+// response if it is invalid.
+func listRunsOpts(w http.ResponseWriter, r *http.Request) (github.ListWorkflowRunsOpts, bool) {
+	query := r.URL.Query()
+
+	opts := github.ListWorkflowRunsOpts{
+		Branch: query.Get("branch"),
+		Event:  query.Get("event"),
+		Status: query.Get("status"),
+	}
+
+	if perPage, err := strconv.Atoi(query.Get("per_page")); err == nil {
+		opts.PerPage = perPage
+	}
+
+	if page, err := strconv.Atoi(query.Get("page")); err == nil {
+		opts.Page = page
+	}
+
+	if created := strings.TrimPrefix(query.Get("created"), ">="); created != "" {
+		createdAt, err := time.Parse(time.RFC3339, created)
+		if err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Invalid created filter"})
+
+			return opts, false
+		}
+
+		opts.CreatedAt = &createdAt
+	}
+
+	return opts, true
+}
+
+// writeRuns encodes a workflow run list response.
+func writeRuns(w http.ResponseWriter, runs []*github.WorkflowRun) {
+	items := make([]map[string]any, 0, len(runs))
+	for _, run := range runs {
+		items = append(items, runJSON(run))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"total_count":   len(items),
+		"workflow_runs": items,
+	})
+}
+
+func (s *GitHubServer) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	runID, ok := runIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	run, err := s.Fake.GetWorkflowRun(r.Context(), chi.URLParam(r, "owner"), chi.URLParam(r, "repo"), runID)
+	if err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, runJSON(run))
+}
+
+func (s *GitHubServer) handleListRunJobs(w http.ResponseWriter, r *http.Request) {
+	runID, ok := runIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	jobs, err := s.Fake.ListWorkflowRunJobs(r.Context(), chi.URLParam(r, "owner"), chi.URLParam(r, "repo"), runID)
+	if err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	items := make([]map[string]any, 0, len(jobs))
+
+	for _, job := range jobs {
+		item := map[string]any{
+			"id":          job.ID,
+			"run_id":      runID,
+			"name":        job.Name,
+			"status":      job.Status,
+			"conclusion":  nullIfEmpty(job.Conclusion),
+			"runner_id":   job.RunnerID,
+			"runner_name": job.RunnerName,
+			"labels":      job.Labels,
+		}
+
+		if !job.StartedAt.IsZero() {
+			item["started_at"] = job.StartedAt.UTC().Format(time.RFC3339)
+		}
+
+		items = append(items, item)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"total_count": len(items),
+		"jobs":        items,
+	})
+}
+
+func (s *GitHubServer) handleCancelRun(w http.ResponseWriter, r *http.Request) {
+	runID, ok := runIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.Fake.CancelWorkflowRun(r.Context(), chi.URLParam(r, "owner"), chi.URLParam(r, "repo"), runID); err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]any{})
+}
+
+func (s *GitHubServer) handleGetEnvironment(w http.ResponseWriter, r *http.Request) {
+	env, err := s.Fake.GetEnvironment(r.Context(), chi.URLParam(r, "owner"), chi.URLParam(r, "repo"), chi.URLParam(r, "name"))
+	if err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	if env == nil {
+		writeNotFound(w)
+
+		return
+	}
+
+	rules := make([]map[string]any, 0, 2)
+
+	if env.RequiredReviewers {
+		rules = append(rules, map[string]any{"type": "required_reviewers"})
+	}
+
+	if env.WaitTimer > 0 {
+		rules = append(rules, map[string]any{"type": "wait_timer", "wait_timer": env.WaitTimer})
+	}
+
+	result := map[string]any{
+		"name":             env.Name,
+		"protection_rules": rules,
+	}
+
+	if env.BranchPolicies != nil {
+		result["deployment_branch_policy"] = map[string]bool{
+			"protected_branches":     false,
+			"custom_branch_policies": true,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *GitHubServer) handleListBranchPolicies(w http.ResponseWriter, r *http.Request) {
+	env, err := s.Fake.GetEnvironment(r.Context(), chi.URLParam(r, "owner"), chi.URLParam(r, "repo"), chi.URLParam(r, "name"))
+	if err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	if env == nil {
+		writeNotFound(w)
+
+		return
+	}
+
+	policies := make([]map[string]string, 0, len(env.BranchPolicies))
+	for _, name := range env.BranchPolicies {
+		policies = append(policies, map[string]string{"name": name})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"total_count":     len(policies),
+		"branch_policies": policies,
+	})
+}
+
+func (s *GitHubServer) handleListAnnotations(w http.ResponseWriter, r *http.Request) {
+	checkRunID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeNotFound(w)
+
+		return
+	}
+
+	annotations, err := s.Fake.ListCheckRunAnnotations(r.Context(), chi.URLParam(r, "owner"),
+		chi.URLParam(r, "repo"), checkRunID)
+	if err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	items := make([]map[string]any, 0, len(annotations))
+	for _, a := range annotations {
+		items = append(items, map[string]any{
+			"path":             a.Path,
+			"start_line":       a.StartLine,
+			"end_line":         a.EndLine,
+			"annotation_level": a.Level,
+			"title":            a.Title,
+			"message":          a.Message,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (s *GitHubServer) handleGetCommitSHA(w http.ResponseWriter, r *http.Request) {
+	ref, err := url.PathUnescape(chi.URLParam(r, "*"))
+	if err != nil {
+		writeNotFound(w)
+
+		return
+	}
+
+	sha, err := s.Fake.ResolveRef(r.Context(), chi.URLParam(r, "owner"), chi.URLParam(r, "repo"), ref)
+	if err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.github.v3.sha")
+	_, _ = w.Write([]byte(sha))
+}
+
+func (s *GitHubServer) handleGetContents(w http.ResponseWriter, r *http.Request) {
+	path, err := url.PathUnescape(chi.URLParam(r, "*"))
+	if err != nil {
+		writeNotFound(w)
+
+		return
+	}
+
+	// The client requests workflow files by path; the fake stores them by workflow ID.
+	workflowID := strings.TrimPrefix(path, ".github/workflows/")
+
+	file, err := s.Fake.GetWorkflowFile(r.Context(), chi.URLParam(r, "owner"), chi.URLParam(r, "repo"),
+		workflowID, r.URL.Query().Get("ref"))
+	if err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	if file == nil {
+		writeNotFound(w)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"type":     "file",
+		"path":     file.Path,
+		"encoding": "base64",
+		"content":  base64.StdEncoding.EncodeToString(file.Content),
+	})
+}
+
+func (s *GitHubServer) handleCreateIssueComment(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(chi.URLParam(r, "number"))
+	if err != nil {
+		writeNotFound(w)
+
+		return
+	}
+
+	var body struct {
+		Body string `json:"body"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Problems parsing JSON"})
+
+		return
+	}
+
+	if err := s.Fake.CreateIssueComment(r.Context(), chi.URLParam(r, "owner"), chi.URLParam(r, "repo"),
+		number, body.Body); err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{"body": body.Body})
+}
+
+// handleEditIssue supports closing an issue, the only edit the client makes.
+func (s *GitHubServer) handleEditIssue(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(chi.URLParam(r, "number"))
+	if err != nil {
+		writeNotFound(w)
+
+		return
+	}
+
+	var body struct {
+		State string `json:"state"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.State != "closed" {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Only closing issues is supported"})
+
+		return
+	}
+
+	if err := s.Fake.CloseIssue(r.Context(), chi.URLParam(r, "owner"), chi.URLParam(r, "repo"), number); err != nil {
+		writeFakeError(w, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"number": number, "state": "closed"})
+}
+
+// runIDParam parses the run ID path parameter, writing a 404 if it is invalid.
+func runIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	runID, err := strconv.ParseInt(chi.URLParam(r, "runID"), 10, 64)
+	if err != nil {
+		writeNotFound(w)
+
+		return 0, false
+	}
+
+	return runID, true
+}
+
+// runJSON encodes a workflow run as returned by the GitHub API.
+func runJSON(run *github.WorkflowRun) map[string]any {
+	return map[string]any{
+		"id":            run.ID,
+		"name":          run.Name,
+		"status":        run.Status,
+		"conclusion":    nullIfEmpty(run.Conclusion),
+		"html_url":      run.HTMLURL,
+		"workflow_id":   run.WorkflowID,
+		"head_branch":   run.HeadBranch,
+		"head_sha":      run.HeadSHA,
+		"run_attempt":   run.RunAttempt,
+		"logs_url":      run.LogsURL,
+		"artifacts_url": run.ArtifactsURL,
+		"event":         "workflow_dispatch",
+		"created_at":    run.CreatedAt.UTC().Format(time.RFC3339),
+		"updated_at":    run.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// writeRunners encodes a runner list response.
+func writeRunners(w http.ResponseWriter, runners []*github.Runner) {
+	items := make([]map[string]any, 0, len(runners))
+
+	for _, runner := range runners {
+		labels := make([]map[string]string, 0, len(runner.Labels))
+		for _, l := range runner.Labels {
+			labels = append(labels, map[string]string{"name": l})
+		}
+
+		items = append(items, map[string]any{
+			"id":     runner.ID,
+			"name":   runner.Name,
+			"os":     runner.OS,
+			"status": runner.Status,
+			"busy":   runner.Busy,
+			"labels": labels,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"total_count": len(items),
+		"runners":     items,
+	})
+}
+
+// writeFakeError maps an error returned by the fake to an API error response.
+func writeFakeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, github.ErrFakeNotFound) {
+		writeNotFound(w)
+
+		return
+	}
+
+	writeJSON(w, http.StatusInternalServerError, map[string]string{"message": err.Error()})
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// nullIfEmpty encodes empty strings as JSON null, as GitHub does for the
+// conclusion of unfinished runs and jobs.
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
@@ -0,0 +1,352 @@
+// Package testing provides an in-process harness for end-to-end tests of the
+// queue and dispatcher against a fake GitHub, so no real tokens or runners are
+// needed. Import it under another name, e.g. dtesting, next to the standard
+// testing package.
+package testing
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/api"
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/dispatcher"
+	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/metrics"
+	"github.com/ethpandaops/dispatchoor/pkg/queue"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultGroupID is the ID of the group created when Options.Groups is empty.
+	DefaultGroupID = "test-group"
+	// DefaultRunnerLabel is the runner label of the default group.
+	DefaultRunnerLabel = "self-hosted"
+
+	// DefaultWaitTimeout bounds the Wait helpers.
+	DefaultWaitTimeout = 10 * time.Second
+
+	// waitPollInterval is how often the Wait helpers check the store.
+	waitPollInterval = 20 * time.Millisecond
+)
+
+// TB is the subset of testing.TB used by the harness.
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...any)
+	Cleanup(fn func())
+	TempDir() string
+}
+
+// sharedMetrics is used by every harness, since metrics register globally.
+var sharedMetrics = sync.OnceValue(metrics.New)
+
+// Options configures a Harness.
+type Options struct {
+	// Groups replaces the default group DefaultGroupID, whose runners carry
+	// DefaultRunnerLabel and which has no templates.
+	Groups []config.Group
+	// Configure adjusts the loaded configuration before the services are created.
+	Configure func(cfg *config.Config)
+	// HTTP routes GitHub calls through the real client and a fake GitHub API
+	// server instead of calling the fake client directly.
+	HTTP bool
+	// Log receives the services' logs. Logs are discarded when nil.
+	Log logrus.FieldLogger
+}
+
+// Harness wires a SQLite store, the queue and the dispatcher to a fake GitHub.
+// The dispatcher runs on short intervals once Start is called.
+type Harness struct {
+	t TB
+
+	Config     *config.Config
+	Store      store.Store
+	Queue      queue.Service
+	Dispatcher dispatcher.Dispatcher
+	// GitHub holds the fake GitHub state, whether or not Options.HTTP is set.
+	GitHub *github.FakeClient
+	// Client is the client given to the dispatcher.
+	Client github.Client
+	// Server is the fake GitHub API, set when Options.HTTP is set.
+	Server *GitHubServer
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New creates a harness. Its services are stopped when the test ends.
+func New(t TB, opts Options) *Harness {
+	t.Helper()
+
+	log := opts.Log
+	if log == nil {
+		logger := logrus.New()
+		logger.SetOutput(io.Discard)
+		log = logger
+	}
+
+	dir := t.TempDir()
+
+	cfg := loadConfig(t, dir, opts.Groups)
+	if opts.Configure != nil {
+		opts.Configure(cfg)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	h := &Harness{
+		t:      t,
+		Config: cfg,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	st := store.NewSQLiteStore(log, cfg.Database.SQLite.Path)
+	if err := st.Start(ctx); err != nil {
+		t.Fatalf("Failed to start store: %v", err)
+	}
+
+	t.Cleanup(func() { _ = st.Stop() })
+
+	if err := st.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate store: %v", err)
+	}
+
+	if err := api.SyncGroupsFromConfig(ctx, log, st, cfg); err != nil {
+		t.Fatalf("Failed to sync groups: %v", err)
+	}
+
+	h.Store = st
+
+	if opts.HTTP {
+		h.Server = NewGitHubServer(t)
+		h.GitHub = h.Server.Fake
+		h.Client = h.Server.Client(t, log)
+	} else {
+		h.GitHub = github.NewFakeClient()
+		h.Client = h.GitHub
+	}
+
+	h.Queue = queue.NewService(log, cfg, st, sharedMetrics())
+	h.Dispatcher = dispatcher.NewDispatcher(log, cfg, st, h.Queue, h.Client, sharedMetrics())
+
+	// Registered last so it runs first, before the store is closed.
+	t.Cleanup(func() {
+		cancel()
+		_ = h.Dispatcher.Stop()
+		_ = h.Queue.Stop()
+	})
+
+	return h
+}
+
+// loadConfig writes a configuration for the groups and loads it, so defaults
+// and validation apply as they do for a real config file.
+func loadConfig(t TB, dir string, groups []config.Group) *config.Config {
+	t.Helper()
+
+	if len(groups) == 0 {
+		groups = []config.Group{{
+			ID:           DefaultGroupID,
+			Name:         "Test Group",
+			RunnerLabels: []string{DefaultRunnerLabel},
+		}}
+	}
+
+	raw := map[string]any{
+		"database": map[string]any{
+			"driver": "sqlite",
+			"sqlite": map[string]any{"path": filepath.Join(dir, "dispatchoor.db")},
+		},
+		"auth": map[string]any{
+			"basic": map[string]any{"enabled": true},
+		},
+		"dispatcher": map[string]any{
+			"enabled":           true,
+			"interval":          "50ms",
+			"tracking_interval": "50ms",
+		},
+		"groups": map[string]any{"github": groups},
+	}
+
+	data, err := yaml.Marshal(raw)
+	if err != nil {
+		t.Fatalf("Failed to encode config: %v", err)
+	}
+
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	return cfg
+}
+
+// Context returns the harness context, cancelled when the test ends.
+func (h *Harness) Context() context.Context {
+	return h.ctx
+}
+
+// Start starts the queue and the dispatch and run tracking loops.
+func (h *Harness) Start() {
+	h.t.Helper()
+
+	if err := h.Queue.Start(h.ctx); err != nil {
+		h.t.Fatalf("Failed to start queue: %v", err)
+	}
+
+	if err := h.Dispatcher.Start(h.ctx); err != nil {
+		h.t.Fatalf("Failed to start dispatcher: %v", err)
+	}
+}
+
+// AddRunner registers an online, idle runner with GitHub and the store.
+func (h *Harness) AddRunner(id int64, name string, labels ...string) *store.Runner {
+	h.t.Helper()
+
+	if len(labels) == 0 {
+		labels = []string{DefaultRunnerLabel}
+	}
+
+	ghRunner := &github.Runner{ID: id, Name: name, OS: "Linux", Status: "online", Labels: labels}
+
+	runners, err := h.GitHub.ListOrgRunners(h.ctx, "")
+	if err != nil {
+		h.t.Fatalf("Failed to list fake runners: %v", err)
+	}
+
+	h.GitHub.SetRunners(append(runners, ghRunner)...)
+
+	now := time.Now()
+	runner := &store.Runner{
+		ID:         id,
+		Name:       name,
+		Labels:     labels,
+		Status:     store.RunnerStatusOnline,
+		OS:         ghRunner.OS,
+		LastSeenAt: now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := h.Store.UpsertRunner(h.ctx, runner); err != nil {
+		h.t.Fatalf("Failed to store runner: %v", err)
+	}
+
+	return runner
+}
+
+// Enqueue adds a job for a template to a group's queue.
+func (h *Harness) Enqueue(groupID, templateID string, inputs input.Map) *store.Job {
+	h.t.Helper()
+
+	job, err := h.Queue.Enqueue(h.ctx, groupID, templateID, "harness", inputs, nil)
+	if err != nil {
+		h.t.Fatalf("Failed to enqueue job: %v", err)
+	}
+
+	return job
+}
+
+// EnqueueManual adds a job without a template to a group's queue.
+func (h *Harness) EnqueueManual(groupID string, opts *queue.EnqueueOptions, inputs input.Map) *store.Job {
+	h.t.Helper()
+
+	job, err := h.Queue.Enqueue(h.ctx, groupID, "", "harness", inputs, opts)
+	if err != nil {
+		h.t.Fatalf("Failed to enqueue manual job: %v", err)
+	}
+
+	return job
+}
+
+// Job returns a job from the store, failing the test when it does not exist.
+func (h *Harness) Job(jobID string) *store.Job {
+	h.t.Helper()
+
+	job, err := h.Store.GetJob(h.ctx, jobID)
+	if err != nil {
+		h.t.Fatalf("Failed to get job %s: %v", jobID, err)
+	}
+
+	if job == nil {
+		h.t.Fatalf("Job %s not found", jobID)
+	}
+
+	return job
+}
+
+// WaitForStatus waits up to DefaultWaitTimeout for a job to reach a status.
+func (h *Harness) WaitForStatus(jobID string, status store.JobStatus) *store.Job {
+	h.t.Helper()
+
+	var job *store.Job
+
+	if !h.waitFor(func() bool {
+		job = h.Job(jobID)
+
+		return job.Status == status
+	}) {
+		h.t.Fatalf("Job %s is %s, expected %s after %s", jobID, job.Status, status, DefaultWaitTimeout)
+	}
+
+	return job
+}
+
+// WaitForRun waits up to DefaultWaitTimeout for a job to be matched to a
+// workflow run and returns the run ID.
+func (h *Harness) WaitForRun(jobID string) int64 {
+	h.t.Helper()
+
+	var job *store.Job
+
+	if !h.waitFor(func() bool {
+		job = h.Job(jobID)
+
+		return job.RunID != nil && *job.RunID != 0
+	}) {
+		h.t.Fatalf("Job %s has no workflow run after %s (status %s)", jobID, DefaultWaitTimeout, job.Status)
+	}
+
+	return *job.RunID
+}
+
+// WaitFor waits up to DefaultWaitTimeout for cond to hold, failing the test
+// with what is being waited for otherwise.
+func (h *Harness) WaitFor(what string, cond func() bool) {
+	h.t.Helper()
+
+	if !h.waitFor(cond) {
+		h.t.Fatalf("Timed out after %s waiting for %s", DefaultWaitTimeout, what)
+	}
+}
+
+// waitFor polls cond until it holds or DefaultWaitTimeout passes.
+func (h *Harness) waitFor(cond func() bool) bool {
+	deadline := time.Now().Add(DefaultWaitTimeout)
+
+	for {
+		if cond() {
+			return true
+		}
+
+		if time.Now().After(deadline) {
+			return false
+		}
+
+		time.Sleep(waitPollInterval)
+	}
+}
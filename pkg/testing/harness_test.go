@@ -0,0 +1,1209 @@
+package testing_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/dispatchoor/pkg/config"
+	"github.com/ethpandaops/dispatchoor/pkg/dispatcher"
+	"github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/ethpandaops/dispatchoor/pkg/input"
+	"github.com/ethpandaops/dispatchoor/pkg/queue"
+	"github.com/ethpandaops/dispatchoor/pkg/store"
+	dtesting "github.com/ethpandaops/dispatchoor/pkg/testing"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+func TestHarnessDispatchLifecycle(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		http bool
+	}{
+		{name: "fake client", http: false},
+		{name: "fake API server", http: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := dtesting.New(t, dtesting.Options{
+				HTTP: tc.http,
+				Groups: []config.Group{{
+					ID:           "sync",
+					Name:         "Sync Tests",
+					RunnerLabels: []string{"sync"},
+					WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+						ID:         "sync-hoodi",
+						Name:       "Sync Hoodi",
+						Owner:      "ethpandaops",
+						Repo:       "syncoor-tests",
+						WorkflowID: "sync.yml",
+						Ref:        "main",
+						Inputs:     input.FromStrings(map[string]string{"network": "hoodi"}),
+					}},
+				}},
+			})
+
+			runner := h.AddRunner(1, "runner-1", "sync")
+			job := h.Enqueue("sync", "sync-hoodi", input.FromStrings(map[string]string{"client": "geth"}))
+
+			h.Start()
+
+			runID := h.WaitForRun(job.ID)
+
+			dispatches := h.GitHub.Dispatches()
+			if len(dispatches) != 1 {
+				t.Fatalf("Expected 1 dispatch, got %d", len(dispatches))
+			}
+
+			if d := dispatches[0]; d.RunID != runID || d.Ref != "main" ||
+				d.Inputs["network"].String() != "hoodi" || d.Inputs["client"].String() != "geth" {
+				t.Errorf("Unexpected dispatch %+v for run %d", d, runID)
+			}
+
+			if _, err := h.GitHub.StartRun(runID, runner.ID, runner.Name); err != nil {
+				t.Fatalf("Failed to start run: %v", err)
+			}
+
+			running := h.WaitForStatus(job.ID, store.JobStatusRunning)
+			if running.RunnerName != "runner-1" {
+				t.Errorf("Expected runner-1, got %q", running.RunnerName)
+			}
+
+			if err := h.GitHub.CompleteRun(runID, "success"); err != nil {
+				t.Fatalf("Failed to complete run: %v", err)
+			}
+
+			h.WaitForStatus(job.ID, store.JobStatusCompleted)
+		})
+	}
+}
+
+func TestHarnessShortestJobFirst(t *testing.T) {
+	template := func(id string) config.WorkflowDispatchTemplate {
+		return config.WorkflowDispatchTemplate{
+			ID:         id,
+			Name:       id,
+			Owner:      "ethpandaops",
+			Repo:       "syncoor-tests",
+			WorkflowID: id + ".yml",
+			Ref:        "main",
+		}
+	}
+
+	h := dtesting.New(t, dtesting.Options{
+		Groups: []config.Group{{
+			ID:                        "sync",
+			Name:                      "Sync Tests",
+			RunnerLabels:              []string{"sync"},
+			SchedulingPolicy:          config.SchedulingPolicyShortestJobFirst,
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{template("soak"), template("quick")},
+		}},
+	})
+
+	// Record one completed run of each template so their durations are known.
+	for templateID, duration := range map[string]time.Duration{"soak": 6 * time.Hour, "quick": 10 * time.Minute} {
+		job := h.Enqueue("sync", templateID, nil)
+		completedAt := time.Now().Add(-time.Hour)
+		triggeredAt := completedAt.Add(-duration)
+		job.Status = store.JobStatusCompleted
+		job.TriggeredAt = &triggeredAt
+		job.CompletedAt = &completedAt
+
+		if err := h.Store.UpdateJob(h.Context(), job); err != nil {
+			t.Fatalf("Failed to complete job: %v", err)
+		}
+	}
+
+	h.AddRunner(1, "runner-1", "sync")
+
+	soak := h.Enqueue("sync", "soak", nil)
+	quick := h.Enqueue("sync", "quick", nil)
+
+	h.Start()
+
+	h.WaitForRun(quick.ID)
+
+	if job := h.Job(soak.ID); job.Status != store.JobStatusPending {
+		t.Errorf("Expected soak job to wait behind the quick job, got %s", job.Status)
+	}
+}
+
+func TestHarnessMatchingReport(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		Groups: []config.Group{{
+			ID:           "sync",
+			Name:         "Sync Tests",
+			RunnerLabels: []string{"sync"},
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+				ID:         "sync-hoodi",
+				Name:       "Sync Hoodi",
+				Owner:      "ethpandaops",
+				Repo:       "syncoor-tests",
+				WorkflowID: "sync.yml",
+				Ref:        "main",
+			}},
+		}},
+	})
+
+	first := h.Enqueue("sync", "sync-hoodi", nil)
+	second := h.Enqueue("sync", "sync-hoodi", nil)
+	paused := h.Enqueue("sync", "sync-hoodi", nil)
+
+	if _, err := h.Queue.Pause(h.Context(), paused.ID); err != nil {
+		t.Fatalf("Failed to pause job: %v", err)
+	}
+
+	reasons := func() map[string]dispatcher.MatchReason {
+		t.Helper()
+
+		reports, err := dispatcher.MatchingReport(h.Context(), logrus.New(), h.Config, h.Store, h.Queue, time.Now())
+		if err != nil {
+			t.Fatalf("Failed to build matching report: %v", err)
+		}
+
+		if len(reports) != 1 || reports[0].Error != "" || reports[0].NextJobID != first.ID {
+			t.Fatalf("Unexpected matching report %+v", reports)
+		}
+
+		byJob := make(map[string]dispatcher.MatchReason)
+		for _, job := range reports[0].Jobs {
+			byJob[job.JobID] = job.Reason
+		}
+
+		return byJob
+	}
+
+	if got := reasons(); got[first.ID] != dispatcher.MatchReasonNoIdleRunner ||
+		got[second.ID] != dispatcher.MatchReasonQueuedBehind || got[paused.ID] != dispatcher.MatchReasonJobPaused {
+		t.Errorf("Unexpected reasons without runners: %v", got)
+	}
+
+	h.AddRunner(1, "runner-1", "sync")
+
+	if got := reasons(); got[first.ID] != dispatcher.MatchReasonDispatchable {
+		t.Errorf("Expected the first job to be dispatchable with an idle runner, got %v", got)
+	}
+
+	if len(h.GitHub.Dispatches()) != 0 {
+		t.Error("Expected the matching report not to dispatch")
+	}
+}
+
+func TestHarnessKeepRunning(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		Groups: []config.Group{{
+			ID:           "devnet",
+			Name:         "Devnet Simulations",
+			RunnerLabels: []string{"devnet"},
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+				ID:          "soak",
+				Name:        "Soak",
+				Owner:       "ethpandaops",
+				Repo:        "devnet-tests",
+				WorkflowID:  "soak.yml",
+				Ref:         "main",
+				KeepRunning: 2,
+			}},
+		}},
+	})
+
+	waitForJobs := func(count int) []*store.Job {
+		t.Helper()
+
+		deadline := time.Now().Add(dtesting.DefaultWaitTimeout)
+
+		for {
+			jobs, err := h.Queue.ListByStatus(h.Context(), "devnet", store.JobStatusPending)
+			if err != nil {
+				t.Fatalf("Failed to list jobs: %v", err)
+			}
+
+			if len(jobs) == count {
+				return jobs
+			}
+
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected %d pending jobs, got %d", count, len(jobs))
+			}
+
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	h.Start()
+
+	jobs := waitForJobs(2)
+	if jobs[0].CreatedBy != "keep_running" {
+		t.Errorf("Expected keep_running creator, got %q", jobs[0].CreatedBy)
+	}
+
+	now := time.Now()
+	finished := jobs[0]
+	finished.Status = store.JobStatusCompleted
+	finished.TriggeredAt = &now
+	finished.CompletedAt = &now
+
+	if err := h.Store.UpdateJob(h.Context(), finished); err != nil {
+		t.Fatalf("Failed to complete job: %v", err)
+	}
+
+	h.Dispatcher.Trigger()
+
+	for _, job := range waitForJobs(2) {
+		if job.ID == finished.ID {
+			t.Errorf("Expected completed job %s to be replaced", finished.ID)
+		}
+	}
+}
+
+func TestHarnessReservedInputs(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		Groups: []config.Group{{
+			ID:           "sync",
+			Name:         "Sync Tests",
+			RunnerLabels: []string{"sync"},
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+				ID:         "sync-hoodi",
+				Name:       "Sync Hoodi",
+				Owner:      "ethpandaops",
+				Repo:       "syncoor-tests",
+				WorkflowID: "sync.yml",
+				Ref:        "main",
+				Inputs:     input.FromStrings(map[string]string{"network": "hoodi"}),
+			}},
+		}},
+	})
+
+	h.GitHub.SetWorkflowFile("ethpandaops", "syncoor-tests", "sync.yml", []byte(`
+on:
+  workflow_dispatch:
+    inputs:
+      network:
+        required: true
+      dispatchoor_job_id:
+        required: false
+      dispatchoor_group:
+        required: false
+`))
+
+	h.AddRunner(1, "runner-1", "sync")
+	job := h.Enqueue("sync", "sync-hoodi", nil)
+
+	h.Start()
+	h.WaitForRun(job.ID)
+
+	dispatches := h.GitHub.Dispatches()
+	if len(dispatches) != 1 {
+		t.Fatalf("Expected 1 dispatch, got %d", len(dispatches))
+	}
+
+	inputs := dispatches[0].Inputs
+	if inputs["network"].String() != "hoodi" || inputs[config.ReservedInputJobID].String() != job.ID ||
+		inputs[config.ReservedInputGroup].String() != "sync" {
+		t.Errorf("Unexpected dispatch inputs %v", inputs)
+	}
+
+	if _, ok := inputs[config.ReservedInputCreatedBy]; ok {
+		t.Errorf("Expected undeclared reserved inputs to be left out, got %v", inputs)
+	}
+
+	if stored := h.Job(job.ID); stored.Inputs[config.ReservedInputJobID].String() != "" {
+		t.Errorf("Expected reserved inputs not to be stored on the job, got %v", stored.Inputs)
+	}
+}
+
+func TestHarnessStalledJob(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		Groups: []config.Group{{
+			ID:           "sync",
+			Name:         "Sync Tests",
+			RunnerLabels: []string{"sync"},
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+				ID:           "sync-hoodi",
+				Name:         "Sync Hoodi",
+				Owner:        "ethpandaops",
+				Repo:         "syncoor-tests",
+				WorkflowID:   "sync.yml",
+				Ref:          "main",
+				StallTimeout: time.Second,
+			}},
+		}},
+	})
+
+	stalled := make(chan string, 1)
+	h.Dispatcher.SetJobStalledCallback(func(job *store.Job, _ time.Duration) {
+		stalled <- job.ID
+	})
+
+	runner := h.AddRunner(1, "runner-1", "sync")
+	job := h.Enqueue("sync", "sync-hoodi", nil)
+
+	h.Start()
+
+	runID := h.WaitForRun(job.ID)
+	if _, err := h.GitHub.StartRun(runID, runner.ID, runner.Name); err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+
+	h.WaitForStatus(job.ID, store.JobStatusRunning)
+
+	select {
+	case id := <-stalled:
+		if id != job.ID {
+			t.Errorf("Expected job %s to stall, got %s", job.ID, id)
+		}
+	case <-time.After(dtesting.DefaultWaitTimeout):
+		t.Fatal("Expected the running job to be flagged as stalled")
+	}
+
+	if h.Job(job.ID).StalledAt == nil {
+		t.Error("Expected stalled_at to be set")
+	}
+
+	// A heartbeat clears the flag.
+	if err := h.Store.RecordJobHeartbeat(h.Context(), job.ID, time.Now()); err != nil {
+		t.Fatalf("Failed to record heartbeat: %v", err)
+	}
+
+	if h.Job(job.ID).StalledAt != nil {
+		t.Error("Expected a heartbeat to clear stalled_at")
+	}
+}
+
+func TestHarnessMaxDuration(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		Groups: []config.Group{{
+			ID:           "sync",
+			Name:         "Sync Tests",
+			RunnerLabels: []string{"sync"},
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+				ID:          "sync-hoodi",
+				Name:        "Sync Hoodi",
+				Owner:       "ethpandaops",
+				Repo:        "syncoor-tests",
+				WorkflowID:  "sync.yml",
+				Ref:         "main",
+				MaxDuration: time.Second,
+			}},
+		}},
+	})
+
+	runner := h.AddRunner(1, "runner-1", "sync")
+	job := h.Enqueue("sync", "sync-hoodi", nil)
+
+	h.Start()
+
+	runID := h.WaitForRun(job.ID)
+	if _, err := h.GitHub.StartRun(runID, runner.ID, runner.Name); err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+
+	h.WaitForStatus(job.ID, store.JobStatusRunning)
+	failed := h.WaitForStatus(job.ID, store.JobStatusFailed)
+
+	if !strings.HasPrefix(failed.ErrorMessage, "timeout") {
+		t.Errorf("Expected a timeout error message, got %q", failed.ErrorMessage)
+	}
+
+	if run := h.GitHub.Run(runID); run.Conclusion != "cancelled" {
+		t.Errorf("Expected the run to be cancelled, got conclusion %q", run.Conclusion)
+	}
+}
+
+func TestHarnessGroupInputs(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		Groups: []config.Group{{
+			ID:           "sync",
+			Name:         "Sync Tests",
+			RunnerLabels: []string{"sync"},
+			Inputs:       input.FromStrings(map[string]string{"network": "hoodi", "cluster": "eu"}),
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+				ID:         "sync-mainnet",
+				Name:       "Sync Mainnet",
+				Owner:      "ethpandaops",
+				Repo:       "syncoor-tests",
+				WorkflowID: "sync.yml",
+				Ref:        "main",
+				Inputs:     input.FromStrings(map[string]string{"network": "mainnet", "el-client": "geth"}),
+			}},
+		}},
+	})
+
+	job := h.Enqueue("sync", "sync-mainnet", input.FromStrings(map[string]string{"el-client": "reth"}))
+
+	want := map[string]string{"network": "mainnet", "cluster": "eu", "el-client": "reth"}
+	if !maps.Equal(job.Inputs.Strings(), want) {
+		t.Errorf("Expected inputs %v, got %v", want, job.Inputs)
+	}
+}
+
+func TestHarnessTypedInputs(t *testing.T) {
+	var inputs input.Map
+	if err := yaml.Unmarshal([]byte("debug: true\nretries: 3\nversion: \"1.10\"\n"), &inputs); err != nil {
+		t.Fatalf("Failed to parse inputs: %v", err)
+	}
+
+	h := dtesting.New(t, dtesting.Options{
+		HTTP: true,
+		Groups: []config.Group{{
+			ID:           "sync",
+			Name:         "Sync Tests",
+			RunnerLabels: []string{"sync"},
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+				ID:         "sync-hoodi",
+				Name:       "Sync Hoodi",
+				Owner:      "ethpandaops",
+				Repo:       "syncoor-tests",
+				WorkflowID: "sync.yml",
+				Ref:        "main",
+				Inputs:     inputs,
+			}},
+		}},
+	})
+
+	h.AddRunner(1, "runner-1", "sync")
+	// Overrides from forms arrive as strings and take the kind of the default.
+	job := h.Enqueue("sync", "sync-hoodi", input.Map{"debug": input.String("false")})
+
+	h.Start()
+	h.WaitForRun(job.ID)
+
+	dispatches := h.GitHub.Dispatches()
+	if len(dispatches) != 1 {
+		t.Fatalf("Expected 1 dispatch, got %d", len(dispatches))
+	}
+
+	body, err := json.Marshal(dispatches[0].Inputs)
+	if err != nil {
+		t.Fatalf("Failed to encode inputs: %v", err)
+	}
+
+	if want := `{"debug":false,"retries":3,"version":"1.10"}`; string(body) != want {
+		t.Errorf("Expected dispatch inputs %s, got %s", want, body)
+	}
+}
+
+func TestHarnessManualJob(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		HTTP: true,
+		Groups: []config.Group{{
+			ID:           "sync",
+			Name:         "Sync Tests",
+			RunnerLabels: []string{"sync"},
+		}},
+	})
+
+	if _, err := h.Queue.Enqueue(t.Context(), "sync", "", "harness", nil, &queue.EnqueueOptions{
+		Owner: "ethpandaops/syncoor-tests", Repo: "syncoor-tests", WorkflowID: "sync.yml", Ref: "main",
+	}); err == nil {
+		t.Error("Expected an owner with a slash to be rejected")
+	}
+
+	h.AddRunner(1, "runner-1", "sync")
+	job := h.EnqueueManual("sync", &queue.EnqueueOptions{
+		Name:       "Ad-hoc sync",
+		Owner:      " ethpandaops ",
+		Repo:       "syncoor-tests",
+		WorkflowID: "sync.yml",
+		Ref:        "main",
+	}, input.FromStrings(map[string]string{"network": "hoodi"}))
+
+	stored := h.Job(job.ID)
+	if stored.TemplateID != "" || stored.Owner == nil || *stored.Owner != "ethpandaops" ||
+		stored.Repo == nil || *stored.Repo != "syncoor-tests" || stored.WorkflowID == nil || *stored.WorkflowID != "sync.yml" ||
+		stored.Ref == nil || *stored.Ref != "main" || stored.Name == nil || *stored.Name != "Ad-hoc sync" {
+		t.Fatalf("Unexpected stored manual job %+v", stored)
+	}
+
+	h.Start()
+	h.WaitForRun(job.ID)
+
+	dispatches := h.GitHub.Dispatches()
+	if len(dispatches) != 1 {
+		t.Fatalf("Expected 1 dispatch, got %d", len(dispatches))
+	}
+
+	if d := dispatches[0]; d.Owner != "ethpandaops" || d.Repo != "syncoor-tests" || d.WorkflowID != "sync.yml" ||
+		d.Ref != "main" || d.Inputs["network"].String() != "hoodi" {
+		t.Errorf("Unexpected dispatch %+v", d)
+	}
+}
+
+func TestHarnessCanaryDispatch(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		Groups: []config.Group{
+			{
+				ID:           "canary",
+				Name:         "Canary",
+				RunnerLabels: []string{"canary"},
+				WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+					ID:         "sync-canary",
+					Name:       "Sync Canary",
+					Owner:      "ethpandaops",
+					Repo:       "syncoor-tests",
+					WorkflowID: "sync.yml",
+					Ref:        "main",
+					Canary:     &config.CanaryConfig{Percent: 100, WorkflowID: "sync-next.yml"},
+				}},
+			},
+			{
+				ID:           "stable",
+				Name:         "Stable",
+				RunnerLabels: []string{"stable"},
+				WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+					ID:         "sync-stable",
+					Name:       "Sync Stable",
+					Owner:      "ethpandaops",
+					Repo:       "syncoor-tests",
+					WorkflowID: "sync.yml",
+					Ref:        "main",
+					Canary:     &config.CanaryConfig{Percent: 0, Ref: "next"},
+				}},
+			},
+		},
+	})
+
+	h.AddRunner(1, "runner-1", "canary")
+	h.AddRunner(2, "runner-2", "stable")
+
+	canary := h.Enqueue("canary", "sync-canary", nil)
+	stable := h.Enqueue("stable", "sync-stable", nil)
+
+	h.Start()
+	h.WaitForRun(canary.ID)
+	h.WaitForRun(stable.ID)
+
+	workflows := make(map[string]int)
+	for _, d := range h.GitHub.Dispatches() {
+		workflows[d.WorkflowID+"@"+d.Ref]++
+	}
+
+	if len(workflows) != 2 || workflows["sync-next.yml@main"] != 1 || workflows["sync.yml@main"] != 1 {
+		t.Errorf("Expected one canary and one stable dispatch, got %v", workflows)
+	}
+
+	if got := h.Job(canary.ID).Variant; got != store.JobVariantCanary {
+		t.Errorf("Expected canary variant, got %q", got)
+	}
+
+	// A canary at 0% leaves jobs on the template's workflow, without a variant.
+	if got := h.Job(stable.ID).Variant; got != "" {
+		t.Errorf("Expected no variant at 0%%, got %q", got)
+	}
+}
+
+func TestHarnessRerunAttempt(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		HTTP: true,
+		Groups: []config.Group{{
+			ID:           "sync",
+			Name:         "Sync Tests",
+			RunnerLabels: []string{"sync"},
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+				ID:         "sync-hoodi",
+				Name:       "Sync Hoodi",
+				Owner:      "ethpandaops",
+				Repo:       "syncoor-tests",
+				WorkflowID: "sync.yml",
+				Ref:        "main",
+			}},
+		}},
+		Configure: func(cfg *config.Config) {
+			cfg.Dispatcher.Reruns.Enabled = true
+			cfg.Dispatcher.Reruns.Interval = 20 * time.Millisecond
+		},
+	})
+
+	runner := h.AddRunner(1, "runner-1", "sync")
+	job := h.Enqueue("sync", "sync-hoodi", nil)
+
+	h.Start()
+
+	runID := h.WaitForRun(job.ID)
+
+	if _, err := h.GitHub.StartRun(runID, runner.ID, runner.Name); err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+
+	h.WaitForStatus(job.ID, store.JobStatusRunning)
+
+	if err := h.GitHub.CompleteRun(runID, "failure"); err != nil {
+		t.Fatalf("Failed to complete run: %v", err)
+	}
+
+	failed := h.WaitForStatus(job.ID, store.JobStatusFailed)
+	if failed.RunAttempt != 1 || failed.LogsURL == "" || failed.ArtifactsURL == "" {
+		t.Fatalf("Expected attempt 1 with log and artifact URLs, got %d %q %q",
+			failed.RunAttempt, failed.LogsURL, failed.ArtifactsURL)
+	}
+
+	// Re-running from GitHub takes the job back up and tracks the new attempt.
+	if err := h.GitHub.RerunRun(runID); err != nil {
+		t.Fatalf("Failed to re-run run: %v", err)
+	}
+
+	rerun := h.WaitForStatus(job.ID, store.JobStatusTriggered)
+	if rerun.RunAttempt != 2 || rerun.CompletedAt != nil || rerun.ErrorMessage != "" {
+		t.Fatalf("Unexpected re-run job: attempt %d, completed %v, error %q",
+			rerun.RunAttempt, rerun.CompletedAt, rerun.ErrorMessage)
+	}
+
+	if _, err := h.GitHub.StartRun(runID, runner.ID, runner.Name); err != nil {
+		t.Fatalf("Failed to start re-run: %v", err)
+	}
+
+	h.WaitForStatus(job.ID, store.JobStatusRunning)
+
+	if err := h.GitHub.CompleteRun(runID, "success"); err != nil {
+		t.Fatalf("Failed to complete re-run: %v", err)
+	}
+
+	h.WaitForStatus(job.ID, store.JobStatusCompleted)
+}
+
+func TestHarnessMinOnlineRunners(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		Groups: []config.Group{{
+			ID:               "sync",
+			Name:             "Sync Tests",
+			RunnerLabels:     []string{"sync"},
+			MinOnlineRunners: 2,
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+				ID:         "sync-hoodi",
+				Name:       "Sync Hoodi",
+				Owner:      "ethpandaops",
+				Repo:       "syncoor-tests",
+				WorkflowID: "sync.yml",
+				Ref:        "main",
+			}},
+		}},
+	})
+
+	h.AddRunner(1, "runner-1", "sync")
+
+	job := h.Enqueue("sync", "sync-hoodi", nil)
+
+	reports, err := dispatcher.MatchingReport(h.Context(), logrus.New(), h.Config, h.Store, h.Queue, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to build matching report: %v", err)
+	}
+
+	if len(reports) != 1 || len(reports[0].Jobs) != 1 ||
+		reports[0].Jobs[0].Reason != dispatcher.MatchReasonNotEnoughOnlineRunners {
+		t.Fatalf("Expected the job to wait for online runners, got %+v", reports)
+	}
+
+	h.Start()
+
+	// An idle runner is not enough while the pool is below its minimum.
+	time.Sleep(300 * time.Millisecond)
+
+	if len(h.GitHub.Dispatches()) != 0 {
+		t.Fatal("Expected no dispatch while fewer runners are online than min_online_runners")
+	}
+
+	h.AddRunner(2, "runner-2", "sync")
+	h.WaitForRun(job.ID)
+}
+
+func TestHarnessParallelGroupDispatch(t *testing.T) {
+	template := func(id, repo string) config.WorkflowDispatchTemplate {
+		return config.WorkflowDispatchTemplate{
+			ID:         id,
+			Name:       id,
+			Owner:      "ethpandaops",
+			Repo:       repo,
+			WorkflowID: "run.yml",
+			Ref:        "main",
+		}
+	}
+
+	h := dtesting.New(t, dtesting.Options{
+		Groups: []config.Group{
+			{
+				ID:                        "sync",
+				Name:                      "Sync Tests",
+				RunnerLabels:              []string{"sync"},
+				WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{template("sync-hoodi", "syncoor-tests")},
+			},
+			{
+				ID:                        "deploy",
+				Name:                      "Deploys",
+				RunnerLabels:              []string{"deploy"},
+				WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{template("deploy-devnet", "devnets")},
+			},
+		},
+	})
+
+	// Dispatches to the sync group's repository hang until released.
+	release := make(chan struct{})
+	h.GitHub.SetDispatchHook(func(_, repo, _ string) {
+		if repo == "syncoor-tests" {
+			select {
+			case <-release:
+			case <-time.After(dtesting.DefaultWaitTimeout):
+			}
+		}
+	})
+
+	h.AddRunner(1, "runner-1", "sync")
+	h.AddRunner(2, "runner-2", "deploy")
+
+	syncJob := h.Enqueue("sync", "sync-hoodi", nil)
+	deployJob := h.Enqueue("deploy", "deploy-devnet", nil)
+
+	h.Start()
+
+	// The groups share no runners, so the hung dispatch does not hold up the
+	// other group.
+	h.WaitForRun(deployJob.ID)
+
+	if job := h.Job(syncJob.ID); job.RunID != nil {
+		t.Fatal("Expected the sync job's dispatch to still be held")
+	}
+
+	close(release)
+	h.WaitForRun(syncJob.ID)
+}
+
+func TestHarnessBulkRunTracking(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		Groups: []config.Group{{
+			ID:           "sync",
+			Name:         "Sync Tests",
+			RunnerLabels: []string{"sync"},
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+				ID:         "sync-hoodi",
+				Name:       "Sync Hoodi",
+				Owner:      "ethpandaops",
+				Repo:       "syncoor-tests",
+				WorkflowID: "sync.yml",
+				Ref:        "main",
+			}},
+		}},
+	})
+
+	runners := []*store.Runner{h.AddRunner(1, "runner-1", "sync"), h.AddRunner(2, "runner-2", "sync")}
+	jobs := []*store.Job{h.Enqueue("sync", "sync-hoodi", nil), h.Enqueue("sync", "sync-hoodi", nil)}
+	runIDs := make([]int64, len(jobs))
+
+	h.Start()
+
+	for i, job := range jobs {
+		runIDs[i] = h.WaitForRun(job.ID)
+		if _, err := h.GitHub.StartRun(runIDs[i], runners[i].ID, runners[i].Name); err != nil {
+			t.Fatalf("Failed to start run: %v", err)
+		}
+
+		h.WaitForStatus(job.ID, store.JobStatusRunning)
+	}
+
+	// Both runs are in one repository, so each cycle lists them together.
+	gets := h.GitHub.Calls("GetWorkflowRun")
+	lists := h.GitHub.Calls("ListRepoWorkflowRuns")
+
+	h.WaitFor("bulk run listings", func() bool {
+		return h.GitHub.Calls("ListRepoWorkflowRuns") >= lists+3
+	})
+
+	if got := h.GitHub.Calls("GetWorkflowRun"); got != gets {
+		t.Errorf("Expected no single run fetches while runs are listed in bulk, got %d", got-gets)
+	}
+
+	for i, job := range jobs {
+		if err := h.GitHub.CompleteRun(runIDs[i], "success"); err != nil {
+			t.Fatalf("Failed to complete run: %v", err)
+		}
+
+		h.WaitForStatus(job.ID, store.JobStatusCompleted)
+	}
+}
+
+func TestHarnessSecretHandoff(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		Groups: []config.Group{{
+			ID:           "sync",
+			Name:         "Sync Tests",
+			RunnerLabels: []string{"sync"},
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+				ID:            "sync-hoodi",
+				Name:          "Sync Hoodi",
+				Owner:         "ethpandaops",
+				Repo:          "syncoor-tests",
+				WorkflowID:    "sync.yml",
+				Ref:           "main",
+				SecretInputs:  []string{"api_token"},
+				SecretHandoff: &config.SecretHandoffConfig{Inputs: []string{"api_token"}},
+			}},
+		}},
+	})
+
+	runner := h.AddRunner(1, "runner-1", "sync")
+	job := h.Enqueue("sync", "sync-hoodi", input.Map{"api_token": input.String("hunter2")})
+
+	h.Start()
+
+	runID := h.WaitForRun(job.ID)
+
+	dispatches := h.GitHub.Dispatches()
+	if len(dispatches) != 1 {
+		t.Fatalf("Expected one dispatch, got %d", len(dispatches))
+	}
+
+	// The run only receives the name of the secret holding the value.
+	name := dispatches[0].Inputs["api_token"].String()
+	if name == "hunter2" || !strings.HasPrefix(name, "DISPATCHOOR_") {
+		t.Fatalf("Expected a secret name as the input, got %q", name)
+	}
+
+	key := github.FakeSecretKey("ethpandaops", "syncoor-tests", "", name)
+	if got := h.GitHub.Secrets()[key]; got != "hunter2" {
+		t.Fatalf("Expected secret %s to hold the input value, got %q", key, got)
+	}
+
+	if _, err := h.GitHub.StartRun(runID, runner.ID, runner.Name); err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+
+	if err := h.GitHub.CompleteRun(runID, "success"); err != nil {
+		t.Fatalf("Failed to complete run: %v", err)
+	}
+
+	h.WaitForStatus(job.ID, store.JobStatusCompleted)
+	h.WaitFor("hand-off secrets to be deleted", func() bool {
+		return len(h.GitHub.Secrets()) == 0 && h.Job(job.ID).HandoffSecrets == nil
+	})
+}
+
+// discardRateLimit ignores the rate limit reported by the poller.
+type discardRateLimit struct{}
+
+func (discardRateLimit) SetGitHubRateLimit(float64) {}
+
+func TestHarnessHostedRunnerPool(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		HTTP: true,
+		Groups: []config.Group{{
+			ID:           "sync",
+			Name:         "Sync Tests",
+			RunnerLabels: []string{"ubuntu-32core"},
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+				ID:         "sync-hoodi",
+				Name:       "Sync Hoodi",
+				Owner:      "ethpandaops",
+				Repo:       "syncoor-tests",
+				WorkflowID: "sync.yml",
+				Ref:        "main",
+			}},
+		}},
+		Configure: func(cfg *config.Config) {
+			cfg.GitHub.HostedRunners = true
+		},
+	})
+
+	h.GitHub.SetHostedRunners(&github.HostedRunner{
+		ID:         7,
+		Name:       "ubuntu-32core",
+		Platform:   "linux-x64",
+		Status:     github.HostedRunnerStatusReady,
+		MaxRunners: 1,
+	})
+
+	poller := github.NewPoller(logrus.New(), h.Config, h.Client, h.Store, discardRateLimit{})
+	if err := poller.ForceRefresh(h.Context()); err != nil {
+		t.Fatalf("Failed to poll runners: %v", err)
+	}
+
+	pool, err := h.Store.GetRunner(h.Context(), github.HostedRunnerID(7))
+	if err != nil || pool == nil {
+		t.Fatalf("Expected the hosted pool to be stored, got %v, %v", pool, err)
+	}
+
+	if !pool.Hosted || pool.MaxRunners != 1 || pool.Status != store.RunnerStatusOnline || pool.Busy ||
+		pool.OS != "linux" || len(pool.Labels) != 1 || pool.Labels[0] != "ubuntu-32core" {
+		t.Fatalf("Unexpected hosted pool %+v", pool)
+	}
+
+	first := h.Enqueue("sync", "sync-hoodi", nil)
+	second := h.Enqueue("sync", "sync-hoodi", nil)
+
+	h.Start()
+
+	runID := h.WaitForRun(first.ID)
+
+	// Hosted machines are not known runners; the pool is full while the job runs.
+	if _, err := h.GitHub.StartRun(runID, 9001, "GitHub Actions 9001"); err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+
+	h.WaitForStatus(first.ID, store.JobStatusRunning)
+
+	time.Sleep(300 * time.Millisecond)
+
+	if n := len(h.GitHub.Dispatches()); n != 1 {
+		t.Fatalf("Expected 1 dispatch while the pool is full, got %d", n)
+	}
+
+	reports, err := dispatcher.MatchingReport(h.Context(), logrus.New(), h.Config, h.Store, h.Queue, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to build matching report: %v", err)
+	}
+
+	if len(reports) != 1 || len(reports[0].Runners) != 1 || !reports[0].Runners[0].Hosted ||
+		!reports[0].Runners[0].Busy || reports[0].IdleRunners != 0 {
+		t.Fatalf("Expected the full hosted pool in the matching report, got %+v", reports)
+	}
+
+	if err := h.GitHub.CompleteRun(runID, "success"); err != nil {
+		t.Fatalf("Failed to complete run: %v", err)
+	}
+
+	h.WaitForStatus(first.ID, store.JobStatusCompleted)
+	h.WaitForRun(second.ID)
+}
+
+func TestHarnessNotBefore(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		Groups: []config.Group{{
+			ID:           "sync",
+			Name:         "Sync Tests",
+			RunnerLabels: []string{"sync"},
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+				ID:         "sync-hoodi",
+				Name:       "Sync Hoodi",
+				Owner:      "ethpandaops",
+				Repo:       "syncoor-tests",
+				WorkflowID: "sync.yml",
+				Ref:        "main",
+			}},
+		}},
+	})
+
+	h.AddRunner(1, "runner-1", "sync")
+
+	notBefore := time.Now().Add(time.Hour)
+
+	held, err := h.Queue.Enqueue(h.Context(), "sync", "sync-hoodi", "harness", nil, &queue.EnqueueOptions{NotBefore: &notBefore})
+	if err != nil {
+		t.Fatalf("Failed to enqueue held job: %v", err)
+	}
+
+	later := h.Enqueue("sync", "sync-hoodi", nil)
+
+	reports, err := dispatcher.MatchingReport(h.Context(), logrus.New(), h.Config, h.Store, h.Queue, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to build matching report: %v", err)
+	}
+
+	if len(reports) != 1 || reports[0].NextJobID != later.ID || len(reports[0].Jobs) != 2 ||
+		reports[0].Jobs[0].Reason != dispatcher.MatchReasonNotBefore {
+		t.Fatalf("Expected the held job to be passed over, got %+v", reports)
+	}
+
+	h.Start()
+
+	runID := h.WaitForRun(later.ID)
+
+	if job := h.Job(held.ID); job.Status != store.JobStatusPending || job.NotBefore == nil {
+		t.Fatalf("Expected the held job to stay pending, got %s", job.Status)
+	}
+
+	if err := h.Queue.UpdateJob(h.Context(), held.ID, &queue.UpdateJobOptions{ClearNotBefore: true}); err != nil {
+		t.Fatalf("Failed to clear not_before: %v", err)
+	}
+
+	if err := h.GitHub.CompleteRun(runID, "success"); err != nil {
+		t.Fatalf("Failed to complete run: %v", err)
+	}
+
+	h.WaitForRun(held.ID)
+}
+
+func TestHarnessWorkflowRunWebhooks(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		Groups: []config.Group{{
+			ID:           "sync",
+			Name:         "Sync Tests",
+			RunnerLabels: []string{"sync"},
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+				ID:         "sync-hoodi",
+				Name:       "Sync Hoodi",
+				Owner:      "ethpandaops",
+				Repo:       "syncoor-tests",
+				WorkflowID: "sync.yml",
+				Ref:        "main",
+			}},
+		}},
+		Configure: func(cfg *config.Config) {
+			// Only webhooks move the job on.
+			cfg.Dispatcher.TrackingInterval = time.Hour
+		},
+	})
+
+	h.AddRunner(1, "runner-1", "sync")
+
+	job := h.Enqueue("sync", "sync-hoodi", nil)
+
+	h.Start()
+
+	runID := h.WaitForRun(job.ID)
+
+	if err := h.Dispatcher.HandleWorkflowJobStarted(h.Context(), runID, 1, "runner-1"); err != nil {
+		t.Fatalf("Failed to handle workflow job: %v", err)
+	}
+
+	running := h.Job(job.ID)
+	if running.Status != store.JobStatusRunning || running.RunnerName != "runner-1" {
+		t.Fatalf("Expected the job running on runner-1, got %s on %q", running.Status, running.RunnerName)
+	}
+
+	if runner, err := h.Store.GetRunner(h.Context(), 1); err != nil || runner == nil || !runner.Busy {
+		t.Fatalf("Expected runner-1 to be busy, got %+v, %v", runner, err)
+	}
+
+	run := &github.WorkflowRun{ID: runID, Status: "completed", Conclusion: "failure", RunAttempt: 1}
+	if err := h.Dispatcher.HandleWorkflowRun(h.Context(), run); err != nil {
+		t.Fatalf("Failed to handle workflow run: %v", err)
+	}
+
+	if failed := h.Job(job.ID); failed.Status != store.JobStatusFailed || failed.ErrorMessage != "Workflow failure" {
+		t.Fatalf("Expected the job to fail from the webhook, got %s: %s", failed.Status, failed.ErrorMessage)
+	}
+
+	// A repeated delivery does not move a finished job again.
+	if err := h.Dispatcher.HandleWorkflowRun(h.Context(), run); err != nil {
+		t.Fatalf("Failed to handle repeated workflow run: %v", err)
+	}
+}
+
+func TestGitHubAppAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "app.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	app := config.GitHubAppConfig{AppID: 7, InstallationID: 42, PrivateKeyFile: keyFile}
+
+	start := func(server *dtesting.GitHubServer) github.Client {
+		client := github.NewClient(logrus.New(), "", github.WithBaseURL(server.URL), github.WithAppAuth(app))
+		if err := client.Start(t.Context()); err != nil {
+			t.Fatalf("Failed to start client: %v", err)
+		}
+
+		if !client.IsConnected() {
+			t.Fatalf("Expected the client to connect, got %q", client.ConnectionError())
+		}
+
+		return client
+	}
+
+	server := dtesting.NewGitHubServer(t)
+	client := start(server)
+
+	if _, err := client.ListOrgRunners(t.Context(), "ethpandaops"); err != nil {
+		t.Fatalf("Failed to list runners: %v", err)
+	}
+
+	if got := server.LastAuthorization(); got != "Bearer ghs_42_1" {
+		t.Errorf("Expected requests to carry the installation token, got %q", got)
+	}
+
+	if n := server.InstallationTokens(); n != 1 {
+		t.Errorf("Expected the installation token to be reused, got %d minted", n)
+	}
+
+	// Tokens close to expiry are renewed before the next request.
+	expiring := dtesting.NewGitHubServer(t)
+	expiring.InstallationTokenTTL = time.Minute
+	client = start(expiring)
+
+	if _, err := client.ListOrgRunners(t.Context(), "ethpandaops"); err != nil {
+		t.Fatalf("Failed to list runners: %v", err)
+	}
+
+	if got := expiring.LastAuthorization(); got != "Bearer ghs_42_2" {
+		t.Errorf("Expected an expiring token to be renewed, got %q", got)
+	}
+
+	app.PrivateKeyFile = filepath.Join(t.TempDir(), "missing.pem")
+	if err := github.NewClient(logrus.New(), "", github.WithAppAuth(app)).Start(t.Context()); err == nil {
+		t.Error("Expected a missing private key to fail the client's start")
+	}
+}
+
+func TestHarnessEnvironmentCheckErrors(t *testing.T) {
+	h := dtesting.New(t, dtesting.Options{
+		Groups: []config.Group{{
+			ID:           "deploy",
+			Name:         "Deploys",
+			RunnerLabels: []string{"deploy"},
+			WorkflowDispatchTemplates: []config.WorkflowDispatchTemplate{{
+				ID:          "deploy-devnet",
+				Name:        "Deploy Devnet",
+				Owner:       "ethpandaops",
+				Repo:        "devnet-deployer",
+				WorkflowID:  "deploy.yml",
+				Ref:         "main",
+				Environment: "devnet",
+			}},
+		}},
+	})
+
+	h.AddRunner(1, "runner-1", "deploy")
+	h.GitHub.SetEnvironment("ethpandaops", "devnet-deployer", &github.Environment{Name: "devnet"})
+
+	// GitHub failing to answer holds the job, and it is dispatched once
+	// GitHub answers again.
+	h.GitHub.SetError("GetEnvironment", errors.New("502 bad gateway"))
+
+	job := h.Enqueue("deploy", "deploy-devnet", nil)
+
+	h.Start()
+
+	h.WaitFor("environment check retried", func() bool { return h.GitHub.Calls("GetEnvironment") >= 2 })
+
+	if got := h.Job(job.ID); got.Status != store.JobStatusPending || got.RunID != nil {
+		t.Fatalf("Expected the job to be held while the environment check fails, got %s", got.Status)
+	}
+
+	h.GitHub.SetError("GetEnvironment", nil)
+
+	if err := h.GitHub.CompleteRun(h.WaitForRun(job.ID), "success"); err != nil {
+		t.Fatalf("Failed to complete run: %v", err)
+	}
+
+	h.WaitForStatus(job.ID, store.JobStatusCompleted)
+
+	// A job whose environment can never be checked is failed, not dispatched.
+	h.GitHub.SetError("GetEnvironment", errors.New("502 bad gateway"))
+
+	unchecked := h.Enqueue("deploy", "deploy-devnet", nil)
+
+	failed := h.WaitForStatus(unchecked.ID, store.JobStatusFailed)
+	if failed.RunID != nil || !strings.Contains(failed.ErrorMessage, "did not answer") {
+		t.Errorf("Expected the unchecked job to fail undispatched, got run %v: %q", failed.RunID, failed.ErrorMessage)
+	}
+
+	// A definite rejection still fails the job.
+	h.GitHub.SetError("GetEnvironment", nil)
+	h.GitHub.SetEnvironment("ethpandaops", "devnet-deployer", &github.Environment{Name: "devnet", RequiredReviewers: true})
+
+	rejected := h.Enqueue("deploy", "deploy-devnet", nil)
+	h.WaitForStatus(rejected.ID, store.JobStatusFailed)
+}
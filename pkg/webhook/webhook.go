@@ -0,0 +1,235 @@
+// Package webhook verifies and decodes GitHub webhook deliveries into the
+// events dispatchoor acts on.
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	dgithub "github.com/ethpandaops/dispatchoor/pkg/github"
+	"github.com/google/go-github/v60/github"
+)
+
+// ErrInvalidSignature is returned when a webhook delivery is not signed
+// with the configured secret.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// WorkflowJobEvent is a workflow_job webhook delivery.
+type WorkflowJobEvent struct {
+	Action string // queued, in_progress, completed, waiting
+	Owner  string
+	Repo   string
+	RunID  int64
+	// RunAttempt is the attempt of the run the job belongs to.
+	RunAttempt int
+	Job        *dgithub.WorkflowJob
+}
+
+// WorkflowRunEvent is a workflow_run webhook delivery.
+type WorkflowRunEvent struct {
+	Action string // requested, in_progress, completed
+	Owner  string
+	Repo   string
+	Run    *dgithub.WorkflowRun
+}
+
+// IssueCommentEvent is an issue_comment webhook delivery. Comments on pull
+// requests are delivered as issue comments too.
+type IssueCommentEvent struct {
+	Action string // created, edited, deleted
+	Owner  string
+	Repo   string
+	// Number is the issue or pull request number.
+	Number int
+	Body   string
+	// Author is the login of the comment's author, AuthorID their numeric
+	// user ID and AuthorType "User" or "Bot".
+	Author     string
+	AuthorID   int64
+	AuthorType string
+}
+
+// IssuesEvent is an issues webhook delivery. Pull requests are not delivered
+// as issues events.
+type IssuesEvent struct {
+	Action string // opened, edited, labeled, closed, ...
+	Owner  string
+	Repo   string
+	Number int
+	Title  string
+	Body   string
+	// Labels are the names of the labels on the issue.
+	Labels []string
+	// Author is the login of the issue's author, AuthorID their numeric
+	// user ID and AuthorType "User" or "Bot".
+	Author     string
+	AuthorID   int64
+	AuthorType string
+}
+
+// PushEvent is a push webhook delivery.
+type PushEvent struct {
+	// Repo is the owner/name of the repository.
+	Repo string
+	// Ref is the pushed ref, such as refs/heads/main or refs/tags/v1.
+	Ref           string
+	DefaultBranch string
+}
+
+// Parse verifies the signature of a webhook delivery and decodes it into a
+// *WorkflowJobEvent, *WorkflowRunEvent, *IssueCommentEvent, *IssuesEvent or
+// *PushEvent. It returns nil without an error for other events.
+func Parse(r *http.Request, secret string) (any, error) {
+	payload, err := github.ValidatePayload(r, []byte(secret))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	switch github.WebHookType(r) {
+	case "workflow_job":
+		return parseWorkflowJobEvent(payload)
+	case "workflow_run":
+		return parseWorkflowRunEvent(payload)
+	case "issue_comment":
+		return parseIssueCommentEvent(payload)
+	case "issues":
+		return parseIssuesEvent(payload)
+	case "push":
+		return parsePushEvent(payload)
+	default:
+		return nil, nil
+	}
+}
+
+// parseIssueCommentEvent decodes an issue_comment payload.
+func parseIssueCommentEvent(payload []byte) (*IssueCommentEvent, error) {
+	event, err := github.ParseWebHook("issue_comment", payload)
+	if err != nil {
+		return nil, fmt.Errorf("parsing issue_comment event: %w", err)
+	}
+
+	ev, ok := event.(*github.IssueCommentEvent)
+	if !ok || ev.Comment == nil || ev.Issue == nil {
+		return nil, fmt.Errorf("unexpected issue_comment payload")
+	}
+
+	return &IssueCommentEvent{
+		Action:     ev.GetAction(),
+		Owner:      ev.GetRepo().GetOwner().GetLogin(),
+		Repo:       ev.GetRepo().GetName(),
+		Number:     ev.GetIssue().GetNumber(),
+		Body:       ev.GetComment().GetBody(),
+		Author:     ev.GetComment().GetUser().GetLogin(),
+		AuthorID:   ev.GetComment().GetUser().GetID(),
+		AuthorType: ev.GetComment().GetUser().GetType(),
+	}, nil
+}
+
+// parseIssuesEvent decodes an issues payload.
+func parseIssuesEvent(payload []byte) (*IssuesEvent, error) {
+	event, err := github.ParseWebHook("issues", payload)
+	if err != nil {
+		return nil, fmt.Errorf("parsing issues event: %w", err)
+	}
+
+	ev, ok := event.(*github.IssuesEvent)
+	if !ok || ev.Issue == nil {
+		return nil, fmt.Errorf("unexpected issues payload")
+	}
+
+	labels := make([]string, 0, len(ev.GetIssue().Labels))
+	for _, label := range ev.GetIssue().Labels {
+		labels = append(labels, label.GetName())
+	}
+
+	return &IssuesEvent{
+		Action:     ev.GetAction(),
+		Owner:      ev.GetRepo().GetOwner().GetLogin(),
+		Repo:       ev.GetRepo().GetName(),
+		Number:     ev.GetIssue().GetNumber(),
+		Title:      ev.GetIssue().GetTitle(),
+		Body:       ev.GetIssue().GetBody(),
+		Labels:     labels,
+		Author:     ev.GetIssue().GetUser().GetLogin(),
+		AuthorID:   ev.GetIssue().GetUser().GetID(),
+		AuthorType: ev.GetIssue().GetUser().GetType(),
+	}, nil
+}
+
+// parsePushEvent decodes a push payload.
+func parsePushEvent(payload []byte) (*PushEvent, error) {
+	event, err := github.ParseWebHook("push", payload)
+	if err != nil {
+		return nil, fmt.Errorf("parsing push event: %w", err)
+	}
+
+	ev, ok := event.(*github.PushEvent)
+	if !ok || ev.Repo == nil {
+		return nil, fmt.Errorf("unexpected push payload")
+	}
+
+	return &PushEvent{
+		Repo:          ev.GetRepo().GetFullName(),
+		Ref:           ev.GetRef(),
+		DefaultBranch: ev.GetRepo().GetDefaultBranch(),
+	}, nil
+}
+
+// parseWorkflowRunEvent decodes a workflow_run payload.
+func parseWorkflowRunEvent(payload []byte) (*WorkflowRunEvent, error) {
+	event, err := github.ParseWebHook("workflow_run", payload)
+	if err != nil {
+		return nil, fmt.Errorf("parsing workflow_run event: %w", err)
+	}
+
+	ev, ok := event.(*github.WorkflowRunEvent)
+	if !ok || ev.WorkflowRun == nil {
+		return nil, fmt.Errorf("unexpected workflow_run payload")
+	}
+
+	return &WorkflowRunEvent{
+		Action: ev.GetAction(),
+		Owner:  ev.GetRepo().GetOwner().GetLogin(),
+		Repo:   ev.GetRepo().GetName(),
+		Run:    dgithub.ConvertWorkflowRun(ev.WorkflowRun),
+	}, nil
+}
+
+// parseWorkflowJobEvent decodes a workflow_job payload.
+func parseWorkflowJobEvent(payload []byte) (*WorkflowJobEvent, error) {
+	event, err := github.ParseWebHook("workflow_job", payload)
+	if err != nil {
+		return nil, fmt.Errorf("parsing workflow_job event: %w", err)
+	}
+
+	ev, ok := event.(*github.WorkflowJobEvent)
+	if !ok || ev.WorkflowJob == nil {
+		return nil, fmt.Errorf("unexpected workflow_job payload")
+	}
+
+	job := ev.WorkflowJob
+
+	wj := &dgithub.WorkflowJob{
+		ID:         job.GetID(),
+		Name:       job.GetName(),
+		Status:     job.GetStatus(),
+		Conclusion: job.GetConclusion(),
+		RunnerID:   job.GetRunnerID(),
+		RunnerName: job.GetRunnerName(),
+		Labels:     job.Labels,
+	}
+
+	if job.StartedAt != nil {
+		wj.StartedAt = job.StartedAt.Time
+	}
+
+	return &WorkflowJobEvent{
+		Action:     ev.GetAction(),
+		Owner:      ev.GetRepo().GetOwner().GetLogin(),
+		Repo:       ev.GetRepo().GetName(),
+		RunID:      job.GetRunID(),
+		RunAttempt: int(job.GetRunAttempt()),
+		Job:        wj,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// delivery builds a webhook request for event, signed with secret.
+func delivery(t *testing.T, event, secret, payload string) *http.Request {
+	t.Helper()
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", bytes.NewReader([]byte(payload)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	return req
+}
+
+func TestParse(t *testing.T) {
+	run := `{"action":"completed","repository":{"name":"tests","owner":{"login":"ethpandaops"}},` +
+		`"workflow_run":{"id":42,"status":"completed","conclusion":"success","run_attempt":2}}`
+
+	event, err := Parse(delivery(t, "workflow_run", "secret", run), "secret")
+	if err != nil {
+		t.Fatalf("Failed to parse workflow_run: %v", err)
+	}
+
+	runEvent, ok := event.(*WorkflowRunEvent)
+	if !ok || runEvent.Action != "completed" || runEvent.Owner != "ethpandaops" || runEvent.Repo != "tests" ||
+		runEvent.Run.ID != 42 || runEvent.Run.Conclusion != "success" || runEvent.Run.RunAttempt != 2 {
+		t.Errorf("Unexpected workflow_run event %+v", event)
+	}
+
+	job := `{"action":"in_progress","repository":{"name":"tests","owner":{"login":"ethpandaops"}},` +
+		`"workflow_job":{"id":7,"run_id":42,"run_attempt":1,"runner_id":3,"runner_name":"runner-3","labels":["self-hosted"]}}`
+
+	event, err = Parse(delivery(t, "workflow_job", "secret", job), "secret")
+	if err != nil {
+		t.Fatalf("Failed to parse workflow_job: %v", err)
+	}
+
+	jobEvent, ok := event.(*WorkflowJobEvent)
+	if !ok || jobEvent.RunID != 42 || jobEvent.RunAttempt != 1 || jobEvent.Job.RunnerID != 3 ||
+		jobEvent.Job.RunnerName != "runner-3" || len(jobEvent.Job.Labels) != 1 {
+		t.Errorf("Unexpected workflow_job event %+v", event)
+	}
+
+	if _, err := Parse(delivery(t, "workflow_run", "other", run), "secret"); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected a wrongly signed delivery to be rejected, got %v", err)
+	}
+
+	if event, err := Parse(delivery(t, "star", "secret", `{"action":"created"}`), "secret"); err != nil || event != nil {
+		t.Errorf("Expected other events to be ignored, got %+v (%v)", event, err)
+	}
+}
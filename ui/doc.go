@@ -0,0 +1,3 @@
+// Package ui exposes the web UI build for serving from the dispatchoor binary.
+// Build with -tags embedui after `npm run build` to embed ui/dist.
+package ui
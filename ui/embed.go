@@ -0,0 +1,21 @@
+//go:build embedui
+
+package ui
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:dist
+var dist embed.FS
+
+// Assets returns the built UI (ui/dist) embedded in the binary.
+func Assets() (fs.FS, bool) {
+	sub, err := fs.Sub(dist, "dist")
+	if err != nil {
+		return nil, false
+	}
+
+	return sub, true
+}
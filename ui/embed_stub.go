@@ -0,0 +1,10 @@
+//go:build !embedui
+
+package ui
+
+import "io/fs"
+
+// Assets returns false since this binary was built without the embedui tag.
+func Assets() (fs.FS, bool) {
+	return nil, false
+}
@@ -1,17 +1,44 @@
 import type {
   GroupWithStats,
+  OverviewResponse,
+  SearchResponse,
   Group,
   JobTemplate,
+  InputValue,
+  GroupedTemplatesResponse,
+  TemplateLint,
   Job,
+  JobAnnotation,
+  JobEvent,
+  JobAttemptsResponse,
+  JobComparison,
   Runner,
   SystemStatus,
   ApiError,
   User,
+  LoginResponse,
+  Session,
   HistoryResponse,
+  HistoryStatsGroupBy,
   HistoryStatsResponse,
   HistoryStatsTimeRange,
   HealthResponse,
   ReloadTemplatesResponse,
+  SavedFilter,
+  SavedFilterRequest,
+  SavedFilterView,
+  Subscription,
+  SubscriptionRequest,
+  CampaignResponse,
+  CreateCampaignRequest,
+  CampaignActionResponse,
+  StagedConfigSync,
+  QueueChangesResponse,
+  QueueStatsRange,
+  QueueStatsResponse,
+  QueuePageResponse,
+  QueueSummaryResponse,
+  MatchingReportResponse,
 } from '../types';
 import { getConfig } from '../config';
 
@@ -38,9 +65,43 @@ class ApiClient {
     return this.token;
   }
 
+  // Refresh tokens are only issued when the server uses JWT sessions.
+  private setSession(result: LoginResponse) {
+    this.setToken(result.token);
+    if (result.refresh_token) {
+      localStorage.setItem('refresh_token', result.refresh_token);
+    } else {
+      localStorage.removeItem('refresh_token');
+    }
+  }
+
+  // Renews an expired access token, returning whether it succeeded.
+  private async refreshSession(): Promise<boolean> {
+    const refreshToken = localStorage.getItem('refresh_token');
+    if (!refreshToken) {
+      return false;
+    }
+
+    const response = await fetch(`${this.getApiBase()}/auth/refresh`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ refresh_token: refreshToken }),
+      credentials: 'include',
+    });
+
+    if (!response.ok) {
+      localStorage.removeItem('refresh_token');
+      return false;
+    }
+
+    this.setSession(await response.json());
+    return true;
+  }
+
   private async request<T>(
     path: string,
-    options: RequestInit = {}
+    options: RequestInit = {},
+    retried = false
   ): Promise<T> {
     const headers: Record<string, string> = {
       'Content-Type': 'application/json',
@@ -51,6 +112,12 @@ class ApiClient {
       headers['Authorization'] = `Bearer ${token}`;
     }
 
+    // Cookie-authenticated requests must echo the CSRF cookie.
+    const csrfToken = document.cookie.match(/(?:^|; )csrf_token=([^;]*)/)?.[1];
+    if (csrfToken) {
+      headers['X-CSRF-Token'] = csrfToken;
+    }
+
     const response = await fetch(`${this.getApiBase()}${path}`, {
       ...options,
       headers: {
@@ -61,6 +128,10 @@ class ApiClient {
     });
 
     if (response.status === 401) {
+      if (!retried && token && (await this.refreshSession())) {
+        return this.request<T>(path, options, true);
+      }
+
       this.setToken(null);
       // Don't dispatch logout event if this IS the logout request (prevents infinite loop)
       if (!path.endsWith('/auth/logout')) {
@@ -89,12 +160,12 @@ class ApiClient {
   }
 
   // Auth
-  async login(username: string, password: string): Promise<{ token: string; user: User }> {
-    const result = await this.request<{ token: string; user: User }>('/auth/login', {
+  async login(username: string, password: string): Promise<LoginResponse> {
+    const result = await this.request<LoginResponse>('/auth/login', {
       method: 'POST',
       body: JSON.stringify({ username, password }),
     });
-    this.setToken(result.token);
+    this.setSession(result);
     return result;
   }
 
@@ -103,6 +174,7 @@ class ApiClient {
       await this.request<void>('/auth/logout', { method: 'POST' });
     } finally {
       this.setToken(null);
+      localStorage.removeItem('refresh_token');
     }
   }
 
@@ -110,16 +182,26 @@ class ApiClient {
     return this.request<User>('/auth/me');
   }
 
+  async getSessions(all = false): Promise<Session[]> {
+    const query = all ? '?all=true' : '';
+    const result = await this.request<{ sessions: Session[] }>(`/auth/sessions${query}`);
+    return result.sessions;
+  }
+
+  async revokeSession(id: string): Promise<void> {
+    await this.request<void>(`/auth/sessions/${id}`, { method: 'DELETE' });
+  }
+
   getGitHubAuthUrl(): string {
     return `${this.getApiBase()}/auth/github`;
   }
 
-  async exchangeCode(code: string): Promise<{ token: string; user: User }> {
-    const result = await this.request<{ token: string; user: User }>('/auth/exchange', {
+  async exchangeCode(code: string): Promise<LoginResponse> {
+    const result = await this.request<LoginResponse>('/auth/exchange', {
       method: 'POST',
       body: JSON.stringify({ code }),
     });
-    this.setToken(result.token);
+    this.setSession(result);
     return result;
   }
 
@@ -128,6 +210,16 @@ class ApiClient {
     return this.request<GroupWithStats[]>('/groups');
   }
 
+  async getOverview(): Promise<OverviewResponse> {
+    return this.request<OverviewResponse>('/overview');
+  }
+
+  async search(query: string, limit?: number): Promise<SearchResponse> {
+    const params = new URLSearchParams({ q: query });
+    if (limit) params.set('limit', String(limit));
+    return this.request<SearchResponse>(`/search?${params}`);
+  }
+
   async getGroup(id: string): Promise<Group> {
     return this.request<Group>(`/groups/${id}`);
   }
@@ -140,20 +232,46 @@ class ApiClient {
     return this.request<Group>(`/groups/${id}/unpause`, { method: 'POST' });
   }
 
+  async archiveGroup(id: string): Promise<Group> {
+    return this.request<Group>(`/groups/${id}/archive`, { method: 'POST' });
+  }
+
+  async unarchiveGroup(id: string): Promise<Group> {
+    return this.request<Group>(`/groups/${id}/unarchive`, { method: 'POST' });
+  }
+
   // Job Templates
   async getJobTemplates(groupId: string): Promise<JobTemplate[]> {
     return this.request<JobTemplate[]>(`/groups/${groupId}/templates`);
   }
 
+  async getJobTemplatesByCategory(groupId: string): Promise<GroupedTemplatesResponse> {
+    return this.request<GroupedTemplatesResponse>(`/groups/${groupId}/templates?group_by=category`);
+  }
+
   async getJobTemplate(id: string): Promise<JobTemplate> {
     return this.request<JobTemplate>(`/templates/${id}`);
   }
 
+  async lintJobTemplate(id: string, cached = false): Promise<TemplateLint> {
+    return this.request<TemplateLint>(`/templates/${id}/lint${cached ? '?cached=true' : ''}`);
+  }
+
   // Queue / Jobs
   async getQueue(groupId: string): Promise<Job[]> {
     return this.request<Job[]>(`/groups/${groupId}/queue`);
   }
 
+  async getQueuePage(groupId: string, limit = 100, after?: string): Promise<QueuePageResponse> {
+    const query = new URLSearchParams({ limit: String(limit) });
+    if (after) query.set('after', after);
+    return this.request<QueuePageResponse>(`/groups/${groupId}/queue?${query.toString()}`);
+  }
+
+  async getQueueSummary(groupId: string): Promise<QueueSummaryResponse> {
+    return this.request<QueueSummaryResponse>(`/groups/${groupId}/queue?summary=true`);
+  }
+
   async getHistory(
     groupId: string,
     limit = 50,
@@ -161,7 +279,10 @@ class ApiClient {
     filters?: {
       statuses?: ('completed' | 'failed' | 'cancelled')[];
       labels?: Record<string, string>;
-    }
+      templateId?: string;
+      assignee?: string;
+    },
+    beforeId?: string
   ): Promise<HistoryResponse> {
     const params = new URLSearchParams();
     params.set('limit', limit.toString());
@@ -170,6 +291,10 @@ class ApiClient {
       params.set('before', before);
     }
 
+    if (beforeId) {
+      params.set('before_id', beforeId);
+    }
+
     if (filters?.statuses && filters.statuses.length > 0) {
       params.set('status', filters.statuses.join(','));
     }
@@ -180,26 +305,69 @@ class ApiClient {
       }
     }
 
+    if (filters?.templateId) {
+      params.set('template_id', filters.templateId);
+    }
+
+    if (filters?.assignee) {
+      params.set('assignee', filters.assignee);
+    }
+
     return this.request<HistoryResponse>(`/groups/${groupId}/history?${params.toString()}`);
   }
 
   async getHistoryStats(
     groupId: string,
-    range: HistoryStatsTimeRange = 'auto'
+    range: HistoryStatsTimeRange = 'auto',
+    groupBy?: HistoryStatsGroupBy,
+    labelKey?: string
   ): Promise<HistoryStatsResponse> {
     const params = new URLSearchParams();
     params.set('range', range);
+    if (groupBy) {
+      params.set('group_by', groupBy);
+    }
+    if (labelKey) {
+      params.set('label_key', labelKey);
+    }
     return this.request<HistoryStatsResponse>(`/groups/${groupId}/history/stats?${params.toString()}`);
   }
 
+  async restoreJob(
+    groupId: string,
+    request: { run_url: string; template_id?: string; inputs?: Record<string, string>; created_by?: string }
+  ): Promise<Job> {
+    return this.request<Job>(`/groups/${groupId}/history/restore`, {
+      method: 'POST',
+      body: JSON.stringify(request),
+    });
+  }
+
   async getJob(id: string): Promise<Job> {
     return this.request<Job>(`/jobs/${id}`);
   }
 
+  async getJobAnnotations(id: string): Promise<JobAnnotation[]> {
+    return this.request<JobAnnotation[]>(`/jobs/${id}/annotations`);
+  }
+
+  async getJobTimeline(id: string): Promise<JobEvent[]> {
+    return this.request<JobEvent[]>(`/jobs/${id}/timeline`);
+  }
+
+  async getJobAttempts(id: string): Promise<JobAttemptsResponse> {
+    return this.request<JobAttemptsResponse>(`/jobs/${id}/attempts`);
+  }
+
+  async compareJobs(a: string, b: string): Promise<JobComparison> {
+    const params = new URLSearchParams({ a, b });
+    return this.request<JobComparison>(`/jobs/compare?${params}`);
+  }
+
   async createJob(
     groupId: string,
     templateId: string | null,
-    inputs?: Record<string, string>,
+    inputs?: Record<string, InputValue>,
     autoRequeue?: boolean,
     requeueLimit?: number | null,
     manualFields?: {
@@ -209,6 +377,7 @@ class ApiClient {
       workflow_id?: string;
       ref?: string;
       labels?: Record<string, string>;
+      campaign_id?: string;
     }
   ): Promise<Job> {
     return this.request<Job>(`/groups/${groupId}/queue`, {
@@ -233,6 +402,7 @@ class ApiClient {
       workflow_id?: string;
       ref?: string;
       labels?: Record<string, string>;
+      priority?: number;
     }
   ): Promise<Job> {
     return this.request<Job>(`/jobs/${id}`, {
@@ -257,6 +427,33 @@ class ApiClient {
     return this.request<Job>(`/jobs/${id}/cancel`, { method: 'POST' });
   }
 
+  async getCampaigns(): Promise<CampaignResponse[]> {
+    return this.request<CampaignResponse[]>('/campaigns');
+  }
+
+  async getCampaign(id: string): Promise<CampaignResponse> {
+    return this.request<CampaignResponse>(`/campaigns/${id}`);
+  }
+
+  async createCampaign(request: CreateCampaignRequest): Promise<CampaignResponse> {
+    return this.request<CampaignResponse>('/campaigns', {
+      method: 'POST',
+      body: JSON.stringify(request),
+    });
+  }
+
+  async pauseCampaign(id: string): Promise<CampaignActionResponse> {
+    return this.request<CampaignActionResponse>(`/campaigns/${id}/pause`, { method: 'POST' });
+  }
+
+  async unpauseCampaign(id: string): Promise<CampaignActionResponse> {
+    return this.request<CampaignActionResponse>(`/campaigns/${id}/unpause`, { method: 'POST' });
+  }
+
+  async cancelCampaign(id: string): Promise<CampaignActionResponse> {
+    return this.request<CampaignActionResponse>(`/campaigns/${id}/cancel`, { method: 'POST' });
+  }
+
   async disableAutoRequeue(id: string): Promise<Job> {
     return this.request<Job>(`/jobs/${id}/disable-requeue`, { method: 'POST' });
   }
@@ -268,6 +465,59 @@ class ApiClient {
     });
   }
 
+  async requeueJob(id: string, options?: { group_id?: string; inputs?: Record<string, string> }): Promise<Job> {
+    return this.request<Job>(`/jobs/${id}/requeue`, {
+      method: 'POST',
+      body: JSON.stringify(options ?? {}),
+    });
+  }
+
+  async retainJob(id: string): Promise<Job> {
+    return this.request<Job>(`/jobs/${id}/retain`, { method: 'POST' });
+  }
+
+  async unretainJob(id: string): Promise<Job> {
+    return this.request<Job>(`/jobs/${id}/unretain`, { method: 'POST' });
+  }
+
+  async assignJob(id: string, assignee?: string): Promise<Job> {
+    return this.request<Job>(`/jobs/${id}/assign`, {
+      method: 'POST',
+      body: JSON.stringify(assignee ? { assignee } : {}),
+    });
+  }
+
+  async unassignJob(id: string): Promise<Job> {
+    return this.request<Job>(`/jobs/${id}/unassign`, { method: 'POST' });
+  }
+
+  async updateJobOwner(id: string, createdBy: string): Promise<Job> {
+    return this.request<Job>(`/jobs/${id}/owner`, {
+      method: 'PATCH',
+      body: JSON.stringify({ created_by: createdBy }),
+    });
+  }
+
+  async getQueueChanges(
+    groupId: string,
+    params: { job_id?: string; limit?: number; offset?: number } = {}
+  ): Promise<QueueChangesResponse> {
+    const query = new URLSearchParams();
+    if (params.job_id) query.set('job_id', params.job_id);
+    if (params.limit) query.set('limit', String(params.limit));
+    if (params.offset) query.set('offset', String(params.offset));
+    const qs = query.toString();
+    return this.request<QueueChangesResponse>(`/groups/${groupId}/queue/changes${qs ? `?${qs}` : ''}`);
+  }
+
+  async getQueueStats(groupId: string, range: QueueStatsRange = '24h'): Promise<QueueStatsResponse> {
+    return this.request<QueueStatsResponse>(`/groups/${groupId}/queue/stats?range=${range}`);
+  }
+
+  async getMatchingReport(): Promise<MatchingReportResponse> {
+    return this.request<MatchingReportResponse>('/admin/matching-report');
+  }
+
   async reorderQueue(groupId: string, jobIds: string[]): Promise<void> {
     await this.request<void>(`/groups/${groupId}/queue/reorder`, {
       method: 'PUT',
@@ -293,6 +543,57 @@ class ApiClient {
     return this.request<ReloadTemplatesResponse>('/templates/reload', { method: 'POST' });
   }
 
+  async getStagedConfigSync(): Promise<StagedConfigSync> {
+    return this.request<StagedConfigSync>('/config/sync');
+  }
+
+  async approveConfigSync(id: string): Promise<ReloadTemplatesResponse> {
+    return this.request<ReloadTemplatesResponse>(`/config/sync/${id}/approve`, { method: 'POST' });
+  }
+
+  async rejectConfigSync(id: string): Promise<void> {
+    return this.request<void>(`/config/sync/${id}/reject`, { method: 'POST' });
+  }
+
+  // Saved Filters
+  async getSavedFilters(view?: SavedFilterView): Promise<SavedFilter[]> {
+    return this.request<SavedFilter[]>(view ? `/filters?view=${view}` : '/filters');
+  }
+
+  async createSavedFilter(filter: SavedFilterRequest): Promise<SavedFilter> {
+    return this.request<SavedFilter>('/filters', {
+      method: 'POST',
+      body: JSON.stringify(filter),
+    });
+  }
+
+  async updateSavedFilter(id: string, filter: SavedFilterRequest): Promise<SavedFilter> {
+    return this.request<SavedFilter>(`/filters/${id}`, {
+      method: 'PUT',
+      body: JSON.stringify(filter),
+    });
+  }
+
+  async deleteSavedFilter(id: string): Promise<void> {
+    await this.request<void>(`/filters/${id}`, { method: 'DELETE' });
+  }
+
+  // Subscriptions
+  async getSubscriptions(): Promise<Subscription[]> {
+    return this.request<Subscription[]>('/subscriptions');
+  }
+
+  async createSubscription(subscription: SubscriptionRequest): Promise<Subscription> {
+    return this.request<Subscription>('/subscriptions', {
+      method: 'POST',
+      body: JSON.stringify(subscription),
+    });
+  }
+
+  async deleteSubscription(id: string): Promise<void> {
+    await this.request<void>(`/subscriptions/${id}`, { method: 'DELETE' });
+  }
+
   // System
   async getStatus(): Promise<SystemStatus> {
     return this.request<SystemStatus>('/status');
@@ -1,6 +1,6 @@
 import { useState, useEffect } from 'react';
 import { useMutation, useQueryClient } from '@tanstack/react-query';
-import type { JobTemplate } from '../../types';
+import { inputStrings, type JobTemplate } from '../../types';
 import { api } from '../../api/client';
 import { LabelsDisplay } from '../common/LabelBadge';
 
@@ -47,7 +47,7 @@ export function AddJobDialog({ groupId, templates, isOpen, onClose, preselectedT
 
   // Derive inputs from template defaults merged with user overrides
   const inputs = selectedTemplate
-    ? { ...selectedTemplate.default_inputs, ...inputOverrides }
+    ? { ...inputStrings(selectedTemplate.default_inputs), ...inputOverrides }
     : inputOverrides;
 
   // Filter templates by search query (searches name and labels)
@@ -1,6 +1,6 @@
 import { useState, useEffect } from 'react';
 import { useMutation, useQueryClient } from '@tanstack/react-query';
-import type { JobTemplate } from '../../types';
+import { inputStrings, type JobTemplate } from '../../types';
 import { api } from '../../api/client';
 import { LabelsDisplay } from '../common/LabelBadge';
 
@@ -84,7 +84,7 @@ export function AddManualJobDialog({ groupId, templates, isOpen, onClose }: AddM
         setRepo(template.repo);
         setWorkflowId(template.workflow_id);
         setRef(template.ref);
-        setInputs({ ...template.default_inputs });
+        setInputs(inputStrings(template.default_inputs));
         setLabels(template.labels ? { ...template.labels } : {});
       }
     } else {
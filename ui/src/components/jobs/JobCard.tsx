@@ -192,6 +192,9 @@ export function JobCard({ job, template, isDragging, dragHandleProps }: JobCardP
       return false;
     }
 
+    // Inputs that differ from the template defaults, as computed by the API.
+    if (job.inputs_overridden && Object.keys(job.inputs_overridden).length > 0) return false;
+
     // Check if inputs match template defaults
     const jobInputs = job.inputs || {};
     const templateInputs = template.default_inputs || {};
@@ -424,6 +427,22 @@ export function JobCard({ job, template, isDragging, dragHandleProps }: JobCardP
               {job.runner_name}
             </span>
           )}
+          {job.not_before && job.status === 'pending' && new Date(job.not_before) > new Date() && (
+            <span className="flex items-center gap-1 text-sky-400" title="Held in the queue until this time">
+              Scheduled for {formatTime(job.not_before)}
+            </span>
+          )}
+          {job.stalled_at && job.status === 'running' && (
+            <span className="flex items-center gap-1 text-amber-400" title={`No heartbeat or run update since ${formatTime(job.stalled_at)}`}>
+              Stalled
+            </span>
+          )}
+          {job.progress && (job.status === 'triggered' || job.status === 'running') && (
+            <span className="flex items-center gap-1 text-blue-400" title={job.progress.message || 'Reported progress'}>
+              {job.progress.percent !== undefined && `${job.progress.percent}%`}
+              {job.progress.stage && <span>{job.progress.stage}</span>}
+            </span>
+          )}
         </div>
         {/* Timing info - right */}
         <div className="flex flex-wrap items-center justify-end gap-x-4 gap-y-1">
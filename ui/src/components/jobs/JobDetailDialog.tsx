@@ -1,6 +1,6 @@
 import { useState, useEffect } from 'react';
 import { useMutation, useQueryClient } from '@tanstack/react-query';
-import type { Job, JobTemplate } from '../../types';
+import { inputStrings, type Job, type JobTemplate } from '../../types';
 import { api } from '../../api/client';
 import { useAuthStore } from '../../stores/authStore';
 import { LabelsDisplay } from '../common/LabelBadge';
@@ -34,10 +34,14 @@ export function JobDetailDialog({ job, template, isOpen, onClose }: JobDetailDia
   const queryClient = useQueryClient();
   const isAdmin = user?.role === 'admin';
   const canEdit = isAdmin && job.status === 'pending';
+  const canRequeue = isAdmin && ['completed', 'failed', 'cancelled'].includes(job.status);
+  const canAssign = job.assignee
+    ? isAdmin || job.assignee === user?.username
+    : !!user && job.status === 'failed';
 
   // Initialize edit state with job overrides or template defaults
   const getInitialState = (): EditState => ({
-    inputs: job.inputs || {},
+    inputs: inputStrings(job.inputs),
     owner: job.owner ?? template?.owner ?? '',
     repo: job.repo ?? template?.repo ?? '',
     workflowId: job.workflow_id ?? template?.workflow_id ?? '',
@@ -112,6 +116,28 @@ export function JobDetailDialog({ job, template, isOpen, onClose }: JobDetailDia
     },
   });
 
+  const requeueMutation = useMutation({
+    mutationFn: () => api.requeueJob(job.id),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['queue', job.group_id] });
+      onClose();
+    },
+  });
+
+  const retainMutation = useMutation({
+    mutationFn: () => (job.retained ? api.unretainJob(job.id) : api.retainJob(job.id)),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['history', job.group_id] });
+    },
+  });
+
+  const assignMutation = useMutation({
+    mutationFn: () => (job.assignee ? api.unassignJob(job.id) : api.assignJob(job.id)),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['history', job.group_id] });
+    },
+  });
+
   if (!isOpen) return null;
 
   const colors = statusColors[job.status] || statusColors.pending;
@@ -165,6 +191,11 @@ export function JobDetailDialog({ job, template, isOpen, onClose }: JobDetailDia
       case 'workflowId': templateValue = template?.workflow_id; break;
       case 'ref': templateValue = template?.ref; break;
     }
+    // Canary jobs run the template's canary workflow and ref, where set.
+    if (job.variant === 'canary') {
+      if (field === 'workflowId') templateValue = template?.canary_workflow_id || templateValue;
+      if (field === 'ref') templateValue = template?.canary_ref || templateValue;
+    }
     return jobValue ?? templateValue ?? '';
   };
 
@@ -205,7 +236,7 @@ export function JobDetailDialog({ job, template, isOpen, onClose }: JobDetailDia
     return value;
   };
 
-  const inputEntries = Object.entries(canEdit ? editState.inputs : (job.inputs || {}));
+  const inputEntries = Object.entries(canEdit ? editState.inputs : inputStrings(job.inputs));
 
   // Get effective owner/repo for links
   const effectiveOwner = getEffectiveValue('owner');
@@ -319,6 +350,7 @@ export function JobDetailDialog({ job, template, isOpen, onClose }: JobDetailDia
                 <div className="flex items-center gap-2 mb-1">
                   <span className="text-zinc-500 text-xs">Workflow File</span>
                   {isOverridden('workflowId') && <span className="text-xs text-amber-400">overridden</span>}
+                  {job.variant === 'canary' && <span className="text-xs text-purple-400">canary</span>}
                 </div>
                 {canEdit ? (
                   <input
@@ -367,6 +399,11 @@ export function JobDetailDialog({ job, template, isOpen, onClose }: JobDetailDia
                 {isOverridden('ref') && template?.ref && (
                   <p className="text-xs text-zinc-500 mt-1">Template: {template.ref}</p>
                 )}
+                {job.resolved_sha && (
+                  <p className="text-xs text-zinc-500 mt-1 font-mono" title={job.resolved_sha}>
+                    Commit: {job.resolved_sha.slice(0, 12)}
+                  </p>
+                )}
               </div>
             </div>
             {/* GitHub links - only show when not editing */}
@@ -408,6 +445,9 @@ export function JobDetailDialog({ job, template, isOpen, onClose }: JobDetailDia
                   className="flex items-center gap-1 text-sm text-zinc-200 hover:text-blue-400"
                 >
                   Run #{job.run_id}
+                  {job.run_attempt !== undefined && job.run_attempt > 1 && (
+                    <span className="text-zinc-500">(attempt {job.run_attempt})</span>
+                  )}
                   <svg className="size-3" fill="none" stroke="currentColor" viewBox="0 0 24 24">
                     <path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M10 6H6a2 2 0 00-2 2v10a2 2 0 002 2h10a2 2 0 002-2v-4M14 4h6m0 0v6m0-6L10 14" />
                   </svg>
@@ -477,6 +517,21 @@ export function JobDetailDialog({ job, template, isOpen, onClose }: JobDetailDia
             </div>
           )}
 
+          {/* Workflow Outputs */}
+          {job.outputs && Object.keys(job.outputs).length > 0 && (
+            <div className="rounded-sm border border-zinc-800 bg-zinc-800/30 p-3 space-y-2">
+              <h3 className="text-xs font-medium text-zinc-400 uppercase tracking-wide">Outputs</h3>
+              <div className="grid grid-cols-2 gap-2 text-sm">
+                {Object.entries(job.outputs).map(([key, value]) => (
+                  <div key={key}>
+                    <span className="text-zinc-500">{key}</span>
+                    <p className="text-zinc-200 font-mono break-all">{value}</p>
+                  </div>
+                ))}
+              </div>
+            </div>
+          )}
+
           {/* Workflow Inputs */}
           <div className="rounded-sm border border-zinc-800 bg-zinc-800/30 p-3 space-y-2">
             <div className="flex items-center justify-between">
@@ -488,7 +543,7 @@ export function JobDetailDialog({ job, template, isOpen, onClose }: JobDetailDia
             {inputEntries.length > 0 ? (
               <div className="space-y-3">
                 {inputEntries.map(([key, value]) => {
-                  const templateDefault = template?.default_inputs?.[key];
+                  const templateDefault = template?.default_inputs?.[key]?.toString();
                   const currentValue = canEdit ? editState.inputs[key] : value;
                   const isInputOverridden = templateDefault !== undefined && currentValue !== templateDefault;
 
@@ -559,6 +614,21 @@ export function JobDetailDialog({ job, template, isOpen, onClose }: JobDetailDia
               {updateMutation.error instanceof Error ? updateMutation.error.message : 'Failed to update job'}
             </div>
           )}
+          {assignMutation.error && (
+            <div className="rounded-sm bg-red-500/10 border border-red-500/20 px-3 py-2 text-sm text-red-400">
+              {assignMutation.error instanceof Error ? assignMutation.error.message : 'Failed to change job assignee'}
+            </div>
+          )}
+          {retainMutation.error && (
+            <div className="rounded-sm bg-red-500/10 border border-red-500/20 px-3 py-2 text-sm text-red-400">
+              {retainMutation.error instanceof Error ? retainMutation.error.message : 'Failed to change job retention'}
+            </div>
+          )}
+          {requeueMutation.error && (
+            <div className="rounded-sm bg-red-500/10 border border-red-500/20 px-3 py-2 text-sm text-red-400">
+              {requeueMutation.error instanceof Error ? requeueMutation.error.message : 'Failed to requeue job'}
+            </div>
+          )}
         </div>
 
         {/* Footer */}
@@ -580,12 +650,43 @@ export function JobDetailDialog({ job, template, isOpen, onClose }: JobDetailDia
               </button>
             </>
           ) : (
-            <button
-              onClick={onClose}
-              className="rounded-sm px-4 py-2 text-sm text-zinc-300 hover:bg-zinc-800"
-            >
-              Close
-            </button>
+            <>
+              {canAssign && (
+                <button
+                  onClick={() => assignMutation.mutate()}
+                  disabled={assignMutation.isPending}
+                  title={job.assignee ? `Assigned to ${job.assignee}` : 'Take this failure to investigate'}
+                  className="rounded-sm px-4 py-2 text-sm text-zinc-300 hover:bg-zinc-800 disabled:opacity-50"
+                >
+                  {job.assignee ? `Unassign ${job.assignee}` : 'Assign to me'}
+                </button>
+              )}
+              {canRequeue && (
+                <button
+                  onClick={() => retainMutation.mutate()}
+                  disabled={retainMutation.isPending}
+                  title={job.retained ? 'Let history cleanup delete this job again' : 'Keep this job out of history cleanup'}
+                  className="rounded-sm px-4 py-2 text-sm text-zinc-300 hover:bg-zinc-800 disabled:opacity-50"
+                >
+                  {job.retained ? '★ Retained' : '☆ Retain'}
+                </button>
+              )}
+              {canRequeue && (
+                <button
+                  onClick={() => requeueMutation.mutate()}
+                  disabled={requeueMutation.isPending}
+                  className="rounded-sm bg-blue-600 px-4 py-2 text-sm font-medium text-white hover:bg-blue-700 disabled:opacity-50"
+                >
+                  {requeueMutation.isPending ? 'Requeueing...' : 'Requeue'}
+                </button>
+              )}
+              <button
+                onClick={onClose}
+                className="rounded-sm px-4 py-2 text-sm text-zinc-300 hover:bg-zinc-800"
+              >
+                Close
+              </button>
+            </>
           )}
         </div>
       </div>
@@ -176,6 +176,47 @@ export function StatusIndicator() {
             )}
           </div>
 
+          {/* Failed Permission Checks */}
+          {systemStatus?.permissions?.some((p) => !p.ok) && (
+            <div className="border-b border-zinc-800 px-3 py-2">
+              <div className="mb-1">
+                <span className="text-xs font-medium text-zinc-400">Permissions</span>
+              </div>
+              <div className="space-y-1">
+                {systemStatus.permissions
+                  .filter((p) => !p.ok)
+                  .map((p) => (
+                    <div key={`${p.target}-${p.permission}`} className="text-xs" title={p.error}>
+                      <span className="text-red-400">{p.permission}</span>
+                      <span className="text-zinc-500"> on {p.target}</span>
+                    </div>
+                  ))}
+              </div>
+            </div>
+          )}
+
+          {/* Runner Pools Below Minimum */}
+          {systemStatus?.runner_pools?.some((p) => p.status !== 'healthy') && (
+            <div className="border-b border-zinc-800 px-3 py-2">
+              <div className="mb-1">
+                <span className="text-xs font-medium text-zinc-400">Runner Pools</span>
+              </div>
+              <div className="space-y-1">
+                {systemStatus.runner_pools
+                  .filter((p) => p.status !== 'healthy')
+                  .map((p) => (
+                    <div key={p.group_id} className="text-xs" title="Dispatch is held for this group">
+                      <span className="text-amber-400">{p.group_id}</span>
+                      <span className="text-zinc-500">
+                        {' '}
+                        {p.online_runners} of {p.min_online_runners} runners online
+                      </span>
+                    </div>
+                  ))}
+              </div>
+            </div>
+          )}
+
           {/* Queue Stats */}
           <div className="border-b border-zinc-800 px-3 py-2">
             <div className="flex items-center justify-between">
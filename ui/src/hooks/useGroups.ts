@@ -9,6 +9,16 @@ export function useGroups() {
   });
 }
 
+// useOverview loads the dashboard in one request. Its key sits under
+// ['groups'] so that invalidating groups refreshes it too.
+export function useOverview() {
+  return useQuery({
+    queryKey: ['groups', { overview: true }],
+    queryFn: () => api.getOverview(),
+    staleTime: 30_000,
+  });
+}
+
 export function useGroup(id: string) {
   return useQuery({
     queryKey: ['groups', id],
@@ -1,16 +1,19 @@
 import { useEffect, useRef, useCallback, useState } from 'react';
 import { useQueryClient } from '@tanstack/react-query';
 import { api } from '../api/client';
-import type { Job, Runner } from '../types';
+import type { Job, Runner, WSJobDelta } from '../types';
 
 type MessageType =
   | 'runner_status'
   | 'queue_update'
   | 'job_state'
+  | 'job_delta'
   | 'dispatch'
+  | 'group_state'
   | 'system_status'
   | 'subscribed'
   | 'unsubscribed'
+  | 'reconnect'
   | 'error';
 
 interface WSMessage {
@@ -35,6 +38,8 @@ export function useWebSocket(options: UseWebSocketOptions = {}) {
   const [isConnected, setIsConnected] = useState(false);
   // Flag to prevent reconnect and close pending connections when intentionally disconnecting
   const isDisconnectingRef = useRef(false);
+  // Reconnect delay suggested by the server before it closes the connection
+  const reconnectDelayRef = useRef<number | undefined>(undefined);
 
   // Store options and queryClient in refs to avoid stale closures
   const optionsRef = useRef(options);
@@ -92,6 +97,17 @@ export function useWebSocket(options: UseWebSocketOptions = {}) {
           }
           break;
 
+        case 'job_delta':
+          // The changed fields only flag what to refetch.
+          if (payload && groupId) {
+            const delta = payload as WSJobDelta;
+            qc.invalidateQueries({ queryKey: ['job', delta.id] });
+            qc.invalidateQueries({ queryKey: ['queue', groupId] });
+            qc.invalidateQueries({ queryKey: ['history', groupId] });
+            qc.invalidateQueries({ queryKey: ['groups'] });
+          }
+          break;
+
         case 'dispatch':
           if (payload) {
             const job = payload as Job;
@@ -101,6 +117,19 @@ export function useWebSocket(options: UseWebSocketOptions = {}) {
           }
           break;
 
+        case 'group_state':
+          if (groupId) {
+            qc.invalidateQueries({ queryKey: ['group', groupId] });
+            qc.invalidateQueries({ queryKey: ['groups'] });
+          }
+          break;
+
+        case 'reconnect':
+          if (payload) {
+            reconnectDelayRef.current = (payload as { retry_after_ms: number }).retry_after_ms;
+          }
+          break;
+
         case 'error':
           if (payload) {
             opts.onError?.(String(payload));
@@ -122,7 +151,7 @@ export function useWebSocket(options: UseWebSocketOptions = {}) {
         setIsConnected(true);
         // Re-subscribe to all groups
         subscribedGroupsRef.current.forEach((groupId) => {
-          ws.send(JSON.stringify({ type: 'subscribe', group_id: groupId }));
+          ws.send(JSON.stringify({ type: 'subscribe', group_id: groupId, format: 'delta' }));
         });
       };
 
@@ -131,7 +160,9 @@ export function useWebSocket(options: UseWebSocketOptions = {}) {
         wsRef.current = null;
         // Only attempt to reconnect if we're not intentionally disconnecting
         if (!isDisconnectingRef.current) {
-          reconnectTimeoutRef.current = setTimeout(() => connectRef.current(), 3000);
+          const delay = reconnectDelayRef.current ?? 3000;
+          reconnectDelayRef.current = undefined;
+          reconnectTimeoutRef.current = setTimeout(() => connectRef.current(), delay);
         }
       };
 
@@ -163,7 +194,7 @@ export function useWebSocket(options: UseWebSocketOptions = {}) {
   const subscribe = useCallback((groupId: string) => {
     subscribedGroupsRef.current.add(groupId);
     if (wsRef.current?.readyState === WebSocket.OPEN) {
-      wsRef.current.send(JSON.stringify({ type: 'subscribe', group_id: groupId }));
+      wsRef.current.send(JSON.stringify({ type: 'subscribe', group_id: groupId, format: 'delta' }));
     }
   }, []);
 
@@ -1,5 +1,5 @@
 import { Link } from 'react-router-dom';
-import { useGroups } from '../hooks/useGroups';
+import { useOverview } from '../hooks/useGroups';
 import { MiniHistoryChart } from '../components/charts/MiniHistoryChart';
 import type { GroupWithStats } from '../types';
 
@@ -73,7 +73,8 @@ function GroupCard({ group }: { group: GroupWithStats }) {
 }
 
 export function DashboardPage() {
-  const { data: groups, isLoading, error } = useGroups();
+  const { data: overview, isLoading, error } = useOverview();
+  const groups = overview?.groups;
 
   return (
     <div className="space-y-6">
@@ -81,7 +82,7 @@ export function DashboardPage() {
 
       {error && (
         <div className="rounded-sm border border-red-500/20 bg-red-500/10 px-4 py-3 text-sm text-red-400">
-          Failed to load groups: {error.message}
+          Failed to load overview: {error.message}
         </div>
       )}
 
@@ -107,6 +108,22 @@ export function DashboardPage() {
           </p>
         </div>
       )}
+
+      {overview && overview.recent_failures.length > 0 && (
+        <div className="rounded-sm border border-zinc-800 bg-zinc-900 p-6">
+          <h2 className="mb-3 text-sm font-semibold text-zinc-300">Failures in the last 24 hours</h2>
+          <ul className="space-y-2">
+            {overview.recent_failures.map((job) => (
+              <li key={job.id} className="flex items-center justify-between text-sm">
+                <Link to={`/groups/${job.group_id}`} className="text-zinc-100 hover:text-zinc-300">
+                  {job.name || job.template_id || job.id}
+                </Link>
+                <span className="truncate pl-4 text-xs text-red-400">{job.error_message}</span>
+              </li>
+            ))}
+          </ul>
+        </div>
+      )}
     </div>
   );
 }
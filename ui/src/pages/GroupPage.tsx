@@ -27,7 +27,7 @@ import { AddJobDialog } from '../components/jobs/AddJobDialog';
 import { AddManualJobDialog } from '../components/jobs/AddManualJobDialog';
 import { LabelsDisplay } from '../components/common/LabelBadge';
 import { HistoryChart } from '../components/charts/HistoryChart';
-import type { Job, JobTemplate, Runner } from '../types';
+import { inputStrings, type Job, type JobTemplate, type Runner } from '../types';
 
 function SortableJobCard({ job, template }: { job: Job; template?: JobTemplate }) {
   const { attributes, listeners, setNodeRef, transform, transition, isDragging } = useSortable({
@@ -122,7 +122,7 @@ export function GroupPage() {
 
   // History pagination state
   const [historyJobs, setHistoryJobs] = useState<Job[]>([]);
-  const [historyCursor, setHistoryCursor] = useState<string | undefined>();
+  const [historyCursor, setHistoryCursor] = useState<{ before?: string; beforeId?: string } | undefined>();
   const [hasMoreHistory, setHasMoreHistory] = useState(false);
   const [isLoadingMore, setIsLoadingMore] = useState(false);
 
@@ -176,16 +176,19 @@ export function GroupPage() {
       }
     });
 
-    return { statuses, labels };
+    // "My failures": failed jobs assigned to the current user.
+    const mine = searchParams.get('hmine') === 'true';
+
+    return { statuses, labels, mine };
   };
 
   const historyFilters = getHistoryFiltersFromURL();
 
-  const updateHistoryFilters = (newFilters: { statuses: HistoryStatus[]; labels: Record<string, string> }) => {
+  const updateHistoryFilters = (newFilters: { statuses: HistoryStatus[]; labels: Record<string, string>; mine: boolean }) => {
     setSearchParams((prev) => {
       // Clear existing filter params
       const keysToDelete = Array.from(prev.keys()).filter(
-        k => k === 'hstatus' || k.startsWith('hlabel.')
+        k => k === 'hstatus' || k === 'hmine' || k.startsWith('hlabel.')
       );
       keysToDelete.forEach(k => prev.delete(k));
 
@@ -199,6 +202,10 @@ export function GroupPage() {
         prev.set(`hlabel.${key}`, value);
       }
 
+      if (newFilters.mine) {
+        prev.set('hmine', 'true');
+      }
+
       return prev;
     });
 
@@ -225,10 +232,11 @@ export function GroupPage() {
   };
 
   const clearHistoryFilters = () => {
-    updateHistoryFilters({ statuses: [], labels: {} });
+    updateHistoryFilters({ statuses: [], labels: {}, mine: false });
   };
 
-  const hasActiveHistoryFilters = historyFilters.statuses.length > 0 || Object.keys(historyFilters.labels).length > 0;
+  const hasActiveHistoryFilters =
+    historyFilters.statuses.length > 0 || Object.keys(historyFilters.labels).length > 0 || historyFilters.mine;
 
   const [expandedGroups, setExpandedGroups] = useState<Set<string>>(new Set());
   const { subscribe, unsubscribe } = useWebSocket();
@@ -348,12 +356,15 @@ export function GroupPage() {
 
   // Build filter object for API calls
   const historyFilterParams = {
-    statuses: historyFilters.statuses.length > 0 ? historyFilters.statuses : undefined,
+    statuses: historyFilters.mine
+      ? (['failed'] as HistoryStatus[])
+      : historyFilters.statuses.length > 0 ? historyFilters.statuses : undefined,
     labels: Object.keys(historyFilters.labels).length > 0 ? historyFilters.labels : undefined,
+    assignee: historyFilters.mine ? 'me' : undefined,
   };
 
   const { data: historyData, isLoading: historyLoading } = useQuery({
-    queryKey: ['history', id, historyFilters.statuses, historyFilters.labels],
+    queryKey: ['history', id, historyFilters.statuses, historyFilters.labels, historyFilters.mine],
     queryFn: () => api.getHistory(id!, 50, undefined, historyFilterParams),
     enabled: !!id,
   });
@@ -374,14 +385,15 @@ export function GroupPage() {
   }, [id]);
 
   const loadMoreHistory = async () => {
-    if (!historyData?.next_cursor || isLoadingMore) return;
+    const cursor = historyCursor ?? { before: historyData?.next_cursor, beforeId: historyData?.next_cursor_id };
+    if (!cursor.before || isLoadingMore) return;
 
     setIsLoadingMore(true);
     try {
-      const moreData = await api.getHistory(id!, 50, historyData.next_cursor, historyFilterParams);
+      const moreData = await api.getHistory(id!, 50, cursor.before, historyFilterParams, cursor.beforeId);
       setHistoryJobs(prev => [...prev, ...moreData.jobs]);
       setHasMoreHistory(moreData.has_more);
-      setHistoryCursor(moreData.next_cursor);
+      setHistoryCursor({ before: moreData.next_cursor, beforeId: moreData.next_cursor_id });
     } finally {
       setIsLoadingMore(false);
     }
@@ -873,8 +885,47 @@ export function GroupPage() {
               </span>
             )}
           </div>
-          {group.description && (
-            <p className="mt-1 text-sm text-zinc-400">{group.description}</p>
+          {group.description_html ? (
+            <div
+              className="mt-1 text-sm text-zinc-400 [&_a]:text-blue-400 [&_a]:underline [&_code]:text-zinc-300"
+              // Rendered and sanitized by the API.
+              dangerouslySetInnerHTML={{ __html: group.description_html }}
+            />
+          ) : (
+            group.description && <p className="mt-1 text-sm text-zinc-400">{group.description}</p>
+          )}
+          {group.metadata && (
+            <div className="mt-2 flex flex-wrap items-center gap-x-4 gap-y-1 text-xs text-zinc-400">
+              {group.metadata.team && (
+                <span>
+                  Team: <span className="text-zinc-300">{group.metadata.team}</span>
+                </span>
+              )}
+              {group.metadata.chat_channel && (
+                <span>
+                  Channel: <span className="text-zinc-300">{group.metadata.chat_channel}</span>
+                </span>
+              )}
+              {Object.entries(group.metadata.fields ?? {}).map(([key, value]) => (
+                <span key={key}>
+                  {key}: <span className="text-zinc-300">{value}</span>
+                </span>
+              ))}
+              {group.metadata.links?.map((link) => (
+                <a
+                  key={link.url}
+                  href={link.url}
+                  target="_blank"
+                  rel="noopener noreferrer"
+                  className="text-blue-400 hover:underline"
+                >
+                  {link.title}
+                </a>
+              ))}
+            </div>
+          )}
+          {group.paused && group.paused_reason && (
+            <p className="mt-1 text-sm text-amber-400">{group.paused_reason}</p>
           )}
           <div className="mt-2 flex flex-wrap gap-1.5">
             {group.runner_labels.map((label) => (
@@ -1251,6 +1302,17 @@ export function GroupPage() {
                           {status}
                         </button>
                       ))}
+                      <button
+                        onClick={() => updateHistoryFilters({ ...historyFilters, mine: !historyFilters.mine })}
+                        title="Failed jobs assigned to you"
+                        className={`rounded-xs px-2 py-0.5 text-xs transition-colors ${
+                          historyFilters.mine
+                            ? 'bg-amber-500/30 text-amber-300 ring-1 ring-amber-500/50'
+                            : 'bg-zinc-800 text-zinc-400 hover:bg-zinc-700 hover:text-zinc-300'
+                        }`}
+                      >
+                        My failures
+                      </button>
                     </div>
                   </div>
 
@@ -1556,7 +1618,7 @@ export function GroupPage() {
                         templateSelectionMode && selectedTemplateIds.has(template.id)
                           ? 'border-blue-500/50 ring-1 ring-blue-500/20'
                           : 'border-zinc-800'
-                      }`}
+                      } ${template.deprecated ? 'opacity-60' : ''}`}
                     >
                       <div className="flex items-start justify-between gap-3">
                         {/* Checkbox for selection mode */}
@@ -1582,6 +1644,14 @@ export function GroupPage() {
                                 Not in config
                               </span>
                             )}
+                            {template.deprecated && (
+                              <span
+                                className="inline-flex items-center rounded-sm bg-zinc-700/50 px-1.5 py-0.5 text-xs text-zinc-400"
+                                title={template.sunset_at ? `Stops accepting jobs on ${new Date(template.sunset_at).toLocaleString()}` : 'This template is deprecated'}
+                              >
+                                Deprecated
+                              </span>
+                            )}
                             {template.source_type === 'file' && (
                               <span
                                 className="inline-flex items-center gap-1 rounded-sm bg-blue-500/20 px-1.5 py-0.5 text-xs text-blue-300"
@@ -1606,6 +1676,40 @@ export function GroupPage() {
                                 URL
                               </span>
                             )}
+                            {template.source_type === 'git' && (
+                              <span
+                                className="inline-flex items-center gap-1 rounded-sm bg-emerald-500/20 px-1.5 py-0.5 text-xs text-emerald-300"
+                                title={`Loaded from git: ${template.source_path}`}
+                              >
+                                <svg className="size-3" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                                  <path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2}
+                                    d="M6 3v12m0 0a3 3 0 103 3m-3-3a3 3 0 013 3m0 0h3a6 6 0 006-6V9m0 0a3 3 0 10-3-3 3 3 0 003 3z" />
+                                </svg>
+                                Git
+                              </span>
+                            )}
+                            {template.source_type === 'api' && (
+                              <span
+                                className="inline-flex items-center rounded-sm bg-sky-500/20 px-1.5 py-0.5 text-xs text-sky-300"
+                                title="Created through the API; config syncs leave it alone"
+                              >
+                                API
+                              </span>
+                            )}
+                            {template.overrides && (
+                              <span
+                                className="inline-flex items-center rounded-sm bg-amber-500/20 px-1.5 py-0.5 text-xs text-amber-300"
+                                title={`Overridden by ${template.overrides.updated_by}: ${[
+                                  template.overrides.owner && `owner ${template.overrides.owner}`,
+                                  template.overrides.repo && `repo ${template.overrides.repo}`,
+                                  template.overrides.ref && `ref ${template.overrides.ref}`,
+                                ]
+                                  .filter(Boolean)
+                                  .join(', ')}`}
+                              >
+                                Overridden
+                              </span>
+                            )}
                           </div>
                           {/* Template labels */}
                           {template.labels && Object.keys(template.labels).length > 0 && (
@@ -1667,7 +1771,7 @@ export function GroupPage() {
                         <div className="mt-3 border-t border-zinc-800 pt-3">
                           <h5 className="mb-2 text-xs font-medium text-zinc-400">Workflow Inputs</h5>
                           <div className="space-y-2">
-                            {Object.entries(template.default_inputs).map(([key, value]) => (
+                            {Object.entries(inputStrings(template.default_inputs)).map(([key, value]) => (
                               <div key={key} className="text-xs">
                                 <span className="font-medium text-zinc-400">{key}:</span>
                                 {value.length > 80 ? (
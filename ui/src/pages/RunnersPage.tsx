@@ -19,6 +19,14 @@ function RunnerRow({ runner }: { runner: Runner }) {
             }`}
           />
           <span className="font-medium text-zinc-100">{runner.name}</span>
+          {runner.hosted && (
+            <span
+              className="rounded-sm bg-sky-500/10 px-1.5 py-0.5 text-xs text-sky-400"
+              title={`GitHub-hosted larger runner, up to ${runner.max_runners ?? 0} concurrent jobs`}
+            >
+              Hosted ×{runner.max_runners ?? 0}
+            </span>
+          )}
         </div>
       </td>
       <td className="px-4 py-3">
@@ -15,10 +15,37 @@ export interface Group {
   runner_labels: string[];
   enabled: boolean;
   paused: boolean;
+  // Set when the group was paused automatically (e.g. by the circuit breaker).
+  paused_reason?: string;
+  resumed_at?: string;
+  archived: boolean;
+  archived_at?: string;
+  // "fifo" (default when unset) or "shortest_job_first".
+  scheduling_policy?: string;
+  // Caps on job priority and auto-requeue limits (unset = no cap).
+  max_priority?: number;
+  max_requeue_limit?: number;
+  // Dispatch is held while fewer matching runners are online (0 = no minimum).
+  min_online_runners: number;
+  metadata?: GroupMetadata;
+  // The markdown description rendered as sanitized HTML.
+  description_html?: string;
   created_at: string;
   updated_at: string;
 }
 
+export interface GroupLink {
+  title: string;
+  url: string;
+}
+
+export interface GroupMetadata {
+  team?: string;
+  chat_channel?: string;
+  links?: GroupLink[];
+  fields?: Record<string, string>;
+}
+
 export interface GroupWithStats extends Group {
   queued_jobs: number;
   running_jobs: number;
@@ -26,9 +53,65 @@ export interface GroupWithStats extends Group {
   busy_runners: number;
   total_runners: number;
   template_count: number;
+  oldest_pending_age_seconds: number;
+  oldest_pending_job_id?: string;
+  starved: boolean;
+  online_runners: number;
+  // Set while online runners are below min_online_runners.
+  degraded: boolean;
+  // Jobs finished in the last 24 hours.
+  last_24h: {
+    completed: number;
+    failed: number;
+    cancelled: number;
+  };
+}
+
+export interface RunnerSummary {
+  total: number;
+  online: number;
+  offline: number;
+  busy: number;
+  idle: number;
+}
+
+// Everything the dashboard shows on load, from GET /overview.
+export interface OverviewResponse {
+  groups: GroupWithStats[];
+  runners: RunnerSummary;
+  // Failures of the last 24 hours, most recent first.
+  recent_failures: Job[];
+  system: SystemStatus;
 }
 
-export type TemplateSourceType = 'inline' | 'file' | 'url';
+export type SearchResultType = 'group' | 'template' | 'job' | 'runner';
+
+// A match of GET /search. group_id is set for templates and jobs, status for
+// jobs and runners.
+export interface SearchResult {
+  type: SearchResultType;
+  id: string;
+  name: string;
+  group_id?: string;
+  status?: string;
+}
+
+export interface SearchResponse {
+  query: string;
+  results: SearchResult[];
+}
+
+// 'api' templates were created through the API, e.g. by cloning.
+export type TemplateSourceType = 'inline' | 'file' | 'url' | 'git' | 'api';
+
+// Workflow inputs keep their type; booleans and numbers are dispatched as such.
+export type InputValue = string | boolean | number;
+
+// inputStrings returns inputs as strings for editing. The server converts
+// edited values back to the type of the input they replace.
+export function inputStrings(inputs: Record<string, InputValue> | undefined): Record<string, string> {
+  return Object.fromEntries(Object.entries(inputs || {}).map(([key, value]) => [key, String(value)]));
+}
 
 export interface JobTemplate {
   id: string;
@@ -38,15 +121,65 @@ export interface JobTemplate {
   repo: string;
   workflow_id: string;
   ref: string;
-  default_inputs: Record<string, string>;
+  default_inputs: Record<string, InputValue>;
   labels?: Record<string, string>;
   in_config: boolean;
   source_type: TemplateSourceType;
   source_path: string;
+  deprecated: boolean;
+  sunset_at?: string;
+  environment?: string;
+  category?: string;
+  display_order: number;
+  dispatch_windows?: DispatchWindow[];
+  // Inputs jobs may not override.
+  pinned_inputs?: string[];
+  // Idle runners required before the template's jobs are dispatched.
+  min_idle_runners: number;
+  // Priority of jobs enqueued from the template.
+  default_priority: number;
+  // Jobs the dispatcher keeps queued or running at all times (0 = off).
+  keep_running: number;
+  stall_timeout_seconds: number;
+  // Running jobs are cancelled and failed after this long (0 = no limit).
+  max_duration_seconds: number;
+  // Share of jobs dispatched with the canary workflow and ref (0 = none).
+  canary_percent: number;
+  canary_workflow_id?: string;
+  canary_ref?: string;
+  // Secret inputs passed to the workflow through short-lived Actions secrets.
+  secret_handoff_inputs?: string[];
+  secret_handoff_environment?: boolean;
+  // API changes to a template from config, kept across config syncs.
+  overrides?: TemplateOverrides;
   created_at: string;
   updated_at: string;
 }
 
+export interface TemplateOverrides {
+  owner?: string;
+  repo?: string;
+  ref?: string;
+  updated_by: string;
+  updated_at: string;
+}
+
+export interface DispatchWindow {
+  days?: string[];
+  start: string;
+  end: string;
+  timezone?: string;
+}
+
+export interface TemplateCategory {
+  category: string;
+  templates: JobTemplate[];
+}
+
+export interface GroupedTemplatesResponse {
+  categories: TemplateCategory[];
+}
+
 export interface Job {
   id: string;
   group_id: string;
@@ -58,11 +191,25 @@ export interface Job {
   auto_requeue: boolean;
   requeue_limit: number | null;
   requeue_count: number;
-  inputs: Record<string, string>;
+  inputs: Record<string, InputValue>;
   created_by: string;
   triggered_at: string | null;
   run_id: number | null;
   run_url: string;
+  // Attempt of the workflow run, above 1 once re-run from GitHub.
+  run_attempt?: number;
+  // GitHub API URLs of the run attempt's logs archive and artifacts.
+  logs_url?: string;
+  artifacts_url?: string;
+  // Retained jobs are never deleted by history cleanup.
+  retained: boolean;
+  // User investigating a failed job, and when they took it.
+  assignee?: string;
+  assigned_at?: string;
+  // Variant a job of a canary template was dispatched as.
+  variant?: 'stable' | 'canary';
+  // Actions secrets holding the job's secret inputs, until they are deleted.
+  handoff_secrets?: { owner: string; repo: string; environment?: string; names: string[] };
   runner_id: number | null;
   runner_name: string;
   completed_at: string | null;
@@ -76,13 +223,315 @@ export interface Job {
   workflow_id?: string;
   ref?: string;
   labels?: Record<string, string>;
+  // Structured results reported by the workflow run.
+  outputs?: Record<string, string>;
+  // ID of the job this one was requeued from.
+  requeued_from?: string;
+  // Commit the ref resolved to at dispatch time.
+  resolved_sha?: string;
+  // Who enqueued the job, set once created_by has been reassigned.
+  original_created_by?: string;
+  // Campaign the job belongs to.
+  campaign_id?: string;
+  // Overrides the template's max_duration_seconds.
+  max_duration_seconds?: number;
+  // Where the job's transitions are delivered besides its subscribers.
+  notify_targets?: JobNotifyTarget[];
+  // Earliest time a pending job may be dispatched.
+  not_before?: string;
+  // Issue the job was requested by, as owner/repo#number.
+  source_issue?: string;
+  // Latest progress reported by the job's workflow run.
+  progress?: JobProgress;
+  // Last heartbeat or progress report from the workflow, and when a running
+  // job was flagged as stalled.
+  heartbeat_at?: string;
+  stalled_at?: string;
+  // Dispatch order of an unpaused pending job (1-based) and the number of
+  // jobs ahead of it; computed by the API.
+  queue_position?: number;
+  ahead_count?: number;
+  // Inputs differing from the template defaults (default null when the
+  // template does not declare the input); computed by the API.
+  inputs_overridden?: Record<string, InputOverride>;
+}
+
+export interface InputOverride {
+  value: InputValue;
+  default: InputValue | null;
+}
+
+export interface JobProgress {
+  percent?: number;
+  stage?: string;
+  message?: string;
+  updated_at: string;
+}
+
+export interface JobAnnotation {
+  workflow_job: string;
+  path: string;
+  start_line: number;
+  end_line: number;
+  title?: string;
+  message: string;
+}
+
+export interface JobEvent {
+  id: string;
+  job_id: string;
+  // Empty for the event that created the job.
+  from_status?: JobStatus;
+  to_status: JobStatus;
+  actor: string;
+  run_id?: number;
+  runner_name?: string;
+  message?: string;
+  created_at: string;
+}
+
+export interface JobRunAttempt {
+  job_id: string;
+  attempt: number;
+  // How the attempt ended: failed or cancelled.
+  status: JobStatus;
+  error_message?: string;
+  runner_name?: string;
+  logs_url?: string;
+  completed_at?: string;
+}
+
+export interface JobAttemptsResponse {
+  job_id: string;
+  run_attempt: number;
+  attempts: JobRunAttempt[];
+}
+
+export interface FieldChange {
+  a: string;
+  b: string;
+  changed: boolean;
+}
+
+export interface InputChange {
+  key: string;
+  // Null when the input is not set on that job.
+  a: InputValue | null;
+  b: InputValue | null;
+}
+
+export interface JobComparison {
+  a: Job;
+  b: Job;
+  template_id: string;
+  inputs: InputChange[];
+  ref: FieldChange;
+  resolved_sha: FieldChange;
+  runner: FieldChange;
+  conclusion: FieldChange;
+  error_message: FieldChange;
+  duration: {
+    a_seconds: number | null;
+    b_seconds: number | null;
+    delta_seconds?: number;
+  };
+}
+
+export type QueueChangeOperation = 'reorder' | 'pause' | 'unpause' | 'priority' | 'delete';
+
+export interface QueueSnapshotEntry {
+  job_id: string;
+  position: number;
+  priority: number;
+  paused: boolean;
+}
+
+export interface QueueChange {
+  id: string;
+  group_id: string;
+  // Empty for reorders, which affect the whole queue.
+  job_id?: string;
+  operation: QueueChangeOperation;
+  actor: string;
+  // Affected jobs in dispatch order; after is empty for deletes.
+  before: QueueSnapshotEntry[];
+  after: QueueSnapshotEntry[];
+  created_at: string;
+}
+
+export interface QueueChangesResponse {
+  changes: QueueChange[];
+  total: number;
+}
+
+export type QueueStatsRange = '1h' | '6h' | '24h' | '7d' | '30d';
+
+// One bucket of sampled queue stats; pending, running and idle_runners are averages.
+export interface QueueStatsPoint {
+  time: string;
+  samples: number;
+  pending: number;
+  max_pending: number;
+  running: number;
+  idle_runners: number;
+  min_idle_runners: number;
+}
+
+export interface QueueStatsResponse {
+  group_id: string;
+  range: QueueStatsRange;
+  start: string;
+  end: string;
+  bucket_seconds: number;
+  points: QueueStatsPoint[];
+}
+
+// A page of pending jobs in dispatch order.
+export interface QueuePageResponse {
+  jobs: Job[];
+  // Triggered and running jobs, on the first page only.
+  active?: Job[];
+  has_more: boolean;
+  next_cursor?: string;
+}
+
+export interface QueueTemplateSummary {
+  template_id: string;
+  pending: number;
+  paused: number;
+  triggered: number;
+  running: number;
+}
+
+export interface QueueSummaryResponse {
+  templates: QueueTemplateSummary[];
+  total: QueueTemplateSummary;
+}
+
+export type MatchReason =
+  | 'dispatchable'
+  | 'dispatcher_disabled'
+  | 'group_disabled'
+  | 'group_paused'
+  | 'triggered_jobs_pending'
+  | 'job_paused'
+  | 'not_before'
+  | 'outside_dispatch_window'
+  | 'queued_behind'
+  | 'not_enough_online_runners'
+  | 'no_idle_runner'
+  | 'not_enough_idle_runners';
+
+export interface GroupMatchReport {
+  group_id: string;
+  runner_labels: string[];
+  paused: boolean;
+  paused_reason?: string;
+  triggered_jobs: number;
+  runners: {
+    id: number;
+    name: string;
+    status: RunnerStatus;
+    busy: boolean;
+    idle: boolean;
+    hosted?: boolean;
+    free_slots?: number;
+  }[];
+  idle_runners: number;
+  online_runners: number;
+  min_online_runners?: number;
+  next_job_id?: string;
+  jobs: { job_id: string; template_id?: string; position: number; reason: MatchReason; detail?: string }[];
+  error?: string;
+}
+
+export interface MatchingReportResponse {
+  generated_at: string;
+  dispatcher_enabled: boolean;
+  groups: GroupMatchReport[];
+}
+
+export interface SettingsValues {
+  dispatch_interval: string;
+  tracking_interval: string;
+  rate_limit_buffer: number;
+}
+
+export interface SettingOverride {
+  key: keyof SettingsValues;
+  value: string;
+  updated_by: string;
+  updated_at: string;
+}
+
+export interface SettingsResponse {
+  effective: SettingsValues;
+  defaults: SettingsValues;
+  overrides: SettingOverride[];
+}
+
+export interface TableStats {
+  table: string;
+  rows: number;
+  size_bytes?: number;
+}
+
+export interface DatabaseStats {
+  driver: string;
+  size_bytes: number;
+  free_bytes?: number;
+  tables: TableStats[];
+}
+
+export interface Campaign {
+  id: string;
+  name: string;
+  description: string;
+  metadata: Record<string, string>;
+  created_by: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CampaignProgress {
+  total: number;
+  pending: number;
+  paused: number;
+  triggered: number;
+  running: number;
+  completed: number;
+  failed: number;
+  cancelled: number;
+  // Completed, failed and cancelled jobs.
+  finished: number;
+}
+
+export interface CampaignResponse extends Campaign {
+  progress: CampaignProgress;
+  // Only set for a single campaign.
+  jobs?: Job[];
+}
+
+export interface CreateCampaignRequest {
+  name: string;
+  description?: string;
+  metadata?: Record<string, string>;
+  jobs?: { group_id: string; template_id: string; inputs?: Record<string, string> }[];
+}
+
+export interface CampaignActionResponse {
+  affected: number;
+  errors?: string[];
+  progress: CampaignProgress;
 }
 
 export interface HistoryResponse {
   jobs: Job[];
   has_more: boolean;
   next_cursor?: string;
-  total_count: number;
+  next_cursor_id?: string;
+  total_count?: number;
+  total_count_cached?: boolean;
 }
 
 export interface HistoryStatsBucket {
@@ -108,6 +557,15 @@ export interface HistoryStatsResponse {
   buckets: HistoryStatsBucket[];
   range: HistoryStatsRange;
   totals: HistoryStatsTotals;
+  series?: HistoryStatsSeries[];
+}
+
+export type HistoryStatsGroupBy = 'template_id' | 'label' | 'created_by';
+
+export interface HistoryStatsSeries {
+  key: string;
+  buckets: HistoryStatsBucket[];
+  totals: HistoryStatsTotals;
 }
 
 export type HistoryStatsTimeRange = '1h' | '6h' | '24h' | '7d' | '30d' | 'auto';
@@ -119,6 +577,9 @@ export interface Runner {
   status: RunnerStatus;
   busy: boolean;
   os: string;
+  // A GitHub-hosted larger runner: a pool running up to max_runners jobs.
+  hosted: boolean;
+  max_runners?: number;
   last_seen_at: string;
   created_at: string;
   updated_at: string;
@@ -132,6 +593,27 @@ export interface User {
   github_id?: string;
   created_at: string;
   updated_at: string;
+  // Admin acting as this user via X-Act-As; only set by /auth/me.
+  impersonated_by?: string;
+}
+
+export interface LoginResponse {
+  token: string;
+  user: User;
+  refresh_token?: string;
+  expires_at?: string;
+}
+
+export interface Session {
+  id: string;
+  user_id: string;
+  username: string;
+  expires_at: string;
+  created_at: string;
+  ip_address?: string;
+  user_agent?: string;
+  last_used_at?: string;
+  current: boolean;
 }
 
 export interface ApiError {
@@ -144,6 +626,24 @@ export interface DatabaseStatus {
   status: ComponentStatus;
   latency?: string;
   error?: string;
+  // Set when database.maintenance is enabled.
+  maintenance?: MaintenanceStatus;
+}
+
+export interface MaintenanceStatus {
+  status: ComponentStatus;
+  interval: string;
+  next_run_at?: string;
+  last_run_at?: string;
+  duration?: string;
+  tasks?: MaintenanceTaskStatus[];
+  error?: string;
+}
+
+export interface MaintenanceTaskStatus {
+  task: string;
+  duration: string;
+  error?: string;
 }
 
 export interface GitHubClientStatus {
@@ -160,6 +660,32 @@ export interface GitHubClientsStatus {
   dispatch?: GitHubClientStatus;
 }
 
+export type LintSeverity = 'error' | 'warning';
+
+export interface LintIssue {
+  severity: LintSeverity;
+  code: string;
+  input?: string;
+  message: string;
+}
+
+export interface TemplateLint {
+  template_id: string;
+  ref: string;
+  workflow_path?: string;
+  ok: boolean;
+  issues: LintIssue[];
+  checked_at: string;
+}
+
+export interface PermissionStatus {
+  target: string;
+  permission: string;
+  ok: boolean;
+  error?: string;
+  templates?: string[];
+}
+
 export interface QueueStats {
   pending_jobs: number;
   triggered_jobs: number;
@@ -179,6 +705,15 @@ export interface SystemStatus {
   github: GitHubClientsStatus;
   queue: QueueStats;
   version: VersionInfo;
+  permissions?: PermissionStatus[];
+  runner_pools?: RunnerPoolStatus[];
+}
+
+export interface RunnerPoolStatus {
+  group_id: string;
+  status: ComponentStatus;
+  online_runners: number;
+  min_online_runners: number;
 }
 
 // WebSocket message types
@@ -186,18 +721,31 @@ export type WSMessageType =
   | 'runner_status'
   | 'queue_update'
   | 'job_state'
+  | 'job_delta'
   | 'dispatch'
+  | 'group_state'
+  | 'group_starved'
+  | 'job_stalled'
+  | 'campaign_update'
   | 'system_status'
   | 'subscribe'
   | 'unsubscribe'
   | 'ping'
-  | 'pong';
+  | 'pong'
+  | 'reconnect';
 
 export interface WSMessage<T = unknown> {
   type: WSMessageType;
   payload: T;
 }
 
+// Sent instead of job_state to delta subscribers: the job fields changed since
+// its last state, with dropped fields as null.
+export interface WSJobDelta {
+  id: string;
+  changed: Partial<Record<keyof Job, unknown>>;
+}
+
 export interface WSRunnerStatus {
   group_id: string;
   runner: Runner;
@@ -225,6 +773,18 @@ export interface WSDispatch {
   workflow: string;
 }
 
+export interface WSGroupStarved {
+  group_id: string;
+  job_id: string;
+  age_seconds: number;
+}
+
+export interface WSJobStalled {
+  group_id: string;
+  job_id: string;
+  idle_seconds: number;
+}
+
 export interface WSSystemStatus {
   dispatcher_running: boolean;
   github_rate_limit_remaining: number;
@@ -241,6 +801,84 @@ export interface ReloadTemplatesGroupStats {
 export interface ReloadTemplatesResponse {
   message: string;
   groups: ReloadTemplatesGroupStats[];
+  // Set when the reload was staged for approval instead of applied.
+  staged?: StagedConfigSync;
+}
+
+export interface ConfigSyncItem {
+  group_id: string;
+  template_id?: string;
+  // Changed fields of an updated group or template.
+  fields?: string[];
+}
+
+export interface ConfigSyncDiff {
+  groups_created: ConfigSyncItem[] | null;
+  groups_updated: ConfigSyncItem[] | null;
+  groups_removed: ConfigSyncItem[] | null;
+  templates_created: ConfigSyncItem[] | null;
+  templates_updated: ConfigSyncItem[] | null;
+  templates_deleted: ConfigSyncItem[] | null;
+  templates_retired: ConfigSyncItem[] | null;
+}
+
+export interface StagedConfigSync {
+  id: string;
+  diff: ConfigSyncDiff;
+  staged_by: string;
+  staged_at: string;
+}
+
+export type SavedFilterView = 'queue' | 'history';
+
+export interface SavedFilter {
+  id: string;
+  user_id: string;
+  name: string;
+  view: SavedFilterView;
+  group_id?: string;
+  statuses: JobStatus[];
+  labels: Record<string, string>;
+  template_id?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface SavedFilterRequest {
+  name: string;
+  view: SavedFilterView;
+  group_id?: string;
+  statuses?: JobStatus[];
+  labels?: Record<string, string>;
+  template_id?: string;
+}
+
+export type SubscriptionTarget = 'job' | 'template';
+export type SubscriptionChannel = 'email' | 'slack' | 'webhook';
+
+export interface Subscription {
+  id: string;
+  user_id: string;
+  target_type: SubscriptionTarget;
+  target_id: string;
+  channel: SubscriptionChannel;
+  destination: string;
+  statuses: JobStatus[];
+  created_at: string;
+}
+
+export interface JobNotifyTarget {
+  channel: SubscriptionChannel;
+  destination: string;
+  statuses?: JobStatus[];
+}
+
+export interface SubscriptionRequest {
+  target_type: SubscriptionTarget;
+  target_id: string;
+  channel: SubscriptionChannel;
+  destination: string;
+  statuses?: JobStatus[];
 }
 
 // Health endpoint types